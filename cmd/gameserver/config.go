@@ -45,6 +45,11 @@ type gameServer struct {
 	ShowClock    bool    `envconfig:"GAME_SERVER_SHOW_CLOCK" default:"true"`
 	ExpRate      float64 `envconfig:"GAME_SERVER_EXP_RATE" default:"1.0"`
 	SpRate       float64 `envconfig:"GAME_SERVER_SP_RATE" default:"1.0"`
+	DropRate     float64 `envconfig:"GAME_SERVER_DROP_RATE" default:"1.0"`  // multiplies drop chances
+	AdenaRate    float64 `envconfig:"GAME_SERVER_ADENA_RATE" default:"1.0"` // multiplies dropped adena
+	OfflineTrade bool    `envconfig:"GAME_SERVER_OFFLINE_TRADE" default:"false"`
+	// FreeTeleportLevel: gatekeeper teleports are free below this level (0 = never).
+	FreeTeleportLevel int `envconfig:"GAME_SERVER_FREE_TELEPORT_LEVEL" default:"0"`
 }
 
 type loginServerConnection struct {
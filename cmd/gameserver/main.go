@@ -53,6 +53,14 @@ func main() {
 		ShowClock:    config.GameServer.ShowClock,
 		ExpRate:      config.GameServer.ExpRate,
 		SpRate:       config.GameServer.SpRate,
+		DropRate:     config.GameServer.DropRate,
+		AdenaRate:    config.GameServer.AdenaRate,
+
+		// Offline trade
+		OfflineTrade: config.GameServer.OfflineTrade,
+
+		// Gatekeepers
+		FreeTeleportLevel: config.GameServer.FreeTeleportLevel,
 	}
 
 	service := gameserver.New(gameServerParams)
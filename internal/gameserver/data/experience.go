@@ -174,3 +174,36 @@ func LevelPenalty(playerLevel, npcLevel int) float64 {
 	}
 	return math.Pow(5.0/6.0, float64(diff-5))
 }
+
+// partyExpBonus is the group EXP/SP multiplier by number of rewarded members
+// (index = members-1), L2J HF L2Party.BONUS_EXP_SP.
+var partyExpBonus = [...]float64{1.0, 1.10, 1.20, 1.30, 1.40, 1.50, 2.0, 2.10, 2.20}
+
+// PartyExpBonus returns the group bonus multiplier applied to a party's pooled
+// EXP/SP before it is split (L2J L2Party.getBaseExpSpBonus). A lone member gets
+// 1.0; sizes past the table use its last entry.
+func PartyExpBonus(members int) float64 {
+	i := members - 1
+	if i < 1 {
+		return 1.0
+	}
+	if i >= len(partyExpBonus) {
+		i = len(partyExpBonus) - 1
+	}
+	return partyExpBonus[i]
+}
+
+// PartyLevelGapFactor returns the share of its party cut a member keeps when it is
+// gap levels below the party's highest rewarded member, mirroring the stock HF
+// "highfive" cutoff (PartyXpCutoffGaps 0-9;10-14;15+ → 100%;30%;0%). This stops a
+// low-level character from being power-levelled by a much stronger group.
+func PartyLevelGapFactor(gap int) float64 {
+	switch {
+	case gap <= 9:
+		return 1.0
+	case gap <= 14:
+		return 0.30
+	default:
+		return 0
+	}
+}
@@ -0,0 +1,21 @@
+package data
+
+import "testing"
+
+func TestPartyExpBonus(t *testing.T) {
+	cases := map[int]float64{0: 1.0, 1: 1.0, 2: 1.10, 3: 1.20, 7: 2.0, 9: 2.20, 12: 2.20}
+	for members, want := range cases {
+		if got := PartyExpBonus(members); got != want {
+			t.Errorf("PartyExpBonus(%d) = %v, want %v", members, got, want)
+		}
+	}
+}
+
+func TestPartyLevelGapFactor(t *testing.T) {
+	cases := map[int]float64{0: 1.0, 9: 1.0, 10: 0.30, 14: 0.30, 15: 0, 40: 0}
+	for gap, want := range cases {
+		if got := PartyLevelGapFactor(gap); got != want {
+			t.Errorf("PartyLevelGapFactor(%d) = %v, want %v", gap, got, want)
+		}
+	}
+}
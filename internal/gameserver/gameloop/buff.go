@@ -19,7 +19,8 @@ func isBuffSkill(skill *models.Skill) bool {
 
 // buildBuffFromSkill builds a runtime BuffInfo from a continuous skill's GENERAL/
 // SELF effects: Buff stat funcs become Mods; TickHp/TickMp/TickHpFatal become
-// periodic Ticks. Returns nil if the skill declares no buff-relevant effects.
+// periodic Ticks; SilentMove hides from aggressive monsters. Returns nil if the
+// skill declares no buff-relevant effects.
 func buildBuffFromSkill(skill *models.Skill, now time.Time) *models.BuffInfo {
 	b := &models.BuffInfo{
 		SkillID:      int32(skill.ID),
@@ -44,9 +45,11 @@ func buildBuffFromSkill(skill *models.Skill, now time.Time) *models.BuffInfo {
 			b.Ticks = append(b.Ticks, tickFromEffect(eff, models.TickHP, true))
 		case "TickMp":
 			b.Ticks = append(b.Ticks, tickFromEffect(eff, models.TickMP, false))
+		case "SilentMove":
+			b.SilentMove = true
 		}
 	}
-	if len(b.Mods) == 0 && len(b.Ticks) == 0 {
+	if len(b.Mods) == 0 && len(b.Ticks) == 0 && !b.SilentMove {
 		return nil
 	}
 
@@ -73,11 +76,15 @@ func tickFromEffect(eff models.SkillEffect, kind models.TickKind, fatal bool) mo
 }
 
 // applyBuff applies a continuous skill's effect to a player target, refreshing the
-// buff bar and stats. No-op if the target isn't a player or the skill declares no
-// buff effects. A stronger same-abnormalType buff already present blocks it.
+// buff bar and stats, or to an NPC. No-op if the target is neither or the skill
+// declares no buff effects. A stronger same-abnormalType buff already present
+// blocks it.
 func (gl *GameLoop) applyBuff(targetID int32, skill *models.Skill) {
 	target, ok := gl.world.GetPlayer(targetID)
 	if !ok || target.Character == nil {
+		if npc, isNPC := gl.world.GetNPC(targetID); isNPC {
+			gl.applyNPCBuff(npc, skill)
+		}
 		return
 	}
 	buff := buildBuffFromSkill(skill, time.Now())
@@ -93,6 +100,20 @@ func (gl *GameLoop) applyBuff(targetID int32, skill *models.Skill) {
 	gl.sendUserInfo(target)
 }
 
+// applyNPCBuff puts a continuous skill's effect on a live NPC, whose stats
+// (npcStats) carry its modifiers until it expires. NPC effects have no buff
+// bar, and their HoT/DoT ticks are not run.
+func (gl *GameLoop) applyNPCBuff(npc *models.NpcInstance, skill *models.Skill) {
+	if npc.IsDead {
+		return
+	}
+	buff := buildBuffFromSkill(skill, time.Now())
+	if buff == nil || !npc.Effects.Add(buff) {
+		return
+	}
+	gl.buffedNPCs[npc.ObjectID] = struct{}{}
+}
+
 // handleDispel cancels one of a player's active buffs (RequestDispel — the player
 // clicked a buff icon off). Reuses the buff-removal path.
 func (gl *GameLoop) handleDispel(cmd CmdDispel) {
@@ -124,9 +145,17 @@ func (gl *GameLoop) rebuildStatMods(player *registry.PlayerWorldState) {
 	player.RebuildStatMods()
 }
 
-// sendAbnormalStatus pushes the active-buff bar (icons + timers) to the player.
+// sendAbnormalStatus pushes the active-buff bar (icons + timers) to the player,
+// and mirrors the icons into the party window of the player's party members.
 func (gl *GameLoop) sendAbnormalStatus(player *registry.PlayerWorldState) {
-	now := time.Now()
+	buffs := abnormalBuffs(player, time.Now())
+	gl.sendToPlayer(player, outclient.BuildAbnormalStatusUpdate(buffs))
+	gl.broadcastPartySpelled(player, buffs)
+}
+
+// abnormalBuffs lists the player's active effects as buff-bar entries with their
+// remaining time at now.
+func abnormalBuffs(player *registry.PlayerWorldState, now time.Time) []outclient.AbnormalBuff {
 	buffs := make([]outclient.AbnormalBuff, 0, player.Effects.Len())
 	for _, b := range player.Effects.Buffs() {
 		remain := int32(-1) // infinite (toggle)
@@ -142,7 +171,7 @@ func (gl *GameLoop) sendAbnormalStatus(player *registry.PlayerWorldState) {
 			RemainSec:    remain,
 		})
 	}
-	gl.sendToPlayer(player, outclient.BuildAbnormalStatusUpdate(buffs))
+	return buffs
 }
 
 // sendUserInfo rebuilds and sends the player's own UserInfo (stats changed).
@@ -195,6 +224,19 @@ func (gl *GameLoop) serviceBuffs() {
 			}
 		}
 	}
+
+	// NPC effects only expire.
+	for objectID := range gl.buffedNPCs {
+		npc, ok := gl.world.GetNPC(objectID)
+		if !ok || npc.IsDead {
+			delete(gl.buffedNPCs, objectID)
+			continue
+		}
+		npc.Effects.RemoveExpired(now)
+		if npc.Effects.Len() == 0 {
+			delete(gl.buffedNPCs, objectID)
+		}
+	}
 }
 
 // fireBuffTicks applies one round of a buff's HoT/DoT ticks to a player.
@@ -231,6 +273,7 @@ func (gl *GameLoop) applyDoTToPlayer(player *registry.PlayerWorldState, dmg int)
 	})
 	gl.sendToPlayer(player, su)
 	gl.broadcastToTargeters(player.CharID, su)
+	gl.updatePartyStatus(player)
 }
 
 func maxInt(a, b int) int {
@@ -0,0 +1,86 @@
+package gameloop
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/rs/zerolog/log"
+
+	"github.com/VerTox/l2go/internal/gameserver/registry"
+)
+
+// BypassHandler runs one dialogue command (RequestBypassToServer) on the loop.
+// npcObjID is the NPC the command is addressed to: the objectId of an
+// "npc_<objectId>_" prefix, or else the player's target. arg is what follows
+// the command name. Handlers check for themselves that the player may use
+// that NPC.
+type BypassHandler func(gl *GameLoop, player *registry.PlayerWorldState, npcObjID int32, arg string)
+
+// bypassHandlers maps lower-cased command names to their handlers. Filled from
+// init() by the files that own the commands, before the loop starts.
+var bypassHandlers = make(map[string]BypassHandler)
+
+// RegisterBypass adds the handler for command name, matched case-insensitively
+// so both "Chat" and "chat" links reach it. Panics on a duplicate, like the
+// packet registry, so a clash surfaces in tests. Call it from init().
+func RegisterBypass(name string, h BypassHandler) {
+	key := strings.ToLower(name)
+	if _, exists := bypassHandlers[key]; exists {
+		panic(fmt.Sprintf("duplicate bypass handler: %q", name))
+	}
+	bypassHandlers[key] = h
+}
+
+// handleBypass routes a dialogue command to its registered handler. Commands
+// look like "<name> <arg>", optionally prefixed with "npc_<objectId>_" (L2J
+// html links use "npc_%objectId%_Chat 1").
+func (gl *GameLoop) handleBypass(cmd CmdBypass) {
+	player, ok := gl.world.GetPlayer(cmd.CharID)
+	if !ok || player.Character == nil {
+		return
+	}
+	name, arg, npcObjID, ok := parseBypass(cmd.Command)
+	if !ok {
+		log.Debug().Int32("char_id", cmd.CharID).Str("cmd", cmd.Command).Msg("malformed bypass command")
+		return
+	}
+	if npcObjID == 0 {
+		npcObjID = player.TargetID
+	}
+	h, ok := bypassHandlers[strings.ToLower(name)]
+	if !ok {
+		log.Debug().Int32("char_id", cmd.CharID).Str("cmd", cmd.Command).Msg("unhandled bypass command")
+		return
+	}
+	h(gl, player, npcObjID, arg)
+}
+
+// parseBypass splits a bypass into its command name, argument and, when the
+// "npc_<objectId>_" prefix is present, the addressed NPC.
+func parseBypass(command string) (name, arg string, npcObjID int32, ok bool) {
+	command = strings.TrimSpace(command)
+	if rest, found := strings.CutPrefix(command, "npc_"); found {
+		id, tail, found := strings.Cut(rest, "_")
+		objID, err := strconv.ParseInt(id, 10, 32)
+		if !found || err != nil || objID <= 0 {
+			return "", "", 0, false
+		}
+		npcObjID, command = int32(objID), tail
+	}
+	name, arg, _ = strings.Cut(command, " ")
+	if name == "" {
+		return "", "", 0, false
+	}
+	return name, strings.TrimSpace(arg), npcObjID, true
+}
+
+// bypassListID parses the list id argument of the shop and multisell commands.
+func bypassListID(arg string) (int32, bool) {
+	id, err := strconv.ParseInt(arg, 10, 32)
+	if err != nil {
+		log.Debug().Str("arg", arg).Msg("bad list id in bypass")
+		return 0, false
+	}
+	return int32(id), true
+}
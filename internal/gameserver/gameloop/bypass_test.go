@@ -0,0 +1,130 @@
+package gameloop
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/VerTox/l2go/internal/gameserver/models"
+	"github.com/VerTox/l2go/internal/gameserver/registry"
+)
+
+// loadTestHtml loads the given pages into the global html registry for the
+// duration of the test.
+func loadTestHtml(t *testing.T, pages map[string]string) {
+	t.Helper()
+	dir := t.TempDir()
+	for name, data := range pages {
+		p := filepath.Join(dir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(p), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(p, []byte(data), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := registry.GetHtmlRegistry().LoadFromDirectory(dir); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = registry.GetHtmlRegistry().LoadFromDirectory(t.TempDir()) })
+}
+
+func TestParseBypass(t *testing.T) {
+	for _, tc := range []struct {
+		in        string
+		name, arg string
+		npc       int32
+		ok        bool
+	}{
+		{in: "learn_skills", name: "learn_skills", ok: true},
+		{in: "buy 3030101", name: "buy", arg: "3030101", ok: true},
+		{in: "npc_2000_Chat 1", name: "Chat", arg: "1", npc: 2000, ok: true},
+		{in: " npc_2000_multisell  100 ", name: "multisell", arg: "100", npc: 2000, ok: true},
+		{in: "npc_x_Chat 1"},
+		{in: "npc_2000"},
+		{in: ""},
+	} {
+		name, arg, npc, ok := parseBypass(tc.in)
+		if name != tc.name || arg != tc.arg || npc != tc.npc || ok != tc.ok {
+			t.Errorf("parseBypass(%q) = %q, %q, %d, %v", tc.in, name, arg, npc, ok)
+		}
+	}
+}
+
+func TestBypass_RoutesThroughTable(t *testing.T) {
+	gl, p := newTestLoopWithPlayer(t)
+	p.TargetID = 2000
+
+	type call struct {
+		npc int32
+		arg string
+	}
+	var calls []call
+	RegisterBypass("test_cmd", func(_ *GameLoop, player *registry.PlayerWorldState, npcObjID int32, arg string) {
+		calls = append(calls, call{npcObjID, arg})
+	})
+	t.Cleanup(func() { delete(bypassHandlers, "test_cmd") })
+
+	gl.handleBypass(CmdBypass{CharID: 7, Command: "test_cmd a b"})
+	gl.handleBypass(CmdBypass{CharID: 7, Command: "npc_2001_Test_Cmd"})
+	gl.handleBypass(CmdBypass{CharID: 7, Command: "unknown 1"})
+	if len(calls) != 2 || calls[0] != (call{2000, "a b"}) || calls[1] != (call{2001, ""}) {
+		t.Fatalf("calls = %+v", calls)
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Fatal("a duplicate bypass must panic")
+		}
+	}()
+	RegisterBypass("TEST_CMD", func(*GameLoop, *registry.PlayerWorldState, int32, string) {})
+}
+
+func TestNpcDialog_PagesFromDatapack(t *testing.T) {
+	loadTestHtml(t, map[string]string{
+		"merchant/30301.htm":  `<a action="bypass -h npc_%objectId%_Chat 1">%npcname% greets %playername%</a>`,
+		"default/30301-1.htm": "page one of %npcId%",
+	})
+	gl, p := newTestLoopWithPlayer(t)
+	addMerchant(gl, 2000, 30301, models.Position{X: 100})
+	addMerchant(gl, 2001, 30315, models.Position{X: 100})
+
+	npc, _ := gl.world.GetNPC(2000)
+	if html := NpcDialogHtml(p, npc); html != `<a action="bypass -h npc_2000_Chat 1">Hally greets Tester</a>` {
+		t.Fatalf("dialogue = %q", html)
+	}
+	// Chat pages fall back to the default directory.
+	if page, ok := npcHtmlPage(npc, 1); !ok || renderNpcHtml(page, p, npc) != "page one of 30301" {
+		t.Fatalf("page 1 = %q, %v", page, ok)
+	}
+	if _, ok := npcHtmlPage(npc, 2); ok {
+		t.Fatal("page 2 does not exist")
+	}
+
+	// Without a page the generated dialogue stays.
+	other, _ := gl.world.GetNPC(2001)
+	if html := NpcDialogHtml(p, other); !strings.Contains(html, "&$1536;") {
+		t.Fatalf("dialogue = %q", html)
+	}
+}
+
+func TestDialogNpc_NeedsLivingNpcInReach(t *testing.T) {
+	gl, p := newTestLoopWithPlayer(t)
+	addMerchant(gl, 2000, 30301, models.Position{X: 100})
+	addMerchant(gl, 2001, 30301, models.Position{X: 1000})
+	addAttackableNPC(gl, 2002, models.Position{X: 100})
+
+	if _, ok := gl.dialogNpc(p, 2000); !ok {
+		t.Fatal("the merchant in reach must talk")
+	}
+	for _, id := range []int32{2001, 2002, 3000} {
+		if _, ok := gl.dialogNpc(p, id); ok {
+			t.Fatalf("npc %d must not talk", id)
+		}
+	}
+	p.Character.CurrentHP = 0
+	if _, ok := gl.dialogNpc(p, 2000); ok {
+		t.Fatal("a dead player talks to no one")
+	}
+}
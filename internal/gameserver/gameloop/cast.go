@@ -87,13 +87,23 @@ func (gl *GameLoop) handleCastRequest(cmd CmdCastRequest) {
 	// In range (arrived or already close): drop any pending approach for this caster.
 	delete(gl.castPending, cmd.CasterCharID)
 
+	// Line of sight (L2J useMagic's canSeeTarget check): no casting through walls.
+	if target != caster.CharID && !gl.geo.CanSeeTarget(caster.Position, gl.objectPosition(target, caster.Position)) {
+		gl.sendToPlayer(caster, outclient.BuildSystemMessageNoParams(outclient.SysMsgCantSeeTarget))
+		if conn := gl.connections.GetConnection(caster.AccountName); conn != nil {
+			_ = conn.Send(outclient.BuildActionFailed())
+		}
+		return
+	}
+
 	// PvP gate: an offensive skill on another player must pass checkPvpSkill
-	// (flag/karma/ctrl). Blocked → INCORRECT_TARGET + ActionFailed, no cast.
+	// (zones, then flag/karma/ctrl). Blocked → the refusal message + ActionFailed,
+	// no cast.
 	if isOffensiveSkill(skill) && target != caster.CharID {
 		if tgt, isPlayer := gl.world.GetPlayer(target); isPlayer {
-			allowed, flagAttacker := canAttackPlayer(tgt, cmd.CtrlPressed, time.Now())
+			allowed, flagAttacker, refusal := gl.checkPvPAttack(caster, tgt, cmd.CtrlPressed, time.Now())
 			if !allowed {
-				gl.sendToPlayer(caster, outclient.BuildSystemMessageNoParams(outclient.SysMsgIncorrectTarget))
+				gl.sendToPlayer(caster, outclient.BuildSystemMessageNoParams(refusal))
 				if conn := gl.connections.GetConnection(caster.AccountName); conn != nil {
 					_ = conn.Send(outclient.BuildActionFailed())
 				}
@@ -182,7 +192,14 @@ func (gl *GameLoop) castTime(caster *registry.PlayerWorldState, skill *models.Sk
 	if skill.HitTime <= 0 {
 		return 0
 	}
-	stats := gl.computePlayerStats(caster)
+	return scaledCastTime(skill, gl.computePlayerStats(caster))
+}
+
+// scaledCastTime is the castTime formula for a caster with the given stats.
+func scaledCastTime(skill *models.Skill, stats models.ComputedStats) time.Duration {
+	if skill.HitTime <= 0 {
+		return 0
+	}
 	speed := stats.MAtkSpd
 	if !skill.IsMagic() {
 		speed = stats.PAtkSpd
@@ -326,32 +343,18 @@ func (gl *GameLoop) applySkillEffects(caster *registry.PlayerWorldState, targetI
 		return
 	}
 
-	var hp, mp, cp, magicPower, physPower, drainPower int
 	for _, eff := range skill.Effects {
-		if eff.Scope != models.ScopeGeneral && eff.Scope != models.ScopeSelf {
-			continue
-		}
-		switch eff.Name {
-		case "Escape":
+		if eff.Name == "Escape" && (eff.Scope == models.ScopeGeneral || eff.Scope == models.ScopeSelf) {
 			// Scroll of Escape and kin: teleport the caster (stop-gap — instant, no
 			// 20s channel; the full interruptible cast comes with the skill engine,
 			// l2go-2w8). Terminal: nothing else applies. (l2go-kg9)
 			gl.applyEscape(caster, eff.Params["escapeType"])
 			return
-		case "Heal", "Hp", "HealPercent":
-			hp += effectPower(eff)
-		case "ManaHeal", "Mp", "ManaHealPercent":
-			mp += effectPower(eff)
-		case "Cp", "CpHeal", "CpHealPercent":
-			cp += effectPower(eff)
-		case "MagicalAttack", "MagicalAttackRange", "MagicalAttackMp", "MagicalAttackByAbnormal":
-			magicPower += effectPower(eff)
-		case "PhysicalAttack", "PhysicalAttackHpLink", "PhysicalAttackMute":
-			physPower += effectPower(eff)
-		case "HpDrain", "DeathLink":
-			drainPower += effectPower(eff)
 		}
 	}
+	pw := skillPowersOf(skill)
+	hp, mp, cp := pw.hp, pw.mp, pw.cp
+	magicPower, physPower, drainPower := pw.magic, pw.phys, pw.drain
 
 	// Restore effects target a player (self or friendly). SkillID 0 → no extra cast
 	// visual (we already sent MagicSkillUse).
@@ -404,14 +407,15 @@ func (gl *GameLoop) applySkillEffects(caster *registry.PlayerWorldState, targetI
 	if !isNPC || npc.IsDead || npc.Template == nil {
 		return
 	}
+	defStats := npcStats(npc)
 	if magicPower > 0 {
-		gl.dealSkillDamageToNPC(caster, npc, calcMagicDamage(float64(casterStats.MAtk), npc.Template.MDef, magicPower))
+		gl.dealSkillDamageToNPC(caster, npc, calcMagicDamage(float64(casterStats.MAtk), float64(defStats.MDef), magicPower))
 	}
 	if physPower > 0 {
-		gl.dealSkillDamageToNPC(caster, npc, calcPhysSkillDamage(casterStats.PAtk, int(npc.Template.PDef), physPower))
+		gl.dealSkillDamageToNPC(caster, npc, calcPhysSkillDamage(casterStats.PAtk, defStats.PDef, physPower))
 	}
 	if drainPower > 0 {
-		dmg := calcMagicDamage(float64(casterStats.MAtk), npc.Template.MDef, drainPower)
+		dmg := calcMagicDamage(float64(casterStats.MAtk), float64(defStats.MDef), drainPower)
 		gl.dealSkillDamageToNPC(caster, npc, dmg)
 		// Drain: heal the caster for half the damage dealt (L2J absorbs a share).
 		if caster.Character.CurrentHP > 0 {
@@ -420,6 +424,37 @@ func (gl *GameLoop) applySkillEffects(caster *registry.PlayerWorldState, targetI
 	}
 }
 
+// skillPowers sums the instant effect powers of a skill by kind.
+type skillPowers struct {
+	hp, mp, cp         int // restored
+	magic, phys, drain int // damage
+}
+
+// skillPowersOf sums the powers of a skill's GENERAL/SELF instant effects.
+func skillPowersOf(skill *models.Skill) skillPowers {
+	var pw skillPowers
+	for _, eff := range skill.Effects {
+		if eff.Scope != models.ScopeGeneral && eff.Scope != models.ScopeSelf {
+			continue
+		}
+		switch eff.Name {
+		case "Heal", "Hp", "HealPercent":
+			pw.hp += effectPower(eff)
+		case "ManaHeal", "Mp", "ManaHealPercent":
+			pw.mp += effectPower(eff)
+		case "Cp", "CpHeal", "CpHealPercent":
+			pw.cp += effectPower(eff)
+		case "MagicalAttack", "MagicalAttackRange", "MagicalAttackMp", "MagicalAttackByAbnormal":
+			pw.magic += effectPower(eff)
+		case "PhysicalAttack", "PhysicalAttackHpLink", "PhysicalAttackMute":
+			pw.phys += effectPower(eff)
+		case "HpDrain", "DeathLink":
+			pw.drain += effectPower(eff)
+		}
+	}
+	return pw
+}
+
 // dealSkillDamageToNPC applies skill damage to an NPC and reports it to the caster.
 func (gl *GameLoop) dealSkillDamageToNPC(caster *registry.PlayerWorldState, npc *models.NpcInstance, damage int) {
 	gl.dealDamageToNPC(npc, caster.CharID, damage)
@@ -44,7 +44,7 @@ func (gl *GameLoop) resolveCombatTarget(objectID int32) (*combatTarget, bool) {
 		pDef, evasion := 10, 20
 		var coll float64
 		if npc.Template != nil {
-			pDef = int(npc.Template.PDef)
+			pDef = npcStats(npc).PDef
 			if pDef < 1 {
 				pDef = 1
 			}
@@ -74,6 +74,7 @@ func (gl *GameLoop) startMoveToTargetPos(player *registry.PlayerWorldState, targ
 	if dest == player.Position {
 		return
 	}
+	delete(gl.playerPaths, player.CharID)
 	player.IsMoving = true
 	player.MoveStartPos = player.Position
 	player.MoveDestination = dest
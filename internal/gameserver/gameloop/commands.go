@@ -1,6 +1,10 @@
 package gameloop
 
-import "github.com/VerTox/l2go/internal/gameserver/models"
+import (
+	"github.com/VerTox/l2go/internal/gameserver/models"
+	"github.com/VerTox/l2go/internal/gameserver/registry"
+	"github.com/VerTox/l2go/internal/gameserver/usecase"
+)
 
 // Command is sent from client handler goroutines to the game loop.
 type Command interface {
@@ -83,8 +87,12 @@ type CmdCancelAttack struct {
 func (CmdCancelAttack) commandMarker() {}
 
 // CmdPlayerDisconnected — player disconnected from the game.
+// OfflineTrade asks to keep the player in the world as an offline store keeper:
+// only the client-bound state is dropped. If its store has closed meanwhile the
+// loop logs the player out itself, since the handler left it in the world.
 type CmdPlayerDisconnected struct {
-	CharID int32
+	CharID       int32
+	OfflineTrade bool
 }
 
 func (CmdPlayerDisconnected) commandMarker() {}
@@ -108,8 +116,12 @@ func (CmdPlayerMoved) commandMarker() {}
 
 // CmdMoveToLocation — player issued a ground move (clicked the ground). Cancels any
 // attack/interact intention so the loop stops chasing the previous target.
+// FindPath is set when the straight walk to Dest runs into a wall: the loop then
+// walks the player round it.
 type CmdMoveToLocation struct {
-	CharID int32
+	CharID   int32
+	Dest     models.Position
+	FindPath bool
 }
 
 func (CmdMoveToLocation) commandMarker() {}
@@ -173,3 +185,394 @@ type CmdRevive struct {
 }
 
 func (CmdRevive) commandMarker() {}
+
+// CmdPartyInvite — player invited another player (by name) to its party
+// (RequestJoinParty). LootType is the mode proposed if a new party is formed.
+type CmdPartyInvite struct {
+	CharID     int32
+	TargetName string
+	LootType   int32
+}
+
+func (CmdPartyInvite) commandMarker() {}
+
+// CmdPartyAnswer — the invitee answered a pending party invitation
+// (RequestAnswerJoinParty).
+type CmdPartyAnswer struct {
+	CharID int32
+	Accept bool
+}
+
+func (CmdPartyAnswer) commandMarker() {}
+
+// CmdPartyLeave — player left its party voluntarily (RequestWithDrawalParty).
+type CmdPartyLeave struct {
+	CharID int32
+}
+
+func (CmdPartyLeave) commandMarker() {}
+
+// CmdPartyKick — the leader expelled a member by name (RequestOustPartyMember).
+type CmdPartyKick struct {
+	CharID     int32
+	TargetName string
+}
+
+func (CmdPartyKick) commandMarker() {}
+
+// CmdPartyChangeLeader — the leader handed leadership to a member by name
+// (RequestChangePartyLeader).
+type CmdPartyChangeLeader struct {
+	CharID     int32
+	TargetName string
+}
+
+func (CmdPartyChangeLeader) commandMarker() {}
+
+// CmdPartyLootChange — the leader asked the party to approve a new loot mode
+// (RequestPartyLootModification).
+type CmdPartyLootChange struct {
+	CharID   int32
+	LootType int32
+}
+
+func (CmdPartyLootChange) commandMarker() {}
+
+// CmdPartyLootAnswer — a member voted on the pending loot-mode change
+// (AnswerPartyLootModification).
+type CmdPartyLootAnswer struct {
+	CharID int32
+	Accept bool
+}
+
+func (CmdPartyLootAnswer) commandMarker() {}
+
+// CmdTradeRequest — player asked another player (by object id) to trade
+// (TradeRequest).
+type CmdTradeRequest struct {
+	CharID         int32
+	TargetObjectID int32
+}
+
+func (CmdTradeRequest) commandMarker() {}
+
+// CmdTradeAnswer — the asked player answered a pending trade request
+// (AnswerTradeRequest).
+type CmdTradeAnswer struct {
+	CharID int32
+	Accept bool
+}
+
+func (CmdTradeAnswer) commandMarker() {}
+
+// CmdTradeAddItem — player put Count units of an item into its trade window
+// (AddTradeItem). Item is the handler-validated inventory row; its Count is what
+// the player owns, which caps the total offered.
+type CmdTradeAddItem struct {
+	CharID int32
+	Item   models.CharacterItem
+	Count  int64
+}
+
+func (CmdTradeAddItem) commandMarker() {}
+
+// CmdTradeDone — player pressed OK (Confirm) or Cancel in the trade window
+// (TradeDone).
+type CmdTradeDone struct {
+	CharID  int32
+	Confirm bool
+}
+
+func (CmdTradeDone) commandMarker() {}
+
+// CmdSitStand — player toggled sit/stand (RequestActionUse action 0).
+type CmdSitStand struct {
+	CharID int32
+}
+
+func (CmdSitStand) commandMarker() {}
+
+// CmdPrivateStoreManage — player asked for a private store setup window
+// (RequestPrivateStoreManageSell/Buy or the store actions 10, 28 and 61).
+// Asking for the side that is already open closes that store first.
+type CmdPrivateStoreManage struct {
+	CharID  int32
+	Buy     bool
+	Package bool
+}
+
+func (CmdPrivateStoreManage) commandMarker() {}
+
+// CmdPrivateStoreSetSell — player submitted the sell list (SetPrivateStoreListSell).
+// Items are inventory stacks the handler already checked for ownership, count
+// and tradeability; an empty list closes the store.
+type CmdPrivateStoreSetSell struct {
+	CharID  int32
+	Package bool
+	Items   []registry.StoreItem
+}
+
+func (CmdPrivateStoreSetSell) commandMarker() {}
+
+// CmdPrivateStoreSetBuy — player submitted the buy list (SetPrivateStoreListBuy).
+// Items name the wanted ItemID/EnchantLevel; the handler checked the templates
+// and that the owner can pay for everything. An empty list closes the store.
+type CmdPrivateStoreSetBuy struct {
+	CharID int32
+	Items  []registry.StoreItem
+}
+
+func (CmdPrivateStoreSetBuy) commandMarker() {}
+
+// StoreMsgKind selects which store title a CmdPrivateStoreMsg sets.
+type StoreMsgKind int
+
+const (
+	StoreMsgSell StoreMsgKind = iota
+	StoreMsgBuy
+	// StoreMsgWhole is the package sale title (SetPrivateStoreWholeMsg); it
+	// shares the sell list's title as in L2J.
+	StoreMsgWhole
+)
+
+// CmdPrivateStoreMsg — player set a store title (SetPrivateStoreMsgSell/Buy,
+// SetPrivateStoreWholeMsg).
+type CmdPrivateStoreMsg struct {
+	CharID  int32
+	Kind    StoreMsgKind
+	Message string
+}
+
+func (CmdPrivateStoreMsg) commandMarker() {}
+
+// CmdPrivateStoreQuit — player closed its store (RequestPrivateStoreQuitSell/Buy).
+type CmdPrivateStoreQuit struct {
+	CharID int32
+}
+
+func (CmdPrivateStoreQuit) commandMarker() {}
+
+// CmdPrivateStoreBuy — player buys from a sell store (RequestPrivateStoreBuy).
+// Each line names a listed stack by Item.ObjectID with the count and the unit
+// price the buyer saw.
+type CmdPrivateStoreBuy struct {
+	CharID      int32
+	StoreCharID int32
+	Items       []registry.StoreItem
+}
+
+func (CmdPrivateStoreBuy) commandMarker() {}
+
+// CmdPrivateStoreSell — player sells into a buy store (RequestPrivateStoreSell).
+// Each line is one of the seller's own stacks, validated by the handler, with
+// the count and the unit price the seller saw.
+type CmdPrivateStoreSell struct {
+	CharID      int32
+	StoreCharID int32
+	Items       []registry.StoreItem
+}
+
+func (CmdPrivateStoreSell) commandMarker() {}
+
+// CmdPrivateStoreDealDone — the store worker's outcome for a StoreJobDeal: on
+// failure the reserved lines go back into the store, on success an emptied
+// store closes.
+type CmdPrivateStoreDealDone struct {
+	StoreCharID int32
+	Seq         int64
+	BuyList     bool
+	Lines       []registry.StoreItem
+	OK          bool
+}
+
+func (CmdPrivateStoreDealDone) commandMarker() {}
+
+// CmdOfflineTradeRestore — startup put an offline store keeper back into the
+// world (the handler already added it to the WorldRegistry). Store is its
+// persisted store, re-validated against the keeper's inventory.
+type CmdOfflineTradeRestore struct {
+	CharID int32
+	Store  registry.PrivateStore
+}
+
+func (CmdOfflineTradeRestore) commandMarker() {}
+
+// CmdWarehouseOpen — player picked a warehouse link in a keeper's dialogue
+// (bypass): show the deposit or withdrawal window of its own or its clan's
+// warehouse.
+type CmdWarehouseOpen struct {
+	CharID   int32
+	NpcObjID int32
+	Clan     bool
+	Deposit  bool
+}
+
+func (CmdWarehouseOpen) commandMarker() {}
+
+// CmdWarehouseTransfer — player confirmed the open warehouse window
+// (SendWareHouseDepositList / SendWareHouseWithDrawList).
+type CmdWarehouseTransfer struct {
+	CharID  int32
+	Deposit bool
+	Items   []usecase.ItemTransfer
+}
+
+func (CmdWarehouseTransfer) commandMarker() {}
+
+// CmdShopOpen — player picked a buy/sell link in a merchant's dialogue
+// (bypass): show the shop window of buy list ListID.
+type CmdShopOpen struct {
+	CharID   int32
+	NpcObjID int32
+	ListID   int32
+}
+
+func (CmdShopOpen) commandMarker() {}
+
+// CmdShopBuy — player confirmed purchases in the shop window (RequestBuyItem).
+type CmdShopBuy struct {
+	CharID int32
+	ListID int32
+	Items  []registry.BuyListLine
+}
+
+func (CmdShopBuy) commandMarker() {}
+
+// CmdShopSell — player confirmed sales in the shop window (RequestSellItem).
+type CmdShopSell struct {
+	CharID int32
+	ListID int32
+	Items  []usecase.ShopSale
+}
+
+func (CmdShopSell) commandMarker() {}
+
+// CmdShopRefund — player bought back refund list lines (RequestRefundItem).
+type CmdShopRefund struct {
+	CharID  int32
+	ListID  int32
+	Indexes []int32
+}
+
+func (CmdShopRefund) commandMarker() {}
+
+// CmdMultisellOpen — player followed a "multisell <id>" link in an NPC's
+// dialogue (bypass): show the list.
+type CmdMultisellOpen struct {
+	CharID   int32
+	NpcObjID int32
+	ListID   int32
+}
+
+func (CmdMultisellOpen) commandMarker() {}
+
+// CmdMultisellChoose — player picked an exchange in the open multisell window
+// (MultiSellChoose).
+type CmdMultisellChoose struct {
+	CharID  int32
+	ListID  int32
+	EntryID int32
+	Amount  int64
+}
+
+func (CmdMultisellChoose) commandMarker() {}
+
+// CmdBypass — player followed a "bypass" link in an NPC dialogue
+// (RequestBypassToServer); routed through the bypass table.
+type CmdBypass struct {
+	CharID  int32
+	Command string
+}
+
+func (CmdBypass) commandMarker() {}
+
+// CmdLinkHtml — player followed a "link" in an NPC dialogue (RequestLinkHtml):
+// show that page of the datapack html on behalf of the targeted NPC.
+type CmdLinkHtml struct {
+	CharID int32
+	Link   string
+}
+
+func (CmdLinkHtml) commandMarker() {}
+
+// CmdQuestList — player opened the quest journal (RequestQuestList): send
+// QuestList.
+type CmdQuestList struct {
+	CharID int32
+}
+
+func (CmdQuestList) commandMarker() {}
+
+// CmdQuestAbort — player gave up a quest from the quest journal
+// (RequestQuestAbort).
+type CmdQuestAbort struct {
+	CharID  int32
+	QuestID int32
+}
+
+func (CmdQuestAbort) commandMarker() {}
+
+// CmdQuestionMark — player clicked a quest's question mark
+// (RequestTutorialQuestionMark).
+type CmdQuestionMark struct {
+	CharID int32
+	Mark   int32
+}
+
+func (CmdQuestionMark) commandMarker() {}
+
+// CmdPickUp — player clicked an item on the ground (Action on a ground item).
+type CmdPickUp struct {
+	CharID   int32
+	ObjectID int32
+}
+
+func (CmdPickUp) commandMarker() {}
+
+// CmdPickUpDone — the ground item worker's outcome for a GroundItemPickUp: on
+// success the item leaves the ground, on failure it is free to pick up again.
+type CmdPickUpDone struct {
+	CharID   int32
+	PickerID int32
+	ObjectID int32
+	OK       bool
+}
+
+func (CmdPickUpDone) commandMarker() {}
+
+// CmdDropItem — player dropped an inventory item at Position (RequestDropItem).
+type CmdDropItem struct {
+	CharID   int32
+	ObjectID int32
+	Count    int64
+	Position models.Position
+}
+
+func (CmdDropItem) commandMarker() {}
+
+// CmdDestroyItem — player destroyed an inventory item (RequestDestroyItem).
+type CmdDestroyItem struct {
+	CharID   int32
+	ObjectID int32
+	Count    int64
+}
+
+func (CmdDestroyItem) commandMarker() {}
+
+// CmdCrystallizeItem — player crystallized an inventory item
+// (RequestCrystallizeItem).
+type CmdCrystallizeItem struct {
+	CharID   int32
+	ObjectID int32
+}
+
+func (CmdCrystallizeItem) commandMarker() {}
+
+// CmdItemDropped — the discard worker moved a dropped item's row to the
+// ground; the loop shows it lying there.
+type CmdItemDropped struct {
+	CharID int32
+	Item   models.GroundItem
+}
+
+func (CmdItemDropped) commandMarker() {}
@@ -0,0 +1,156 @@
+package gameloop
+
+import (
+	"github.com/rs/zerolog/log"
+
+	"github.com/VerTox/l2go/internal/gameserver/models"
+	"github.com/VerTox/l2go/internal/gameserver/packets/outclient"
+	"github.com/VerTox/l2go/internal/gameserver/registry"
+)
+
+const (
+	// dropItemRange is how far from themselves a player may drop an item
+	// (L2J RequestDropItem, 150 units, and no more than dropItemHeight
+	// above or below).
+	dropItemRange  = 150
+	dropItemHeight = 50
+	// skillCrystallize is the skill whose level caps the grade a player may
+	// crystallize (L2J CommonSkill.CRYSTALLIZE).
+	skillCrystallize = 248
+)
+
+// DiscardJobKind is what a DiscardJob does with the item.
+type DiscardJobKind int
+
+const (
+	// DiscardDrop moves the item to the ground; the worker reports back
+	// with CmdItemDropped.
+	DiscardDrop DiscardJobKind = iota
+	// DiscardDestroy deletes the item.
+	DiscardDestroy
+	// DiscardCrystallize breaks the item into crystals of its grade.
+	DiscardCrystallize
+)
+
+// DiscardJob is an inventory item the loop cleared to be dropped, destroyed
+// or crystallized. The item checks need the database and run on the discard
+// worker.
+type DiscardJob struct {
+	Kind     DiscardJobKind
+	CharID   int32
+	ObjectID int32
+	Count    int64
+	// Position is where a dropped item lands.
+	Position models.Position
+	// CrystallizeLevel is the player's Crystallize skill level.
+	CrystallizeLevel int32
+}
+
+// SetDiscardSink wires the channel that receives discard jobs. Kept out of
+// New() like the other optional sinks; without it nothing can be dropped,
+// destroyed or crystallized.
+func (gl *GameLoop) SetDiscardSink(sink chan<- DiscardJob) {
+	gl.discardSink = sink
+}
+
+// enqueueDiscardJob hands a job to the discard worker. Non-blocking: with no
+// sink or a full queue the job is dropped.
+func (gl *GameLoop) enqueueDiscardJob(job DiscardJob) {
+	if gl.discardSink == nil {
+		return
+	}
+	select {
+	case gl.discardSink <- job:
+	default:
+		log.Warn().Int32("char_id", job.CharID).Int32("obj_id", job.ObjectID).Msg("discard sink full, dropping discard job")
+	}
+}
+
+// discardingPlayer returns the player if they may part with an item right
+// now: alive, and neither trading nor running a store (L2J
+// CANNOT_TRADE_DISCARD_DROP_ITEM_WHILE_IN_SHOPMODE).
+func (gl *GameLoop) discardingPlayer(charID int32) (*registry.PlayerWorldState, bool) {
+	player, ok := gl.world.GetPlayer(charID)
+	if !ok || player.Character == nil {
+		return nil, false
+	}
+	if player.Character.CurrentHP <= 0 {
+		gl.sendToPlayer(player, outclient.BuildActionFailed())
+		return nil, false
+	}
+	if gl.trades[charID] != nil || player.PrivateStore.Type != registry.StoreNone {
+		gl.sendToPlayer(player, outclient.BuildSystemMessageNoParams(outclient.SysMsgCannotDiscardInShopMode))
+		gl.sendToPlayer(player, outclient.BuildActionFailed())
+		return nil, false
+	}
+	return player, true
+}
+
+// handleDropItem checks where the player wants to drop an item and hands the
+// drop to the worker.
+func (gl *GameLoop) handleDropItem(cmd CmdDropItem) {
+	player, ok := gl.discardingPlayer(cmd.CharID)
+	if !ok {
+		return
+	}
+	dx := int64(player.Position.X - cmd.Position.X)
+	dy := int64(player.Position.Y - cmd.Position.Y)
+	dz := player.Position.Z - cmd.Position.Z
+	if dx*dx+dy*dy > dropItemRange*dropItemRange || dz > dropItemHeight || dz < -dropItemHeight {
+		gl.sendToPlayer(player, outclient.BuildSystemMessageNoParams(outclient.SysMsgTargetTooFar))
+		gl.sendToPlayer(player, outclient.BuildActionFailed())
+		return
+	}
+	gl.enqueueDiscardJob(DiscardJob{
+		Kind:     DiscardDrop,
+		CharID:   cmd.CharID,
+		ObjectID: cmd.ObjectID,
+		Count:    cmd.Count,
+		Position: cmd.Position,
+	})
+}
+
+// handleDestroyItem hands an item the player wants destroyed to the worker.
+func (gl *GameLoop) handleDestroyItem(cmd CmdDestroyItem) {
+	if _, ok := gl.discardingPlayer(cmd.CharID); !ok {
+		return
+	}
+	gl.enqueueDiscardJob(DiscardJob{
+		Kind:     DiscardDestroy,
+		CharID:   cmd.CharID,
+		ObjectID: cmd.ObjectID,
+		Count:    cmd.Count,
+	})
+}
+
+// handleCrystallizeItem hands an item to crystallize to the worker along with
+// the player's Crystallize level; without the skill nothing can be
+// crystallized.
+func (gl *GameLoop) handleCrystallizeItem(cmd CmdCrystallizeItem) {
+	player, ok := gl.discardingPlayer(cmd.CharID)
+	if !ok {
+		return
+	}
+	level := player.KnownSkills[skillCrystallize]
+	if level <= 0 {
+		gl.sendToPlayer(player, outclient.BuildSystemMessageNoParams(outclient.SysMsgCrystallizeLevelTooLow))
+		gl.sendToPlayer(player, outclient.BuildActionFailed())
+		return
+	}
+	gl.enqueueDiscardJob(DiscardJob{
+		Kind:             DiscardCrystallize,
+		CharID:           cmd.CharID,
+		ObjectID:         cmd.ObjectID,
+		Count:            1,
+		CrystallizeLevel: level,
+	})
+}
+
+// handleItemDropped puts an item a player dropped on the ground. A player's
+// drop is free for anyone to pick up.
+func (gl *GameLoop) handleItemDropped(cmd CmdItemDropped) {
+	item := cmd.Item
+	item.DropperID = cmd.CharID
+	item.OwnerID = 0
+	gl.spawnGroundItem(&item)
+}
@@ -0,0 +1,67 @@
+package gameloop
+
+import (
+	"testing"
+
+	"github.com/VerTox/l2go/internal/gameserver/models"
+	"github.com/VerTox/l2go/internal/gameserver/registry"
+)
+
+func TestDropItem_Checks(t *testing.T) {
+	gl, p := newTestLoopWithPlayer(t)
+	sink := make(chan DiscardJob, 4)
+	gl.SetDiscardSink(sink)
+
+	// Too far away, or too far below, is refused.
+	for _, pos := range []models.Position{{X: 200}, {X: 10, Z: -80}} {
+		gl.handleDropItem(CmdDropItem{CharID: 7, ObjectID: 100, Count: 1, Position: pos})
+		if len(sink) != 0 {
+			t.Fatalf("drop at %+v accepted", pos)
+		}
+	}
+
+	gl.handleDropItem(CmdDropItem{CharID: 7, ObjectID: 100, Count: 3, Position: models.Position{X: 40, Y: 40}})
+	job := <-sink
+	if job.Kind != DiscardDrop || job.ObjectID != 100 || job.Count != 3 || job.Position.X != 40 {
+		t.Fatalf("drop job = %+v", job)
+	}
+
+	// Nothing leaves a player who runs a store.
+	p.PrivateStore.Type = registry.StoreSell
+	gl.handleDropItem(CmdDropItem{CharID: 7, ObjectID: 100, Count: 1})
+	gl.handleDestroyItem(CmdDestroyItem{CharID: 7, ObjectID: 100, Count: 1})
+	if len(sink) != 0 {
+		t.Fatal("a store owner discarded an item")
+	}
+}
+
+func TestCrystallizeItem_NeedsTheSkill(t *testing.T) {
+	gl, p := newTestLoopWithPlayer(t)
+	sink := make(chan DiscardJob, 4)
+	gl.SetDiscardSink(sink)
+
+	gl.handleCrystallizeItem(CmdCrystallizeItem{CharID: 7, ObjectID: 100})
+	if len(sink) != 0 {
+		t.Fatal("crystallized without the skill")
+	}
+
+	p.KnownSkills = map[int32]int32{skillCrystallize: 3}
+	gl.handleCrystallizeItem(CmdCrystallizeItem{CharID: 7, ObjectID: 100})
+	if job := <-sink; job.Kind != DiscardCrystallize || job.CrystallizeLevel != 3 || job.Count != 1 {
+		t.Fatalf("crystallize job = %+v", job)
+	}
+}
+
+func TestItemDropped_LiesFreeOnTheGround(t *testing.T) {
+	gl, p := newTestLoopWithPlayer(t)
+	gl.handleItemDropped(CmdItemDropped{CharID: 7, Item: models.GroundItem{ItemID: 57, Count: 10, StoredID: 55, Position: models.Position{X: 30}}})
+
+	items := gl.world.GetItemsInRange(models.Position{}, 100)
+	if len(items) != 1 {
+		t.Fatalf("%d items on the ground", len(items))
+	}
+	it := items[0]
+	if it.DropperID != 7 || it.OwnerID != 0 || it.StoredID != 55 || !p.KnownItems[it.ObjectID] {
+		t.Fatalf("dropped item = %+v", it)
+	}
+}
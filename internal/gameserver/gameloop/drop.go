@@ -0,0 +1,163 @@
+package gameloop
+
+import (
+	"math"
+	"math/rand"
+	"time"
+
+	"github.com/VerTox/l2go/internal/gameserver/models"
+	"github.com/VerTox/l2go/internal/gameserver/registry"
+)
+
+// deepBlueLevelGap is how many levels a killer may be above the NPC before
+// drop chances shrink (L2J DeepBlueDropRules), by deepBluePenalty percent of
+// the chance for every level beyond it.
+const (
+	deepBlueLevelGap = 8
+	deepBluePenalty  = 9
+)
+
+// SetDropRates sets the server drop rates: dropRate multiplies the chance of
+// every drop but adena, adenaRate the amount of adena dropped. Rates of 0 or
+// less mean 1.
+func (gl *GameLoop) SetDropRates(dropRate, adenaRate float64) {
+	if dropRate <= 0 {
+		dropRate = 1.0
+	}
+	if adenaRate <= 0 {
+		adenaRate = 1.0
+	}
+	gl.dropRate, gl.adenaRate = dropRate, adenaRate
+}
+
+// droppedItem is a count of an item rolled from a kill.
+type droppedItem struct {
+	ItemID int32
+	Count  int64
+}
+
+// dropLoot rolls a dead NPC's death drops and scatters them on the ground
+// around the corpse. The drops belong to the attacker who did the most damage:
+// for lootProtection only that attacker's party may pick them up. A
+// non-stackable item drops one piece at a time. Herbs, which are used when
+// picked up, are not dropped yet.
+func (gl *GameLoop) dropLoot(npc *models.NpcInstance, killerID int32) {
+	if npc.Template == nil {
+		return
+	}
+	ownerID := killerID
+	if hl, ok := gl.npcHateLists[npc.ObjectID]; ok {
+		if top := hl.GetTopDamageDealer(); top != 0 {
+			ownerID = top
+		}
+	}
+	owner, ok := gl.world.GetPlayer(ownerID)
+	if !ok || owner.Character == nil {
+		return
+	}
+
+	dropRate := gl.dropRate * deepBlueModifier(owner.Character.Level, npc.Template.Level)
+	protectedUntil := time.Now().Add(lootProtection)
+	for _, d := range rollDeathDrops(npc.Template, dropRate, gl.adenaRate, rand.Float64) {
+		tmpl := registry.GetItemTemplateRegistry().Get(d.ItemID)
+		if tmpl != nil && tmpl.ExImmediateEffect {
+			continue
+		}
+		per, n := d.Count, int64(1)
+		if tmpl != nil && !tmpl.Stackable {
+			per, n = 1, d.Count
+		}
+		for range n {
+			gl.spawnGroundItem(&models.GroundItem{
+				ItemID:         d.ItemID,
+				Count:          per,
+				Position:       scatterDrop(npc.Position),
+				DropperID:      npc.ObjectID,
+				OwnerID:        ownerID,
+				ProtectedUntil: protectedUntil,
+			})
+		}
+	}
+}
+
+// deepBlueModifier scales drop chances down for a killer far above the NPC's
+// level (L2J DeepBlueDropRules): deepBluePenalty percent less for every level
+// beyond deepBlueLevelGap, down to nothing.
+func deepBlueModifier(killerLevel, npcLevel int) float64 {
+	over := killerLevel - npcLevel - deepBlueLevelGap
+	if over <= 0 {
+		return 1
+	}
+	return math.Max(0, 1-float64(over*deepBluePenalty)/100)
+}
+
+// rollDeathDrops rolls an NPC's death drops (L2J GeneralDropItem and
+// GroupedGeneralDropItem). Every ungrouped item and every group rolls its
+// chance, multiplied by dropRate, on its own; a chance above 100% drops once
+// for each whole 100% and rolls for the rest. A group that drops gives one of
+// its items, picked by their shares. Adena ignores dropRate and has its
+// amount multiplied by adenaRate instead. roll returns a number in [0, 1).
+// Counts of the same item are added up.
+func rollDeathDrops(t *models.NpcTemplate, dropRate, adenaRate float64, roll func() float64) []droppedItem {
+	var out []droppedItem
+	add := func(d models.DropItem, times int) {
+		var count int64
+		for range times {
+			count += d.Min
+			if d.Max > d.Min {
+				count += int64(roll() * float64(d.Max-d.Min+1))
+			}
+		}
+		if d.ItemID == models.ItemIDAdena {
+			count = int64(float64(count) * adenaRate)
+		}
+		if count <= 0 {
+			return
+		}
+		for i := range out {
+			if out[i].ItemID == d.ItemID {
+				out[i].Count += count
+				return
+			}
+		}
+		out = append(out, droppedItem{ItemID: d.ItemID, Count: count})
+	}
+	successes := func(chance float64) int {
+		n := int(chance / 100)
+		if roll()*100 < math.Mod(chance, 100) {
+			n++
+		}
+		return n
+	}
+	rate := func(d models.DropItem) float64 {
+		if d.ItemID == models.ItemIDAdena {
+			return 1
+		}
+		return dropRate
+	}
+
+	for _, d := range t.DeathItems {
+		if n := successes(d.Chance * rate(d)); n > 0 {
+			add(d, n)
+		}
+	}
+	for _, g := range t.DeathGroups {
+		// A group of adena alone drops at its own chance, like adena does.
+		groupRate := dropRate
+		if len(g.Items) == 1 && g.Items[0].ItemID == models.ItemIDAdena {
+			groupRate = 1
+		}
+		for range successes(g.Chance * groupRate) {
+			pick := roll() * 100
+			var total float64
+			for _, d := range g.Items {
+				total += d.Chance
+				if pick < total {
+					add(d, 1)
+					break
+				}
+			}
+		}
+	}
+	return out
+}
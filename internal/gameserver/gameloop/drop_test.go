@@ -0,0 +1,101 @@
+package gameloop
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/VerTox/l2go/internal/gameserver/models"
+)
+
+// scriptedRoll returns the given rolls in order, then 0.99.
+func scriptedRoll(rolls ...float64) func() float64 {
+	return func() float64 {
+		if len(rolls) == 0 {
+			return 0.99
+		}
+		r := rolls[0]
+		rolls = rolls[1:]
+		return r
+	}
+}
+
+func TestRollDeathDrops(t *testing.T) {
+	tmpl := &models.NpcTemplate{
+		DeathItems: []models.DropItem{
+			{ItemID: 1869, Min: 1, Max: 1, Chance: 250},
+		},
+		DeathGroups: []models.DropGroup{
+			{Chance: 70, Items: []models.DropItem{{ItemID: models.ItemIDAdena, Min: 30, Max: 39, Chance: 100}}},
+			{Chance: 40, Items: []models.DropItem{
+				{ItemID: 112, Min: 1, Max: 1, Chance: 30},
+				{ItemID: 118, Min: 1, Max: 1, Chance: 70},
+			}},
+		},
+	}
+
+	// 250% is two drops and a roll for the third (0.4 < 0.5: a hit). The
+	// adena group hits (0.5 < 0.7), picks its only item and rolls 30 + 5,
+	// doubled by the adena rate; the last group hits (0.3 < 0.4) and picks
+	// the second item (0.3 is past the first item's 30%).
+	got := rollDeathDrops(tmpl, 1, 2, scriptedRoll(0.4, 0.5, 0.5, 0.5, 0.3, 0.3))
+	want := []droppedItem{{ItemID: 1869, Count: 3}, {ItemID: models.ItemIDAdena, Count: 70}, {ItemID: 118, Count: 1}}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("drops = %+v, want %+v", got, want)
+	}
+
+	// A halved drop rate leaves one sure drop of 1869 (0.9 misses the other
+	// 25%) and halves the item group to 20% (0.3 misses), but adena keeps its
+	// 70% (0.45 hits).
+	got = rollDeathDrops(tmpl, 0.5, 1, scriptedRoll(0.9, 0.45, 0, 0, 0.3))
+	want = []droppedItem{{ItemID: 1869, Count: 1}, {ItemID: models.ItemIDAdena, Count: 30}}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("halved drops = %+v, want %+v", got, want)
+	}
+
+	if got := rollDeathDrops(&models.NpcTemplate{}, 1, 1, scriptedRoll()); got != nil {
+		t.Fatalf("an NPC without drops dropped %+v", got)
+	}
+}
+
+func TestDeepBlueModifier(t *testing.T) {
+	for _, tc := range []struct {
+		killer, npc int
+		want        float64
+	}{
+		{20, 20, 1},
+		{28, 20, 1},
+		{30, 20, 0.82},
+		{40, 20, 0},
+	} {
+		if got := deepBlueModifier(tc.killer, tc.npc); got < tc.want-1e-9 || got > tc.want+1e-9 {
+			t.Errorf("deepBlueModifier(%d, %d) = %v, want %v", tc.killer, tc.npc, got, tc.want)
+		}
+	}
+}
+
+func TestNPCDeath_DropsBelongToTopAttacker(t *testing.T) {
+	gl, p := newTestLoopWithPlayer(t)
+	mob := addAttackableNPC(gl, 3000, models.Position{X: 100})
+	mob.Template.DeathItems = []models.DropItem{{ItemID: 1869, Min: 2, Max: 2, Chance: 100}}
+	hl := NewHateList()
+	hl.AddHate(7, 100)
+	gl.npcHateLists[mob.ObjectID] = hl
+
+	// Char 8 dealt the last blow, but char 7 did the damage.
+	gl.handleNPCDeath(mob, 8)
+	items := gl.world.GetItemsInRange(mob.Position, 2*itemDropScatter)
+	if len(items) != 1 {
+		t.Fatalf("ground items = %d, want 1", len(items))
+	}
+	it := items[0]
+	if it.ItemID != 1869 || it.Count != 2 || it.OwnerID != 7 || it.DropperID != mob.ObjectID || it.StoredID != 0 {
+		t.Fatalf("drop = %+v", it)
+	}
+	if !it.IsProtectedFrom(8, time.Now()) || it.IsProtectedFrom(7, time.Now()) {
+		t.Fatal("the drop is reserved for its owner")
+	}
+	if !p.KnownItems[it.ObjectID] {
+		t.Fatal("players around see the drop fall")
+	}
+}
@@ -1,6 +1,7 @@
 package gameloop
 
 import (
+	"math/rand"
 	"time"
 
 	"github.com/VerTox/l2go/internal/gameserver/models"
@@ -158,7 +159,7 @@ func (e *InteractApproachEvent) Execute(gl *GameLoop) {
 			player.Position.X, player.Position.Y, player.Position.Z,
 			npc.Position.X, npc.Position.Y, npc.Position.Z,
 		))
-		_ = conn.Send(outclient.BuildNpcHtmlMessage(e.TargetObjectID, outclient.DefaultNpcHtml))
+		_ = conn.Send(outclient.BuildNpcHtmlMessage(e.TargetObjectID, NpcDialogHtml(player, npc)))
 	}
 }
 
@@ -216,6 +217,26 @@ func (e *NextAttackEvent) Execute(gl *GameLoop) {
 		return
 	}
 
+	// A target or attacker that walked into a peace zone ends the fight (L2J
+	// doAttack's isInsidePeaceZone check).
+	if tgt.isPlayer() {
+		if refusal := gl.peaceZoneRefusal(e.AttackerCharID, tgt.objectID); refusal != 0 {
+			gl.stopAttacker(e.AttackerCharID)
+			gl.sendToPlayer(player, outclient.BuildSystemMessageNoParams(refusal))
+			gl.sendToPlayer(player, outclient.BuildActionFailed())
+			return
+		}
+	}
+
+	// A wall or a hill between them ends the fight too (L2J doAttack's
+	// canSeeTarget check).
+	if !gl.geo.CanSeeTarget(player.Position, tgt.pos) {
+		gl.stopAttacker(e.AttackerCharID)
+		gl.sendToPlayer(player, outclient.BuildSystemMessageNoParams(outclient.SysMsgCantSeeTarget))
+		gl.sendToPlayer(player, outclient.BuildActionFailed())
+		return
+	}
+
 	// Compute attack timing
 	pAtkSpd := 300 // default
 	if player.Character != nil {
@@ -416,6 +437,10 @@ func (gl *GameLoop) dealDamageToNPC(npc *models.NpcInstance, attackerCharID int3
 			target = attackerCharID
 		}
 		gl.startNPCAttack(npc.ObjectID, target)
+		if npc.CurrentHP > 0 {
+			gl.callClanHelp(npc, attackerCharID)
+			gl.callMinionHelp(npc, attackerCharID)
+		}
 	}
 
 	su := outclient.BuildStatusUpdate(npc.ObjectID, []outclient.StatusAttribute{
@@ -425,7 +450,7 @@ func (gl *GameLoop) dealDamageToNPC(npc *models.NpcInstance, attackerCharID int3
 	gl.broadcastToTargeters(npc.ObjectID, su)
 
 	if npc.CurrentHP <= 0 {
-		gl.handleNPCDeath(npc)
+		gl.handleNPCDeath(npc, attackerCharID)
 	}
 }
 
@@ -450,6 +475,16 @@ func (e *RespawnEvent) Execute(gl *GameLoop) {
 		return
 	}
 
+	// A territory spawn comes back at a new random point of its territory, on
+	// the ground there, which becomes its spawn point to wander around and
+	// return to.
+	if info.Territory != nil {
+		pos := info.Territory.RandomPoint(rand.Intn)
+		pos.Z = gl.geo.GetHeight(pos.X, pos.Y, pos.Z)
+		info.Position = pos
+		info.Heading = int32(rand.Intn(65536))
+	}
+
 	// Create new NPC instance with new ObjectID
 	newNPC := &models.NpcInstance{
 		ObjectID:   gl.nextObjectID(),
@@ -470,6 +505,10 @@ func (e *RespawnEvent) Execute(gl *GameLoop) {
 
 	// Add to world
 	gl.world.AddNPC(newNPC)
+	gl.spawnMinions(newNPC)
+	if isRaidBoss(newNPC) {
+		gl.raidBossSpawned(newNPC)
+	}
 
 	log.Debug().
 		Int32("new_object_id", newNPC.ObjectID).
@@ -498,8 +537,9 @@ func (e *CorpseDecayEvent) Execute(gl *GameLoop) {
 	// Remove from world registry
 	gl.world.RemoveNPC(e.ObjectID)
 
-	// Clean up hate list
+	// Clean up hate list and skill cooldowns
 	delete(gl.npcHateLists, e.ObjectID)
+	delete(gl.npcSkillReuse, e.ObjectID)
 
 	log.Debug().Int32("object_id", e.ObjectID).Msg("NPC corpse decayed")
 }
@@ -12,8 +12,28 @@ import (
 	"github.com/VerTox/l2go/internal/gameserver/usecase"
 )
 
-// awardExpForNPCKill distributes EXP and SP to all attackers from the hate list
-// proportionally to their damage dealt.
+// rewardUnit is one recipient of a kill reward: a solo attacker, or a whole group
+// (party) whose members' damage is pooled and paid out together.
+type rewardUnit struct {
+	charID  int32   // solo attacker; 0 for a group
+	members []int32 // group roster from rewardGroupOf; nil for a solo attacker
+	hate    int64
+}
+
+// partyRewardGroup is the default rewardGroupOf hook: a party member is paid as
+// part of its party, everyone else solo.
+func (gl *GameLoop) partyRewardGroup(charID int32) []int32 {
+	if p := gl.partyOf(charID); p != nil {
+		return p.Members
+	}
+	return nil
+}
+
+// awardExpForNPCKill distributes EXP and SP from the hate list proportionally to
+// the damage dealt (L2J L2Attackable.calculateRewards). Attackers that
+// rewardGroupOf puts in the same group form one unit: their damage is summed, the
+// unit's reward gets the per-member group bonus and is split among the members
+// near the NPC (L2J L2Party.distributeXpAndSp).
 func (gl *GameLoop) awardExpForNPCKill(npc *models.NpcInstance) {
 	hl, ok := gl.npcHateLists[npc.ObjectID]
 	if !ok || hl.IsEmpty() {
@@ -33,78 +53,138 @@ func (gl *GameLoop) awardExpForNPCKill(npc *models.NpcInstance) {
 		return // NPC yields no reward (no <acquire> in the datapack)
 	}
 
-	// Compute total hate for proportional distribution
+	// Pool the damage per reward unit. A group is keyed by its first member (the
+	// party leader) so every attacker of the same party lands in one unit.
 	var totalHate int64
-	attackers := hl.GetAllAttackers()
-	hateValues := make(map[int32]int64, len(attackers))
-	for _, charID := range attackers {
-		h := hl.entries[charID]
-		hateValues[charID] = h
+	units := make(map[int32]*rewardUnit)
+	for charID, e := range hl.entries {
+		h := e.damage
 		totalHate += h
+		key := charID
+		var members []int32
+		if gl.rewardGroupOf != nil {
+			if members = gl.rewardGroupOf(charID); len(members) > 0 {
+				key = members[0]
+			}
+		}
+		u, ok := units[key]
+		if !ok {
+			u = &rewardUnit{members: members}
+			if members == nil {
+				u.charID = charID
+			}
+			units[key] = u
+		}
+		u.hate += h
 	}
 	if totalHate <= 0 {
 		totalHate = 1
 	}
 
-	for _, charID := range attackers {
-		player, exists := gl.world.GetPlayer(charID)
-		if !exists || player.Character == nil {
+	for _, u := range units {
+		// Proportion based on damage dealt
+		proportion := float64(u.hate) / float64(totalHate)
+		if u.members == nil {
+			player, exists := gl.world.GetPlayer(u.charID)
+			if !exists || player.Character == nil {
+				continue
+			}
+			penalty := data.LevelPenalty(player.Character.Level, npcLevel)
+			// No artificial floor: L2J truncates to int and lets a heavily
+			// over-levelled (grey) mob yield 0. All factors are non-negative.
+			gl.grantExpSp(player,
+				int64(float64(baseExp)*proportion*penalty*gl.expRate),
+				int64(float64(baseSP)*proportion*penalty*gl.spRate))
 			continue
 		}
+		gl.distributeGroupReward(u.members, npc.Position, npcLevel,
+			float64(baseExp)*proportion, float64(baseSP)*proportion)
+	}
+}
 
-		// Proportion based on damage dealt
-		proportion := float64(hateValues[charID]) / float64(totalHate)
-
-		// Level penalty
-		penalty := data.LevelPenalty(player.Character.Level, npcLevel)
-
-		// Final EXP/SP with server rates
-		earnedExp := int64(float64(baseExp) * proportion * penalty * gl.expRate)
-		earnedSP := int64(float64(baseSP) * proportion * penalty * gl.spRate)
-
-		// No artificial floor: L2J truncates to int and lets a heavily over-levelled
-		// (grey) mob yield 0. Values are already >= 0 (all factors non-negative).
-		if earnedExp < 0 {
-			earnedExp = 0
+// distributeGroupReward pays a group unit's share of a kill (exp/sp before
+// penalties and rates) to its members within partyRange of the kill. The level
+// penalty is taken from the highest rewarded member, the pool grows by the group
+// bonus for the number of rewarded members, and each member gets a level²-weighted
+// cut reduced by its level gap below the top member.
+func (gl *GameLoop) distributeGroupReward(memberIDs []int32, pos models.Position, npcLevel int, exp, sp float64) {
+	rewarded := make([]*registry.PlayerWorldState, 0, len(memberIDs))
+	topLevel := 0
+	var sqLevelSum float64
+	for _, id := range memberIDs {
+		member, ok := gl.world.GetPlayer(id)
+		if !ok || member.Character == nil || member.Character.CurrentHP <= 0 {
+			continue
 		}
-		if earnedSP < 0 {
-			earnedSP = 0
+		dx := member.Position.X - pos.X
+		dy := member.Position.Y - pos.Y
+		if dx*dx+dy*dy > partyRange*partyRange {
+			continue
 		}
-
-		// Apply EXP and SP
-		oldLevel := player.Character.Level
-		player.Character.Experience += earnedExp
-		player.Character.SP += int(earnedSP)
-
-		// Check level-up
-		newLevel := data.LevelForExp(player.Character.Experience)
-		if newLevel > data.MaxLevel {
-			newLevel = data.MaxLevel
+		rewarded = append(rewarded, member)
+		lvl := member.Character.Level
+		sqLevelSum += float64(lvl * lvl)
+		if lvl > topLevel {
+			topLevel = lvl
 		}
+	}
+	if len(rewarded) == 0 || sqLevelSum <= 0 {
+		return
+	}
 
-		leveledUp := newLevel > oldLevel
+	factor := data.LevelPenalty(topLevel, npcLevel) * data.PartyExpBonus(len(rewarded))
+	for _, member := range rewarded {
+		lvl := member.Character.Level
+		cut := float64(lvl*lvl) / sqLevelSum * factor * data.PartyLevelGapFactor(topLevel-lvl)
+		gl.grantExpSp(member,
+			int64(exp*cut*gl.expRate),
+			int64(sp*cut*gl.spRate))
+	}
+}
 
-		if leveledUp {
-			player.Character.Level = newLevel
-			gl.applyLevelUp(player, oldLevel, newLevel)
-			// Persist immediately: a level-up is the most painful progress to lose
-			// on a crash. Async (value-copy) write, so it does not stall the loop.
-			gl.persistPlayer(player)
-		}
+// grantExpSp adds earned EXP/SP to a player, applies any level-up and notifies
+// the client.
+func (gl *GameLoop) grantExpSp(player *registry.PlayerWorldState, earnedExp, earnedSP int64) {
+	if earnedExp < 0 {
+		earnedExp = 0
+	}
+	if earnedSP < 0 {
+		earnedSP = 0
+	}
+
+	// Apply EXP and SP
+	oldLevel := player.Character.Level
+	player.Character.Experience += earnedExp
+	player.Character.SP += int(earnedSP)
 
-		gl.prom.recordProgression(earnedExp, earnedSP, leveledUp)
+	// Check level-up
+	newLevel := data.LevelForExp(player.Character.Experience)
+	if newLevel > data.MaxLevel {
+		newLevel = data.MaxLevel
+	}
 
-		// Send notifications to the player
-		gl.sendExpRewardNotification(player, earnedExp, int32(earnedSP), leveledUp)
+	leveledUp := newLevel > oldLevel
 
-		log.Debug().
-			Int32("char_id", charID).
-			Int64("exp", earnedExp).
-			Int64("sp", earnedSP).
-			Int("level", player.Character.Level).
-			Bool("leveled_up", leveledUp).
-			Msg("EXP/SP awarded")
+	if leveledUp {
+		player.Character.Level = newLevel
+		gl.applyLevelUp(player, oldLevel, newLevel)
+		// Persist immediately: a level-up is the most painful progress to lose
+		// on a crash. Async (value-copy) write, so it does not stall the loop.
+		gl.persistPlayer(player)
 	}
+
+	gl.prom.recordProgression(earnedExp, earnedSP, leveledUp)
+
+	// Send notifications to the player
+	gl.sendExpRewardNotification(player, earnedExp, int32(earnedSP), leveledUp)
+
+	log.Debug().
+		Int32("char_id", player.CharID).
+		Int64("exp", earnedExp).
+		Int64("sp", earnedSP).
+		Int("level", player.Character.Level).
+		Bool("leveled_up", leveledUp).
+		Msg("EXP/SP awarded")
 }
 
 // applyLevelUp recalculates stats and restores HP/MP on level up.
@@ -149,6 +229,9 @@ func (gl *GameLoop) applyLevelUp(player *registry.PlayerWorldState, oldLevel, ne
 
 	// Level changed → the memoized ComputedStats is stale. (l2go-gur)
 	player.InvalidateStats()
+
+	// New level and full bars in the party window.
+	gl.updatePartyStatus(player)
 }
 
 // sendExpRewardNotification sends SystemMessage and UserInfo to a player after earning EXP.
@@ -279,6 +362,7 @@ func (gl *GameLoop) buildUserInfoForPlayer(player *registry.PlayerWorldState) []
 		InventoryLimit: 80,
 		RunningFlag: runningFlag,
 		InCombat:    inCombatFlag,
+		PrivateStoreType: int32(player.PrivateStore.Type),
 		ExpPercent:  expPercent / 100.0, // UserInfo expects 0.0-1.0 fraction
 		HairStyle:   int32(char.HairStyle),
 		HairColor:   int32(char.HairColor),
@@ -0,0 +1,98 @@
+package gameloop
+
+import (
+	"testing"
+
+	"github.com/VerTox/l2go/internal/gameserver/models"
+)
+
+// setupKill places a level-npcLevel mob with the given reward and hate list and
+// sets each listed player's level.
+func setupKill(t *testing.T, gl *GameLoop, npcLevel int, exp, sp int64, hate map[int32]int64, levels map[int32]int) *models.NpcInstance {
+	t.Helper()
+	npc := addAttackableNPC(gl, 1000, models.Position{})
+	npc.Template.Level = npcLevel
+	npc.Template.RewardExp = exp
+	npc.Template.RewardSp = sp
+	hl := NewHateList()
+	for id, h := range hate {
+		hl.AddHate(id, h)
+	}
+	gl.npcHateLists[npc.ObjectID] = hl
+	for id, lvl := range levels {
+		p, ok := gl.world.GetPlayer(id)
+		if !ok {
+			t.Fatalf("player %d not in world", id)
+		}
+		p.Character.Level = lvl
+	}
+	return npc
+}
+
+func expOf(t *testing.T, gl *GameLoop, id int32) (int64, int) {
+	t.Helper()
+	p, ok := gl.world.GetPlayer(id)
+	if !ok {
+		t.Fatalf("player %d not in world", id)
+	}
+	return p.Character.Experience, p.Character.SP
+}
+
+func TestAwardExp_SoloSplitByDamage(t *testing.T) {
+	gl, _ := newTestLoopWithPlayer(t)
+	addPlayer(t, gl, 8, "acc2", models.Position{X: 100})
+	npc := setupKill(t, gl, 10, 1000, 100, map[int32]int64{7: 300, 8: 100}, map[int32]int{7: 10, 8: 10})
+
+	gl.awardExpForNPCKill(npc)
+
+	if exp, sp := expOf(t, gl, 7); exp != 750 || sp != 75 {
+		t.Errorf("char 7 got exp=%d sp=%d, want 750/75", exp, sp)
+	}
+	if exp, sp := expOf(t, gl, 8); exp != 250 || sp != 25 {
+		t.Errorf("char 8 got exp=%d sp=%d, want 250/25", exp, sp)
+	}
+}
+
+func TestAwardExp_PartyPaidAsOneUnitWithBonus(t *testing.T) {
+	gl, _ := newTestLoopWithPlayer(t)
+	addPlayer(t, gl, 8, "acc2", models.Position{X: 100})
+	addPlayer(t, gl, 9, "acc3", models.Position{X: 200})
+	joinParty(t, gl, 7, 8)
+	// The party dealt half the damage between its members; char 9 the other half.
+	npc := setupKill(t, gl, 20, 10000, 0, map[int32]int64{7: 100, 8: 100, 9: 200}, map[int32]int{7: 20, 8: 20, 9: 20})
+
+	gl.awardExpForNPCKill(npc)
+
+	// Party pool: 10000 × 0.5 × 1.10 (2-member bonus), split evenly by equal levels.
+	for _, id := range []int32{7, 8} {
+		if exp, _ := expOf(t, gl, id); exp != 2750 {
+			t.Errorf("party member %d got exp=%d, want 2750", id, exp)
+		}
+	}
+	if exp, _ := expOf(t, gl, 9); exp != 5000 {
+		t.Errorf("solo char 9 got exp=%d, want 5000", exp)
+	}
+}
+
+func TestAwardExp_PartyLevelGapAndRange(t *testing.T) {
+	gl, _ := newTestLoopWithPlayer(t)
+	addPlayer(t, gl, 8, "acc2", models.Position{X: 100})
+	addPlayer(t, gl, 10, "acc4", models.Position{X: partyRange + 500})
+	joinParty(t, gl, 7, 8, 10)
+	// Only the leader hit the mob; 8 sits 12 levels below it, 10 is out of range.
+	npc := setupKill(t, gl, 30, 10000, 0, map[int32]int64{7: 100}, map[int32]int{7: 30, 8: 18, 10: 30})
+
+	gl.awardExpForNPCKill(npc)
+
+	// Two rewarded members: pool 10000 × 1.10, cut by level² (900 : 324).
+	if exp, _ := expOf(t, gl, 7); exp != 8088 {
+		t.Errorf("leader got exp=%d, want 8088", exp)
+	}
+	// A 12-level gap keeps only 30% of the level² cut.
+	if exp, _ := expOf(t, gl, 8); exp != 873 {
+		t.Errorf("low-level member got exp=%d, want 873", exp)
+	}
+	if exp, _ := expOf(t, gl, 10); exp != 0 {
+		t.Errorf("out-of-range member got exp=%d, want 0", exp)
+	}
+}
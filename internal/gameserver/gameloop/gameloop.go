@@ -2,11 +2,13 @@ package gameloop
 
 import (
 	"context"
+	"math/rand"
 	"strconv"
 	"time"
 
 	"github.com/rs/zerolog/log"
 
+	"github.com/VerTox/l2go/internal/gameserver/geodata"
 	"github.com/VerTox/l2go/internal/gameserver/models"
 	"github.com/VerTox/l2go/internal/gameserver/packets/outclient"
 	"github.com/VerTox/l2go/internal/gameserver/registry"
@@ -17,7 +19,7 @@ const (
 	tickInterval        = 100 * time.Millisecond
 	commandChannelSize  = 1024
 	corpseDecayDelay    = 7 * time.Second
-	respawnDelay        = 60 * time.Second
+	respawnDelay        = 60 * time.Second // for NPCs whose spawn sets no delay
 	combatStanceTimeout = 15 * time.Second
 
 	// broadcastRadius is how far movement/combat packets are sent. It equals the
@@ -34,6 +36,27 @@ type SpawnInfo struct {
 	TemplateID int32
 	Position   models.Position
 	Heading    int32
+	// RespawnDelay is how long after death the NPC comes back, give or take
+	// up to RespawnRandom (L2J L2Spawn respawn min/max delay). Zero means
+	// respawnDelay.
+	RespawnDelay  time.Duration
+	RespawnRandom time.Duration
+	// Territory is the zone the NPC respawns in, at a random point of it; nil
+	// respawns it at Position.
+	Territory *models.Zone
+}
+
+// nextRespawnDelay rolls how long after death the NPC respawns. roll(n)
+// returns [0, n).
+func (s SpawnInfo) nextRespawnDelay(roll func(n int64) int64) time.Duration {
+	d := s.RespawnDelay
+	if d <= 0 {
+		d = respawnDelay
+	}
+	if s.RespawnRandom > 0 {
+		d += time.Duration(roll(int64(2*s.RespawnRandom)+1)) - s.RespawnRandom
+	}
+	return max(d, 0)
 }
 
 // PlayerCombatState tracks a player's auto-attack state.
@@ -57,6 +80,7 @@ type GameLoop struct {
 	npcCombatState  map[int32]*NPCCombatState    // NPC objectID -> NPC auto-attack state
 	npcHateLists    map[int32]*HateList          // NPC objectID -> hate
 	npcSpawnInfo    map[int32]SpawnInfo          // NPC objectID -> spawn data for respawn
+	npcMoves        map[int32]*npcMove           // NPC objectID -> move in progress
 	activeRegions   map[string]*ActiveRegion
 	// playerRegionCenter is the grid cell each live player's 3x3 activation block is
 	// centered on, so a move only re-counts regions when the player crosses a cell
@@ -118,6 +142,117 @@ type GameLoop struct {
 	// buffs/flags are added, expire, or the player disconnects. (l2go-t2q)
 	buffedPlayers  map[int32]struct{}
 	flaggedPlayers map[int32]struct{}
+	// buffedNPCs are the NPCs with an active effect, swept for expiry by
+	// serviceBuffs the same way.
+	buffedNPCs map[int32]struct{}
+
+	// npcCasts holds the skill each NPC is casting; npcSkillReuse its skill
+	// cooldowns (NPC objectID -> skillID -> ready-at). Loop-owned, dropped
+	// when the corpse decays.
+	npcCasts      map[int32]*npcCast
+	npcSkillReuse map[int32]map[int32]time.Time
+
+	// zoneRegistry is the world's zones; playerZones the ones each live player
+	// stands in, kept by revalidatePlayerZones. zoneListeners are told of every
+	// enter and leave. compassZones is the compass code last sent to each
+	// player outside the general zone. Loop-owned.
+	zoneRegistry  *registry.ZoneRegistry
+	playerZones   map[int32][]*models.Zone
+	zoneListeners []ZoneListener
+	compassZones  map[int32]int32
+
+	// geo answers line-of-sight and ground height questions; without geodata
+	// for a region every line there is clear.
+	geo *geodata.Engine
+
+	// Pathfinding (pathfinding.go). pathCache keeps recent searches;
+	// pathNodesLeft is what remains of the tick's search budget and pathTime
+	// the tick's time spent searching, reported as the "pathfinding" phase.
+	// playerPaths holds the waypoints left for players walking round an
+	// obstacle. Loop-owned.
+	pathCache     map[pathKey]cachedPath
+	pathNodesLeft int
+	pathTime      time.Duration
+	playerPaths   map[int32][]models.Position
+
+	// parties maps every party member's charID to its shared *Party; partyInvites
+	// holds outstanding invitations keyed by the invitee. partySeq stamps invites
+	// and loot votes so their expiry events can detect they were superseded.
+	// Loop-owned.
+	parties      map[int32]*Party
+	partyInvites map[int32]partyInvite
+	partySeq     int64
+
+	// rewardGroupOf returns the roster a character is paid with on a kill (first
+	// entry identifies the group), or nil when it is rewarded solo. Defaults to the
+	// character's party; a hook so other groupings can share rewards the same way.
+	rewardGroupOf func(charID int32) []int32
+
+	// trades maps both partners of an open trade window to the shared session;
+	// tradeRequests holds unanswered requests keyed by the asked player. tradeSeq
+	// stamps requests so their expiry can detect a newer one. Loop-owned.
+	trades        map[int32]*tradeSession
+	tradeRequests map[int32]tradeRequest
+	tradeSeq      int64
+
+	// tradeSink receives trade work that needs the database (open, commit). nil
+	// until SetTradeSink is called.
+	tradeSink chan<- TradeJob
+
+	// storeSeq stamps each private store opening so a late deal result for an
+	// earlier opening is ignored. storeSink receives store work that needs the
+	// database (windows, deals); nil until SetStoreSink is called.
+	storeSeq  int64
+	storeSink chan<- StoreJob
+
+	// warehouseSink receives warehouse work that needs the database (windows,
+	// deposits, withdrawals); nil until SetWarehouseSink is called.
+	warehouseSink chan<- WarehouseJob
+
+	// shopSink receives merchant work that needs the database (shop windows,
+	// purchases, sales, buy-backs); nil until SetShopSink is called.
+	shopSink chan<- ShopJob
+
+	// multisellSink receives multisell exchanges, which need the database; nil
+	// until SetMultisellSink is called.
+	multisellSink chan<- MultisellJob
+
+	// teleportSink receives paid gatekeeper teleports, whose fee needs the
+	// database; nil until SetTeleportSink is called.
+	teleportSink chan<- TeleportJob
+	// freeTeleportLevel: characters below it teleport for free (0 = never).
+	freeTeleportLevel int
+
+	// questSink receives quest steps, whose state and items need the
+	// database; nil until SetQuestSink is called.
+	questSink chan<- QuestJob
+
+	// groundItemSink receives pickups and decays of ground items, which
+	// need the database; nil until SetGroundItemSink is called.
+	groundItemSink chan<- GroundItemJob
+	// dropRate multiplies drop chances, adenaRate dropped adena (both 1.0
+	// unless SetDropRates says otherwise).
+	dropRate  float64
+	adenaRate float64
+
+	// discardSink receives items players drop, destroy or crystallize, which
+	// need the database; nil until SetDiscardSink is called.
+	discardSink chan<- DiscardJob
+
+	// raidBosses maps each living raid boss's template id to its object id.
+	// raidBossSink receives boss states and raid points to save; nil until
+	// SetRaidBossSink is called.
+	raidBosses   map[int32]int32
+	raidBossSink chan<- RaidBossJob
+
+	// minions is each leader's escort (leader objectID -> minion objectIDs),
+	// minionLeaders the way back.
+	minions       map[int32][]int32
+	minionLeaders map[int32]int32
+
+	// npcTemplates looks up the templates NPCs are spawned and respawned
+	// from: the NPC template registry, or a test's own set.
+	npcTemplates func(templateID int32) *models.NpcTemplate
 }
 
 // New creates a new GameLoop. expRate and spRate control experience/SP multipliers (default 1.0).
@@ -128,7 +263,7 @@ func New(world *registry.WorldRegistry, connections *registry.ConnectionRegistry
 	if spRate <= 0 {
 		spRate = 1.0
 	}
-	return &GameLoop{
+	gl := &GameLoop{
 		commands:        make(chan Command, commandChannelSize),
 		world:           world,
 		connections:     connections,
@@ -137,6 +272,7 @@ func New(world *registry.WorldRegistry, connections *registry.ConnectionRegistry
 		npcCombatState:  make(map[int32]*NPCCombatState),
 		npcHateLists:    make(map[int32]*HateList),
 		npcSpawnInfo:    make(map[int32]SpawnInfo),
+		npcMoves:        make(map[int32]*npcMove),
 		activeRegions:      make(map[string]*ActiveRegion),
 		playerRegionCenter: make(map[int32][2]int),
 		interactPending:    make(map[int32]int32),
@@ -144,9 +280,32 @@ func New(world *registry.WorldRegistry, connections *registry.ConnectionRegistry
 		skillReuse:      make(map[int32]map[int32]time.Time),
 		buffedPlayers:   make(map[int32]struct{}),
 		flaggedPlayers:  make(map[int32]struct{}),
+		buffedNPCs:      make(map[int32]struct{}),
+		npcCasts:        make(map[int32]*npcCast),
+		npcSkillReuse:   make(map[int32]map[int32]time.Time),
+		zoneRegistry:    registry.GetZoneRegistry(),
+		playerZones:     make(map[int32][]*models.Zone),
+		compassZones:    make(map[int32]int32),
+		geo:             geodata.GetEngine(),
+		pathCache:       make(map[pathKey]cachedPath),
+		pathNodesLeft:   pathTickNodes,
+		playerPaths:     make(map[int32][]models.Position),
+		parties:         make(map[int32]*Party),
+		partyInvites:    make(map[int32]partyInvite),
+		trades:          make(map[int32]*tradeSession),
+		tradeRequests:   make(map[int32]tradeRequest),
+		raidBosses:      make(map[int32]int32),
+		minions:         make(map[int32][]int32),
+		minionLeaders:   make(map[int32]int32),
+		npcTemplates:    registry.GetNpcTemplateRegistry().Get,
 		expRate:         expRate,
 		spRate:          spRate,
+		dropRate:        1.0,
+		adenaRate:       1.0,
 	}
+	gl.rewardGroupOf = gl.partyRewardGroup
+	gl.OnZoneChange(gl.updateCompassZone)
+	return gl
 }
 
 // SetSkillData wires the skill template registry used for casting. Kept out of
@@ -170,17 +329,37 @@ func (gl *GameLoop) RegisterSpawnInfo(objectID int32, info SpawnInfo) {
 // RegisterWorldSpawns seeds npcSpawnInfo from the NPCs already loaded into the world,
 // treating each NPC's initial position/heading as its spawn point. Must be called once
 // at startup after the world is populated — otherwise npcSpawnInfo is empty and no NPC
-// ever respawns (RespawnEvent logs 'spawn info not found'). (l2go-c44)
+// ever respawns (RespawnEvent logs 'spawn info not found'). (l2go-c44) The respawn
+// delay and territory come from the NPC's spawn entry. Leaders then spawn their
+// minions beside them; the spawn list has single NPCs only.
 func (gl *GameLoop) RegisterWorldSpawns() {
 	npcs := gl.world.GetAllNPCs()
 	for _, npc := range npcs {
-		gl.npcSpawnInfo[npc.ObjectID] = SpawnInfo{
+		info := SpawnInfo{
 			TemplateID: npc.TemplateID,
 			Position:   npc.Position,
 			Heading:    npc.Heading,
 		}
+		if sd := npc.Spawn; sd != nil {
+			info.RespawnDelay = time.Duration(sd.RespawnDelay) * time.Second
+			info.RespawnRandom = time.Duration(sd.RespawnRandom) * time.Second
+			if sd.Territory != "" && gl.zoneRegistry != nil {
+				info.Territory, _ = gl.zoneRegistry.Get(sd.Territory)
+			}
+		}
+		gl.npcSpawnInfo[npc.ObjectID] = info
 	}
 	log.Info().Int("count", len(npcs)).Msg("Registered NPC spawn info for respawn")
+
+	// Leaders spawn with their minions.
+	leaders := 0
+	for _, npc := range npcs {
+		if npc.Template != nil && len(npc.Template.Minions) > 0 {
+			gl.spawnMinions(npc)
+			leaders++
+		}
+	}
+	log.Info().Int("leaders", leaders).Int("minions", len(gl.minionLeaders)).Msg("Spawned minion groups")
 }
 
 // Run starts the game loop. It blocks until ctx is cancelled.
@@ -192,6 +371,8 @@ func (gl *GameLoop) Run(ctx context.Context) error {
 	lastAutosave := time.Now()
 	lastRegen := time.Now()
 	lastBuffService := time.Now()
+	lastTradeCheck := time.Now()
+	lastNPCAI := time.Now()
 
 	// Tick-health instrumentation: how well the single loop goroutine keeps the
 	// 100ms cadence under load (scheduling gap, work time, command backlog). Owned
@@ -215,6 +396,7 @@ func (gl *GameLoop) Run(ctx context.Context) error {
 			cmdDepth := len(gl.commands) // backlog seen before draining
 
 			phaseStart := time.Now()
+			gl.pathNodesLeft = pathTickNodes
 			gl.tick()
 			gl.prom.observePhase("core", time.Since(phaseStart))
 
@@ -232,6 +414,7 @@ func (gl *GameLoop) Run(ctx context.Context) error {
 			if time.Since(lastAutosave) > autosaveInterval {
 				phaseStart = time.Now()
 				gl.autosaveOnlinePlayers()
+				gl.saveRaidBosses()
 				gl.prom.observePhase("autosave", time.Since(phaseStart))
 				lastAutosave = time.Now()
 			}
@@ -252,6 +435,30 @@ func (gl *GameLoop) Run(ctx context.Context) error {
 				lastBuffService = time.Now()
 			}
 
+			// Monster AI in active regions: aggro, hate decay, losing interest.
+			if time.Since(lastNPCAI) > npcAIInterval {
+				phaseStart = time.Now()
+				gl.thinkNPCs()
+				gl.prom.observePhase("npc_ai", time.Since(phaseStart))
+				lastNPCAI = time.Now()
+			}
+
+			// Cancel trades whose partners died, left or walked apart.
+			if len(gl.trades) > 0 && time.Since(lastTradeCheck) > tradeCheckInterval {
+				phaseStart = time.Now()
+				gl.checkTrades()
+				gl.prom.observePhase("trades", time.Since(phaseStart))
+				lastTradeCheck = time.Now()
+			}
+
+			// Path searches made anywhere since the last tick (chases, walks home,
+			// clicks into walls), already counted in the phases above; reported
+			// on their own so their share of the budget shows.
+			if gl.pathTime > 0 {
+				gl.prom.observePhase("pathfinding", gl.pathTime)
+				gl.pathTime = 0
+			}
+
 			// Record this tick's health and periodically report the window. work
 			// covers the whole iteration (tick + periodic subsystems above) so the
 			// report reflects the real per-tick budget against the 100ms deadline.
@@ -319,6 +526,7 @@ executeEvents:
 	// Execute all events whose time has come
 	now := time.Now()
 	gl.advancePlayerMovement(now)
+	gl.advanceNPCMovement(now)
 	for {
 		e := gl.events.Peek()
 		if e == nil || e.ExecuteAt().After(now) {
@@ -338,7 +546,7 @@ executeEvents:
 // authoritative — interpolating it here fought the client's own interpolation
 // (dual authority + speed mismatch) and produced rubber-band snaps for observers. (l2go-2ax)
 func serverDrivenMovement(i Intention) bool {
-	return i == IntentionAttack || i == IntentionInteract || i == IntentionCast
+	return i == IntentionAttack || i == IntentionInteract || i == IntentionCast || i == IntentionPickUp
 }
 
 func (gl *GameLoop) advancePlayerMovement(now time.Time) {
@@ -350,7 +558,8 @@ func (gl *GameLoop) advancePlayerMovement(now time.Time) {
 		}
 		// Skip client-authoritative ground walking (l2go-2ax): its position is synced
 		// from the client's ValidatePosition packets, not interpolated here.
-		if st, ok := gl.aiState[charID]; !ok || !serverDrivenMovement(st.Intention) {
+		// A walk round an obstacle is server-driven too: the loop picks its legs.
+		if st, ok := gl.aiState[charID]; (!ok || !serverDrivenMovement(st.Intention)) && !gl.followingPath(charID) {
 			continue
 		}
 		speed := usecase.PlayerMoveSpeed(gl.computePlayerStats(player), player.IsRunning)
@@ -359,6 +568,10 @@ func (gl *GameLoop) advancePlayerMovement(now time.Time) {
 		// Dynamic player-to-player visibility follows the authoritative server
 		// position: spawn/despawn other players as this one crosses their range.
 		gl.reconcilePlayerVisibility(charID)
+		gl.revalidatePlayerZones(charID)
+		if arrived && gl.nextPlayerLeg(player, pos, now) {
+			continue
+		}
 		if arrived {
 			player.IsMoving = false
 			player.MoveStartPos = models.Position{}
@@ -405,6 +618,86 @@ func (gl *GameLoop) processCommand(cmd Command) {
 		gl.handleSkillLearnInfo(c)
 	case CmdLearnSkill:
 		gl.handleLearnSkill(c)
+	case CmdPartyInvite:
+		gl.handlePartyInvite(c)
+	case CmdPartyAnswer:
+		gl.handlePartyAnswer(c)
+	case CmdPartyLeave:
+		gl.handlePartyLeave(c)
+	case CmdPartyKick:
+		gl.handlePartyKick(c)
+	case CmdPartyChangeLeader:
+		gl.handlePartyChangeLeader(c)
+	case CmdPartyLootChange:
+		gl.handlePartyLootChange(c)
+	case CmdPartyLootAnswer:
+		gl.handlePartyLootAnswer(c)
+	case CmdTradeRequest:
+		gl.handleTradeRequest(c)
+	case CmdTradeAnswer:
+		gl.handleTradeAnswer(c)
+	case CmdTradeAddItem:
+		gl.handleTradeAddItem(c)
+	case CmdTradeDone:
+		gl.handleTradeDone(c)
+	case CmdSitStand:
+		gl.handleSitStand(c)
+	case CmdPrivateStoreManage:
+		gl.handlePrivateStoreManage(c)
+	case CmdPrivateStoreSetSell:
+		gl.handlePrivateStoreSetSell(c)
+	case CmdPrivateStoreSetBuy:
+		gl.handlePrivateStoreSetBuy(c)
+	case CmdPrivateStoreMsg:
+		gl.handlePrivateStoreMsg(c)
+	case CmdPrivateStoreQuit:
+		gl.handlePrivateStoreQuit(c)
+	case CmdPrivateStoreBuy:
+		gl.handlePrivateStoreBuy(c)
+	case CmdPrivateStoreSell:
+		gl.handlePrivateStoreSell(c)
+	case CmdPrivateStoreDealDone:
+		gl.handlePrivateStoreDealDone(c)
+	case CmdOfflineTradeRestore:
+		gl.handleOfflineTradeRestore(c)
+	case CmdWarehouseOpen:
+		gl.handleWarehouseOpen(c)
+	case CmdWarehouseTransfer:
+		gl.handleWarehouseTransfer(c)
+	case CmdShopOpen:
+		gl.handleShopOpen(c)
+	case CmdShopBuy:
+		gl.handleShopBuy(c)
+	case CmdShopSell:
+		gl.handleShopSell(c)
+	case CmdShopRefund:
+		gl.handleShopRefund(c)
+	case CmdMultisellOpen:
+		gl.handleMultisellOpen(c)
+	case CmdMultisellChoose:
+		gl.handleMultisellChoose(c)
+	case CmdBypass:
+		gl.handleBypass(c)
+	case CmdLinkHtml:
+		gl.handleLinkHtml(c)
+	case CmdQuestList:
+		gl.handleQuestList(c)
+	case CmdQuestAbort:
+		gl.handleQuestAbort(c)
+	case CmdQuestionMark:
+		gl.handleQuestionMark(c)
+	case CmdPickUp:
+		gl.handlePickUp(c)
+	case CmdPickUpDone:
+		gl.handlePickUpDone(c)
+	case CmdDropItem:
+		gl.handleDropItem(c)
+	case CmdDestroyItem:
+		gl.handleDestroyItem(c)
+	case CmdCrystallizeItem:
+		gl.handleCrystallizeItem(c)
+	case CmdItemDropped:
+		gl.handleItemDropped(c)
 	}
 }
 
@@ -424,11 +717,18 @@ func (gl *GameLoop) handleAttackRequest(cmd CmdAttackRequest) {
 		if tgt.objectID == cmd.AttackerCharID {
 			return // can't attack self
 		}
-		// PvP gate (L2J checkPvpSkill / onForcedAttack): plain click needs the
-		// target flagged/PK; Ctrl force (Attack 0x01) always allowed but flags us.
-		allowed, flagAttacker := canAttackPlayer(tgt.player, cmd.Force, time.Now())
+		// PvP gate (L2J checkPvpSkill / onForcedAttack): never in a peace zone;
+		// plain click needs the target flagged/PK or both of us in an arena; Ctrl
+		// force (Attack 0x01) always allowed but flags us.
+		// A plain click on an open private store browses it instead (L2J
+		// L2PcInstance.onAction: store mode opens the store window).
+		if !cmd.Force && tgt.player.PrivateStore.Type.Open() {
+			gl.browsePrivateStore(attacker, tgt.player)
+			return
+		}
+		allowed, flagAttacker, refusal := gl.checkPvPAttack(attacker, tgt.player, cmd.Force, time.Now())
 		if !allowed {
-			gl.sendToPlayer(attacker, outclient.BuildSystemMessageNoParams(outclient.SysMsgIncorrectTarget))
+			gl.sendToPlayer(attacker, outclient.BuildSystemMessageNoParams(refusal))
 			if conn := gl.connections.GetConnection(cmd.AccountName); conn != nil {
 				_ = conn.Send(outclient.BuildActionFailed())
 			}
@@ -540,10 +840,23 @@ func (gl *GameLoop) handleMoveToLocation(cmd CmdMoveToLocation) {
 		gl.abortCast(player)
 	}
 	gl.setIntention(cmd.CharID, IntentionMoveTo, 0)
+
+	// A click into a wall: walk round it.
+	delete(gl.playerPaths, cmd.CharID)
+	if cmd.FindPath {
+		if player, ok := gl.world.GetPlayer(cmd.CharID); ok {
+			gl.startPlayerPath(player, cmd.Dest)
+		}
+	}
 }
 
 // handlePlayerDisconnected cleans up combat state for a disconnected player.
 func (gl *GameLoop) handlePlayerDisconnected(cmd CmdPlayerDisconnected) {
+	if cmd.OfflineTrade {
+		gl.enterOfflineTrade(cmd.CharID)
+		return
+	}
+
 	gl.stopAttacker(cmd.CharID)
 
 	// Drop skill cooldowns for the disconnected player (mirrors item-reuse cleanup).
@@ -557,16 +870,29 @@ func (gl *GameLoop) handlePlayerDisconnected(cmd CmdPlayerDisconnected) {
 	// Drop any pending interact/cast approach for the gone player. (l2go-bdb)
 	delete(gl.interactPending, cmd.CharID)
 	delete(gl.castPending, cmd.CharID)
+	delete(gl.playerPaths, cmd.CharID)
 
 	// Stop all NPCs attacking this player
 	gl.stopAllNPCAttacksOnPlayer(cmd.CharID)
 
+	// Leave the party (a leader hands it over, a two-member party disperses) and
+	// drop pending invitations. Uses only the party's own bookkeeping: the
+	// handler may already have removed the player from the world.
+	gl.leavePartyOnDisconnect(cmd.CharID)
+
+	// Close an open trade window and drop trade requests. A trade already handed
+	// to the worker for commit is no longer tracked here and completes atomically.
+	gl.leaveTradeOnDisconnect(cmd.CharID)
+
 	// Despawn this player from everyone who had them in view (and clear the known
 	// sets) so a later reconnect is spawned fresh.
 	gl.despawnPlayerFromAll(cmd.CharID)
 
 	// Release the player's activated region block (ref-counted, l2go-wdl).
 	gl.leavePlayerRegions(cmd.CharID)
+
+	// Leave every zone the player stood in, telling the zone listeners.
+	gl.leavePlayerZones(cmd.CharID)
 }
 
 // handlePlayerEnteredWorld activates regions around the player and establishes the
@@ -575,6 +901,7 @@ func (gl *GameLoop) handlePlayerDisconnected(cmd CmdPlayerDisconnected) {
 func (gl *GameLoop) handlePlayerEnteredWorld(cmd CmdPlayerEnteredWorld) {
 	gl.updatePlayerRegions(cmd.CharID, cmd.Position.X, cmd.Position.Y)
 	gl.reconcilePlayerVisibility(cmd.CharID)
+	gl.revalidatePlayerZones(cmd.CharID)
 }
 
 // handlePlayerMoved updates active regions and player-to-player visibility when a
@@ -598,6 +925,7 @@ func (gl *GameLoop) handlePlayerMoved(cmd CmdPlayerMoved) {
 	}
 
 	gl.reconcilePlayerVisibility(cmd.CharID)
+	gl.revalidatePlayerZones(cmd.CharID)
 }
 
 // enterCombatStance puts a player into the weapon-drawn combat stance on the first
@@ -637,8 +965,9 @@ func (gl *GameLoop) stopAttacker(charID int32) {
 	gl.clearIntention(charID)
 }
 
-// handleNPCDeath processes an NPC death: broadcasts Die, stops all attackers, schedules respawn.
-func (gl *GameLoop) handleNPCDeath(npc *models.NpcInstance) {
+// handleNPCDeath processes an NPC death: broadcasts Die, stops all attackers, schedules
+// respawn. killerID is the player who dealt the last blow.
+func (gl *GameLoop) handleNPCDeath(npc *models.NpcInstance, killerID int32) {
 	gl.prom.recordNPCKill()
 	npc.IsDead = true
 	npc.CurrentHP = 0
@@ -650,12 +979,20 @@ func (gl *GameLoop) handleNPCDeath(npc *models.NpcInstance) {
 	// Stop all players attacking this NPC
 	gl.stopAllAttackersOnTarget(npc.ObjectID)
 
-	// Stop NPC's own auto-attack
+	// Stop NPC's own auto-attack and cast; its effects end with it
 	gl.stopNPCAttack(npc.ObjectID)
+	delete(gl.npcCasts, npc.ObjectID)
+	npc.Effects = models.CharEffectList{}
 
 	// Award EXP/SP to attackers
 	gl.awardExpForNPCKill(npc)
 
+	// Roll the death drops for the looters
+	gl.dropLoot(npc, killerID)
+
+	// Quests hunting this NPC count the kill for the killer
+	gl.notifyQuestKill(killerID, npc)
+
 	now := time.Now()
 
 	// Schedule corpse decay
@@ -664,11 +1001,22 @@ func (gl *GameLoop) handleNPCDeath(npc *models.NpcInstance) {
 		ObjectID: npc.ObjectID,
 	})
 
-	// Schedule respawn
-	gl.events.Schedule(&RespawnEvent{
-		At:       now.Add(respawnDelay),
-		ObjectID: npc.ObjectID,
-	})
+	// A leader's minions leave with it.
+	gl.despawnMinions(npc.ObjectID)
+
+	if gl.isMinion(npc.ObjectID) {
+		gl.minionDied(npc)
+	} else {
+		// Schedule respawn after the spawn's own delay
+		respawnAt := now.Add(gl.npcSpawnInfo[npc.ObjectID].nextRespawnDelay(rand.Int63n))
+		gl.events.Schedule(&RespawnEvent{
+			At:       respawnAt,
+			ObjectID: npc.ObjectID,
+		})
+		if isRaidBoss(npc) {
+			gl.raidBossDied(npc, killerID, respawnAt)
+		}
+	}
 
 	log.Debug().
 		Int32("object_id", npc.ObjectID).
@@ -763,7 +1111,7 @@ func (gl *GameLoop) computePlayerStats(player *registry.PlayerWorldState) models
 
 // getNpcTemplate looks up an NPC template by ID.
 func (gl *GameLoop) getNpcTemplate(templateID int32) *models.NpcTemplate {
-	return registry.GetNpcTemplateRegistry().Get(templateID)
+	return gl.npcTemplates(templateID)
 }
 
 // nextObjectID generates a new unique object ID for NPCs.
@@ -0,0 +1,169 @@
+package gameloop
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/rs/zerolog/log"
+
+	"github.com/VerTox/l2go/internal/gameserver/models"
+	"github.com/VerTox/l2go/internal/gameserver/packets/outclient"
+	"github.com/VerTox/l2go/internal/gameserver/registry"
+)
+
+// teleporterNpcType is the datapack NPC type of gatekeepers.
+const teleporterNpcType = "L2Teleporter"
+
+// Gatekeeper refusals, shown in the NPC's dialogue window like L2J's
+// teleporter pages.
+const (
+	teleportInCombatHtml = "You cannot teleport while in combat. Come back when the fight is over."
+	teleportNobleHtml    = "Only nobles may go there."
+	teleportLevelHtml    = "You cannot go there at your level."
+)
+
+func init() {
+	RegisterBypass(BypassTeleportList, bypassTeleportList)
+	RegisterBypass(BypassTeleport, bypassTeleport)
+}
+
+// TeleportJob is a gatekeeper teleport the loop validated whose fee still has
+// to be paid: Price units of ItemID. The worker pays it off the loop and then
+// sends CmdTeleport back.
+type TeleportJob struct {
+	CharID int32
+	ItemID int32
+	Price  int64
+	Dest   models.Position
+}
+
+// SetTeleportSink wires the channel that receives paid teleports. Kept out of
+// New() like the other optional sinks; without it only free teleports go.
+func (gl *GameLoop) SetTeleportSink(sink chan<- TeleportJob) {
+	gl.teleportSink = sink
+}
+
+// SetFreeTeleportLevel makes gatekeeper teleports free for characters below
+// level (L2J MaxFreeTeleportLevel); 0 turns free teleports off. Noble
+// destinations always cost their pass.
+func (gl *GameLoop) SetFreeTeleportLevel(level int) {
+	gl.freeTeleportLevel = level
+}
+
+// enqueueTeleportJob hands a paid teleport to the teleport worker.
+// Non-blocking: with no sink or a full queue the job is dropped.
+func (gl *GameLoop) enqueueTeleportJob(job TeleportJob) {
+	if gl.teleportSink == nil {
+		return
+	}
+	select {
+	case gl.teleportSink <- job:
+	default:
+		log.Warn().Int32("char_id", job.CharID).Msg("teleport sink full, dropping teleport job")
+	}
+}
+
+// IsTeleporter reports whether an NPC template is a gatekeeper.
+func IsTeleporter(tmpl *models.NpcTemplate) bool {
+	return tmpl != nil && tmpl.Type == teleporterNpcType
+}
+
+// teleportPrice is what loc costs the player: nothing below the free level,
+// except for noble destinations.
+func (gl *GameLoop) teleportPrice(char *models.Character, loc *registry.TeleportLocation) int64 {
+	if !loc.Noble && char.Level < gl.freeTeleportLevel {
+		return 0
+	}
+	return loc.Price
+}
+
+// gatekeeperList returns the NPC at npcObjID and teleport list listID if the
+// player may use them now: a dialogue NPC in reach that the list belongs to.
+func (gl *GameLoop) gatekeeperList(player *registry.PlayerWorldState, npcObjID, listID int32) (*models.NpcInstance, *registry.TeleportList, bool) {
+	npc, ok := gl.dialogNpc(player, npcObjID)
+	if !ok {
+		return nil, nil, false
+	}
+	list, ok := registry.GetTeleportRegistry().Get(listID)
+	if !ok || !list.AllowsNpc(npc.TemplateID) {
+		return nil, nil, false
+	}
+	return npc, list, true
+}
+
+// bypassTeleportList shows the destinations of a teleport list at what each
+// costs the player.
+func bypassTeleportList(gl *GameLoop, player *registry.PlayerWorldState, npcObjID int32, arg string) {
+	listID, ok := bypassListID(arg)
+	if !ok {
+		return
+	}
+	npc, list, ok := gl.gatekeeperList(player, npcObjID, listID)
+	if !ok {
+		return
+	}
+	var b strings.Builder
+	b.WriteString("<html><body>" + npc.Template.Name + ":<br>Where would you like to go?<br><br>")
+	for i := range list.Locations {
+		loc := &list.Locations[i]
+		cost := "free"
+		if price := gl.teleportPrice(player.Character, loc); price > 0 {
+			cost = strconv.FormatInt(price, 10) + " " + registry.GetItemName(loc.ItemID)
+		}
+		b.WriteString("<a action=\"bypass -h npc_" + strconv.Itoa(int(npc.ObjectID)) + "_" + BypassTeleport + " " +
+			strconv.Itoa(int(listID)) + " " + strconv.Itoa(int(loc.ID)) + "\">" + loc.Name + " - " + cost + "</a><br>")
+	}
+	b.WriteString("</body></html>")
+	gl.sendToPlayer(player, outclient.BuildNpcHtmlMessage(npc.ObjectID, b.String()))
+}
+
+// bypassTeleport sends the player to a destination of a teleport list (L2J
+// L2TeleporterInstance.doTeleport): refused in combat, off-level or, for noble
+// destinations, to non-nobles. A free teleport goes at once; a paid one goes to
+// the teleport worker, which takes the fee first.
+func bypassTeleport(gl *GameLoop, player *registry.PlayerWorldState, npcObjID int32, arg string) {
+	listArg, locArg, _ := strings.Cut(arg, " ")
+	listID, ok := bypassListID(listArg)
+	if !ok {
+		return
+	}
+	locID, err := strconv.ParseInt(locArg, 10, 32)
+	if err != nil {
+		return
+	}
+	npc, list, ok := gl.gatekeeperList(player, npcObjID, listID)
+	if !ok {
+		return
+	}
+	loc, ok := list.Location(int32(locID))
+	if !ok {
+		return
+	}
+	if gl.trades[player.CharID] != nil || player.PrivateStore.Type != registry.StoreNone || player.IsTeleporting {
+		gl.sendToPlayer(player, outclient.BuildActionFailed())
+		return
+	}
+
+	char := player.Character
+	refuse := ""
+	switch {
+	case player.InCombat:
+		refuse = teleportInCombatHtml
+	case loc.Noble && !char.IsNoble():
+		refuse = teleportNobleHtml
+	case loc.MinLevel > 0 && char.Level < loc.MinLevel, loc.MaxLevel > 0 && char.Level > loc.MaxLevel:
+		refuse = teleportLevelHtml
+	}
+	if refuse != "" {
+		html := "<html><body>" + npc.Template.Name + ":<br>" + refuse + "</body></html>"
+		gl.sendToPlayer(player, outclient.BuildNpcHtmlMessage(npc.ObjectID, html))
+		return
+	}
+
+	price := gl.teleportPrice(char, loc)
+	if price == 0 {
+		gl.handleTeleport(CmdTeleport{CharID: player.CharID, Dest: loc.Position})
+		return
+	}
+	gl.enqueueTeleportJob(TeleportJob{CharID: player.CharID, ItemID: loc.ItemID, Price: price, Dest: loc.Position})
+}
@@ -0,0 +1,117 @@
+package gameloop
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/VerTox/l2go/internal/gameserver/models"
+	"github.com/VerTox/l2go/internal/gameserver/registry"
+)
+
+// loadTestTeleports loads teleport list 6000201 at NPC 60002 into the global
+// registry for the duration of the test.
+func loadTestTeleports(t *testing.T) {
+	t.Helper()
+	dir := t.TempDir()
+	xml := `<list><npcs><npc>60002</npc></npcs>
+		<location id="1" name="Gludin Village" x="-80826" y="149775" z="-3043" price="9400" />
+		<location id="2" name="Ketra Orc Outpost" x="146990" y="-67128" z="-3640" price="1" itemId="6651" noble="true" />
+		<location id="3" name="Hunters Village" x="117110" y="76883" z="-2695" price="30000" minLevel="20" />
+	</list>`
+	if err := os.WriteFile(filepath.Join(dir, "6000201.xml"), []byte(xml), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := registry.GetTeleportRegistry().LoadFromDirectory(dir); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = registry.GetTeleportRegistry().LoadFromDirectory(t.TempDir()) })
+}
+
+func addGatekeeper(gl *GameLoop, objectID int32, pos models.Position) {
+	gl.world.AddNPC(&models.NpcInstance{
+		ObjectID:   objectID,
+		TemplateID: 60002,
+		Position:   pos,
+		CurrentHP:  100,
+		Template:   &models.NpcTemplate{ID: 60002, Name: "Selanta", Type: "L2Teleporter"},
+	})
+}
+
+func TestGatekeeper_PaidTeleportGoesToWorker(t *testing.T) {
+	loadTestTeleports(t)
+	gl, p := newTestLoopWithPlayer(t)
+	sink := make(chan TeleportJob, 4)
+	gl.SetTeleportSink(sink)
+	addGatekeeper(gl, 2000, models.Position{X: 100})
+	addMerchant(gl, 2001, 30301, models.Position{X: 100})
+
+	npc, _ := gl.world.GetNPC(2000)
+	if html := NpcDialogHtml(p, npc); !strings.Contains(html, "bypass -h teleport_list 6000201") {
+		t.Fatalf("gatekeeper dialogue = %q", html)
+	}
+
+	// The list belongs to the gatekeeper only.
+	gl.handleBypass(CmdBypass{CharID: 7, Command: "npc_2001_goto 6000201 1"})
+	if len(sink) != 0 {
+		t.Fatal("another NPC must not use the list")
+	}
+
+	gl.handleBypass(CmdBypass{CharID: 7, Command: "npc_2000_goto 6000201 1"})
+	job := <-sink
+	if job.ItemID != models.ItemIDAdena || job.Price != 9400 || job.Dest != (models.Position{X: -80826, Y: 149775, Z: -3043}) {
+		t.Fatalf("job = %+v", job)
+	}
+	if p.Position.X != 0 {
+		t.Fatal("a paid teleport waits for the fee")
+	}
+}
+
+func TestGatekeeper_FreeBelowLevel(t *testing.T) {
+	loadTestTeleports(t)
+	gl, p := newTestLoopWithPlayer(t)
+	sink := make(chan TeleportJob, 4)
+	gl.SetTeleportSink(sink)
+	gl.SetFreeTeleportLevel(20)
+	addGatekeeper(gl, 2000, models.Position{X: 100})
+	p.Character.Noble = true
+
+	// Noble destinations still cost their pass.
+	gl.handleBypass(CmdBypass{CharID: 7, Command: "npc_2000_goto 6000201 2"})
+	if job := <-sink; job.ItemID != 6651 || job.Price != 1 {
+		t.Fatalf("noble job = %+v", job)
+	}
+
+	gl.handleBypass(CmdBypass{CharID: 7, Command: "npc_2000_goto 6000201 1"})
+	if len(sink) != 0 || p.Position.X != -80826 || !p.IsTeleporting {
+		t.Fatalf("a free teleport must go at once: position %+v", p.Position)
+	}
+}
+
+func TestGatekeeper_Refusals(t *testing.T) {
+	loadTestTeleports(t)
+	gl, p := newTestLoopWithPlayer(t)
+	sink := make(chan TeleportJob, 4)
+	gl.SetTeleportSink(sink)
+	addGatekeeper(gl, 2000, models.Position{X: 100})
+
+	// Below the destination's level, a non-noble at a noble destination, an
+	// unknown destination and a fight are all refused.
+	gl.handleBypass(CmdBypass{CharID: 7, Command: "npc_2000_goto 6000201 3"})
+	gl.handleBypass(CmdBypass{CharID: 7, Command: "npc_2000_goto 6000201 2"})
+	gl.handleBypass(CmdBypass{CharID: 7, Command: "npc_2000_goto 6000201 9"})
+	p.InCombat = true
+	gl.handleBypass(CmdBypass{CharID: 7, Command: "npc_2000_goto 6000201 1"})
+	if len(sink) != 0 {
+		t.Fatalf("%d refused teleports were queued", len(sink))
+	}
+
+	// Out of reach of the gatekeeper.
+	p.InCombat = false
+	p.Position = models.Position{X: 1000}
+	gl.handleBypass(CmdBypass{CharID: 7, Command: "npc_2000_goto 6000201 1"})
+	if len(sink) != 0 {
+		t.Fatal("a gatekeeper out of reach must not teleport")
+	}
+}
@@ -0,0 +1,336 @@
+package gameloop
+
+import (
+	"math/rand"
+	"time"
+
+	"github.com/rs/zerolog/log"
+
+	"github.com/VerTox/l2go/internal/gameserver/models"
+	"github.com/VerTox/l2go/internal/gameserver/packets/outclient"
+	"github.com/VerTox/l2go/internal/gameserver/registry"
+	"github.com/VerTox/l2go/internal/gameserver/usecase"
+)
+
+const (
+	// itemDropScatter is how far from a dead monster its drops land (L2J
+	// L2Attackable.dropItem, randDropLim).
+	itemDropScatter = 70
+	// lootProtection is how long a monster's drops stay reserved for its
+	// owner's party (L2J ItemsAutoDestroy/DropProtection, 15 s).
+	lootProtection = 15 * time.Second
+	// groundItemLifetime is how long an item lies on the ground before it
+	// decays (L2J AutoDestroyDroppedItemAfter, 600 s).
+	groundItemLifetime = 10 * time.Minute
+	// pickupRange is how close a player walks up to an item to pick it up
+	// (L2J L2PlayerAI.thinkPickUp, moveToPawn offset 36).
+	pickupRange = 36
+)
+
+// GroundItemJobKind is the kind of database work a GroundItemJob asks for.
+type GroundItemJobKind int
+
+const (
+	// GroundItemPickUp puts the item into CharID's inventory; the worker
+	// reports back with CmdPickUpDone.
+	GroundItemPickUp GroundItemJobKind = iota
+	// GroundItemDestroy deletes the row of a dropped item that decayed.
+	GroundItemDestroy
+)
+
+// GroundItemJob hands the database side of a ground item to the ground item
+// worker. Item is a copy taken when the job was queued.
+type GroundItemJob struct {
+	Kind           GroundItemJobKind
+	CharID         int32 // who receives the item
+	PickerID       int32 // who picked it up: CharID, or a party member who found it for CharID
+	Item           models.GroundItem
+	InventorySlots int
+}
+
+// SetGroundItemSink wires the channel that receives ground item jobs. Kept out
+// of New() like the other optional sinks; without it items cannot be picked
+// up.
+func (gl *GameLoop) SetGroundItemSink(sink chan<- GroundItemJob) {
+	gl.groundItemSink = sink
+}
+
+// enqueueGroundItemJob hands a job to the ground item worker. Non-blocking:
+// reports false with no sink or a full queue.
+func (gl *GameLoop) enqueueGroundItemJob(job GroundItemJob) bool {
+	if gl.groundItemSink == nil {
+		return false
+	}
+	select {
+	case gl.groundItemSink <- job:
+		return true
+	default:
+		log.Warn().Int32("char_id", job.CharID).Int32("obj_id", job.Item.ObjectID).Msg("ground item sink full, dropping job")
+		return false
+	}
+}
+
+// spawnGroundItem puts item on the ground under a new object id, shows it
+// falling to the players around and schedules its decay.
+func (gl *GameLoop) spawnGroundItem(item *models.GroundItem) {
+	item.ObjectID = gl.nextObjectID()
+	gl.world.AddItem(item)
+
+	data := outclient.BuildDropItem(item.DropperID, item.ObjectID, item.ItemID,
+		item.Position.X, item.Position.Y, item.Position.Z, itemStackable(item.ItemID), item.Count)
+	for _, p := range gl.world.GetPlayersInRange(item.Position, registry.VisibilityWatchRadius) {
+		gl.sendToPlayer(p, data)
+		p.KnownItems[item.ObjectID] = true
+	}
+
+	gl.events.Schedule(&ItemDecayEvent{
+		At:       time.Now().Add(groundItemLifetime),
+		ObjectID: item.ObjectID,
+	})
+}
+
+// removeGroundItem takes item off the ground and out of sight. A non-zero
+// pickerID shows that player picking it up first.
+func (gl *GameLoop) removeGroundItem(item *models.GroundItem, pickerID int32) {
+	var getItem []byte
+	if pickerID != 0 {
+		getItem = outclient.BuildGetItem(pickerID, item.ObjectID, item.Position.X, item.Position.Y, item.Position.Z)
+	}
+	deleteObject := outclient.BuildDeleteObject(item.ObjectID)
+	for _, p := range gl.world.GetPlayersInRange(item.Position, registry.VisibilityForgetRadius) {
+		if !p.KnownItems[item.ObjectID] {
+			continue
+		}
+		if getItem != nil {
+			gl.sendToPlayer(p, getItem)
+		}
+		gl.sendToPlayer(p, deleteObject)
+		delete(p.KnownItems, item.ObjectID)
+	}
+	gl.world.RemoveItem(item.ObjectID)
+}
+
+// reconcileItemVisibility shows the player the items that came within watch
+// range and forgets the ones beyond the forget radius, like
+// reconcilePlayerVisibility does for players.
+func (gl *GameLoop) reconcileItemVisibility(player *registry.PlayerWorldState) {
+	for _, item := range gl.world.GetItemsInRange(player.Position, registry.VisibilityWatchRadius) {
+		if player.KnownItems[item.ObjectID] {
+			continue
+		}
+		gl.sendToPlayer(player, outclient.BuildSpawnItem(item.ObjectID, item.ItemID,
+			item.Position.X, item.Position.Y, item.Position.Z, itemStackable(item.ItemID), item.Count))
+		player.KnownItems[item.ObjectID] = true
+	}
+	if len(player.KnownItems) == 0 {
+		return
+	}
+
+	keep := make(map[int32]bool)
+	for _, item := range gl.world.GetItemsInRange(player.Position, registry.VisibilityForgetRadius) {
+		keep[item.ObjectID] = true
+	}
+	for id := range player.KnownItems {
+		if keep[id] {
+			continue
+		}
+		gl.sendToPlayer(player, outclient.BuildDeleteObject(id))
+		delete(player.KnownItems, id)
+	}
+}
+
+// handlePickUp starts a player walking to an item to pick it up (L2J
+// AI_INTENTION_PICK_UP); the pickup happens on arrival.
+func (gl *GameLoop) handlePickUp(cmd CmdPickUp) {
+	player, ok := gl.world.GetPlayer(cmd.CharID)
+	if !ok || player.Character == nil || player.Character.CurrentHP <= 0 || player.IsTeleporting {
+		return
+	}
+	item, ok := gl.world.GetItem(cmd.ObjectID)
+	if !ok {
+		gl.sendToPlayer(player, outclient.BuildActionFailed())
+		return
+	}
+
+	// A new intention abandons the previous one.
+	if cs, ok := gl.combatState[cmd.CharID]; ok && cs.IsAutoAttacking {
+		gl.stopAttacker(cmd.CharID)
+	}
+	delete(gl.interactPending, cmd.CharID)
+	delete(gl.castPending, cmd.CharID)
+
+	gl.setIntention(cmd.CharID, IntentionPickUp, cmd.ObjectID)
+	if withinPickupRange(player.Position, item.Position) {
+		gl.pickUpItem(player, item)
+		return
+	}
+	gl.startMoveToTargetPos(player, item.ObjectID, item.Position, pickupRange)
+	gl.events.Schedule(&PickUpApproachEvent{
+		At:       time.Now().Add(300 * time.Millisecond),
+		CharID:   cmd.CharID,
+		ObjectID: cmd.ObjectID,
+	})
+}
+
+// pickUpItem picks up an item the player has reached. The item is reserved
+// for its receiver while the ground item worker moves it into the inventory;
+// CmdPickUpDone then takes it off the ground, or frees it if that failed.
+func (gl *GameLoop) pickUpItem(player *registry.PlayerWorldState, item *models.GroundItem) {
+	gl.clearIntention(player.CharID)
+	if item.PickingUp != 0 {
+		gl.sendToPlayer(player, outclient.BuildActionFailed())
+		return
+	}
+	if item.IsProtectedFrom(player.CharID, time.Now()) && !gl.sameParty(player.CharID, item.OwnerID) {
+		gl.sendToPlayer(player, failedToPickUpMessage(item))
+		gl.sendToPlayer(player, outclient.BuildActionFailed())
+		return
+	}
+
+	// Items picked up in a party go to whoever its loot mode picks. L2J
+	// splits adena between the members around; here the finder keeps it.
+	receiverID := player.CharID
+	if item.ItemID != models.ItemIDAdena {
+		receiverID = gl.partyLooter(player.CharID, item.Position, false)
+	}
+	receiver, ok := gl.world.GetPlayer(receiverID)
+	if !ok || receiver.Character == nil {
+		receiver, receiverID = player, player.CharID
+	}
+
+	item.PickingUp = receiverID
+	if !gl.enqueueGroundItemJob(GroundItemJob{
+		Kind:           GroundItemPickUp,
+		CharID:         receiverID,
+		PickerID:       player.CharID,
+		Item:           *item,
+		InventorySlots: usecase.InventoryLimit(receiver.Character),
+	}) {
+		item.PickingUp = 0
+		gl.sendToPlayer(player, outclient.BuildActionFailed())
+	}
+}
+
+// handlePickUpDone settles a pickup the ground item worker finished.
+func (gl *GameLoop) handlePickUpDone(cmd CmdPickUpDone) {
+	item, ok := gl.world.GetItem(cmd.ObjectID)
+	if !ok || item.PickingUp != cmd.CharID {
+		return
+	}
+	if !cmd.OK {
+		item.PickingUp = 0
+		return
+	}
+	gl.removeGroundItem(item, cmd.PickerID)
+	if receiver, ok := gl.world.GetPlayer(cmd.CharID); ok {
+		gl.notifyQuestItemPickup(receiver, item.ItemID, item.Count)
+	}
+}
+
+// sameParty reports whether two characters are in the same party.
+func (gl *GameLoop) sameParty(a, b int32) bool {
+	p := gl.partyOf(a)
+	return p != nil && p == gl.partyOf(b)
+}
+
+// withinPickupRange reports whether a player at pos can reach an item at
+// itemPos. The item counts as reached a little beyond pickupRange, since the
+// approach stops right at it.
+func withinPickupRange(pos, itemPos models.Position) bool {
+	const reach = pickupRange + 14
+	dx, dy := pos.X-itemPos.X, pos.Y-itemPos.Y
+	return dx*dx+dy*dy <= reach*reach
+}
+
+// failedToPickUpMessage builds the message a player gets when someone else's
+// loot is still protected from them.
+func failedToPickUpMessage(item *models.GroundItem) []byte {
+	switch {
+	case item.ItemID == models.ItemIDAdena:
+		return outclient.NewSystemMessage(outclient.SysMsgFailedToPickUpS1Adena).AddLong(item.Count).Build()
+	case item.Count > 1:
+		return outclient.NewSystemMessage(outclient.SysMsgFailedToPickUpS2S1).AddItemName(item.ItemID).AddLong(item.Count).Build()
+	default:
+		return outclient.NewSystemMessage(outclient.SysMsgFailedToPickUpS1).AddItemName(item.ItemID).Build()
+	}
+}
+
+// itemStackable reports whether the client should show an item's count.
+func itemStackable(itemID int32) bool {
+	tmpl := registry.GetItemTemplateRegistry().Get(itemID)
+	return tmpl != nil && tmpl.Stackable
+}
+
+// scatterDrop picks where a drop from a monster at pos lands.
+func scatterDrop(pos models.Position) models.Position {
+	return models.Position{
+		X: pos.X + rand.Intn(2*itemDropScatter+1) - itemDropScatter,
+		Y: pos.Y + rand.Intn(2*itemDropScatter+1) - itemDropScatter,
+		Z: pos.Z,
+	}
+}
+
+// PickUpApproachEvent is the heartbeat of a player walking to an item: it
+// picks the item up once in range. The pick-up intention is its liveness key;
+// any new intention stops it.
+type PickUpApproachEvent struct {
+	At       time.Time
+	CharID   int32
+	ObjectID int32
+}
+
+func (e *PickUpApproachEvent) ExecuteAt() time.Time { return e.At }
+
+func (e *PickUpApproachEvent) Execute(gl *GameLoop) {
+	st, ok := gl.aiState[e.CharID]
+	if !ok || st.Intention != IntentionPickUp || st.TargetObjectID != e.ObjectID {
+		return
+	}
+	player, ok := gl.world.GetPlayer(e.CharID)
+	if !ok {
+		return
+	}
+	item, ok := gl.world.GetItem(e.ObjectID)
+	if !ok {
+		gl.clearIntention(e.CharID)
+		gl.sendToPlayer(player, outclient.BuildActionFailed())
+		return
+	}
+	if withinPickupRange(player.Position, item.Position) {
+		gl.pickUpItem(player, item)
+		return
+	}
+	if !player.IsMoving {
+		gl.startMoveToTargetPos(player, item.ObjectID, item.Position, pickupRange)
+	}
+	gl.events.Schedule(&PickUpApproachEvent{
+		At:       time.Now().Add(400 * time.Millisecond),
+		CharID:   e.CharID,
+		ObjectID: e.ObjectID,
+	})
+}
+
+// ItemDecayEvent takes an item off the ground once it has lain there for
+// groundItemLifetime (L2J ItemsAutoDestroy). An item being picked up is left
+// to the pickup and checked again a second later.
+type ItemDecayEvent struct {
+	At       time.Time
+	ObjectID int32
+}
+
+func (e *ItemDecayEvent) ExecuteAt() time.Time { return e.At }
+
+func (e *ItemDecayEvent) Execute(gl *GameLoop) {
+	item, ok := gl.world.GetItem(e.ObjectID)
+	if !ok {
+		return
+	}
+	if item.PickingUp != 0 {
+		gl.events.Schedule(&ItemDecayEvent{At: time.Now().Add(time.Second), ObjectID: e.ObjectID})
+		return
+	}
+	gl.removeGroundItem(item, 0)
+	if item.StoredID != 0 {
+		gl.enqueueGroundItemJob(GroundItemJob{Kind: GroundItemDestroy, Item: *item})
+	}
+}
@@ -0,0 +1,149 @@
+package gameloop
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/VerTox/l2go/internal/gameserver/models"
+)
+
+// dropTestItem puts an item owned by ownerID on the ground at pos.
+func dropTestItem(gl *GameLoop, pos models.Position, ownerID int32) *models.GroundItem {
+	item := &models.GroundItem{
+		ItemID:         1869,
+		Count:          2,
+		Position:       pos,
+		OwnerID:        ownerID,
+		ProtectedUntil: time.Now().Add(lootProtection),
+	}
+	gl.spawnGroundItem(item)
+	return item
+}
+
+func TestPickUp_LootProtectionAndParty(t *testing.T) {
+	gl, _ := newTestLoopWithPlayer(t)
+	addPlayer(t, gl, 8, "acc2", models.Position{X: 10})
+	sink := make(chan GroundItemJob, 4)
+	gl.SetGroundItemSink(sink)
+	item := dropTestItem(gl, models.Position{X: 20}, 7)
+
+	// Someone else's loot stays protected from a stranger...
+	gl.handlePickUp(CmdPickUp{CharID: 8, ObjectID: item.ObjectID})
+	if len(sink) != 0 || item.PickingUp != 0 {
+		t.Fatal("a stranger must not pick up protected loot")
+	}
+
+	// ...but not from the owner's party.
+	joinParty(t, gl, 7, 8)
+	gl.handlePickUp(CmdPickUp{CharID: 8, ObjectID: item.ObjectID})
+	job := <-sink
+	if job.Kind != GroundItemPickUp || job.CharID != 8 || job.PickerID != 8 || job.Item.ObjectID != item.ObjectID {
+		t.Fatalf("pickup job = %+v", job)
+	}
+	if item.PickingUp != 8 {
+		t.Fatal("the item is reserved while the worker moves it")
+	}
+
+	// A reserved item cannot be picked up twice.
+	gl.handlePickUp(CmdPickUp{CharID: 7, ObjectID: item.ObjectID})
+	if len(sink) != 0 {
+		t.Fatal("a reserved item was handed out twice")
+	}
+
+	// A failed pickup frees the item; a finished one takes it off the ground.
+	gl.handlePickUpDone(CmdPickUpDone{CharID: 8, PickerID: 8, ObjectID: item.ObjectID})
+	if item.PickingUp != 0 {
+		t.Fatal("a failed pickup must free the item")
+	}
+	gl.handlePickUp(CmdPickUp{CharID: 7, ObjectID: item.ObjectID})
+	<-sink
+	gl.handlePickUpDone(CmdPickUpDone{CharID: 7, PickerID: 7, ObjectID: item.ObjectID, OK: true})
+	if _, ok := gl.world.GetItem(item.ObjectID); ok {
+		t.Fatal("a picked up item must leave the ground")
+	}
+	for _, id := range []int32{7, 8} {
+		if p, _ := gl.world.GetPlayer(id); p.KnownItems[item.ObjectID] {
+			t.Fatalf("player %d still knows the picked up item", id)
+		}
+	}
+}
+
+func TestPickUp_WalksToTheItem(t *testing.T) {
+	gl, p := newTestLoopWithPlayer(t)
+	sink := make(chan GroundItemJob, 4)
+	gl.SetGroundItemSink(sink)
+	item := dropTestItem(gl, models.Position{X: 500}, 0)
+
+	gl.handlePickUp(CmdPickUp{CharID: 7, ObjectID: item.ObjectID})
+	if len(sink) != 0 || !p.IsMoving || gl.aiState[7].Intention != IntentionPickUp {
+		t.Fatal("a far item is walked to first")
+	}
+	(&PickUpApproachEvent{CharID: 7, ObjectID: item.ObjectID}).Execute(gl)
+	if len(sink) != 0 {
+		t.Fatal("picked up from afar")
+	}
+
+	_ = gl.world.UpdatePlayerPosition(context.Background(), 7, p.MoveDestination, 0)
+	(&PickUpApproachEvent{CharID: 7, ObjectID: item.ObjectID}).Execute(gl)
+	if job := <-sink; job.CharID != 7 || job.Item.ObjectID != item.ObjectID {
+		t.Fatalf("pickup job = %+v", job)
+	}
+
+	// A ground move abandons a pickup under way.
+	other := dropTestItem(gl, models.Position{X: -500}, 0)
+	gl.handlePickUp(CmdPickUp{CharID: 7, ObjectID: other.ObjectID})
+	gl.handleMoveToLocation(CmdMoveToLocation{CharID: 7})
+	_ = gl.world.UpdatePlayerPosition(context.Background(), 7, other.Position, 0)
+	(&PickUpApproachEvent{CharID: 7, ObjectID: other.ObjectID}).Execute(gl)
+	if len(sink) != 0 {
+		t.Fatal("an abandoned pickup went on")
+	}
+}
+
+func TestItemDecay(t *testing.T) {
+	gl, p := newTestLoopWithPlayer(t)
+	sink := make(chan GroundItemJob, 4)
+	gl.SetGroundItemSink(sink)
+
+	// A monster drop just vanishes.
+	item := dropTestItem(gl, models.Position{X: 20}, 0)
+	(&ItemDecayEvent{ObjectID: item.ObjectID}).Execute(gl)
+	if _, ok := gl.world.GetItem(item.ObjectID); ok || p.KnownItems[item.ObjectID] || len(sink) != 0 {
+		t.Fatal("a decayed monster drop must vanish")
+	}
+
+	// A dropped item's row is deleted, but not while it is being picked up.
+	item = &models.GroundItem{ItemID: 1, Count: 1, StoredID: 55}
+	gl.spawnGroundItem(item)
+	item.PickingUp = 7
+	(&ItemDecayEvent{ObjectID: item.ObjectID}).Execute(gl)
+	if _, ok := gl.world.GetItem(item.ObjectID); !ok {
+		t.Fatal("an item being picked up must not decay")
+	}
+	item.PickingUp = 0
+	(&ItemDecayEvent{ObjectID: item.ObjectID}).Execute(gl)
+	if job := <-sink; job.Kind != GroundItemDestroy || job.Item.StoredID != 55 {
+		t.Fatalf("destroy job = %+v", job)
+	}
+}
+
+func TestReconcileItemVisibility(t *testing.T) {
+	gl, p := newTestLoopWithPlayer(t)
+	item := dropTestItem(gl, models.Position{X: 5000}, 0)
+	if p.KnownItems[item.ObjectID] {
+		t.Fatal("a drop out of range must not be shown")
+	}
+
+	_ = gl.world.UpdatePlayerPosition(context.Background(), 7, models.Position{X: 4900}, 0)
+	gl.reconcilePlayerVisibility(7)
+	if !p.KnownItems[item.ObjectID] {
+		t.Fatal("an item coming into range must be shown")
+	}
+
+	_ = gl.world.UpdatePlayerPosition(context.Background(), 7, models.Position{X: 0}, 0)
+	gl.reconcilePlayerVisibility(7)
+	if p.KnownItems[item.ObjectID] {
+		t.Fatal("an item out of range must be forgotten")
+	}
+}
@@ -1,29 +1,91 @@
 package gameloop
 
-// HateList tracks accumulated threat (hate) per attacker for an NPC.
+// HateList tracks, per attacker, an NPC's hate and the damage it took (L2J
+// AggroInfo). Hate picks whom the NPC fights and decays; damage only grows
+// and shares out the kill's rewards.
 type HateList struct {
-	entries map[int32]int64 // charID -> total damage/hate
+	entries map[int32]*hateEntry // charID -> hate and damage
+}
+
+type hateEntry struct {
+	hate   int64
+	damage int64
 }
 
 // NewHateList creates a new empty hate list.
 func NewHateList() *HateList {
 	return &HateList{
-		entries: make(map[int32]int64),
+		entries: make(map[int32]*hateEntry),
+	}
+}
+
+func (hl *HateList) entry(charID int32) *hateEntry {
+	e, ok := hl.entries[charID]
+	if !ok {
+		e = &hateEntry{}
+		hl.entries[charID] = e
 	}
+	return e
 }
 
-// AddHate adds hate for a character.
+// AddHate adds hate for damage a character dealt; the damage also counts
+// toward the kill's rewards.
 func (hl *HateList) AddHate(charID int32, amount int64) {
-	hl.entries[charID] += amount
+	e := hl.entry(charID)
+	e.hate += amount
+	e.damage += amount
+}
+
+// AddAggro adds hate alone, for a character the NPC noticed rather than one
+// that hurt it.
+func (hl *HateList) AddAggro(charID int32, amount int64) {
+	hl.entry(charID).hate += amount
+}
+
+// Hate returns a character's current hate.
+func (hl *HateList) Hate(charID int32) int64 {
+	if e, ok := hl.entries[charID]; ok {
+		return e.hate
+	}
+	return 0
 }
 
-// GetTopAttacker returns the charID with the highest hate, or 0 if empty.
+// StopHating drops a character's hate; the damage it dealt still counts
+// (L2J stopHating).
+func (hl *HateList) StopHating(charID int32) {
+	if e, ok := hl.entries[charID]; ok {
+		e.hate = 0
+	}
+}
+
+// Decay lowers a character's hate by percent of itself, and by at least 1.
+func (hl *HateList) Decay(charID int32, percent int64) {
+	if e, ok := hl.entries[charID]; ok && e.hate > 0 {
+		e.hate -= max(e.hate*percent/100, 1)
+	}
+}
+
+// GetTopAttacker returns the most hated charID, or 0 if nobody is hated.
 func (hl *HateList) GetTopAttacker() int32 {
 	var topID int32
 	var topHate int64
-	for charID, hate := range hl.entries {
-		if hate > topHate {
-			topHate = hate
+	for charID, e := range hl.entries {
+		if e.hate > topHate {
+			topHate = e.hate
+			topID = charID
+		}
+	}
+	return topID
+}
+
+// GetTopDamageDealer returns the charID that dealt the most damage, or 0 if
+// nobody hurt the NPC.
+func (hl *HateList) GetTopDamageDealer() int32 {
+	var topID int32
+	var topDamage int64
+	for charID, e := range hl.entries {
+		if e.damage > topDamage {
+			topDamage = e.damage
 			topID = charID
 		}
 	}
@@ -41,7 +103,7 @@ func (hl *HateList) GetAllAttackers() []int32 {
 
 // Clear resets the hate list.
 func (hl *HateList) Clear() {
-	hl.entries = make(map[int32]int64)
+	hl.entries = make(map[int32]*hateEntry)
 }
 
 // IsEmpty returns true if no one has hate on this NPC.
@@ -18,12 +18,13 @@ const (
 	IntentionInteract
 	IntentionCast   // scaffold — skill system not implemented yet
 	IntentionFollow // scaffold — follow not implemented yet
+	IntentionPickUp
 )
 
 // PlayerAIState holds a player's current intention and its target.
 type PlayerAIState struct {
 	Intention      Intention
-	TargetObjectID int32           // for Attack / Interact / Cast
+	TargetObjectID int32           // for Attack / Interact / Cast / PickUp
 	MoveDest       models.Position // for MoveTo
 }
 
@@ -69,6 +70,8 @@ func (gl *GameLoop) onMovementArrived(charID int32) {
 	case IntentionInteract:
 		// No-op: the interact heartbeat (InteractApproachEvent) opens the dialogue on
 		// arrival, mirroring the attack no-op above.
+	case IntentionPickUp:
+		// No-op: the pickup heartbeat (PickUpApproachEvent) picks the item up.
 	default:
 		// MoveTo / Idle / scaffolded intentions: nothing to do on arrival
 	}
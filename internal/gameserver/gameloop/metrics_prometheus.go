@@ -39,6 +39,7 @@ type PromMetrics struct {
 	spAwarded     prometheus.Counter
 	levelups       prometheus.Counter
 	skillCasts     *prometheus.CounterVec
+	pathSearches   *prometheus.CounterVec
 	playersByLevel  *prometheus.GaugeVec
 	playersByClass  *prometheus.GaugeVec
 	buffedPlayers   prometheus.Gauge
@@ -85,7 +86,7 @@ func NewPromMetrics() *PromMetrics {
 		}),
 		phaseSecs: prometheus.NewHistogramVec(prometheus.HistogramOpts{
 			Name:    "l2go_gameloop_tick_phase_seconds",
-			Help:    "Time spent in each tick subsystem (core/regen/buffs/region_cleanup/autosave) — attributes the tick budget.",
+			Help:    "Time spent in each tick subsystem (core/regen/buffs/region_cleanup/autosave/pathfinding) — attributes the tick budget.",
 			Buckets: tickWorkBuckets,
 		}, []string{"phase"}),
 		knownPlayers: prometheus.NewHistogram(prometheus.HistogramOpts{
@@ -154,6 +155,10 @@ func NewPromMetrics() *PromMetrics {
 			Name: "l2go_skill_casts_total",
 			Help: "Skill cast attempts by outcome (success = started/applied, fail = rejected pre-start, interrupted = aborted mid-cast).",
 		}, []string{"outcome"}),
+		pathSearches: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "l2go_pathfinding_searches_total",
+			Help: "Path searches by result (found, none = no way within the node cap, deferred = tick node budget spent, cached).",
+		}, []string{"result"}),
 		playersByLevel: prometheus.NewGaugeVec(prometheus.GaugeOpts{
 			Name: "l2go_players_by_level",
 			Help: "Online players bucketed by level range.",
@@ -180,7 +185,7 @@ func NewPromMetrics() *PromMetrics {
 			Buckets: sessionDurationBuckets,
 		}),
 	}
-	reg.MustRegister(pm.workSecs, pm.gapSecs, pm.phaseSecs, pm.knownPlayers, pm.knownPlayersMax, pm.behind, pm.players, pm.cmdBacklog, pm.activeRegions, pm.npcTotal, pm.worldEntries, pm.entryDuration, pm.combatAttacks, pm.npcKills, pm.playerDeaths, pm.expAwarded, pm.spAwarded, pm.levelups, pm.skillCasts, pm.playersByLevel, pm.playersByClass, pm.buffedPlayers, pm.sessionsStarted, pm.sessionsEnded, pm.sessionDuration)
+	reg.MustRegister(pm.workSecs, pm.gapSecs, pm.phaseSecs, pm.knownPlayers, pm.knownPlayersMax, pm.behind, pm.players, pm.cmdBacklog, pm.activeRegions, pm.npcTotal, pm.worldEntries, pm.entryDuration, pm.combatAttacks, pm.npcKills, pm.playerDeaths, pm.expAwarded, pm.spAwarded, pm.levelups, pm.skillCasts, pm.pathSearches, pm.playersByLevel, pm.playersByClass, pm.buffedPlayers, pm.sessionsStarted, pm.sessionsEnded, pm.sessionDuration)
 	// Go runtime metrics (goroutines/GC/heap) — cheap and valuable under load, where
 	// goroutine-per-connection growth and GC pauses matter as much as tick time.
 	reg.MustRegister(collectors.NewGoCollector())
@@ -347,6 +352,15 @@ func (pm *PromMetrics) recordSkillCast(outcome string) {
 	pm.skillCasts.WithLabelValues(outcome).Inc()
 }
 
+// recordPathSearch counts one findPath call by result. Called on the loop
+// goroutine; nil-safe.
+func (pm *PromMetrics) recordPathSearch(result string) {
+	if pm == nil {
+		return
+	}
+	pm.pathSearches.WithLabelValues(result).Inc()
+}
+
 // recordProgression records EXP/SP awarded from one kill and whether it triggered
 // a level-up. Called on the loop goroutine; nil-safe. Negative amounts are ignored
 // (Prometheus counters must be monotonic).
@@ -0,0 +1,274 @@
+package gameloop
+
+import (
+	"math"
+	"math/rand"
+	"slices"
+	"time"
+
+	"github.com/rs/zerolog/log"
+
+	"github.com/VerTox/l2go/internal/gameserver/models"
+	"github.com/VerTox/l2go/internal/gameserver/packets/outclient"
+)
+
+const (
+	// Minions spawn at a random point this far from their leader, beyond its
+	// body (L2J MinionList.spawnMinion offsets).
+	minionSpawnMinOffset = 30
+	minionSpawnMaxOffset = 70
+	// An idle minion further than this from its leader goes back to its side;
+	// a raid boss's escort keeps a looser order (L2J AttackableAI
+	// thinkActive).
+	minionFollowRange     = 200
+	raidMinionFollowRange = 500
+)
+
+// spawnMinions tops a leader's escort up to its template's <minions>: each
+// minion it lacks spawns around it.
+func (gl *GameLoop) spawnMinions(leader *models.NpcInstance) {
+	if leader.Template == nil {
+		return
+	}
+	for _, m := range leader.Template.Minions {
+		gl.topUpMinions(leader, m)
+	}
+}
+
+// topUpMinions spawns the minions of one <minions> entry a leader lacks.
+func (gl *GameLoop) topUpMinions(leader *models.NpcInstance, m models.NpcMinion) {
+	tpl := gl.getNpcTemplate(m.NpcID)
+	if tpl == nil {
+		log.Warn().Int32("leader", leader.TemplateID).Int32("minion", m.NpcID).Msg("minion template not found")
+		return
+	}
+	alive := 0
+	for _, id := range gl.minions[leader.ObjectID] {
+		if npc, ok := gl.world.GetNPC(id); ok && npc.TemplateID == m.NpcID && !npc.IsDead {
+			alive++
+		}
+	}
+	for range m.Count - alive {
+		gl.spawnMinion(leader, tpl)
+	}
+}
+
+// spawnMinion puts one minion into the world beside its leader. The point it
+// spawns on is its home until it next goes back to its leader; it never
+// respawns on its own.
+func (gl *GameLoop) spawnMinion(leader *models.NpcInstance, tpl *models.NpcTemplate) *models.NpcInstance {
+	pos := gl.minionPoint(leader)
+	npc := &models.NpcInstance{
+		ObjectID:   gl.nextObjectID(),
+		TemplateID: tpl.ID,
+		Template:   tpl,
+		Position:   pos,
+		Heading:    leader.Heading,
+		IsRunning:  true,
+		CurrentHP:  tpl.HP,
+		CurrentMP:  tpl.MP,
+	}
+	gl.world.AddNPC(npc)
+	gl.npcSpawnInfo[npc.ObjectID] = SpawnInfo{TemplateID: tpl.ID, Position: pos, Heading: npc.Heading}
+	gl.minions[leader.ObjectID] = append(gl.minions[leader.ObjectID], npc.ObjectID)
+	gl.minionLeaders[npc.ObjectID] = leader.ObjectID
+	return npc
+}
+
+// minionPoint picks a random point of the ground around a leader for one of
+// its minions to stand on.
+func (gl *GameLoop) minionPoint(leader *models.NpcInstance) models.Position {
+	offset := float64(minionSpawnMinOffset + rand.Intn(minionSpawnMaxOffset-minionSpawnMinOffset+1))
+	angle := rand.Float64() * 2 * math.Pi
+	pos := leader.Position
+	pos.X += int(offset * math.Cos(angle))
+	pos.Y += int(offset * math.Sin(angle))
+	return gl.geo.MoveCheck(leader.Position, pos)
+}
+
+// leaderOf returns a minion's leader while it is in the world; false for an
+// NPC that is nobody's minion or whose leader is gone.
+func (gl *GameLoop) leaderOf(objectID int32) (*models.NpcInstance, bool) {
+	leaderID, ok := gl.minionLeaders[objectID]
+	if !ok {
+		return nil, false
+	}
+	return gl.world.GetNPC(leaderID)
+}
+
+// followLeader is the idle AI step of a minion (L2J AttackableAI thinkActive):
+// it joins the fight its leader is in, and keeps to its leader's side instead
+// of wandering, running when the leader runs. Reports false for an NPC that
+// is nobody's minion.
+func (gl *GameLoop) followLeader(npc *models.NpcInstance) bool {
+	if !gl.isMinion(npc.ObjectID) {
+		return false
+	}
+	leader, ok := gl.leaderOf(npc.ObjectID)
+	if !ok || leader.IsDead {
+		return true
+	}
+	if ncs, ok := gl.npcCombatState[leader.ObjectID]; ok && ncs.IsAttacking && npc.IsAttackable() {
+		gl.joinFight(npc, ncs.TargetCharID)
+		return true
+	}
+	if gl.isNPCMoving(npc.ObjectID) || npc.Template == nil || !npc.Template.CanMove {
+		return true
+	}
+	followRange := minionFollowRange
+	if leader.Template != nil && leader.Template.IsRaid() {
+		followRange = raidMinionFollowRange
+	}
+	if distanceBetween(npc.Position, leader.Position) <= float64(followRange) {
+		return true
+	}
+	gl.setMinionHome(npc, leader)
+	if leader.IsRunning && gl.isNPCMoving(leader.ObjectID) {
+		gl.moveNPCToPawn(npc, leader.ObjectID, leader.Position, minionSpawnMaxOffset)
+		return true
+	}
+	gl.walkNPCTo(npc, gl.npcSpawnInfo[npc.ObjectID].Position)
+	return true
+}
+
+// setMinionHome moves a minion's home to a point beside its leader, so it
+// chases and returns home around where the leader is now rather than where
+// it spawned.
+func (gl *GameLoop) setMinionHome(npc, leader *models.NpcInstance) {
+	info, ok := gl.npcSpawnInfo[npc.ObjectID]
+	if !ok {
+		return
+	}
+	info.Position = gl.minionPoint(leader)
+	info.Heading = leader.Heading
+	gl.npcSpawnInfo[npc.ObjectID] = info
+}
+
+// callMinionHelp pulls an attacked NPC's group into the fight (L2J
+// MinionList.onAssist): a leader's minions, or a minion's leader and the
+// other minions, that are not fighting or walking home yet take a dislike to
+// the attacker and attack it.
+func (gl *GameLoop) callMinionHelp(npc *models.NpcInstance, attackerCharID int32) {
+	leaderID := npc.ObjectID
+	if id, ok := gl.minionLeaders[npc.ObjectID]; ok {
+		leaderID = id
+	}
+	group := gl.minions[leaderID]
+	if len(group) == 0 {
+		return
+	}
+	attacker, ok := gl.world.GetPlayer(attackerCharID)
+	if !ok || !noticeable(attacker) {
+		return
+	}
+	for _, id := range append([]int32{leaderID}, group...) {
+		if id == npc.ObjectID {
+			continue
+		}
+		ally, ok := gl.world.GetNPC(id)
+		if !ok || ally.IsDead || !ally.IsAttackable() {
+			continue
+		}
+		if ncs, ok := gl.npcCombatState[id]; (ok && ncs.IsAttacking) || gl.isReturningHome(id) {
+			continue
+		}
+		gl.joinFight(ally, attackerCharID)
+	}
+}
+
+// joinFight has an NPC that was not fighting take a dislike to charID and
+// attack it.
+func (gl *GameLoop) joinFight(npc *models.NpcInstance, charID int32) {
+	hl, ok := gl.npcHateLists[npc.ObjectID]
+	if !ok {
+		hl = NewHateList()
+		gl.npcHateLists[npc.ObjectID] = hl
+	}
+	if hl.Hate(charID) <= 0 {
+		hl.AddAggro(charID, npcAggroHate)
+	}
+	gl.startNPCAttack(npc.ObjectID, charID)
+}
+
+// isMinion reports whether the NPC is some leader's minion.
+func (gl *GameLoop) isMinion(objectID int32) bool {
+	_, ok := gl.minionLeaders[objectID]
+	return ok
+}
+
+// forgetMinion drops a dead or despawned minion from its leader's escort.
+func (gl *GameLoop) forgetMinion(objectID int32) {
+	leaderID, ok := gl.minionLeaders[objectID]
+	if !ok {
+		return
+	}
+	delete(gl.minionLeaders, objectID)
+	gl.minions[leaderID] = slices.DeleteFunc(gl.minions[leaderID], func(id int32) bool { return id == objectID })
+	if len(gl.minions[leaderID]) == 0 {
+		delete(gl.minions, leaderID)
+	}
+}
+
+// minionDied drops a dead minion from its leader's escort. Minions respawn on
+// their leader's schedule, not their own spawn's: one with a respawn time of
+// its own comes back beside its leader after it if the leader still lives
+// (L2J MinionList.onMinionDie), the others when the leader respawns.
+func (gl *GameLoop) minionDied(npc *models.NpcInstance) {
+	leader, ok := gl.leaderOf(npc.ObjectID)
+	gl.forgetMinion(npc.ObjectID)
+	delete(gl.npcSpawnInfo, npc.ObjectID)
+	if !ok || leader.IsDead || leader.Template == nil {
+		return
+	}
+	for _, m := range leader.Template.Minions {
+		if m.NpcID == npc.TemplateID && m.RespawnTime > 0 {
+			gl.events.Schedule(&MinionRespawnEvent{
+				At:       time.Now().Add(time.Duration(m.RespawnTime) * time.Second),
+				LeaderID: leader.ObjectID,
+				Minion:   m,
+			})
+			return
+		}
+	}
+}
+
+// MinionRespawnEvent brings back the minions of one <minions> entry of a
+// leader after one of them died, if the leader is still alive.
+type MinionRespawnEvent struct {
+	At       time.Time
+	LeaderID int32
+	Minion   models.NpcMinion
+}
+
+func (e *MinionRespawnEvent) ExecuteAt() time.Time { return e.At }
+
+func (e *MinionRespawnEvent) Execute(gl *GameLoop) {
+	if leader, ok := gl.world.GetNPC(e.LeaderID); ok && !leader.IsDead {
+		gl.topUpMinions(leader, e.Minion)
+	}
+}
+
+// despawnMinions removes a leader's living minions from the world (L2J
+// MinionList.onMasterDie); the dead ones are left to decay.
+func (gl *GameLoop) despawnMinions(leaderID int32) {
+	for _, id := range slices.Clone(gl.minions[leaderID]) {
+		if npc, ok := gl.world.GetNPC(id); ok && !npc.IsDead {
+			gl.despawnNPC(npc)
+		}
+	}
+}
+
+// despawnNPC takes a living NPC out of the world for good: it stops whatever
+// it was doing, disappears for the players around and does not respawn.
+func (gl *GameLoop) despawnNPC(npc *models.NpcInstance) {
+	gl.stopAllAttackersOnTarget(npc.ObjectID)
+	gl.stopNPCAttack(npc.ObjectID)
+	delete(gl.npcMoves, npc.ObjectID)
+	delete(gl.npcCasts, npc.ObjectID)
+	delete(gl.npcHateLists, npc.ObjectID)
+	delete(gl.npcSkillReuse, npc.ObjectID)
+	delete(gl.npcSpawnInfo, npc.ObjectID)
+	gl.forgetMinion(npc.ObjectID)
+	gl.broadcastToNearby(npc.Position, outclient.BuildDeleteObject(npc.ObjectID))
+	gl.world.RemoveNPC(npc.ObjectID)
+}
@@ -0,0 +1,211 @@
+package gameloop
+
+import (
+	"testing"
+	"time"
+
+	"github.com/VerTox/l2go/internal/gameserver/models"
+)
+
+// Template ids for the minion tests, out of the datapack's range.
+const (
+	testLeaderID       = 990011
+	testGuardMinionID  = 990012
+	testHealerMinionID = 990013
+)
+
+// addLeader gives gl a camp leader escorted by two guards and a healer that
+// comes back healerRespawn seconds after it dies, and puts one into the world
+// as a spawn-list NPC at pos.
+func addLeader(gl *GameLoop, objectID int32, pos models.Position, healerRespawn int) *models.NpcInstance {
+	tpl := &models.NpcTemplate{
+		ID: testLeaderID, Name: "TestLeader", Type: "L2Monster", HP: 100, CanMove: true, WalkSpd: 50, RunSpd: 150,
+		Minions: []models.NpcMinion{
+			{NpcID: testGuardMinionID, Count: 2},
+			{NpcID: testHealerMinionID, Count: 1, RespawnTime: healerRespawn},
+		},
+	}
+	useNpcTemplates(gl, tpl,
+		&models.NpcTemplate{ID: testGuardMinionID, Name: "TestGuard", Type: "L2Monster", HP: 50, CanMove: true, WalkSpd: 50, RunSpd: 150},
+		&models.NpcTemplate{ID: testHealerMinionID, Name: "TestHealer", Type: "L2Monster", HP: 50, CanMove: true, WalkSpd: 50, RunSpd: 150})
+	leader := &models.NpcInstance{ObjectID: objectID, TemplateID: tpl.ID, Template: tpl, Position: pos, CurrentHP: tpl.HP}
+	gl.world.AddNPC(leader)
+	gl.RegisterWorldSpawns()
+	return leader
+}
+
+// minionsOf returns a leader's minions in the world.
+func minionsOf(t *testing.T, gl *GameLoop, leaderID int32) []*models.NpcInstance {
+	t.Helper()
+	var out []*models.NpcInstance
+	for _, id := range gl.minions[leaderID] {
+		npc, ok := gl.world.GetNPC(id)
+		if !ok {
+			t.Fatalf("minion %d of %d is not in the world", id, leaderID)
+		}
+		out = append(out, npc)
+	}
+	return out
+}
+
+func TestRegisterWorldSpawns_LeaderSpawnsWithMinions(t *testing.T) {
+	gl, _ := newTestLoopWithPlayer(t)
+	leader := addLeader(gl, 1000, models.Position{X: 5000, Y: 5000}, 0)
+
+	minions := minionsOf(t, gl, leader.ObjectID)
+	if len(minions) != 3 {
+		t.Fatalf("leader spawned %d minions, want 3", len(minions))
+	}
+	for _, m := range minions {
+		if d := distanceBetween(m.Position, leader.Position); d < minionSpawnMinOffset-1 || d > minionSpawnMaxOffset+1 {
+			t.Errorf("minion %d spawned %.0f from its leader", m.ObjectID, d)
+		}
+		if _, ok := gl.npcSpawnInfo[m.ObjectID]; !ok {
+			t.Errorf("minion %d has no spawn info", m.ObjectID)
+		}
+	}
+
+	// Registering again (a respawn, a second call) does not double the escort.
+	gl.spawnMinions(leader)
+	if got := len(gl.minions[leader.ObjectID]); got != 3 {
+		t.Errorf("topping up a full escort left %d minions, want 3", got)
+	}
+}
+
+func TestFollowLeader_IdleMinionWalksBackToLeader(t *testing.T) {
+	gl, _ := newTestLoopWithPlayer(t)
+	leader := addLeader(gl, 1000, models.Position{X: 5000, Y: 5000}, 0)
+	minion := minionsOf(t, gl, leader.ObjectID)[0]
+	gl.world.UpdateNPCPosition(leader.ObjectID, models.Position{X: 6000, Y: 5000}, 0)
+
+	gl.thinkIdle(minion)
+
+	mv, ok := gl.npcMoves[minion.ObjectID]
+	if !ok {
+		t.Fatal("minion left behind did not move")
+	}
+	if d := distanceBetween(mv.goal(), leader.Position); d > minionSpawnMaxOffset+1 {
+		t.Errorf("minion walks to %+v, %.0f from its leader", mv.goal(), d)
+	}
+	if home := gl.npcSpawnInfo[minion.ObjectID].Position; distanceBetween(home, leader.Position) > minionSpawnMaxOffset+1 {
+		t.Errorf("minion home %+v did not move with its leader", home)
+	}
+
+	// One already beside its leader stays put instead of wandering.
+	near := minionsOf(t, gl, leader.ObjectID)[1]
+	gl.world.UpdateNPCPosition(near.ObjectID, models.Position{X: 6050, Y: 5000}, 0)
+	for range 100 {
+		gl.thinkIdle(near)
+	}
+	if gl.isNPCMoving(near.ObjectID) {
+		t.Error("minion beside its leader wandered off")
+	}
+}
+
+func TestCallMinionHelp_GroupJoinsFight(t *testing.T) {
+	gl, _ := newTestLoopWithPlayer(t)
+	leader := addLeader(gl, 1000, models.Position{}, 0)
+
+	gl.dealDamageToNPC(leader, 7, 10)
+
+	for _, m := range minionsOf(t, gl, leader.ObjectID) {
+		if ncs, ok := gl.npcCombatState[m.ObjectID]; !ok || !ncs.IsAttacking || ncs.TargetCharID != 7 {
+			t.Errorf("minion %d did not join its leader's fight: %+v", m.ObjectID, ncs)
+		}
+	}
+}
+
+func TestCallMinionHelp_LeaderAndSiblingsAnswerMinion(t *testing.T) {
+	gl, _ := newTestLoopWithPlayer(t)
+	leader := addLeader(gl, 1000, models.Position{}, 0)
+	minions := minionsOf(t, gl, leader.ObjectID)
+
+	gl.dealDamageToNPC(minions[0], 7, 10)
+
+	for _, npc := range append([]*models.NpcInstance{leader}, minions[1:]...) {
+		if ncs, ok := gl.npcCombatState[npc.ObjectID]; !ok || !ncs.IsAttacking || ncs.TargetCharID != 7 {
+			t.Errorf("NPC %d did not answer the attacked minion: %+v", npc.ObjectID, ncs)
+		}
+	}
+}
+
+func TestFollowLeader_IdleMinionJoinsLeadersFight(t *testing.T) {
+	gl, _ := newTestLoopWithPlayer(t)
+	leader := addLeader(gl, 1000, models.Position{}, 0)
+	minion := minionsOf(t, gl, leader.ObjectID)[0]
+	gl.startNPCAttack(leader.ObjectID, 7)
+
+	gl.thinkNPC(minion)
+
+	if ncs, ok := gl.npcCombatState[minion.ObjectID]; !ok || ncs.TargetCharID != 7 {
+		t.Errorf("idle minion did not join its leader's fight: %+v", ncs)
+	}
+}
+
+func TestMinionDeath_RespawnsOnLeadersSchedule(t *testing.T) {
+	gl, _ := newTestLoopWithPlayer(t)
+	leader := addLeader(gl, 1000, models.Position{X: 5000, Y: 5000}, 60)
+	var guard, healer *models.NpcInstance
+	for _, m := range minionsOf(t, gl, leader.ObjectID) {
+		switch m.TemplateID {
+		case testGuardMinionID:
+			guard = m
+		case testHealerMinionID:
+			healer = m
+		}
+	}
+	events := gl.events.Len()
+
+	// A guard has no respawn time of its own: only its corpse decay is due.
+	gl.handleNPCDeath(guard, 7)
+	if gl.events.Len() != events+1 || gl.isMinion(guard.ObjectID) {
+		t.Fatalf("dead guard: %d new events, still a minion %v", gl.events.Len()-events, gl.isMinion(guard.ObjectID))
+	}
+	if _, ok := gl.npcSpawnInfo[guard.ObjectID]; ok {
+		t.Error("dead guard kept its spawn info")
+	}
+
+	// The healer comes back beside the living leader after its respawn time.
+	gl.handleNPCDeath(healer, 7)
+	var respawn *MinionRespawnEvent
+	for _, e := range gl.events.items {
+		if r, ok := e.(*MinionRespawnEvent); ok {
+			respawn = r
+		}
+	}
+	if respawn == nil || respawn.LeaderID != leader.ObjectID || time.Until(respawn.At) < 59*time.Second {
+		t.Fatalf("healer respawn event = %+v", respawn)
+	}
+	respawn.Execute(gl)
+	if got := len(gl.minions[leader.ObjectID]); got != 2 {
+		t.Errorf("escort after the healer respawn = %d, want guard + healer", got)
+	}
+
+	// The leader respawning brings the whole escort back.
+	gl.despawnMinions(leader.ObjectID)
+	gl.world.RemoveNPC(leader.ObjectID)
+	(&RespawnEvent{At: time.Now(), ObjectID: leader.ObjectID}).Execute(gl)
+	if len(gl.minionLeaders) != 3 {
+		t.Errorf("respawned leader brought %d minions, want 3", len(gl.minionLeaders))
+	}
+}
+
+func TestLeaderDeath_DespawnsMinions(t *testing.T) {
+	gl, _ := newTestLoopWithPlayer(t)
+	leader := addLeader(gl, 1000, models.Position{X: 5000, Y: 5000}, 60)
+	minions := minionsOf(t, gl, leader.ObjectID)
+
+	gl.handleNPCDeath(leader, 7)
+
+	for _, m := range minions {
+		if _, ok := gl.world.GetNPC(m.ObjectID); ok {
+			t.Errorf("minion %d outlived its leader", m.ObjectID)
+		}
+		if _, ok := gl.npcSpawnInfo[m.ObjectID]; ok {
+			t.Errorf("despawned minion %d kept its spawn info", m.ObjectID)
+		}
+	}
+	if len(gl.minions) != 0 || len(gl.minionLeaders) != 0 {
+		t.Errorf("leftover escort: %v / %v", gl.minions, gl.minionLeaders)
+	}
+}
@@ -0,0 +1,161 @@
+package gameloop
+
+import (
+	"github.com/rs/zerolog/log"
+
+	"github.com/VerTox/l2go/internal/gameserver/packets/outclient"
+	"github.com/VerTox/l2go/internal/gameserver/registry"
+	"github.com/VerTox/l2go/internal/gameserver/usecase"
+)
+
+// multisellMaxAmount caps how many exchanges one MultiSellChoose may ask for
+// (L2J MultiSellChoose).
+const multisellMaxAmount = 5000
+
+// multisellTaxRate is the castle tax applied to lists with applyTaxes. No
+// castle collects taxes yet.
+const multisellTaxRate = 0.0
+
+func init() {
+	// Multisell links may sit in any NPC's dialogue; the list names the NPCs it
+	// opens at.
+	RegisterBypass(BypassMultisell, func(gl *GameLoop, player *registry.PlayerWorldState, npcObjID int32, arg string) {
+		if listID, ok := bypassListID(arg); ok {
+			gl.handleMultisellOpen(CmdMultisellOpen{CharID: player.CharID, NpcObjID: npcObjID, ListID: listID})
+		}
+	})
+}
+
+// MultisellJob is a multisell exchange the loop validated: the entry's cost
+// and products per exchange, and how many exchanges to run. It needs the
+// database and therefore runs off the loop on the multisell worker.
+type MultisellJob struct {
+	CharID         int32
+	ListID         int32
+	EntryID        int32
+	Amount         int64
+	Cost           []registry.MultisellIngredient
+	Products       []registry.MultisellIngredient
+	KeepEnchant    bool
+	InventorySlots int
+}
+
+// SetMultisellSink wires the channel that receives multisell exchanges. Kept
+// out of New() like the other optional sinks; without it lists still open but
+// nothing is exchanged.
+func (gl *GameLoop) SetMultisellSink(sink chan<- MultisellJob) {
+	gl.multisellSink = sink
+}
+
+// enqueueMultisellJob hands an exchange to the multisell worker. Non-blocking:
+// with no sink or a full queue the job is dropped.
+func (gl *GameLoop) enqueueMultisellJob(job MultisellJob) {
+	if gl.multisellSink == nil {
+		return
+	}
+	select {
+	case gl.multisellSink <- job:
+	default:
+		log.Warn().Int32("char_id", job.CharID).Msg("multisell sink full, dropping multisell job")
+	}
+}
+
+// multisellUser returns the player and list if it may use list listID at
+// npcObjID right now: alive, not trading or running a store, and within
+// interact range of a living NPC the list allows.
+func (gl *GameLoop) multisellUser(charID, npcObjID, listID int32) (*registry.PlayerWorldState, *registry.MultisellList, bool) {
+	player, ok := gl.world.GetPlayer(charID)
+	if !ok || player.Character == nil || player.Character.CurrentHP <= 0 {
+		return nil, nil, false
+	}
+	if gl.trades[charID] != nil || player.PrivateStore.Type != registry.StoreNone {
+		gl.sendToPlayer(player, outclient.BuildActionFailed())
+		return nil, nil, false
+	}
+	list, ok := registry.GetMultisellRegistry().Get(listID)
+	if !ok {
+		return nil, nil, false
+	}
+	npc, ok := gl.world.GetNPC(npcObjID)
+	if !ok || npc.IsDead || npc.IsAttackable() || !list.AllowsNpc(npc.TemplateID) {
+		return nil, nil, false
+	}
+	dx := player.Position.X - npc.Position.X
+	dy := player.Position.Y - npc.Position.Y
+	if dx*dx+dy*dy > interactRange*interactRange {
+		return nil, nil, false
+	}
+	return player, list, true
+}
+
+// handleMultisellOpen remembers the list the player opened and sends it, page
+// by page (L2J MultisellData.separateAndSend).
+func (gl *GameLoop) handleMultisellOpen(cmd CmdMultisellOpen) {
+	player, list, ok := gl.multisellUser(cmd.CharID, cmd.NpcObjID, cmd.ListID)
+	if !ok {
+		return
+	}
+	player.Multisell = registry.ActiveMultisell{ListID: cmd.ListID, NpcObjID: cmd.NpcObjID}
+
+	page := int32(1)
+	for start := 0; ; start += outclient.MultiSellPageSize {
+		end := min(start+outclient.MultiSellPageSize, len(list.Entries))
+		entries := make([]outclient.MultiSellEntry, 0, end-start)
+		for i := start; i < end; i++ {
+			e := &list.Entries[i]
+			entries = append(entries, outclient.MultiSellEntry{
+				EntryID:     e.ID,
+				Stackable:   e.Stackable,
+				Products:    multisellItems(e.Products),
+				Ingredients: multisellItems(list.Cost(e, multisellTaxRate)),
+			})
+		}
+		gl.sendToPlayer(player, outclient.BuildMultiSellList(list.ID, page, end == len(list.Entries), entries))
+		if end == len(list.Entries) {
+			return
+		}
+		page++
+	}
+}
+
+// handleMultisellChoose checks a picked exchange against the open list and
+// hands it to the worker. Several exchanges at once only go for entries whose
+// products all stack.
+func (gl *GameLoop) handleMultisellChoose(cmd CmdMultisellChoose) {
+	player, ok := gl.world.GetPlayer(cmd.CharID)
+	if !ok || player.Multisell.ListID == 0 || player.Multisell.ListID != cmd.ListID {
+		return
+	}
+	_, list, ok := gl.multisellUser(cmd.CharID, player.Multisell.NpcObjID, cmd.ListID)
+	if !ok {
+		return
+	}
+	entry, ok := list.Entry(cmd.EntryID)
+	if !ok || cmd.Amount < 1 || cmd.Amount > multisellMaxAmount || (!entry.Stackable && cmd.Amount > 1) {
+		gl.sendToPlayer(player, outclient.BuildSystemMessageNoParams(outclient.SysMsgExceededQuantityForInput))
+		return
+	}
+	gl.enqueueMultisellJob(MultisellJob{
+		CharID:         cmd.CharID,
+		ListID:         cmd.ListID,
+		EntryID:        cmd.EntryID,
+		Amount:         cmd.Amount,
+		Cost:           list.Cost(entry, multisellTaxRate),
+		Products:       entry.Products,
+		KeepEnchant:    list.MaintainEnchantment,
+		InventorySlots: usecase.InventoryLimit(player.Character),
+	})
+}
+
+// multisellItems describes entry lines for MultiSellList.
+func multisellItems(lines []registry.MultisellIngredient) []outclient.MultiSellItem {
+	out := make([]outclient.MultiSellItem, len(lines))
+	for i, l := range lines {
+		out[i] = outclient.MultiSellItem{ItemID: l.ItemID, Type2: -1, Count: l.Count}
+		if tmpl := registry.GetItemTemplateRegistry().Get(l.ItemID); tmpl != nil {
+			out[i].BodyPart = tmpl.BodyPartCode
+			out[i].Type2 = int16(tmpl.Type2)
+		}
+	}
+	return out
+}
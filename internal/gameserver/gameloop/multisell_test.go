@@ -0,0 +1,95 @@
+package gameloop
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/VerTox/l2go/internal/gameserver/models"
+	"github.com/VerTox/l2go/internal/gameserver/registry"
+)
+
+// loadTestMultisell loads list 100 at NPC 30301 into the global registry for
+// the duration of the test: a stackable entry and an equipment one that keeps
+// the enchant.
+func loadTestMultisell(t *testing.T) {
+	t.Helper()
+	dir := t.TempDir()
+	xml := `<list maintainEnchantment="true"><npcs><npc>30301</npc></npcs>
+		<item><ingredient id="1060" count="10" /><ingredient id="57" count="100" /><production id="1061" count="1" /></item>
+		<item><ingredient id="1" count="1" /><production id="2" count="1" /></item>
+	</list>`
+	if err := os.WriteFile(filepath.Join(dir, "100.xml"), []byte(xml), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	ms := registry.GetMultisellRegistry()
+	if err := ms.LoadFromDirectory(dir); err != nil {
+		t.Fatal(err)
+	}
+	// Without item templates nothing stacks; mark the first entry by hand.
+	list, _ := ms.Get(100)
+	list.Entries[0].Stackable = true
+	t.Cleanup(func() { _ = ms.LoadFromDirectory(t.TempDir()) })
+}
+
+func TestMultisell_OpenAtListedNpc(t *testing.T) {
+	loadTestMultisell(t)
+	gl, p := newTestLoopWithPlayer(t)
+	addMerchant(gl, 2000, 30301, models.Position{X: 100})
+	addMerchant(gl, 2001, 30315, models.Position{X: 100})
+	addAttackableNPC(gl, 2002, models.Position{X: 100})
+
+	npc, _ := gl.world.GetNPC(2000)
+	if html := NpcDialogHtml(p, npc); !strings.Contains(html, `bypass -h multisell 100`) {
+		t.Fatalf("merchant dialogue = %q", html)
+	}
+
+	gl.handleMultisellOpen(CmdMultisellOpen{CharID: 7, NpcObjID: 2001, ListID: 100})
+	gl.handleMultisellOpen(CmdMultisellOpen{CharID: 7, NpcObjID: 2002, ListID: 100})
+	gl.handleMultisellOpen(CmdMultisellOpen{CharID: 7, NpcObjID: 2000, ListID: 5})
+	if p.Multisell.ListID != 0 {
+		t.Fatal("only an NPC the list names opens it")
+	}
+
+	gl.handleMultisellOpen(CmdMultisellOpen{CharID: 7, NpcObjID: 2000, ListID: 100})
+	if p.Multisell != (registry.ActiveMultisell{ListID: 100, NpcObjID: 2000}) {
+		t.Fatalf("open multisell = %+v", p.Multisell)
+	}
+}
+
+func TestMultisell_ChooseValidatesAmount(t *testing.T) {
+	loadTestMultisell(t)
+	gl, p := newTestLoopWithPlayer(t)
+	sink := make(chan MultisellJob, 4)
+	gl.SetMultisellSink(sink)
+	addMerchant(gl, 2000, 30301, models.Position{X: 100})
+
+	// Nothing is open yet.
+	gl.handleMultisellChoose(CmdMultisellChoose{CharID: 7, ListID: 100, EntryID: 1, Amount: 1})
+	if len(sink) != 0 {
+		t.Fatal("a choice without an open list must be ignored")
+	}
+
+	gl.handleMultisellOpen(CmdMultisellOpen{CharID: 7, NpcObjID: 2000, ListID: 100})
+	gl.handleMultisellChoose(CmdMultisellChoose{CharID: 7, ListID: 100, EntryID: 1, Amount: 3})
+	job := <-sink
+	if job.Amount != 3 || len(job.Cost) != 2 || job.Products[0].ItemID != 1061 || !job.KeepEnchant {
+		t.Fatalf("job = %+v", job)
+	}
+
+	// Unknown entries, non-stackable batches, too many and walking away are refused.
+	for _, cmd := range []CmdMultisellChoose{
+		{CharID: 7, ListID: 100, EntryID: 3, Amount: 1},
+		{CharID: 7, ListID: 100, EntryID: 2, Amount: 2},
+		{CharID: 7, ListID: 100, EntryID: 1, Amount: multisellMaxAmount + 1},
+		{CharID: 7, ListID: 101, EntryID: 1, Amount: 1},
+	} {
+		gl.handleMultisellChoose(cmd)
+	}
+	p.Position = models.Position{X: 1000}
+	gl.handleMultisellChoose(CmdMultisellChoose{CharID: 7, ListID: 100, EntryID: 2, Amount: 1})
+	if len(sink) != 0 {
+		t.Fatalf("%d refused choices were queued", len(sink))
+	}
+}
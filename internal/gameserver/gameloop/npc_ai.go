@@ -0,0 +1,264 @@
+package gameloop
+
+import (
+	"slices"
+	"time"
+
+	"github.com/VerTox/l2go/internal/gameserver/models"
+	"github.com/VerTox/l2go/internal/gameserver/registry"
+)
+
+const (
+	// npcAIInterval is how often NPCs in active regions look around and
+	// weigh their hate (L2J AI task, 1 s).
+	npcAIInterval = time.Second
+	// aggroHeight is how far above or below an aggressive monster a player
+	// goes unnoticed.
+	aggroHeight = 300
+	// aggroLevelGap is how many levels a player may be above an aggressive
+	// monster and still draw its attention — the same gap that turns the
+	// monster's drops deep blue.
+	aggroLevelGap = deepBlueLevelGap
+	// npcAggroHate is the hate a monster bears a player it noticed but who
+	// has not hurt it yet.
+	npcAggroHate = 100
+	// hateDecayPercent is how much hate fades every npcAIInterval for a
+	// character beyond the NPC's reach and aggro range.
+	hateDecayPercent = 20
+	// npcChaseRange is how far from its spawn point an NPC follows a target
+	// before it loses interest.
+	npcChaseRange = 2000
+	// npcChaseRetry is how often a chasing NPC re-aims at its target.
+	npcChaseRetry = 400 * time.Millisecond
+	// clanHelpHeight is how far above or below an ally its attacker may be
+	// for the ally to answer a call for help (L2J faction call, 600).
+	clanHelpHeight = 600
+)
+
+// thinkNPCs runs the AI of the monsters in active regions: hate fades for
+// targets out of reach, a monster picks its most hated target it can still
+// pursue, and an idle aggressive monster attacks players it notices. Regions
+// nobody is near cost nothing.
+func (gl *GameLoop) thinkNPCs() {
+	for _, r := range gl.activeRegions {
+		for _, npc := range gl.npcsInCell(r.Cell) {
+			gl.thinkNPC(npc)
+		}
+	}
+}
+
+// npcsInCell returns the NPCs standing in an activation cell. cellOf truncates
+// toward zero, so a cell spans up to two regionSize around its origin.
+func (gl *GameLoop) npcsInCell(cell [2]int) []*models.NpcInstance {
+	origin := models.Position{X: cell[0] * regionSize, Y: cell[1] * regionSize}
+	var out []*models.NpcInstance
+	for _, npc := range gl.world.GetNPCsInRange(origin, regionSize*3/2) {
+		if cellOf(npc.Position.X, npc.Position.Y) == cell {
+			out = append(out, npc)
+		}
+	}
+	return out
+}
+
+// thinkNPC is one AI step of a monster.
+func (gl *GameLoop) thinkNPC(npc *models.NpcInstance) {
+	if npc.IsDead || !npc.IsAttackable() {
+		return
+	}
+	hl := gl.npcHateLists[npc.ObjectID]
+	if hl != nil {
+		gl.decayHate(npc, hl)
+	}
+
+	ncs, attacking := gl.npcCombatState[npc.ObjectID]
+	if (!attacking || !ncs.IsAttacking) && !gl.isReturningHome(npc.ObjectID) {
+		for _, charID := range gl.noticedPlayers(npc) {
+			if hl == nil {
+				hl = NewHateList()
+				gl.npcHateLists[npc.ObjectID] = hl
+			}
+			if hl.Hate(charID) <= 0 {
+				hl.AddAggro(charID, npcAggroHate)
+			}
+		}
+	}
+	if hl == nil {
+		gl.thinkIdle(npc)
+		return
+	}
+
+	target := gl.npcTarget(npc, hl)
+	switch {
+	case target == 0 && attacking:
+		gl.npcLeaveCombat(npc)
+	case target == 0:
+		gl.thinkIdle(npc)
+	case !attacking || ncs.TargetCharID != target:
+		gl.startNPCAttack(npc.ObjectID, target)
+		gl.callMinionHelp(npc, target)
+	}
+}
+
+// decayHate fades the NPC's hate for characters it can neither hit nor see
+// within its aggro range, so a monster forgets a target that got away.
+func (gl *GameLoop) decayHate(npc *models.NpcInstance, hl *HateList) {
+	engage := npcAttackReach(npc)
+	if npc.Template != nil {
+		engage = max(engage, npc.Template.AggroRange)
+	}
+	for _, charID := range hl.GetAllAttackers() {
+		if hl.Hate(charID) <= 0 {
+			continue
+		}
+		p, ok := gl.world.GetPlayer(charID)
+		if !ok || distanceBetween(npc.Position, p.Position) > float64(engage) {
+			hl.Decay(charID, hateDecayPercent)
+		}
+	}
+}
+
+// npcTarget returns the most hated character the NPC may still pursue,
+// forgetting the ones it may not; 0 if nobody is left.
+func (gl *GameLoop) npcTarget(npc *models.NpcInstance, hl *HateList) int32 {
+	for {
+		top := hl.GetTopAttacker()
+		if top == 0 || gl.canPursue(npc, top) {
+			return top
+		}
+		hl.StopHating(top)
+	}
+}
+
+// canPursue reports whether the NPC may keep after charID: alive, still in the
+// world, and within npcChaseRange of the NPC's spawn point.
+func (gl *GameLoop) canPursue(npc *models.NpcInstance, charID int32) bool {
+	p, ok := gl.world.GetPlayer(charID)
+	if !ok || p.Character == nil || p.Character.CurrentHP <= 0 || p.IsTeleporting {
+		return false
+	}
+	if spawn, ok := gl.npcSpawnInfo[npc.ObjectID]; ok {
+		return distanceBetween(spawn.Position, p.Position) <= npcChaseRange
+	}
+	return true
+}
+
+// noticedPlayers returns the players an aggressive monster notices (L2J
+// L2AttackableAI.autoAttackCondition): alive, within its aggro range and
+// aggroHeight, no more than aggroLevelGap levels above it, not silent moving
+// unless it is a raid boss, and in its line of sight.
+func (gl *GameLoop) noticedPlayers(npc *models.NpcInstance) []int32 {
+	if npc.Template == nil || !npc.Template.Aggressive || npc.Template.AggroRange <= 0 {
+		return nil
+	}
+	raid := npc.Template.IsRaid()
+	var out []int32
+	for _, p := range gl.world.GetPlayersInRange(npc.Position, npc.Template.AggroRange) {
+		if !noticeable(p) {
+			continue
+		}
+		if dz := p.Position.Z - npc.Position.Z; dz > aggroHeight || dz < -aggroHeight {
+			continue
+		}
+		if p.Character.Level-npc.Template.Level > aggroLevelGap {
+			continue
+		}
+		if !raid && p.Effects.IsSilentMoving() {
+			continue
+		}
+		if !gl.geo.CanSeeTarget(npc.Position, p.Position) {
+			continue
+		}
+		out = append(out, p.CharID)
+	}
+	return out
+}
+
+// noticeable reports whether a player can draw a monster's attention at all:
+// alive, in the world and not an offline store keeper.
+func noticeable(p *registry.PlayerWorldState) bool {
+	return p.Character != nil && p.Character.CurrentHP > 0 && !p.IsTeleporting && !p.OfflineTrade
+}
+
+// callClanHelp pulls an attacked NPC's clan into the fight (L2J
+// AttackableAI faction call): allies of a shared clan within its help range
+// that are not fighting or walking home yet take a dislike to the attacker
+// and attack it.
+func (gl *GameLoop) callClanHelp(npc *models.NpcInstance, attackerCharID int32) {
+	t := npc.Template
+	if t == nil || len(t.Clans) == 0 || t.ClanHelpRange <= 0 {
+		return
+	}
+	attacker, ok := gl.world.GetPlayer(attackerCharID)
+	if !ok || !noticeable(attacker) {
+		return
+	}
+	for _, ally := range gl.world.GetNPCsInRange(npc.Position, t.ClanHelpRange) {
+		if ally.ObjectID == npc.ObjectID || ally.IsDead || !ally.IsAttackable() {
+			continue
+		}
+		if slices.Contains(t.IgnoreClanNpcIDs, ally.TemplateID) || !ally.Template.IsClan(t.Clans) {
+			continue
+		}
+		if dz := attacker.Position.Z - ally.Position.Z; dz > clanHelpHeight || dz < -clanHelpHeight {
+			continue
+		}
+		if ncs, ok := gl.npcCombatState[ally.ObjectID]; (ok && ncs.IsAttacking) || gl.isReturningHome(ally.ObjectID) {
+			continue
+		}
+		hl, ok := gl.npcHateLists[ally.ObjectID]
+		if !ok {
+			hl = NewHateList()
+			gl.npcHateLists[ally.ObjectID] = hl
+		}
+		hl.AddAggro(attackerCharID, npcAggroHate)
+		gl.startNPCAttack(ally.ObjectID, attackerCharID)
+	}
+}
+
+// npcLoseTarget makes the NPC forget charID and turn on its next most hated
+// target, or leave combat if there is none.
+func (gl *GameLoop) npcLoseTarget(npc *models.NpcInstance, charID int32) {
+	hl, ok := gl.npcHateLists[npc.ObjectID]
+	if !ok {
+		gl.npcLeaveCombat(npc)
+		return
+	}
+	hl.StopHating(charID)
+	if next := gl.npcTarget(npc, hl); next != 0 {
+		gl.startNPCAttack(npc.ObjectID, next)
+		return
+	}
+	gl.npcLeaveCombat(npc)
+}
+
+// npcLeaveCombat ends the NPC's fight: it hates nobody and returns home. The
+// damage taken counts toward the kill's rewards until it gets there.
+func (gl *GameLoop) npcLeaveCombat(npc *models.NpcInstance) {
+	gl.stopNPCAttack(npc.ObjectID)
+	if hl, ok := gl.npcHateLists[npc.ObjectID]; ok {
+		for _, charID := range hl.GetAllAttackers() {
+			hl.StopHating(charID)
+		}
+	}
+	gl.returnHome(npc)
+}
+
+// npcAttackReach is how close an NPC must be to hit: its attack range, 40
+// without one, plus 50 for the bodies' collision.
+func npcAttackReach(npc *models.NpcInstance) int {
+	attackRange := 40
+	if npc.Template != nil && npc.Template.AttackRange > 0 {
+		attackRange = npc.Template.AttackRange
+	}
+	return attackRange + 50
+}
+
+// chaseTarget runs the NPC after a target out of its reach. Reports false if
+// it cannot: it does not move, the target left its chase range, or there is
+// no way to it.
+func (gl *GameLoop) chaseTarget(npc *models.NpcInstance, target *registry.PlayerWorldState) bool {
+	if npc.Template == nil || !npc.Template.CanMove || !gl.canPursue(npc, target.CharID) {
+		return false
+	}
+	return gl.moveNPCToPawn(npc, target.CharID, target.Position, npcAttackReach(npc)-10)
+}
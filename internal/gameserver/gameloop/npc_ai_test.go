@@ -0,0 +1,182 @@
+package gameloop
+
+import (
+	"testing"
+	"time"
+
+	"github.com/VerTox/l2go/internal/gameserver/models"
+)
+
+// addAggressiveNPC adds a level 10 monster that notices players within 300
+// and walks, spawned where it stands.
+func addAggressiveNPC(gl *GameLoop, objectID int32, pos models.Position) *models.NpcInstance {
+	npc := addAttackableNPC(gl, objectID, pos)
+	npc.Template.Level = 10
+	npc.Template.AggroRange = 300
+	npc.Template.Aggressive = true
+	npc.Template.CanMove = true
+	npc.Template.RunSpd = 200
+	gl.RegisterSpawnInfo(objectID, SpawnInfo{Position: pos})
+	return npc
+}
+
+func npcTargetOf(gl *GameLoop, objectID int32) int32 {
+	if ncs, ok := gl.npcCombatState[objectID]; ok && ncs.IsAttacking {
+		return ncs.TargetCharID
+	}
+	return 0
+}
+
+func TestAggro_NoticesPlayersInActiveRegions(t *testing.T) {
+	gl, p := newTestLoopWithPlayer(t)
+	near := addAggressiveNPC(gl, 1000, models.Position{X: 200})
+	far := addAggressiveNPC(gl, 1001, models.Position{X: -400})
+	above := addAggressiveNPC(gl, 1002, models.Position{Y: 100, Z: 400})
+
+	// Nobody has activated the region yet: the monsters sleep.
+	gl.thinkNPCs()
+	if npcTargetOf(gl, near.ObjectID) != 0 {
+		t.Fatal("a monster in an inactive region attacked")
+	}
+
+	gl.updatePlayerRegions(7, 0, 0)
+	gl.thinkNPCs()
+	if npcTargetOf(gl, near.ObjectID) != 7 {
+		t.Fatal("an aggressive monster must attack a player in its aggro range")
+	}
+	if npcTargetOf(gl, far.ObjectID) != 0 || npcTargetOf(gl, above.ObjectID) != 0 {
+		t.Fatal("monsters out of aggro range or height noticed the player")
+	}
+	if hl := gl.npcHateLists[near.ObjectID]; hl.Hate(7) != npcAggroHate || hl.GetTopDamageDealer() != 0 {
+		t.Fatal("noticing a player is hate, not damage")
+	}
+
+	// A passive monster minds its own business.
+	gl.npcLeaveCombat(near)
+	near.Template.Aggressive = false
+	gl.thinkNPCs()
+	if npcTargetOf(gl, near.ObjectID) != 0 {
+		t.Fatal("a passive monster attacked unprovoked")
+	}
+	near.Template.Aggressive = true
+
+	// A player well above the monster's level, or silent moving, is ignored.
+	gl.npcLeaveCombat(near)
+	p.Character.Level = 10 + aggroLevelGap + 1
+	gl.thinkNPCs()
+	if npcTargetOf(gl, near.ObjectID) != 0 {
+		t.Fatal("a monster attacked a player far above its level")
+	}
+	p.Character.Level = 1
+	p.Effects.Add(&models.BuffInfo{SkillID: 221, SilentMove: true})
+	gl.thinkNPCs()
+	if npcTargetOf(gl, near.ObjectID) != 0 {
+		t.Fatal("a monster noticed a silent moving player")
+	}
+
+	// Raid bosses see through Silent Move.
+	near.Template.Type = "L2RaidBoss"
+	gl.thinkNPCs()
+	if npcTargetOf(gl, near.ObjectID) != 7 {
+		t.Fatal("a raid boss must notice a silent moving player")
+	}
+}
+
+func TestNPCChase_RunsAfterTheTarget(t *testing.T) {
+	gl, _ := newTestLoopWithPlayer(t)
+	npc := addAggressiveNPC(gl, 1000, models.Position{X: 600})
+	gl.npcHateLists[npc.ObjectID] = NewHateList()
+	gl.npcHateLists[npc.ObjectID].AddHate(7, 10)
+	gl.startNPCAttack(npc.ObjectID, 7)
+
+	(&NPCNextAttackEvent{NPCObjectID: npc.ObjectID, TargetCharID: 7}).Execute(gl)
+	if !gl.isNPCMoving(npc.ObjectID) || npcTargetOf(gl, npc.ObjectID) != 7 {
+		t.Fatal("a target out of reach must be chased, not dropped")
+	}
+	gl.advanceNPCMovement(time.Now().Add(10 * time.Second))
+	if gl.isNPCMoving(npc.ObjectID) || npc.Position.X > npcAttackReach(npc) {
+		t.Fatalf("the chase should end within reach, NPC at %+v", npc.Position)
+	}
+	if got := gl.world.GetNPCsInRange(models.Position{}, npcAttackReach(npc)); len(got) != 1 {
+		t.Fatal("the spatial index must follow the NPC")
+	}
+
+	// A monster that cannot move gives up on a target out of reach.
+	gl.world.UpdateNPCPosition(npc.ObjectID, models.Position{X: 600}, 0)
+	npc.Template.CanMove = false
+	(&NPCNextAttackEvent{NPCObjectID: npc.ObjectID, TargetCharID: 7}).Execute(gl)
+	if npcTargetOf(gl, npc.ObjectID) != 0 {
+		t.Fatal("an immobile monster kept a target out of reach")
+	}
+}
+
+func TestNPCLosesInterest(t *testing.T) {
+	gl, p := newTestLoopWithPlayer(t)
+	gl.updatePlayerRegions(7, 0, 0)
+	npc := addAggressiveNPC(gl, 1000, models.Position{X: 100})
+	addPlayer(t, gl, 8, "acc2", models.Position{X: 150})
+
+	// Player 8 hurt it most, then ran past the chase range: it turns on 7.
+	hl := NewHateList()
+	hl.AddHate(8, 1000)
+	hl.AddHate(7, 10)
+	gl.npcHateLists[npc.ObjectID] = hl
+	p8, _ := gl.world.GetPlayer(8)
+	p8.Position = models.Position{X: npcChaseRange + 500}
+	gl.thinkNPCs()
+	if npcTargetOf(gl, npc.ObjectID) != 7 {
+		t.Fatalf("target = %d, want the player still in chase range", npcTargetOf(gl, npc.ObjectID))
+	}
+	if hl.GetTopDamageDealer() != 8 {
+		t.Fatal("losing interest must not forget the damage dealt")
+	}
+
+	// Hate for a player out of reach and aggro range fades until the monster
+	// gives up.
+	npc.Template.AggroRange = 0
+	p.Position = models.Position{X: 1000}
+	for range 40 {
+		gl.thinkNPCs()
+	}
+	if npcTargetOf(gl, npc.ObjectID) != 0 || hl.Hate(7) != 0 {
+		t.Fatalf("hate %d should have faded", hl.Hate(7))
+	}
+}
+
+func TestClanHelp_AlliesJoinTheFight(t *testing.T) {
+	gl, _ := newTestLoopWithPlayer(t)
+	orc := func(objectID int32, templateID int32, pos models.Position, clans ...string) *models.NpcInstance {
+		npc := addAttackableNPC(gl, objectID, pos)
+		npc.TemplateID = templateID
+		npc.Template.Clans = clans
+		npc.Template.ClanHelpRange = 300
+		return npc
+	}
+	victim := orc(1000, 20495, models.Position{}, "ORC")
+	ally := orc(1001, 20496, models.Position{X: 200}, "ORC")
+	stranger := orc(1002, 20497, models.Position{X: 150}, "SKELETON")
+	far := orc(1003, 20496, models.Position{X: 500}, "ORC")
+	ignored := orc(1004, 20498, models.Position{Y: 100}, "ORC")
+	victim.Template.IgnoreClanNpcIDs = []int32{20498}
+
+	gl.dealDamageToNPC(victim, 7, 10)
+
+	if npcTargetOf(gl, ally.ObjectID) != 7 {
+		t.Fatal("an ally of the same clan in help range must join the fight")
+	}
+	if gl.npcHateLists[ally.ObjectID].Hate(7) <= 0 {
+		t.Error("the ally must hate the attacker")
+	}
+	for _, npc := range []*models.NpcInstance{stranger, far, ignored} {
+		if npcTargetOf(gl, npc.ObjectID) != 0 {
+			t.Errorf("NPC %d answered a call it should not have", npc.TemplateID)
+		}
+	}
+
+	// A clan of ALL calls on everyone with a clan.
+	victim.Template.Clans = []string{models.ClanAll}
+	gl.dealDamageToNPC(victim, 7, 10)
+	if npcTargetOf(gl, stranger.ObjectID) != 7 {
+		t.Fatal("an ALL clan NPC must be helped by any clan")
+	}
+}
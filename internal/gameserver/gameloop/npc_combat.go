@@ -40,30 +40,52 @@ func (e *NPCNextAttackEvent) Execute(gl *GameLoop) {
 
 	player, exists := gl.world.GetPlayer(e.TargetCharID)
 	if !exists || player.Character == nil || player.Character.CurrentHP <= 0 {
-		gl.stopNPCAttack(e.NPCObjectID)
+		gl.npcLoseTarget(npc, e.TargetCharID)
 		return
 	}
 
-	// Check range (NPC attack range + collision)
-	attackRange := 40
-	if npc.Template != nil && npc.Template.AttackRange > 0 {
-		attackRange = npc.Template.AttackRange
+	// A casting NPC swings again once its cast is over; otherwise its turn may
+	// go to a skill instead of a swing.
+	end, casting := gl.isNPCCasting(npc.ObjectID)
+	if !casting {
+		end, casting = gl.npcTryCast(npc, player)
 	}
-	attackRange += 50
+	if casting {
+		gl.events.Schedule(&NPCNextAttackEvent{
+			At:           end,
+			NPCObjectID:  e.NPCObjectID,
+			TargetCharID: e.TargetCharID,
+		})
+		return
+	}
+
+	// Check range (NPC attack range + collision); out of it the NPC runs after
+	// the target and swings once it has caught up.
+	attackRange := npcAttackReach(npc)
 
 	dx := npc.Position.X - player.Position.X
 	dy := npc.Position.Y - player.Position.Y
 	distSq := dx*dx + dy*dy
 	rangeSq := attackRange * attackRange
 	if distSq > rangeSq {
-		gl.stopNPCAttack(e.NPCObjectID)
+		if !gl.chaseTarget(npc, player) {
+			gl.npcLoseTarget(npc, e.TargetCharID)
+			return
+		}
+		gl.events.Schedule(&NPCNextAttackEvent{
+			At:           time.Now().Add(npcChaseRetry),
+			NPCObjectID:  e.NPCObjectID,
+			TargetCharID: e.TargetCharID,
+		})
 		return
 	}
+	gl.stopNPCMove(npc)
 
 	// NPC attack speed
+	stats := npcStats(npc)
 	pAtkSpd := 300
-	if npc.Template != nil && npc.Template.PAtkSpd > 0 {
-		pAtkSpd = npc.Template.PAtkSpd
+	if stats.PAtkSpd > 0 {
+		pAtkSpd = stats.PAtkSpd
 	}
 	timeAtkMs := calcAttackSpeed(pAtkSpd)
 
@@ -71,11 +93,11 @@ func (e *NPCNextAttackEvent) Execute(gl *GameLoop) {
 	pAtk := 10
 	critRate := 4
 	if npc.Template != nil {
-		pAtk = int(npc.Template.PAtk)
+		pAtk = stats.PAtk
 		if pAtk < 1 {
 			pAtk = 1
 		}
-		critRate = npc.Template.CritRate
+		critRate = stats.CritRate
 	}
 
 	// Player defense
@@ -173,17 +195,23 @@ func (e *NPCHitEvent) Execute(gl *GameLoop) {
 	if !exists || player.Character == nil || player.Character.CurrentHP <= 0 {
 		return
 	}
+	gl.dealNPCDamageToPlayer(e.NPCObjectID, player, e.Damage)
+}
 
+// dealNPCDamageToPlayer applies damage from an NPC to a live player: reduces HP,
+// broadcasts the HP bar, reports the damage and handles death. Shared by melee
+// hits and NPC skill casts.
+func (gl *GameLoop) dealNPCDamageToPlayer(npcObjectID int32, player *registry.PlayerWorldState, damage int32) {
 	// Taking damage puts the player into combat stance (L2J: stance on real hit/being
 	// hit, not on the attack request). (l2go-7qv)
-	gl.enterCombatStance(e.TargetCharID)
+	gl.enterCombatStance(player.CharID)
 
-	player.Character.CurrentHP -= float64(e.Damage)
+	player.Character.CurrentHP -= float64(damage)
 	if player.Character.CurrentHP < 0 {
 		player.Character.CurrentHP = 0
 	}
 
-	su := outclient.BuildStatusUpdate(e.TargetCharID, []outclient.StatusAttribute{
+	su := outclient.BuildStatusUpdate(player.CharID, []outclient.StatusAttribute{
 		{ID: outclient.StatusMaxHP, Value: int32(player.Character.MaxHP)},
 		{ID: outclient.StatusCurHP, Value: int32(player.Character.CurrentHP)},
 	})
@@ -191,19 +219,20 @@ func (e *NPCHitEvent) Execute(gl *GameLoop) {
 		_ = conn.Send(su)
 	}
 
-	gl.broadcastToTargeters(e.TargetCharID, su)
+	gl.broadcastToTargeters(player.CharID, su)
+	gl.updatePartyStatus(player)
 
 	// Damage-received message to the victim (L2J PcStatus.reduceHp): the victim name
 	// is written as plain TEXT, then the attacker NPC name and the damage. (l2go-jau)
-	if e.Damage > 0 {
-		if npc, ok := gl.world.GetNPC(e.NPCObjectID); ok {
+	if damage > 0 {
+		if npc, ok := gl.world.GetNPC(npcObjectID); ok {
 			gl.sendToPlayer(player, outclient.NewSystemMessage(outclient.SysMsgC1ReceivedDamageS3FromC2).
-				AddString(player.Character.Name).AddNpcName(npc.TemplateID).AddInt(e.Damage).Build())
+				AddString(player.Character.Name).AddNpcName(npc.TemplateID).AddInt(damage).Build())
 		}
 	}
 
 	if player.Character.CurrentHP <= 0 {
-		gl.handlePlayerDeath(e.TargetCharID, player)
+		gl.handlePlayerDeath(player.CharID, player)
 	}
 }
 
@@ -218,6 +247,14 @@ func (gl *GameLoop) handlePlayerDeath(charID int32, player *registry.PlayerWorld
 	gl.stopAllNPCAttacksOnPlayer(charID)
 	gl.stopAttacker(charID)
 
+	// The dead can't trade (L2J cancelActiveTrade on doDie).
+	gl.cancelTradeOf(charID)
+
+	// Nor keep a store open or stay seated; the Die packet already took the store
+	// sign down on the clients.
+	player.PrivateStore.Type = registry.StoreNone
+	player.Sitting = false
+
 	// A dead player is no longer in combat (L2J: isInCombat resets on death). Clear the
 	// flag immediately rather than waiting out the 15s stance timeout — otherwise logout
 	// and restart stay blocked and the player is soft-locked until it expires. (l2go-3xh.1)
@@ -227,6 +264,11 @@ func (gl *GameLoop) handlePlayerDeath(charID int32, player *registry.PlayerWorld
 		Int32("char_id", charID).
 		Str("name", player.Character.Name).
 		Msg("Player died")
+
+	// An offline store keeper has no client to revive it: it leaves the world.
+	if player.OfflineTrade {
+		gl.endOfflineTrade(player)
+	}
 }
 
 // startNPCAttack begins an NPC's auto-attack against a player.
@@ -0,0 +1,190 @@
+package gameloop
+
+import (
+	"math"
+	"time"
+
+	"github.com/VerTox/l2go/internal/gameserver/models"
+	"github.com/VerTox/l2go/internal/gameserver/packets/outclient"
+	"github.com/VerTox/l2go/internal/gameserver/usecase"
+)
+
+// npcDefaultSpeed is the movement speed (units/sec) of an NPC whose template
+// gives none.
+const npcDefaultSpeed = 80
+
+// npcMove is an NPC's move along a path, interpolated by the tick like
+// server-driven player movement: a straight leg from Start to Dest, then one
+// leg to each waypoint left in Path.
+type npcMove struct {
+	Start   models.Position
+	Dest    models.Position
+	Path    []models.Position
+	Started time.Time
+	Speed   float64
+	// Home marks the walk back to the spawn point after a fight.
+	Home bool
+}
+
+// goal is where the move ends: its last waypoint.
+func (mv *npcMove) goal() models.Position {
+	if len(mv.Path) > 0 {
+		return mv.Path[len(mv.Path)-1]
+	}
+	return mv.Dest
+}
+
+// npcMoveSpeed returns an NPC's movement speed: running or walking, from its
+// template.
+func npcMoveSpeed(npc *models.NpcInstance) float64 {
+	speed := 0
+	if npc.Template != nil {
+		speed = npc.Template.WalkSpd
+		if npc.IsRunning {
+			speed = npc.Template.RunSpd
+		}
+	}
+	if speed <= 0 {
+		speed = npcDefaultSpeed
+	}
+	return float64(speed)
+}
+
+// headingTo is the L2 heading (0-65535, clockwise from east) from one
+// position toward another.
+func headingTo(from, to models.Position) int32 {
+	angle := math.Atan2(float64(to.Y-from.Y), float64(to.X-from.X))
+	if angle < 0 {
+		angle += 2 * math.Pi
+	}
+	return int32(angle * 65536 / (2 * math.Pi))
+}
+
+// moveNPCToPawn starts npc running toward the object at targetPos, to stop
+// within reach of it, and shows the chase to the players around (L2J
+// moveToPawn). An obstacle in the way is run round along a path found with
+// geodata, shown one leg at a time. Reports false when there is no way to the
+// target; a search put off to a later tick keeps the NPC on its current move.
+func (gl *GameLoop) moveNPCToPawn(npc *models.NpcInstance, targetID int32, targetPos models.Position, reach int) bool {
+	dest := stopPointWithinReach(npc.Position, targetPos, reach)
+	if dest == npc.Position {
+		return true
+	}
+	if mv, ok := gl.npcMoves[npc.ObjectID]; ok {
+		if g := mv.goal(); g.X == dest.X && g.Y == dest.Y {
+			return true // already on its way there
+		}
+	}
+	path, res := gl.findPath(npc.Position, dest)
+	switch res {
+	case pathNone:
+		return false
+	case pathDeferred:
+		return true
+	}
+	gl.setNPCRunning(npc, true)
+	from := npc.Position
+	gl.startNPCPath(npc, path)
+	if len(path) > 1 {
+		gl.broadcastToNearby(from, outclient.NewMoveToLocation(npc.ObjectID,
+			int32(path[0].X), int32(path[0].Y), int32(path[0].Z), int32(from.X), int32(from.Y), int32(from.Z)).Build())
+		return true
+	}
+	gl.broadcastToNearby(from, outclient.BuildMoveToPawn(npc.ObjectID, targetID, int32(reach),
+		from.X, from.Y, from.Z, targetPos.X, targetPos.Y, targetPos.Z))
+	return true
+}
+
+// walkNPCTo starts npc walking to dest along a path round the obstacles, and
+// shows the first leg to the players around. It leaves npc where it is
+// unless the result is pathFound.
+func (gl *GameLoop) walkNPCTo(npc *models.NpcInstance, dest models.Position) pathResult {
+	path, res := gl.findPath(npc.Position, dest)
+	if res != pathFound {
+		return res
+	}
+	gl.setNPCRunning(npc, false)
+	from := npc.Position
+	gl.startNPCPath(npc, path)
+	gl.broadcastToNearby(from, outclient.NewMoveToLocation(npc.ObjectID,
+		int32(path[0].X), int32(path[0].Y), int32(path[0].Z), int32(from.X), int32(from.Y), int32(from.Z)).Build())
+	return pathFound
+}
+
+// setNPCRunning switches npc between running and walking, telling the players
+// around when it changes.
+func (gl *GameLoop) setNPCRunning(npc *models.NpcInstance, running bool) {
+	if npc.IsRunning == running {
+		return
+	}
+	npc.IsRunning = running
+	gl.broadcastToNearby(npc.Position, outclient.NewChangeMoveType(npc.ObjectID, running).Build())
+}
+
+// startNPCPath records npc moving from where it stands along path's
+// waypoints, replacing any move in progress.
+func (gl *GameLoop) startNPCPath(npc *models.NpcInstance, path []models.Position) {
+	gl.npcMoves[npc.ObjectID] = &npcMove{
+		Start:   npc.Position,
+		Dest:    path[0],
+		Path:    path[1:],
+		Started: time.Now(),
+		Speed:   npcMoveSpeed(npc),
+	}
+}
+
+// stopNPCMove halts npc where it stands, if it was moving.
+func (gl *GameLoop) stopNPCMove(npc *models.NpcInstance) {
+	if _, ok := gl.npcMoves[npc.ObjectID]; !ok {
+		return
+	}
+	gl.stepNPCMovement(npc.ObjectID, time.Now())
+	delete(gl.npcMoves, npc.ObjectID)
+	gl.broadcastToNearby(npc.Position, outclient.BuildStopMove(npc.ObjectID,
+		int32(npc.Position.X), int32(npc.Position.Y), int32(npc.Position.Z), npc.Heading))
+}
+
+// isNPCMoving reports whether npc is on its way somewhere.
+func (gl *GameLoop) isNPCMoving(objectID int32) bool {
+	_, ok := gl.npcMoves[objectID]
+	return ok
+}
+
+// advanceNPCMovement moves every moving NPC along its line; a dead or vanished
+// NPC stops.
+func (gl *GameLoop) advanceNPCMovement(now time.Time) {
+	for objectID := range gl.npcMoves {
+		gl.stepNPCMovement(objectID, now)
+	}
+}
+
+// stepNPCMovement puts a moving NPC where it is at now, turning onto the next
+// leg at each waypoint and ending the move at the last.
+func (gl *GameLoop) stepNPCMovement(objectID int32, now time.Time) {
+	mv := gl.npcMoves[objectID]
+	npc, ok := gl.world.GetNPC(objectID)
+	if !ok || npc.IsDead {
+		delete(gl.npcMoves, objectID)
+		return
+	}
+	for {
+		total := usecase.CalculateMovementTime(distanceBetween(mv.Start, mv.Dest), mv.Speed)
+		pos, arrived := interpolatePosition(mv.Start, mv.Dest, now.Sub(mv.Started), total)
+		gl.world.UpdateNPCPosition(objectID, pos, headingTo(mv.Start, mv.Dest))
+		if !arrived {
+			return
+		}
+		if len(mv.Path) == 0 {
+			break
+		}
+		mv.Start, mv.Dest, mv.Path = mv.Dest, mv.Path[0], mv.Path[1:]
+		mv.Started = mv.Started.Add(total)
+		gl.broadcastToNearby(mv.Start, outclient.NewMoveToLocation(objectID,
+			int32(mv.Dest.X), int32(mv.Dest.Y), int32(mv.Dest.Z),
+			int32(mv.Start.X), int32(mv.Start.Y), int32(mv.Start.Z)).Build())
+	}
+	delete(gl.npcMoves, objectID)
+	if mv.Home {
+		gl.npcArrivedHome(npc)
+	}
+}
@@ -0,0 +1,288 @@
+package gameloop
+
+import (
+	"math/rand"
+	"time"
+
+	"github.com/VerTox/l2go/internal/gameserver/models"
+	"github.com/VerTox/l2go/internal/gameserver/packets/outclient"
+	"github.com/VerTox/l2go/internal/gameserver/registry"
+)
+
+const (
+	// npcSkillChance is the percent chance that a fighting NPC uses an
+	// offensive skill on its turn instead of swinging. Casters (MAGE and
+	// HEALER AI) cast whenever one is ready.
+	npcSkillChance = 10
+	// npcHealBelow is the share of its HP below which an NPC heals itself.
+	npcHealBelow = 0.5
+	// npcMAtkSpd is the casting speed of NPC templates (L2J baseMAtkSpd).
+	npcMAtkSpd = 333
+	// npcAfterCast is the pause between a cast landing and the NPC's next turn,
+	// on top of the skill's coolTime.
+	npcAfterCast = 300 * time.Millisecond
+)
+
+// npcCast is a skill an NPC is casting.
+type npcCast struct {
+	Skill    *models.Skill
+	TargetID int32
+	End      time.Time
+}
+
+// nextTurn is when the NPC may act again after the cast.
+func (c *npcCast) nextTurn() time.Time {
+	return c.End.Add(time.Duration(c.Skill.CoolTime)*time.Millisecond + npcAfterCast)
+}
+
+// npcStats returns an NPC's combat stats: its template's, with the modifiers
+// of the buffs and debuffs on it applied.
+func npcStats(npc *models.NpcInstance) models.ComputedStats {
+	var cs models.ComputedStats
+	if t := npc.Template; t != nil {
+		cs = models.ComputedStats{
+			PAtk:     int(t.PAtk),
+			MAtk:     int(t.MAtk),
+			PDef:     int(t.PDef),
+			MDef:     int(t.MDef),
+			PAtkSpd:  t.PAtkSpd,
+			MAtkSpd:  t.MAtkSpd,
+			CritRate: t.CritRate,
+			RunSpd:   t.RunSpd,
+			WalkSpd:  t.WalkSpd,
+			MaxHP:    int(t.HP),
+			MaxMP:    int(t.MP),
+		}
+	}
+	if cs.MAtkSpd <= 0 {
+		cs.MAtkSpd = npcMAtkSpd
+	}
+	return models.ApplyStatModifiers(cs, npc.Effects.Mods())
+}
+
+// isCasterNPC reports whether an NPC's AI prefers skills to melee.
+func isCasterNPC(npc *models.NpcInstance) bool {
+	if npc.Template == nil {
+		return false
+	}
+	switch npc.Template.AIType {
+	case "MAGE", "HEALER":
+		return true
+	}
+	return false
+}
+
+// chooseNPCSkill picks the skill a fighting NPC casts on its turn, and on
+// whom, mirroring L2J AttackableAI: a heal on itself when badly hurt, then
+// any self-buff it lacks, then — by npcSkillChance, or always for casters —
+// a nuke or debuff on its target when in cast range and sight. Only skills off
+// cooldown with the MP to pay for them count. roll(n) returns [0, n).
+// Returns nil when the NPC should just swing.
+func (gl *GameLoop) chooseNPCSkill(npc *models.NpcInstance, target *registry.PlayerWorldState, roll func(int) int) (*models.Skill, int32) {
+	if gl.skillData == nil || npc.Template == nil || len(npc.Template.Skills) == 0 {
+		return nil, 0
+	}
+	now := time.Now()
+	hurt := npc.CurrentHP < npc.Template.HP*npcHealBelow
+
+	var buffs, attacks []*models.Skill
+	for _, ns := range npc.Template.Skills {
+		skill := gl.skillData.GetSkill(int(ns.ID), ns.Level)
+		if skill == nil || !skill.OperateType.IsActive() || !gl.npcCanCast(npc, skill, now) {
+			continue
+		}
+		switch {
+		case isOffensiveSkill(skill):
+			if !npcSkillHasEffect(skill) || !npcInCastRange(npc, target.Position, skill) || !gl.geo.CanSeeTarget(npc.Position, target.Position) {
+				continue
+			}
+			attacks = append(attacks, skill)
+		case isBuffSkill(skill):
+			if !npc.Effects.HasSkill(int32(skill.ID)) && buildBuffFromSkill(skill, now) != nil {
+				buffs = append(buffs, skill)
+			}
+		case skillPowersOf(skill).hp > 0:
+			if hurt {
+				return skill, npc.ObjectID
+			}
+		}
+	}
+	if len(buffs) > 0 {
+		return buffs[roll(len(buffs))], npc.ObjectID
+	}
+	if len(attacks) == 0 {
+		return nil, 0
+	}
+	if !isCasterNPC(npc) && roll(100) >= npcSkillChance {
+		return nil, 0
+	}
+	return attacks[roll(len(attacks))], target.CharID
+}
+
+// npcSkillHasEffect reports whether casting an offensive skill would do
+// anything the loop models: damage, or a debuff with stat modifiers.
+func npcSkillHasEffect(skill *models.Skill) bool {
+	if isBuffSkill(skill) {
+		return buildBuffFromSkill(skill, time.Now()) != nil
+	}
+	pw := skillPowersOf(skill)
+	return pw.magic > 0 || pw.phys > 0 || pw.drain > 0
+}
+
+// npcCanCast reports whether a skill is off the NPC's cooldown and its MP
+// covers the full cost.
+func (gl *GameLoop) npcCanCast(npc *models.NpcInstance, skill *models.Skill, now time.Time) bool {
+	if readyAt, ok := gl.npcSkillReuse[npc.ObjectID][int32(skill.ID)]; ok && now.Before(readyAt) {
+		return false
+	}
+	return int(npc.CurrentMP) >= skill.MpConsume1+skill.MpConsume2
+}
+
+// npcInCastRange reports whether pos is within the skill's cast range of the
+// NPC, with the same margin targetInCastRange gives players.
+func npcInCastRange(npc *models.NpcInstance, pos models.Position, skill *models.Skill) bool {
+	if skill.CastRange <= 0 {
+		return true
+	}
+	dx := npc.Position.X - pos.X
+	dy := npc.Position.Y - pos.Y
+	reach := skill.CastRange + 80
+	return dx*dx+dy*dy <= reach*reach
+}
+
+// npcTryCast lets a fighting NPC cast a skill on its turn instead of
+// swinging. Reports whether it began a cast, and when its next turn is.
+func (gl *GameLoop) npcTryCast(npc *models.NpcInstance, target *registry.PlayerWorldState) (time.Time, bool) {
+	skill, targetID := gl.chooseNPCSkill(npc, target, rand.Intn)
+	if skill == nil {
+		return time.Time{}, false
+	}
+	return gl.beginNPCCast(npc, skill, targetID), true
+}
+
+// beginNPCCast starts an NPC cast the way handleCastRequest starts a
+// player's: it pays mpConsume1, stands still, plays MagicSkillUse and
+// schedules the hit. Returns when the NPC's next turn is.
+func (gl *GameLoop) beginNPCCast(npc *models.NpcInstance, skill *models.Skill, targetID int32) time.Time {
+	npc.CurrentMP -= float64(skill.MpConsume1)
+	gl.stopNPCMove(npc)
+
+	hitTime := scaledCastTime(skill, npcStats(npc))
+	cast := &npcCast{Skill: skill, TargetID: targetID, End: time.Now().Add(hitTime)}
+	gl.npcCasts[npc.ObjectID] = cast
+
+	tpos := gl.objectPosition(targetID, npc.Position)
+	gl.broadcastToNearby(npc.Position, outclient.BuildMagicSkillUse(npc.ObjectID, targetID,
+		int32(skill.ID), int32(skill.Level), int32(hitTime.Milliseconds()), int32(skill.ReuseDelay),
+		int32(npc.Position.X), int32(npc.Position.Y), int32(npc.Position.Z),
+		int32(tpos.X), int32(tpos.Y), int32(tpos.Z)))
+
+	gl.events.Schedule(&NPCCastHitEvent{At: cast.End, NPCObjectID: npc.ObjectID, Cast: cast})
+	return cast.nextTurn()
+}
+
+// NPCCastHitEvent fires at the end of an NPC cast: applies the skill and arms
+// its cooldown.
+type NPCCastHitEvent struct {
+	At          time.Time
+	NPCObjectID int32
+	Cast        *npcCast
+}
+
+func (e *NPCCastHitEvent) ExecuteAt() time.Time { return e.At }
+
+func (e *NPCCastHitEvent) Execute(gl *GameLoop) {
+	if gl.npcCasts[e.NPCObjectID] != e.Cast {
+		return // the NPC died or started another cast
+	}
+	delete(gl.npcCasts, e.NPCObjectID)
+
+	npc, ok := gl.world.GetNPC(e.NPCObjectID)
+	if !ok || npc.IsDead {
+		return
+	}
+	skill := e.Cast.Skill
+	if skill.MpConsume2 > 0 {
+		if int(npc.CurrentMP) < skill.MpConsume2 {
+			return // fizzles
+		}
+		npc.CurrentMP -= float64(skill.MpConsume2)
+	}
+
+	gl.broadcastToNearby(npc.Position, outclient.BuildMagicSkillLaunched(npc.ObjectID,
+		int32(skill.ID), int32(skill.Level), []int32{e.Cast.TargetID}))
+	gl.applyNPCSkillEffects(npc, e.Cast.TargetID, skill)
+
+	if skill.ReuseDelay > 0 {
+		byID := gl.npcSkillReuse[npc.ObjectID]
+		if byID == nil {
+			byID = make(map[int32]time.Time)
+			gl.npcSkillReuse[npc.ObjectID] = byID
+		}
+		byID[int32(skill.ID)] = time.Now().Add(time.Duration(skill.ReuseDelay) * time.Millisecond)
+	}
+}
+
+// isNPCCasting reports whether an NPC is in the middle of a cast, and when
+// its next turn is.
+func (gl *GameLoop) isNPCCasting(objectID int32) (time.Time, bool) {
+	if c, ok := gl.npcCasts[objectID]; ok {
+		return c.nextTurn(), true
+	}
+	return time.Time{}, false
+}
+
+// applyNPCSkillEffects is applySkillEffects for an NPC caster: buffs and
+// debuffs go through applyBuff, heals restore the NPC, and damage lands on a
+// player target through the NPC hit path.
+func (gl *GameLoop) applyNPCSkillEffects(npc *models.NpcInstance, targetID int32, skill *models.Skill) {
+	if isBuffSkill(skill) {
+		gl.applyBuff(targetID, skill)
+		return
+	}
+	pw := skillPowersOf(skill)
+	if pw.hp > 0 && targetID == npc.ObjectID {
+		gl.healNPC(npc, float64(pw.hp))
+	}
+	if pw.magic <= 0 && pw.phys <= 0 && pw.drain <= 0 {
+		return
+	}
+	tgt, ok := gl.world.GetPlayer(targetID)
+	if !ok || tgt.Character == nil || tgt.Character.CurrentHP <= 0 {
+		return
+	}
+	atk := npcStats(npc)
+	def := gl.computePlayerStats(tgt)
+	total := 0
+	if pw.magic > 0 {
+		total += calcMagicDamage(float64(atk.MAtk), float64(def.MDef), pw.magic)
+	}
+	if pw.phys > 0 {
+		total += calcPhysSkillDamage(atk.PAtk, def.PDef, pw.phys)
+	}
+	drain := 0
+	if pw.drain > 0 {
+		drain = calcMagicDamage(float64(atk.MAtk), float64(def.MDef), pw.drain)
+		total += drain
+	}
+	gl.dealNPCDamageToPlayer(npc.ObjectID, tgt, int32(total))
+	if drain > 0 {
+		gl.healNPC(npc, float64(drain/2))
+	}
+}
+
+// healNPC restores an NPC's HP up to its maximum and shows the HP bar to its
+// targeters.
+func (gl *GameLoop) healNPC(npc *models.NpcInstance, hp float64) {
+	if npc.IsDead || npc.Template == nil {
+		return
+	}
+	npc.CurrentHP += hp
+	if npc.CurrentHP > npc.Template.HP {
+		npc.CurrentHP = npc.Template.HP
+	}
+	gl.broadcastToTargeters(npc.ObjectID, outclient.BuildStatusUpdate(npc.ObjectID, []outclient.StatusAttribute{
+		{ID: outclient.StatusMaxHP, Value: int32(npc.Template.HP)},
+		{ID: outclient.StatusCurHP, Value: int32(npc.CurrentHP)},
+	}))
+}
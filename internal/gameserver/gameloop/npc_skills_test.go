@@ -0,0 +1,232 @@
+package gameloop
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/VerTox/l2go/internal/gameserver/models"
+	"github.com/VerTox/l2go/internal/gameserver/registry"
+)
+
+// npcSkillsXML holds an NPC's kit: a magic nuke (4001), a self heal (4002),
+// a self-buff (4003), a debuff (4004) and a passive (4005).
+const npcSkillsXML = `<list>
+	<skill id="4001" levels="1" name="NPC Wind Strike">
+		<set name="castRange" val="600" />
+		<set name="hitTime" val="4000" />
+		<set name="isMagic" val="1" />
+		<set name="mpConsume1" val="3" />
+		<set name="mpConsume2" val="10" />
+		<set name="operateType" val="A1" />
+		<set name="reuseDelay" val="8000" />
+		<set name="targetType" val="ENEMY" />
+		<effects>
+			<effect name="MagicalAttack">
+				<param power="20" />
+			</effect>
+		</effects>
+	</skill>
+	<skill id="4002" levels="1" name="NPC Heal">
+		<set name="castRange" val="600" />
+		<set name="hitTime" val="2000" />
+		<set name="isMagic" val="1" />
+		<set name="mpConsume1" val="4" />
+		<set name="mpConsume2" val="13" />
+		<set name="operateType" val="A1" />
+		<set name="reuseDelay" val="8000" />
+		<set name="targetType" val="TARGET" />
+		<effects>
+			<effect name="Heal">
+				<param power="50" />
+			</effect>
+		</effects>
+	</skill>
+	<skill id="4003" levels="1" name="NPC Might">
+		<set name="abnormalTime" val="120" />
+		<set name="abnormalType" val="PA_UP" />
+		<set name="abnormalLvl" val="1" />
+		<set name="hitTime" val="1500" />
+		<set name="isMagic" val="1" />
+		<set name="operateType" val="A2" />
+		<set name="targetType" val="SELF" />
+		<effects>
+			<effect name="Buff">
+				<mul stat="pAtk" val="2" />
+			</effect>
+		</effects>
+	</skill>
+	<skill id="4004" levels="1" name="NPC Curse Weakness">
+		<set name="abnormalTime" val="30" />
+		<set name="abnormalType" val="PA_DOWN" />
+		<set name="castRange" val="600" />
+		<set name="hitTime" val="1500" />
+		<set name="isDebuff" val="true" />
+		<set name="isMagic" val="1" />
+		<set name="operateType" val="A2" />
+		<set name="reuseDelay" val="8000" />
+		<set name="targetType" val="ENEMY_ONLY" />
+		<effects>
+			<effect name="Debuff">
+				<mul stat="pAtk" val="0.5" />
+			</effect>
+		</effects>
+	</skill>
+	<skill id="4005" levels="1" name="Weapon Mastery">
+		<set name="operateType" val="P" />
+		<set name="targetType" val="SELF" />
+		<effects>
+			<effect name="Buff">
+				<mul stat="pAtk" val="1.1" />
+			</effect>
+		</effects>
+	</skill>
+</list>`
+
+// addCasterNPC adds a monster with the given skills of npcSkillsXML, wiring
+// the skill data into the loop.
+func addCasterNPC(t *testing.T, gl *GameLoop, aiType string, pos models.Position, skills ...int32) *models.NpcInstance {
+	t.Helper()
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "04000-04099.xml"), []byte(npcSkillsXML), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	gl.SetSkillData(registry.NewSkillData([]string{dir}))
+
+	npc := addAggressiveNPC(gl, 1000, pos)
+	npc.Template.AIType = aiType
+	npc.Template.MP, npc.CurrentMP = 100, 100
+	npc.Template.MAtk = 100
+	npc.Template.PAtk = 10
+	for _, id := range skills {
+		npc.Template.Skills = append(npc.Template.Skills, models.NpcSkill{ID: id, Level: 1})
+	}
+	return npc
+}
+
+// worstRoll always rolls the highest number: no skill chance ever succeeds.
+func worstRoll(n int) int { return n - 1 }
+
+func TestNPCCast_CasterNukesFromRange(t *testing.T) {
+	gl, p := newTestLoopWithPlayer(t)
+	p.Character.MaxHP, p.Character.CurrentHP = 1000, 1000
+	npc := addCasterNPC(t, gl, "MAGE", models.Position{X: 400}, 4001, 4005)
+	gl.startNPCAttack(npc.ObjectID, 7)
+
+	(&NPCNextAttackEvent{NPCObjectID: npc.ObjectID, TargetCharID: 7}).Execute(gl)
+	cast := gl.npcCasts[npc.ObjectID]
+	if cast == nil || cast.Skill.ID != 4001 || cast.TargetID != 7 {
+		t.Fatalf("a mage in cast range must nuke instead of running in, cast = %+v", cast)
+	}
+	if npc.CurrentMP != 97 {
+		t.Errorf("MP after cast start = %v, want 97", npc.CurrentMP)
+	}
+	if gl.isNPCMoving(npc.ObjectID) {
+		t.Error("a casting NPC must stand still")
+	}
+
+	// A turn during the cast waits for it.
+	(&NPCNextAttackEvent{NPCObjectID: npc.ObjectID, TargetCharID: 7}).Execute(gl)
+	if gl.npcCasts[npc.ObjectID] != cast {
+		t.Fatal("a turn during the cast replaced it")
+	}
+
+	(&NPCCastHitEvent{NPCObjectID: npc.ObjectID, Cast: cast}).Execute(gl)
+	if _, casting := gl.npcCasts[npc.ObjectID]; casting {
+		t.Error("cast not over after the hit")
+	}
+	if npc.CurrentMP != 87 {
+		t.Errorf("MP after the hit = %v, want 87", npc.CurrentMP)
+	}
+	if p.Character.CurrentHP >= 1000 {
+		t.Error("the nuke did no damage")
+	}
+	if gl.npcCanCast(npc, cast.Skill, time.Now()) {
+		t.Error("the nuke must be on cooldown after landing")
+	}
+	// On cooldown, the mage has nothing to cast.
+	if skill, _ := gl.chooseNPCSkill(npc, p, worstRoll); skill != nil {
+		t.Errorf("chose %d while everything is on cooldown", skill.ID)
+	}
+}
+
+func TestNPCCast_FighterMostlySwings(t *testing.T) {
+	gl, p := newTestLoopWithPlayer(t)
+	npc := addCasterNPC(t, gl, "BALANCED", models.Position{X: 100}, 4001)
+
+	if skill, _ := gl.chooseNPCSkill(npc, p, worstRoll); skill != nil {
+		t.Fatal("a fighter cast past its skill chance")
+	}
+	if skill, target := gl.chooseNPCSkill(npc, p, func(int) int { return 0 }); skill == nil || target != 7 {
+		t.Fatal("a fighter never casts")
+	}
+
+	// Out of the skill's cast range there is nothing to cast.
+	npc.Position.X = 2000
+	if skill, _ := gl.chooseNPCSkill(npc, p, func(int) int { return 0 }); skill != nil {
+		t.Fatal("cast a nuke out of its cast range")
+	}
+}
+
+func TestNPCCast_BuffsItselfAndHealsWhenHurt(t *testing.T) {
+	gl, p := newTestLoopWithPlayer(t)
+	npc := addCasterNPC(t, gl, "BALANCED", models.Position{X: 100}, 4002, 4003)
+
+	skill, target := gl.chooseNPCSkill(npc, p, worstRoll)
+	if skill == nil || skill.ID != 4003 || target != npc.ObjectID {
+		t.Fatalf("an NPC lacking its buff must buff itself, got %v", skill)
+	}
+	base := npcStats(npc).PAtk
+	gl.applyNPCSkillEffects(npc, npc.ObjectID, skill)
+	if got := npcStats(npc).PAtk; got != 2*base {
+		t.Errorf("buffed pAtk = %d, want %d", got, 2*base)
+	}
+	if skill, _ := gl.chooseNPCSkill(npc, p, worstRoll); skill != nil {
+		t.Fatal("recast a buff already on")
+	}
+
+	npc.CurrentHP = 20
+	skill, target = gl.chooseNPCSkill(npc, p, worstRoll)
+	if skill == nil || skill.ID != 4002 || target != npc.ObjectID {
+		t.Fatalf("a badly hurt NPC must heal itself, got %v", skill)
+	}
+	gl.applyNPCSkillEffects(npc, npc.ObjectID, skill)
+	if npc.CurrentHP != 70 {
+		t.Errorf("HP after heal = %v, want 70", npc.CurrentHP)
+	}
+
+	// Its effects end with it.
+	gl.handleNPCDeath(npc, 7)
+	if npc.Effects.Len() != 0 {
+		t.Error("a dead NPC kept its buffs")
+	}
+}
+
+func TestNPCCast_DebuffLandsOnPlayer(t *testing.T) {
+	gl, p := newTestLoopWithPlayer(t)
+	npc := addCasterNPC(t, gl, "MAGE", models.Position{X: 100}, 4004)
+
+	skill, target := gl.chooseNPCSkill(npc, p, worstRoll)
+	if skill == nil || skill.ID != 4004 || target != 7 {
+		t.Fatalf("a mage must debuff its target, got %v", skill)
+	}
+	gl.applyNPCSkillEffects(npc, target, skill)
+	if !p.Effects.HasSkill(4004) {
+		t.Fatal("the debuff did not land")
+	}
+}
+
+func TestNPCCast_DeathCancelsCast(t *testing.T) {
+	gl, p := newTestLoopWithPlayer(t)
+	p.Character.MaxHP, p.Character.CurrentHP = 1000, 1000
+	npc := addCasterNPC(t, gl, "MAGE", models.Position{X: 100}, 4001)
+
+	gl.beginNPCCast(npc, gl.skillData.GetSkill(4001, 1), 7)
+	cast := gl.npcCasts[npc.ObjectID]
+	gl.handleNPCDeath(npc, 7)
+	(&NPCCastHitEvent{NPCObjectID: npc.ObjectID, Cast: cast}).Execute(gl)
+	if p.Character.CurrentHP != 1000 {
+		t.Error("a dead NPC's cast landed")
+	}
+}
@@ -0,0 +1,117 @@
+package gameloop
+
+import (
+	"math/rand"
+
+	"github.com/VerTox/l2go/internal/gameserver/models"
+	"github.com/VerTox/l2go/internal/gameserver/packets/outclient"
+)
+
+const (
+	// npcWanderRadius is how far from its spawn point an idle monster
+	// wanders (L2J MaxDriftRange).
+	npcWanderRadius = 300
+	// npcRandomWalkRate: an idle monster sets off on a walk on one AI step
+	// in this many (L2J RANDOM_WALK_RATE).
+	npcRandomWalkRate = 30
+)
+
+// canWander reports whether an idle NPC walks around its spawn point:
+// monsters that move do, guards keep their post.
+func canWander(npc *models.NpcInstance) bool {
+	return npc.Template != nil && npc.Template.CanMove && npc.Template.Type != "L2Guard"
+}
+
+// wander walks an idle NPC to a random point within npcWanderRadius of its
+// spawn point.
+func (gl *GameLoop) wander(npc *models.NpcInstance, roll func(n int) int) {
+	spawn, ok := gl.npcSpawnInfo[npc.ObjectID]
+	if !ok {
+		return
+	}
+	dest := spawn.Position
+	dest.X += roll(2*npcWanderRadius+1) - npcWanderRadius
+	dest.Y += roll(2*npcWanderRadius+1) - npcWanderRadius
+	gl.walkNPCTo(npc, dest)
+}
+
+// thinkIdle is the AI step of an NPC with nobody to fight: a minion keeps to
+// its leader, one away from its spawn point walks back, one at home now and
+// then wanders.
+func (gl *GameLoop) thinkIdle(npc *models.NpcInstance) {
+	if gl.followLeader(npc) {
+		return
+	}
+	if gl.isNPCMoving(npc.ObjectID) {
+		return
+	}
+	spawn, ok := gl.npcSpawnInfo[npc.ObjectID]
+	if !ok || !canWander(npc) {
+		return
+	}
+	if distanceBetween(npc.Position, spawn.Position) > npcWanderRadius*3/2 {
+		gl.returnHome(npc)
+		return
+	}
+	if rand.Intn(npcRandomWalkRate) == 0 {
+		gl.wander(npc, rand.Intn)
+	}
+}
+
+// returnHome walks an NPC that left combat back to its spawn point, where it
+// recovers (L2J returnHome); a minion's home is beside its leader. One that
+// cannot walk recovers where it stands; one with no way back is put home at
+// once. When the tick's path search budget is spent it stays put, and the
+// idle AI sends it home later.
+func (gl *GameLoop) returnHome(npc *models.NpcInstance) {
+	if leader, ok := gl.leaderOf(npc.ObjectID); ok && !leader.IsDead {
+		gl.setMinionHome(npc, leader)
+	}
+	spawn, ok := gl.npcSpawnInfo[npc.ObjectID]
+	if !ok || npc.Template == nil || !npc.Template.CanMove || npc.Position == spawn.Position {
+		gl.stopNPCMove(npc)
+		gl.npcArrivedHome(npc)
+		return
+	}
+	switch gl.walkNPCTo(npc, spawn.Position) {
+	case pathFound:
+		gl.npcMoves[npc.ObjectID].Home = true
+	case pathNone:
+		gl.stopNPCMove(npc)
+		gl.broadcastToNearby(npc.Position, outclient.BuildTeleportToLocation(npc.ObjectID,
+			int32(spawn.Position.X), int32(spawn.Position.Y), int32(spawn.Position.Z), spawn.Heading))
+		gl.world.UpdateNPCPosition(npc.ObjectID, spawn.Position, spawn.Heading)
+		gl.npcArrivedHome(npc)
+	case pathDeferred:
+		gl.stopNPCMove(npc)
+	}
+}
+
+// isReturningHome reports whether the NPC is on its way back to its spawn
+// point; it ignores players it passes.
+func (gl *GameLoop) isReturningHome(objectID int32) bool {
+	mv, ok := gl.npcMoves[objectID]
+	return ok && mv.Home
+}
+
+// npcArrivedHome heals an NPC back from a fight, HP and MP, and forgets who
+// was in it; a raid boss saves its state whether or not it needed healing.
+func (gl *GameLoop) npcArrivedHome(npc *models.NpcInstance) {
+	delete(gl.npcHateLists, npc.ObjectID)
+	if npc.Template == nil {
+		return
+	}
+	if npc.CurrentHP < npc.Template.HP || npc.CurrentMP < npc.Template.MP {
+		npc.CurrentHP = npc.Template.HP
+		npc.CurrentMP = npc.Template.MP
+		gl.broadcastToTargeters(npc.ObjectID, outclient.BuildStatusUpdate(npc.ObjectID, []outclient.StatusAttribute{
+			{ID: outclient.StatusMaxHP, Value: int32(npc.Template.HP)},
+			{ID: outclient.StatusCurHP, Value: int32(npc.CurrentHP)},
+			{ID: outclient.StatusMaxMP, Value: int32(npc.Template.MP)},
+			{ID: outclient.StatusCurMP, Value: int32(npc.CurrentMP)},
+		}))
+	}
+	if isRaidBoss(npc) {
+		gl.enqueueRaidBossJob(RaidBossJob{Status: raidBossStatus(npc)})
+	}
+}
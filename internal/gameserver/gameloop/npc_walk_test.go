@@ -0,0 +1,78 @@
+package gameloop
+
+import (
+	"testing"
+	"time"
+
+	"github.com/VerTox/l2go/internal/gameserver/models"
+)
+
+func TestWander_StaysNearTheSpawn(t *testing.T) {
+	gl, _ := newTestLoopWithPlayer(t)
+	npc := addAggressiveNPC(gl, 1000, models.Position{X: 5000, Y: 5000})
+	npc.Template.WalkSpd = 50
+
+	// The farthest corner the roll allows.
+	gl.wander(npc, func(n int) int { return n - 1 })
+	mv := gl.npcMoves[npc.ObjectID]
+	if mv == nil || mv.Dest != (models.Position{X: 5000 + npcWanderRadius, Y: 5000 + npcWanderRadius}) {
+		t.Fatalf("wander move = %+v", mv)
+	}
+	if npc.IsRunning || mv.Speed != 50 || mv.Home {
+		t.Fatal("a wandering monster walks")
+	}
+
+	gl.advanceNPCMovement(time.Now().Add(time.Minute))
+	if gl.isNPCMoving(npc.ObjectID) || npc.Position != mv.Dest {
+		t.Fatalf("NPC at %+v after its walk", npc.Position)
+	}
+
+	// Guards keep their post.
+	npc.Template.Type = "L2Guard"
+	if canWander(npc) {
+		t.Fatal("a guard must not wander")
+	}
+}
+
+func TestLeaveCombat_WalksHomeAndHeals(t *testing.T) {
+	gl, _ := newTestLoopWithPlayer(t)
+	gl.updatePlayerRegions(7, 0, 0)
+	npc := addAggressiveNPC(gl, 1000, models.Position{X: 100})
+	gl.world.UpdateNPCPosition(npc.ObjectID, models.Position{X: 250}, 0)
+	npc.CurrentHP = 30
+	hl := NewHateList()
+	hl.AddHate(7, 70)
+	gl.npcHateLists[npc.ObjectID] = hl
+	gl.startNPCAttack(npc.ObjectID, 7)
+
+	gl.npcLeaveCombat(npc)
+	if !gl.isReturningHome(npc.ObjectID) || npcTargetOf(gl, npc.ObjectID) != 0 {
+		t.Fatal("a monster leaving combat must walk home")
+	}
+
+	// On the way home it ignores the player it walks past.
+	gl.thinkNPCs()
+	if npcTargetOf(gl, npc.ObjectID) != 0 {
+		t.Fatal("a monster walking home attacked")
+	}
+
+	gl.advanceNPCMovement(time.Now().Add(time.Minute))
+	if npc.Position != (models.Position{X: 100}) || npc.CurrentHP != npc.Template.HP {
+		t.Fatalf("NPC at %+v with %v HP, want home and healed", npc.Position, npc.CurrentHP)
+	}
+	if _, ok := gl.npcHateLists[npc.ObjectID]; ok {
+		t.Fatal("a healed monster must forget the fight")
+	}
+}
+
+func TestLeaveCombat_ImmobileHealsInPlace(t *testing.T) {
+	gl, _ := newTestLoopWithPlayer(t)
+	npc := addAttackableNPC(gl, 1000, models.Position{X: 100})
+	gl.RegisterSpawnInfo(npc.ObjectID, SpawnInfo{Position: models.Position{X: 100}})
+	npc.CurrentHP = 1
+
+	gl.npcLeaveCombat(npc)
+	if gl.isNPCMoving(npc.ObjectID) || npc.CurrentHP != npc.Template.HP {
+		t.Fatal("an NPC that cannot move heals where it stands")
+	}
+}
@@ -0,0 +1,235 @@
+package gameloop
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/rs/zerolog/log"
+
+	"github.com/VerTox/l2go/internal/gameserver/models"
+	"github.com/VerTox/l2go/internal/gameserver/packets/outclient"
+	"github.com/VerTox/l2go/internal/gameserver/registry"
+)
+
+// Bypass tokens the NPC dialogues link to (RequestBypassToServer). Each is
+// registered in the bypass table by the file that handles it.
+const (
+	// BypassChat shows another page of the addressed NPC's dialogue; the page
+	// number follows it (L2J "npc_%objectId%_Chat 1").
+	BypassChat            = "Chat"
+	BypassLearnSkills     = "learn_skills"
+	BypassDepositPrivate  = "deposit_p"
+	BypassWithdrawPrivate = "withdraw_p"
+	BypassDepositClan     = "deposit_c"
+	BypassWithdrawClan    = "withdraw_c"
+	// BypassShop opens a merchant's shop window; the buy list id follows it.
+	BypassShop = "buy"
+	// BypassMultisell opens a multisell list; the list id follows it.
+	BypassMultisell = "multisell"
+	// BypassTeleportList shows a gatekeeper's destinations; the teleport list
+	// id follows it.
+	BypassTeleportList = "teleport_list"
+	// BypassTeleport teleports to a destination; the teleport list id and
+	// the location id follow it (L2J "goto").
+	BypassTeleport = "goto"
+	// BypassQuest talks to the NPC about its quests; a quest name and a quest
+	// event may follow it (L2J "Quest").
+	BypassQuest = "Quest"
+)
+
+func init() { RegisterBypass(BypassChat, bypassChat) }
+
+// NpcDialogHtml is the dialogue an NPC opens for the player: its first html
+// page from the datapack if it has one, or else the generated service page,
+// which links to the NPC's quests if it takes part in any.
+func NpcDialogHtml(player *registry.PlayerWorldState, npc *models.NpcInstance) string {
+	if page, ok := npcHtmlPage(npc, 0); ok {
+		return renderNpcHtml(page, player, npc)
+	}
+	html := npcServiceHtml(player, npc)
+	if HasQuests(npc) {
+		link := "<a action=\"bypass -h npc_" + strconv.Itoa(int(npc.ObjectID)) + "_" + BypassQuest + "\">Quest</a><br>"
+		html = strings.Replace(html, "</body>", link+"</body>", 1)
+	}
+	return html
+}
+
+// npcHtmlDir is the html directory holding an NPC's pages, after its type
+// (L2J's getHtmlPath overrides); other NPCs keep theirs in "default".
+func npcHtmlDir(npc *models.NpcInstance) string {
+	switch {
+	case IsMerchant(npc.Template):
+		return "merchant"
+	case IsWarehouseKeeper(npc.Template):
+		return "warehouse"
+	case registry.IsTrainer(npc.TemplateID):
+		return "trainer"
+	case IsTeleporter(npc.Template):
+		return "teleporter"
+	}
+	return "default"
+}
+
+// npcHtmlPage looks up page n of an NPC's dialogue: "<npcId>.htm" for the
+// first page, "<npcId>-<n>.htm" for the others, in the NPC's own html
+// directory and then in "default".
+func npcHtmlPage(npc *models.NpcInstance, n int) (string, bool) {
+	name := strconv.Itoa(int(npc.TemplateID))
+	if n > 0 {
+		name += "-" + strconv.Itoa(n)
+	}
+	name += ".htm"
+	pages := registry.GetHtmlRegistry()
+	if page, ok := pages.Get(npcHtmlDir(npc) + "/" + name); ok {
+		return page, true
+	}
+	return pages.Get("default/" + name)
+}
+
+// renderNpcHtml fills in the placeholders of an html page shown by npc.
+func renderNpcHtml(page string, player *registry.PlayerWorldState, npc *models.NpcInstance) string {
+	var npcName, playerName string
+	if npc.Template != nil {
+		npcName = npc.Template.Name
+	}
+	if player.Character != nil {
+		playerName = player.Character.Name
+	}
+	return strings.NewReplacer(
+		"%objectId%", strconv.Itoa(int(npc.ObjectID)),
+		"%npcId%", strconv.Itoa(int(npc.TemplateID)),
+		"%npcname%", npcName,
+		"%playername%", playerName,
+	).Replace(page)
+}
+
+// dialogNpc returns the NPC a living player talks to at npcObjID: alive, not
+// attackable and within interact range.
+func (gl *GameLoop) dialogNpc(player *registry.PlayerWorldState, npcObjID int32) (*models.NpcInstance, bool) {
+	if player.Character == nil || player.Character.CurrentHP <= 0 {
+		return nil, false
+	}
+	npc, ok := gl.world.GetNPC(npcObjID)
+	if !ok || npc.IsDead || npc.IsAttackable() {
+		return nil, false
+	}
+	dx := player.Position.X - npc.Position.X
+	dy := player.Position.Y - npc.Position.Y
+	if dx*dx+dy*dy > interactRange*interactRange {
+		return nil, false
+	}
+	return npc, true
+}
+
+// bypassChat shows page arg of the NPC's dialogue; no page number means the
+// first page.
+func bypassChat(gl *GameLoop, player *registry.PlayerWorldState, npcObjID int32, arg string) {
+	n := 0
+	if arg != "" {
+		var err error
+		if n, err = strconv.Atoi(arg); err != nil || n < 0 {
+			return
+		}
+	}
+	npc, ok := gl.dialogNpc(player, npcObjID)
+	if !ok {
+		return
+	}
+	html := NpcDialogHtml(player, npc)
+	if n > 0 {
+		page, ok := npcHtmlPage(npc, n)
+		if !ok {
+			log.Debug().Int32("npc_id", npc.TemplateID).Int("page", n).Msg("npc chat page not found")
+			gl.sendToPlayer(player, outclient.BuildActionFailed())
+			return
+		}
+		html = renderNpcHtml(page, player, npc)
+	}
+	gl.sendToPlayer(player, outclient.BuildNpcHtmlMessage(npc.ObjectID, html))
+}
+
+// handleLinkHtml shows a datapack page a dialogue links to, on behalf of the
+// targeted NPC (L2J RequestLinkHtml). Links leaving the html directory are
+// refused.
+func (gl *GameLoop) handleLinkHtml(cmd CmdLinkHtml) {
+	player, ok := gl.world.GetPlayer(cmd.CharID)
+	if !ok {
+		return
+	}
+	if !registry.IsHtmlPath(cmd.Link) {
+		log.Warn().Int32("char_id", cmd.CharID).Str("link", cmd.Link).Msg("refused html link")
+		return
+	}
+	npc, ok := gl.dialogNpc(player, player.TargetID)
+	if !ok {
+		return
+	}
+	page, ok := registry.GetHtmlRegistry().Get(cmd.Link)
+	if !ok {
+		log.Debug().Str("link", cmd.Link).Msg("linked html page not found")
+		gl.sendToPlayer(player, outclient.BuildActionFailed())
+		return
+	}
+	gl.sendToPlayer(player, outclient.BuildNpcHtmlMessage(npc.ObjectID, renderNpcHtml(page, player, npc)))
+}
+
+// npcServiceHtml is the dialogue generated for an NPC without an html page: the
+// service links of skill trainers that teach the player's class (l2go-hv9), of
+// warehouse keepers, of gatekeepers and of merchants (their shops and the
+// multisell lists naming them), or the client's stock "nothing to say" page.
+func npcServiceHtml(player *registry.PlayerWorldState, npc *models.NpcInstance) string {
+	char := player.Character
+	if char == nil {
+		return outclient.DefaultNpcHtml
+	}
+	if registry.IsTrainer(npc.TemplateID) &&
+		registry.CanTeach(npc.TemplateID, int(char.Race), int(char.Sex), int(char.ClassID)) {
+		return "<html><body>Skill Trainer<br><br>" +
+			"<a action=\"bypass -h " + BypassLearnSkills + "\">Learn Skills</a>" +
+			"</body></html>"
+	}
+	if IsWarehouseKeeper(npc.Template) {
+		var b strings.Builder
+		b.WriteString("<html><body>Warehouse Keeper<br><br>")
+		b.WriteString("<a action=\"bypass -h " + BypassDepositPrivate + "\">Deposit an item</a><br>")
+		b.WriteString("<a action=\"bypass -h " + BypassWithdrawPrivate + "\">Withdraw an item</a><br>")
+		if char.HasClan() {
+			b.WriteString("<a action=\"bypass -h " + BypassDepositClan + "\">Deposit an item (clan warehouse)</a><br>")
+			b.WriteString("<a action=\"bypass -h " + BypassWithdrawClan + "\">Withdraw an item (clan warehouse)</a><br>")
+		}
+		b.WriteString("</body></html>")
+		return b.String()
+	}
+	if teleports := registry.GetTeleportRegistry().ListsForNpc(npc.TemplateID); len(teleports) > 0 {
+		var b strings.Builder
+		b.WriteString("<html><body>Gatekeeper<br><br>")
+		for i, id := range teleports {
+			label := "Teleport"
+			if len(teleports) > 1 {
+				label += " (" + strconv.Itoa(i+1) + ")"
+			}
+			b.WriteString("<a action=\"bypass -h " + BypassTeleportList + " " + strconv.Itoa(int(id)) + "\">" + label + "</a><br>")
+		}
+		b.WriteString("</body></html>")
+		return b.String()
+	}
+	lists := registry.GetBuyListRegistry().ListsForNpc(npc.TemplateID)
+	exchanges := registry.GetMultisellRegistry().ListsForNpc(npc.TemplateID)
+	if IsMerchant(npc.Template) && len(lists)+len(exchanges) > 0 {
+		var b strings.Builder
+		b.WriteString("<html><body>Merchant<br><br>")
+		for i, id := range lists {
+			label := "Buy/Sell"
+			if len(lists) > 1 {
+				label += " (" + strconv.Itoa(i+1) + ")"
+			}
+			b.WriteString("<a action=\"bypass -h " + BypassShop + " " + strconv.Itoa(int(id)) + "\">" + label + "</a><br>")
+		}
+		for _, id := range exchanges {
+			b.WriteString("<a action=\"bypass -h " + BypassMultisell + " " + strconv.Itoa(int(id)) + "\">Exchange</a><br>")
+		}
+		b.WriteString("</body></html>")
+		return b.String()
+	}
+	return outclient.DefaultNpcHtml
+}
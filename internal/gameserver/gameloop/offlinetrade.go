@@ -0,0 +1,99 @@
+package gameloop
+
+import (
+	"context"
+
+	"github.com/rs/zerolog/log"
+
+	"github.com/VerTox/l2go/internal/gameserver/registry"
+)
+
+// enterOfflineTrade keeps a disconnected store keeper in the world with its
+// store open (L2J OfflineTradeUtil.enteredOfflineMode). Only client-bound state
+// goes: auto-attack, pending approaches, party and trade window. The keeper
+// stays visible, so nobody despawns it, and it releases its region block since
+// it never moves again. Its store is persisted for a restart.
+func (gl *GameLoop) enterOfflineTrade(charID int32) {
+	player, ok := gl.world.GetPlayer(charID)
+	if !ok {
+		gl.handlePlayerDisconnected(CmdPlayerDisconnected{CharID: charID})
+		return
+	}
+	if !player.PrivateStore.Type.Open() {
+		// The store closed after the handler checked it: a normal logout, but
+		// the handler left the player in the world for us to remove.
+		gl.removeDetachedPlayer(player)
+		return
+	}
+
+	gl.stopAttacker(charID)
+	delete(gl.interactPending, charID)
+	delete(gl.castPending, charID)
+	gl.leavePartyOnDisconnect(charID)
+	gl.leaveTradeOnDisconnect(charID)
+	gl.leavePlayerRegions(charID)
+
+	player.OfflineTrade = true
+	gl.saveOfflineStore(player)
+
+	log.Info().
+		Int32("char_id", charID).
+		Int32("store_type", int32(player.PrivateStore.Type)).
+		Msg("player entered offline trade mode")
+}
+
+// handleOfflineTradeRestore reopens a persisted store for a keeper put back
+// into the world at startup. The store goes up before the keeper is spawned to
+// anyone, so the first CharInfo already shows it seated behind the sign.
+func (gl *GameLoop) handleOfflineTradeRestore(cmd CmdOfflineTradeRestore) {
+	player, ok := gl.world.GetPlayer(cmd.CharID)
+	if !ok {
+		return
+	}
+	player.OfflineTrade = true
+
+	st := cmd.Store
+	buy := st.Type == registry.StoreBuy
+	list := st.SellList
+	if buy {
+		list = st.BuyList
+	}
+	if !st.Type.Open() || len(list) == 0 || len(list) > privateStoreSlots(player, buy) || !storeTotalValid(list) {
+		gl.endOfflineTrade(player)
+		return
+	}
+
+	gl.storeSeq++
+	st.Seq = gl.storeSeq
+	player.PrivateStore = st
+	player.Sitting = true
+	gl.reconcilePlayerVisibility(cmd.CharID)
+
+	// The restore may have dropped lines the keeper no longer owns.
+	gl.saveOfflineStore(player)
+}
+
+// saveOfflineStore hands an offline keeper's current store to the store worker
+// for persistence.
+func (gl *GameLoop) saveOfflineStore(player *registry.PlayerWorldState) {
+	gl.enqueueStoreJob(StoreJob{Kind: StoreJobOfflineSave, CharID: player.CharID, Store: snapshotStore(&player.PrivateStore)})
+}
+
+// endOfflineTrade logs an offline store keeper out once its store is gone
+// (sold out, or the keeper died) and forgets the persisted store.
+func (gl *GameLoop) endOfflineTrade(player *registry.PlayerWorldState) {
+	player.OfflineTrade = false
+	gl.removeDetachedPlayer(player)
+	gl.enqueueStoreJob(StoreJob{Kind: StoreJobOfflineEnd, CharID: player.CharID})
+
+	log.Info().Int32("char_id", player.CharID).Msg("offline store keeper left the world")
+}
+
+// removeDetachedPlayer does the logout a handler would do for a player that has
+// no client anymore: full disconnect cleanup, a final save and removal from the
+// world.
+func (gl *GameLoop) removeDetachedPlayer(player *registry.PlayerWorldState) {
+	gl.handlePlayerDisconnected(CmdPlayerDisconnected{CharID: player.CharID})
+	gl.persistPlayer(player)
+	_ = gl.world.RemovePlayer(context.Background(), player.CharID)
+}
@@ -0,0 +1,71 @@
+package gameloop
+
+import (
+	"testing"
+
+	"github.com/VerTox/l2go/internal/gameserver/models"
+	"github.com/VerTox/l2go/internal/gameserver/registry"
+)
+
+func TestOfflineTrade_KeeperStaysUntilSoldOut(t *testing.T) {
+	gl, p := newTestLoopWithPlayer(t)
+	addPlayer(t, gl, 8, "acc2", models.Position{X: 100})
+	sink := openSellStore(t, gl, false)
+
+	gl.handlePlayerDisconnected(CmdPlayerDisconnected{CharID: 7, OfflineTrade: true})
+	if _, ok := gl.world.GetPlayer(7); !ok || !p.OfflineTrade || p.PrivateStore.Type != registry.StoreSell {
+		t.Fatal("the keeper should stay in the world with its store open")
+	}
+	if job := <-sink; job.Kind != StoreJobOfflineSave || job.CharID != 7 || len(job.Store.SellList) != 1 {
+		t.Fatalf("save job = %+v", job)
+	}
+
+	// Buying the keeper out logs it out for good.
+	line := registry.StoreItem{Item: models.CharacterItem{ObjectID: 500}, Count: 2, Price: 100}
+	gl.handlePrivateStoreBuy(CmdPrivateStoreBuy{CharID: 8, StoreCharID: 7, Items: []registry.StoreItem{line}})
+	job := <-sink
+	gl.handlePrivateStoreDealDone(CmdPrivateStoreDealDone{StoreCharID: 7, Seq: job.Seq, Lines: job.Lines, OK: true})
+	if _, ok := gl.world.GetPlayer(7); ok {
+		t.Fatal("a sold-out keeper should leave the world")
+	}
+	if job := <-sink; job.Kind != StoreJobOfflineEnd || job.CharID != 7 {
+		t.Fatalf("end job = %+v", job)
+	}
+}
+
+func TestOfflineTrade_NoStoreMeansLogout(t *testing.T) {
+	gl, _ := newTestLoopWithPlayer(t)
+	gl.handlePlayerDisconnected(CmdPlayerDisconnected{CharID: 7, OfflineTrade: true})
+	if _, ok := gl.world.GetPlayer(7); ok {
+		t.Fatal("a player without an open store should be logged out")
+	}
+}
+
+func TestOfflineTrade_Restore(t *testing.T) {
+	gl, p := newTestLoopWithPlayer(t)
+	sink := make(chan StoreJob, 4)
+	gl.SetStoreSink(sink)
+	sword := models.CharacterItem{ObjectID: 500, OwnerID: 7, ItemID: 1, Count: 1}
+	st := registry.PrivateStore{Type: registry.StoreSell,
+		SellList: []registry.StoreItem{{Item: sword, Count: 1, Price: 100}}}
+
+	gl.handleOfflineTradeRestore(CmdOfflineTradeRestore{CharID: 7, Store: st})
+	if !p.OfflineTrade || !p.Sitting || p.PrivateStore.Type != registry.StoreSell || p.PrivateStore.Seq == 0 {
+		t.Fatalf("restored keeper = %+v", p)
+	}
+	if job := <-sink; job.Kind != StoreJobOfflineSave {
+		t.Fatalf("save job = %+v", job)
+	}
+
+	// A store that cannot reopen is forgotten and its keeper logged out.
+	gl2, _ := newTestLoopWithPlayer(t)
+	gl2.SetStoreSink(sink)
+	st.SellList[0].Price = -1
+	gl2.handleOfflineTradeRestore(CmdOfflineTradeRestore{CharID: 7, Store: st})
+	if _, ok := gl2.world.GetPlayer(7); ok {
+		t.Fatal("an invalid store should not bring its keeper back")
+	}
+	if job := <-sink; job.Kind != StoreJobOfflineEnd {
+		t.Fatalf("end job = %+v", job)
+	}
+}
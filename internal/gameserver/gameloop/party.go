@@ -0,0 +1,632 @@
+package gameloop
+
+import (
+	"math/rand"
+	"strings"
+	"time"
+
+	"github.com/VerTox/l2go/internal/gameserver/models"
+	"github.com/VerTox/l2go/internal/gameserver/packets/outclient"
+	"github.com/VerTox/l2go/internal/gameserver/registry"
+)
+
+// PartyLootType is the party item-distribution mode (L2J PartyDistributionType);
+// the numeric value is the id used on the wire.
+type PartyLootType int32
+
+const (
+	PartyLootFindersKeepers PartyLootType = iota
+	PartyLootRandom
+	PartyLootRandomIncludingSpoil
+	PartyLootByTurn
+	PartyLootByTurnIncludingSpoil
+)
+
+// valid reports whether t is one of the five known modes.
+func (t PartyLootType) valid() bool {
+	return t >= PartyLootFindersKeepers && t <= PartyLootByTurnIncludingSpoil
+}
+
+// sysStringID is the client SysString naming the mode, used as the parameter of
+// the loot-change system messages (L2J PartyDistributionType.getSysStringId).
+func (t PartyLootType) sysStringID() int32 {
+	switch t {
+	case PartyLootRandom:
+		return 488
+	case PartyLootRandomIncludingSpoil:
+		return 798
+	case PartyLootByTurn:
+		return 799
+	case PartyLootByTurnIncludingSpoil:
+		return 800
+	default:
+		return 487
+	}
+}
+
+const (
+	maxPartyMembers = 9
+
+	// partyInviteTimeout is how long an AskJoinParty stays answerable (L2J
+	// L2Request.REQUEST_TIMEOUT); afterwards the inviter gets JoinParty(0).
+	partyInviteTimeout = 15 * time.Second
+
+	// partyLootVoteTimeout cancels a loot-mode vote that not every member answered
+	// (L2J PARTY_DISTRIBUTION_TYPE_REQUEST_TIMEOUT).
+	partyLootVoteTimeout = 15 * time.Second
+
+	// partyRange is how close a member must be to share EXP and loot (L2J
+	// ALT_PARTY_RANGE).
+	partyRange = 1500
+)
+
+// Party is a player group. Loop-owned: every member's charID maps to the same
+// *Party in GameLoop.parties.
+type Party struct {
+	// Members in join order; Members[0] is the leader.
+	Members []int32
+	// LootType is the active item-distribution mode.
+	LootType PartyLootType
+
+	// names keeps each member's name so departure packets can still be built when
+	// the member is already gone from the world (disconnect race).
+	names map[int32]string
+	// lootTurn is the next Members index tried by the by-turn modes.
+	lootTurn int
+	// vote is the pending loot-mode change, nil when none.
+	vote *partyLootVote
+}
+
+// partyLootVote is a leader-initiated loot-mode change awaiting member approval.
+type partyLootVote struct {
+	lootType PartyLootType
+	approved map[int32]struct{}
+	seq      int64
+}
+
+// partyInvite is an outstanding AskJoinParty, keyed by the invitee's charID.
+type partyInvite struct {
+	inviterCharID int32
+	lootType      PartyLootType
+	seq           int64
+}
+
+// partyRemoveReason selects the messages sent when a member leaves the party.
+type partyRemoveReason int
+
+const (
+	partyRemoveLeft partyRemoveReason = iota
+	partyRemoveExpelled
+	partyRemoveDisconnected
+)
+
+// Leader returns the party leader's charID.
+func (p *Party) Leader() int32 { return p.Members[0] }
+
+// IsLeader reports whether charID leads the party.
+func (p *Party) IsLeader(charID int32) bool { return len(p.Members) > 0 && p.Members[0] == charID }
+
+func (p *Party) indexOf(charID int32) int {
+	for i, id := range p.Members {
+		if id == charID {
+			return i
+		}
+	}
+	return -1
+}
+
+// memberByName resolves a member by name, case-insensitively (L2J getPlayerByName).
+func (p *Party) memberByName(name string) (int32, bool) {
+	for _, id := range p.Members {
+		if strings.EqualFold(p.names[id], name) {
+			return id, true
+		}
+	}
+	return 0, false
+}
+
+// partyOf returns the party charID belongs to, or nil.
+func (gl *GameLoop) partyOf(charID int32) *Party {
+	return gl.parties[charID]
+}
+
+// partyInviteOutstanding reports whether charID has sent an invitation that is
+// still awaiting an answer.
+func (gl *GameLoop) partyInviteOutstanding(charID int32) bool {
+	for _, inv := range gl.partyInvites {
+		if inv.inviterCharID == charID {
+			return true
+		}
+	}
+	return false
+}
+
+// handlePartyInvite validates a RequestJoinParty and sends AskJoinParty to the
+// invitee. Mirrors L2J RequestJoinParty: the invitee must be online, not the
+// inviter, not in a party and not busy with another request; an existing party
+// only lets its leader invite and caps at maxPartyMembers.
+func (gl *GameLoop) handlePartyInvite(cmd CmdPartyInvite) {
+	inviter, ok := gl.world.GetPlayer(cmd.CharID)
+	if !ok || inviter.Character == nil {
+		return
+	}
+	target, ok := gl.world.GetPlayerByName(cmd.TargetName)
+	if !ok || target.Character == nil {
+		gl.sendToPlayer(inviter, outclient.BuildSystemMessageNoParams(outclient.SysMsgFirstSelectUserToInvite))
+		return
+	}
+	if target.CharID == inviter.CharID {
+		gl.sendToPlayer(inviter, outclient.BuildSystemMessageNoParams(outclient.SysMsgYouInvitedWrongTarget))
+		return
+	}
+	if gl.partyOf(target.CharID) != nil {
+		gl.sendToPlayer(inviter, outclient.NewSystemMessage(outclient.SysMsgC1IsAlreadyInParty).
+			AddPlayerName(target.Character.Name).Build())
+		return
+	}
+
+	lootType := PartyLootType(cmd.LootType)
+	if p := gl.partyOf(inviter.CharID); p != nil {
+		if !p.IsLeader(inviter.CharID) {
+			gl.sendToPlayer(inviter, outclient.BuildSystemMessageNoParams(outclient.SysMsgOnlyLeaderCanInvite))
+			return
+		}
+		if len(p.Members) >= maxPartyMembers {
+			gl.sendToPlayer(inviter, outclient.BuildSystemMessageNoParams(outclient.SysMsgPartyFull))
+			return
+		}
+		lootType = p.LootType
+	}
+	if !lootType.valid() {
+		lootType = PartyLootFindersKeepers
+	}
+
+	if gl.partyInviteOutstanding(inviter.CharID) {
+		gl.sendToPlayer(inviter, outclient.BuildSystemMessageNoParams(outclient.SysMsgWaitingForAnotherReply))
+		return
+	}
+	if _, pending := gl.partyInvites[target.CharID]; pending || gl.partyInviteOutstanding(target.CharID) {
+		gl.sendToPlayer(inviter, outclient.NewSystemMessage(outclient.SysMsgC1IsBusyTryLater).
+			AddPlayerName(target.Character.Name).Build())
+		return
+	}
+
+	gl.partySeq++
+	gl.partyInvites[target.CharID] = partyInvite{inviterCharID: inviter.CharID, lootType: lootType, seq: gl.partySeq}
+	gl.sendToPlayer(target, outclient.BuildAskJoinParty(inviter.Character.Name, int32(lootType)))
+	gl.sendToPlayer(inviter, outclient.NewSystemMessage(outclient.SysMsgYouInvitedC1ToParty).
+		AddPlayerName(target.Character.Name).Build())
+	gl.events.Schedule(&PartyInviteExpireEvent{
+		At:           time.Now().Add(partyInviteTimeout),
+		TargetCharID: target.CharID,
+		Seq:          gl.partySeq,
+	})
+}
+
+// PartyInviteExpireEvent withdraws an unanswered invitation. Seq guards against a
+// newer invitation to the same player.
+type PartyInviteExpireEvent struct {
+	At           time.Time
+	TargetCharID int32
+	Seq          int64
+}
+
+func (e *PartyInviteExpireEvent) ExecuteAt() time.Time { return e.At }
+
+func (e *PartyInviteExpireEvent) Execute(gl *GameLoop) {
+	inv, ok := gl.partyInvites[e.TargetCharID]
+	if !ok || inv.seq != e.Seq {
+		return
+	}
+	delete(gl.partyInvites, e.TargetCharID)
+	if inviter, ok := gl.world.GetPlayer(inv.inviterCharID); ok {
+		gl.sendToPlayer(inviter, outclient.BuildJoinParty(0))
+	}
+}
+
+// handlePartyAnswer resolves the invitee's reply: relays JoinParty to the inviter
+// and, on accept, joins the inviter's party (forming it if needed).
+func (gl *GameLoop) handlePartyAnswer(cmd CmdPartyAnswer) {
+	inv, ok := gl.partyInvites[cmd.CharID]
+	if !ok {
+		return
+	}
+	delete(gl.partyInvites, cmd.CharID)
+
+	inviter, ok := gl.world.GetPlayer(inv.inviterCharID)
+	if !ok || inviter.Character == nil {
+		return
+	}
+	if !cmd.Accept {
+		gl.sendToPlayer(inviter, outclient.BuildJoinParty(0))
+		return
+	}
+	invitee, ok := gl.world.GetPlayer(cmd.CharID)
+	if !ok || invitee.Character == nil || gl.partyOf(cmd.CharID) != nil {
+		gl.sendToPlayer(inviter, outclient.BuildJoinParty(0))
+		return
+	}
+	gl.sendToPlayer(inviter, outclient.BuildJoinParty(1))
+
+	p := gl.partyOf(inviter.CharID)
+	if p == nil {
+		p = &Party{
+			Members:  []int32{inviter.CharID},
+			LootType: inv.lootType,
+			names:    map[int32]string{inviter.CharID: inviter.Character.Name},
+		}
+		gl.parties[inviter.CharID] = p
+	}
+	if len(p.Members) >= maxPartyMembers {
+		full := outclient.BuildSystemMessageNoParams(outclient.SysMsgPartyFull)
+		gl.sendToPlayer(inviter, full)
+		gl.sendToPlayer(invitee, full)
+		return
+	}
+	gl.addPartyMember(p, invitee)
+}
+
+// addPartyMember joins player to p (L2J L2Party.addPartyMember): the newcomer gets
+// the full window, the others get a new row, and buff icons are exchanged.
+func (gl *GameLoop) addPartyMember(p *Party, player *registry.PlayerWorldState) {
+	if p.vote != nil {
+		gl.finishLootVote(p, false)
+	}
+
+	leaderName := p.names[p.Leader()]
+	gl.sendToPlayer(player, outclient.BuildPartySmallWindowAll(p.Leader(), int32(p.LootType), gl.partyWindowRows(p, player.CharID)))
+	gl.sendToPlayer(player, outclient.NewSystemMessage(outclient.SysMsgYouJoinedS1Party).AddString(leaderName).Build())
+	gl.sendToParty(p, outclient.NewSystemMessage(outclient.SysMsgC1JoinedParty).AddPlayerName(player.Character.Name).Build(), 0)
+	gl.sendToParty(p, outclient.BuildPartySmallWindowAdd(p.Leader(), int32(p.LootType), partyMemberInfo(player)), 0)
+
+	p.Members = append(p.Members, player.CharID)
+	p.names[player.CharID] = player.Character.Name
+	gl.parties[player.CharID] = p
+
+	now := time.Now()
+	joined := outclient.BuildPartySpelled(player.CharID, abnormalBuffs(player, now))
+	for _, id := range p.Members {
+		if id == player.CharID {
+			continue
+		}
+		member, ok := gl.world.GetPlayer(id)
+		if !ok {
+			continue
+		}
+		gl.sendToPlayer(player, outclient.BuildPartySpelled(id, abnormalBuffs(member, now)))
+		gl.sendToPlayer(member, joined)
+	}
+}
+
+// handlePartyLeave processes RequestWithDrawalParty.
+func (gl *GameLoop) handlePartyLeave(cmd CmdPartyLeave) {
+	if p := gl.partyOf(cmd.CharID); p != nil {
+		gl.removePartyMember(p, cmd.CharID, partyRemoveLeft)
+	}
+}
+
+// handlePartyKick processes RequestOustPartyMember: only the leader may expel, and
+// never itself.
+func (gl *GameLoop) handlePartyKick(cmd CmdPartyKick) {
+	p := gl.partyOf(cmd.CharID)
+	if p == nil || !p.IsLeader(cmd.CharID) {
+		return
+	}
+	target, ok := p.memberByName(cmd.TargetName)
+	if !ok || target == cmd.CharID {
+		return
+	}
+	gl.removePartyMember(p, target, partyRemoveExpelled)
+}
+
+// handlePartyChangeLeader processes RequestChangePartyLeader (L2J L2Party.setLeader):
+// the new leader swaps places with the old one and every window is rebuilt.
+func (gl *GameLoop) handlePartyChangeLeader(cmd CmdPartyChangeLeader) {
+	p := gl.partyOf(cmd.CharID)
+	if p == nil || !p.IsLeader(cmd.CharID) {
+		return
+	}
+	target, ok := p.memberByName(cmd.TargetName)
+	if !ok {
+		gl.sendToChar(cmd.CharID, outclient.BuildSystemMessageNoParams(outclient.SysMsgTransferRightsOnlyToPartyMember))
+		return
+	}
+	if target == cmd.CharID {
+		gl.sendToChar(cmd.CharID, outclient.BuildSystemMessageNoParams(outclient.SysMsgCannotTransferRightsToYourself))
+		return
+	}
+	// A pending vote was started by the old leader; the new one restarts it if wanted.
+	if p.vote != nil {
+		gl.finishLootVote(p, false)
+	}
+	idx := p.indexOf(target)
+	p.Members[0], p.Members[idx] = p.Members[idx], p.Members[0]
+	gl.sendToParty(p, outclient.NewSystemMessage(outclient.SysMsgC1HasBecomePartyLeader).AddPlayerName(p.names[target]).Build(), 0)
+	gl.refreshPartyWindows(p)
+}
+
+// removePartyMember takes charID out of p (L2J L2Party.removePartyMember). A party
+// left with a single member disperses; so does one whose leader leaves on purpose,
+// while a disconnecting leader hands the party to the next member. Works from the
+// party's own bookkeeping, so it is safe when the member is already gone from the
+// world (disconnect race).
+func (gl *GameLoop) removePartyMember(p *Party, charID int32, reason partyRemoveReason) {
+	idx := p.indexOf(charID)
+	if idx < 0 {
+		return
+	}
+	wasLeader := idx == 0
+	if len(p.Members) <= 2 || (wasLeader && reason != partyRemoveDisconnected) {
+		gl.disbandParty(p)
+		return
+	}
+	if p.vote != nil {
+		gl.finishLootVote(p, false)
+	}
+
+	name := p.names[charID]
+	p.Members = append(p.Members[:idx], p.Members[idx+1:]...)
+	delete(p.names, charID)
+	delete(gl.parties, charID)
+	if p.lootTurn > idx {
+		p.lootTurn--
+	}
+
+	switch reason {
+	case partyRemoveExpelled:
+		gl.sendToChar(charID, outclient.BuildSystemMessageNoParams(outclient.SysMsgHaveBeenExpelledFromParty))
+		gl.sendToParty(p, outclient.NewSystemMessage(outclient.SysMsgC1WasExpelledFromParty).AddPlayerName(name).Build(), 0)
+	default:
+		gl.sendToChar(charID, outclient.BuildSystemMessageNoParams(outclient.SysMsgYouLeftParty))
+		gl.sendToParty(p, outclient.NewSystemMessage(outclient.SysMsgC1LeftParty).AddPlayerName(name).Build(), 0)
+	}
+	gl.sendToChar(charID, outclient.BuildPartySmallWindowDeleteAll())
+	gl.sendToParty(p, outclient.BuildPartySmallWindowDelete(charID, name), 0)
+
+	if wasLeader {
+		gl.sendToParty(p, outclient.NewSystemMessage(outclient.SysMsgC1HasBecomePartyLeader).AddPlayerName(p.names[p.Leader()]).Build(), 0)
+		gl.refreshPartyWindows(p)
+	}
+}
+
+// disbandParty dissolves p: every member is told the party dispersed and its window
+// is closed.
+func (gl *GameLoop) disbandParty(p *Party) {
+	p.vote = nil
+	dispersed := outclient.BuildSystemMessageNoParams(outclient.SysMsgPartyDispersed)
+	deleteAll := outclient.BuildPartySmallWindowDeleteAll()
+	for _, id := range p.Members {
+		delete(gl.parties, id)
+		gl.sendToChar(id, dispersed)
+		gl.sendToChar(id, deleteAll)
+	}
+	p.Members = nil
+}
+
+// refreshPartyWindows rebuilds every member's party window after a leader change
+// (L2J broadcastToPartyMembersNewLeader).
+func (gl *GameLoop) refreshPartyWindows(p *Party) {
+	for _, id := range p.Members {
+		member, ok := gl.world.GetPlayer(id)
+		if !ok {
+			continue
+		}
+		gl.sendToPlayer(member, outclient.BuildPartySmallWindowDeleteAll())
+		gl.sendToPlayer(member, outclient.BuildPartySmallWindowAll(p.Leader(), int32(p.LootType), gl.partyWindowRows(p, id)))
+	}
+}
+
+// leavePartyOnDisconnect drops a leaving player's pending invitations and removes
+// it from its party. Called from handlePlayerDisconnected.
+func (gl *GameLoop) leavePartyOnDisconnect(charID int32) {
+	delete(gl.partyInvites, charID)
+	for target, inv := range gl.partyInvites {
+		if inv.inviterCharID == charID {
+			delete(gl.partyInvites, target)
+		}
+	}
+	if p := gl.partyOf(charID); p != nil {
+		gl.removePartyMember(p, charID, partyRemoveDisconnected)
+	}
+}
+
+// handlePartyLootChange starts a loot-mode vote (L2J L2Party.requestLootChange):
+// every other member is asked to approve; the leader's vote is implicit.
+func (gl *GameLoop) handlePartyLootChange(cmd CmdPartyLootChange) {
+	p := gl.partyOf(cmd.CharID)
+	lootType := PartyLootType(cmd.LootType)
+	if p == nil || !p.IsLeader(cmd.CharID) || !lootType.valid() || lootType == p.LootType || p.vote != nil {
+		return
+	}
+	gl.partySeq++
+	p.vote = &partyLootVote{lootType: lootType, approved: make(map[int32]struct{}), seq: gl.partySeq}
+	gl.sendToParty(p, outclient.BuildExAskModifyPartyLooting(p.names[cmd.CharID], int32(lootType)), cmd.CharID)
+	gl.sendToChar(cmd.CharID, outclient.NewSystemMessage(outclient.SysMsgRequestingApprovalPartyLootS1).
+		AddSystemString(lootType.sysStringID()).Build())
+	gl.events.Schedule(&PartyLootVoteExpireEvent{
+		At:    time.Now().Add(partyLootVoteTimeout),
+		Party: p,
+		Seq:   gl.partySeq,
+	})
+}
+
+// handlePartyLootAnswer records a member's vote. One refusal cancels the change;
+// approval by every non-leader member applies it.
+func (gl *GameLoop) handlePartyLootAnswer(cmd CmdPartyLootAnswer) {
+	p := gl.partyOf(cmd.CharID)
+	if p == nil || p.vote == nil || p.IsLeader(cmd.CharID) {
+		return
+	}
+	if _, voted := p.vote.approved[cmd.CharID]; voted {
+		return
+	}
+	if !cmd.Accept {
+		gl.finishLootVote(p, false)
+		return
+	}
+	p.vote.approved[cmd.CharID] = struct{}{}
+	if len(p.vote.approved) >= len(p.Members)-1 {
+		gl.finishLootVote(p, true)
+	}
+}
+
+// PartyLootVoteExpireEvent cancels a loot vote still pending after the timeout.
+type PartyLootVoteExpireEvent struct {
+	At    time.Time
+	Party *Party
+	Seq   int64
+}
+
+func (e *PartyLootVoteExpireEvent) ExecuteAt() time.Time { return e.At }
+
+func (e *PartyLootVoteExpireEvent) Execute(gl *GameLoop) {
+	if e.Party.vote == nil || e.Party.vote.seq != e.Seq {
+		return
+	}
+	gl.finishLootVote(e.Party, false)
+}
+
+// finishLootVote closes the pending vote (L2J L2Party.finishLootRequest).
+func (gl *GameLoop) finishLootVote(p *Party, approved bool) {
+	v := p.vote
+	if v == nil {
+		return
+	}
+	p.vote = nil
+	if approved {
+		p.LootType = v.lootType
+		p.lootTurn = 0
+		gl.sendToParty(p, outclient.BuildExSetPartyLooting(1, int32(p.LootType)), 0)
+		gl.sendToParty(p, outclient.NewSystemMessage(outclient.SysMsgPartyLootChangedS1).
+			AddSystemString(p.LootType.sysStringID()).Build(), 0)
+		return
+	}
+	gl.sendToParty(p, outclient.BuildExSetPartyLooting(0, int32(p.LootType)), 0)
+	gl.sendToParty(p, outclient.BuildSystemMessageNoParams(outclient.SysMsgPartyLootChangeCancelled), 0)
+}
+
+// partyMembersInRange returns p's living members within radius of pos — the
+// members eligible to share EXP and loot.
+func (gl *GameLoop) partyMembersInRange(p *Party, pos models.Position, radius int) []*registry.PlayerWorldState {
+	out := make([]*registry.PlayerWorldState, 0, len(p.Members))
+	for _, id := range p.Members {
+		member, ok := gl.world.GetPlayer(id)
+		if !ok || member.Character == nil || member.Character.CurrentHP <= 0 {
+			continue
+		}
+		dx := member.Position.X - pos.X
+		dy := member.Position.Y - pos.Y
+		if dx*dx+dy*dy > radius*radius {
+			continue
+		}
+		out = append(out, member)
+	}
+	return out
+}
+
+// partyLooter picks who receives an item found by finderCharID according to the
+// party's loot mode (L2J L2Party.getActualLooter). spoil marks sweeper loot, which
+// only the *IncludingSpoil modes share. Outside a party the finder keeps it.
+func (gl *GameLoop) partyLooter(finderCharID int32, pos models.Position, spoil bool) int32 {
+	p := gl.partyOf(finderCharID)
+	if p == nil {
+		return finderCharID
+	}
+	switch p.LootType {
+	case PartyLootRandom, PartyLootByTurn:
+		if spoil {
+			return finderCharID
+		}
+	case PartyLootRandomIncludingSpoil, PartyLootByTurnIncludingSpoil:
+	default:
+		return finderCharID
+	}
+
+	eligible := gl.partyMembersInRange(p, pos, partyRange)
+	if len(eligible) == 0 {
+		return finderCharID
+	}
+	if p.LootType == PartyLootRandom || p.LootType == PartyLootRandomIncludingSpoil {
+		return eligible[rand.Intn(len(eligible))].CharID
+	}
+
+	inRange := make(map[int32]struct{}, len(eligible))
+	for _, m := range eligible {
+		inRange[m.CharID] = struct{}{}
+	}
+	for i := range p.Members {
+		idx := (p.lootTurn + i) % len(p.Members)
+		if _, ok := inRange[p.Members[idx]]; ok {
+			p.lootTurn = idx + 1
+			return p.Members[idx]
+		}
+	}
+	return finderCharID
+}
+
+// partyMemberInfo builds a party-window row from a player's live state.
+func partyMemberInfo(player *registry.PlayerWorldState) outclient.PartyMemberInfo {
+	c := player.Character
+	return outclient.PartyMemberInfo{
+		ObjectID: player.CharID,
+		Name:     c.Name,
+		CurCP:    int32(c.CurrentCP),
+		MaxCP:    int32(c.MaxCP),
+		CurHP:    int32(c.CurrentHP),
+		MaxHP:    int32(c.MaxHP),
+		CurMP:    int32(c.CurrentMP),
+		MaxMP:    int32(c.MaxMP),
+		Level:    int32(c.Level),
+		ClassID:  int32(c.ClassID),
+		Race:     int32(c.Race),
+	}
+}
+
+// partyWindowRows lists p's online members except exclude, for PartySmallWindowAll.
+func (gl *GameLoop) partyWindowRows(p *Party, exclude int32) []outclient.PartyMemberInfo {
+	rows := make([]outclient.PartyMemberInfo, 0, len(p.Members))
+	for _, id := range p.Members {
+		if id == exclude {
+			continue
+		}
+		if member, ok := gl.world.GetPlayer(id); ok && member.Character != nil {
+			rows = append(rows, partyMemberInfo(member))
+		}
+	}
+	return rows
+}
+
+// updatePartyStatus refreshes player's row (bars, level) in its party members'
+// windows. Called wherever the player's HP/MP/CP or level change.
+func (gl *GameLoop) updatePartyStatus(player *registry.PlayerWorldState) {
+	p := gl.partyOf(player.CharID)
+	if p == nil || player.Character == nil {
+		return
+	}
+	gl.sendToParty(p, outclient.BuildPartySmallWindowUpdate(partyMemberInfo(player)), player.CharID)
+}
+
+// broadcastPartySpelled mirrors player's buff icons into its party members' windows.
+func (gl *GameLoop) broadcastPartySpelled(player *registry.PlayerWorldState, buffs []outclient.AbnormalBuff) {
+	p := gl.partyOf(player.CharID)
+	if p == nil {
+		return
+	}
+	gl.sendToParty(p, outclient.BuildPartySpelled(player.CharID, buffs), player.CharID)
+}
+
+// sendToParty sends data to every online member of p except exclude (0 = none).
+func (gl *GameLoop) sendToParty(p *Party, data []byte, exclude int32) {
+	for _, id := range p.Members {
+		if id != exclude {
+			gl.sendToChar(id, data)
+		}
+	}
+}
+
+// sendToChar sends data to the player with charID if it is online.
+func (gl *GameLoop) sendToChar(charID int32, data []byte) {
+	if player, ok := gl.world.GetPlayer(charID); ok {
+		gl.sendToPlayer(player, data)
+	}
+}
@@ -0,0 +1,260 @@
+package gameloop
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/VerTox/l2go/internal/gameserver/models"
+	"github.com/VerTox/l2go/internal/gameserver/packets/outclient"
+)
+
+// joinParty invites each member by name on behalf of leader and accepts.
+func joinParty(t *testing.T, gl *GameLoop, leader int32, members ...int32) {
+	t.Helper()
+	for _, id := range members {
+		p, ok := gl.world.GetPlayer(id)
+		if !ok {
+			t.Fatalf("player %d not in world", id)
+		}
+		gl.handlePartyInvite(CmdPartyInvite{CharID: leader, TargetName: p.Character.Name})
+		if _, pending := gl.partyInvites[id]; !pending {
+			t.Fatalf("invite to %d not pending", id)
+		}
+		gl.handlePartyAnswer(CmdPartyAnswer{CharID: id, Accept: true})
+	}
+}
+
+func TestParty_InviteAcceptFormsParty(t *testing.T) {
+	gl, _ := newTestLoopWithPlayer(t)
+	addPlayer(t, gl, 8, "acc2", models.Position{X: 100})
+	conn, rec := newRecordingConn(t)
+	gl.connections.Register("acc2", conn)
+
+	gl.handlePartyInvite(CmdPartyInvite{CharID: 7, TargetName: "ACC2", LootType: int32(PartyLootRandom)})
+	if !eventually(func() bool { return rec.contains(outclient.BuildAskJoinParty("Tester", int32(PartyLootRandom))) }) {
+		t.Fatal("invitee should receive AskJoinParty")
+	}
+	gl.handlePartyAnswer(CmdPartyAnswer{CharID: 8, Accept: true})
+
+	p := gl.partyOf(7)
+	if p == nil || gl.partyOf(8) != p {
+		t.Fatal("both players should share one party")
+	}
+	if !p.IsLeader(7) || len(p.Members) != 2 || p.LootType != PartyLootRandom {
+		t.Fatalf("unexpected party: %+v", p)
+	}
+	leader, _ := gl.world.GetPlayer(7)
+	want := outclient.BuildPartySmallWindowAll(7, int32(PartyLootRandom), []outclient.PartyMemberInfo{partyMemberInfo(leader)})
+	if !eventually(func() bool { return rec.contains(want) }) {
+		t.Error("new member should receive the full party window")
+	}
+}
+
+func TestParty_InviteRejections(t *testing.T) {
+	gl, _ := newTestLoopWithPlayer(t)
+	addPlayer(t, gl, 8, "acc2", models.Position{})
+	addPlayer(t, gl, 9, "acc3", models.Position{})
+
+	// Self-invite and unknown names never create an invitation.
+	gl.handlePartyInvite(CmdPartyInvite{CharID: 7, TargetName: "Tester"})
+	gl.handlePartyInvite(CmdPartyInvite{CharID: 7, TargetName: "nobody"})
+	if len(gl.partyInvites) != 0 {
+		t.Fatalf("invalid invites must be rejected, got %v", gl.partyInvites)
+	}
+
+	// One outstanding invitation per inviter; a busy invitee can't be invited again.
+	gl.handlePartyInvite(CmdPartyInvite{CharID: 7, TargetName: "acc2"})
+	gl.handlePartyInvite(CmdPartyInvite{CharID: 7, TargetName: "acc3"})
+	gl.handlePartyInvite(CmdPartyInvite{CharID: 9, TargetName: "acc2"})
+	if len(gl.partyInvites) != 1 || gl.partyInvites[8].inviterCharID != 7 {
+		t.Fatalf("want only 7->8 pending, got %v", gl.partyInvites)
+	}
+	gl.handlePartyAnswer(CmdPartyAnswer{CharID: 8, Accept: true})
+
+	// Only the leader may invite into an existing party.
+	gl.handlePartyInvite(CmdPartyInvite{CharID: 8, TargetName: "acc3"})
+	if _, pending := gl.partyInvites[9]; pending {
+		t.Fatal("non-leader must not invite")
+	}
+}
+
+func TestParty_InviteExpires(t *testing.T) {
+	gl, _ := newTestLoopWithPlayer(t)
+	addPlayer(t, gl, 8, "acc2", models.Position{})
+
+	gl.handlePartyInvite(CmdPartyInvite{CharID: 7, TargetName: "acc2"})
+	inv := gl.partyInvites[8]
+	(&PartyInviteExpireEvent{At: time.Now(), TargetCharID: 8, Seq: inv.seq}).Execute(gl)
+	if _, pending := gl.partyInvites[8]; pending {
+		t.Fatal("expired invitation should be withdrawn")
+	}
+	gl.handlePartyAnswer(CmdPartyAnswer{CharID: 8, Accept: true})
+	if gl.partyOf(7) != nil || gl.partyOf(8) != nil {
+		t.Fatal("answering an expired invitation must not form a party")
+	}
+}
+
+func TestParty_DeclineLeavesNoParty(t *testing.T) {
+	gl, _ := newTestLoopWithPlayer(t)
+	addPlayer(t, gl, 8, "acc2", models.Position{})
+
+	gl.handlePartyInvite(CmdPartyInvite{CharID: 7, TargetName: "acc2"})
+	gl.handlePartyAnswer(CmdPartyAnswer{CharID: 8, Accept: false})
+	if gl.partyOf(7) != nil || gl.partyOf(8) != nil || len(gl.partyInvites) != 0 {
+		t.Fatal("declined invitation must leave no party behind")
+	}
+}
+
+func TestParty_LeaveKickAndDisband(t *testing.T) {
+	gl, _ := newTestLoopWithPlayer(t)
+	addPlayer(t, gl, 8, "acc2", models.Position{})
+	addPlayer(t, gl, 9, "acc3", models.Position{})
+	addPlayer(t, gl, 10, "acc4", models.Position{})
+	joinParty(t, gl, 7, 8, 9, 10)
+
+	// Only the leader kicks.
+	gl.handlePartyKick(CmdPartyKick{CharID: 8, TargetName: "acc3"})
+	if gl.partyOf(9) == nil {
+		t.Fatal("non-leader kick must be ignored")
+	}
+	gl.handlePartyKick(CmdPartyKick{CharID: 7, TargetName: "acc3"})
+	if gl.partyOf(9) != nil || len(gl.partyOf(7).Members) != 3 {
+		t.Fatal("kicked member should be removed")
+	}
+
+	gl.handlePartyLeave(CmdPartyLeave{CharID: 10})
+	p := gl.partyOf(7)
+	if gl.partyOf(10) != nil || len(p.Members) != 2 {
+		t.Fatal("leaving member should be removed")
+	}
+
+	// Two members left: the next departure disperses the party.
+	gl.handlePartyLeave(CmdPartyLeave{CharID: 8})
+	if gl.partyOf(7) != nil || gl.partyOf(8) != nil || len(gl.parties) != 0 {
+		t.Fatalf("party of two should disband, parties=%v", gl.parties)
+	}
+}
+
+func TestParty_LeaderLeavingDisbands(t *testing.T) {
+	gl, _ := newTestLoopWithPlayer(t)
+	addPlayer(t, gl, 8, "acc2", models.Position{})
+	addPlayer(t, gl, 9, "acc3", models.Position{})
+	joinParty(t, gl, 7, 8, 9)
+
+	gl.handlePartyLeave(CmdPartyLeave{CharID: 7})
+	if len(gl.parties) != 0 {
+		t.Fatal("a leader leaving on purpose disperses the party")
+	}
+}
+
+func TestParty_LeaderDisconnectHandsOver(t *testing.T) {
+	gl, _ := newTestLoopWithPlayer(t)
+	addPlayer(t, gl, 8, "acc2", models.Position{})
+	addPlayer(t, gl, 9, "acc3", models.Position{})
+	joinParty(t, gl, 7, 8, 9)
+
+	// The handler removes the player from the world right after queueing the
+	// disconnect; the loop must cope without the leader's world state.
+	_ = gl.world.RemovePlayer(context.Background(), 7)
+	gl.handlePlayerDisconnected(CmdPlayerDisconnected{CharID: 7})
+
+	p := gl.partyOf(8)
+	if p == nil || !p.IsLeader(8) || len(p.Members) != 2 || gl.partyOf(7) != nil {
+		t.Fatalf("leadership should pass to the next member, got %+v", p)
+	}
+
+	// Two-member party whose leader logs out disbands cleanly.
+	_ = gl.world.RemovePlayer(context.Background(), 8)
+	gl.handlePlayerDisconnected(CmdPlayerDisconnected{CharID: 8})
+	if len(gl.parties) != 0 {
+		t.Fatalf("party should be disbanded, parties=%v", gl.parties)
+	}
+}
+
+func TestParty_DisconnectDropsInvites(t *testing.T) {
+	gl, _ := newTestLoopWithPlayer(t)
+	addPlayer(t, gl, 8, "acc2", models.Position{})
+
+	gl.handlePartyInvite(CmdPartyInvite{CharID: 7, TargetName: "acc2"})
+	gl.handlePlayerDisconnected(CmdPlayerDisconnected{CharID: 7})
+	if len(gl.partyInvites) != 0 {
+		t.Fatal("inviter disconnect should withdraw its invitation")
+	}
+}
+
+func TestParty_ChangeLeader(t *testing.T) {
+	gl, _ := newTestLoopWithPlayer(t)
+	addPlayer(t, gl, 8, "acc2", models.Position{})
+	addPlayer(t, gl, 9, "acc3", models.Position{})
+	joinParty(t, gl, 7, 8, 9)
+
+	gl.handlePartyChangeLeader(CmdPartyChangeLeader{CharID: 8, TargetName: "acc3"})
+	if !gl.partyOf(7).IsLeader(7) {
+		t.Fatal("only the leader may transfer leadership")
+	}
+	gl.handlePartyChangeLeader(CmdPartyChangeLeader{CharID: 7, TargetName: "acc3"})
+	p := gl.partyOf(7)
+	if !p.IsLeader(9) || p.indexOf(7) != 2 {
+		t.Fatalf("leader and new leader should swap places, got %v", p.Members)
+	}
+}
+
+func TestParty_LootVote(t *testing.T) {
+	gl, _ := newTestLoopWithPlayer(t)
+	addPlayer(t, gl, 8, "acc2", models.Position{})
+	addPlayer(t, gl, 9, "acc3", models.Position{})
+	joinParty(t, gl, 7, 8, 9)
+	p := gl.partyOf(7)
+
+	// A single refusal cancels.
+	gl.handlePartyLootChange(CmdPartyLootChange{CharID: 7, LootType: int32(PartyLootByTurn)})
+	gl.handlePartyLootAnswer(CmdPartyLootAnswer{CharID: 8, Accept: false})
+	if p.vote != nil || p.LootType != PartyLootFindersKeepers {
+		t.Fatal("refused vote should be cancelled without changing the mode")
+	}
+
+	// Every non-leader approving applies the change.
+	gl.handlePartyLootChange(CmdPartyLootChange{CharID: 7, LootType: int32(PartyLootByTurn)})
+	gl.handlePartyLootAnswer(CmdPartyLootAnswer{CharID: 8, Accept: true})
+	if p.vote == nil {
+		t.Fatal("vote should wait for every member")
+	}
+	gl.handlePartyLootAnswer(CmdPartyLootAnswer{CharID: 9, Accept: true})
+	if p.vote != nil || p.LootType != PartyLootByTurn {
+		t.Fatalf("approved vote should switch to by-turn, got %v", p.LootType)
+	}
+
+	// An unanswered vote times out.
+	gl.handlePartyLootChange(CmdPartyLootChange{CharID: 7, LootType: int32(PartyLootRandom)})
+	(&PartyLootVoteExpireEvent{At: time.Now(), Party: p, Seq: p.vote.seq}).Execute(gl)
+	if p.vote != nil || p.LootType != PartyLootByTurn {
+		t.Fatal("expired vote should be cancelled")
+	}
+}
+
+func TestParty_LooterByTurnAndSpoil(t *testing.T) {
+	gl, _ := newTestLoopWithPlayer(t)
+	addPlayer(t, gl, 8, "acc2", models.Position{X: 100})
+	addPlayer(t, gl, 9, "acc3", models.Position{X: 50000}) // out of party range
+	joinParty(t, gl, 7, 8, 9)
+	p := gl.partyOf(7)
+	p.LootType = PartyLootByTurn
+
+	got := []int32{
+		gl.partyLooter(7, models.Position{}, false),
+		gl.partyLooter(7, models.Position{}, false),
+		gl.partyLooter(7, models.Position{}, false),
+	}
+	if got[0] != 7 || got[1] != 8 || got[2] != 7 {
+		t.Fatalf("by-turn should rotate over in-range members, got %v", got)
+	}
+	if l := gl.partyLooter(8, models.Position{}, true); l != 8 {
+		t.Fatalf("spoil stays with the finder in by-turn mode, got %d", l)
+	}
+
+	p.LootType = PartyLootFindersKeepers
+	if l := gl.partyLooter(8, models.Position{}, false); l != 8 {
+		t.Fatalf("finders keepers should return the finder, got %d", l)
+	}
+}
@@ -0,0 +1,157 @@
+package gameloop
+
+import (
+	"slices"
+	"time"
+
+	"github.com/VerTox/l2go/internal/gameserver/models"
+	"github.com/VerTox/l2go/internal/gameserver/packets/outclient"
+	"github.com/VerTox/l2go/internal/gameserver/registry"
+)
+
+const (
+	// pathSearchNodes caps the geodata cells one path search may expand; a
+	// goal further than that is taken as unreachable.
+	pathSearchNodes = 4000
+	// pathTickNodes caps the cells all path searches of one tick may expand
+	// together, keeping pathfinding a small share of the 100ms tick. A search
+	// that would go over waits for the next tick.
+	pathTickNodes = 20000
+	// pathCacheTTL is how long a searched path, or the lack of one, is reused
+	// for walks between the same cells.
+	pathCacheTTL = 10 * time.Second
+	// pathCacheSize bounds the path cache.
+	pathCacheSize = 4096
+)
+
+// pathResult is the outcome of findPath.
+type pathResult int
+
+const (
+	pathFound pathResult = iota
+	// pathNone: no walkable way within pathSearchNodes.
+	pathNone
+	// pathDeferred: the tick's node budget is spent; ask again next tick.
+	pathDeferred
+)
+
+// pathKey identifies a searched walk by its end cells: geodata cell and a
+// coarse height, so two floors of a building do not share paths.
+type pathKey struct{ from, to [3]int }
+
+func pathCell(p models.Position) [3]int { return [3]int{p.X >> 4, p.Y >> 4, p.Z >> 6} }
+
+// cachedPath is a search result kept for reuse; path is nil when there was no
+// way.
+type cachedPath struct {
+	path    []models.Position
+	expires time.Time
+}
+
+// findPath returns the waypoints of a walk from from to to, the last being
+// to on the ground: to alone for a straight walk, otherwise a way round the
+// obstacles found with geodata.FindPath. Searched ways come from the cache
+// while fresh. Time spent here is added to pathTime, the tick's pathfinding
+// phase.
+func (gl *GameLoop) findPath(from, to models.Position) ([]models.Position, pathResult) {
+	key := pathKey{pathCell(from), pathCell(to)}
+	if c, ok := gl.pathCache[key]; ok && time.Now().Before(c.expires) {
+		gl.prom.recordPathSearch("cached")
+		if c.path == nil {
+			return nil, pathNone
+		}
+		path := slices.Clone(c.path)
+		last := &path[len(path)-1]
+		last.X, last.Y = to.X, to.Y
+		return path, pathFound
+	}
+
+	budget := min(pathSearchNodes, gl.pathNodesLeft)
+	start := time.Now()
+	path, expanded, ok := gl.geo.FindPath(from, to, budget)
+	gl.pathTime += time.Since(start)
+	gl.pathNodesLeft -= expanded
+	switch {
+	case ok && expanded == 0:
+		return path, pathFound // a straight walk: nothing worth caching
+	case ok:
+		gl.prom.recordPathSearch("found")
+		gl.cachePath(key, path)
+		return path, pathFound
+	case expanded >= budget && budget < pathSearchNodes:
+		gl.prom.recordPathSearch("deferred")
+		return nil, pathDeferred
+	}
+	gl.prom.recordPathSearch("none")
+	gl.cachePath(key, nil)
+	return nil, pathNone
+}
+
+// cachePath stores a search result, making room first by dropping the stale
+// entries, or everything if none are stale.
+func (gl *GameLoop) cachePath(key pathKey, path []models.Position) {
+	now := time.Now()
+	if len(gl.pathCache) >= pathCacheSize {
+		for k, c := range gl.pathCache {
+			if !now.Before(c.expires) {
+				delete(gl.pathCache, k)
+			}
+		}
+		if len(gl.pathCache) >= pathCacheSize {
+			clear(gl.pathCache)
+		}
+	}
+	gl.pathCache[key] = cachedPath{path: path, expires: now.Add(pathCacheTTL)}
+}
+
+// startPlayerPath walks a player round the obstacle its click ran into,
+// server-driven one waypoint at a time (L2J moveToLocation with
+// pathfinding). Without a way round — or with the tick's search budget
+// spent — it walks up to the obstacle instead.
+func (gl *GameLoop) startPlayerPath(player *registry.PlayerWorldState, dest models.Position) {
+	path, res := gl.findPath(player.Position, dest)
+	if res != pathFound {
+		end := gl.geo.MoveCheck(player.Position, dest)
+		gl.broadcastToNearby(player.Position, outclient.NewMoveToLocation(player.CharID,
+			int32(end.X), int32(end.Y), int32(end.Z),
+			int32(player.Position.X), int32(player.Position.Y), int32(player.Position.Z)).Build())
+		return
+	}
+	gl.playerPaths[player.CharID] = path[1:]
+	gl.startPlayerLeg(player, player.Position, path[0], time.Now())
+}
+
+// startPlayerLeg moves a player along one leg of its path and shows the leg
+// to it and the players around.
+func (gl *GameLoop) startPlayerLeg(player *registry.PlayerWorldState, from, to models.Position, at time.Time) {
+	player.IsMoving = true
+	player.MoveStartPos = from
+	player.MoveDestination = to
+	player.MoveStarted = at
+	gl.broadcastToNearby(from, outclient.NewMoveToLocation(player.CharID,
+		int32(to.X), int32(to.Y), int32(to.Z), int32(from.X), int32(from.Y), int32(from.Z)).Build())
+}
+
+// nextPlayerLeg starts the next leg of a player's path once it reaches the
+// waypoint at pos. Reports false when the player reached the end of its path,
+// or was not following one.
+func (gl *GameLoop) nextPlayerLeg(player *registry.PlayerWorldState, pos models.Position, now time.Time) bool {
+	path, ok := gl.playerPaths[player.CharID]
+	if !ok {
+		return false
+	}
+	if len(path) == 0 {
+		delete(gl.playerPaths, player.CharID)
+		return false
+	}
+	gl.playerPaths[player.CharID] = path[1:]
+	gl.startPlayerLeg(player, pos, path[0], now)
+	return true
+}
+
+// followingPath reports whether a player is walking a path the loop found;
+// the loop drives such a walk like an approach.
+func (gl *GameLoop) followingPath(charID int32) bool {
+	_, ok := gl.playerPaths[charID]
+	return ok
+}
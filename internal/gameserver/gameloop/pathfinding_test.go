@@ -0,0 +1,164 @@
+package gameloop
+
+import (
+	"context"
+	"encoding/binary"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/VerTox/l2go/internal/gameserver/geodata"
+	"github.com/VerTox/l2go/internal/gameserver/models"
+)
+
+// loadWallGeodata gives gl geodata region 20_18, whose corner is the world
+// origin: flat ground at 0, but for a wall along x 64..79 from y 0 to 127, and
+// a pit at x 256..383, y 0..127, whose cells cannot be left.
+func loadWallGeodata(t *testing.T, gl *GameLoop) {
+	t.Helper()
+	var data []byte
+	put16 := func(v uint16) { data = binary.LittleEndian.AppendUint16(data, v) }
+	cell := func(height int, nswe uint16) { put16(uint16(height<<1)&0xFFF0 | nswe) }
+	for i := range 256 * 256 {
+		switch i {
+		case 0:
+			data = append(data, 1)
+			for cx := range 8 {
+				for range 8 {
+					switch cx {
+					case 3:
+						cell(0, 0xF&^1) // no way east
+					case 4:
+						cell(200, 0)
+					case 5:
+						cell(0, 0xF&^2) // no way west
+					default:
+						cell(0, 0xF)
+					}
+				}
+			}
+		case 2 * 256:
+			data = append(data, 1)
+			for range 64 {
+				cell(0, 0)
+			}
+		default:
+			data = append(data, 0)
+			put16(0)
+		}
+	}
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "20_18.l2j"), data, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	gl.geo = geodata.NewEngine()
+	if err := gl.geo.LoadFromDirectory(dir); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestFindPath_StraightWithoutGeodata(t *testing.T) {
+	gl, _ := newTestLoopWithPlayer(t)
+	gl.geo = geodata.NewEngine()
+	to := models.Position{X: 900, Y: 40}
+	path, res := gl.findPath(models.Position{X: 8, Y: 40}, to)
+	if res != pathFound || len(path) != 1 || path[0] != to {
+		t.Fatalf("path %+v (%v), want straight to %+v", path, res, to)
+	}
+	if len(gl.pathCache) != 0 {
+		t.Fatal("a straight walk must not be cached")
+	}
+}
+
+func TestFindPath_BudgetAndCache(t *testing.T) {
+	gl, _ := newTestLoopWithPlayer(t)
+	loadWallGeodata(t, gl)
+	from, to := models.Position{X: 8, Y: 40}, models.Position{X: 120, Y: 40}
+
+	gl.pathNodesLeft = 0
+	if _, res := gl.findPath(from, to); res != pathDeferred {
+		t.Fatalf("with the tick's budget spent: %v, want deferred", res)
+	}
+
+	gl.pathNodesLeft = pathTickNodes
+	path, res := gl.findPath(from, to)
+	if res != pathFound || len(path) < 2 || path[len(path)-1] != to {
+		t.Fatalf("round the wall: path %+v (%v)", path, res)
+	}
+	spent := pathTickNodes - gl.pathNodesLeft
+	if spent <= 0 {
+		t.Fatal("the search spent none of the tick's budget")
+	}
+
+	// A walk between the same cells reuses the path, to its exact end.
+	near := models.Position{X: 122, Y: 42}
+	cached, res := gl.findPath(from, near)
+	if res != pathFound || gl.pathNodesLeft != pathTickNodes-spent || cached[len(cached)-1] != near {
+		t.Fatalf("cached path %+v (%v), %d nodes left", cached, res, gl.pathNodesLeft)
+	}
+	if path[len(path)-1] != to {
+		t.Fatal("reusing a cached path changed the cache")
+	}
+}
+
+func TestPlayerPath_WalksRoundTheWall(t *testing.T) {
+	gl, player := newTestLoopWithPlayer(t)
+	loadWallGeodata(t, gl)
+	start := models.Position{X: 8, Y: 40}
+	_ = gl.world.UpdatePlayerPosition(context.Background(), 7, start, 0)
+	dest := models.Position{X: 120, Y: 40}
+
+	gl.handleMoveToLocation(CmdMoveToLocation{CharID: 7, Dest: dest, FindPath: true})
+	if !gl.followingPath(7) || !player.IsMoving || player.MoveDestination == dest {
+		t.Fatalf("player heads for %+v, want the first waypoint round the wall", player.MoveDestination)
+	}
+
+	now := time.Now()
+	for range 20 {
+		if !gl.followingPath(7) {
+			break
+		}
+		now = now.Add(time.Minute)
+		gl.advancePlayerMovement(now)
+	}
+	if gl.followingPath(7) || player.IsMoving || player.Position != dest {
+		t.Fatalf("player at %+v (moving %v), want stopped at %+v", player.Position, player.IsMoving, dest)
+	}
+}
+
+func TestNPCChase_RunsRoundTheWall(t *testing.T) {
+	gl, _ := newTestLoopWithPlayer(t)
+	loadWallGeodata(t, gl)
+	npc := addAggressiveNPC(gl, 1000, models.Position{X: 8, Y: 40})
+
+	if !gl.moveNPCToPawn(npc, 7, models.Position{X: 200, Y: 40}, 40) {
+		t.Fatal("no way round the wall")
+	}
+	mv := gl.npcMoves[npc.ObjectID]
+	if mv == nil || len(mv.Path) == 0 {
+		t.Fatalf("chase move %+v, want a path of several legs", mv)
+	}
+	goal := mv.goal()
+	gl.advanceNPCMovement(time.Now().Add(time.Minute))
+	if gl.isNPCMoving(npc.ObjectID) || npc.Position != goal {
+		t.Fatalf("NPC at %+v, want at the end of its path %+v", npc.Position, goal)
+	}
+}
+
+func TestReturnHome_NoWayTeleports(t *testing.T) {
+	gl, _ := newTestLoopWithPlayer(t)
+	loadWallGeodata(t, gl)
+	home := models.Position{X: 1000, Y: 40}
+	npc := addAggressiveNPC(gl, 1000, home)
+	gl.world.UpdateNPCPosition(npc.ObjectID, models.Position{X: 300, Y: 40}, 0)
+	npc.CurrentHP = 30
+
+	if gl.moveNPCToPawn(npc, 7, models.Position{X: 600, Y: 40}, 40) {
+		t.Fatal("chased out of a pit that cannot be left")
+	}
+	gl.npcLeaveCombat(npc)
+	if gl.isNPCMoving(npc.ObjectID) || npc.Position != home || npc.CurrentHP != npc.Template.HP {
+		t.Fatalf("NPC at %+v with %v HP, want put home and healed", npc.Position, npc.CurrentHP)
+	}
+}
@@ -0,0 +1,595 @@
+package gameloop
+
+import (
+	"unicode/utf8"
+
+	"github.com/rs/zerolog/log"
+
+	"github.com/VerTox/l2go/internal/gameserver/models"
+	"github.com/VerTox/l2go/internal/gameserver/packets/outclient"
+	"github.com/VerTox/l2go/internal/gameserver/registry"
+	"github.com/VerTox/l2go/internal/gameserver/usecase"
+)
+
+const (
+	// storeInteractRange is how close a visitor must stand to browse or deal
+	// with a private store (L2J INTERACTION_DISTANCE).
+	storeInteractRange = 150
+
+	// maxStoreMsgLen is the longest store title the client can send (L2J
+	// SetPrivateStoreMsgSell.MAX_MSG_LENGTH).
+	maxStoreMsgLen = 29
+)
+
+// StoreJobKind selects what the store worker does with a StoreJob.
+type StoreJobKind int
+
+const (
+	// StoreJobManage sends the owner its setup window (PrivateStoreManageListSell
+	// or PrivateStoreManageListBuy, by Store.Type) with its current inventory.
+	StoreJobManage StoreJobKind = iota
+	// StoreJobBrowse sends a visitor the store window (PrivateStoreListSell or
+	// PrivateStoreListBuy) for Store.
+	StoreJobBrowse
+	// StoreJobDeal settles a purchase in one DB transaction and reports back to
+	// the loop with CmdPrivateStoreDealDone.
+	StoreJobDeal
+	// StoreJobOfflineSave persists the Store of an offline store keeper so it
+	// reopens after a restart.
+	StoreJobOfflineSave
+	// StoreJobOfflineEnd forgets the persisted store of a keeper that left.
+	StoreJobOfflineEnd
+)
+
+// StoreJob is private store work that needs the database and therefore runs
+// off the loop on the store worker. Store is a snapshot: the loop keeps
+// editing the live lists meanwhile.
+type StoreJob struct {
+	Kind        StoreJobKind
+	CharID      int32
+	StoreCharID int32
+	Store       registry.PrivateStore
+
+	// Deal: Items[i] moves from SellerID to BuyerID and settles Lines[i], the
+	// store line reserved for it; Total adena goes the other way.
+	SellerID   int32
+	BuyerID    int32
+	SellerName string
+	BuyerName  string
+	Items      []usecase.ItemTransfer
+	Lines      []registry.StoreItem
+	Total      int64
+	Seq        int64
+}
+
+// SetStoreSink wires the channel that receives private store work needing the
+// database (setup and browse windows, deals). Kept out of New() like the other
+// optional sinks; without it stores open but nothing can be bought.
+func (gl *GameLoop) SetStoreSink(sink chan<- StoreJob) {
+	gl.storeSink = sink
+}
+
+// enqueueStoreJob hands work to the store worker. Non-blocking: with no sink or
+// a full queue it reports false.
+func (gl *GameLoop) enqueueStoreJob(job StoreJob) bool {
+	if gl.storeSink == nil {
+		return false
+	}
+	select {
+	case gl.storeSink <- job:
+		return true
+	default:
+		log.Warn().Int32("char_id", job.CharID).Int32("store_char_id", job.StoreCharID).Msg("store sink full, dropping store job")
+		return false
+	}
+}
+
+// snapshotStore copies a store so a job can read it while the loop edits the
+// live lists.
+func snapshotStore(st *registry.PrivateStore) registry.PrivateStore {
+	cp := *st
+	cp.SellList = append([]registry.StoreItem(nil), st.SellList...)
+	cp.BuyList = append([]registry.StoreItem(nil), st.BuyList...)
+	return cp
+}
+
+// privateStoreSlots is how many lines a store may hold (L2J defaults for
+// MAX_PVTSTORESELL_SLOTS / MAX_PVTSTOREBUY_SLOTS: dwarves get one more).
+func privateStoreSlots(player *registry.PlayerWorldState, buy bool) int {
+	n := 3
+	if buy {
+		n = 4
+	}
+	if player.Character != nil && models.CharacterRace(player.Character.Race) == models.RaceDwarf {
+		n++
+	}
+	return n
+}
+
+// storeMsgPacket is the title packet of an open store, or nil when the player
+// has no store open. Sent with every spawn so the title survives re-spawns.
+func storeMsgPacket(player *registry.PlayerWorldState) []byte {
+	st := &player.PrivateStore
+	switch st.Type {
+	case registry.StoreSell:
+		return outclient.BuildPrivateStoreMsgSell(player.CharID, st.SellMsg)
+	case registry.StorePackageSell:
+		return outclient.BuildExPrivateStoreSetWholeMsg(player.CharID, st.SellMsg)
+	case registry.StoreBuy:
+		return outclient.BuildPrivateStoreMsgBuy(player.CharID, st.BuyMsg)
+	}
+	return nil
+}
+
+// broadcastStoreSign refreshes how the player looks after a store or sitting
+// change: UserInfo to itself, CharInfo plus the store title to everyone who has
+// it spawned.
+func (gl *GameLoop) broadcastStoreSign(player *registry.PlayerWorldState) {
+	gl.sendUserInfo(player)
+	charInfo := buildPlayerCharInfo(player)
+	msg := storeMsgPacket(player)
+	for _, other := range gl.world.GetPlayersInRange(player.Position, registry.VisibilityForgetRadius) {
+		if other.CharID == player.CharID || !other.KnownPlayers[player.CharID] {
+			continue
+		}
+		gl.sendToPlayer(other, charInfo)
+		if msg != nil {
+			gl.sendToPlayer(other, msg)
+		}
+	}
+	if msg != nil {
+		gl.sendToPlayer(player, msg)
+	}
+}
+
+// setSitting sits the player down or stands it up and shows it to everyone
+// around (ChangeWaitType).
+func (gl *GameLoop) setSitting(player *registry.PlayerWorldState, sit bool) {
+	if player.Sitting == sit {
+		return
+	}
+	player.Sitting = sit
+	waitType := outclient.WaitTypeStanding
+	if sit {
+		waitType = outclient.WaitTypeSitting
+	}
+	gl.broadcastToNearby(player.Position, outclient.BuildChangeWaitType(player.CharID, waitType, player.Position))
+}
+
+// handleSitStand toggles sitting. A store owner must close the store to stand
+// up, and nobody sits down mid-swing or mid-cast.
+func (gl *GameLoop) handleSitStand(cmd CmdSitStand) {
+	player, ok := gl.world.GetPlayer(cmd.CharID)
+	if !ok || player.Character == nil || player.Character.CurrentHP <= 0 {
+		return
+	}
+	if player.PrivateStore.Type != registry.StoreNone || player.Casting != nil {
+		gl.sendToPlayer(player, outclient.BuildActionFailed())
+		return
+	}
+	if cs, ok := gl.combatState[cmd.CharID]; ok && cs.IsAutoAttacking {
+		gl.sendToPlayer(player, outclient.BuildActionFailed())
+		return
+	}
+	gl.setSitting(player, !player.Sitting)
+}
+
+// handlePrivateStoreManage mirrors L2J tryOpenPrivateSellStore /
+// tryOpenPrivateBuyStore: asking for the side that is open (or being set up)
+// closes it; a player with no store then stands up and gets the setup window.
+// Asking for the other side while a store runs does nothing.
+func (gl *GameLoop) handlePrivateStoreManage(cmd CmdPrivateStoreManage) {
+	player, ok := gl.world.GetPlayer(cmd.CharID)
+	if !ok || player.Character == nil || player.Character.CurrentHP <= 0 {
+		return
+	}
+	if gl.trades[cmd.CharID] != nil {
+		gl.sendToPlayer(player, outclient.BuildSystemMessageNoParams(outclient.SysMsgAlreadyTrading))
+		return
+	}
+	st := &player.PrivateStore
+	wasOpen := st.Type.Open()
+	switch {
+	case !cmd.Buy && (st.Type.Selling() || st.Type == registry.StoreSellManage):
+		st.Type = registry.StoreNone
+	case cmd.Buy && (st.Type == registry.StoreBuy || st.Type == registry.StoreBuyManage):
+		st.Type = registry.StoreNone
+	}
+	if st.Type != registry.StoreNone {
+		gl.sendToPlayer(player, outclient.BuildActionFailed())
+		return
+	}
+	gl.setSitting(player, false)
+	if wasOpen {
+		gl.broadcastStoreSign(player)
+	}
+
+	if cmd.Buy {
+		st.Type = registry.StoreBuyManage
+	} else {
+		st.Type = registry.StoreSellManage
+		st.Package = cmd.Package
+	}
+	if !gl.enqueueStoreJob(StoreJob{Kind: StoreJobManage, CharID: cmd.CharID, Store: snapshotStore(st)}) {
+		st.Type = registry.StoreNone
+	}
+}
+
+// handlePrivateStoreSetSell opens the sell store (L2J SetPrivateStoreListSell):
+// the list must fit the slot limit and its total the adena cap; the owner then
+// sits down behind the sign.
+func (gl *GameLoop) handlePrivateStoreSetSell(cmd CmdPrivateStoreSetSell) {
+	player, ok := gl.world.GetPlayer(cmd.CharID)
+	if !ok || player.Character == nil || player.Character.CurrentHP <= 0 {
+		return
+	}
+	st := &player.PrivateStore
+	if st.Type != registry.StoreSellManage {
+		return
+	}
+	if len(cmd.Items) == 0 {
+		gl.closePrivateStore(player)
+		return
+	}
+	if len(cmd.Items) > privateStoreSlots(player, false) || !storeTotalValid(cmd.Items) {
+		gl.sendToPlayer(player, outclient.BuildSystemMessageNoParams(outclient.SysMsgExceededQuantityForInput))
+		st.Package = cmd.Package
+		gl.enqueueStoreJob(StoreJob{Kind: StoreJobManage, CharID: cmd.CharID, Store: snapshotStore(st)})
+		return
+	}
+
+	st.SellList = cmd.Items
+	st.Package = cmd.Package
+	st.Type = registry.StoreSell
+	if cmd.Package {
+		st.Type = registry.StorePackageSell
+	}
+	gl.openPrivateStore(player)
+}
+
+// handlePrivateStoreSetBuy opens the buy store (L2J SetPrivateStoreListBuy).
+func (gl *GameLoop) handlePrivateStoreSetBuy(cmd CmdPrivateStoreSetBuy) {
+	player, ok := gl.world.GetPlayer(cmd.CharID)
+	if !ok || player.Character == nil || player.Character.CurrentHP <= 0 {
+		return
+	}
+	st := &player.PrivateStore
+	if st.Type != registry.StoreBuyManage {
+		return
+	}
+	if len(cmd.Items) == 0 {
+		gl.closePrivateStore(player)
+		return
+	}
+	if len(cmd.Items) > privateStoreSlots(player, true) || !storeTotalValid(cmd.Items) {
+		gl.sendToPlayer(player, outclient.BuildSystemMessageNoParams(outclient.SysMsgExceededQuantityForInput))
+		gl.enqueueStoreJob(StoreJob{Kind: StoreJobManage, CharID: cmd.CharID, Store: snapshotStore(st)})
+		return
+	}
+
+	st.BuyList = cmd.Items
+	st.Type = registry.StoreBuy
+	gl.openPrivateStore(player)
+}
+
+// storeTotalValid reports whether every line is positive and the whole list
+// is worth no more than MaxAdena, so no price arithmetic can overflow later.
+func storeTotalValid(items []registry.StoreItem) bool {
+	var total int64
+	for _, it := range items {
+		if it.Count <= 0 || it.Price < 0 {
+			return false
+		}
+		if it.Price > 0 && it.Count > (usecase.MaxAdena-total)/it.Price {
+			return false
+		}
+		total += it.Count * it.Price
+	}
+	return true
+}
+
+// openPrivateStore stamps a freshly opened store, sits the owner down and shows
+// the sign to everyone around.
+func (gl *GameLoop) openPrivateStore(player *registry.PlayerWorldState) {
+	gl.storeSeq++
+	player.PrivateStore.Seq = gl.storeSeq
+	gl.setSitting(player, true)
+	gl.broadcastStoreSign(player)
+}
+
+// closePrivateStore takes the store down and stands the owner up. The lists
+// are kept for the next setup window.
+func (gl *GameLoop) closePrivateStore(player *registry.PlayerWorldState) {
+	player.PrivateStore.Type = registry.StoreNone
+	gl.setSitting(player, false)
+	gl.broadcastStoreSign(player)
+}
+
+// handlePrivateStoreQuit closes the player's store or abandons its setup.
+func (gl *GameLoop) handlePrivateStoreQuit(cmd CmdPrivateStoreQuit) {
+	player, ok := gl.world.GetPlayer(cmd.CharID)
+	if !ok || player.PrivateStore.Type == registry.StoreNone {
+		return
+	}
+	gl.closePrivateStore(player)
+}
+
+// handlePrivateStoreMsg sets a store title. An open store shows the new title
+// to everyone around right away.
+func (gl *GameLoop) handlePrivateStoreMsg(cmd CmdPrivateStoreMsg) {
+	player, ok := gl.world.GetPlayer(cmd.CharID)
+	if !ok || utf8.RuneCountInString(cmd.Message) > maxStoreMsgLen {
+		return
+	}
+	st := &player.PrivateStore
+	if cmd.Kind == StoreMsgBuy {
+		st.BuyMsg = cmd.Message
+	} else {
+		st.SellMsg = cmd.Message
+	}
+	msg := storeMsgPacket(player)
+	if msg == nil {
+		return
+	}
+	gl.sendToPlayer(player, msg)
+	for _, other := range gl.world.GetPlayersInRange(player.Position, registry.VisibilityForgetRadius) {
+		if other.CharID != player.CharID && other.KnownPlayers[player.CharID] {
+			gl.sendToPlayer(other, msg)
+		}
+	}
+}
+
+// browsePrivateStore opens owner's store window for visitor, walking the
+// visitor up to the store first when it is too far (click again on arrival).
+func (gl *GameLoop) browsePrivateStore(visitor, owner *registry.PlayerWorldState) {
+	if distanceBetween(visitor.Position, owner.Position) > storeInteractRange {
+		gl.startMoveToTargetPos(visitor, owner.CharID, owner.Position, storeInteractRange)
+		return
+	}
+	gl.enqueueStoreJob(StoreJob{
+		Kind:        StoreJobBrowse,
+		CharID:      visitor.CharID,
+		StoreCharID: owner.CharID,
+		Store:       snapshotStore(&owner.PrivateStore),
+	})
+}
+
+// storeVisit resolves the visitor and store owner of a purchase request: both
+// online and alive, not the same player, not trading, and within reach.
+func (gl *GameLoop) storeVisit(charID, storeCharID int32) (visitor, owner *registry.PlayerWorldState, ok bool) {
+	visitor, ok = gl.world.GetPlayer(charID)
+	if !ok || visitor.Character == nil || visitor.Character.CurrentHP <= 0 || gl.trades[charID] != nil {
+		return nil, nil, false
+	}
+	owner, ok = gl.world.GetPlayer(storeCharID)
+	if !ok || owner.Character == nil || owner.CharID == charID {
+		return nil, nil, false
+	}
+	if distanceBetween(visitor.Position, owner.Position) > storeInteractRange {
+		gl.sendToPlayer(visitor, outclient.BuildSystemMessageNoParams(outclient.SysMsgTargetTooFar))
+		return nil, nil, false
+	}
+	return visitor, owner, true
+}
+
+// handlePrivateStoreBuy validates a purchase from a sell store against the live
+// list (L2J TradeList.privateStoreBuy): every line must be listed at the price
+// the buyer saw, and a package store sells only everything at once. The bought
+// units are reserved off the list before the deal goes to the store worker, so
+// two buyers can never pay for the same units.
+func (gl *GameLoop) handlePrivateStoreBuy(cmd CmdPrivateStoreBuy) {
+	buyer, owner, ok := gl.storeVisit(cmd.CharID, cmd.StoreCharID)
+	if !ok {
+		return
+	}
+	st := &owner.PrivateStore
+	if !st.Type.Selling() || len(cmd.Items) == 0 {
+		gl.sendToPlayer(buyer, outclient.BuildActionFailed())
+		return
+	}
+
+	want := make(map[int32]int64, len(cmd.Items))
+	for _, req := range cmd.Items {
+		want[req.Item.ObjectID] += req.Count
+	}
+	if st.Type == registry.StorePackageSell && len(want) != len(st.SellList) {
+		gl.sendToPlayer(buyer, outclient.BuildActionFailed())
+		return
+	}
+	var (
+		items []usecase.ItemTransfer
+		lines []registry.StoreItem
+		total int64
+	)
+	for _, line := range st.SellList {
+		count, ok := want[line.Item.ObjectID]
+		if !ok {
+			continue
+		}
+		if count <= 0 || count > line.Count || (st.Type == registry.StorePackageSell && count != line.Count) ||
+			!priceMatches(cmd.Items, line) {
+			gl.sendToPlayer(buyer, outclient.BuildActionFailed())
+			return
+		}
+		reserved := line
+		reserved.Count = count
+		items = append(items, usecase.ItemTransfer{ObjectID: line.Item.ObjectID, Count: count})
+		lines = append(lines, reserved)
+		total += count * line.Price
+		delete(want, line.Item.ObjectID)
+	}
+	if len(want) != 0 {
+		gl.sendToPlayer(buyer, outclient.BuildActionFailed())
+		return
+	}
+
+	st.SellList = reserveStoreLines(st.SellList, lines, false)
+	gl.startStoreDeal(StoreJob{
+		CharID:     buyer.CharID,
+		SellerID:   owner.CharID,
+		BuyerID:    buyer.CharID,
+		SellerName: owner.Character.Name,
+		BuyerName:  buyer.Character.Name,
+		Items:      items,
+		Lines:      lines,
+		Total:      total,
+	}, owner, false)
+}
+
+// priceMatches reports whether every request line for the listed stack asks
+// the listed price.
+func priceMatches(reqs []registry.StoreItem, line registry.StoreItem) bool {
+	for _, req := range reqs {
+		if req.Item.ObjectID == line.Item.ObjectID && req.Price != line.Price {
+			return false
+		}
+	}
+	return true
+}
+
+// handlePrivateStoreSell validates a sale into a buy store (L2J
+// TradeList.privateStoreSell): each offered stack must match a wanted item and
+// enchant level at the listed price, within the count still wanted.
+func (gl *GameLoop) handlePrivateStoreSell(cmd CmdPrivateStoreSell) {
+	seller, owner, ok := gl.storeVisit(cmd.CharID, cmd.StoreCharID)
+	if !ok {
+		return
+	}
+	st := &owner.PrivateStore
+	if st.Type != registry.StoreBuy || len(cmd.Items) == 0 {
+		gl.sendToPlayer(seller, outclient.BuildActionFailed())
+		return
+	}
+
+	var (
+		items []usecase.ItemTransfer
+		lines []registry.StoreItem
+		total int64
+	)
+	remaining := append([]registry.StoreItem(nil), st.BuyList...)
+	seen := make(map[int32]bool, len(cmd.Items))
+	for _, offer := range cmd.Items {
+		i := findBuyLine(remaining, offer.Item)
+		if i < 0 || seen[offer.Item.ObjectID] || offer.Count <= 0 || offer.Count > remaining[i].Count ||
+			offer.Price != remaining[i].Price {
+			gl.sendToPlayer(seller, outclient.BuildActionFailed())
+			return
+		}
+		seen[offer.Item.ObjectID] = true
+		remaining[i].Count -= offer.Count
+		reserved := remaining[i]
+		reserved.Count = offer.Count
+		items = append(items, usecase.ItemTransfer{ObjectID: offer.Item.ObjectID, Count: offer.Count})
+		lines = append(lines, reserved)
+		total += offer.Count * offer.Price
+	}
+
+	st.BuyList = reserveStoreLines(st.BuyList, lines, true)
+	gl.startStoreDeal(StoreJob{
+		CharID:     seller.CharID,
+		SellerID:   seller.CharID,
+		BuyerID:    owner.CharID,
+		SellerName: seller.Character.Name,
+		BuyerName:  owner.Character.Name,
+		Items:      items,
+		Lines:      lines,
+		Total:      total,
+	}, owner, true)
+}
+
+// findBuyLine returns the index of the buy line wanting item, or -1.
+func findBuyLine(list []registry.StoreItem, item models.CharacterItem) int {
+	for i, line := range list {
+		if line.Item.ItemID == item.ItemID && line.Item.EnchantLevel == item.EnchantLevel {
+			return i
+		}
+	}
+	return -1
+}
+
+// sameStoreLine reports whether two lines are the same store entry: the same
+// stack on a sell list, the same wanted item on a buy list.
+func sameStoreLine(a, b registry.StoreItem, buyList bool) bool {
+	if buyList {
+		return a.Item.ItemID == b.Item.ItemID && a.Item.EnchantLevel == b.Item.EnchantLevel
+	}
+	return a.Item.ObjectID == b.Item.ObjectID
+}
+
+// reserveStoreLines takes the reserved counts off a store list, dropping lines
+// that run out.
+func reserveStoreLines(list, reserved []registry.StoreItem, buyList bool) []registry.StoreItem {
+	out := list[:0]
+	for _, line := range list {
+		for _, r := range reserved {
+			if sameStoreLine(line, r, buyList) {
+				line.Count -= r.Count
+			}
+		}
+		if line.Count > 0 {
+			out = append(out, line)
+		}
+	}
+	return out
+}
+
+// restoreStoreLines puts the counts of a failed deal back on a store list.
+func restoreStoreLines(list, reserved []registry.StoreItem, buyList bool) []registry.StoreItem {
+	for _, r := range reserved {
+		found := false
+		for i := range list {
+			if sameStoreLine(list[i], r, buyList) {
+				list[i].Count += r.Count
+				found = true
+				break
+			}
+		}
+		if !found {
+			list = append(list, r)
+		}
+	}
+	return list
+}
+
+// startStoreDeal hands a reserved deal to the store worker, returning the
+// reservation at once when the worker cannot take it.
+func (gl *GameLoop) startStoreDeal(job StoreJob, owner *registry.PlayerWorldState, buyList bool) {
+	job.Kind = StoreJobDeal
+	job.StoreCharID = owner.CharID
+	job.Seq = owner.PrivateStore.Seq
+	if !gl.enqueueStoreJob(job) {
+		gl.handlePrivateStoreDealDone(CmdPrivateStoreDealDone{
+			StoreCharID: owner.CharID, Seq: job.Seq, BuyList: buyList, Lines: job.Lines,
+		})
+		gl.sendToChar(job.CharID, outclient.BuildActionFailed())
+	}
+}
+
+// handlePrivateStoreDealDone applies a deal's outcome to the store it was
+// reserved from, unless the store has since been reopened (the lists were
+// replaced) or its owner left. A store emptied by a successful deal closes.
+func (gl *GameLoop) handlePrivateStoreDealDone(cmd CmdPrivateStoreDealDone) {
+	owner, ok := gl.world.GetPlayer(cmd.StoreCharID)
+	if !ok || owner.PrivateStore.Seq != cmd.Seq {
+		return
+	}
+	st := &owner.PrivateStore
+	switch {
+	case !cmd.OK && cmd.BuyList:
+		st.BuyList = restoreStoreLines(st.BuyList, cmd.Lines, true)
+	case !cmd.OK:
+		st.SellList = restoreStoreLines(st.SellList, cmd.Lines, false)
+	case (st.Type.Selling() && len(st.SellList) == 0) || (st.Type == registry.StoreBuy && len(st.BuyList) == 0):
+		gl.closePrivateStore(owner)
+	}
+
+	// An offline keeper's persisted lists follow every deal; once its store
+	// is sold out the keeper leaves the world.
+	if owner.OfflineTrade {
+		if st.Type.Open() {
+			gl.saveOfflineStore(owner)
+		} else {
+			gl.endOfflineTrade(owner)
+		}
+	}
+}
@@ -0,0 +1,116 @@
+package gameloop
+
+import (
+	"testing"
+
+	"github.com/VerTox/l2go/internal/gameserver/models"
+	"github.com/VerTox/l2go/internal/gameserver/packets/outclient"
+	"github.com/VerTox/l2go/internal/gameserver/registry"
+)
+
+// openSellStore puts char 7 behind a sell store listing two swords at 100
+// adena each and returns the sink the loop hands store work to.
+func openSellStore(t *testing.T, gl *GameLoop, pkg bool) chan StoreJob {
+	t.Helper()
+	sink := make(chan StoreJob, 4)
+	gl.SetStoreSink(sink)
+	gl.handlePrivateStoreManage(CmdPrivateStoreManage{CharID: 7, Package: pkg})
+	if job := <-sink; job.Kind != StoreJobManage || job.Store.Type != registry.StoreSellManage {
+		t.Fatalf("manage job = %+v", job)
+	}
+	sword := models.CharacterItem{ObjectID: 500, OwnerID: 7, ItemID: 1, Count: 2}
+	gl.handlePrivateStoreSetSell(CmdPrivateStoreSetSell{CharID: 7, Package: pkg,
+		Items: []registry.StoreItem{{Item: sword, Count: 2, Price: 100}}})
+	return sink
+}
+
+func TestPrivateStore_OpenSitsAndShowsSign(t *testing.T) {
+	gl, p := newTestLoopWithPlayer(t)
+	addPlayer(t, gl, 8, "acc2", models.Position{X: 100})
+	conn, rec := newRecordingConn(t)
+	gl.connections.Register("acc2", conn)
+	gl.reconcilePlayerVisibility(7)
+
+	gl.handlePrivateStoreMsg(CmdPrivateStoreMsg{CharID: 7, Kind: StoreMsgSell, Message: "cheap"})
+	openSellStore(t, gl, false)
+	if p.PrivateStore.Type != registry.StoreSell || !p.Sitting {
+		t.Fatalf("store = %v sitting = %v, want open and seated", p.PrivateStore.Type, p.Sitting)
+	}
+	if !eventually(func() bool { return rec.contains(outclient.BuildPrivateStoreMsgSell(7, "cheap")) }) {
+		t.Fatal("a player nearby should see the store title")
+	}
+
+	// The owner cannot stand up while the store is open.
+	gl.handleSitStand(CmdSitStand{CharID: 7})
+	if !p.Sitting {
+		t.Fatal("sit/stand must be refused behind an open store")
+	}
+	gl.handlePrivateStoreQuit(CmdPrivateStoreQuit{CharID: 7})
+	if p.PrivateStore.Type != registry.StoreNone || p.Sitting {
+		t.Fatal("quit should close the store and stand the owner up")
+	}
+	gl.handleSitStand(CmdSitStand{CharID: 7})
+	if !p.Sitting {
+		t.Fatal("sit/stand should work again once the store closed")
+	}
+}
+
+func TestPrivateStore_BuyReservesAndSettles(t *testing.T) {
+	gl, p := newTestLoopWithPlayer(t)
+	addPlayer(t, gl, 8, "acc2", models.Position{X: 100})
+	sink := openSellStore(t, gl, false)
+	line := registry.StoreItem{Item: models.CharacterItem{ObjectID: 500}, Count: 1, Price: 100}
+
+	// A price the buyer did not see is refused outright.
+	bad := line
+	bad.Price = 50
+	gl.handlePrivateStoreBuy(CmdPrivateStoreBuy{CharID: 8, StoreCharID: 7, Items: []registry.StoreItem{bad}})
+	if len(sink) != 0 {
+		t.Fatal("a mismatched price must not start a deal")
+	}
+
+	gl.handlePrivateStoreBuy(CmdPrivateStoreBuy{CharID: 8, StoreCharID: 7, Items: []registry.StoreItem{line}})
+	job := <-sink
+	if job.Kind != StoreJobDeal || job.SellerID != 7 || job.BuyerID != 8 || job.Total != 100 ||
+		len(job.Items) != 1 || job.Items[0].Count != 1 {
+		t.Fatalf("deal job = %+v", job)
+	}
+	if got := p.PrivateStore.SellList[0].Count; got != 1 {
+		t.Fatalf("listed count during the deal = %d, want 1 reserved away", got)
+	}
+
+	// A failed deal puts the reserved unit back.
+	gl.handlePrivateStoreDealDone(CmdPrivateStoreDealDone{StoreCharID: 7, Seq: job.Seq, Lines: job.Lines})
+	if got := p.PrivateStore.SellList[0].Count; got != 2 {
+		t.Fatalf("listed count after failure = %d, want 2", got)
+	}
+
+	// Buying everything empties and closes the store once the deal commits.
+	line.Count = 2
+	gl.handlePrivateStoreBuy(CmdPrivateStoreBuy{CharID: 8, StoreCharID: 7, Items: []registry.StoreItem{line}})
+	job = <-sink
+	gl.handlePrivateStoreDealDone(CmdPrivateStoreDealDone{StoreCharID: 7, Seq: job.Seq, Lines: job.Lines, OK: true})
+	if p.PrivateStore.Type != registry.StoreNone {
+		t.Fatal("an emptied store should close")
+	}
+}
+
+func TestPrivateStore_PackageMustBeBoughtWhole(t *testing.T) {
+	gl, p := newTestLoopWithPlayer(t)
+	addPlayer(t, gl, 8, "acc2", models.Position{X: 100})
+	sink := openSellStore(t, gl, true)
+	if p.PrivateStore.Type != registry.StorePackageSell {
+		t.Fatalf("store = %v, want package sale", p.PrivateStore.Type)
+	}
+
+	line := registry.StoreItem{Item: models.CharacterItem{ObjectID: 500}, Count: 1, Price: 100}
+	gl.handlePrivateStoreBuy(CmdPrivateStoreBuy{CharID: 8, StoreCharID: 7, Items: []registry.StoreItem{line}})
+	if len(sink) != 0 || p.PrivateStore.SellList[0].Count != 2 {
+		t.Fatal("a partial package purchase must be refused")
+	}
+	line.Count = 2
+	gl.handlePrivateStoreBuy(CmdPrivateStoreBuy{CharID: 8, StoreCharID: 7, Items: []registry.StoreItem{line}})
+	if job := <-sink; job.Total != 200 {
+		t.Fatalf("package total = %d, want 200", job.Total)
+	}
+}
@@ -11,10 +11,11 @@ import (
 // PvPVsNormalTime default). Purple name + auto-attackable for this window.
 const pvpFlagDuration = 120 * time.Second
 
-// canAttackPlayer implements L2J Skill.checkPvpSkill (без зон): атаковать другого
-// игрока атакующим действием можно, только если цель уже флагнута, является PK
-// (karma>0), либо атака форсирована Ctrl. flagAttacker=true, если атакующий должен
-// получить PvP-флаг (Ctrl-force по чистой цели). Caller делает проверки self/dead.
+// canAttackPlayer implements L2J Skill.checkPvpSkill (без зон — их добавляет
+// checkPvPAttack): атаковать другого игрока атакующим действием можно, только если
+// цель уже флагнута, является PK (karma>0), либо атака форсирована Ctrl.
+// flagAttacker=true, если атакующий должен получить PvP-флаг (Ctrl-force по чистой
+// цели). Caller делает проверки self/dead.
 func canAttackPlayer(target *registry.PlayerWorldState, ctrl bool, now time.Time) (allowed bool, flagAttacker bool) {
 	if target == nil || target.Character == nil {
 		return false, false
@@ -28,6 +29,37 @@ func canAttackPlayer(target *registry.PlayerWorldState, ctrl bool, now time.Time
 	return false, false
 }
 
+// checkPvPAttack is the gate for an offensive action of one player on another:
+// canAttackPlayer with the zones applied (L2J isInsidePeaceZone and
+// L2PcInstance.isAutoAttackable). Nobody fights in or into a peace zone; two
+// players inside a PvP arena always may, without flagging. refusal is the
+// system message to show when the action is not allowed.
+func (gl *GameLoop) checkPvPAttack(attacker, target *registry.PlayerWorldState, ctrl bool, now time.Time) (allowed, flagAttacker bool, refusal int32) {
+	if code := gl.peaceZoneRefusal(attacker.CharID, target.CharID); code != 0 {
+		return false, false, code
+	}
+	if gl.inPvPZone(attacker.CharID) && gl.inPvPZone(target.CharID) {
+		return true, false, 0
+	}
+	allowed, flagAttacker = canAttackPlayer(target, ctrl, now)
+	if !allowed {
+		return false, false, outclient.SysMsgIncorrectTarget
+	}
+	return true, flagAttacker, 0
+}
+
+// peaceZoneRefusal returns the system message refusing a PvP action when the
+// target or the attacker stands in a peace zone, or 0 when neither does.
+func (gl *GameLoop) peaceZoneRefusal(attackerID, targetID int32) int32 {
+	switch {
+	case gl.inPeaceZone(targetID):
+		return outclient.SysMsgTargetInPeaceZone
+	case gl.inPeaceZone(attackerID):
+		return outclient.SysMsgCantAtkPeaceZone
+	}
+	return 0
+}
+
 // broadcastRelation tells nearby players (and the player itself) how to render
 // this player: purple + auto-attackable while PvP-flagged or carrying karma.
 func (gl *GameLoop) broadcastRelation(player *registry.PlayerWorldState) {
@@ -55,9 +87,10 @@ func (gl *GameLoop) broadcastRelation(player *registry.PlayerWorldState) {
 }
 
 // setPvPFlag (re)arms the player's PvP flag. On a fresh flag it broadcasts the
-// relation change (purple name) and refreshes the player's own UserInfo.
+// relation change (purple name) and refreshes the player's own UserInfo. Fights
+// inside a PvP arena flag nobody (L2J updatePvPStatus).
 func (gl *GameLoop) setPvPFlag(player *registry.PlayerWorldState) {
-	if player == nil || player.Character == nil {
+	if player == nil || player.Character == nil || gl.inPvPZone(player.CharID) {
 		return
 	}
 	was := player.IsPvPFlagged(time.Now())
@@ -102,10 +135,14 @@ func (gl *GameLoop) expirePvPFlags() {
 // restart block covers "can't relog while fighting"), HP reduction, StatusUpdate
 // to the victim and its targeters, and death on lethal. Mirrors NPCHitEvent's
 // player-damage path (npc_combat.go). Shared with future melee PvP (l2go-npi).
+// A blow landing after either party stepped into a peace zone does nothing.
 func (gl *GameLoop) dealDamageToPlayer(target *registry.PlayerWorldState, attackerCharID int32, damage int) {
 	if target == nil || target.Character == nil || target.Character.CurrentHP <= 0 {
 		return
 	}
+	if gl.peaceZoneRefusal(attackerCharID, target.CharID) != 0 {
+		return
+	}
 
 	// Both attacker and victim enter combat stance (L2J: stance on real hit).
 	gl.enterCombatStance(attackerCharID)
@@ -124,6 +161,7 @@ func (gl *GameLoop) dealDamageToPlayer(target *registry.PlayerWorldState, attack
 		_ = conn.Send(su)
 	}
 	gl.broadcastToTargeters(target.CharID, su)
+	gl.updatePartyStatus(target)
 
 	if target.Character.CurrentHP <= 0 {
 		gl.handlePlayerDeath(target.CharID, target)
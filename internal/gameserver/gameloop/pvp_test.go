@@ -5,6 +5,7 @@ import (
 	"time"
 
 	"github.com/VerTox/l2go/internal/gameserver/models"
+	"github.com/VerTox/l2go/internal/gameserver/packets/outclient"
 	"github.com/VerTox/l2go/internal/gameserver/registry"
 )
 
@@ -35,3 +36,59 @@ func TestCanAttackPlayer(t *testing.T) {
 		t.Fatalf("PK target: want allowed, no flag, got %v %v", allowed, flag)
 	}
 }
+
+// addPvPZones gives the loop a town around the origin and an arena to the east.
+func addPvPZones(gl *GameLoop) {
+	gl.zoneRegistry = registry.NewZoneRegistry()
+	gl.zoneRegistry.Add(&models.Zone{Name: "town", Type: models.ZoneTown,
+		Shape: models.ZoneCylinder{Radius: 1000, MinZ: -100, MaxZ: 100}})
+	gl.zoneRegistry.Add(&models.Zone{Name: "arena", Type: models.ZoneArena,
+		Shape: models.ZoneCuboid{MinX: 5000, MinY: -500, MaxX: 6000, MaxY: 500, MinZ: -100, MaxZ: 100}})
+}
+
+func TestCheckPvPAttack_Zones(t *testing.T) {
+	gl, p := newTestLoopWithPlayer(t)
+	addPvPZones(gl)
+	addPlayer(t, gl, 8, "acc2", models.Position{X: 3000})
+	other, _ := gl.world.GetPlayer(8)
+	now := time.Now()
+	place := func(charID int32, x int) {
+		gl.handlePlayerMoved(CmdPlayerMoved{CharID: charID, Position: models.Position{X: x}})
+	}
+
+	// Attacker in town: even a Ctrl attack on a PK outside is refused.
+	place(7, 0)
+	place(8, 3000)
+	other.Character.Karma = 100
+	if allowed, _, refusal := gl.checkPvPAttack(p, other, true, now); allowed || refusal != outclient.SysMsgCantAtkPeaceZone {
+		t.Errorf("attack out of town: allowed=%v refusal=%d", allowed, refusal)
+	}
+	// Target in town.
+	if allowed, _, refusal := gl.checkPvPAttack(other, p, true, now); allowed || refusal != outclient.SysMsgTargetInPeaceZone {
+		t.Errorf("attack into town: allowed=%v refusal=%d", allowed, refusal)
+	}
+	// Blows landing in town do nothing.
+	gl.dealDamageToPlayer(p, 8, 50)
+	if p.Character.CurrentHP != 100 {
+		t.Errorf("damage landed in town: HP=%v", p.Character.CurrentHP)
+	}
+
+	// Both in the arena: free to fight, nobody flags.
+	other.Character.Karma = 0
+	place(7, 5200)
+	place(8, 5400)
+	allowed, flag, _ := gl.checkPvPAttack(p, other, false, now)
+	if !allowed || flag {
+		t.Errorf("arena attack: allowed=%v flag=%v", allowed, flag)
+	}
+	gl.setPvPFlag(other)
+	if other.IsPvPFlagged(now) {
+		t.Error("a fighter in the arena was flagged")
+	}
+
+	// Outside every zone the flag rules apply again.
+	place(7, 3000)
+	if allowed, _, refusal := gl.checkPvPAttack(p, other, false, now); allowed || refusal != outclient.SysMsgIncorrectTarget {
+		t.Errorf("open ground attack on a clean target: allowed=%v refusal=%d", allowed, refusal)
+	}
+}
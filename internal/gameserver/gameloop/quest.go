@@ -0,0 +1,490 @@
+package gameloop
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/rs/zerolog/log"
+
+	"github.com/VerTox/l2go/internal/gameserver/models"
+	"github.com/VerTox/l2go/internal/gameserver/packets/outclient"
+	"github.com/VerTox/l2go/internal/gameserver/registry"
+	"github.com/VerTox/l2go/internal/gameserver/usecase"
+)
+
+// Quest variables the engine keeps; the others belong to the script.
+const (
+	questVarState  = "state"
+	questVarCond   = "cond"
+	questStarted   = "started"
+	questCompleted = "completed"
+)
+
+// Quest sounds scripts play at the usual moments (client ItemSound files).
+const (
+	SoundAccept  = "ItemSound.quest_accept"
+	SoundMiddle  = "ItemSound.quest_middle"
+	SoundItemGet = "ItemSound.quest_itemget"
+	SoundFinish  = "ItemSound.quest_finish"
+	SoundGiveUp  = "ItemSound.quest_giveup"
+)
+
+// noQuestHtml is what an NPC says when none of its quests is open to the
+// player (L2J Quest.getNoQuestMsg).
+const noQuestHtml = "<html><body>You are either not on a quest that involves this NPC, or you don't meet this NPC's minimum quest requirements.</body></html>"
+
+// AlreadyCompletedHtml is what a quest NPC says about a one-time quest the
+// player finished (L2J Quest.getAlreadyCompletedMsg).
+const AlreadyCompletedHtml = "<html><body>This quest has already been completed.</body></html>"
+
+// Quest is a quest script registered in Go (L2J's Quest). Its NPCs route
+// events to it: StartNpcs offer it, TalkNpcs take part once it is started and
+// killing a KillNpcs monster raises OnKill for the killer. A hook returns the
+// html to show: a page file name, looked up under "quests/<Name>/" in the
+// html datapack, a whole "<html>" page, or "" for none. Unset hooks are
+// skipped. Hooks run on the loop goroutine.
+type Quest struct {
+	ID int32
+	// Name keys the quest's saved state and its html directory
+	// (e.g. "Q00001_LettersOfLove").
+	Name string
+	// Title is shown when an NPC offers several quests.
+	Title     string
+	StartNpcs []int32
+	TalkNpcs  []int32
+	KillNpcs  []int32
+	// Items are the quest's own items, taken back when the quest ends or is
+	// aborted.
+	Items []int32
+	// Repeatable quests forget their state when they end, one-time quests
+	// remember they were completed.
+	Repeatable bool
+
+	OnTalk         func(qs *QuestState, npc *models.NpcInstance) string
+	OnEvent        func(qs *QuestState, npc *models.NpcInstance, event string) string
+	OnKill         func(qs *QuestState, npc *models.NpcInstance) string
+	OnItemPickup   func(qs *QuestState, itemID int32, count int64) string
+	OnQuestionMark func(qs *QuestState, mark int32) string
+}
+
+func init() { RegisterBypass(BypassQuest, bypassQuest) }
+
+// Registered quests, filled from init() before the loop starts.
+var (
+	quests       = make(map[string]*Quest)
+	questsByID   = make(map[int32]*Quest)
+	questsByKill = make(map[int32][]*Quest)
+)
+
+// RegisterQuest adds a quest script. Panics on a duplicate name or id, like
+// RegisterBypass. Call it from init().
+func RegisterQuest(q *Quest) {
+	if q.Name == "" || q.ID <= 0 {
+		panic(fmt.Sprintf("quest needs a name and an id: %q %d", q.Name, q.ID))
+	}
+	if _, exists := quests[q.Name]; exists {
+		panic(fmt.Sprintf("duplicate quest: %q", q.Name))
+	}
+	if _, exists := questsByID[q.ID]; exists {
+		panic(fmt.Sprintf("duplicate quest id: %d", q.ID))
+	}
+	quests[q.Name] = q
+	questsByID[q.ID] = q
+	for _, id := range q.KillNpcs {
+		questsByKill[id] = append(questsByKill[id], q)
+	}
+}
+
+// QuestJob saves a quest step that changed the player's quest: its
+// variables, nil when the quest is forgotten, and the items it took and
+// handed out. It needs the database and therefore runs off the loop on the
+// quest worker, in order.
+type QuestJob struct {
+	CharID int32
+	Quest  string
+	Vars   map[string]string
+	Take   []usecase.QuestItem
+	Give   []usecase.QuestItem
+}
+
+// SetQuestSink wires the channel that receives quest steps. Kept out of New()
+// like the other optional sinks; without it quests run but nothing is saved
+// and no items change hands.
+func (gl *GameLoop) SetQuestSink(sink chan<- QuestJob) {
+	gl.questSink = sink
+}
+
+// enqueueQuestJob hands a quest step to the quest worker. Non-blocking:
+// reports false when the queue is full and the job was dropped. With no sink
+// there is nothing to save and the step goes ahead.
+func (gl *GameLoop) enqueueQuestJob(job QuestJob) bool {
+	if gl.questSink == nil {
+		return true
+	}
+	select {
+	case gl.questSink <- job:
+		return true
+	default:
+		log.Warn().Int32("char_id", job.CharID).Str("quest", job.Quest).Msg("quest sink full, dropping quest job")
+		return false
+	}
+}
+
+// QuestState is a script's handle on one player's quest while a hook runs
+// (L2J QuestState). Changes are applied when the hook returns.
+type QuestState struct {
+	Player *registry.PlayerWorldState
+	Quest  *Quest
+
+	gl          *GameLoop
+	vars        map[string]string
+	changed     bool
+	listChanged bool
+	take, give  []usecase.QuestItem
+	exp, sp     int64
+}
+
+// questState opens q's state for player on a copy of its saved variables.
+func (gl *GameLoop) questState(player *registry.PlayerWorldState, q *Quest) *QuestState {
+	return &QuestState{Player: player, Quest: q, gl: gl, vars: maps.Clone(player.Quests[q.Name])}
+}
+
+// Get returns a quest variable, "" when unset.
+func (qs *QuestState) Get(name string) string { return qs.vars[name] }
+
+// GetInt returns a numeric quest variable, 0 when unset.
+func (qs *QuestState) GetInt(name string) int {
+	n, _ := strconv.Atoi(qs.vars[name])
+	return n
+}
+
+// Set stores a quest variable; an empty value removes it.
+func (qs *QuestState) Set(name, value string) {
+	if qs.vars == nil {
+		qs.vars = make(map[string]string)
+	}
+	if value == "" {
+		delete(qs.vars, name)
+	} else {
+		qs.vars[name] = value
+	}
+	qs.changed = true
+}
+
+// SetInt stores a numeric quest variable.
+func (qs *QuestState) SetInt(name string, value int) { qs.Set(name, strconv.Itoa(value)) }
+
+// IsStarted reports whether the quest is under way.
+func (qs *QuestState) IsStarted() bool { return qs.vars[questVarState] == questStarted }
+
+// IsCompleted reports whether a one-time quest was finished.
+func (qs *QuestState) IsCompleted() bool { return qs.vars[questVarState] == questCompleted }
+
+// Cond is the quest step the journal shows.
+func (qs *QuestState) Cond() int { return qs.GetInt(questVarCond) }
+
+// SetCond moves the quest to step cond.
+func (qs *QuestState) SetCond(cond int) {
+	qs.SetInt(questVarCond, cond)
+	qs.listChanged = true
+}
+
+// StartQuest starts the quest at step 1.
+func (qs *QuestState) StartQuest() {
+	qs.vars = map[string]string{questVarState: questStarted, questVarCond: "1"}
+	qs.changed, qs.listChanged = true, true
+	qs.PlaySound(SoundAccept)
+}
+
+// ExitQuest ends the quest: its items are taken back and a one-time quest is
+// remembered as completed, a repeatable one forgotten.
+func (qs *QuestState) ExitQuest() {
+	qs.takeQuestItems()
+	qs.vars = nil
+	if !qs.Quest.Repeatable {
+		qs.vars = map[string]string{questVarState: questCompleted}
+	}
+	qs.changed, qs.listChanged = true, true
+	qs.PlaySound(SoundFinish)
+}
+
+// takeQuestItems takes back every quest item the player carries.
+func (qs *QuestState) takeQuestItems() {
+	for _, id := range qs.Quest.Items {
+		qs.TakeItems(id, 0)
+	}
+}
+
+// GiveItems hands the player count units of itemID.
+func (qs *QuestState) GiveItems(itemID int32, count int64) {
+	qs.give = append(qs.give, usecase.QuestItem{ItemID: itemID, Count: count})
+}
+
+// TakeItems takes count units of itemID, or every unit with count 0.
+func (qs *QuestState) TakeItems(itemID int32, count int64) {
+	qs.take = append(qs.take, usecase.QuestItem{ItemID: itemID, Count: count})
+}
+
+// RewardExpSp grants experience and skill points once the step is saved.
+func (qs *QuestState) RewardExpSp(exp, sp int64) {
+	qs.exp += exp
+	qs.sp += sp
+}
+
+// PlaySound plays a sound for the player.
+func (qs *QuestState) PlaySound(file string) {
+	qs.gl.sendToPlayer(qs.Player, outclient.BuildPlaySound(file))
+}
+
+// ShowQuestionMark shows the player a question mark; clicking it raises the
+// quest's OnQuestionMark with the same mark.
+func (qs *QuestState) ShowQuestionMark(mark int32) {
+	qs.gl.sendToPlayer(qs.Player, outclient.BuildTutorialShowQuestionMark(mark))
+}
+
+// finishQuestStep applies what a hook did to the player's quest: hands the
+// step to the quest worker, keeps the new variables, pays the rewards,
+// refreshes the journal and shows the hook's html on behalf of npc (nil
+// outside dialogues). A step the worker cannot take is refused as a whole, so
+// the loop never runs ahead of what will be saved.
+func (gl *GameLoop) finishQuestStep(qs *QuestState, npc *models.NpcInstance, html string) {
+	player := qs.Player
+	if qs.changed || len(qs.take) > 0 || len(qs.give) > 0 {
+		job := QuestJob{CharID: player.CharID, Quest: qs.Quest.Name, Take: qs.take, Give: qs.give}
+		if qs.vars != nil {
+			job.Vars = maps.Clone(qs.vars)
+		}
+		if !gl.enqueueQuestJob(job) {
+			gl.sendToPlayer(player, outclient.BuildActionFailed())
+			return
+		}
+		if qs.vars == nil {
+			delete(player.Quests, qs.Quest.Name)
+		} else {
+			if player.Quests == nil {
+				player.Quests = make(map[string]map[string]string)
+			}
+			player.Quests[qs.Quest.Name] = qs.vars
+		}
+	}
+	if qs.exp > 0 || qs.sp > 0 {
+		gl.grantExpSp(player, qs.exp, qs.sp)
+	}
+	if qs.listChanged {
+		gl.sendToPlayer(player, QuestListPacket(player))
+	}
+	if html == "" {
+		return
+	}
+	if !strings.HasPrefix(html, "<html") {
+		page, ok := registry.GetHtmlRegistry().Get("quests/" + qs.Quest.Name + "/" + html)
+		if !ok {
+			log.Warn().Str("quest", qs.Quest.Name).Str("page", html).Msg("quest html page not found")
+			gl.sendToPlayer(player, outclient.BuildActionFailed())
+			return
+		}
+		html = page
+	}
+	var objID int32
+	if npc != nil {
+		objID = npc.ObjectID
+		html = renderNpcHtml(html, player, npc)
+	}
+	gl.sendToPlayer(player, outclient.BuildNpcHtmlMessage(objID, html))
+}
+
+// QuestListPacket builds the player's quest journal: the started quests by
+// id and the finished one-time ones.
+func QuestListPacket(player *registry.PlayerWorldState) []byte {
+	var entries []outclient.QuestListEntry
+	var completed []int32
+	for name, vars := range player.Quests {
+		q, ok := quests[name]
+		if !ok {
+			continue
+		}
+		switch vars[questVarState] {
+		case questStarted:
+			cond, _ := strconv.Atoi(vars[questVarCond])
+			entries = append(entries, outclient.QuestListEntry{ID: q.ID, Cond: int32(cond)})
+		case questCompleted:
+			completed = append(completed, q.ID)
+		}
+	}
+	sort.Slice(entries, func(i, j int) bool { return entries[i].ID < entries[j].ID })
+	return outclient.BuildQuestList(entries, completed)
+}
+
+// talkQuests are the quests npc talks about with player, by id: those it
+// starts and the started ones it takes part in.
+func talkQuests(player *registry.PlayerWorldState, npcID int32) []*Quest {
+	var out []*Quest
+	for _, q := range quests {
+		started := player.Quests[q.Name][questVarState] == questStarted
+		if slices.Contains(q.StartNpcs, npcID) || (started && slices.Contains(q.TalkNpcs, npcID)) {
+			out = append(out, q)
+		}
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].ID < out[j].ID })
+	return out
+}
+
+// HasQuests reports whether npc takes part in any quest.
+func HasQuests(npc *models.NpcInstance) bool {
+	for _, q := range quests {
+		if slices.Contains(q.StartNpcs, npc.TemplateID) || slices.Contains(q.TalkNpcs, npc.TemplateID) {
+			return true
+		}
+	}
+	return false
+}
+
+// bypassQuest runs the "Quest" dialogue command (L2J): with no argument the
+// NPC talks about its only open quest or lists them, "Quest <name>" talks
+// about that one and "Quest <name> <event>" raises a quest page's event.
+func bypassQuest(gl *GameLoop, player *registry.PlayerWorldState, npcObjID int32, arg string) {
+	npc, ok := gl.dialogNpc(player, npcObjID)
+	if !ok {
+		return
+	}
+	name, event, _ := strings.Cut(arg, " ")
+	event = strings.TrimSpace(event)
+	if name == "" {
+		open := talkQuests(player, npc.TemplateID)
+		switch len(open) {
+		case 0:
+			gl.sendToPlayer(player, outclient.BuildNpcHtmlMessage(npc.ObjectID, noQuestHtml))
+		case 1:
+			gl.questTalk(player, npc, open[0])
+		default:
+			var b strings.Builder
+			b.WriteString("<html><body>")
+			for _, q := range open {
+				title := q.Title
+				if title == "" {
+					title = q.Name
+				}
+				b.WriteString("<a action=\"bypass -h npc_" + strconv.Itoa(int(npc.ObjectID)) + "_" + BypassQuest + " " + q.Name + "\">" + title + "</a><br>")
+			}
+			b.WriteString("</body></html>")
+			gl.sendToPlayer(player, outclient.BuildNpcHtmlMessage(npc.ObjectID, b.String()))
+		}
+		return
+	}
+	q, ok := quests[name]
+	if !ok {
+		log.Debug().Str("quest", name).Msg("unknown quest in bypass")
+		return
+	}
+	if event == "" {
+		if slices.Contains(talkQuests(player, npc.TemplateID), q) {
+			gl.questTalk(player, npc, q)
+		}
+		return
+	}
+	if q.OnEvent == nil || !(slices.Contains(q.StartNpcs, npc.TemplateID) || slices.Contains(q.TalkNpcs, npc.TemplateID)) {
+		return
+	}
+	qs := gl.questState(player, q)
+	gl.finishQuestStep(qs, npc, q.OnEvent(qs, npc, event))
+}
+
+// questTalk raises q's OnTalk for the player talking to npc.
+func (gl *GameLoop) questTalk(player *registry.PlayerWorldState, npc *models.NpcInstance, q *Quest) {
+	if q.OnTalk == nil {
+		return
+	}
+	qs := gl.questState(player, q)
+	gl.finishQuestStep(qs, npc, q.OnTalk(qs, npc))
+}
+
+// notifyQuestKill raises OnKill of the killer's started quests that hunt npc.
+func (gl *GameLoop) notifyQuestKill(killerID int32, npc *models.NpcInstance) {
+	hunting := questsByKill[npc.TemplateID]
+	if len(hunting) == 0 {
+		return
+	}
+	player, ok := gl.world.GetPlayer(killerID)
+	if !ok || player.Character == nil {
+		return
+	}
+	for _, q := range hunting {
+		if q.OnKill == nil || player.Quests[q.Name][questVarState] != questStarted {
+			continue
+		}
+		qs := gl.questState(player, q)
+		gl.finishQuestStep(qs, nil, q.OnKill(qs, npc))
+	}
+}
+
+// notifyQuestItemPickup raises OnItemPickup of the player's started quests
+// when it picks count units of itemID up from the ground.
+func (gl *GameLoop) notifyQuestItemPickup(player *registry.PlayerWorldState, itemID int32, count int64) {
+	for _, q := range startedQuests(player) {
+		if q.OnItemPickup == nil {
+			continue
+		}
+		qs := gl.questState(player, q)
+		gl.finishQuestStep(qs, nil, q.OnItemPickup(qs, itemID, count))
+	}
+}
+
+// startedQuests are the registered quests the player has under way, by id.
+func startedQuests(player *registry.PlayerWorldState) []*Quest {
+	var out []*Quest
+	for name, vars := range player.Quests {
+		if q, ok := quests[name]; ok && vars[questVarState] == questStarted {
+			out = append(out, q)
+		}
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].ID < out[j].ID })
+	return out
+}
+
+// handleQuestList sends the player its quest journal.
+func (gl *GameLoop) handleQuestList(cmd CmdQuestList) {
+	if player, ok := gl.world.GetPlayer(cmd.CharID); ok {
+		gl.sendToPlayer(player, QuestListPacket(player))
+	}
+}
+
+// handleQuestAbort gives up a started quest: its items are taken back and
+// its state forgotten, so it can be taken again.
+func (gl *GameLoop) handleQuestAbort(cmd CmdQuestAbort) {
+	player, ok := gl.world.GetPlayer(cmd.CharID)
+	if !ok {
+		return
+	}
+	q, ok := questsByID[cmd.QuestID]
+	if !ok {
+		return
+	}
+	qs := gl.questState(player, q)
+	if !qs.IsStarted() {
+		return
+	}
+	qs.takeQuestItems()
+	qs.vars = nil
+	qs.changed, qs.listChanged = true, true
+	qs.PlaySound(SoundGiveUp)
+	gl.finishQuestStep(qs, nil, "")
+}
+
+// handleQuestionMark raises OnQuestionMark of the player's started quests.
+func (gl *GameLoop) handleQuestionMark(cmd CmdQuestionMark) {
+	player, ok := gl.world.GetPlayer(cmd.CharID)
+	if !ok {
+		return
+	}
+	for _, q := range startedQuests(player) {
+		if q.OnQuestionMark == nil {
+			continue
+		}
+		qs := gl.questState(player, q)
+		gl.finishQuestStep(qs, nil, q.OnQuestionMark(qs, cmd.Mark))
+	}
+}
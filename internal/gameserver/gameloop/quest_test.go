@@ -0,0 +1,276 @@
+package gameloop
+
+import (
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/VerTox/l2go/internal/gameserver/models"
+	"github.com/VerTox/l2go/internal/gameserver/packets/outclient"
+	"github.com/VerTox/l2go/internal/gameserver/usecase"
+)
+
+const (
+	testQuestGiver = 30048
+	testQuestMob   = 12345
+	testQuestItem  = 702
+)
+
+// registerTestQuest registers a small repeatable hunting quest for the
+// duration of the test: the giver starts it, each kill of the test mob is one
+// trophy, and three trophies end it.
+func registerTestQuest(t *testing.T) *Quest {
+	t.Helper()
+	q := &Quest{
+		ID:         999,
+		Name:       "Q00999_Test",
+		Title:      "Test Quest",
+		StartNpcs:  []int32{testQuestGiver},
+		TalkNpcs:   []int32{testQuestGiver},
+		KillNpcs:   []int32{testQuestMob},
+		Items:      []int32{testQuestItem},
+		Repeatable: true,
+		OnTalk: func(qs *QuestState, npc *models.NpcInstance) string {
+			switch {
+			case !qs.IsStarted():
+				return "start.htm"
+			case qs.GetInt("kills") < 3:
+				return "<html><body>not yet</body></html>"
+			}
+			qs.GiveItems(models.ItemIDAdena, 100)
+			qs.ExitQuest()
+			return ""
+		},
+		OnEvent: func(qs *QuestState, npc *models.NpcInstance, event string) string {
+			if event == "accept" && !qs.IsStarted() {
+				qs.StartQuest()
+			}
+			return ""
+		},
+		OnKill: func(qs *QuestState, npc *models.NpcInstance) string {
+			qs.SetInt("kills", qs.GetInt("kills")+1)
+			qs.GiveItems(testQuestItem, 1)
+			if qs.GetInt("kills") == 3 {
+				qs.SetCond(2)
+			}
+			return ""
+		},
+		OnItemPickup: func(qs *QuestState, itemID int32, count int64) string {
+			qs.Set("picked", "yes")
+			return ""
+		},
+		OnQuestionMark: func(qs *QuestState, mark int32) string {
+			qs.SetInt("mark", int(mark))
+			return ""
+		},
+	}
+	RegisterQuest(q)
+	t.Cleanup(func() {
+		delete(quests, q.Name)
+		delete(questsByID, q.ID)
+		for _, id := range q.KillNpcs {
+			questsByKill[id] = slices.DeleteFunc(questsByKill[id], func(k *Quest) bool { return k == q })
+		}
+	})
+	return q
+}
+
+func addQuestGiver(gl *GameLoop, objectID int32, pos models.Position) *models.NpcInstance {
+	npc := &models.NpcInstance{
+		ObjectID:   objectID,
+		TemplateID: testQuestGiver,
+		Position:   pos,
+		CurrentHP:  100,
+		Template:   &models.NpcTemplate{ID: testQuestGiver, Name: "Darin", Type: "L2Npc"},
+	}
+	gl.world.AddNPC(npc)
+	return npc
+}
+
+func TestQuest_TalkStartHuntAndFinish(t *testing.T) {
+	registerTestQuest(t)
+	gl, p := newTestLoopWithPlayer(t)
+	sink := make(chan QuestJob, 8)
+	gl.SetQuestSink(sink)
+	giver := addQuestGiver(gl, 2000, models.Position{X: 100})
+	mob := addAttackableNPC(gl, 3000, models.Position{X: 100})
+	mob.TemplateID = testQuestMob
+
+	if html := NpcDialogHtml(p, giver); !strings.Contains(html, "bypass -h npc_2000_Quest") {
+		t.Fatalf("quest giver dialogue = %q", html)
+	}
+
+	// Talking only shows the offer; accepting starts the quest.
+	gl.handleBypass(CmdBypass{CharID: 7, Command: "npc_2000_Quest"})
+	if len(sink) != 0 || p.Quests != nil {
+		t.Fatal("talking must not start the quest")
+	}
+	gl.handleBypass(CmdBypass{CharID: 7, Command: "npc_2000_Quest Q00999_Test accept"})
+	job := <-sink
+	if job.Quest != "Q00999_Test" || job.Vars["state"] != "started" || job.Vars["cond"] != "1" {
+		t.Fatalf("start job = %+v", job)
+	}
+
+	// Kills count for the killer only while the quest runs.
+	for range 3 {
+		gl.handleNPCDeath(mob, 7)
+		mob.IsDead = false
+	}
+	var last QuestJob
+	for range 3 {
+		last = <-sink
+		if len(last.Give) != 1 || last.Give[0] != (usecase.QuestItem{ItemID: testQuestItem, Count: 1}) {
+			t.Fatalf("kill job = %+v", last)
+		}
+	}
+	if last.Vars["kills"] != "3" || last.Vars["cond"] != "2" {
+		t.Fatalf("vars after kills = %v", last.Vars)
+	}
+	if p.Quests["Q00999_Test"]["kills"] != "3" {
+		t.Fatalf("player quests = %v", p.Quests)
+	}
+
+	// Finishing a repeatable quest pays, takes the trophies and forgets it.
+	gl.handleBypass(CmdBypass{CharID: 7, Command: "npc_2000_Quest Q00999_Test"})
+	job = <-sink
+	if job.Vars != nil || len(job.Take) != 1 || job.Take[0].ItemID != testQuestItem || job.Take[0].Count != 0 {
+		t.Fatalf("finish job = %+v", job)
+	}
+	if len(job.Give) != 1 || job.Give[0].ItemID != models.ItemIDAdena {
+		t.Fatalf("reward = %+v", job.Give)
+	}
+	if _, ok := p.Quests["Q00999_Test"]; ok {
+		t.Fatal("a repeatable quest is forgotten when it ends")
+	}
+	gl.handleNPCDeath(mob, 7)
+	if len(sink) != 0 {
+		t.Fatal("kills after the quest must not count")
+	}
+}
+
+func TestQuest_OneTimeQuestIsRemembered(t *testing.T) {
+	q := registerTestQuest(t)
+	q.Repeatable = false
+	gl, p := newTestLoopWithPlayer(t)
+	p.Quests = map[string]map[string]string{q.Name: {"state": "started", "cond": "2", "kills": "3"}}
+	addQuestGiver(gl, 2000, models.Position{X: 100})
+
+	gl.handleBypass(CmdBypass{CharID: 7, Command: "npc_2000_Quest"})
+	if vars := p.Quests[q.Name]; len(vars) != 1 || vars["state"] != "completed" {
+		t.Fatalf("completed quest vars = %v", vars)
+	}
+	// The journal no longer lists it as under way.
+	if got, want := QuestListPacket(p), outclient.BuildQuestList(nil, []int32{999}); string(got) != string(want) {
+		t.Fatalf("QuestList = %x, want %x", got, want)
+	}
+}
+
+func TestQuest_FullSinkRefusesStep(t *testing.T) {
+	q := registerTestQuest(t)
+	finish := q.OnTalk
+	q.OnTalk = func(qs *QuestState, npc *models.NpcInstance) string {
+		qs.RewardExpSp(10, 5)
+		return finish(qs, npc)
+	}
+	gl, p := newTestLoopWithPlayer(t)
+	gl.SetQuestSink(make(chan QuestJob)) // never has room
+	p.Quests = map[string]map[string]string{q.Name: {"state": "started", "cond": "2", "kills": "3"}}
+	addQuestGiver(gl, 2000, models.Position{X: 100})
+	exp, sp := p.Character.Experience, p.Character.SP
+
+	gl.handleBypass(CmdBypass{CharID: 7, Command: "npc_2000_Quest"})
+	if p.Quests[q.Name]["kills"] != "3" {
+		t.Fatalf("quest after a refused step = %v, want unchanged", p.Quests)
+	}
+	if p.Character.Experience != exp || p.Character.SP != sp {
+		t.Fatalf("exp/sp after a refused step = %d/%d, want %d/%d", p.Character.Experience, p.Character.SP, exp, sp)
+	}
+
+	// With room the same step goes through and pays.
+	sink := make(chan QuestJob, 1)
+	gl.SetQuestSink(sink)
+	gl.handleBypass(CmdBypass{CharID: 7, Command: "npc_2000_Quest"})
+	if len(sink) != 1 {
+		t.Fatal("step was not handed to the quest worker")
+	}
+	if _, ok := p.Quests[q.Name]; ok {
+		t.Fatalf("quest after the step = %v, want forgotten", p.Quests)
+	}
+	if p.Character.Experience != exp+10 || p.Character.SP != sp+5 {
+		t.Fatalf("exp/sp after the step = %d/%d, want %d/%d", p.Character.Experience, p.Character.SP, exp+10, sp+5)
+	}
+}
+
+func TestQuest_AbortTakesItemsAndForgets(t *testing.T) {
+	q := registerTestQuest(t)
+	gl, p := newTestLoopWithPlayer(t)
+	sink := make(chan QuestJob, 4)
+	gl.SetQuestSink(sink)
+	p.Quests = map[string]map[string]string{q.Name: {"state": "started", "cond": "1", "kills": "1"}}
+
+	gl.handleQuestAbort(CmdQuestAbort{CharID: 7, QuestID: 1234})
+	if len(sink) != 0 {
+		t.Fatal("unknown quest ids are ignored")
+	}
+	gl.handleQuestAbort(CmdQuestAbort{CharID: 7, QuestID: q.ID})
+	job := <-sink
+	if job.Vars != nil || len(job.Take) != 1 || job.Take[0].ItemID != testQuestItem {
+		t.Fatalf("abort job = %+v", job)
+	}
+	if len(p.Quests) != 0 {
+		t.Fatalf("player quests = %v", p.Quests)
+	}
+	gl.handleQuestAbort(CmdQuestAbort{CharID: 7, QuestID: q.ID})
+	if len(sink) != 0 {
+		t.Fatal("a quest not under way cannot be aborted")
+	}
+}
+
+func TestQuest_NpcChecks(t *testing.T) {
+	q := registerTestQuest(t)
+	gl, p := newTestLoopWithPlayer(t)
+	sink := make(chan QuestJob, 4)
+	gl.SetQuestSink(sink)
+	addQuestGiver(gl, 2000, models.Position{X: 1000})
+	addMerchant(gl, 2001, 30301, models.Position{X: 100})
+
+	// Out of reach, and an NPC outside the quest.
+	gl.handleBypass(CmdBypass{CharID: 7, Command: "npc_2000_Quest Q00999_Test accept"})
+	gl.handleBypass(CmdBypass{CharID: 7, Command: "npc_2001_Quest Q00999_Test accept"})
+	if len(sink) != 0 || p.Quests[q.Name] != nil {
+		t.Fatal("quest events need one of the quest's NPCs in reach")
+	}
+	npc, _ := gl.world.GetNPC(2001)
+	if talkQuests(p, npc.TemplateID) != nil || HasQuests(npc) {
+		t.Fatal("the merchant has no quests")
+	}
+}
+
+func TestQuest_PickupAndQuestionMarkHooks(t *testing.T) {
+	q := registerTestQuest(t)
+	gl, p := newTestLoopWithPlayer(t)
+
+	// Hooks only reach quests under way.
+	gl.notifyQuestItemPickup(p, 57, 10)
+	gl.handleQuestionMark(CmdQuestionMark{CharID: 7, Mark: 4})
+	if p.Quests != nil {
+		t.Fatalf("player quests = %v", p.Quests)
+	}
+
+	p.Quests = map[string]map[string]string{q.Name: {"state": "started", "cond": "1"}}
+	gl.notifyQuestItemPickup(p, 57, 10)
+	gl.handleQuestionMark(CmdQuestionMark{CharID: 7, Mark: 4})
+	if vars := p.Quests[q.Name]; vars["picked"] != "yes" || vars["mark"] != "4" {
+		t.Fatalf("vars = %v", vars)
+	}
+}
+
+func TestRegisterQuest_PanicsOnDuplicate(t *testing.T) {
+	q := registerTestQuest(t)
+	defer func() {
+		if recover() == nil {
+			t.Fatal("duplicate quest id must panic")
+		}
+	}()
+	RegisterQuest(&Quest{ID: q.ID, Name: "Q00999_Other"})
+}
@@ -0,0 +1,159 @@
+package gameloop
+
+import (
+	"math/rand"
+	"time"
+
+	"github.com/rs/zerolog/log"
+
+	"github.com/VerTox/l2go/internal/gameserver/models"
+	"github.com/VerTox/l2go/internal/gameserver/packets/outclient"
+)
+
+// raidPointsSpread: the raid points of a kill are the boss's level / 2, give
+// or take up to this many (L2J L2RaidBossInstance.doDie).
+const raidPointsSpread = 5
+
+// RaidBossJob saves a raid boss's state and, for a kill, the raid points each
+// character earned. It needs the database and therefore runs off the loop on
+// the raid boss worker, in order.
+type RaidBossJob struct {
+	Status models.RaidBossStatus
+	Points map[int32]int32 // charID -> raid points; nil unless the boss died
+}
+
+// SetRaidBossSink wires the channel that receives raid boss states. Kept out
+// of New() like the other optional sinks; without it bosses live and die but
+// nothing is saved.
+func (gl *GameLoop) SetRaidBossSink(sink chan<- RaidBossJob) {
+	gl.raidBossSink = sink
+}
+
+// enqueueRaidBossJob hands a boss state to the raid boss worker. Non-blocking:
+// with no sink or a full queue the job is dropped.
+func (gl *GameLoop) enqueueRaidBossJob(job RaidBossJob) {
+	if gl.raidBossSink == nil {
+		return
+	}
+	select {
+	case gl.raidBossSink <- job:
+	default:
+		log.Warn().Int32("boss_id", job.Status.BossID).Msg("raid boss sink full, dropping raid boss job")
+	}
+}
+
+// isRaidBoss reports whether the NPC is a raid or grand boss.
+func isRaidBoss(npc *models.NpcInstance) bool {
+	return npc.Template != nil && npc.Template.IsRaid()
+}
+
+// RestoreRaidBosses applies the boss states saved before the last shutdown to
+// the bosses the spawn list put into the world (L2J RaidBossSpawnManager
+// init). A boss still waiting to respawn leaves the world, with its minions,
+// until its respawn time; a living one gets its saved HP and MP back. Must be
+// called once at startup, after RegisterWorldSpawns and before Run.
+func (gl *GameLoop) RestoreRaidBosses(statuses []models.RaidBossStatus) {
+	saved := make(map[int32]models.RaidBossStatus, len(statuses))
+	for _, s := range statuses {
+		saved[s.BossID] = s
+	}
+	now := time.Now()
+	waiting, alive := 0, 0
+	for _, npc := range gl.world.GetAllNPCs() {
+		if !isRaidBoss(npc) {
+			continue
+		}
+		s, ok := saved[npc.TemplateID]
+		if ok && !s.Alive() && s.RespawnAt.After(now) {
+			gl.despawnMinions(npc.ObjectID)
+			gl.world.RemoveNPC(npc.ObjectID)
+			gl.events.Schedule(&RespawnEvent{At: s.RespawnAt, ObjectID: npc.ObjectID})
+			waiting++
+			continue
+		}
+		if ok && s.Alive() && s.CurrentHP > 0 {
+			npc.CurrentHP = min(s.CurrentHP, npc.Template.HP)
+			npc.CurrentMP = min(s.CurrentMP, npc.Template.MP)
+		}
+		gl.raidBosses[npc.TemplateID] = npc.ObjectID
+		alive++
+	}
+	log.Info().Int("alive", alive).Int("waiting", waiting).Msg("Restored raid bosses")
+}
+
+// raidBossSpawned takes a boss that just respawned under the manager: it is
+// saved alive and announced to everyone online.
+func (gl *GameLoop) raidBossSpawned(npc *models.NpcInstance) {
+	gl.raidBosses[npc.TemplateID] = npc.ObjectID
+	gl.enqueueRaidBossJob(RaidBossJob{Status: raidBossStatus(npc)})
+	gl.announce("Raid Boss " + npc.Template.Name + " has spawned.")
+	log.Info().Int32("boss_id", npc.TemplateID).Str("name", npc.Template.Name).Msg("Raid boss spawned")
+}
+
+// raidBossDied records a boss kill: its respawn time is saved, the killer's
+// party (or the killer alone) earns raid points, and the players around hear
+// the raid was successful.
+func (gl *GameLoop) raidBossDied(npc *models.NpcInstance, killerID int32, respawnAt time.Time) {
+	delete(gl.raidBosses, npc.TemplateID)
+	status := raidBossStatus(npc)
+	status.RespawnAt = respawnAt
+	gl.enqueueRaidBossJob(RaidBossJob{Status: status, Points: gl.raidPoints(npc, killerID, rand.Intn)})
+	gl.broadcastToNearby(npc.Position, outclient.BuildSystemMessageNoParams(outclient.SysMsgRaidWasSuccessful))
+}
+
+// raidPoints rolls the raid points a boss kill earns: each member of the
+// killer's party within party range of the boss gets level / 2 plus or minus
+// raidPointsSpread, at least 1; a killer with no party gets them alone. roll(n)
+// returns [0, n).
+func (gl *GameLoop) raidPoints(npc *models.NpcInstance, killerID int32, roll func(n int) int) map[int32]int32 {
+	if _, ok := gl.world.GetPlayer(killerID); !ok {
+		return nil
+	}
+	earners := []int32{killerID}
+	if p := gl.partyOf(killerID); p != nil {
+		earners = earners[:0]
+		for _, m := range gl.partyMembersInRange(p, npc.Position, partyRange) {
+			earners = append(earners, m.CharID)
+		}
+	}
+	points := make(map[int32]int32, len(earners))
+	for _, charID := range earners {
+		points[charID] = int32(max(1, npc.Template.Level/2+roll(2*raidPointsSpread+1)-raidPointsSpread))
+	}
+	return points
+}
+
+// raidBossStatus is a boss's state to save while it lives.
+func raidBossStatus(npc *models.NpcInstance) models.RaidBossStatus {
+	return models.RaidBossStatus{BossID: npc.TemplateID, CurrentHP: npc.CurrentHP, CurrentMP: npc.CurrentMP}
+}
+
+// saveRaidBosses saves the HP and MP of every living boss; run with the
+// autosave.
+func (gl *GameLoop) saveRaidBosses() {
+	for _, objectID := range gl.raidBosses {
+		if npc, ok := gl.world.GetNPC(objectID); ok && !npc.IsDead {
+			gl.enqueueRaidBossJob(RaidBossJob{Status: raidBossStatus(npc)})
+		}
+	}
+}
+
+// RaidBossStatuses returns the state of every living boss, for the save on
+// shutdown. Only safe once the loop has stopped.
+func (gl *GameLoop) RaidBossStatuses() []models.RaidBossStatus {
+	var out []models.RaidBossStatus
+	for _, objectID := range gl.raidBosses {
+		if npc, ok := gl.world.GetNPC(objectID); ok && !npc.IsDead {
+			out = append(out, raidBossStatus(npc))
+		}
+	}
+	return out
+}
+
+// announce shows a server announcement to every player online.
+func (gl *GameLoop) announce(text string) {
+	pkt := outclient.BuildCreatureSay(0, outclient.ChatAnnouncement, "", text)
+	for _, p := range gl.world.SnapshotPlayers(nil) {
+		gl.sendToPlayer(p, pkt)
+	}
+}
@@ -0,0 +1,174 @@
+package gameloop
+
+import (
+	"testing"
+	"time"
+
+	"github.com/VerTox/l2go/internal/gameserver/models"
+	"github.com/VerTox/l2go/internal/gameserver/packets/outclient"
+)
+
+// Template ids for the raid boss tests, out of the datapack's range.
+const (
+	testRaidBossID = 990001
+	testMinionID   = 990002
+)
+
+// useNpcTemplates makes tpls the only NPC templates gl spawns from.
+func useNpcTemplates(gl *GameLoop, tpls ...*models.NpcTemplate) {
+	byID := make(map[int32]*models.NpcTemplate, len(tpls))
+	for _, tpl := range tpls {
+		byID[tpl.ID] = tpl
+	}
+	gl.npcTemplates = func(templateID int32) *models.NpcTemplate { return byID[templateID] }
+}
+
+// addRaidBoss gives gl a level-40 raid boss escorted by two minions and puts
+// one into the world as a spawn-list NPC.
+func addRaidBoss(gl *GameLoop, objectID int32) *models.NpcInstance {
+	tpl := &models.NpcTemplate{
+		ID: testRaidBossID, Name: "TestBoss", Type: "L2RaidBoss", Level: 40, HP: 1000, MP: 500,
+		Minions: []models.NpcMinion{{NpcID: testMinionID, Count: 2}},
+	}
+	useNpcTemplates(gl, tpl, &models.NpcTemplate{ID: testMinionID, Name: "TestMinion", Type: "L2Monster", HP: 50, MP: 10})
+	npc := &models.NpcInstance{ObjectID: objectID, TemplateID: tpl.ID, Template: tpl, CurrentHP: tpl.HP, CurrentMP: tpl.MP}
+	gl.world.AddNPC(npc)
+	gl.RegisterWorldSpawns()
+	return npc
+}
+
+func TestRestoreRaidBosses_WaitingBossLeavesWorld(t *testing.T) {
+	gl, _ := newTestLoopWithPlayer(t)
+	boss := addRaidBoss(gl, 1000)
+
+	gl.RestoreRaidBosses([]models.RaidBossStatus{{BossID: testRaidBossID, RespawnAt: time.Now().Add(time.Hour)}})
+
+	if _, ok := gl.world.GetNPC(boss.ObjectID); ok {
+		t.Error("boss waiting to respawn is still in the world")
+	}
+	if len(gl.minionLeaders) != 0 || len(gl.raidBosses) != 0 {
+		t.Errorf("waiting boss has minions %v / is tracked %v", gl.minions, gl.raidBosses)
+	}
+	if gl.events.Len() != 1 {
+		t.Errorf("scheduled events = %d, want the boss respawn", gl.events.Len())
+	}
+}
+
+func TestRestoreRaidBosses_LivingBossGetsSavedHP(t *testing.T) {
+	gl, _ := newTestLoopWithPlayer(t)
+	boss := addRaidBoss(gl, 1000)
+
+	gl.RestoreRaidBosses([]models.RaidBossStatus{{BossID: testRaidBossID, CurrentHP: 400, CurrentMP: 9000}})
+
+	if boss.CurrentHP != 400 || boss.CurrentMP != 500 {
+		t.Errorf("boss HP/MP = %v/%v, want 400/500 (MP capped)", boss.CurrentHP, boss.CurrentMP)
+	}
+	if gl.raidBosses[testRaidBossID] != boss.ObjectID {
+		t.Errorf("raid bosses = %v, want the boss tracked", gl.raidBosses)
+	}
+	if got := len(gl.minions[boss.ObjectID]); got != 2 {
+		t.Fatalf("boss spawned %d minions, want 2", got)
+	}
+	for _, id := range gl.minions[boss.ObjectID] {
+		m, ok := gl.world.GetNPC(id)
+		if !ok || m.TemplateID != testMinionID {
+			t.Errorf("minion %d = %+v", id, m)
+		}
+	}
+}
+
+func TestRaidBossDied_SavesTimerAwardsPartyAndDespawnsMinions(t *testing.T) {
+	gl, _ := newTestLoopWithPlayer(t)
+	addPlayer(t, gl, 8, "acc2", models.Position{X: 100})
+	addPlayer(t, gl, 9, "acc3", models.Position{X: partyRange + 500})
+	joinParty(t, gl, 7, 8, 9)
+	sink := make(chan RaidBossJob, 4)
+	gl.SetRaidBossSink(sink)
+	boss := addRaidBoss(gl, 1000)
+	gl.RestoreRaidBosses(nil)
+	minions := gl.minions[boss.ObjectID]
+
+	gl.handleNPCDeath(boss, 7)
+
+	job := <-sink
+	if job.Status.BossID != testRaidBossID || job.Status.Alive() || !job.Status.RespawnAt.After(time.Now()) {
+		t.Errorf("saved status = %+v, want a respawn time ahead", job.Status)
+	}
+	if len(job.Points) != 2 || job.Points[7] < 15 || job.Points[7] > 25 || job.Points[8] == 0 {
+		t.Errorf("raid points = %v, want 15-25 each for the members in range", job.Points)
+	}
+	for _, id := range minions {
+		if _, ok := gl.world.GetNPC(id); ok {
+			t.Errorf("minion %d outlived its boss", id)
+		}
+	}
+	if len(gl.minions) != 0 || len(gl.minionLeaders) != 0 || len(gl.raidBosses) != 0 {
+		t.Errorf("leftover state: minions %v leaders %v bosses %v", gl.minions, gl.minionLeaders, gl.raidBosses)
+	}
+}
+
+func TestRaidPoints_SoloKillerAtLeastOne(t *testing.T) {
+	gl, _ := newTestLoopWithPlayer(t)
+	boss := addAttackableNPC(gl, 1000, models.Position{})
+	boss.Template.Level = 2
+
+	got := gl.raidPoints(boss, 7, func(int) int { return 0 })
+	if len(got) != 1 || got[7] != 1 {
+		t.Errorf("raid points = %v, want 1 for the killer alone", got)
+	}
+	if got := gl.raidPoints(boss, 404, func(int) int { return 0 }); got != nil {
+		t.Errorf("raid points for a killer not in the world = %v, want none", got)
+	}
+}
+
+func TestRespawnEvent_RaidBossAnnouncedWithMinions(t *testing.T) {
+	gl, _ := newTestLoopWithPlayer(t)
+	conn, rec := newRecordingConn(t)
+	gl.connections.Register("acc", conn)
+	sink := make(chan RaidBossJob, 4)
+	gl.SetRaidBossSink(sink)
+	boss := addRaidBoss(gl, 1000)
+	gl.despawnMinions(boss.ObjectID)
+	gl.world.RemoveNPC(boss.ObjectID)
+
+	(&RespawnEvent{At: time.Now(), ObjectID: boss.ObjectID}).Execute(gl)
+
+	newID, ok := gl.raidBosses[testRaidBossID]
+	if !ok {
+		t.Fatal("respawned boss is not tracked")
+	}
+	if got := len(gl.minions[newID]); got != 2 {
+		t.Errorf("respawned boss has %d minions, want 2", got)
+	}
+	if job := <-sink; !job.Status.Alive() || job.Status.CurrentHP != 1000 {
+		t.Errorf("saved status = %+v, want alive at full HP", job.Status)
+	}
+	want := outclient.BuildCreatureSay(0, outclient.ChatAnnouncement, "", "Raid Boss TestBoss has spawned.")
+	if !eventually(func() bool { return rec.contains(want) }) {
+		t.Error("raid boss spawn was not announced")
+	}
+}
+
+func TestNpcArrivedHome_RaidBossRestoresMPAndSaves(t *testing.T) {
+	gl, _ := newTestLoopWithPlayer(t)
+	sink := make(chan RaidBossJob, 4)
+	gl.SetRaidBossSink(sink)
+	boss := addRaidBoss(gl, 1000)
+	gl.RestoreRaidBosses(nil)
+
+	// Full HP, MP spent on skills.
+	boss.CurrentMP = 100
+	gl.npcArrivedHome(boss)
+	if boss.CurrentMP != 500 {
+		t.Errorf("boss MP = %v, want 500", boss.CurrentMP)
+	}
+	if job := <-sink; job.Status.CurrentHP != 1000 || job.Status.CurrentMP != 500 {
+		t.Errorf("saved status = %+v, want full HP and MP", job.Status)
+	}
+
+	// Nothing to heal: still saved.
+	gl.npcArrivedHome(boss)
+	if len(sink) != 1 {
+		t.Errorf("boss arriving home at full HP/MP saved %d times, want once", len(sink))
+	}
+}
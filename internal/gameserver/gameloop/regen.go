@@ -42,5 +42,6 @@ func (gl *GameLoop) regenPlayers() {
 			_ = conn.Send(su)
 		}
 		gl.broadcastToTargeters(charID, su)
+		gl.updatePartyStatus(player)
 	}
 }
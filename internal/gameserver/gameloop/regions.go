@@ -8,6 +8,7 @@ import (
 // ActiveRegion tracks whether a region has players nearby and should be ticked.
 type ActiveRegion struct {
 	Key            string
+	Cell           [2]int
 	PlayerCount    int
 	LastPlayerTime time.Time
 	Active         bool
@@ -28,14 +29,23 @@ func cellKey(c [2]int) string {
 	return fmt.Sprintf("%d,%d", c[0], c[1])
 }
 
-// surroundingKeys returns the 3x3 block of cell keys around the given center cell.
-func surroundingKeys(center [2]int) []string {
-	keys := make([]string, 0, 9)
+// surroundingCells returns the 3x3 block of cells around the given center cell.
+func surroundingCells(center [2]int) [][2]int {
+	cells := make([][2]int, 0, 9)
 	for dx := -1; dx <= 1; dx++ {
 		for dy := -1; dy <= 1; dy++ {
-			keys = append(keys, cellKey([2]int{center[0] + dx, center[1] + dy}))
+			cells = append(cells, [2]int{center[0] + dx, center[1] + dy})
 		}
 	}
+	return cells
+}
+
+// surroundingKeys returns the 3x3 block of cell keys around the given center cell.
+func surroundingKeys(center [2]int) []string {
+	keys := make([]string, 0, 9)
+	for _, c := range surroundingCells(center) {
+		keys = append(keys, cellKey(c))
+	}
 	return keys
 }
 
@@ -72,10 +82,11 @@ func (gl *GameLoop) leavePlayerRegions(charID int32) {
 // activateRegionBlock increments the reference count on the 3x3 block around center.
 func (gl *GameLoop) activateRegionBlock(center [2]int) {
 	now := time.Now()
-	for _, key := range surroundingKeys(center) {
+	for _, cell := range surroundingCells(center) {
+		key := cellKey(cell)
 		r, ok := gl.activeRegions[key]
 		if !ok {
-			r = &ActiveRegion{Key: key}
+			r = &ActiveRegion{Key: key, Cell: cell}
 			gl.activeRegions[key] = r
 		}
 		r.PlayerCount++
@@ -1,7 +1,9 @@
 package gameloop
 
 import (
+	"math/rand"
 	"testing"
+	"time"
 
 	"github.com/VerTox/l2go/internal/gameserver/models"
 	"github.com/VerTox/l2go/internal/gameserver/registry"
@@ -93,3 +95,39 @@ func TestRegisterWorldSpawnsPopulatesSpawnInfo(t *testing.T) {
 		t.Errorf("spawn info = %+v, want TemplateID 42, pos {10,20,30}, heading 5", info)
 	}
 }
+
+func TestSpawnInfo_NextRespawnDelay(t *testing.T) {
+	raid := SpawnInfo{RespawnDelay: 12 * time.Hour, RespawnRandom: time.Hour}
+	if got := raid.nextRespawnDelay(func(int64) int64 { return 0 }); got != 11*time.Hour {
+		t.Errorf("shortest raid respawn = %v, want 11h", got)
+	}
+	if got := raid.nextRespawnDelay(func(n int64) int64 { return n - 1 }); got != 13*time.Hour {
+		t.Errorf("longest raid respawn = %v, want 13h", got)
+	}
+	if got := (SpawnInfo{}).nextRespawnDelay(nil); got != respawnDelay {
+		t.Errorf("respawn without a delay = %v, want the default %v", got, respawnDelay)
+	}
+}
+
+func TestRegisterWorldSpawns_DelayAndTerritory(t *testing.T) {
+	world := registry.NewWorldRegistry()
+	sd := &models.SpawnData{NpcID: 42, RespawnDelay: 43200, RespawnRandom: 3600, Territory: "orc_camp"}
+	world.AddNPC(&models.NpcInstance{ObjectID: 1000, TemplateID: 42, Template: &models.NpcTemplate{ID: 42}, Spawn: sd})
+	gl := New(world, registry.NewConnectionRegistry(), 1, 1)
+	gl.zoneRegistry = registry.NewZoneRegistry()
+	camp := &models.Zone{Name: "orc_camp", Type: models.ZoneSpawnTerritory,
+		Shape: models.ZoneNPoly{Xs: []int{0, 1000, 0}, Ys: []int{0, 0, 1000}, MinZ: -100, MaxZ: 100}}
+	gl.zoneRegistry.Add(camp)
+
+	gl.RegisterWorldSpawns()
+
+	info := gl.npcSpawnInfo[1000]
+	if info.RespawnDelay != 12*time.Hour || info.RespawnRandom != time.Hour || info.Territory != camp {
+		t.Fatalf("spawn info = %+v", info)
+	}
+	for range 20 {
+		if pos := info.Territory.RandomPoint(rand.Intn); !camp.Contains(pos) {
+			t.Fatalf("respawn point %+v is outside the territory", pos)
+		}
+	}
+}
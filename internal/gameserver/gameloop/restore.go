@@ -45,6 +45,7 @@ func (gl *GameLoop) handleRestoreStats(cmd CmdRestoreStats) {
 		_ = conn.Send(gl.buildUserInfoForPlayer(player))
 	}
 	gl.broadcastToTargeters(cmd.CharID, su)
+	gl.updatePartyStatus(player)
 }
 
 // handleItemSkillCast casts an item's linked skill (potion/consumable) through the
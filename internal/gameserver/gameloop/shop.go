@@ -0,0 +1,158 @@
+package gameloop
+
+import (
+	"github.com/rs/zerolog/log"
+
+	"github.com/VerTox/l2go/internal/gameserver/models"
+	"github.com/VerTox/l2go/internal/gameserver/packets/outclient"
+	"github.com/VerTox/l2go/internal/gameserver/registry"
+	"github.com/VerTox/l2go/internal/gameserver/usecase"
+)
+
+// merchantNpcTypes are the datapack NPC types that run a shop (L2J
+// L2MerchantInstance and its subclasses).
+var merchantNpcTypes = map[string]bool{
+	"L2Merchant":   true,
+	"L2Fisherman":  true,
+	"L2PetManager": true,
+}
+
+func init() {
+	RegisterBypass(BypassShop, func(gl *GameLoop, player *registry.PlayerWorldState, npcObjID int32, arg string) {
+		if listID, ok := bypassListID(arg); ok {
+			gl.handleShopOpen(CmdShopOpen{CharID: player.CharID, NpcObjID: npcObjID, ListID: listID})
+		}
+	})
+}
+
+// ShopJobKind selects what the shop worker does with a ShopJob.
+type ShopJobKind int
+
+const (
+	// ShopJobOpen sends the shop window: ExBuyList and ExBuySellList.
+	ShopJobOpen ShopJobKind = iota
+	// ShopJobBuy buys the Buy lines off list ListID.
+	ShopJobBuy
+	// ShopJobSell sells the Sell lines to the merchant.
+	ShopJobSell
+	// ShopJobRefund buys back the Refund lines of the refund list.
+	ShopJobRefund
+)
+
+// ShopJob is merchant work that needs the database and therefore runs off the
+// loop on the shop worker. The loop has already checked that the player stands
+// at a merchant allowed to open list ListID.
+type ShopJob struct {
+	Kind           ShopJobKind
+	CharID         int32
+	ListID         int32
+	InventorySlots int
+	Buy            []registry.BuyListLine
+	Sell           []usecase.ShopSale
+	Refund         []int32
+}
+
+// SetShopSink wires the channel that receives merchant work. Kept out of New()
+// like the other optional sinks; without it merchants open nothing.
+func (gl *GameLoop) SetShopSink(sink chan<- ShopJob) {
+	gl.shopSink = sink
+}
+
+// enqueueShopJob hands work to the shop worker. Non-blocking: with no sink or
+// a full queue the job is dropped.
+func (gl *GameLoop) enqueueShopJob(job ShopJob) {
+	if gl.shopSink == nil {
+		return
+	}
+	select {
+	case gl.shopSink <- job:
+	default:
+		log.Warn().Int32("char_id", job.CharID).Msg("shop sink full, dropping shop job")
+	}
+}
+
+// IsMerchant reports whether an NPC template runs a shop.
+func IsMerchant(tmpl *models.NpcTemplate) bool {
+	return tmpl != nil && merchantNpcTypes[tmpl.Type]
+}
+
+// shopUser returns the player if it may deal with list listID at npcObjID
+// right now: alive, not trading or running a store, and within interact range
+// of a merchant the list belongs to (L2J RequestBuyItem/RequestSellItem
+// checks).
+func (gl *GameLoop) shopUser(charID, npcObjID, listID int32) (*registry.PlayerWorldState, bool) {
+	player, ok := gl.world.GetPlayer(charID)
+	if !ok || player.Character == nil || player.Character.CurrentHP <= 0 {
+		return nil, false
+	}
+	if gl.trades[charID] != nil {
+		gl.sendToPlayer(player, outclient.BuildSystemMessageNoParams(outclient.SysMsgAlreadyTrading))
+		return nil, false
+	}
+	if player.PrivateStore.Type != registry.StoreNone {
+		gl.sendToPlayer(player, outclient.BuildActionFailed())
+		return nil, false
+	}
+	npc, ok := gl.world.GetNPC(npcObjID)
+	if !ok || npc.IsDead || !IsMerchant(npc.Template) ||
+		!registry.GetBuyListRegistry().AllowsNpc(listID, npc.TemplateID) {
+		return nil, false
+	}
+	dx := player.Position.X - npc.Position.X
+	dy := player.Position.Y - npc.Position.Y
+	if dx*dx+dy*dy > interactRange*interactRange {
+		return nil, false
+	}
+	return player, true
+}
+
+// handleShopOpen remembers the merchant the player opened a shop at and has
+// the worker send the window.
+func (gl *GameLoop) handleShopOpen(cmd CmdShopOpen) {
+	player, ok := gl.shopUser(cmd.CharID, cmd.NpcObjID, cmd.ListID)
+	if !ok {
+		return
+	}
+	player.Merchant = cmd.NpcObjID
+	gl.enqueueShopJob(ShopJob{Kind: ShopJobOpen, CharID: cmd.CharID, ListID: cmd.ListID})
+}
+
+// handleShopBuy hands confirmed purchases to the worker.
+func (gl *GameLoop) handleShopBuy(cmd CmdShopBuy) {
+	if len(cmd.Items) == 0 {
+		return
+	}
+	gl.handleShopDeal(cmd.CharID, cmd.ListID, ShopJob{Kind: ShopJobBuy, Buy: cmd.Items})
+}
+
+// handleShopSell hands confirmed sales to the worker.
+func (gl *GameLoop) handleShopSell(cmd CmdShopSell) {
+	if len(cmd.Items) == 0 {
+		return
+	}
+	gl.handleShopDeal(cmd.CharID, cmd.ListID, ShopJob{Kind: ShopJobSell, Sell: cmd.Items})
+}
+
+// handleShopRefund hands a buy-back to the worker.
+func (gl *GameLoop) handleShopRefund(cmd CmdShopRefund) {
+	if len(cmd.Indexes) == 0 {
+		return
+	}
+	gl.handleShopDeal(cmd.CharID, cmd.ListID, ShopJob{Kind: ShopJobRefund, Refund: cmd.Indexes})
+}
+
+// handleShopDeal enqueues a deal once the player is still at the merchant that
+// opened its shop and the named list belongs to it.
+func (gl *GameLoop) handleShopDeal(charID, listID int32, job ShopJob) {
+	player, ok := gl.world.GetPlayer(charID)
+	if !ok || player.Merchant == 0 {
+		return
+	}
+	if _, ok := gl.shopUser(charID, player.Merchant, listID); !ok {
+		return
+	}
+	job.CharID = charID
+	job.ListID = listID
+	job.InventorySlots = usecase.InventoryLimit(player.Character)
+	gl.enqueueShopJob(job)
+}
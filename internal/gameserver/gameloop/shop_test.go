@@ -0,0 +1,99 @@
+package gameloop
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/VerTox/l2go/internal/gameserver/models"
+	"github.com/VerTox/l2go/internal/gameserver/registry"
+	"github.com/VerTox/l2go/internal/gameserver/usecase"
+)
+
+// loadTestBuyList loads a single buy list, 3030101 at NPC 30301, into the
+// global registry for the duration of the test.
+func loadTestBuyList(t *testing.T) {
+	t.Helper()
+	dir := t.TempDir()
+	xml := `<list><npcs><npc>30301</npc></npcs><item id="1060" price="40" /></list>`
+	if err := os.WriteFile(filepath.Join(dir, "3030101.xml"), []byte(xml), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := registry.GetBuyListRegistry().LoadFromDirectory(dir); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = registry.GetBuyListRegistry().LoadFromDirectory(t.TempDir()) })
+}
+
+func addMerchant(gl *GameLoop, objectID, npcID int32, pos models.Position) {
+	gl.world.AddNPC(&models.NpcInstance{
+		ObjectID:   objectID,
+		TemplateID: npcID,
+		Position:   pos,
+		CurrentHP:  100,
+		Template:   &models.NpcTemplate{ID: npcID, Name: "Hally", Type: "L2Merchant"},
+	})
+}
+
+func TestShop_OpenAtMerchant(t *testing.T) {
+	loadTestBuyList(t)
+	gl, p := newTestLoopWithPlayer(t)
+	sink := make(chan ShopJob, 4)
+	gl.SetShopSink(sink)
+	addMerchant(gl, 2000, 30301, models.Position{X: 100})
+	addMerchant(gl, 2001, 30315, models.Position{X: 100})
+	addWarehouseKeeper(gl, 2002, models.Position{X: 100})
+
+	npc, _ := gl.world.GetNPC(2000)
+	if html := NpcDialogHtml(p, npc); !strings.Contains(html, `bypass -h buy 3030101`) {
+		t.Fatalf("merchant dialogue = %q", html)
+	}
+
+	// The list must belong to the merchant, and keepers sell nothing.
+	gl.handleShopOpen(CmdShopOpen{CharID: 7, NpcObjID: 2001, ListID: 3030101})
+	gl.handleShopOpen(CmdShopOpen{CharID: 7, NpcObjID: 2002, ListID: 3030101})
+	if len(sink) != 0 || p.Merchant != 0 {
+		t.Fatal("only a merchant of the list opens it")
+	}
+
+	gl.handleShopOpen(CmdShopOpen{CharID: 7, NpcObjID: 2000, ListID: 3030101})
+	if job := <-sink; job.Kind != ShopJobOpen || job.ListID != 3030101 || p.Merchant != 2000 {
+		t.Fatalf("open job = %+v, merchant = %d", job, p.Merchant)
+	}
+}
+
+func TestShop_DealsNeedMerchantInReach(t *testing.T) {
+	loadTestBuyList(t)
+	gl, p := newTestLoopWithPlayer(t)
+	sink := make(chan ShopJob, 4)
+	gl.SetShopSink(sink)
+	addMerchant(gl, 2000, 30301, models.Position{X: 100})
+	buy := CmdShopBuy{CharID: 7, ListID: 3030101, Items: []registry.BuyListLine{{ItemID: 1060, Count: 5}}}
+
+	// Nothing is open yet.
+	gl.handleShopBuy(buy)
+	if len(sink) != 0 {
+		t.Fatal("a deal without an open shop must be ignored")
+	}
+
+	gl.handleShopOpen(CmdShopOpen{CharID: 7, NpcObjID: 2000, ListID: 3030101})
+	<-sink
+	gl.handleShopBuy(buy)
+	job := <-sink
+	if job.Kind != ShopJobBuy || len(job.Buy) != 1 || job.InventorySlots != usecase.InventorySlots {
+		t.Fatalf("buy job = %+v", job)
+	}
+	gl.handleShopRefund(CmdShopRefund{CharID: 7, ListID: 3030101, Indexes: []int32{0}})
+	if job := <-sink; job.Kind != ShopJobRefund || job.Refund[0] != 0 {
+		t.Fatalf("refund job = %+v", job)
+	}
+
+	// Another list id than the merchant's, or walking away, closes the deal.
+	gl.handleShopSell(CmdShopSell{CharID: 7, ListID: 1, Items: []usecase.ShopSale{{ObjectID: 500, ItemID: 1060, Count: 1}}})
+	p.Position = models.Position{X: 1000}
+	gl.handleShopBuy(buy)
+	if len(sink) != 0 {
+		t.Fatal("deals off the merchant's list or out of reach must be ignored")
+	}
+}
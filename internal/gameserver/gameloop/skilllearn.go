@@ -10,6 +10,12 @@ import (
 // trainerInteractDistance is the L2J canInteract range for a trainer NPC.
 const trainerInteractDistance = 150
 
+func init() {
+	RegisterBypass(BypassLearnSkills, func(gl *GameLoop, player *registry.PlayerWorldState, npcObjID int32, _ string) {
+		gl.handleOpenSkillLearn(CmdOpenSkillLearn{CharID: player.CharID, NpcObjID: npcObjID})
+	})
+}
+
 // LearnedSkill is enqueued to the persist sink after a successful learn so the DB
 // write happens off the game-loop goroutine.
 type LearnedSkill struct {
@@ -30,6 +30,7 @@ func (gl *GameLoop) handleTeleport(cmd CmdTeleport) {
 	player.IsMoving = false
 	player.MoveStartPos = models.Position{}
 	player.MoveDestination = models.Position{}
+	delete(gl.playerPaths, cmd.CharID)
 
 	dest := models.Position{X: cmd.Dest.X, Y: cmd.Dest.Y, Z: cmd.Dest.Z + teleportZOffset}
 	oldPos := player.Position
@@ -56,6 +57,7 @@ func (gl *GameLoop) handleTeleport(cmd CmdTeleport) {
 	// The teleporting client unloaded its whole area, so it no longer knows anyone;
 	// visibility is rebuilt on Appearing (CmdPlayerEnteredWorld → reconcile).
 	player.KnownPlayers = make(map[int32]bool)
+	player.KnownItems = make(map[int32]bool)
 
 	player.IsTeleporting = true
 	_ = gl.world.UpdatePlayerPosition(context.Background(), cmd.CharID, dest, cmd.Heading)
@@ -0,0 +1,395 @@
+package gameloop
+
+import (
+	"time"
+
+	"github.com/rs/zerolog/log"
+
+	"github.com/VerTox/l2go/internal/gameserver/models"
+	"github.com/VerTox/l2go/internal/gameserver/packets/outclient"
+	"github.com/VerTox/l2go/internal/gameserver/registry"
+	"github.com/VerTox/l2go/internal/gameserver/usecase"
+)
+
+const (
+	// tradeRange is how close the partners must be to start or confirm a trade
+	// (L2J TradeRequest / TradeDone interaction distance).
+	tradeRange = 150
+
+	// tradeLeashRange cancels an open trade once the partners drift this far
+	// apart, so a walked-away window does not stay open forever.
+	tradeLeashRange = 1000
+
+	// tradeRequestTimeout is how long a SendTradeRequest stays answerable (L2J
+	// L2Request.REQUEST_TIMEOUT).
+	tradeRequestTimeout = 15 * time.Second
+
+	// tradeCheckInterval is how often open trades are checked for a dead,
+	// departed or out-of-range partner.
+	tradeCheckInterval = time.Second
+)
+
+// TradeJobKind selects what the trade worker does with a TradeJob.
+type TradeJobKind int
+
+const (
+	// TradeJobOpen sends each partner TradeStart with its tradeable inventory.
+	TradeJobOpen TradeJobKind = iota
+	// TradeJobCommit swaps both offers in one DB transaction and reports the
+	// outcome (TradeDone + InventoryUpdate) to both partners.
+	TradeJobCommit
+)
+
+// TradeJob is trade work that needs the database and therefore runs off the loop
+// on the trade worker. For a commit the session is already gone from the loop:
+// the worker owns the outcome, so a partner disconnecting mid-commit can neither
+// cancel nor repeat it — the transaction alone decides.
+type TradeJob struct {
+	Kind   TradeJobKind
+	CharA  int32
+	CharB  int32
+	OfferA []usecase.ItemTransfer
+	OfferB []usecase.ItemTransfer
+	// SlotsA and SlotsB are each partner's inventory limit, for a commit.
+	SlotsA int
+	SlotsB int
+}
+
+// tradeSession is an open trade window between two players. Loop-owned: both
+// charIDs map to the same *tradeSession in GameLoop.trades.
+type tradeSession struct {
+	chars [2]int32
+	// names keeps both names for the cancel message when a partner is already
+	// gone from the world (disconnect race).
+	names     [2]string
+	offers    [2][]usecase.ItemTransfer
+	confirmed [2]bool
+}
+
+// side returns charID's index in the session.
+func (s *tradeSession) side(charID int32) int {
+	if s.chars[0] == charID {
+		return 0
+	}
+	return 1
+}
+
+// tradeRequest is an unanswered SendTradeRequest, keyed by the asked player.
+type tradeRequest struct {
+	requesterCharID int32
+	seq             int64
+}
+
+// SetTradeSink wires the channel that receives trade work needing the database
+// (opening the window, committing the swap). Kept out of New() like the other
+// optional sinks; without it a trade can be negotiated but never completes.
+func (gl *GameLoop) SetTradeSink(sink chan<- TradeJob) {
+	gl.tradeSink = sink
+}
+
+// tradeRequestOutstanding reports whether charID has asked someone to trade and
+// is still waiting for the answer.
+func (gl *GameLoop) tradeRequestOutstanding(charID int32) bool {
+	for _, req := range gl.tradeRequests {
+		if req.requesterCharID == charID {
+			return true
+		}
+	}
+	return false
+}
+
+// handleTradeRequest validates a TradeRequest and asks the target. Mirrors L2J
+// TradeRequest: both alive, within tradeRange, neither already trading nor busy
+// with another request.
+func (gl *GameLoop) handleTradeRequest(cmd CmdTradeRequest) {
+	player, ok := gl.world.GetPlayer(cmd.CharID)
+	if !ok || player.Character == nil || player.Character.CurrentHP <= 0 {
+		return
+	}
+	if gl.trades[cmd.CharID] != nil {
+		gl.sendToPlayer(player, outclient.BuildSystemMessageNoParams(outclient.SysMsgAlreadyTrading))
+		return
+	}
+	if player.PrivateStore.Type != registry.StoreNone {
+		gl.sendToPlayer(player, outclient.BuildActionFailed())
+		return
+	}
+	target, ok := gl.world.GetPlayer(cmd.TargetObjectID)
+	if !ok || target.Character == nil || target.CharID == cmd.CharID || target.Character.CurrentHP <= 0 {
+		gl.sendToPlayer(player, outclient.BuildSystemMessageNoParams(outclient.SysMsgIncorrectTarget))
+		return
+	}
+	if distanceBetween(player.Position, target.Position) > tradeRange {
+		gl.sendToPlayer(player, outclient.BuildSystemMessageNoParams(outclient.SysMsgTargetTooFar))
+		return
+	}
+	if gl.tradeRequestOutstanding(cmd.CharID) {
+		gl.sendToPlayer(player, outclient.BuildSystemMessageNoParams(outclient.SysMsgWaitingForAnotherReply))
+		return
+	}
+	if gl.trades[target.CharID] != nil {
+		gl.sendToPlayer(player, outclient.NewSystemMessage(outclient.SysMsgC1AlreadyTrading).
+			AddPlayerName(target.Character.Name).Build())
+		return
+	}
+	if _, pending := gl.tradeRequests[target.CharID]; pending || gl.tradeRequestOutstanding(target.CharID) ||
+		target.PrivateStore.Type != registry.StoreNone {
+		gl.sendToPlayer(player, outclient.NewSystemMessage(outclient.SysMsgC1IsBusyTryLater).
+			AddPlayerName(target.Character.Name).Build())
+		return
+	}
+
+	gl.tradeSeq++
+	gl.tradeRequests[target.CharID] = tradeRequest{requesterCharID: cmd.CharID, seq: gl.tradeSeq}
+	gl.sendToPlayer(target, outclient.BuildSendTradeRequest(cmd.CharID))
+	gl.sendToPlayer(player, outclient.NewSystemMessage(outclient.SysMsgRequestC1ForTrade).
+		AddPlayerName(target.Character.Name).Build())
+	gl.events.Schedule(&TradeRequestExpireEvent{
+		At:           time.Now().Add(tradeRequestTimeout),
+		TargetCharID: target.CharID,
+		Seq:          gl.tradeSeq,
+	})
+}
+
+// TradeRequestExpireEvent withdraws an unanswered trade request. Seq guards
+// against a newer request to the same player.
+type TradeRequestExpireEvent struct {
+	At           time.Time
+	TargetCharID int32
+	Seq          int64
+}
+
+func (e *TradeRequestExpireEvent) ExecuteAt() time.Time { return e.At }
+
+func (e *TradeRequestExpireEvent) Execute(gl *GameLoop) {
+	if req, ok := gl.tradeRequests[e.TargetCharID]; ok && req.seq == e.Seq {
+		delete(gl.tradeRequests, e.TargetCharID)
+	}
+}
+
+// handleTradeAnswer resolves AnswerTradeRequest: a decline is relayed to the
+// requester, an accept opens the trade window if both are still able to trade.
+func (gl *GameLoop) handleTradeAnswer(cmd CmdTradeAnswer) {
+	req, ok := gl.tradeRequests[cmd.CharID]
+	if !ok {
+		return
+	}
+	delete(gl.tradeRequests, cmd.CharID)
+
+	player, ok := gl.world.GetPlayer(cmd.CharID)
+	if !ok || player.Character == nil {
+		return
+	}
+	requester, ok := gl.world.GetPlayer(req.requesterCharID)
+	if !ok || requester.Character == nil {
+		gl.sendToPlayer(player, outclient.BuildSystemMessageNoParams(outclient.SysMsgTargetNotFound))
+		return
+	}
+	if !cmd.Accept {
+		gl.sendToPlayer(requester, outclient.NewSystemMessage(outclient.SysMsgC1DeniedTradeRequest).
+			AddPlayerName(player.Character.Name).Build())
+		return
+	}
+	if gl.trades[cmd.CharID] != nil || gl.trades[requester.CharID] != nil {
+		gl.sendToPlayer(player, outclient.BuildSystemMessageNoParams(outclient.SysMsgAlreadyTrading))
+		return
+	}
+	if player.Character.CurrentHP <= 0 || requester.Character.CurrentHP <= 0 ||
+		distanceBetween(player.Position, requester.Position) > tradeRange {
+		gl.sendToPlayer(player, outclient.BuildSystemMessageNoParams(outclient.SysMsgTargetTooFar))
+		return
+	}
+
+	s := &tradeSession{
+		chars: [2]int32{requester.CharID, player.CharID},
+		names: [2]string{requester.Character.Name, player.Character.Name},
+	}
+	gl.trades[requester.CharID] = s
+	gl.trades[player.CharID] = s
+	gl.sendToPlayer(requester, outclient.NewSystemMessage(outclient.SysMsgBeginTradeWithC1).
+		AddPlayerName(player.Character.Name).Build())
+	gl.sendToPlayer(player, outclient.NewSystemMessage(outclient.SysMsgBeginTradeWithC1).
+		AddPlayerName(requester.Character.Name).Build())
+
+	if !gl.enqueueTradeJob(TradeJob{Kind: TradeJobOpen, CharA: requester.CharID, CharB: player.CharID}) {
+		gl.cancelTrade(s, 0)
+	}
+}
+
+// enqueueTradeJob hands work to the trade worker. Non-blocking: with no sink or
+// a full queue it reports false and the caller cancels the trade.
+func (gl *GameLoop) enqueueTradeJob(job TradeJob) bool {
+	if gl.tradeSink == nil {
+		return job.Kind == TradeJobOpen // the window still works without an item list
+	}
+	select {
+	case gl.tradeSink <- job:
+		return true
+	default:
+		log.Warn().Int32("char_a", job.CharA).Int32("char_b", job.CharB).Msg("trade sink full, cancelling trade")
+		return false
+	}
+}
+
+// handleTradeAddItem puts a validated item into the player's side of the window.
+// Offers of the same object accumulate but never exceed what the player owns;
+// nothing can change once either side confirmed.
+func (gl *GameLoop) handleTradeAddItem(cmd CmdTradeAddItem) {
+	s := gl.trades[cmd.CharID]
+	if s == nil || cmd.Count <= 0 {
+		return
+	}
+	if s.confirmed[0] || s.confirmed[1] {
+		gl.sendToChar(cmd.CharID, outclient.BuildSystemMessageNoParams(outclient.SysMsgCannotAdjustItemsAfterConfirm))
+		return
+	}
+	me := s.side(cmd.CharID)
+
+	var line *usecase.ItemTransfer
+	for i := range s.offers[me] {
+		if s.offers[me][i].ObjectID == cmd.Item.ObjectID {
+			line = &s.offers[me][i]
+			break
+		}
+	}
+	if line == nil {
+		s.offers[me] = append(s.offers[me], usecase.ItemTransfer{ObjectID: cmd.Item.ObjectID})
+		line = &s.offers[me][len(s.offers[me])-1]
+	}
+	if line.Count+cmd.Count > cmd.Item.Count {
+		return
+	}
+	line.Count += cmd.Count
+
+	row := tradeItemRow(cmd.Item, line.Count)
+	gl.sendToChar(cmd.CharID, outclient.BuildTradeOwnAdd(row))
+	gl.sendToChar(s.chars[1-me], outclient.BuildTradeOtherAdd(row))
+}
+
+// tradeItemRow is the trade-window view of count units of an item.
+func tradeItemRow(item models.CharacterItem, count int64) outclient.InventoryItem {
+	return outclient.InventoryItem{
+		ObjectID:            item.ObjectID,
+		ItemID:              item.ItemID,
+		LocationSlot:        -1,
+		Count:               count,
+		ItemType:            int32(registry.GetItemType2(item.ItemID)),
+		CustomType1:         int32(item.CustomType1),
+		BodyPart:            registry.GetBodyPartCode(item.ItemID),
+		EnchantLevel:        int32(item.EnchantLevel),
+		CustomType2:         int32(item.CustomType2),
+		AugmentationID:      int32(item.AugmentationID),
+		Mana:                int32(item.ManaLeft),
+		TimeRemaining:       -9999,
+		DefenseElementFire:  int32(item.AttributeFire),
+		DefenseElementWater: int32(item.AttributeWater),
+		DefenseElementWind:  int32(item.AttributeWind),
+		DefenseElementEarth: int32(item.AttributeEarth),
+		DefenseElementHoly:  int32(item.AttributeHoly),
+		DefenseElementDark:  int32(item.AttributeDark),
+	}
+}
+
+// handleTradeDone handles the OK / Cancel buttons. Once both partners confirmed,
+// the session leaves the loop and the swap is handed to the trade worker.
+func (gl *GameLoop) handleTradeDone(cmd CmdTradeDone) {
+	s := gl.trades[cmd.CharID]
+	if s == nil {
+		return
+	}
+	if !cmd.Confirm {
+		gl.cancelTrade(s, cmd.CharID)
+		return
+	}
+	me := s.side(cmd.CharID)
+	if !gl.tradePartnersInRange(s, tradeRange) {
+		gl.sendToChar(cmd.CharID, outclient.BuildSystemMessageNoParams(outclient.SysMsgTargetTooFar))
+		gl.cancelTrade(s, cmd.CharID)
+		return
+	}
+	if s.confirmed[me] {
+		return
+	}
+	s.confirmed[me] = true
+	other := s.chars[1-me]
+	gl.sendToChar(other, outclient.BuildTradeOtherDone())
+	gl.sendToChar(other, outclient.NewSystemMessage(outclient.SysMsgC1ConfirmedTrade).
+		AddPlayerName(s.names[me]).Build())
+	if !s.confirmed[1-me] {
+		return
+	}
+
+	delete(gl.trades, s.chars[0])
+	delete(gl.trades, s.chars[1])
+	job := TradeJob{Kind: TradeJobCommit, CharA: s.chars[0], CharB: s.chars[1], OfferA: s.offers[0], OfferB: s.offers[1]}
+	if a, ok := gl.world.GetPlayer(s.chars[0]); ok {
+		job.SlotsA = usecase.InventoryLimit(a.Character)
+	}
+	if b, ok := gl.world.GetPlayer(s.chars[1]); ok {
+		job.SlotsB = usecase.InventoryLimit(b.Character)
+	}
+	if !gl.enqueueTradeJob(job) {
+		gl.sendToChar(s.chars[0], outclient.BuildTradeDone(0))
+		gl.sendToChar(s.chars[1], outclient.BuildTradeDone(0))
+	}
+}
+
+// tradePartnersInRange reports whether both partners are online, alive and
+// within radius of each other.
+func (gl *GameLoop) tradePartnersInRange(s *tradeSession, radius float64) bool {
+	a, okA := gl.world.GetPlayer(s.chars[0])
+	b, okB := gl.world.GetPlayer(s.chars[1])
+	if !okA || !okB || a.Character == nil || b.Character == nil {
+		return false
+	}
+	if a.Character.CurrentHP <= 0 || b.Character.CurrentHP <= 0 {
+		return false
+	}
+	return distanceBetween(a.Position, b.Position) <= radius
+}
+
+// cancelTrade closes the window on both sides. byCharID is the partner that
+// cancelled (the other one is told so); 0 when the server aborted it.
+func (gl *GameLoop) cancelTrade(s *tradeSession, byCharID int32) {
+	delete(gl.trades, s.chars[0])
+	delete(gl.trades, s.chars[1])
+	for i, id := range s.chars {
+		gl.sendToChar(id, outclient.BuildTradeDone(0))
+		if byCharID != 0 && id != byCharID {
+			gl.sendToChar(id, outclient.NewSystemMessage(outclient.SysMsgC1CanceledTrade).
+				AddPlayerName(s.names[1-i]).Build())
+		}
+	}
+}
+
+// cancelTradeOf aborts charID's open trade, if any (death, disconnect).
+func (gl *GameLoop) cancelTradeOf(charID int32) {
+	if s := gl.trades[charID]; s != nil {
+		gl.cancelTrade(s, charID)
+	}
+}
+
+// leaveTradeOnDisconnect cancels the player's open trade and drops trade
+// requests to or from it. Uses only the session's bookkeeping: the handler may
+// already have removed the player from the world.
+func (gl *GameLoop) leaveTradeOnDisconnect(charID int32) {
+	gl.cancelTradeOf(charID)
+	delete(gl.tradeRequests, charID)
+	for target, req := range gl.tradeRequests {
+		if req.requesterCharID == charID {
+			delete(gl.tradeRequests, target)
+		}
+	}
+}
+
+// checkTrades cancels open trades whose partners died, left or walked apart.
+func (gl *GameLoop) checkTrades() {
+	for charID, s := range gl.trades {
+		if s.chars[0] != charID {
+			continue // visit each session once
+		}
+		if !gl.tradePartnersInRange(s, tradeLeashRange) {
+			gl.cancelTrade(s, 0)
+		}
+	}
+}
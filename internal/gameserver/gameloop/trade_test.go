@@ -0,0 +1,141 @@
+package gameloop
+
+import (
+	"context"
+	"testing"
+
+	"github.com/VerTox/l2go/internal/gameserver/models"
+	"github.com/VerTox/l2go/internal/gameserver/packets/outclient"
+	"github.com/VerTox/l2go/internal/gameserver/usecase"
+)
+
+// openTrade starts a trade between char 7 and char 8 (both already in the world)
+// and returns the sink the loop hands trade work to.
+func openTrade(t *testing.T, gl *GameLoop) chan TradeJob {
+	t.Helper()
+	sink := make(chan TradeJob, 4)
+	gl.SetTradeSink(sink)
+	gl.handleTradeRequest(CmdTradeRequest{CharID: 7, TargetObjectID: 8})
+	if _, pending := gl.tradeRequests[8]; !pending {
+		t.Fatal("trade request should be pending")
+	}
+	gl.handleTradeAnswer(CmdTradeAnswer{CharID: 8, Accept: true})
+	if gl.trades[7] == nil || gl.trades[7] != gl.trades[8] {
+		t.Fatal("accepted request should open a shared session")
+	}
+	if job := <-sink; job.Kind != TradeJobOpen || job.CharA != 7 || job.CharB != 8 {
+		t.Fatalf("open job = %+v", job)
+	}
+	return sink
+}
+
+func TestTrade_NegotiateAndCommit(t *testing.T) {
+	gl, _ := newTestLoopWithPlayer(t)
+	addPlayer(t, gl, 8, "acc2", models.Position{X: 100})
+	conn, rec := newRecordingConn(t)
+	gl.connections.Register("acc2", conn)
+	sink := openTrade(t, gl)
+
+	sword := models.CharacterItem{ObjectID: 500, OwnerID: 7, ItemID: 1, Count: 1}
+	adena := models.CharacterItem{ObjectID: 600, OwnerID: 8, ItemID: 57, Count: 1000}
+	gl.handleTradeAddItem(CmdTradeAddItem{CharID: 7, Item: sword, Count: 1})
+	gl.handleTradeAddItem(CmdTradeAddItem{CharID: 8, Item: adena, Count: 300})
+	gl.handleTradeAddItem(CmdTradeAddItem{CharID: 8, Item: adena, Count: 200})
+	// Offering more than owned in total is ignored.
+	gl.handleTradeAddItem(CmdTradeAddItem{CharID: 8, Item: adena, Count: 600})
+
+	if !eventually(func() bool { return rec.contains(outclient.BuildTradeOtherAdd(tradeItemRow(sword, 1))) }) {
+		t.Fatal("partner should see the offered sword")
+	}
+
+	gl.handleTradeDone(CmdTradeDone{CharID: 7, Confirm: true})
+	if !eventually(func() bool { return rec.contains(outclient.BuildTradeOtherDone()) }) {
+		t.Fatal("partner should be told the trade was confirmed")
+	}
+	// Confirmed: the window is frozen.
+	gl.handleTradeAddItem(CmdTradeAddItem{CharID: 8, Item: adena, Count: 1})
+	gl.handleTradeDone(CmdTradeDone{CharID: 8, Confirm: true})
+
+	if len(gl.trades) != 0 {
+		t.Fatal("a committed trade must leave the loop")
+	}
+	job := <-sink
+	if job.Kind != TradeJobCommit {
+		t.Fatalf("job = %+v, want commit", job)
+	}
+	wantA := []usecase.ItemTransfer{{ObjectID: 500, Count: 1}}
+	wantB := []usecase.ItemTransfer{{ObjectID: 600, Count: 500}}
+	if len(job.OfferA) != 1 || job.OfferA[0] != wantA[0] || len(job.OfferB) != 1 || job.OfferB[0] != wantB[0] {
+		t.Fatalf("offers = %+v / %+v, want %+v / %+v", job.OfferA, job.OfferB, wantA, wantB)
+	}
+	if job.SlotsA != usecase.InventorySlots || job.SlotsB != usecase.InventorySlots {
+		t.Errorf("slots = %d/%d, want %d each", job.SlotsA, job.SlotsB, usecase.InventorySlots)
+	}
+}
+
+func TestTrade_RequestRejections(t *testing.T) {
+	gl, _ := newTestLoopWithPlayer(t)
+	addPlayer(t, gl, 8, "acc2", models.Position{X: 100})
+	addPlayer(t, gl, 9, "acc3", models.Position{X: 1000})
+	conn, rec := newRecordingConn(t)
+	gl.connections.Register("acc", conn)
+
+	gl.handleTradeRequest(CmdTradeRequest{CharID: 7, TargetObjectID: 9})
+	if !eventually(func() bool { return rec.contains(outclient.BuildSystemMessageNoParams(outclient.SysMsgTargetTooFar)) }) {
+		t.Fatal("a distant target should be refused")
+	}
+	gl.handleTradeRequest(CmdTradeRequest{CharID: 7, TargetObjectID: 7})
+	if len(gl.tradeRequests) != 0 {
+		t.Fatal("no request may be pending after refusals")
+	}
+
+	gl.handleTradeRequest(CmdTradeRequest{CharID: 7, TargetObjectID: 8})
+	gl.handleTradeAnswer(CmdTradeAnswer{CharID: 8, Accept: false})
+	want := outclient.NewSystemMessage(outclient.SysMsgC1DeniedTradeRequest).AddPlayerName("acc2").Build()
+	if !eventually(func() bool { return rec.contains(want) }) {
+		t.Fatal("requester should learn the request was denied")
+	}
+	if len(gl.trades) != 0 {
+		t.Fatal("a declined request must not open a trade")
+	}
+}
+
+func TestTrade_DeathDisconnectAndDistanceCancel(t *testing.T) {
+	gl, _ := newTestLoopWithPlayer(t)
+	addPlayer(t, gl, 8, "acc2", models.Position{X: 100})
+	conn, rec := newRecordingConn(t)
+	gl.connections.Register("acc2", conn)
+
+	// Death.
+	openTrade(t, gl)
+	player, _ := gl.world.GetPlayer(7)
+	gl.handlePlayerDeath(7, player)
+	if len(gl.trades) != 0 {
+		t.Fatal("death should cancel the trade")
+	}
+	if !eventually(func() bool { return rec.contains(outclient.BuildTradeDone(0)) }) {
+		t.Fatal("partner window should close")
+	}
+	player.Character.CurrentHP = 100
+
+	// Walking apart.
+	openTrade(t, gl)
+	_ = gl.world.UpdatePlayerPosition(context.Background(), 8, models.Position{X: 5000}, 0)
+	gl.checkTrades()
+	if len(gl.trades) != 0 {
+		t.Fatal("partners out of range should have the trade cancelled")
+	}
+	_ = gl.world.UpdatePlayerPosition(context.Background(), 8, models.Position{X: 100}, 0)
+
+	// Disconnect after the handler already removed the player from the world.
+	openTrade(t, gl)
+	_ = gl.world.RemovePlayer(context.Background(), 7)
+	gl.handlePlayerDisconnected(CmdPlayerDisconnected{CharID: 7})
+	if len(gl.trades) != 0 {
+		t.Fatal("disconnect should cancel the trade")
+	}
+	want := outclient.NewSystemMessage(outclient.SysMsgC1CanceledTrade).AddPlayerName("Tester").Build()
+	if !eventually(func() bool { return rec.contains(want) }) {
+		t.Fatal("partner should be told who cancelled")
+	}
+}
@@ -23,6 +23,12 @@ func buildPlayerCharInfo(player *registry.PlayerWorldState) []byte {
 		player.InCombat,
 		player.Heading,
 	)
+	// Store sign and sitting are loop-owned live state, so every (re)spawn shows
+	// an open store as it is.
+	ci.PrivateStoreType = int32(player.PrivateStore.Type)
+	if player.Sitting {
+		ci.Sitting = 1
+	}
 	return ci.GetData()
 }
 
@@ -36,18 +42,23 @@ func (gl *GameLoop) sendToPlayer(player *registry.PlayerWorldState, data []byte)
 }
 
 // spawnPlayerTo shows `spawned` to `viewer`: CharInfo plus a RelationChanged so the
-// client renders a normal (non-attackable) cursor instead of the sword cursor.
+// client renders a normal (non-attackable) cursor instead of the sword cursor, and
+// the store title when `spawned` runs a private store.
 func (gl *GameLoop) spawnPlayerTo(viewer, spawned *registry.PlayerWorldState) {
 	gl.sendToPlayer(viewer, buildPlayerCharInfo(spawned))
 	relation := outclient.NewSingleRelation(spawned.CharID, int32(spawned.Character.Karma), 0)
 	gl.sendToPlayer(viewer, relation.GetData())
+	if msg := storeMsgPacket(spawned); msg != nil {
+		gl.sendToPlayer(viewer, msg)
+	}
 }
 
 // reconcilePlayerVisibility brings the moving player's player-to-player visibility
 // up to date: spawns (CharInfo) players newly in range and despawns (DeleteObject)
 // players that left range — bidirectionally, so a stationary player also sees the
-// mover appear/disappear. Runs only on the game-loop goroutine, which is the sole
-// owner of every player's KnownPlayers set, so no locking is required. (l2go-23g)
+// mover appear/disappear. Ground items in and out of the mover's range follow.
+// Runs only on the game-loop goroutine, which is the sole owner of every player's
+// KnownPlayers and KnownItems sets, so no locking is required. (l2go-23g)
 func (gl *GameLoop) reconcilePlayerVisibility(charID int32) {
 	mover, ok := gl.world.GetPlayer(charID)
 	if !ok {
@@ -70,7 +81,7 @@ func (gl *GameLoop) reconcilePlayerVisibility(charID int32) {
 	// during a mass spawn (the O(N^2) the whole crowd pays). Built lazily so a reconcile
 	// that spawns nobody new pays nothing. Reuse is safe: conn.Send copies the bytes
 	// before its in-place XOR, so the shared slice is never mutated. (l2go-795)
-	var moverCharInfo, moverRelation []byte
+	var moverCharInfo, moverRelation, moverStoreMsg []byte
 
 	// Entering range (within watch): spawn each side to the other exactly once.
 	for _, other := range gl.world.GetPlayersInRange(mover.Position, registry.VisibilityWatchRadius) {
@@ -85,9 +96,13 @@ func (gl *GameLoop) reconcilePlayerVisibility(charID int32) {
 			if moverCharInfo == nil {
 				moverCharInfo = buildPlayerCharInfo(mover)
 				moverRelation = outclient.NewSingleRelation(mover.CharID, int32(mover.Character.Karma), 0).GetData()
+				moverStoreMsg = storeMsgPacket(mover)
 			}
 			gl.sendToPlayer(other, moverCharInfo)
 			gl.sendToPlayer(other, moverRelation)
+			if moverStoreMsg != nil {
+				gl.sendToPlayer(other, moverStoreMsg)
+			}
 			other.KnownPlayers[charID] = true
 		}
 	}
@@ -105,6 +120,8 @@ func (gl *GameLoop) reconcilePlayerVisibility(charID int32) {
 			delete(other.KnownPlayers, charID)
 		}
 	}
+
+	gl.reconcileItemVisibility(mover)
 }
 
 // despawnPlayerFromAll sends DeleteObject for a leaving player to everyone who had
@@ -0,0 +1,162 @@
+package gameloop
+
+import (
+	"github.com/rs/zerolog/log"
+
+	"github.com/VerTox/l2go/internal/gameserver/models"
+	"github.com/VerTox/l2go/internal/gameserver/packets/outclient"
+	"github.com/VerTox/l2go/internal/gameserver/registry"
+	"github.com/VerTox/l2go/internal/gameserver/usecase"
+)
+
+// warehouseNpcType is the datapack NPC type of warehouse keepers.
+const warehouseNpcType = "L2Warehouse"
+
+func init() {
+	for _, name := range []string{BypassDepositPrivate, BypassWithdrawPrivate, BypassDepositClan, BypassWithdrawClan} {
+		clan := name == BypassDepositClan || name == BypassWithdrawClan
+		deposit := name == BypassDepositPrivate || name == BypassDepositClan
+		RegisterBypass(name, func(gl *GameLoop, player *registry.PlayerWorldState, npcObjID int32, _ string) {
+			gl.handleWarehouseOpen(CmdWarehouseOpen{CharID: player.CharID, NpcObjID: npcObjID, Clan: clan, Deposit: deposit})
+		})
+	}
+}
+
+// WarehouseJobKind selects what the warehouse worker does with a WarehouseJob.
+type WarehouseJobKind int
+
+const (
+	// WarehouseJobDepositList sends the WareHouseDepositList window.
+	WarehouseJobDepositList WarehouseJobKind = iota
+	// WarehouseJobWithdrawList sends the WareHouseWithdrawalList window.
+	WarehouseJobWithdrawList
+	// WarehouseJobDeposit moves Items from the inventory into Warehouse.
+	WarehouseJobDeposit
+	// WarehouseJobWithdraw moves Items from Warehouse into the inventory.
+	WarehouseJobWithdraw
+)
+
+// WarehouseJob is warehouse work that needs the database and therefore runs
+// off the loop on the warehouse worker. The loop has already checked that the
+// player stands at a keeper with this warehouse open.
+type WarehouseJob struct {
+	Kind           WarehouseJobKind
+	CharID         int32
+	Warehouse      usecase.Warehouse
+	InventorySlots int
+	Items          []usecase.ItemTransfer
+}
+
+// SetWarehouseSink wires the channel that receives warehouse work. Kept out of
+// New() like the other optional sinks; without it keepers open nothing.
+func (gl *GameLoop) SetWarehouseSink(sink chan<- WarehouseJob) {
+	gl.warehouseSink = sink
+}
+
+// enqueueWarehouseJob hands work to the warehouse worker. Non-blocking: with
+// no sink or a full queue the job is dropped.
+func (gl *GameLoop) enqueueWarehouseJob(job WarehouseJob) {
+	if gl.warehouseSink == nil {
+		return
+	}
+	select {
+	case gl.warehouseSink <- job:
+	default:
+		log.Warn().Int32("char_id", job.CharID).Msg("warehouse sink full, dropping warehouse job")
+	}
+}
+
+// IsWarehouseKeeper reports whether an NPC template is a warehouse keeper.
+func IsWarehouseKeeper(tmpl *models.NpcTemplate) bool {
+	return tmpl != nil && tmpl.Type == warehouseNpcType
+}
+
+// warehouseOf resolves which warehouse a player reaches through a keeper: its
+// own, or its clan's. A player without a clan has no clan warehouse.
+func warehouseOf(player *registry.PlayerWorldState, clan bool) (usecase.Warehouse, bool) {
+	if !clan {
+		return usecase.PersonalWarehouse(player.Character), true
+	}
+	if !player.Character.HasClan() {
+		return usecase.Warehouse{}, false
+	}
+	return usecase.ClanWarehouse(int32(player.Character.ClanID)), true
+}
+
+// warehouseUser returns the player if it may use a warehouse at npcObjID right
+// now: alive, not trading or running a store, and within interact range of a
+// warehouse keeper (L2J WarehouseInstance / SendWareHouseDepositList checks).
+func (gl *GameLoop) warehouseUser(charID, npcObjID int32) (*registry.PlayerWorldState, bool) {
+	player, ok := gl.world.GetPlayer(charID)
+	if !ok || player.Character == nil || player.Character.CurrentHP <= 0 {
+		return nil, false
+	}
+	if gl.trades[charID] != nil {
+		gl.sendToPlayer(player, outclient.BuildSystemMessageNoParams(outclient.SysMsgAlreadyTrading))
+		return nil, false
+	}
+	if player.PrivateStore.Type != registry.StoreNone {
+		gl.sendToPlayer(player, outclient.BuildActionFailed())
+		return nil, false
+	}
+	npc, ok := gl.world.GetNPC(npcObjID)
+	if !ok || npc.IsDead || !IsWarehouseKeeper(npc.Template) {
+		return nil, false
+	}
+	dx := player.Position.X - npc.Position.X
+	dy := player.Position.Y - npc.Position.Y
+	if dx*dx+dy*dy > interactRange*interactRange {
+		return nil, false
+	}
+	return player, true
+}
+
+// handleWarehouseOpen remembers which warehouse the player opened at the
+// keeper and has the worker send the deposit or withdrawal window.
+func (gl *GameLoop) handleWarehouseOpen(cmd CmdWarehouseOpen) {
+	player, ok := gl.warehouseUser(cmd.CharID, cmd.NpcObjID)
+	if !ok {
+		return
+	}
+	wh, ok := warehouseOf(player, cmd.Clan)
+	if !ok {
+		gl.sendToPlayer(player, outclient.BuildActionFailed())
+		return
+	}
+	player.Warehouse = registry.ActiveWarehouse{NpcObjID: cmd.NpcObjID, Clan: cmd.Clan}
+
+	kind := WarehouseJobWithdrawList
+	if cmd.Deposit {
+		kind = WarehouseJobDepositList
+	}
+	gl.enqueueWarehouseJob(WarehouseJob{Kind: kind, CharID: cmd.CharID, Warehouse: wh})
+}
+
+// handleWarehouseTransfer hands a confirmed deposit or withdrawal to the worker
+// once the player is still at the keeper that opened its warehouse.
+func (gl *GameLoop) handleWarehouseTransfer(cmd CmdWarehouseTransfer) {
+	player, ok := gl.world.GetPlayer(cmd.CharID)
+	if !ok || player.Warehouse.NpcObjID == 0 || len(cmd.Items) == 0 {
+		return
+	}
+	if _, ok := gl.warehouseUser(cmd.CharID, player.Warehouse.NpcObjID); !ok {
+		return
+	}
+	wh, ok := warehouseOf(player, player.Warehouse.Clan)
+	if !ok {
+		player.Warehouse = registry.ActiveWarehouse{}
+		return
+	}
+
+	kind := WarehouseJobWithdraw
+	if cmd.Deposit {
+		kind = WarehouseJobDeposit
+	}
+	gl.enqueueWarehouseJob(WarehouseJob{
+		Kind:           kind,
+		CharID:         cmd.CharID,
+		Warehouse:      wh,
+		InventorySlots: usecase.InventoryLimit(player.Character),
+		Items:          cmd.Items,
+	})
+}
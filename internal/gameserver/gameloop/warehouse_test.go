@@ -0,0 +1,81 @@
+package gameloop
+
+import (
+	"testing"
+
+	"github.com/VerTox/l2go/internal/gameserver/models"
+	"github.com/VerTox/l2go/internal/gameserver/registry"
+	"github.com/VerTox/l2go/internal/gameserver/usecase"
+)
+
+func addWarehouseKeeper(gl *GameLoop, objectID int32, pos models.Position) {
+	gl.world.AddNPC(&models.NpcInstance{
+		ObjectID:  objectID,
+		Position:  pos,
+		CurrentHP: 100,
+		Template:  &models.NpcTemplate{ID: 30005, Name: "Keeper", Type: "L2Warehouse"},
+	})
+}
+
+func TestWarehouse_OpenAtKeeper(t *testing.T) {
+	gl, p := newTestLoopWithPlayer(t)
+	sink := make(chan WarehouseJob, 4)
+	gl.SetWarehouseSink(sink)
+	addWarehouseKeeper(gl, 2000, models.Position{X: 100})
+	addAttackableNPC(gl, 2001, models.Position{X: 50})
+
+	// Only a warehouse keeper within reach opens anything.
+	gl.handleWarehouseOpen(CmdWarehouseOpen{CharID: 7, NpcObjID: 2001, Deposit: true})
+	if len(sink) != 0 {
+		t.Fatal("a monster is no warehouse keeper")
+	}
+
+	gl.handleWarehouseOpen(CmdWarehouseOpen{CharID: 7, NpcObjID: 2000, Deposit: true})
+	job := <-sink
+	if job.Kind != WarehouseJobDepositList || job.Warehouse.OwnerID != 7 || job.Warehouse.Loc != models.LocWarehouse {
+		t.Fatalf("open job = %+v", job)
+	}
+	if p.Warehouse != (registry.ActiveWarehouse{NpcObjID: 2000}) {
+		t.Fatalf("active warehouse = %+v", p.Warehouse)
+	}
+
+	// No clan, no clan warehouse.
+	gl.handleWarehouseOpen(CmdWarehouseOpen{CharID: 7, NpcObjID: 2000, Clan: true})
+	if len(sink) != 0 {
+		t.Fatal("a clanless player has no clan warehouse")
+	}
+	p.Character.ClanID = 3
+	gl.handleWarehouseOpen(CmdWarehouseOpen{CharID: 7, NpcObjID: 2000, Clan: true})
+	if job := <-sink; job.Kind != WarehouseJobWithdrawList || job.Warehouse.OwnerID != 3 || job.Warehouse.Loc != models.LocClanWH {
+		t.Fatalf("clan open job = %+v", job)
+	}
+}
+
+func TestWarehouse_TransferNeedsKeeperInReach(t *testing.T) {
+	gl, p := newTestLoopWithPlayer(t)
+	sink := make(chan WarehouseJob, 4)
+	gl.SetWarehouseSink(sink)
+	addWarehouseKeeper(gl, 2000, models.Position{X: 100})
+	items := []usecase.ItemTransfer{{ObjectID: 500, Count: 1}}
+
+	// Nothing is open yet.
+	gl.handleWarehouseTransfer(CmdWarehouseTransfer{CharID: 7, Deposit: true, Items: items})
+	if len(sink) != 0 {
+		t.Fatal("a transfer without an open warehouse must be ignored")
+	}
+
+	gl.handleWarehouseOpen(CmdWarehouseOpen{CharID: 7, NpcObjID: 2000, Deposit: true})
+	<-sink
+	gl.handleWarehouseTransfer(CmdWarehouseTransfer{CharID: 7, Deposit: true, Items: items})
+	job := <-sink
+	if job.Kind != WarehouseJobDeposit || len(job.Items) != 1 || job.InventorySlots != usecase.InventorySlots {
+		t.Fatalf("deposit job = %+v", job)
+	}
+
+	// Walking away from the keeper closes the deal.
+	p.Position = models.Position{X: 1000}
+	gl.handleWarehouseTransfer(CmdWarehouseTransfer{CharID: 7, Items: items})
+	if len(sink) != 0 {
+		t.Fatal("a transfer away from the keeper must be ignored")
+	}
+}
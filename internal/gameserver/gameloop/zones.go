@@ -0,0 +1,132 @@
+package gameloop
+
+import (
+	"slices"
+
+	"github.com/VerTox/l2go/internal/gameserver/models"
+	"github.com/VerTox/l2go/internal/gameserver/packets/outclient"
+)
+
+// ZoneListener is told that a player entered (entered true) or left a zone.
+// Listeners run on the loop goroutine, in the order they were added, and may
+// use any loop state.
+type ZoneListener func(charID int32, zone *models.Zone, entered bool)
+
+// OnZoneChange adds a listener for players entering and leaving zones.
+func (gl *GameLoop) OnZoneChange(l ZoneListener) {
+	gl.zoneListeners = append(gl.zoneListeners, l)
+}
+
+// revalidatePlayerZones brings a player's zones up to date with its position
+// (L2J L2Character.revalidateZone): every zone it left is reported first,
+// then every zone it entered.
+func (gl *GameLoop) revalidatePlayerZones(charID int32) {
+	if gl.zoneRegistry == nil {
+		return
+	}
+	player, ok := gl.world.GetPlayer(charID)
+	if !ok {
+		return
+	}
+	old := gl.playerZones[charID]
+	now := gl.zoneRegistry.ZonesAt(player.Position)
+	if len(now) == 0 {
+		delete(gl.playerZones, charID)
+	} else {
+		gl.playerZones[charID] = now
+	}
+	for _, z := range old {
+		if !slices.Contains(now, z) {
+			gl.notifyZoneChange(charID, z, false)
+		}
+	}
+	for _, z := range now {
+		if !slices.Contains(old, z) {
+			gl.notifyZoneChange(charID, z, true)
+		}
+	}
+}
+
+// leavePlayerZones reports a disconnecting player leaving every zone it stood
+// in and stops tracking it.
+func (gl *GameLoop) leavePlayerZones(charID int32) {
+	old := gl.playerZones[charID]
+	delete(gl.playerZones, charID)
+	delete(gl.compassZones, charID) // no compass update for a leaving client
+	for _, z := range old {
+		gl.notifyZoneChange(charID, z, false)
+	}
+}
+
+func (gl *GameLoop) notifyZoneChange(charID int32, zone *models.Zone, entered bool) {
+	for _, l := range gl.zoneListeners {
+		l(charID, zone, entered)
+	}
+}
+
+// isInsideZone reports whether a player stands in a zone of the given type.
+func (gl *GameLoop) isInsideZone(charID int32, t models.ZoneType) bool {
+	for _, z := range gl.playerZones[charID] {
+		if z.Type == t {
+			return true
+		}
+	}
+	return false
+}
+
+// isPeaceZone reports whether a zone forbids fighting between players: peace
+// zones and towns (L2J ZoneId.PEACE).
+func isPeaceZone(z *models.Zone) bool {
+	return z.Type == models.ZonePeace || z.Type == models.ZoneTown
+}
+
+// inPeaceZone reports whether a player stands where players may not fight.
+func (gl *GameLoop) inPeaceZone(charID int32) bool {
+	for _, z := range gl.playerZones[charID] {
+		if isPeaceZone(z) {
+			return true
+		}
+	}
+	return false
+}
+
+// inPvPZone reports whether a player stands in a PvP arena, where fights carry
+// no PvP flag and no karma (L2J ZoneId.PVP). Siege zones join them once the
+// server runs sieges; until then they are plain ground.
+func (gl *GameLoop) inPvPZone(charID int32) bool {
+	return gl.isInsideZone(charID, models.ZoneArena)
+}
+
+// compassZoneCode is the ExSetCompassZoneCode kind of where a player stands,
+// by L2J L2PcInstance.revalidateZone's precedence.
+func (gl *GameLoop) compassZoneCode(charID int32) int32 {
+	switch {
+	case gl.inPvPZone(charID):
+		return outclient.CompassZonePvP
+	case gl.inPeaceZone(charID):
+		return outclient.CompassZonePeace
+	}
+	return outclient.CompassZoneGeneral
+}
+
+// updateCompassZone is the ZoneListener sending ExSetCompassZoneCode when a
+// player crosses into a zone of another kind. Players outside every zone
+// are in the general zone the client starts with.
+func (gl *GameLoop) updateCompassZone(charID int32, _ *models.Zone, _ bool) {
+	code := gl.compassZoneCode(charID)
+	last, ok := gl.compassZones[charID]
+	if !ok {
+		last = outclient.CompassZoneGeneral
+	}
+	if code == last {
+		return
+	}
+	if code == outclient.CompassZoneGeneral {
+		delete(gl.compassZones, charID)
+	} else {
+		gl.compassZones[charID] = code
+	}
+	if player, ok := gl.world.GetPlayer(charID); ok {
+		gl.sendToPlayer(player, outclient.BuildExSetCompassZoneCode(code))
+	}
+}
@@ -0,0 +1,83 @@
+package gameloop
+
+import (
+	"fmt"
+	"slices"
+	"testing"
+
+	"github.com/VerTox/l2go/internal/gameserver/models"
+	"github.com/VerTox/l2go/internal/gameserver/packets/outclient"
+	"github.com/VerTox/l2go/internal/gameserver/registry"
+)
+
+func TestZones_EnterAndLeave(t *testing.T) {
+	gl, _ := newTestLoopWithPlayer(t)
+	gl.zoneRegistry = registry.NewZoneRegistry()
+	gl.zoneRegistry.Add(&models.Zone{Name: "town", Type: models.ZonePeace,
+		Shape: models.ZoneCuboid{MinX: 1000, MinY: -500, MaxX: 3000, MaxY: 500, MinZ: -100, MaxZ: 100}})
+	gl.zoneRegistry.Add(&models.Zone{Name: "well", Type: models.ZoneWater,
+		Shape: models.ZoneCylinder{X: 2000, Radius: 200, MinZ: -100, MaxZ: 100}})
+
+	var got []string
+	gl.OnZoneChange(func(charID int32, z *models.Zone, entered bool) {
+		got = append(got, fmt.Sprintf("%d %s %v", charID, z.Name, entered))
+	})
+	moveTo := func(x int) {
+		gl.handlePlayerMoved(CmdPlayerMoved{CharID: 7, Position: models.Position{X: x}})
+	}
+	expect := func(step string, want ...string) {
+		t.Helper()
+		if !slices.Equal(got, want) {
+			t.Errorf("%s: events = %q, want %q", step, got, want)
+		}
+		got = nil
+	}
+
+	moveTo(1500)
+	expect("into town", "7 town true")
+	if !gl.isInsideZone(7, models.ZonePeace) || gl.isInsideZone(7, models.ZoneWater) {
+		t.Error("zone membership wrong in town")
+	}
+	moveTo(1600)
+	expect("within town")
+	moveTo(2000)
+	expect("into the well", "7 well true")
+	moveTo(4000)
+	expect("out of both", "7 town false", "7 well false")
+	if gl.isInsideZone(7, models.ZonePeace) {
+		t.Error("still in town after leaving it")
+	}
+
+	moveTo(2000)
+	got = nil
+	gl.handlePlayerDisconnected(CmdPlayerDisconnected{CharID: 7})
+	expect("disconnect", "7 town false", "7 well false")
+	if _, tracked := gl.playerZones[7]; tracked {
+		t.Error("a disconnected player is still tracked")
+	}
+}
+
+func TestZones_CompassCode(t *testing.T) {
+	gl, _ := newTestLoopWithPlayer(t)
+	addPvPZones(gl)
+	compass := func(x int) (int32, bool) {
+		gl.handlePlayerMoved(CmdPlayerMoved{CharID: 7, Position: models.Position{X: x}})
+		code, ok := gl.compassZones[7]
+		return code, ok
+	}
+
+	if code, _ := compass(0); code != outclient.CompassZonePeace {
+		t.Errorf("town compass = %#x", code)
+	}
+	if code, _ := compass(5500); code != outclient.CompassZonePvP {
+		t.Errorf("arena compass = %#x", code)
+	}
+	if _, ok := compass(3000); ok {
+		t.Error("open ground must be the general zone")
+	}
+	compass(0)
+	gl.handlePlayerDisconnected(CmdPlayerDisconnected{CharID: 7})
+	if _, ok := gl.compassZones[7]; ok {
+		t.Error("a disconnected player's compass is still tracked")
+	}
+}
@@ -0,0 +1,356 @@
+// Package geodata answers questions about the world's terrain from the L2J and
+// L2OFF geodata files: the ground height at a point, whether one character can
+// see another, and whether it can walk straight to a point. Where a region has
+// no geodata, the world is taken as open and flat: heights stay as given and
+// every line is clear.
+package geodata
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"sync"
+
+	"github.com/rs/zerolog/log"
+
+	"github.com/VerTox/l2go/internal/gameserver/models"
+)
+
+const (
+	// worldMinX and worldMinY are the world's corner: region 11_10's (L2J
+	// L2World.MAP_MIN_X/Y).
+	worldMinX = -294912
+	worldMinY = -262144
+	// firstRegionX and firstRegionY number the regions at the world's corner.
+	firstRegionX = 11
+	firstRegionY = 10
+
+	// maxSeeOverHeight is how far the ground may rise above the line of sight
+	// before it blocks it (L2J GeoData.MAX_SEE_OVER_HEIGHT).
+	maxSeeOverHeight = 48
+	// elevatedSeeOverDistance is how many cells from the viewer the line of
+	// sight is taken at the viewer's height, so standing on a slope does not
+	// hide the ground right ahead.
+	elevatedSeeOverDistance = 2
+)
+
+// Engine holds the loaded geodata regions. Regions are read-only once loaded.
+type Engine struct {
+	mu      sync.RWMutex
+	regions map[[2]int]*region
+	loaded  bool
+}
+
+// NewEngine creates an engine with no regions: everything is open ground.
+func NewEngine() *Engine {
+	return &Engine{regions: make(map[[2]int]*region)}
+}
+
+// Global geodata engine instance.
+var engine = NewEngine()
+
+// GetEngine returns the global geodata engine.
+func GetEngine() *Engine { return engine }
+
+// IsLoaded reports whether geodata has been loaded.
+func (e *Engine) IsLoaded() bool {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+	return e.loaded
+}
+
+// RegionCount returns the number of regions with geodata.
+func (e *Engine) RegionCount() int {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+	return len(e.regions)
+}
+
+var (
+	l2jFileName   = regexp.MustCompile(`^(\d+)_(\d+)\.l2j$`)
+	l2offFileName = regexp.MustCompile(`^(\d+)_(\d+)_conv\.dat$`)
+)
+
+// LoadFromDirectory reads the region files in dir: XX_YY.l2j in the L2J format
+// and XX_YY_conv.dat in the L2OFF one, the L2J file winning when a region has
+// both. A file that fails to parse is skipped with a warning, leaving its
+// region open.
+func (e *Engine) LoadFromDirectory(dir string) error {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return fmt.Errorf("read geodata dir: %w", err)
+	}
+	loaded := make(map[[2]int]*region)
+	for _, format := range []struct {
+		name  *regexp.Regexp
+		parse func([]byte) (*region, error)
+	}{{l2jFileName, parseL2J}, {l2offFileName, parseL2OFF}} {
+		for _, entry := range entries {
+			m := format.name.FindStringSubmatch(entry.Name())
+			if m == nil || entry.IsDir() {
+				continue
+			}
+			rx, _ := strconv.Atoi(m[1])
+			ry, _ := strconv.Atoi(m[2])
+			key := [2]int{rx, ry}
+			if _, dup := loaded[key]; dup {
+				continue
+			}
+			path := filepath.Join(dir, entry.Name())
+			data, err := os.ReadFile(path)
+			if err != nil {
+				log.Warn().Err(err).Str("file", path).Msg("geodata: read failed")
+				continue
+			}
+			reg, err := format.parse(data)
+			if err != nil {
+				log.Warn().Err(err).Str("file", path).Msg("geodata: parse failed")
+				continue
+			}
+			loaded[key] = reg
+		}
+	}
+
+	e.mu.Lock()
+	for key, reg := range loaded {
+		e.regions[key] = reg
+	}
+	e.loaded = true
+	e.mu.Unlock()
+	log.Info().Int("regions", len(loaded)).Msg("Loaded geodata")
+	return nil
+}
+
+// geoX and geoY turn world coordinates into geodata cell coordinates.
+func geoX(x int) int { return (x - worldMinX) >> 4 }
+func geoY(y int) int { return (y - worldMinY) >> 4 }
+
+// cellCenter returns the world position of the middle of a cell.
+func cellCenter(gx, gy, z int) models.Position {
+	return models.Position{X: gx<<4 + worldMinX + 8, Y: gy<<4 + worldMinY + 8, Z: z}
+}
+
+// block returns the block and cell index of geodata cell (gx, gy); false
+// when its region has no geodata.
+func (e *Engine) block(gx, gy int) (block, int, bool) {
+	if gx < 0 || gy < 0 {
+		return nil, 0, false
+	}
+	reg, ok := e.regions[[2]int{gx/cellsPerRegion + firstRegionX, gy/cellsPerRegion + firstRegionY}]
+	if !ok {
+		return nil, 0, false
+	}
+	b, cell := reg.cell(gx%cellsPerRegion, gy%cellsPerRegion)
+	return b, cell, true
+}
+
+// hasGeo reports whether cell (gx, gy) has geodata.
+func (e *Engine) hasGeo(gx, gy int) bool {
+	_, _, ok := e.block(gx, gy)
+	return ok
+}
+
+// nearest returns the height and NSWE of the cell's layer closest to z; z
+// and every direction where there is no geodata.
+func (e *Engine) nearest(gx, gy, z int) (int, byte) {
+	b, cell, ok := e.block(gx, gy)
+	if !ok {
+		return z, nsweAll
+	}
+	return b.nearest(cell, z)
+}
+
+func (e *Engine) nearestZ(gx, gy, z int) int {
+	h, _ := e.nearest(gx, gy, z)
+	return h
+}
+
+func (e *Engine) nextHigherZ(gx, gy, z int) int {
+	b, cell, ok := e.block(gx, gy)
+	if !ok {
+		return z
+	}
+	return b.nextHigher(cell, z)
+}
+
+// direction returns the NSWE bits of a step of (dx, dy) cells.
+func direction(dx, dy int) byte {
+	var dir byte
+	switch {
+	case dx > 0:
+		dir |= nsweEast
+	case dx < 0:
+		dir |= nsweWest
+	}
+	switch {
+	case dy > 0:
+		dir |= nsweSouth
+	case dy < 0:
+		dir |= nsweNorth
+	}
+	return dir
+}
+
+// canLeave reports whether the layer of (gx, gy) nearest z may be left in
+// all the directions of dir.
+func (e *Engine) canLeave(gx, gy, z int, dir byte) bool {
+	_, nswe := e.nearest(gx, gy, z)
+	return nswe&dir == dir
+}
+
+// canStep reports whether a character at height z on (gx, gy) may step to the
+// neighbouring cell (gx+dx, gy+dy). A diagonal step also needs both cells it
+// passes between to allow the turn, so it cannot cut a wall's corner.
+func (e *Engine) canStep(gx, gy, z, dx, dy int) bool {
+	if !e.canLeave(gx, gy, z, direction(dx, dy)) {
+		return false
+	}
+	if dx == 0 || dy == 0 {
+		return true
+	}
+	return e.canLeave(gx, gy+dy, e.nearestZ(gx, gy+dy, z), direction(dx, 0)) &&
+		e.canLeave(gx+dx, gy, e.nearestZ(gx+dx, gy, z), direction(0, dy))
+}
+
+// walkLine calls visit for every cell of the line from (x0, y0) to (x1, y1)
+// but the first, with the step's index (1..n), until visit returns false.
+func walkLine(x0, y0, x1, y1 int, visit func(x, y, i, n int) bool) {
+	dx, dy := abs(x1-x0), abs(y1-y0)
+	sx, sy := 1, 1
+	if x1 < x0 {
+		sx = -1
+	}
+	if y1 < y0 {
+		sy = -1
+	}
+	n := max(dx, dy)
+	x, y, errXY := x0, y0, dx-dy
+	for i := 1; i <= n; i++ {
+		e2 := 2 * errXY
+		if e2 > -dy {
+			errXY -= dy
+			x += sx
+		}
+		if e2 < dx {
+			errXY += dx
+			y += sy
+		}
+		if !visit(x, y, i, n) {
+			return
+		}
+	}
+}
+
+// GetHeight returns the ground height at (x, y) of the layer nearest z: z
+// itself where there is no geodata.
+func (e *Engine) GetHeight(x, y, z int) int {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+	return e.nearestZ(geoX(x), geoY(y), z)
+}
+
+// CanSeeTarget reports whether a character standing at from can see one
+// standing at to (L2J GeoData.canSeeTarget): the line between them, taken
+// from the higher of the two, must not pass more than maxSeeOverHeight under
+// the ground. Two characters on the same cell see each other only on the
+// same floor.
+func (e *Engine) CanSeeTarget(from, to models.Position) bool {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+	gx, gy := geoX(from.X), geoY(from.Y)
+	tgx, tgy := geoX(to.X), geoY(to.Y)
+	z := e.nearestZ(gx, gy, from.Z)
+	tz := e.nearestZ(tgx, tgy, to.Z)
+	if gx == tgx && gy == tgy {
+		return !e.hasGeo(gx, gy) || z == tz
+	}
+	if tz > z {
+		gx, gy, z, tgx, tgy, tz = tgx, tgy, tz, gx, gy, z
+	}
+
+	visible := true
+	prevX, prevY, prevZ := gx, gy, z
+	walkLine(gx, gy, tgx, tgy, func(cx, cy, i, n int) bool {
+		curZ := prevZ
+		if e.hasGeo(cx, cy) {
+			lineZ := z + (tz-z)*i/n
+			maxHeight := lineZ + maxSeeOverHeight
+			if i <= elevatedSeeOverDistance {
+				maxHeight = z + maxSeeOverHeight
+			}
+			dx, dy := cx-prevX, cy-prevY
+			curZ = e.losZ(prevX, prevY, prevZ, cx, cy)
+			if curZ > maxHeight {
+				visible = false
+			} else if dx != 0 && dy != 0 {
+				// A diagonal look passes between two cells; both must be low
+				// enough, or it sees through a wall's corner.
+				visible = e.losZ(prevX, prevY, prevZ, prevX, cy) <= maxHeight &&
+					e.losZ(prevX, prevY, prevZ, cx, prevY) <= maxHeight
+			}
+		}
+		prevX, prevY, prevZ = cx, cy, curZ
+		return visible
+	})
+	return visible
+}
+
+// losZ is the height the line of sight meets on the neighbouring cell (cx,
+// cy) coming from (px, py) at z: the floor it walks onto, or, past a wall,
+// the top of the wall.
+func (e *Engine) losZ(px, py, z, cx, cy int) int {
+	if e.canStep(px, py, z, cx-px, cy-py) {
+		return e.nearestZ(cx, cy, z)
+	}
+	return e.nextHigherZ(cx, cy, z)
+}
+
+// CanMoveToTarget reports whether a character can walk in a straight line
+// from from to to (L2J GeoData.canMove).
+func (e *Engine) CanMoveToTarget(from, to models.Position) bool {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+	_, ok := e.move(from, to)
+	return ok
+}
+
+// MoveCheck returns how far along the straight line from from to to a
+// character can walk (L2J GeoData.moveCheck): to itself, with its height
+// snapped to the ground, when nothing is in the way; otherwise the middle of
+// the last cell before the obstacle, or from when to is on another floor.
+func (e *Engine) MoveCheck(from, to models.Position) models.Position {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+	dest, _ := e.move(from, to)
+	return dest
+}
+
+// move is MoveCheck, also reporting whether to was reached. The caller holds
+// the read lock.
+func (e *Engine) move(from, to models.Position) (models.Position, bool) {
+	gx, gy := geoX(from.X), geoY(from.Y)
+	tgx, tgy := geoX(to.X), geoY(to.Y)
+	tz := e.nearestZ(tgx, tgy, to.Z)
+
+	last := from
+	blocked := false
+	prevX, prevY, prevZ := gx, gy, e.nearestZ(gx, gy, from.Z)
+	walkLine(gx, gy, tgx, tgy, func(cx, cy, _, _ int) bool {
+		if e.hasGeo(prevX, prevY) && !e.canStep(prevX, prevY, prevZ, cx-prevX, cy-prevY) {
+			blocked = true
+			return false
+		}
+		prevX, prevY, prevZ = cx, cy, e.nearestZ(cx, cy, prevZ)
+		last = cellCenter(cx, cy, prevZ)
+		return true
+	})
+	if blocked {
+		return last, false
+	}
+	if e.hasGeo(prevX, prevY) && prevZ != tz {
+		return from, false // the walk ends on another floor than to's
+	}
+	return models.Position{X: to.X, Y: to.Y, Z: tz}, true
+}
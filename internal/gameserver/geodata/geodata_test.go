@@ -0,0 +1,206 @@
+package geodata
+
+import (
+	"encoding/binary"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/VerTox/l2go/internal/gameserver/models"
+)
+
+// cellData encodes a complex or multilayer cell.
+func cellData(height int, nswe byte) uint16 {
+	return uint16(height<<1)&0xFFF0 | uint16(nswe)
+}
+
+// testRegionL2J builds region 20_18, whose corner is the world origin: flat
+// ground at 0, except block 0 (x, y 0..127), where cells with cx 4 are a
+// wall 200 high, and block 1 (x 0..127, y 128..255), whose cells have a
+// floor at 0 and a bridge at 304.
+func testRegionL2J() []byte {
+	var out []byte
+	put16 := func(v uint16) { out = binary.LittleEndian.AppendUint16(out, v) }
+	for i := range blocksPerRegion * blocksPerRegion {
+		switch i {
+		case 0:
+			out = append(out, 1)
+			for cx := range cellsPerBlock {
+				for range cellsPerBlock {
+					switch cx {
+					case 3:
+						put16(cellData(0, nsweAll&^nsweEast))
+					case 4:
+						put16(cellData(200, 0))
+					case 5:
+						put16(cellData(0, nsweAll&^nsweWest))
+					default:
+						put16(cellData(0, nsweAll))
+					}
+				}
+			}
+		case 1:
+			out = append(out, 2)
+			for range cellsPerBlock * cellsPerBlock {
+				out = append(out, 2)
+				put16(cellData(0, nsweAll))
+				put16(cellData(304, nsweAll))
+			}
+		default:
+			out = append(out, 0)
+			put16(0)
+		}
+	}
+	return out
+}
+
+// testRegionL2OFF builds a flat region at height h in the L2OFF format.
+func testRegionL2OFF(h int16) []byte {
+	out := make([]byte, l2offHeaderSize)
+	for range blocksPerRegion * blocksPerRegion {
+		out = binary.LittleEndian.AppendUint16(out, 0)
+		out = binary.LittleEndian.AppendUint16(out, uint16(h))
+		out = binary.LittleEndian.AppendUint16(out, uint16(h))
+	}
+	return out
+}
+
+func loadTestEngine(t *testing.T) *Engine {
+	t.Helper()
+	dir := t.TempDir()
+	files := map[string][]byte{
+		"20_18.l2j":      testRegionL2J(),
+		"20_18_conv.dat": testRegionL2OFF(-500), // the .l2j file wins
+		"21_18_conv.dat": testRegionL2OFF(-100),
+		"22_18.l2j":      {1, 2, 3}, // truncated: skipped
+	}
+	for name, data := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), data, 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	e := NewEngine()
+	if err := e.LoadFromDirectory(dir); err != nil {
+		t.Fatal(err)
+	}
+	return e
+}
+
+func TestGeodata_Load(t *testing.T) {
+	e := loadTestEngine(t)
+	if !e.IsLoaded() || e.RegionCount() != 2 {
+		t.Fatalf("loaded %v with %d regions, want 2", e.IsLoaded(), e.RegionCount())
+	}
+	if err := NewEngine().LoadFromDirectory(filepath.Join(t.TempDir(), "missing")); err == nil {
+		t.Error("a missing directory must be an error")
+	}
+}
+
+func TestGeodata_GetHeight(t *testing.T) {
+	e := loadTestEngine(t)
+	cases := []struct {
+		name    string
+		x, y, z int
+		want    int
+	}{
+		{"flat block", 1000, 1000, 50, 0},
+		{"wall cell", 70, 40, 0, 200},
+		{"bridge floor", 40, 200, 50, 0},
+		{"bridge deck", 40, 200, 250, 304},
+		{"L2OFF region", 32768 + 500, 500, 0, -100},
+		{"no geodata", -1000, 500, 1234, 1234},
+		{"broken region", 2*32768 + 500, 500, 77, 77},
+	}
+	for _, c := range cases {
+		if got := e.GetHeight(c.x, c.y, c.z); got != c.want {
+			t.Errorf("%s: height = %d, want %d", c.name, got, c.want)
+		}
+	}
+}
+
+func TestGeodata_CanSeeTarget(t *testing.T) {
+	e := loadTestEngine(t)
+	west := models.Position{X: 8, Y: 40}
+	east := models.Position{X: 120, Y: 40}
+	if e.CanSeeTarget(west, east) || e.CanSeeTarget(east, west) {
+		t.Error("saw through the wall")
+	}
+	if !e.CanSeeTarget(models.Position{X: 200, Y: 40}, models.Position{X: 2000, Y: 900}) {
+		t.Error("open ground blocked the view")
+	}
+	if !e.CanSeeTarget(models.Position{X: 8, Y: 200}, models.Position{X: 8, Y: 400}) {
+		t.Error("a floor under a bridge blocked the view along it")
+	}
+	if e.CanSeeTarget(models.Position{X: 40, Y: 200, Z: 304}, models.Position{X: 40, Y: 200}) {
+		t.Error("saw through the bridge deck to the floor below")
+	}
+	if !e.CanSeeTarget(models.Position{X: -5000, Y: 40}, models.Position{X: -1000, Y: 40, Z: 900}) {
+		t.Error("no geodata must leave every line clear")
+	}
+}
+
+func TestGeodata_MoveCheck(t *testing.T) {
+	e := loadTestEngine(t)
+	west := models.Position{X: 8, Y: 40}
+	east := models.Position{X: 120, Y: 40}
+	if e.CanMoveToTarget(west, east) {
+		t.Fatal("walked through the wall")
+	}
+	if got := e.MoveCheck(west, east); got.X != 56 || got.Y != 40 || got.Z != 0 {
+		t.Errorf("stopped at %+v, want the last cell before the wall (56, 40, 0)", got)
+	}
+
+	to := models.Position{X: 1500, Y: 700, Z: 80}
+	if got := e.MoveCheck(models.Position{X: 200, Y: 40}, to); got != (models.Position{X: 1500, Y: 700}) {
+		t.Errorf("open walk ended at %+v, want the destination on the ground", got)
+	}
+	// Walking in under the bridge does not reach its deck.
+	from := models.Position{X: 40, Y: 600}
+	if got := e.MoveCheck(from, models.Position{X: 40, Y: 200, Z: 304}); got != from {
+		t.Errorf("a walk ending on another floor went to %+v, want to stay at %+v", got, from)
+	}
+	if !e.CanMoveToTarget(models.Position{X: -5000, Y: 40}, models.Position{X: -1000, Y: 40}) {
+		t.Error("no geodata must leave every walk open")
+	}
+}
+
+func TestGeodata_FindPath(t *testing.T) {
+	e := loadTestEngine(t)
+	west := models.Position{X: 8, Y: 40}
+	east := models.Position{X: 120, Y: 40}
+
+	path, expanded, ok := e.FindPath(west, east, 2000)
+	if !ok {
+		t.Fatalf("no way round the wall found (%d cells expanded)", expanded)
+	}
+	if path[len(path)-1] != east {
+		t.Errorf("path ends at %+v, want %+v", path[len(path)-1], east)
+	}
+	from, detour := west, false
+	for _, wp := range path {
+		if !e.CanMoveToTarget(from, wp) {
+			t.Fatalf("leg %+v -> %+v is not walkable", from, wp)
+		}
+		// The wall spans y 0..127; open ground lies beyond either end.
+		detour = detour || wp.Y < 0 || wp.Y >= 128
+		from = wp
+	}
+	if !detour {
+		t.Errorf("path %+v does not go round the wall's end", path)
+	}
+
+	// A clear line needs no search.
+	open := models.Position{X: 1500, Y: 700}
+	if path, expanded, ok := e.FindPath(models.Position{X: 200, Y: 40}, open, 2000); !ok || expanded != 0 || len(path) != 1 || path[0] != open {
+		t.Errorf("straight walk: path %+v, expanded %d, ok %v", path, expanded, ok)
+	}
+
+	// The search stops at its budget.
+	if _, expanded, ok := e.FindPath(west, east, 5); ok || expanded != 5 {
+		t.Errorf("budget of 5: expanded %d, ok %v", expanded, ok)
+	}
+	// The bridge deck is not reachable from the ground.
+	if _, expanded, ok := e.FindPath(models.Position{X: 40, Y: 600}, models.Position{X: 40, Y: 200, Z: 304}, 500); ok || expanded != 500 {
+		t.Errorf("bridge deck: expanded %d, ok %v", expanded, ok)
+	}
+}
@@ -0,0 +1,142 @@
+package geodata
+
+import (
+	"container/heap"
+
+	"github.com/VerTox/l2go/internal/gameserver/models"
+)
+
+const (
+	// Step costs of the search: a diagonal step is about √2 straight ones.
+	straightCost = 10
+	diagonalCost = 14
+)
+
+// pathNode is a cell layer the path search reached.
+type pathNode struct {
+	x, y, z int
+	// cost is the cheapest known cost from the start; estimate adds the
+	// heuristic to the goal.
+	cost, estimate int
+	parent         *pathNode
+	index          int // in the open heap; -1 once closed
+}
+
+type nodeKey struct{ x, y, z int }
+
+// openNodes is the search frontier, cheapest estimate first.
+type openNodes []*pathNode
+
+func (h openNodes) Len() int           { return len(h) }
+func (h openNodes) Less(i, j int) bool { return h[i].estimate < h[j].estimate }
+func (h openNodes) Swap(i, j int) {
+	h[i], h[j] = h[j], h[i]
+	h[i].index, h[j].index = i, j
+}
+func (h *openNodes) Push(x any) {
+	n := x.(*pathNode)
+	n.index = len(*h)
+	*h = append(*h, n)
+}
+func (h *openNodes) Pop() any {
+	old := *h
+	n := old[len(old)-1]
+	*h = old[:len(old)-1]
+	n.index = -1
+	return n
+}
+
+// heuristic is the octile distance between two cells: the cost of the
+// shortest walk on open ground.
+func heuristic(x0, y0, x1, y1 int) int {
+	dx, dy := abs(x1-x0), abs(y1-y0)
+	return straightCost*max(dx, dy) + (diagonalCost-straightCost)*min(dx, dy)
+}
+
+// FindPath searches a walkable way from from to to with A* over the geodata
+// cells (L2J CellPathFinding), expanding at most maxNodes cells. It returns
+// the path's waypoints after from, the last being to with its height on the
+// ground, and how many cells the search expanded. A straight walk needs no
+// search: its path is to alone. ok is false when to cannot be reached within
+// the budget.
+func (e *Engine) FindPath(from, to models.Position, maxNodes int) (path []models.Position, expanded int, ok bool) {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+	if dest, straight := e.move(from, to); straight {
+		return []models.Position{dest}, 0, true
+	}
+
+	gx, gy := geoX(from.X), geoY(from.Y)
+	tgx, tgy := geoX(to.X), geoY(to.Y)
+	tz := e.nearestZ(tgx, tgy, to.Z)
+	goalHasGeo := e.hasGeo(tgx, tgy)
+
+	start := &pathNode{x: gx, y: gy, z: e.nearestZ(gx, gy, from.Z)}
+	start.estimate = heuristic(gx, gy, tgx, tgy)
+	nodes := map[nodeKey]*pathNode{{start.x, start.y, start.z}: start}
+	open := &openNodes{}
+	heap.Push(open, start)
+
+	for open.Len() > 0 && expanded < maxNodes {
+		cur := heap.Pop(open).(*pathNode)
+		expanded++
+		if cur.x == tgx && cur.y == tgy && (!goalHasGeo || cur.z == tz) {
+			return e.smoothPath(from, cur, models.Position{X: to.X, Y: to.Y, Z: tz}), expanded, true
+		}
+		for dx := -1; dx <= 1; dx++ {
+			for dy := -1; dy <= 1; dy++ {
+				if (dx == 0 && dy == 0) || !e.canStep(cur.x, cur.y, cur.z, dx, dy) {
+					continue
+				}
+				nx, ny := cur.x+dx, cur.y+dy
+				nz := e.nearestZ(nx, ny, cur.z)
+				cost := cur.cost + straightCost
+				if dx != 0 && dy != 0 {
+					cost = cur.cost + diagonalCost
+				}
+				key := nodeKey{nx, ny, nz}
+				n, seen := nodes[key]
+				switch {
+				case !seen:
+					n = &pathNode{x: nx, y: ny, z: nz, cost: cost, parent: cur}
+					n.estimate = cost + heuristic(nx, ny, tgx, tgy)
+					nodes[key] = n
+					heap.Push(open, n)
+				case n.index >= 0 && cost < n.cost:
+					n.estimate -= n.cost - cost
+					n.cost, n.parent = cost, cur
+					heap.Fix(open, n.index)
+				}
+			}
+		}
+	}
+	return nil, expanded, false
+}
+
+// smoothPath turns the searched cells ending at goal into waypoints, keeping
+// only the cells where the way has to turn (L2J path smoothing): a waypoint
+// is dropped whenever the one after it can be walked to straight.
+func (e *Engine) smoothPath(from models.Position, goal *pathNode, to models.Position) []models.Position {
+	var cells []models.Position
+	for n := goal; n.parent != nil; n = n.parent {
+		cells = append(cells, cellCenter(n.x, n.y, n.z))
+	}
+	if len(cells) == 0 {
+		return []models.Position{to}
+	}
+	// cells runs goal to start; the goal cell itself is replaced by to.
+	cells[0] = to
+	for i, j := 0, len(cells)-1; i < j; i, j = i+1, j-1 {
+		cells[i], cells[j] = cells[j], cells[i]
+	}
+
+	var path []models.Position
+	anchor := from
+	for i := 0; i < len(cells)-1; i++ {
+		if _, straight := e.move(anchor, cells[i+1]); !straight {
+			path = append(path, cells[i])
+			anchor = cells[i]
+		}
+	}
+	return append(path, to)
+}
@@ -0,0 +1,225 @@
+package geodata
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+)
+
+const (
+	// blocksPerRegion is how many 8x8-cell blocks a region has along each axis.
+	blocksPerRegion = 256
+	// cellsPerBlock is how many 16-unit cells a block has along each axis.
+	cellsPerBlock = 8
+	// cellsPerRegion is how many cells a region (32768 units) has along each
+	// axis.
+	cellsPerRegion = blocksPerRegion * cellsPerBlock
+
+	// l2offHeaderSize is the header of an L2OFF _conv.dat file, skipped.
+	l2offHeaderSize = 18
+)
+
+// NSWE bits of a cell: the directions a character may leave it in. East is
+// +X and south is +Y.
+const (
+	nsweEast  byte = 1 << 0
+	nsweWest  byte = 1 << 1
+	nsweSouth byte = 1 << 2
+	nsweNorth byte = 1 << 3
+	nsweAll        = nsweEast | nsweWest | nsweSouth | nsweNorth
+)
+
+// block is an 8x8-cell piece of a region. cell is cx*8+cy within the block.
+type block interface {
+	// nearest returns the height and NSWE of the cell's layer closest to z.
+	nearest(cell, z int) (height int, nswe byte)
+	// nextHigher returns the height of the cell's lowest layer at or above
+	// z; z itself if there is none.
+	nextHigher(cell, z int) int
+}
+
+// decodeCell splits a complex or multilayer cell into its height and NSWE.
+func decodeCell(data int16) (int, byte) {
+	return int(int16(uint16(data)&0xFFF0) >> 1), byte(data & 0x0F)
+}
+
+// flatBlock is a block of one height with no obstacles.
+type flatBlock struct{ height int16 }
+
+func (b flatBlock) nearest(int, int) (int, byte) { return int(b.height), nsweAll }
+
+func (b flatBlock) nextHigher(_, z int) int { return max(int(b.height), z) }
+
+// complexBlock is a block of one layer with a height and NSWE per cell.
+type complexBlock struct {
+	cells [cellsPerBlock * cellsPerBlock]int16
+}
+
+func (b *complexBlock) nearest(cell, _ int) (int, byte) { return decodeCell(b.cells[cell]) }
+
+func (b *complexBlock) nextHigher(cell, z int) int {
+	h, _ := decodeCell(b.cells[cell])
+	return max(h, z)
+}
+
+// multilayerBlock is a block whose cells have any number of layers (floors of
+// a building, bridges). The layers of cell i are layers[start[i]:start[i+1]].
+type multilayerBlock struct {
+	start  [cellsPerBlock*cellsPerBlock + 1]uint32
+	layers []int16
+}
+
+func (b *multilayerBlock) nearest(cell, z int) (int, byte) {
+	height, nswe, best := z, nsweAll, -1
+	for _, data := range b.layers[b.start[cell]:b.start[cell+1]] {
+		h, n := decodeCell(data)
+		if d := abs(h - z); best < 0 || d < best {
+			height, nswe, best = h, n, d
+		}
+	}
+	return height, nswe
+}
+
+func (b *multilayerBlock) nextHigher(cell, z int) int {
+	higher, found := z, false
+	for _, data := range b.layers[b.start[cell]:b.start[cell+1]] {
+		h, _ := decodeCell(data)
+		if h >= z && (!found || h < higher) {
+			higher, found = h, true
+		}
+	}
+	return higher
+}
+
+// region is the geodata of one 32768x32768 map tile, numbered like its file
+// (XX_YY).
+type region struct {
+	blocks []block
+}
+
+// cell returns the block and cell index holding region-local cell (x, y).
+func (r *region) cell(x, y int) (block, int) {
+	b := r.blocks[(x/cellsPerBlock)*blocksPerRegion+y/cellsPerBlock]
+	return b, (x%cellsPerBlock)*cellsPerBlock + y%cellsPerBlock
+}
+
+// errTruncated reports a region file that ends before its last block.
+var errTruncated = errors.New("file truncated")
+
+// reader reads the little-endian values of a geodata file, remembering the
+// first overrun.
+type reader struct {
+	data []byte
+	off  int
+	err  error
+}
+
+func (r *reader) u8() byte {
+	if r.off+1 > len(r.data) {
+		r.err = errTruncated
+		return 0
+	}
+	v := r.data[r.off]
+	r.off++
+	return v
+}
+
+func (r *reader) i16() int16 {
+	if r.off+2 > len(r.data) {
+		r.err = errTruncated
+		return 0
+	}
+	v := int16(binary.LittleEndian.Uint16(r.data[r.off:]))
+	r.off += 2
+	return v
+}
+
+// parseL2J reads a region in the L2J .l2j format: per block a type byte (0
+// flat, 1 complex, 2 multilayer), then a height for flat blocks, 64 cells for
+// complex ones, and per cell a layer count byte and its layers for
+// multilayer ones.
+func parseL2J(data []byte) (*region, error) {
+	r := &reader{data: data}
+	reg := &region{blocks: make([]block, blocksPerRegion*blocksPerRegion)}
+	for i := range reg.blocks {
+		switch t := r.u8(); t {
+		case 0:
+			reg.blocks[i] = flatBlock{height: r.i16()}
+		case 1:
+			reg.blocks[i] = readComplex(r)
+		case 2:
+			reg.blocks[i] = readMultilayer(r, func() int { return int(r.u8()) })
+		default:
+			if r.err == nil {
+				return nil, fmt.Errorf("block %d: unknown type %d", i, t)
+			}
+		}
+		if r.err != nil {
+			return nil, fmt.Errorf("block %d: %w", i, r.err)
+		}
+	}
+	return reg, nil
+}
+
+// parseL2OFF reads a region in the L2OFF _conv.dat format: after the header,
+// per block a type short (0 flat, 0x40 complex, anything else multilayer),
+// then a height and a spare short for flat blocks, 64 cells for complex ones,
+// and per cell a layer count short and its layers for multilayer ones.
+func parseL2OFF(data []byte) (*region, error) {
+	if len(data) < l2offHeaderSize {
+		return nil, errTruncated
+	}
+	r := &reader{data: data, off: l2offHeaderSize}
+	reg := &region{blocks: make([]block, blocksPerRegion*blocksPerRegion)}
+	for i := range reg.blocks {
+		switch r.i16() {
+		case 0x0000:
+			reg.blocks[i] = flatBlock{height: r.i16()}
+			r.i16()
+		case 0x0040:
+			reg.blocks[i] = readComplex(r)
+		default:
+			reg.blocks[i] = readMultilayer(r, func() int { return int(r.i16()) })
+		}
+		if r.err != nil {
+			return nil, fmt.Errorf("block %d: %w", i, r.err)
+		}
+	}
+	return reg, nil
+}
+
+func readComplex(r *reader) *complexBlock {
+	b := &complexBlock{}
+	for c := range b.cells {
+		b.cells[c] = r.i16()
+	}
+	return b
+}
+
+// readMultilayer reads a multilayer block, each cell's layer count read by
+// count.
+func readMultilayer(r *reader, count func() int) *multilayerBlock {
+	b := &multilayerBlock{}
+	for c := range cellsPerBlock * cellsPerBlock {
+		n := count()
+		if r.err != nil {
+			return b
+		}
+		if n <= 0 {
+			r.err = fmt.Errorf("cell %d: bad layer count %d", c, n)
+			return b
+		}
+		for range n {
+			b.layers = append(b.layers, r.i16())
+		}
+		b.start[c+1] = uint32(len(b.layers))
+	}
+	return b
+}
+
+func abs(v int) int {
+	if v < 0 {
+		return -v
+	}
+	return v
+}
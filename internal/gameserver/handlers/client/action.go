@@ -7,6 +7,7 @@ import (
 	"github.com/rs/zerolog"
 	"github.com/rs/zerolog/log"
 
+	"github.com/VerTox/l2go/internal/gameserver/gameloop"
 	"github.com/VerTox/l2go/internal/gameserver/packets/inclient"
 	"github.com/VerTox/l2go/internal/gameserver/packets/outclient"
 	"github.com/VerTox/l2go/internal/gameserver/registry"
@@ -59,10 +60,17 @@ func (h *Handler) handleRequestActionUse(ctx context.Context, c *client.ClientCo
 		return h.handleWalkRunToggle(ctx, c, playerState, logger)
 	
 	case 0: // Sit/Stand toggle
-		logger.Debug().Msg("sit/stand toggle not implemented yet")
-		// TODO: Implement sit/stand functionality
+		h.gameLoopCmd <- gameloop.CmdSitStand{CharID: playerState.CharID}
 		return nil
-		
+
+	case 10, 61: // Private store (sell) / package sale
+		h.gameLoopCmd <- gameloop.CmdPrivateStoreManage{CharID: playerState.CharID, Package: actionPacket.ActionID == 61}
+		return nil
+
+	case 28: // Private store (buy)
+		h.gameLoopCmd <- gameloop.CmdPrivateStoreManage{CharID: playerState.CharID, Buy: true}
+		return nil
+
 	default:
 		logger.Debug().Msg("unimplemented action ID")
 		// For now, just log unimplemented actions without failing
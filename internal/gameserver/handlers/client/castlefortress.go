@@ -11,6 +11,4 @@ func registerCastleFortressStubs(r *Registry) {
 	r.registerMultiStub(StateInGame, 0x3d, "RequestAllFortressInfo")
 	// RequestAllAgitInfo (0xD0:0x3e): запрос информации о всех clan halls.
 	r.registerMultiStub(StateInGame, 0x3e, "RequestAllAgitInfo")
-	// RequestGetBossRecord (0xD0:0x40): запрос записи о боссах.
-	r.registerMultiStub(StateInGame, 0x40, "RequestGetBossRecord")
 }
@@ -0,0 +1,31 @@
+package client
+
+import (
+	"context"
+	"errors"
+
+	"github.com/rs/zerolog/log"
+
+	"github.com/VerTox/l2go/internal/gameserver/gameloop"
+	"github.com/VerTox/l2go/internal/gameserver/packets/outclient"
+	"github.com/VerTox/l2go/internal/gameserver/usecase"
+)
+
+// HandleTeleportJob takes a gatekeeper's fee and, once it is paid, hands the
+// teleport back to the game loop. A player short of the fee stays put.
+func (h *Handler) HandleTeleportJob(ctx context.Context, job gameloop.TeleportJob) {
+	changed, err := h.inventoryUseCase.PayFee(ctx, job.CharID, job.ItemID, job.Price)
+	if err != nil {
+		log.Ctx(ctx).Warn().Err(err).Int32("char_id", job.CharID).Int32("item_id", job.ItemID).Msg("teleport fee not paid")
+		switch {
+		case errors.Is(err, usecase.ErrNotEnoughAdena):
+			h.sendToCharacter(job.CharID, outclient.BuildSystemMessageNoParams(outclient.SysMsgNotEnoughAdena))
+		case errors.Is(err, usecase.ErrNotEnoughItems):
+			h.sendToCharacter(job.CharID, outclient.BuildSystemMessageNoParams(outclient.SysMsgNotEnoughRequiredItems))
+		}
+		h.sendToCharacter(job.CharID, outclient.BuildActionFailed())
+		return
+	}
+	h.SendInventoryUpdate(job.CharID, []usecase.ChangedItem{changed})
+	h.gameLoopCmd <- gameloop.CmdTeleport{CharID: job.CharID, Dest: job.Dest}
+}
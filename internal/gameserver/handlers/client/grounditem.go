@@ -0,0 +1,56 @@
+package client
+
+import (
+	"context"
+	"errors"
+
+	"github.com/rs/zerolog/log"
+
+	"github.com/VerTox/l2go/internal/gameserver/gameloop"
+	"github.com/VerTox/l2go/internal/gameserver/models"
+	"github.com/VerTox/l2go/internal/gameserver/packets/outclient"
+	"github.com/VerTox/l2go/internal/gameserver/usecase"
+)
+
+// HandleGroundItemJob runs the database side of a ground item for the ground
+// item worker.
+func (h *Handler) HandleGroundItemJob(ctx context.Context, job gameloop.GroundItemJob) {
+	switch job.Kind {
+	case gameloop.GroundItemPickUp:
+		h.pickUpGroundItem(ctx, job)
+	case gameloop.GroundItemDestroy:
+		if err := h.inventoryUseCase.DestroyGroundItem(ctx, job.Item.StoredID); err != nil {
+			log.Ctx(ctx).Error().Err(err).Int32("item_obj_id", job.Item.StoredID).Msg("failed to destroy decayed item")
+		}
+	}
+}
+
+// pickUpGroundItem puts a picked up item into the receiver's inventory, tells
+// the receiver what it got and reports the outcome back to the game loop.
+func (h *Handler) pickUpGroundItem(ctx context.Context, job gameloop.GroundItemJob) {
+	done := gameloop.CmdPickUpDone{CharID: job.CharID, PickerID: job.PickerID, ObjectID: job.Item.ObjectID}
+	changed, err := h.inventoryUseCase.PickUp(ctx, job.CharID, job.Item, job.InventorySlots)
+	if err != nil {
+		if errors.Is(err, usecase.ErrInventoryFull) {
+			h.sendToCharacter(job.PickerID, outclient.BuildSystemMessageNoParams(outclient.SysMsgSlotsFull))
+		} else {
+			log.Ctx(ctx).Error().Err(err).Int32("char_id", job.CharID).Int32("item_id", job.Item.ItemID).Msg("failed to pick up item")
+		}
+		h.sendToCharacter(job.PickerID, outclient.BuildActionFailed())
+		h.gameLoopCmd <- done
+		return
+	}
+	done.OK = true
+	h.gameLoopCmd <- done
+
+	h.SendInventoryUpdate(job.CharID, changed)
+	it := job.Item
+	switch {
+	case it.ItemID == models.ItemIDAdena:
+		h.sendToCharacter(job.CharID, outclient.NewSystemMessage(outclient.SysMsgEarnedS1Adena).AddLong(it.Count).Build())
+	case it.Count > 1:
+		h.sendToCharacter(job.CharID, outclient.NewSystemMessage(outclient.SysMsgPickedUpS2S1).AddItemName(it.ItemID).AddLong(it.Count).Build())
+	default:
+		h.sendToCharacter(job.CharID, outclient.NewSystemMessage(outclient.SysMsgPickedUpS1).AddItemName(it.ItemID).Build())
+	}
+}
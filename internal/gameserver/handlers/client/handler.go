@@ -43,6 +43,7 @@ type Handler struct {
 	logoutUseCase      usecase.LogoutUseCase
 	inventoryUseCase   *usecase.InventoryUseCase
 	enchantUseCase     *usecase.EnchantUseCase
+	raidBossUseCase    *usecase.RaidBossUseCase
 	world              *registry.WorldRegistry
 	connections        *registry.ConnectionRegistry
 	loginServerHandler LoginServerInterface
@@ -54,6 +55,8 @@ type Handler struct {
 	// prom records world-entry funnel metrics (l2go-5wq). nil leaves entry
 	// instrumentation off; all calls are nil-safe.
 	prom *gameloop.PromMetrics
+	// offlineTrade keeps store keepers in the world after their client leaves.
+	offlineTrade bool
 	// Simple in-memory session storage (TODO: use proper session management)
 	sessions map[*client.ClientConn]*ClientSession
 	// sessions map is written/read from every connection goroutine; guard it.
@@ -90,6 +93,10 @@ func New(characterUseCase *usecase.CharacterUseCase, movementUseCase usecase.Mov
 // New() signature so the enchant feature stays a self-contained add-on.
 func (h *Handler) SetEnchantUseCase(uc *usecase.EnchantUseCase) { h.enchantUseCase = uc }
 
+// SetRaidBossUseCase wires raid boss persistence (HandleRaidBossJob) and the
+// raid point window (RequestGetBossRecord).
+func (h *Handler) SetRaidBossUseCase(uc *usecase.RaidBossUseCase) { h.raidBossUseCase = uc }
+
 // SetSkillData wires the skill template registry used to resolve the SkillList
 // passive/enchanted flags. Kept out of New() like the other add-on setters.
 func (h *Handler) SetSkillData(sd SkillTemplateSource) { h.skillData = sd }
@@ -98,6 +105,10 @@ func (h *Handler) SetSkillData(sd SkillTemplateSource) { h.skillData = sd }
 // Kept out of New() like the other add-on setters; nil leaves it off.
 func (h *Handler) SetPromMetrics(pm *gameloop.PromMetrics) { h.prom = pm }
 
+// SetOfflineTrade switches offline trade on: a player whose client leaves with
+// a private store open stays in the world and keeps trading. Off by default.
+func (h *Handler) SetOfflineTrade(enabled bool) { h.offlineTrade = enabled }
+
 // Handle processes incoming client packets
 func (h *Handler) Handle(ctx context.Context, c *client.ClientConn) {
 	// Cleanup session on disconnect
@@ -211,6 +222,15 @@ func (h *Handler) getSession(c *client.ClientConn) *ClientSession {
 	return h.sessions[c]
 }
 
+// activePlayer resolves the in-world player bound to the connection's session.
+func (h *Handler) activePlayer(c *client.ClientConn) (*registry.PlayerWorldState, bool) {
+	session := h.getSession(c)
+	if session == nil {
+		return nil, false
+	}
+	return h.world.GetPlayerByAccount(session.AccountName)
+}
+
 // setSession stores a session for a client connection
 func (h *Handler) setSession(c *client.ClientConn, session *ClientSession) {
 	h.sessionsMu.Lock()
@@ -240,8 +260,11 @@ func (h *Handler) removeSession(c *client.ClientConn) {
 		// for this account has already replaced the registration, leave it be.
 		h.connections.UnregisterIf(session.AccountName, c)
 
-		// Remove player from world registry if they were in game
-		if playerState, exists := h.world.GetPlayerByAccount(session.AccountName); exists {
+		// Remove player from world registry if they were in game. A store keeper
+		// stays in the world when offline trade is on.
+		if playerState, exists := h.world.GetPlayerByAccount(session.AccountName); exists && h.keepsOfflineStore(playerState) {
+			h.enterOfflineTrade(context.Background(), session.AccountName, playerState)
+		} else if exists {
 			log.Info().
 				Str("account", session.AccountName).
 				Int32("char_id", playerState.CharID).
@@ -286,7 +309,13 @@ func (h *Handler) removeSession(c *client.ClientConn) {
 // account: the new login kicks the old (L2J default behaviour).
 func (h *Handler) kickExistingSession(ctx context.Context, account string, newConn *client.ClientConn) {
 	old := h.connections.GetConnection(account)
-	if old == nil || old == newConn {
+	if old == nil {
+		// No client, but the account may still keep an offline store open: its
+		// keeper leaves the world before the owner plays again.
+		h.endOfflineTrade(ctx, account)
+		return
+	}
+	if old == newConn {
 		return
 	}
 
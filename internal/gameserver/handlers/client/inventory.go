@@ -1,17 +1,157 @@
 package client
 
+import (
+	"context"
+	"errors"
+
+	"github.com/rs/zerolog/log"
+
+	"github.com/VerTox/l2go/internal/gameserver/gameloop"
+	"github.com/VerTox/l2go/internal/gameserver/models"
+	"github.com/VerTox/l2go/internal/gameserver/packets/inclient"
+	"github.com/VerTox/l2go/internal/gameserver/packets/outclient"
+	"github.com/VerTox/l2go/internal/gameserver/transport/client"
+	"github.com/VerTox/l2go/internal/gameserver/usecase"
+)
+
 func init() { addStubRegistrator(registerInventoryStubs) }
 
-// registerInventoryStubs регистрирует стаб-обработчики пакетов инвентаря (High Five).
+// registerInventoryStubs регистрирует обработчики пакетов инвентаря (High Five).
+// Проверки состояния игрока делает game loop (gameloop/discard.go), работу с
+// БД — HandleDiscardJob.
 func registerInventoryStubs(r *Registry) {
 	// RequestDropItem (0x17): выбросить предмет из инвентаря на землю.
-	r.registerStub(StateInGame, 0x17, "RequestDropItem")
+	r.register(StateInGame, 0x17, "RequestDropItem", (*Handler).handleRequestDropItem)
 	// RequestDestroyItem (0x60): уничтожить предмет из инвентаря.
-	r.registerStub(StateInGame, 0x60, "RequestDestroyItem")
+	r.register(StateInGame, 0x60, "RequestDestroyItem", (*Handler).handleRequestDestroyItem)
 	// RequestCrystallizeItem (0x2f): кристаллизовать предмет (получить кристаллы).
-	r.registerStub(StateInGame, 0x2f, "RequestCrystallizeItem")
+	r.register(StateInGame, 0x2f, "RequestCrystallizeItem", (*Handler).handleRequestCrystallizeItem)
 	// RequestPreviewItem (0xc7): предпросмотр/примерка предмета на персонаже.
 	r.registerStub(StateInGame, 0xc7, "RequestPreviewItem")
-	// MultiSellChoose (0xb0): выбор позиции в окне мультиселла.
-	r.registerStub(StateInGame, 0xb0, "MultiSellChoose")
+}
+
+// handleRequestDropItem forwards an item the player let go of to the game
+// loop, which checks where it would land.
+func (h *Handler) handleRequestDropItem(ctx context.Context, c *client.ClientConn, payload []byte) error {
+	pkt, err := inclient.ParseRequestDropItem(payload)
+	if err != nil {
+		log.Ctx(ctx).Debug().Err(err).Msg("failed to parse RequestDropItem")
+		return nil
+	}
+	player, ok := h.activePlayer(c)
+	if !ok {
+		return nil
+	}
+	h.gameLoopCmd <- gameloop.CmdDropItem{
+		CharID:   player.CharID,
+		ObjectID: pkt.ObjectID,
+		Count:    pkt.Count,
+		Position: models.Position{X: int(pkt.X), Y: int(pkt.Y), Z: int(pkt.Z)},
+	}
+	return nil
+}
+
+// handleRequestDestroyItem forwards an item to destroy to the game loop.
+func (h *Handler) handleRequestDestroyItem(ctx context.Context, c *client.ClientConn, payload []byte) error {
+	pkt, err := inclient.ParseItemCountRequest(payload)
+	if err != nil {
+		log.Ctx(ctx).Debug().Err(err).Msg("failed to parse RequestDestroyItem")
+		return nil
+	}
+	player, ok := h.activePlayer(c)
+	if !ok {
+		return nil
+	}
+	h.gameLoopCmd <- gameloop.CmdDestroyItem{CharID: player.CharID, ObjectID: pkt.ObjectID, Count: pkt.Count}
+	return nil
+}
+
+// handleRequestCrystallizeItem forwards an item to crystallize to the game
+// loop. The whole item is always crystallized, so the count is not used.
+func (h *Handler) handleRequestCrystallizeItem(ctx context.Context, c *client.ClientConn, payload []byte) error {
+	pkt, err := inclient.ParseItemCountRequest(payload)
+	if err != nil {
+		log.Ctx(ctx).Debug().Err(err).Msg("failed to parse RequestCrystallizeItem")
+		return nil
+	}
+	player, ok := h.activePlayer(c)
+	if !ok {
+		return nil
+	}
+	h.gameLoopCmd <- gameloop.CmdCrystallizeItem{CharID: player.CharID, ObjectID: pkt.ObjectID}
+	return nil
+}
+
+// HandleDiscardJob drops, destroys or crystallizes an item the game loop
+// cleared, and shows the player their inventory and load afterwards.
+func (h *Handler) HandleDiscardJob(ctx context.Context, job gameloop.DiscardJob) {
+	var (
+		res *usecase.DiscardResult
+		err error
+	)
+	switch job.Kind {
+	case gameloop.DiscardDrop:
+		res, err = h.inventoryUseCase.DropItem(ctx, job.CharID, job.ObjectID, job.Count)
+	case gameloop.DiscardDestroy:
+		res, err = h.inventoryUseCase.DestroyItem(ctx, job.CharID, job.ObjectID, job.Count)
+	case gameloop.DiscardCrystallize:
+		res, err = h.inventoryUseCase.CrystallizeItem(ctx, job.CharID, job.ObjectID, job.CrystallizeLevel)
+	}
+	if err != nil {
+		switch {
+		case errors.Is(err, usecase.ErrCannotDiscard):
+			h.sendToCharacter(job.CharID, outclient.BuildSystemMessageNoParams(outclient.SysMsgCannotDiscardThisItem))
+		case errors.Is(err, usecase.ErrCrystallizeLevelTooLow):
+			h.sendToCharacter(job.CharID, outclient.BuildSystemMessageNoParams(outclient.SysMsgCrystallizeLevelTooLow))
+		default:
+			log.Ctx(ctx).Error().Err(err).Int32("char_id", job.CharID).Int32("item_obj_id", job.ObjectID).Msg("failed to discard item")
+		}
+		h.sendToCharacter(job.CharID, outclient.BuildActionFailed())
+		return
+	}
+
+	if job.Kind == gameloop.DiscardDrop {
+		h.gameLoopCmd <- gameloop.CmdItemDropped{
+			CharID: job.CharID,
+			Item: models.GroundItem{
+				ItemID:   res.Item.ItemID,
+				Count:    res.Item.Count,
+				Enchant:  res.Item.EnchantLevel,
+				Position: job.Position,
+				StoredID: res.Item.ObjectID,
+			},
+		}
+	}
+	h.sendDiscardUpdate(ctx, job.CharID, res)
+	if job.Kind == gameloop.DiscardCrystallize {
+		h.sendToCharacter(job.CharID, outclient.NewSystemMessage(outclient.SysMsgEarnedS2S1S).AddItemName(res.CrystalID).AddLong(res.CrystalCount).Build())
+	}
+}
+
+// sendDiscardUpdate shows the player the items a discard changed, and their
+// new load. An item taken off the paperdoll refreshes the whole equipment view.
+func (h *Handler) sendDiscardUpdate(ctx context.Context, charID int32, res *usecase.DiscardResult) {
+	player, ok := h.world.GetPlayer(charID)
+	if !ok {
+		return
+	}
+	conn := h.connections.GetConnection(player.AccountName)
+	if conn == nil {
+		return
+	}
+	if res.Unequipped && player.Character != nil {
+		if err := h.sendEquipmentUpdatePackets(ctx, conn, player, res.Changed); err != nil {
+			log.Ctx(ctx).Warn().Err(err).Int32("char_id", charID).Msg("failed to send equipment update after discard")
+		}
+	} else {
+		_ = conn.Send(outclient.BuildInventoryUpdate(outclient.InventoryUpdate{Items: buildInventoryItems(res.Changed)}))
+	}
+	load, err := h.inventoryUseCase.CurrentLoad(ctx, charID)
+	if err != nil {
+		log.Ctx(ctx).Warn().Err(err).Int32("char_id", charID).Msg("failed to compute load after discard")
+		return
+	}
+	_ = conn.Send(outclient.BuildStatusUpdate(charID, []outclient.StatusAttribute{
+		{ID: outclient.StatusCurLoad, Value: int32(load)},
+	}))
 }
@@ -63,16 +63,22 @@ func (h *Handler) handleLogout(ctx context.Context, c *client.ClientConn, payloa
 		}
 	}
 
-	// Tell the game loop to despawn this player from everyone who had them in view
-	// (and clear the known-sets) BEFORE the use case removes them from the world.
-	if charID > 0 {
-		h.gameLoopCmd <- gameloop.CmdPlayerDisconnected{CharID: charID}
-	}
+	// A store keeper in offline trade mode is not logged out: closing the
+	// connection below detaches it from the client instead (removeSession).
+	if charID > 0 && h.keepsOfflineStore(playerState) {
+		logger.Info().Msg("store keeper leaving in offline trade mode")
+	} else {
+		// Tell the game loop to despawn this player from everyone who had them in view
+		// (and clear the known-sets) BEFORE the use case removes them from the world.
+		if charID > 0 {
+			h.gameLoopCmd <- gameloop.CmdPlayerDisconnected{CharID: charID}
+		}
 
-	// Perform graceful logout through use case
-	if err := h.logoutUseCase.PerformLogout(ctx, session.AccountName, charID); err != nil {
-		logger.Error().Err(err).Msg("logout failed")
-		// Continue with client disconnect even if logout fails
+		// Perform graceful logout through use case
+		if err := h.logoutUseCase.PerformLogout(ctx, session.AccountName, charID); err != nil {
+			logger.Error().Err(err).Msg("logout failed")
+			// Continue with client disconnect even if logout fails
+		}
 	}
 
 	// Send LeaveWorld packet for graceful client exit
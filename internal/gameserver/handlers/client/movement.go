@@ -33,6 +33,12 @@ func (h *Handler) handleMoveBackwardToLocation(ctx context.Context, c *client.Cl
 		return fmt.Errorf("player not found in world: %s", session.AccountName)
 	}
 
+	// A seated player (a private store owner included) cannot walk until it
+	// stands up.
+	if playerState.Sitting {
+		return c.Send(outclient.BuildActionFailed())
+	}
+
 	// Parse movement packet
 	movePacket, err := inclient.ParseMoveBackwardToLocation(payload)
 	if err != nil {
@@ -108,11 +114,19 @@ func (h *Handler) handleMoveBackwardToLocation(ctx context.Context, c *client.Cl
 		return nil
 	}
 
+	// A wall in the way: the game loop finds a way round it and walks the
+	// player along it, sending MoveToLocation for each leg itself.
+	if result.Blocked {
+		h.gameLoopCmd <- gameloop.CmdMoveToLocation{CharID: playerState.CharID, Dest: destination, FindPath: true}
+		return nil
+	}
+
 	// CRITICAL: Send MoveToLocation confirmation to the client first
-	// Without this, client will ignore subsequent movement requests
+	// Without this, client will ignore subsequent movement requests. The target
+	// is the server's: geodata may have stopped it short of a wall.
 	moveConfirmation := outclient.NewMoveToLocation(
 		playerState.CharID,
-		int32(destination.X), int32(destination.Y), int32(destination.Z),
+		int32(result.TargetPosition.X), int32(result.TargetPosition.Y), int32(result.TargetPosition.Z),
 		int32(result.StartPosition.X), int32(result.StartPosition.Y), int32(result.StartPosition.Z),
 	)
 
@@ -0,0 +1,70 @@
+package client
+
+import (
+	"context"
+	"errors"
+
+	"github.com/rs/zerolog/log"
+
+	"github.com/VerTox/l2go/internal/gameserver/gameloop"
+	"github.com/VerTox/l2go/internal/gameserver/packets/inclient"
+	"github.com/VerTox/l2go/internal/gameserver/packets/outclient"
+	"github.com/VerTox/l2go/internal/gameserver/transport/client"
+	"github.com/VerTox/l2go/internal/gameserver/usecase"
+)
+
+func init() { addStubRegistrator(registerMultisellHandlers) }
+
+// registerMultisellHandlers регистрирует обработчик мультиселла (High Five).
+// Список открывает bypass "multisell <id>" (gameloop/multisell.go); обмен,
+// которому нужна БД, выполняет HandleMultisellJob.
+func registerMultisellHandlers(r *Registry) {
+	// MultiSellChoose (0xb0): выбор позиции в окне мультиселла.
+	r.register(StateInGame, 0xb0, "MultiSellChoose", (*Handler).handleMultiSellChoose)
+}
+
+// handleMultiSellChoose forwards a picked exchange to the game loop, which
+// checks it against the list the player has open.
+func (h *Handler) handleMultiSellChoose(ctx context.Context, c *client.ClientConn, payload []byte) error {
+	pkt, err := inclient.ParseMultiSellChoose(payload)
+	if err != nil {
+		log.Ctx(ctx).Debug().Err(err).Msg("failed to parse MultiSellChoose")
+		return nil
+	}
+	player, ok := h.activePlayer(c)
+	if !ok {
+		return nil
+	}
+	h.gameLoopCmd <- gameloop.CmdMultisellChoose{
+		CharID:  player.CharID,
+		ListID:  pkt.ListID,
+		EntryID: pkt.EntryID,
+		Amount:  pkt.Amount,
+	}
+	return nil
+}
+
+// HandleMultisellJob runs an exchange the game loop validated and reports
+// what the player earned, or why nothing was exchanged.
+func (h *Handler) HandleMultisellJob(ctx context.Context, job gameloop.MultisellJob) {
+	changed, err := h.inventoryUseCase.Exchange(ctx, job.CharID, job.Cost, job.Products, job.Amount, job.KeepEnchant, job.InventorySlots)
+	if err != nil {
+		log.Ctx(ctx).Warn().Err(err).Int32("char_id", job.CharID).Int32("list_id", job.ListID).Int32("entry_id", job.EntryID).Msg("multisell exchange failed")
+		switch {
+		case errors.Is(err, usecase.ErrNotEnoughIngredients):
+			h.sendToCharacter(job.CharID, outclient.BuildSystemMessageNoParams(outclient.SysMsgNotEnoughRequiredItems))
+		case errors.Is(err, usecase.ErrInventoryFull):
+			h.sendToCharacter(job.CharID, outclient.BuildSystemMessageNoParams(outclient.SysMsgSlotsFull))
+		}
+		h.sendToCharacter(job.CharID, outclient.BuildActionFailed())
+		return
+	}
+	h.SendInventoryUpdate(job.CharID, changed)
+	for _, p := range job.Products {
+		if n := p.Count * job.Amount; n > 1 {
+			h.sendToCharacter(job.CharID, outclient.NewSystemMessage(outclient.SysMsgEarnedS2S1S).AddItemName(p.ItemID).AddLong(n).Build())
+		} else {
+			h.sendToCharacter(job.CharID, outclient.NewSystemMessage(outclient.SysMsgEarnedItemS1).AddItemName(p.ItemID).Build())
+		}
+	}
+}
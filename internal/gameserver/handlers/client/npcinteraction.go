@@ -1,16 +1,58 @@
 package client
 
-func init() { addStubRegistrator(registerNpcInteractionStubs) }
+import (
+	"context"
 
-// registerNpcInteractionStubs регистрирует стаб-обработчики пакетов
-// взаимодействия с NPC (High Five). Пакеты пока только логируют факт получения;
-// здесь же будет их логика.
-func registerNpcInteractionStubs(r *Registry) {
+	"github.com/rs/zerolog/log"
+
+	"github.com/VerTox/l2go/internal/gameserver/gameloop"
+	"github.com/VerTox/l2go/internal/gameserver/packets/inclient"
+	"github.com/VerTox/l2go/internal/gameserver/transport/client"
+)
+
+func init() { addStubRegistrator(registerNpcInteractionHandlers) }
+
+// registerNpcInteractionHandlers регистрирует обработчики взаимодействия с NPC
+// (High Five). Команды диалогов разбирает игровой цикл: bypass — через таблицу
+// gameloop.RegisterBypass, ссылки — по страницам datapack/html.
+func registerNpcInteractionHandlers(r *Registry) {
 	// RequestLinkHtml (0x22): нажатие HTML-ссылки в NPC-диалоге.
-	r.registerStub(StateInGame, 0x22, "RequestLinkHtml")
-	// RequestBypassToServer (0x23) — реальный обработчик в skills_learn.go (l2go-hv9).
+	r.register(StateInGame, 0x22, "RequestLinkHtml", (*Handler).handleRequestLinkHtml)
+	// RequestBypassToServer (0x23): bypass-команда из NPC-диалога.
+	r.register(StateInGame, 0x23, "RequestBypassToServer", (*Handler).handleRequestBypassToServer)
 	// DlgAnswer (0xc6): ответ на системный диалог подтверждения.
 	r.registerStub(StateInGame, 0xc6, "DlgAnswer")
 	// BypassUserCmd (0xb3): пользовательская bypass-команда.
 	r.registerStub(StateInGame, 0xb3, "BypassUserCmd")
 }
+
+// handleRequestBypassToServer forwards a dialogue command to the game loop,
+// which routes it through its bypass table.
+func (h *Handler) handleRequestBypassToServer(ctx context.Context, c *client.ClientConn, payload []byte) error {
+	pkt, err := inclient.ParseRequestBypass(payload)
+	if err != nil {
+		log.Ctx(ctx).Debug().Err(err).Msg("failed to parse RequestBypassToServer")
+		return nil
+	}
+	player, ok := h.activePlayer(c)
+	if !ok {
+		return nil
+	}
+	h.gameLoopCmd <- gameloop.CmdBypass{CharID: player.CharID, Command: pkt.Command}
+	return nil
+}
+
+// handleRequestLinkHtml forwards a dialogue link to the game loop.
+func (h *Handler) handleRequestLinkHtml(ctx context.Context, c *client.ClientConn, payload []byte) error {
+	pkt, err := inclient.ParseRequestLinkHtml(payload)
+	if err != nil {
+		log.Ctx(ctx).Debug().Err(err).Msg("failed to parse RequestLinkHtml")
+		return nil
+	}
+	player, ok := h.activePlayer(c)
+	if !ok {
+		return nil
+	}
+	h.gameLoopCmd <- gameloop.CmdLinkHtml{CharID: player.CharID, Link: pkt.Link}
+	return nil
+}
@@ -0,0 +1,91 @@
+package client
+
+import (
+	"context"
+
+	"github.com/rs/zerolog/log"
+
+	"github.com/VerTox/l2go/internal/gameserver/gameloop"
+	"github.com/VerTox/l2go/internal/gameserver/registry"
+)
+
+// keepsOfflineStore reports whether a leaving player stays in the world as an
+// offline store keeper. The store type is owned by the game loop, which checks
+// it again when the disconnect arrives and logs the player out itself if the
+// store closed in between.
+func (h *Handler) keepsOfflineStore(player *registry.PlayerWorldState) bool {
+	return h.offlineTrade && player.PrivateStore.Type.Open()
+}
+
+// enterOfflineTrade detaches a store keeper from its closed client: the loop
+// drops what needed the client and persists the store, the character is saved
+// like on logout, and it stays in the world trading.
+func (h *Handler) enterOfflineTrade(ctx context.Context, account string, player *registry.PlayerWorldState) {
+	h.gameLoopCmd <- gameloop.CmdPlayerDisconnected{CharID: player.CharID, OfflineTrade: true}
+	if h.logoutUseCase != nil {
+		if err := h.logoutUseCase.PerformOfflineTrade(ctx, account, player.CharID); err != nil {
+			log.Ctx(ctx).Warn().Err(err).Str("account", account).Msg("offline trade: failed to save character")
+		}
+	}
+}
+
+// endOfflineTrade logs out the offline store keeper of an account, if any, so
+// the account can play again: its store closes and is forgotten.
+func (h *Handler) endOfflineTrade(ctx context.Context, account string) {
+	player, ok := h.world.GetPlayerByAccount(account)
+	if !ok {
+		return
+	}
+	charID := player.CharID
+	log.Ctx(ctx).Info().Str("account", account).Int32("char_id", charID).Msg("account logged in, closing its offline store")
+
+	h.gameLoopCmd <- gameloop.CmdPlayerDisconnected{CharID: charID}
+	h.world.RemovePlayer(ctx, charID)
+	if h.logoutUseCase != nil {
+		if err := h.logoutUseCase.PerformLogout(ctx, account, charID); err != nil {
+			log.Ctx(ctx).Warn().Err(err).Str("account", account).Msg("offline trade: logout cleanup failed")
+		}
+	}
+	if err := h.inventoryUseCase.DeleteOfflineStore(ctx, charID); err != nil {
+		log.Ctx(ctx).Error().Err(err).Int32("char_id", charID).Msg("failed to forget offline store")
+	}
+}
+
+// RestoreOfflineTraders puts the persisted offline store keepers back into the
+// world on startup (L2J OfflineTradersTable.restoreOfflineTraders). With offline
+// trade switched off the persisted stores are dropped instead.
+func (h *Handler) RestoreOfflineTraders(ctx context.Context) {
+	if !h.offlineTrade {
+		if err := h.inventoryUseCase.ClearOfflineStores(ctx); err != nil {
+			log.Ctx(ctx).Error().Err(err).Msg("failed to clear offline stores")
+		}
+		return
+	}
+
+	stores, err := h.inventoryUseCase.RestoreOfflineStores(ctx)
+	if err != nil {
+		log.Ctx(ctx).Error().Err(err).Msg("failed to load offline stores")
+		return
+	}
+	restored := 0
+	for _, s := range stores {
+		char := s.Character
+		if _, taken := h.world.GetPlayerByAccount(char.AccountName); taken {
+			// The owner logged in before the restore got to it.
+			continue
+		}
+		if err := h.world.AddPlayer(ctx, char); err != nil {
+			log.Ctx(ctx).Error().Err(err).Int32("char_id", char.ID).Msg("failed to restore offline store keeper")
+			continue
+		}
+		_ = h.world.UpdatePlayerRunWalkState(ctx, char.ID, true)
+		player, ok := h.world.GetPlayer(char.ID)
+		if !ok {
+			continue
+		}
+		h.loadPlayerSkills(ctx, player)
+		h.gameLoopCmd <- gameloop.CmdOfflineTradeRestore{CharID: char.ID, Store: s.Store}
+		restored++
+	}
+	log.Ctx(ctx).Info().Int("restored", restored).Int("persisted", len(stores)).Msg("offline stores restored")
+}
@@ -1,21 +1,135 @@
 package client
 
-func init() { addStubRegistrator(registerPartyStubs) }
+import (
+	"context"
 
-// registerPartyStubs регистрирует стаб-обработчики пакетов группы (High Five).
-func registerPartyStubs(r *Registry) {
-	// RequestJoinParty (0x42): пригласить игрока в группу. Для party-системы.
-	r.registerStub(StateInGame, 0x42, "RequestJoinParty")
+	"github.com/rs/zerolog/log"
+
+	"github.com/VerTox/l2go/internal/gameserver/gameloop"
+	"github.com/VerTox/l2go/internal/gameserver/packets/inclient"
+	"github.com/VerTox/l2go/internal/gameserver/transport/client"
+)
+
+func init() { addStubRegistrator(registerPartyHandlers) }
+
+// registerPartyHandlers регистрирует обработчики пакетов группы (High Five). Вся
+// логика группы живёт в game loop (gameloop/party.go) — хендлеры только парсят
+// пакет и пересылают команду.
+func registerPartyHandlers(r *Registry) {
+	// RequestJoinParty (0x42): пригласить игрока в группу.
+	r.register(StateInGame, 0x42, "RequestJoinParty", (*Handler).handleRequestJoinParty)
 	// RequestAnswerJoinParty (0x43): ответ приглашённого на приглашение в группу.
-	r.registerStub(StateInGame, 0x43, "RequestAnswerJoinParty")
+	r.register(StateInGame, 0x43, "RequestAnswerJoinParty", (*Handler).handleRequestAnswerJoinParty)
 	// RequestWithDrawalParty (0x44): добровольный выход игрока из группы.
-	r.registerStub(StateInGame, 0x44, "RequestWithDrawalParty")
+	r.register(StateInGame, 0x44, "RequestWithDrawalParty", (*Handler).handleRequestWithDrawalParty)
 	// RequestOustPartyMember (0x45): исключить участника из группы (лидером).
-	r.registerStub(StateInGame, 0x45, "RequestOustPartyMember")
+	r.register(StateInGame, 0x45, "RequestOustPartyMember", (*Handler).handleRequestOustPartyMember)
 	// RequestChangePartyLeader (0xD0:0x0c): сменить лидера группы.
-	r.registerMultiStub(StateInGame, 0x0c, "RequestChangePartyLeader")
+	r.registerMulti(StateInGame, 0x0c, "RequestChangePartyLeader", (*Handler).handleRequestChangePartyLeader)
 	// RequestPartyLootModification (0xD0:0x78): изменить тип распределения лута.
-	r.registerMultiStub(StateInGame, 0x78, "RequestPartyLootModification")
+	r.registerMulti(StateInGame, 0x78, "RequestPartyLootModification", (*Handler).handleRequestPartyLootModification)
 	// AnswerPartyLootModification (0xD0:0x79): ответ на изменение типа лута.
-	r.registerMultiStub(StateInGame, 0x79, "AnswerPartyLootModification")
+	r.registerMulti(StateInGame, 0x79, "AnswerPartyLootModification", (*Handler).handleAnswerPartyLootModification)
+}
+
+// handleRequestJoinParty forwards a party invitation to the game loop, which
+// validates both players and sends AskJoinParty to the invitee.
+func (h *Handler) handleRequestJoinParty(ctx context.Context, c *client.ClientConn, payload []byte) error {
+	pkt, err := inclient.ParseRequestJoinParty(payload)
+	if err != nil {
+		log.Ctx(ctx).Debug().Err(err).Msg("failed to parse RequestJoinParty")
+		return nil
+	}
+	player, ok := h.activePlayer(c)
+	if !ok {
+		return nil
+	}
+	h.gameLoopCmd <- gameloop.CmdPartyInvite{CharID: player.CharID, TargetName: pkt.Name, LootType: pkt.LootType}
+	return nil
+}
+
+// handleRequestAnswerJoinParty forwards the invitee's answer to the game loop.
+func (h *Handler) handleRequestAnswerJoinParty(ctx context.Context, c *client.ClientConn, payload []byte) error {
+	pkt, err := inclient.ParseRequestAnswerJoinParty(payload)
+	if err != nil {
+		log.Ctx(ctx).Debug().Err(err).Msg("failed to parse RequestAnswerJoinParty")
+		return nil
+	}
+	player, ok := h.activePlayer(c)
+	if !ok {
+		return nil
+	}
+	h.gameLoopCmd <- gameloop.CmdPartyAnswer{CharID: player.CharID, Accept: pkt.Response == 1}
+	return nil
+}
+
+// handleRequestWithDrawalParty forwards a voluntary party leave to the game loop.
+func (h *Handler) handleRequestWithDrawalParty(_ context.Context, c *client.ClientConn, _ []byte) error {
+	player, ok := h.activePlayer(c)
+	if !ok {
+		return nil
+	}
+	h.gameLoopCmd <- gameloop.CmdPartyLeave{CharID: player.CharID}
+	return nil
+}
+
+// handleRequestOustPartyMember forwards the leader's expel request to the game loop.
+func (h *Handler) handleRequestOustPartyMember(ctx context.Context, c *client.ClientConn, payload []byte) error {
+	pkt, err := inclient.ParseRequestPartyMemberName(payload)
+	if err != nil {
+		log.Ctx(ctx).Debug().Err(err).Msg("failed to parse RequestOustPartyMember")
+		return nil
+	}
+	player, ok := h.activePlayer(c)
+	if !ok {
+		return nil
+	}
+	h.gameLoopCmd <- gameloop.CmdPartyKick{CharID: player.CharID, TargetName: pkt.Name}
+	return nil
+}
+
+// handleRequestChangePartyLeader forwards a leadership transfer to the game loop.
+func (h *Handler) handleRequestChangePartyLeader(ctx context.Context, c *client.ClientConn, payload []byte) error {
+	pkt, err := inclient.ParseRequestPartyMemberName(payload)
+	if err != nil {
+		log.Ctx(ctx).Debug().Err(err).Msg("failed to parse RequestChangePartyLeader")
+		return nil
+	}
+	player, ok := h.activePlayer(c)
+	if !ok {
+		return nil
+	}
+	h.gameLoopCmd <- gameloop.CmdPartyChangeLeader{CharID: player.CharID, TargetName: pkt.Name}
+	return nil
+}
+
+// handleRequestPartyLootModification forwards the leader's loot-mode change to the
+// game loop, which starts the member vote.
+func (h *Handler) handleRequestPartyLootModification(ctx context.Context, c *client.ClientConn, payload []byte) error {
+	pkt, err := inclient.ParseRequestPartyLootModification(payload)
+	if err != nil {
+		log.Ctx(ctx).Debug().Err(err).Msg("failed to parse RequestPartyLootModification")
+		return nil
+	}
+	player, ok := h.activePlayer(c)
+	if !ok {
+		return nil
+	}
+	h.gameLoopCmd <- gameloop.CmdPartyLootChange{CharID: player.CharID, LootType: pkt.LootType}
+	return nil
+}
+
+// handleAnswerPartyLootModification forwards a member's loot-mode vote to the game loop.
+func (h *Handler) handleAnswerPartyLootModification(ctx context.Context, c *client.ClientConn, payload []byte) error {
+	pkt, err := inclient.ParseAnswerPartyLootModification(payload)
+	if err != nil {
+		log.Ctx(ctx).Debug().Err(err).Msg("failed to parse AnswerPartyLootModification")
+		return nil
+	}
+	player, ok := h.activePlayer(c)
+	if !ok {
+		return nil
+	}
+	h.gameLoopCmd <- gameloop.CmdPartyLootAnswer{CharID: player.CharID, Accept: pkt.Answer == 1}
+	return nil
 }
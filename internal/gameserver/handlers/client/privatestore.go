@@ -1,29 +1,398 @@
 package client
 
-func init() { addStubRegistrator(registerPrivateStoreStubs) }
+import (
+	"context"
+	"errors"
 
-// registerPrivateStoreStubs регистрирует стаб-обработчики пакетов личных магазинов (High Five).
-func registerPrivateStoreStubs(r *Registry) {
+	"github.com/rs/zerolog/log"
+
+	"github.com/VerTox/l2go/internal/gameserver/gameloop"
+	"github.com/VerTox/l2go/internal/gameserver/models"
+	"github.com/VerTox/l2go/internal/gameserver/packets/inclient"
+	"github.com/VerTox/l2go/internal/gameserver/packets/outclient"
+	"github.com/VerTox/l2go/internal/gameserver/registry"
+	"github.com/VerTox/l2go/internal/gameserver/transport/client"
+	"github.com/VerTox/l2go/internal/gameserver/usecase"
+)
+
+func init() { addStubRegistrator(registerPrivateStoreHandlers) }
+
+// registerPrivateStoreHandlers регистрирует обработчики личных магазинов (High Five).
+// Состояние магазина живёт в game loop (gameloop/privatestore.go); окна и сделки,
+// которым нужна БД, выполняет HandleStoreJob.
+func registerPrivateStoreHandlers(r *Registry) {
 	// RequestPrivateStoreManageSell (0x30): открыть управление магазином продажи.
-	r.registerStub(StateInGame, 0x30, "RequestPrivateStoreManageSell")
+	r.register(StateInGame, 0x30, "RequestPrivateStoreManageSell", (*Handler).handleRequestPrivateStoreManageSell)
 	// SetPrivateStoreListSell (0x31): задать список товаров на продажу.
-	r.registerStub(StateInGame, 0x31, "SetPrivateStoreListSell")
+	r.register(StateInGame, 0x31, "SetPrivateStoreListSell", (*Handler).handleSetPrivateStoreListSell)
 	// RequestPrivateStoreBuy (0x83): купить в личном магазине продавца.
-	r.registerStub(StateInGame, 0x83, "RequestPrivateStoreBuy")
+	r.register(StateInGame, 0x83, "RequestPrivateStoreBuy", (*Handler).handleRequestPrivateStoreBuy)
 	// RequestPrivateStoreQuitSell (0x96): закрыть магазин продажи.
-	r.registerStub(StateInGame, 0x96, "RequestPrivateStoreQuitSell")
+	r.register(StateInGame, 0x96, "RequestPrivateStoreQuitSell", (*Handler).handleRequestPrivateStoreQuit)
 	// SetPrivateStoreMsgSell (0x97): задать сообщение магазина продажи.
-	r.registerStub(StateInGame, 0x97, "SetPrivateStoreMsgSell")
+	r.register(StateInGame, 0x97, "SetPrivateStoreMsgSell", (*Handler).handleSetPrivateStoreMsgSell)
 	// RequestPrivateStoreManageBuy (0x99): открыть управление магазином покупки.
-	r.registerStub(StateInGame, 0x99, "RequestPrivateStoreManageBuy")
+	r.register(StateInGame, 0x99, "RequestPrivateStoreManageBuy", (*Handler).handleRequestPrivateStoreManageBuy)
 	// SetPrivateStoreListBuy (0x9a): задать список в магазине покупки.
-	r.registerStub(StateInGame, 0x9a, "SetPrivateStoreListBuy")
+	r.register(StateInGame, 0x9a, "SetPrivateStoreListBuy", (*Handler).handleSetPrivateStoreListBuy)
 	// RequestPrivateStoreQuitBuy (0x9c): закрыть магазин покупки.
-	r.registerStub(StateInGame, 0x9c, "RequestPrivateStoreQuitBuy")
+	r.register(StateInGame, 0x9c, "RequestPrivateStoreQuitBuy", (*Handler).handleRequestPrivateStoreQuit)
 	// SetPrivateStoreMsgBuy (0x9d): задать сообщение магазина покупки.
-	r.registerStub(StateInGame, 0x9d, "SetPrivateStoreMsgBuy")
+	r.register(StateInGame, 0x9d, "SetPrivateStoreMsgBuy", (*Handler).handleSetPrivateStoreMsgBuy)
 	// RequestPrivateStoreSell (0x9f): продать в личный магазин покупки (покупатель).
-	r.registerStub(StateInGame, 0x9f, "RequestPrivateStoreSell")
+	r.register(StateInGame, 0x9f, "RequestPrivateStoreSell", (*Handler).handleRequestPrivateStoreSell)
 	// SetPrivateStoreWholeMsg (0xD0:0x4a): сообщение для всего магазина.
-	r.registerMultiStub(StateInGame, 0x4a, "SetPrivateStoreWholeMsg")
+	r.registerMulti(StateInGame, 0x4a, "SetPrivateStoreWholeMsg", (*Handler).handleSetPrivateStoreWholeMsg)
+}
+
+// handleRequestPrivateStoreManageSell asks the game loop for the sell store
+// setup window.
+func (h *Handler) handleRequestPrivateStoreManageSell(ctx context.Context, c *client.ClientConn, payload []byte) error {
+	player, ok := h.activePlayer(c)
+	if !ok {
+		return nil
+	}
+	h.gameLoopCmd <- gameloop.CmdPrivateStoreManage{CharID: player.CharID}
+	return nil
+}
+
+// handleRequestPrivateStoreManageBuy asks the game loop for the buy store
+// setup window.
+func (h *Handler) handleRequestPrivateStoreManageBuy(ctx context.Context, c *client.ClientConn, payload []byte) error {
+	player, ok := h.activePlayer(c)
+	if !ok {
+		return nil
+	}
+	h.gameLoopCmd <- gameloop.CmdPrivateStoreManage{CharID: player.CharID, Buy: true}
+	return nil
+}
+
+// handleSetPrivateStoreListSell checks every listed stack against the inventory
+// (ownership, count, tradeable, not adena) and forwards the list to the game
+// loop. One bad line refuses the whole list, as in L2J.
+func (h *Handler) handleSetPrivateStoreListSell(ctx context.Context, c *client.ClientConn, payload []byte) error {
+	pkt, err := inclient.ParseSetPrivateStoreListSell(payload)
+	if err != nil {
+		log.Ctx(ctx).Debug().Err(err).Msg("failed to parse SetPrivateStoreListSell")
+		return nil
+	}
+	player, ok := h.activePlayer(c)
+	if !ok {
+		return nil
+	}
+	items := make([]registry.StoreItem, 0, len(pkt.Items))
+	for _, line := range pkt.Items {
+		item, err := h.inventoryUseCase.TradeItem(ctx, player.CharID, line.ObjectID, line.Count)
+		if err != nil {
+			log.Ctx(ctx).Error().Err(err).Msg("SetPrivateStoreListSell lookup failed")
+			return c.Send(outclient.BuildActionFailed())
+		}
+		if item == nil || item.ItemID == models.ItemIDAdena || line.Price < 0 || hasStoreObject(items, line.ObjectID) {
+			log.Ctx(ctx).Debug().Int32("object_id", line.ObjectID).Int64("count", line.Count).Msg("item cannot be sold in a private store")
+			return c.Send(outclient.BuildActionFailed())
+		}
+		items = append(items, registry.StoreItem{Item: *item, Count: line.Count, Price: line.Price})
+	}
+	h.gameLoopCmd <- gameloop.CmdPrivateStoreSetSell{CharID: player.CharID, Package: pkt.PackageSale, Items: items}
+	return nil
+}
+
+// hasStoreObject reports whether a stack is already on the list being built.
+func hasStoreObject(items []registry.StoreItem, objectID int32) bool {
+	for _, it := range items {
+		if it.Item.ObjectID == objectID {
+			return true
+		}
+	}
+	return false
+}
+
+// handleSetPrivateStoreListBuy checks the wanted items (a tradeable template,
+// not adena) and that the owner can pay for the whole list, then forwards it
+// to the game loop.
+func (h *Handler) handleSetPrivateStoreListBuy(ctx context.Context, c *client.ClientConn, payload []byte) error {
+	pkt, err := inclient.ParseSetPrivateStoreListBuy(payload)
+	if err != nil {
+		log.Ctx(ctx).Debug().Err(err).Msg("failed to parse SetPrivateStoreListBuy")
+		return nil
+	}
+	player, ok := h.activePlayer(c)
+	if !ok {
+		return nil
+	}
+	items := make([]registry.StoreItem, 0, len(pkt.Items))
+	var total int64
+	for _, line := range pkt.Items {
+		if !h.inventoryUseCase.StoreBuyable(line.ItemID) || line.Count <= 0 || line.Price < 0 || (line.Price > 0 && line.Count > (usecase.MaxAdena-total)/line.Price) {
+			log.Ctx(ctx).Debug().Int32("item_id", line.ItemID).Msg("item cannot be bought in a private store")
+			return c.Send(outclient.BuildActionFailed())
+		}
+		total += line.Count * line.Price
+		items = append(items, registry.StoreItem{
+			Item:  models.CharacterItem{ItemID: line.ItemID, EnchantLevel: int(line.EnchantLevel)},
+			Count: line.Count,
+			Price: line.Price,
+		})
+	}
+	adena, err := h.inventoryUseCase.AdenaCount(ctx, player.CharID)
+	if err != nil {
+		log.Ctx(ctx).Error().Err(err).Msg("SetPrivateStoreListBuy adena lookup failed")
+		return c.Send(outclient.BuildActionFailed())
+	}
+	if total > adena {
+		return c.Send(outclient.BuildSystemMessageNoParams(outclient.SysMsgNotEnoughAdena))
+	}
+	h.gameLoopCmd <- gameloop.CmdPrivateStoreSetBuy{CharID: player.CharID, Items: items}
+	return nil
+}
+
+// handleRequestPrivateStoreQuit closes the player's sell or buy store.
+func (h *Handler) handleRequestPrivateStoreQuit(ctx context.Context, c *client.ClientConn, payload []byte) error {
+	player, ok := h.activePlayer(c)
+	if !ok {
+		return nil
+	}
+	h.gameLoopCmd <- gameloop.CmdPrivateStoreQuit{CharID: player.CharID}
+	return nil
+}
+
+func (h *Handler) handleSetPrivateStoreMsgSell(ctx context.Context, c *client.ClientConn, payload []byte) error {
+	return h.setPrivateStoreMsg(ctx, c, payload, gameloop.StoreMsgSell)
+}
+
+func (h *Handler) handleSetPrivateStoreMsgBuy(ctx context.Context, c *client.ClientConn, payload []byte) error {
+	return h.setPrivateStoreMsg(ctx, c, payload, gameloop.StoreMsgBuy)
+}
+
+func (h *Handler) handleSetPrivateStoreWholeMsg(ctx context.Context, c *client.ClientConn, payload []byte) error {
+	return h.setPrivateStoreMsg(ctx, c, payload, gameloop.StoreMsgWhole)
+}
+
+// setPrivateStoreMsg forwards a store title to the game loop.
+func (h *Handler) setPrivateStoreMsg(ctx context.Context, c *client.ClientConn, payload []byte, kind gameloop.StoreMsgKind) error {
+	pkt, err := inclient.ParseSetPrivateStoreMsg(payload)
+	if err != nil {
+		log.Ctx(ctx).Debug().Err(err).Msg("failed to parse SetPrivateStoreMsg")
+		return nil
+	}
+	player, ok := h.activePlayer(c)
+	if !ok {
+		return nil
+	}
+	h.gameLoopCmd <- gameloop.CmdPrivateStoreMsg{CharID: player.CharID, Kind: kind, Message: pkt.Message}
+	return nil
+}
+
+// handleRequestPrivateStoreBuy forwards a purchase from a sell store; the game
+// loop checks it against the live store list.
+func (h *Handler) handleRequestPrivateStoreBuy(ctx context.Context, c *client.ClientConn, payload []byte) error {
+	pkt, err := inclient.ParseRequestPrivateStoreBuy(payload)
+	if err != nil {
+		log.Ctx(ctx).Debug().Err(err).Msg("failed to parse RequestPrivateStoreBuy")
+		return nil
+	}
+	player, ok := h.activePlayer(c)
+	if !ok {
+		return nil
+	}
+	items := make([]registry.StoreItem, len(pkt.Items))
+	for i, line := range pkt.Items {
+		items[i] = registry.StoreItem{Item: models.CharacterItem{ObjectID: line.ObjectID}, Count: line.Count, Price: line.Price}
+	}
+	h.gameLoopCmd <- gameloop.CmdPrivateStoreBuy{CharID: player.CharID, StoreCharID: pkt.StoreObjectID, Items: items}
+	return nil
+}
+
+// handleRequestPrivateStoreSell checks the offered stacks against the seller's
+// inventory and forwards the sale into a buy store to the game loop.
+func (h *Handler) handleRequestPrivateStoreSell(ctx context.Context, c *client.ClientConn, payload []byte) error {
+	pkt, err := inclient.ParseRequestPrivateStoreSell(payload)
+	if err != nil {
+		log.Ctx(ctx).Debug().Err(err).Msg("failed to parse RequestPrivateStoreSell")
+		return nil
+	}
+	player, ok := h.activePlayer(c)
+	if !ok {
+		return nil
+	}
+	items := make([]registry.StoreItem, 0, len(pkt.Items))
+	for _, line := range pkt.Items {
+		item, err := h.inventoryUseCase.TradeItem(ctx, player.CharID, line.ObjectID, line.Count)
+		if err != nil {
+			log.Ctx(ctx).Error().Err(err).Msg("RequestPrivateStoreSell lookup failed")
+			return c.Send(outclient.BuildActionFailed())
+		}
+		if item == nil || item.ItemID != line.ItemID {
+			log.Ctx(ctx).Debug().Int32("object_id", line.ObjectID).Msg("item cannot be sold to the store")
+			return c.Send(outclient.BuildActionFailed())
+		}
+		items = append(items, registry.StoreItem{Item: *item, Count: line.Count, Price: line.Price})
+	}
+	h.gameLoopCmd <- gameloop.CmdPrivateStoreSell{CharID: player.CharID, StoreCharID: pkt.StoreObjectID, Items: items}
+	return nil
+}
+
+// HandleStoreJob runs the database side of a private store for the game loop's
+// store worker: setup and browse windows read the current inventory, and a
+// deal is settled transactionally and reported back to the loop, which either
+// returns the reserved lines to the store or closes an emptied one.
+func (h *Handler) HandleStoreJob(ctx context.Context, job gameloop.StoreJob) {
+	switch job.Kind {
+	case gameloop.StoreJobManage:
+		h.sendStoreManageList(ctx, job.CharID, job.Store)
+	case gameloop.StoreJobBrowse:
+		h.sendStoreList(ctx, job.CharID, job.StoreCharID, job.Store)
+	case gameloop.StoreJobDeal:
+		h.settleStoreDeal(ctx, job)
+	case gameloop.StoreJobOfflineSave:
+		// A keeper already logged out by its owner must not come back.
+		if _, ok := h.world.GetPlayer(job.CharID); !ok {
+			return
+		}
+		if err := h.inventoryUseCase.SaveOfflineStore(ctx, job.CharID, job.Store); err != nil {
+			log.Ctx(ctx).Error().Err(err).Int32("char_id", job.CharID).Msg("failed to persist offline store")
+		}
+	case gameloop.StoreJobOfflineEnd:
+		if err := h.inventoryUseCase.DeleteOfflineStore(ctx, job.CharID); err != nil {
+			log.Ctx(ctx).Error().Err(err).Int32("char_id", job.CharID).Msg("failed to forget offline store")
+		}
+	}
+}
+
+// sendStoreManageList sends the owner its setup window: what it can still list
+// and what is listed already.
+func (h *Handler) sendStoreManageList(ctx context.Context, charID int32, store registry.PrivateStore) {
+	inv, adena, err := h.inventoryUseCase.StoreInventory(ctx, charID)
+	if err != nil {
+		log.Ctx(ctx).Error().Err(err).Int32("char_id", charID).Msg("failed to load store inventory")
+		return
+	}
+	if store.Type == registry.StoreBuyManage {
+		listed := make([]outclient.StoreItemRow, len(store.BuyList))
+		for i, line := range store.BuyList {
+			listed[i] = storeRow(line.Item, line.Count, line.Price)
+			listed[i].StoreCount = line.Count
+		}
+		h.sendToCharacter(charID, outclient.BuildPrivateStoreManageListBuy(charID, adena, storeRows(inv), listed))
+		return
+	}
+	available := inv[:0]
+	for _, it := range inv {
+		if !storeListHasObject(store.SellList, it.ObjectID) {
+			available = append(available, it)
+		}
+	}
+	listed := make([]outclient.StoreItemRow, len(store.SellList))
+	for i, line := range store.SellList {
+		listed[i] = storeRow(line.Item, line.Count, line.Price)
+	}
+	h.sendToCharacter(charID, outclient.BuildPrivateStoreManageListSell(charID, store.Package, adena, storeRows(available), listed))
+}
+
+// sendStoreList shows a visitor the store: a sell store's lines as listed, a
+// buy store's lines matched to the visitor's own stacks it could sell.
+func (h *Handler) sendStoreList(ctx context.Context, visitorID, storeCharID int32, store registry.PrivateStore) {
+	if store.Type.Selling() {
+		adena, err := h.inventoryUseCase.AdenaCount(ctx, visitorID)
+		if err != nil {
+			log.Ctx(ctx).Error().Err(err).Int32("char_id", visitorID).Msg("failed to load adena")
+			return
+		}
+		rows := make([]outclient.StoreItemRow, len(store.SellList))
+		for i, line := range store.SellList {
+			rows[i] = storeRow(line.Item, line.Count, line.Price)
+		}
+		h.sendToCharacter(visitorID, outclient.BuildPrivateStoreListSell(storeCharID, store.Type == registry.StorePackageSell, adena, rows))
+		return
+	}
+	inv, adena, err := h.inventoryUseCase.StoreInventory(ctx, visitorID)
+	if err != nil {
+		log.Ctx(ctx).Error().Err(err).Int32("char_id", visitorID).Msg("failed to load store inventory")
+		return
+	}
+	var rows []outclient.StoreItemRow
+	for _, line := range store.BuyList {
+		for _, it := range inv {
+			if it.ItemID != line.Item.ItemID || it.EnchantLevel != line.Item.EnchantLevel {
+				continue
+			}
+			row := storeRow(it, min(it.Count, line.Count), line.Price)
+			row.StoreCount = line.Count
+			rows = append(rows, row)
+			break
+		}
+	}
+	h.sendToCharacter(visitorID, outclient.BuildPrivateStoreListBuy(storeCharID, adena, rows))
+}
+
+// settleStoreDeal runs the purchase transaction, tells both sides what changed
+// hands and reports the outcome back to the game loop.
+func (h *Handler) settleStoreDeal(ctx context.Context, job gameloop.StoreJob) {
+	done := gameloop.CmdPrivateStoreDealDone{
+		StoreCharID: job.StoreCharID,
+		Seq:         job.Seq,
+		BuyList:     job.StoreCharID == job.BuyerID,
+		Lines:       job.Lines,
+	}
+	changedSeller, changedBuyer, err := h.inventoryUseCase.ExecuteStoreDeal(ctx, job.SellerID, job.BuyerID, job.Items, job.Total)
+	if err != nil {
+		log.Ctx(ctx).Warn().Err(err).Int32("seller", job.SellerID).Int32("buyer", job.BuyerID).Msg("private store deal failed")
+		if errors.Is(err, usecase.ErrNotEnoughAdena) && job.BuyerID == job.CharID {
+			h.sendToCharacter(job.CharID, outclient.BuildSystemMessageNoParams(outclient.SysMsgNotEnoughAdena))
+		}
+		h.sendToCharacter(job.CharID, outclient.BuildActionFailed())
+		h.gameLoopCmd <- done
+		return
+	}
+	done.OK = true
+	h.gameLoopCmd <- done
+
+	for i, t := range job.Items {
+		itemID := job.Lines[i].Item.ItemID
+		if t.Count > 1 {
+			h.sendToCharacter(job.SellerID, outclient.NewSystemMessage(outclient.SysMsgC1PurchasedS3S2).
+				AddPlayerName(job.BuyerName).AddItemName(itemID).AddLong(t.Count).Build())
+			h.sendToCharacter(job.BuyerID, outclient.NewSystemMessage(outclient.SysMsgPurchasedS3S2FromC1).
+				AddPlayerName(job.SellerName).AddItemName(itemID).AddLong(t.Count).Build())
+			continue
+		}
+		h.sendToCharacter(job.SellerID, outclient.NewSystemMessage(outclient.SysMsgC1PurchasedS2).
+			AddPlayerName(job.BuyerName).AddItemName(itemID).Build())
+		h.sendToCharacter(job.BuyerID, outclient.NewSystemMessage(outclient.SysMsgPurchasedS2FromC1).
+			AddPlayerName(job.SellerName).AddItemName(itemID).Build())
+	}
+	h.SendInventoryUpdate(job.SellerID, changedSeller)
+	h.SendInventoryUpdate(job.BuyerID, changedBuyer)
+}
+
+// storeListHasObject reports whether a stack is on a sell list.
+func storeListHasObject(list []registry.StoreItem, objectID int32) bool {
+	for _, line := range list {
+		if line.Item.ObjectID == objectID {
+			return true
+		}
+	}
+	return false
+}
+
+// storeRow is the store-window view of count units of an item at price.
+func storeRow(item models.CharacterItem, count, price int64) outclient.StoreItemRow {
+	row := outclient.StoreItemRow{
+		Item:  convertCharacterItemsToInventoryItems([]models.CharacterItem{item})[0],
+		Price: price,
+	}
+	row.Item.Count = count
+	if tmpl := registry.GetItemTemplateRegistry().Get(item.ItemID); tmpl != nil {
+		row.RefPrice = tmpl.Price * 2
+	}
+	return row
+}
+
+// storeRows lists inventory stacks for a setup window.
+func storeRows(items []models.CharacterItem) []outclient.StoreItemRow {
+	rows := make([]outclient.StoreItemRow, len(items))
+	for i, it := range items {
+		rows[i] = storeRow(it, it.Count, 0)
+	}
+	return rows
 }
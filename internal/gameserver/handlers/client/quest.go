@@ -1,11 +1,69 @@
 package client
 
-func init() { addStubRegistrator(registerQuestStubs) }
-
-// registerQuestStubs регистрирует стаб-обработчики пакетов квестов (High Five).
-func registerQuestStubs(r *Registry) {
-	// RequestQuestList (0x62): список квестов персонажа. Для системы квестов.
-	r.registerStub(StateInGame, 0x62, "RequestQuestList")
-	// RequestQuestAbort (0x63): отменить квест. Для системы квестов.
-	r.registerStub(StateInGame, 0x63, "RequestQuestAbort")
+import (
+	"context"
+
+	"github.com/rs/zerolog/log"
+
+	"github.com/VerTox/l2go/internal/gameserver/gameloop"
+	"github.com/VerTox/l2go/internal/gameserver/packets/inclient"
+	"github.com/VerTox/l2go/internal/gameserver/registry"
+	"github.com/VerTox/l2go/internal/gameserver/transport/client"
+)
+
+func init() { addStubRegistrator(registerQuestHandlers) }
+
+// registerQuestHandlers регистрирует обработчики пакетов квестов (High Five).
+// Сами квесты — скрипты в game loop (gameloop/quest.go).
+func registerQuestHandlers(r *Registry) {
+	// RequestQuestList (0x62): список квестов персонажа (QuestList).
+	r.register(StateInGame, 0x62, "RequestQuestList", (*Handler).handleRequestQuestList)
+	// RequestQuestAbort (0x63): отменить квест из журнала.
+	r.register(StateInGame, 0x63, "RequestQuestAbort", (*Handler).handleRequestQuestAbort)
+}
+
+func (h *Handler) handleRequestQuestList(ctx context.Context, c *client.ClientConn, payload []byte) error {
+	player, ok := h.activePlayer(c)
+	if !ok {
+		return nil
+	}
+	h.gameLoopCmd <- gameloop.CmdQuestList{CharID: player.CharID}
+	return nil
+}
+
+func (h *Handler) handleRequestQuestAbort(ctx context.Context, c *client.ClientConn, payload []byte) error {
+	pkt, err := inclient.ParseRequestQuestAbort(payload)
+	if err != nil {
+		log.Ctx(ctx).Debug().Err(err).Msg("failed to parse RequestQuestAbort")
+		return nil
+	}
+	player, ok := h.activePlayer(c)
+	if !ok {
+		return nil
+	}
+	h.gameLoopCmd <- gameloop.CmdQuestAbort{CharID: player.CharID, QuestID: pkt.QuestID}
+	return nil
+}
+
+// HandleQuestJob saves a quest step: the quest items it took and handed out
+// together with the quest's variables, in one transaction. A failure is
+// logged and the player keeps playing on the loop's copy of the quest.
+func (h *Handler) HandleQuestJob(ctx context.Context, job gameloop.QuestJob) {
+	changed, err := h.inventoryUseCase.SaveQuestStep(ctx, job.CharID, job.Quest, job.Vars, job.Take, job.Give)
+	if err != nil {
+		log.Ctx(ctx).Error().Err(err).Int32("char_id", job.CharID).Str("quest", job.Quest).Msg("failed to save quest step")
+		return
+	}
+	h.SendInventoryUpdate(job.CharID, changed)
+}
+
+// loadPlayerQuests loads the character's quests into its world state at world
+// entry, before the game loop reads them.
+func (h *Handler) loadPlayerQuests(ctx context.Context, player *registry.PlayerWorldState) {
+	quests, err := h.inventoryUseCase.LoadQuests(ctx, player.CharID)
+	if err != nil {
+		log.Ctx(ctx).Error().Err(err).Int32("char_id", player.CharID).Msg("failed to load quests at world entry")
+		return
+	}
+	player.Quests = quests
 }
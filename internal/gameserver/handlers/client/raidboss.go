@@ -0,0 +1,45 @@
+package client
+
+import (
+	"context"
+
+	"github.com/rs/zerolog/log"
+
+	"github.com/VerTox/l2go/internal/gameserver/gameloop"
+	"github.com/VerTox/l2go/internal/gameserver/packets/outclient"
+	"github.com/VerTox/l2go/internal/gameserver/transport/client"
+)
+
+func init() { addStubRegistrator(registerRaidBossHandlers) }
+
+// registerRaidBossHandlers регистрирует обработчики пакетов рейд-боссов (High
+// Five). Сами боссы живут в game loop (gameloop/raidboss.go).
+func registerRaidBossHandlers(r *Registry) {
+	// RequestGetBossRecord (0xD0:0x40): окно очков за рейд-боссов (ExGetBossRecord).
+	r.registerMulti(StateInGame, 0x40, "RequestGetBossRecord", (*Handler).handleRequestGetBossRecord)
+}
+
+func (h *Handler) handleRequestGetBossRecord(ctx context.Context, c *client.ClientConn, payload []byte) error {
+	player, ok := h.activePlayer(c)
+	if !ok {
+		return nil
+	}
+	rec, err := h.raidBossUseCase.GetBossRecord(ctx, player.CharID)
+	if err != nil {
+		log.Ctx(ctx).Error().Err(err).Int32("char_id", player.CharID).Msg("failed to load raid points")
+		return nil
+	}
+	bosses := make([]outclient.BossRecord, 0, len(rec.Bosses))
+	for _, b := range rec.Bosses {
+		bosses = append(bosses, outclient.BossRecord{BossID: b.BossID, Points: b.Points})
+	}
+	return c.Send(outclient.BuildExGetBossRecord(rec.Ranking, rec.Total, bosses))
+}
+
+// HandleRaidBossJob saves a raid boss's state and the raid points of its kill.
+// Best effort: a failure is logged and the boss lives on in the loop.
+func (h *Handler) HandleRaidBossJob(ctx context.Context, job gameloop.RaidBossJob) {
+	if err := h.raidBossUseCase.SaveRaidBoss(ctx, job.Status, job.Points); err != nil {
+		log.Ctx(ctx).Error().Err(err).Int32("boss_id", job.Status.BossID).Msg("failed to save raid boss")
+	}
+}
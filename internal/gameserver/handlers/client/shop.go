@@ -1,13 +1,30 @@
 package client
 
-func init() { addStubRegistrator(registerShopStubs) }
+import (
+	"context"
+	"errors"
 
-// registerShopStubs регистрирует стаб-обработчики пакетов торговли с NPC-магазином (High Five).
-func registerShopStubs(r *Registry) {
+	"github.com/rs/zerolog/log"
+
+	"github.com/VerTox/l2go/internal/gameserver/gameloop"
+	"github.com/VerTox/l2go/internal/gameserver/models"
+	"github.com/VerTox/l2go/internal/gameserver/packets/inclient"
+	"github.com/VerTox/l2go/internal/gameserver/packets/outclient"
+	"github.com/VerTox/l2go/internal/gameserver/registry"
+	"github.com/VerTox/l2go/internal/gameserver/transport/client"
+	"github.com/VerTox/l2go/internal/gameserver/usecase"
+)
+
+func init() { addStubRegistrator(registerShopHandlers) }
+
+// registerShopHandlers регистрирует обработчики торговли с NPC-магазином (High
+// Five). Окно магазина открывает bypass у торговца (gameloop/shop.go); покупку,
+// продажу и выкуп, которым нужна БД, выполняет HandleShopJob.
+func registerShopHandlers(r *Registry) {
 	// RequestSellItem (0x37): продать предмет торговцу.
-	r.registerStub(StateInGame, 0x37, "RequestSellItem")
+	r.register(StateInGame, 0x37, "RequestSellItem", (*Handler).handleRequestSellItem)
 	// RequestBuyItem (0x40): купить предмет у торговца.
-	r.registerStub(StateInGame, 0x40, "RequestBuyItem")
+	r.register(StateInGame, 0x40, "RequestBuyItem", (*Handler).handleRequestBuyItem)
 	// RequestBuySeed (0xc5): купить семена (система Manor).
 	r.registerStub(StateInGame, 0xc5, "RequestBuySeed")
 	// RequestPackageSendableItemList (0xa7): список предметов для пакетной отправки.
@@ -15,7 +32,185 @@ func registerShopStubs(r *Registry) {
 	// RequestPackageSend (0xa8): отправить пакет предметов.
 	r.registerStub(StateInGame, 0xa8, "RequestPackageSend")
 	// RequestRefundItem (0xD0:0x75): возврат предмета (buy-back).
-	r.registerMultiStub(StateInGame, 0x75, "RequestRefundItem")
+	r.registerMulti(StateInGame, 0x75, "RequestRefundItem", (*Handler).handleRequestRefundItem)
 	// RequestBuySellUIClose (0xD0:0x76): закрыть UI покупки/продажи.
 	r.registerMultiStub(StateInGame, 0x76, "RequestBuySellUIClose")
 }
+
+// handleRequestBuyItem forwards confirmed purchases to the game loop, which
+// checks the player is still at the merchant.
+func (h *Handler) handleRequestBuyItem(ctx context.Context, c *client.ClientConn, payload []byte) error {
+	pkt, err := inclient.ParseRequestBuyItem(payload)
+	if err != nil {
+		log.Ctx(ctx).Debug().Err(err).Msg("failed to parse RequestBuyItem")
+		return nil
+	}
+	player, ok := h.activePlayer(c)
+	if !ok {
+		return nil
+	}
+	items := make([]registry.BuyListLine, 0, len(pkt.Items))
+	for _, line := range pkt.Items {
+		if line.Count <= 0 || line.Count > usecase.MaxAdena {
+			return c.Send(outclient.BuildActionFailed())
+		}
+		items = append(items, registry.BuyListLine{ItemID: line.ItemID, Count: line.Count})
+	}
+	h.gameLoopCmd <- gameloop.CmdShopBuy{CharID: player.CharID, ListID: pkt.ListID, Items: items}
+	return nil
+}
+
+// handleRequestSellItem forwards confirmed sales to the game loop.
+func (h *Handler) handleRequestSellItem(ctx context.Context, c *client.ClientConn, payload []byte) error {
+	pkt, err := inclient.ParseRequestSellItem(payload)
+	if err != nil {
+		log.Ctx(ctx).Debug().Err(err).Msg("failed to parse RequestSellItem")
+		return nil
+	}
+	player, ok := h.activePlayer(c)
+	if !ok {
+		return nil
+	}
+	items := make([]usecase.ShopSale, 0, len(pkt.Items))
+	for _, line := range pkt.Items {
+		if line.Count <= 0 {
+			return c.Send(outclient.BuildActionFailed())
+		}
+		items = append(items, usecase.ShopSale{ObjectID: line.ObjectID, ItemID: line.ItemID, Count: line.Count})
+	}
+	h.gameLoopCmd <- gameloop.CmdShopSell{CharID: player.CharID, ListID: pkt.ListID, Items: items}
+	return nil
+}
+
+// handleRequestRefundItem forwards a buy-back to the game loop.
+func (h *Handler) handleRequestRefundItem(ctx context.Context, c *client.ClientConn, payload []byte) error {
+	pkt, err := inclient.ParseRequestRefundItem(payload)
+	if err != nil {
+		log.Ctx(ctx).Debug().Err(err).Msg("failed to parse RequestRefundItem")
+		return nil
+	}
+	player, ok := h.activePlayer(c)
+	if !ok {
+		return nil
+	}
+	h.gameLoopCmd <- gameloop.CmdShopRefund{CharID: player.CharID, ListID: pkt.ListID, Indexes: pkt.Indexes}
+	return nil
+}
+
+// HandleShopJob runs merchant work the game loop handed off: it sends the shop
+// window and settles purchases, sales and buy-backs, reporting the outcome to
+// the player. Limited stock is reserved before the purchase and handed back
+// if it fails; sold items go onto the refund list once the sale committed.
+func (h *Handler) HandleShopJob(ctx context.Context, job gameloop.ShopJob) {
+	var (
+		changed []usecase.ChangedItem
+		err     error
+	)
+	switch job.Kind {
+	case gameloop.ShopJobOpen:
+		h.sendShopWindow(ctx, job)
+		return
+	case gameloop.ShopJobBuy:
+		changed, err = h.buyFromShop(ctx, job)
+	case gameloop.ShopJobSell:
+		var sold []models.CharacterItem
+		changed, sold, err = h.inventoryUseCase.Sell(ctx, job.CharID, job.Sell)
+		if err == nil {
+			registry.GetRefundRegistry().Add(job.CharID, sold...)
+		}
+	case gameloop.ShopJobRefund:
+		items, ok := registry.GetRefundRegistry().Take(job.CharID, job.Refund)
+		if !ok {
+			h.sendToCharacter(job.CharID, outclient.BuildActionFailed())
+			return
+		}
+		changed, err = h.inventoryUseCase.Refund(ctx, job.CharID, items, job.InventorySlots)
+		if err != nil {
+			registry.GetRefundRegistry().Add(job.CharID, items...)
+		}
+	}
+
+	if err != nil {
+		log.Ctx(ctx).Warn().Err(err).Int32("char_id", job.CharID).Int32("list_id", job.ListID).Msg("merchant deal failed")
+		switch {
+		case errors.Is(err, usecase.ErrInventoryFull):
+			h.sendToCharacter(job.CharID, outclient.BuildSystemMessageNoParams(outclient.SysMsgSlotsFull))
+		case errors.Is(err, usecase.ErrNotEnoughAdena):
+			h.sendToCharacter(job.CharID, outclient.BuildSystemMessageNoParams(outclient.SysMsgNotEnoughAdena))
+		}
+		h.sendToCharacter(job.CharID, outclient.BuildActionFailed())
+		return
+	}
+	h.SendInventoryUpdate(job.CharID, changed)
+	h.sendSellList(ctx, job.CharID, true)
+}
+
+// buyFromShop prices the purchase off the buy list, reserving limited stock,
+// and pays for it.
+func (h *Handler) buyFromShop(ctx context.Context, job gameloop.ShopJob) ([]usecase.ChangedItem, error) {
+	lists := registry.GetBuyListRegistry()
+	priced, err := lists.Reserve(job.ListID, job.Buy)
+	if err != nil {
+		return nil, err
+	}
+	lines := make([]usecase.ShopPurchase, len(priced))
+	for i, p := range priced {
+		lines[i] = usecase.ShopPurchase{ItemID: p.ItemID, Count: p.Count, Price: p.Price}
+	}
+	changed, err := h.inventoryUseCase.Buy(ctx, job.CharID, lines, job.InventorySlots)
+	if err != nil {
+		lists.Release(job.ListID, job.Buy)
+		return nil, err
+	}
+	return changed, nil
+}
+
+// sendShopWindow sends the buy tab (ExBuyList) and the sell tab
+// (ExBuySellList) of list job.ListID.
+func (h *Handler) sendShopWindow(ctx context.Context, job gameloop.ShopJob) {
+	products, ok := registry.GetBuyListRegistry().Items(job.ListID)
+	if !ok {
+		return
+	}
+	adena, err := h.inventoryUseCase.AdenaCount(ctx, job.CharID)
+	if err != nil {
+		log.Ctx(ctx).Error().Err(err).Int32("char_id", job.CharID).Msg("failed to load adena")
+		return
+	}
+	items := make([]models.CharacterItem, len(products))
+	for i, p := range products {
+		// Buy list lines name the item id in place of an object id.
+		items[i] = models.CharacterItem{ObjectID: p.ItemID, ItemID: p.ItemID, Count: p.Count, Loc: string(models.LocInventory)}
+	}
+	lines := make([]outclient.ShopItem, len(products))
+	for i, it := range convertCharacterItemsToInventoryItems(items) {
+		lines[i] = outclient.ShopItem{Item: it, Price: products[i].Price}
+	}
+	h.sendToCharacter(job.CharID, outclient.BuildBuyList(adena, job.ListID, lines))
+	h.sendSellList(ctx, job.CharID, false)
+}
+
+// sendSellList sends ExBuySellList: the inventory the merchant buys and the
+// refund list, both priced at what the merchant pays.
+func (h *Handler) sendSellList(ctx context.Context, charID int32, done bool) {
+	sellable, err := h.inventoryUseCase.SellableInventory(ctx, charID)
+	if err != nil {
+		log.Ctx(ctx).Error().Err(err).Int32("char_id", charID).Msg("failed to load sellable inventory")
+		return
+	}
+	h.sendToCharacter(charID, outclient.BuildExBuySellList(
+		shopItems(sellable), shopItems(registry.GetRefundRegistry().List(charID)), done))
+}
+
+// shopItems prices items at what a merchant pays for them.
+func shopItems(items []models.CharacterItem) []outclient.ShopItem {
+	out := make([]outclient.ShopItem, len(items))
+	for i, it := range convertCharacterItemsToInventoryItems(items) {
+		var price int64
+		if tmpl := registry.GetItemTemplateRegistry().Get(it.ItemID); tmpl != nil {
+			price = usecase.SellPrice(tmpl)
+		}
+		out[i] = outclient.ShopItem{Item: it, Price: price}
+	}
+	return out
+}
@@ -8,7 +8,6 @@ import (
 	"github.com/VerTox/l2go/internal/gameserver/gameloop"
 	"github.com/VerTox/l2go/internal/gameserver/packets/inclient"
 	"github.com/VerTox/l2go/internal/gameserver/packets/outclient"
-	"github.com/VerTox/l2go/internal/gameserver/registry"
 	"github.com/VerTox/l2go/internal/gameserver/transport/client"
 )
 
@@ -41,6 +40,14 @@ func (h *Handler) handleAction(ctx context.Context, c *client.ClientConn, payloa
 
 	logger.Debug().Msg("Action on object")
 
+	// Клик по предмету на земле — сразу подбор, без выбора цели
+	// (L2J L2ItemInstance.onAction → AI_INTENTION_PICK_UP).
+	if _, isItem := h.world.GetItem(pkt.ObjectID); isItem {
+		logger.Debug().Msg("pick up request")
+		h.gameLoopCmd <- gameloop.CmdPickUp{CharID: playerState.CharID, ObjectID: pkt.ObjectID}
+		return c.Send(outclient.BuildActionFailed())
+	}
+
 	// Try to find the target object (NPC or player)
 	npc, targetIsNPC := h.world.GetNPC(pkt.ObjectID)
 	_, targetIsPlayer := h.world.GetPlayer(pkt.ObjectID)
@@ -92,16 +99,7 @@ func (h *Handler) handleAction(ctx context.Context, c *client.ClientConn, payloa
 			)); err != nil {
 				logger.Warn().Err(err).Msg("failed to send MoveToPawn")
 			}
-			html := outclient.DefaultNpcHtml
-			// Skill trainers that teach this player's class offer a "Learn Skills"
-			// bypass link (l2go-hv9).
-			if playerState.Character != nil && registry.IsTrainer(npc.TemplateID) &&
-				registry.CanTeach(npc.TemplateID,
-					int(playerState.Character.Race), int(playerState.Character.Sex), int(playerState.Character.ClassID)) {
-				html = "<html><body>Skill Trainer<br><br>" +
-					"<a action=\"bypass -h learn_skills\">Learn Skills</a>" +
-					"</body></html>"
-			}
+			html := gameloop.NpcDialogHtml(playerState, npc)
 			if err := c.Send(outclient.BuildNpcHtmlMessage(pkt.ObjectID, html)); err != nil {
 				logger.Warn().Err(err).Msg("failed to send NpcHtmlMessage")
 			}
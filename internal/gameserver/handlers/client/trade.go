@@ -1,15 +1,152 @@
 package client
 
-func init() { addStubRegistrator(registerTradeStubs) }
+import (
+	"context"
+	"errors"
 
-// registerTradeStubs регистрирует стаб-обработчики пакетов обмена между игроками (High Five).
-func registerTradeStubs(r *Registry) {
+	"github.com/rs/zerolog/log"
+
+	"github.com/VerTox/l2go/internal/gameserver/gameloop"
+	"github.com/VerTox/l2go/internal/gameserver/packets/inclient"
+	"github.com/VerTox/l2go/internal/gameserver/packets/outclient"
+	"github.com/VerTox/l2go/internal/gameserver/transport/client"
+	"github.com/VerTox/l2go/internal/gameserver/usecase"
+)
+
+func init() { addStubRegistrator(registerTradeHandlers) }
+
+// registerTradeHandlers регистрирует обработчики обмена между игроками (High Five).
+// Сессия обмена живёт в game loop (gameloop/trade.go); сам обмен предметами
+// выполняется транзакцией в InventoryUseCase.ExecuteTrade (HandleTradeJob).
+func registerTradeHandlers(r *Registry) {
 	// TradeRequest (0x1a): инициировать обмен с игроком.
-	r.registerStub(StateInGame, 0x1a, "TradeRequest")
+	r.register(StateInGame, 0x1a, "TradeRequest", (*Handler).handleTradeRequest)
 	// AddTradeItem (0x1b): добавить предмет в окно обмена.
-	r.registerStub(StateInGame, 0x1b, "AddTradeItem")
+	r.register(StateInGame, 0x1b, "AddTradeItem", (*Handler).handleAddTradeItem)
 	// TradeDone (0x1c): подтвердить/завершить обмен.
-	r.registerStub(StateInGame, 0x1c, "TradeDone")
+	r.register(StateInGame, 0x1c, "TradeDone", (*Handler).handleTradeDone)
 	// AnswerTradeRequest (0x55): принять/отклонить запрос обмена.
-	r.registerStub(StateInGame, 0x55, "AnswerTradeRequest")
+	r.register(StateInGame, 0x55, "AnswerTradeRequest", (*Handler).handleAnswerTradeRequest)
+}
+
+// handleTradeRequest forwards a trade request to the game loop, which validates
+// both players and sends SendTradeRequest to the target.
+func (h *Handler) handleTradeRequest(ctx context.Context, c *client.ClientConn, payload []byte) error {
+	pkt, err := inclient.ParseTradeRequest(payload)
+	if err != nil {
+		log.Ctx(ctx).Debug().Err(err).Msg("failed to parse TradeRequest")
+		return nil
+	}
+	player, ok := h.activePlayer(c)
+	if !ok {
+		return nil
+	}
+	h.gameLoopCmd <- gameloop.CmdTradeRequest{CharID: player.CharID, TargetObjectID: pkt.ObjectID}
+	return nil
+}
+
+// handleAnswerTradeRequest forwards the asked player's answer to the game loop.
+func (h *Handler) handleAnswerTradeRequest(ctx context.Context, c *client.ClientConn, payload []byte) error {
+	pkt, err := inclient.ParseTradeResponse(payload)
+	if err != nil {
+		log.Ctx(ctx).Debug().Err(err).Msg("failed to parse AnswerTradeRequest")
+		return nil
+	}
+	player, ok := h.activePlayer(c)
+	if !ok {
+		return nil
+	}
+	h.gameLoopCmd <- gameloop.CmdTradeAnswer{CharID: player.CharID, Accept: pkt.Response == 1}
+	return nil
+}
+
+// handleAddTradeItem checks the offered item against the inventory (ownership,
+// tradeable, enough units) and forwards it to the game loop's trade session.
+// Untradeable or missing items are ignored, as in L2J.
+func (h *Handler) handleAddTradeItem(ctx context.Context, c *client.ClientConn, payload []byte) error {
+	pkt, err := inclient.ParseAddTradeItem(payload)
+	if err != nil {
+		log.Ctx(ctx).Debug().Err(err).Msg("failed to parse AddTradeItem")
+		return nil
+	}
+	player, ok := h.activePlayer(c)
+	if !ok {
+		return nil
+	}
+	item, err := h.inventoryUseCase.TradeItem(ctx, player.CharID, pkt.ObjectID, pkt.Count)
+	if err != nil {
+		log.Ctx(ctx).Error().Err(err).Msg("AddTradeItem lookup failed")
+		return nil
+	}
+	if item == nil {
+		log.Ctx(ctx).Debug().Int32("object_id", pkt.ObjectID).Int64("count", pkt.Count).Msg("item not tradeable")
+		return nil
+	}
+	h.gameLoopCmd <- gameloop.CmdTradeAddItem{CharID: player.CharID, Item: *item, Count: pkt.Count}
+	return nil
+}
+
+// handleTradeDone forwards the OK / Cancel button to the game loop.
+func (h *Handler) handleTradeDone(ctx context.Context, c *client.ClientConn, payload []byte) error {
+	pkt, err := inclient.ParseTradeResponse(payload)
+	if err != nil {
+		log.Ctx(ctx).Debug().Err(err).Msg("failed to parse TradeDone")
+		return nil
+	}
+	player, ok := h.activePlayer(c)
+	if !ok {
+		return nil
+	}
+	h.gameLoopCmd <- gameloop.CmdTradeDone{CharID: player.CharID, Confirm: pkt.Response == 1}
+	return nil
+}
+
+// HandleTradeJob runs the database side of a trade for the game loop's trade
+// worker: it sends the TradeStart item lists when a window opens and performs the
+// transactional swap once both partners confirmed. A commit reports to whoever is
+// still online; a partner that left meanwhile finds the result in its inventory.
+func (h *Handler) HandleTradeJob(ctx context.Context, job gameloop.TradeJob) {
+	switch job.Kind {
+	case gameloop.TradeJobOpen:
+		h.sendTradeStart(ctx, job.CharA, job.CharB)
+		h.sendTradeStart(ctx, job.CharB, job.CharA)
+	case gameloop.TradeJobCommit:
+		changedA, changedB, err := h.inventoryUseCase.ExecuteTrade(ctx, job.CharA, job.OfferA, job.SlotsA, job.CharB, job.OfferB, job.SlotsB)
+		if err != nil {
+			log.Ctx(ctx).Warn().Err(err).Int32("char_a", job.CharA).Int32("char_b", job.CharB).Msg("trade commit failed")
+			for _, id := range []int32{job.CharA, job.CharB} {
+				if errors.Is(err, usecase.ErrInventoryFull) {
+					h.sendToCharacter(id, outclient.BuildSystemMessageNoParams(outclient.SysMsgSlotsFull))
+				}
+				h.sendToCharacter(id, outclient.BuildTradeDone(0))
+			}
+			return
+		}
+		for _, id := range []int32{job.CharA, job.CharB} {
+			h.sendToCharacter(id, outclient.BuildTradeDone(1))
+			h.sendToCharacter(id, outclient.BuildSystemMessageNoParams(outclient.SysMsgTradeSuccessful))
+		}
+		h.SendInventoryUpdate(job.CharA, changedA)
+		h.SendInventoryUpdate(job.CharB, changedB)
+	}
+}
+
+// sendTradeStart opens the trade window for charID with its tradeable items.
+func (h *Handler) sendTradeStart(ctx context.Context, charID, partnerID int32) {
+	items, err := h.inventoryUseCase.TradeableInventory(ctx, charID)
+	if err != nil {
+		log.Ctx(ctx).Error().Err(err).Int32("char_id", charID).Msg("failed to load tradeable items")
+	}
+	h.sendToCharacter(charID, outclient.BuildTradeStart(partnerID, convertCharacterItemsToInventoryItems(items)))
+}
+
+// sendToCharacter sends a packet to an online character; a no-op otherwise.
+func (h *Handler) sendToCharacter(charID int32, data []byte) {
+	player, ok := h.world.GetPlayer(charID)
+	if !ok {
+		return
+	}
+	if conn := h.connections.GetConnection(player.AccountName); conn != nil {
+		_ = conn.Send(data)
+	}
 }
@@ -1,5 +1,15 @@
 package client
 
+import (
+	"context"
+
+	"github.com/rs/zerolog/log"
+
+	"github.com/VerTox/l2go/internal/gameserver/gameloop"
+	"github.com/VerTox/l2go/internal/gameserver/packets/inclient"
+	"github.com/VerTox/l2go/internal/gameserver/transport/client"
+)
+
 func init() { addStubRegistrator(registerTutorialStubs) }
 
 // registerTutorialStubs регистрирует стаб-обработчики пакетов туториала
@@ -10,8 +20,23 @@ func registerTutorialStubs(r *Registry) {
 	r.registerStub(StateInGame, 0x85, "RequestTutorialLinkHtml")
 	// RequestTutorialPassCmdToServer (0x86): команда туториала серверу.
 	r.registerStub(StateInGame, 0x86, "RequestTutorialPassCmdToServer")
-	// RequestTutorialQuestionMark (0x87): вопросительный знак туториала.
-	r.registerStub(StateInGame, 0x87, "RequestTutorialQuestionMark")
+	// RequestTutorialQuestionMark (0x87): клик по вопросительному знаку
+	// (TutorialShowQuestionMark) — событие для квестов игрока.
+	r.register(StateInGame, 0x87, "RequestTutorialQuestionMark", (*Handler).handleRequestTutorialQuestionMark)
 	// RequestTutorialClientEvent (0x88): клиентское событие туториала.
 	r.registerStub(StateInGame, 0x88, "RequestTutorialClientEvent")
 }
+
+func (h *Handler) handleRequestTutorialQuestionMark(ctx context.Context, c *client.ClientConn, payload []byte) error {
+	pkt, err := inclient.ParseRequestTutorialQuestionMark(payload)
+	if err != nil {
+		log.Ctx(ctx).Debug().Err(err).Msg("failed to parse RequestTutorialQuestionMark")
+		return nil
+	}
+	player, ok := h.activePlayer(c)
+	if !ok {
+		return nil
+	}
+	h.gameLoopCmd <- gameloop.CmdQuestionMark{CharID: player.CharID, Mark: pkt.Mark}
+	return nil
+}
@@ -1,11 +1,125 @@
 package client
 
-func init() { addStubRegistrator(registerWarehouseStubs) }
+import (
+	"context"
+	"errors"
 
-// registerWarehouseStubs регистрирует стаб-обработчики пакетов склада (High Five).
-func registerWarehouseStubs(r *Registry) {
+	"github.com/rs/zerolog/log"
+
+	"github.com/VerTox/l2go/internal/gameserver/gameloop"
+	"github.com/VerTox/l2go/internal/gameserver/models"
+	"github.com/VerTox/l2go/internal/gameserver/packets/inclient"
+	"github.com/VerTox/l2go/internal/gameserver/packets/outclient"
+	"github.com/VerTox/l2go/internal/gameserver/transport/client"
+	"github.com/VerTox/l2go/internal/gameserver/usecase"
+)
+
+func init() { addStubRegistrator(registerWarehouseHandlers) }
+
+// registerWarehouseHandlers регистрирует обработчики склада (High Five). Окно
+// открывает bypass у хранителя склада (gameloop/warehouse.go); списки и
+// перемещение предметов, которым нужна БД, выполняет HandleWarehouseJob.
+func registerWarehouseHandlers(r *Registry) {
 	// SendWareHouseDepositList (0x3b): положить предметы на склад.
-	r.registerStub(StateInGame, 0x3b, "SendWareHouseDepositList")
+	r.register(StateInGame, 0x3b, "SendWareHouseDepositList", (*Handler).handleSendWareHouseDepositList)
 	// SendWareHouseWithDrawList (0x3c): взять предметы со склада.
-	r.registerStub(StateInGame, 0x3c, "SendWareHouseWithDrawList")
+	r.register(StateInGame, 0x3c, "SendWareHouseWithDrawList", (*Handler).handleSendWareHouseWithDrawList)
+}
+
+// handleSendWareHouseDepositList forwards a confirmed deposit window to the
+// game loop, which checks the player is still at the keeper.
+func (h *Handler) handleSendWareHouseDepositList(ctx context.Context, c *client.ClientConn, payload []byte) error {
+	return h.forwardWarehouseList(ctx, c, payload, true)
+}
+
+// handleSendWareHouseWithDrawList forwards a confirmed withdrawal window.
+func (h *Handler) handleSendWareHouseWithDrawList(ctx context.Context, c *client.ClientConn, payload []byte) error {
+	return h.forwardWarehouseList(ctx, c, payload, false)
+}
+
+func (h *Handler) forwardWarehouseList(ctx context.Context, c *client.ClientConn, payload []byte, deposit bool) error {
+	pkt, err := inclient.ParseWarehouseList(payload)
+	if err != nil {
+		log.Ctx(ctx).Debug().Err(err).Bool("deposit", deposit).Msg("failed to parse warehouse list")
+		return nil
+	}
+	player, ok := h.activePlayer(c)
+	if !ok {
+		return nil
+	}
+	items := make([]usecase.ItemTransfer, 0, len(pkt.Items))
+	for _, line := range pkt.Items {
+		if line.Count <= 0 {
+			return c.Send(outclient.BuildActionFailed())
+		}
+		items = append(items, usecase.ItemTransfer{ObjectID: line.ObjectID, Count: line.Count})
+	}
+	h.gameLoopCmd <- gameloop.CmdWarehouseTransfer{CharID: player.CharID, Deposit: deposit, Items: items}
+	return nil
+}
+
+// HandleWarehouseJob runs warehouse work the game loop handed off: it sends
+// the deposit/withdrawal windows and moves items, reporting the outcome to the
+// player.
+func (h *Handler) HandleWarehouseJob(ctx context.Context, job gameloop.WarehouseJob) {
+	switch job.Kind {
+	case gameloop.WarehouseJobDepositList:
+		items, adena, err := h.inventoryUseCase.DepositableInventory(ctx, job.CharID, job.Warehouse)
+		if err != nil {
+			log.Ctx(ctx).Error().Err(err).Int32("char_id", job.CharID).Msg("failed to load depositable inventory")
+			return
+		}
+		h.sendToCharacter(job.CharID, outclient.BuildWareHouseDepositList(warehouseType(job.Warehouse), adena,
+			convertCharacterItemsToInventoryItems(items)))
+	case gameloop.WarehouseJobWithdrawList:
+		items, err := h.inventoryUseCase.WarehouseItems(ctx, job.Warehouse)
+		if err != nil {
+			log.Ctx(ctx).Error().Err(err).Int32("char_id", job.CharID).Msg("failed to load warehouse")
+			return
+		}
+		if len(items) == 0 {
+			h.sendToCharacter(job.CharID, outclient.BuildSystemMessageNoParams(outclient.SysMsgNoItemDepositedInWh))
+			return
+		}
+		adena, err := h.inventoryUseCase.AdenaCount(ctx, job.CharID)
+		if err != nil {
+			log.Ctx(ctx).Error().Err(err).Int32("char_id", job.CharID).Msg("failed to load adena")
+			return
+		}
+		h.sendToCharacter(job.CharID, outclient.BuildWareHouseWithdrawalList(warehouseType(job.Warehouse), adena,
+			convertCharacterItemsToInventoryItems(items)))
+	case gameloop.WarehouseJobDeposit:
+		changed, err := h.inventoryUseCase.Deposit(ctx, job.CharID, job.Warehouse, job.Items)
+		h.finishWarehouseTransfer(ctx, job, changed, err)
+	case gameloop.WarehouseJobWithdraw:
+		changed, err := h.inventoryUseCase.Withdraw(ctx, job.CharID, job.Warehouse, job.Items, job.InventorySlots)
+		h.finishWarehouseTransfer(ctx, job, changed, err)
+	}
+}
+
+// finishWarehouseTransfer refreshes the inventory after a committed deposit or
+// withdrawal, or tells the player why nothing moved.
+func (h *Handler) finishWarehouseTransfer(ctx context.Context, job gameloop.WarehouseJob, changed []usecase.ChangedItem, err error) {
+	if err == nil {
+		h.SendInventoryUpdate(job.CharID, changed)
+		return
+	}
+	log.Ctx(ctx).Warn().Err(err).Int32("char_id", job.CharID).Str("loc", string(job.Warehouse.Loc)).Msg("warehouse transfer failed")
+	switch {
+	case errors.Is(err, usecase.ErrWarehouseFull):
+		h.sendToCharacter(job.CharID, outclient.BuildSystemMessageNoParams(outclient.SysMsgExceededQuantityForInput))
+	case errors.Is(err, usecase.ErrInventoryFull):
+		h.sendToCharacter(job.CharID, outclient.BuildSystemMessageNoParams(outclient.SysMsgSlotsFull))
+	case errors.Is(err, usecase.ErrNotEnoughAdena):
+		h.sendToCharacter(job.CharID, outclient.BuildSystemMessageNoParams(outclient.SysMsgNotEnoughAdena))
+	}
+	h.sendToCharacter(job.CharID, outclient.BuildActionFailed())
+}
+
+// warehouseType is the window type a warehouse list packet carries.
+func warehouseType(wh usecase.Warehouse) int16 {
+	if wh.Loc == models.LocClanWH {
+		return outclient.WarehouseTypeClan
+	}
+	return outclient.WarehouseTypePrivate
 }
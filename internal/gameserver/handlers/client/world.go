@@ -62,6 +62,9 @@ func (h *Handler) handleEnterWorld(ctx context.Context, c *client.ClientConn, pa
 	// reading this player's state after CmdPlayerEnteredWorld (dispatched below).
 	h.loadPlayerSkills(ctx, playerState)
 
+	// Quest state goes in the same way, before the loop owns the player.
+	h.loadPlayerQuests(ctx, playerState)
+
 	// Send world entry packet sequence
 	if err := h.sendWorldEntryPackets(ctx, c, playerState.Character); err != nil {
 		log.Ctx(ctx).Error().Err(err).Msg("failed to send world entry packets")
@@ -69,6 +72,11 @@ func (h *Handler) handleEnterWorld(ctx context.Context, c *client.ClientConn, pa
 		return err
 	}
 
+	// The quest journal (L2J sends QuestList from EnterWorld).
+	if err := c.Send(gameloop.QuestListPacket(playerState)); err != nil {
+		log.Ctx(ctx).Error().Err(err).Msg("failed to send QuestList")
+	}
+
 	// Send nearby NPCs to us. Player-to-player visibility is established by the game
 	// loop when it processes CmdPlayerEnteredWorld below.
 	h.establishNpcVisibility(ctx, c, playerState)
@@ -785,13 +793,20 @@ func (h *Handler) establishNpcVisibility(ctx context.Context, c *client.ClientCo
 func (h *Handler) sendPlayerSpawnToClient(ctx context.Context, c *client.ClientConn, char *models.Character) error {
 	// Live running/combat/heading from the world registry (fall back to persisted).
 	isRunning, inCombat, heading := true, false, int32(char.Heading)
+	var storeType, sitting int32
 	if playerState, exists := h.world.GetPlayer(char.ID); exists {
 		isRunning = playerState.IsRunning
 		inCombat = playerState.InCombat
 		heading = playerState.Heading
+		storeType = int32(playerState.PrivateStore.Type)
+		if playerState.Sitting {
+			sitting = 1
+		}
 	}
 
 	charInfo := outclient.NewCharInfo(char, &char.Position, char.PaperdollItems, isRunning, inCombat, heading)
+	charInfo.PrivateStoreType = storeType
+	charInfo.Sitting = sitting
 	if err := c.Send(charInfo.GetData()); err != nil {
 		return fmt.Errorf("failed to send CharInfo packet: %w", err)
 	}
@@ -37,6 +37,10 @@ type BuffInfo struct {
 	Mods  []StatModifier
 	Ticks []BuffTick
 
+	// SilentMove hides the character from aggressive monsters (L2J
+	// SilentMove effect).
+	SilentMove bool
+
 	// Runtime schedule (game-loop owned).
 	ExpiresAt time.Time // zero = infinite
 	NextTick  time.Time // next HoT/DoT tick (zero if no ticks)
@@ -130,6 +134,17 @@ func (l *CharEffectList) Mods() []StatModifier {
 	return mods
 }
 
+// IsSilentMoving reports whether an active buff hides the character from
+// aggressive monsters.
+func (l *CharEffectList) IsSilentMoving() bool {
+	for _, b := range l.buffs {
+		if b.SilentMove {
+			return true
+		}
+	}
+	return false
+}
+
 func (l *CharEffectList) indexOfSkill(skillID int32) int {
 	for i, b := range l.buffs {
 		if b.SkillID == skillID {
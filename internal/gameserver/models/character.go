@@ -373,6 +373,36 @@ type CharacterRecipe struct {
 	RegisteredAt time.Time `json:"registered_at" db:"registered_at"`
 }
 
+// OfflineTrade is a private store kept open by a character that left the game
+// in offline-trade mode. Mirrors L2J's character_offline_trade row; StoreType
+// holds a registry.PrivateStoreType value.
+type OfflineTrade struct {
+	CharID    int32              `json:"char_id" db:"char_id"`
+	StoreType int32              `json:"store_type" db:"store_type"`
+	Title     string             `json:"title" db:"title"`
+	StartedAt time.Time          `json:"started_at" db:"started_at"`
+	Items     []OfflineTradeItem `json:"items"`
+}
+
+// OfflineTradeItem is one line of an offline store. Item is the inventory
+// object id for sell stores and the item template id for buy stores.
+type OfflineTradeItem struct {
+	Item    int32 `json:"item" db:"item"`
+	Enchant int32 `json:"enchant" db:"enchant"`
+	Count   int64 `json:"count" db:"count"`
+	Price   int64 `json:"price" db:"price"`
+}
+
+// CharacterQuestVar is one variable of a character's quest (L2J
+// character_quests row). The quest engine keeps "state" and "cond"; the other
+// variables belong to the quest script.
+type CharacterQuestVar struct {
+	CharID    int32  `json:"char_id" db:"char_id"`
+	QuestName string `json:"quest_name" db:"quest_name"`
+	Var       string `json:"var" db:"var"`
+	Value     string `json:"value" db:"value"`
+}
+
 // CharacterShortcut represents a UI shortcut/macro
 type CharacterShortcut struct {
 	CharID     int32 `json:"char_id" db:"char_id"`
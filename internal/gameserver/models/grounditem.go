@@ -0,0 +1,46 @@
+package models
+
+import (
+	"strconv"
+	"time"
+)
+
+// GroundItem is an item lying on the ground (L2J L2ItemInstance in
+// ItemLocation.VOID): a monster's drop, or an item a player dropped.
+type GroundItem struct {
+	ObjectID int32 // runtime world object id, unique among world objects
+	ItemID   int32
+	Count    int64
+	Enchant  int
+	Position Position
+
+	// StoredID is the item's character_items row, kept at LocGround while
+	// the item lies here; zero for drops that were never anyone's (monster
+	// drops), which only get a row when picked up.
+	StoredID int32
+
+	// DropperID is the object that dropped the item (a monster or a player).
+	DropperID int32
+
+	// OwnerID may pick the item up alone, with its party, until
+	// ProtectedUntil (L2J DropProtection); zero leaves it free for all.
+	OwnerID        int32
+	ProtectedUntil time.Time
+
+	// PickingUp is the character the item is being handed to while the
+	// database moves it; nobody else may take it meanwhile.
+	PickingUp int32
+}
+
+// IsProtectedFrom reports whether charID, outside the owner's party, may
+// not pick the item up yet.
+func (g *GroundItem) IsProtectedFrom(charID int32, now time.Time) bool {
+	return g.OwnerID != 0 && g.OwnerID != charID && now.Before(g.ProtectedUntil)
+}
+
+// WorldObject interface implementation for GroundItem
+
+func (g *GroundItem) GetObjectID() int32        { return g.ObjectID }
+func (g *GroundItem) GetPosition() Position     { return g.Position }
+func (g *GroundItem) GetName() string           { return "item " + strconv.Itoa(int(g.ItemID)) }
+func (g *GroundItem) GetObjectType() ObjectType { return ObjectTypeItem }
@@ -8,7 +8,7 @@ import (
 type CharacterItem struct {
 	// Primary identification
 	ObjectID int32  `json:"object_id" db:"object_id"`
-	OwnerID  int32  `json:"owner_id" db:"owner_id"`
+	OwnerID  int32  `json:"owner_id" db:"owner_id"` // the clan for LocClanWH
 	ItemID   int32  `json:"item_id" db:"item_id"`
 	Name     string `json:"name" db:"name"`
 	Icon     string `json:"icon" db:"icon"`
@@ -61,8 +61,14 @@ const (
 	LocPet       ItemLocation = "PET"
 	LocPetEquip  ItemLocation = "PET_EQUIP"
 	LocFreight   ItemLocation = "FREIGHT"
+	// LocGround holds items lying on the ground (L2J VOID); the row keeps the
+	// owner that dropped it.
+	LocGround ItemLocation = "VOID"
 )
 
+// ItemIDAdena is the item id of adena, the currency player stores settle in.
+const ItemIDAdena int32 = 57
+
 // PaperdollSlot represents equipment slots (matches Java L2J Inventory.PAPERDOLL_* constants)
 type PaperdollSlot int
 
@@ -1,5 +1,7 @@
 package models
 
+import "slices"
+
 // NpcTemplate holds the static data for an NPC type loaded from XML.
 type NpcTemplate struct {
 	ID        int32
@@ -54,6 +56,89 @@ type NpcTemplate struct {
 	ShowName   bool
 	CanMove    bool
 	AggroRange int
+	// Aggressive monsters attack players within AggroRange unprovoked
+	// (datapack <ai isAggressive>, true unless it says "false").
+	Aggressive bool
+	// AIType is the datapack <ai type>: "BALANCED", "MAGE", "HEALER",
+	// "ARCHER", ... Casters lean on their skills in combat.
+	AIType string
+
+	// Clans are the factions the NPC belongs to (datapack <clanList>): when
+	// it is attacked, allies of a shared clan within ClanHelpRange join the
+	// fight, except those in IgnoreClanNpcIDs.
+	Clans            []string
+	IgnoreClanNpcIDs []int32
+	ClanHelpRange    int
+
+	// Skills are the datapack <skillList>: passive stat skills and the
+	// active ones the combat AI casts.
+	Skills []NpcSkill
+
+	// Minions are the datapack <parameters><minions>: the NPCs that spawn
+	// around this one as its escort.
+	Minions []NpcMinion
+
+	// Drops (from datapack <dropLists>). DeathItems and DeathGroups are rolled
+	// when the NPC is killed: each ungrouped item on its own, each group as a
+	// whole. SpoilItems is the <corpse> list a spoiled corpse yields to a sweep.
+	DeathItems  []DropItem
+	DeathGroups []DropGroup
+	SpoilItems  []DropItem
+}
+
+// ClanAll is the clan that answers to, and is answered by, every clan.
+const ClanAll = "ALL"
+
+// IsClan reports whether the NPC answers a call for help from an NPC of the
+// given clans (L2J L2NpcTemplate.isClan): it shares one of them, or the
+// caller belongs to ClanAll.
+func (t *NpcTemplate) IsClan(clans []string) bool {
+	if len(t.Clans) == 0 {
+		return false
+	}
+	for _, c := range clans {
+		if c == ClanAll || slices.Contains(t.Clans, c) {
+			return true
+		}
+	}
+	return false
+}
+
+// IsRaid reports whether the NPC is a raid or grand boss.
+func (t *NpcTemplate) IsRaid() bool {
+	return t.Type == "L2RaidBoss" || t.Type == "L2GrandBoss"
+}
+
+// NpcMinion is one <minions> entry of an NPC: Count NPCs of NpcID escorting
+// it (L2J MinionHolder). RespawnTime is the datapack's minion respawn delay
+// in seconds.
+type NpcMinion struct {
+	NpcID       int32
+	Count       int
+	RespawnTime int
+}
+
+// NpcSkill is one <skillList> entry of an NPC.
+type NpcSkill struct {
+	ID    int32
+	Level int
+}
+
+// DropItem is one entry of an NPC drop list (L2J GeneralDropItem): Chance
+// percent to drop between Min and Max of ItemID. Inside a DropGroup, Chance
+// is instead the item's share of the group's drops.
+type DropItem struct {
+	ItemID int32
+	Min    int64
+	Max    int64
+	Chance float64
+}
+
+// DropGroup is a grouped death drop (L2J GroupedGeneralDropItem): Chance
+// percent that the group drops, then one of its Items picked by their shares.
+type DropGroup struct {
+	Chance float64
+	Items  []DropItem
 }
 
 // NpcInstance represents a live NPC spawned in the game world.
@@ -68,6 +153,11 @@ type NpcInstance struct {
 	CurrentHP  float64
 	CurrentMP  float64
 	SpawnID    int32 // which spawn point created this NPC
+	Spawn      *SpawnData // the spawn entry that placed it; nil for NPCs spawned ad hoc
+
+	// Effects are the buffs and debuffs on the NPC; their stat modifiers
+	// apply on top of the template stats.
+	Effects CharEffectList
 }
 
 // IsAttackable returns true if this NPC should be attacked on interaction
@@ -95,9 +185,13 @@ func (n *NpcInstance) GetObjectType() ObjectType { return ObjectTypeNPC }
 
 // SpawnData represents a spawn point from XML data.
 type SpawnData struct {
-	NpcID        int32
-	X, Y, Z      int
-	Heading      int
-	RespawnDelay int // seconds (for future use)
-	Count        int
+	NpcID         int32
+	X, Y, Z       int
+	Heading       int
+	RespawnDelay  int // seconds
+	RespawnRandom int // seconds the delay may run short or long by
+	Count         int
+	// Territory names the spawn zone of a group spawn (XML <spawn zone>): its
+	// Count NPCs appear at random points inside it. Empty for a fixed point.
+	Territory string
 }
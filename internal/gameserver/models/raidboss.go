@@ -0,0 +1,23 @@
+package models
+
+import "time"
+
+// RaidBossStatus is the persisted state of a raid boss (L2J raidboss_spawnlist
+// row): when a dead boss respawns, or the HP and MP a living one had.
+type RaidBossStatus struct {
+	BossID    int32     `json:"boss_id" db:"boss_id"`           // NPC template id
+	RespawnAt time.Time `json:"respawn_time" db:"respawn_time"` // zero while alive
+	CurrentHP float64   `json:"current_hp" db:"current_hp"`
+	CurrentMP float64   `json:"current_mp" db:"current_mp"`
+}
+
+// Alive reports whether the boss was alive when its status was saved.
+func (s RaidBossStatus) Alive() bool { return s.RespawnAt.IsZero() }
+
+// CharacterRaidPoints is the raid points a character earned on one boss (L2J
+// character_raid_points row).
+type CharacterRaidPoints struct {
+	CharID int32 `json:"char_id" db:"char_id"`
+	BossID int32 `json:"boss_id" db:"boss_id"`
+	Points int32 `json:"points" db:"points"`
+}
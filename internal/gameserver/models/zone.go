@@ -0,0 +1,142 @@
+package models
+
+// ZoneType is the kind of a zone: the L2J zone class a datapack <zone type>
+// names.
+type ZoneType string
+
+const (
+	ZonePeace          ZoneType = "PeaceZone"
+	ZoneTown           ZoneType = "TownZone"
+	ZoneArena          ZoneType = "ArenaZone"
+	ZoneSiege          ZoneType = "SiegeZone"
+	ZoneWater          ZoneType = "WaterZone"
+	ZoneNoLanding      ZoneType = "NoLandingZone"
+	ZoneDamage         ZoneType = "DamageZone"
+	ZoneSpawnTerritory ZoneType = "NpcSpawnTerritory"
+)
+
+// ZoneShape is the volume a zone covers (L2J L2ZoneForm).
+type ZoneShape interface {
+	// Contains reports whether the point lies inside the shape.
+	Contains(x, y, z int) bool
+	// Bounds returns the shape's bounding box in the XY plane.
+	Bounds() (minX, minY, maxX, maxY int)
+	// RandomPoint returns a random point inside the shape, halfway up it; rnd(n)
+	// returns [0, n).
+	RandomPoint(rnd func(n int) int) (x, y, z int)
+}
+
+// ZoneCuboid is an axis-aligned box (L2J ZoneCuboid).
+type ZoneCuboid struct {
+	MinX, MinY, MaxX, MaxY int
+	MinZ, MaxZ             int
+}
+
+func (c ZoneCuboid) Contains(x, y, z int) bool {
+	return x >= c.MinX && x <= c.MaxX && y >= c.MinY && y <= c.MaxY && z >= c.MinZ && z <= c.MaxZ
+}
+
+func (c ZoneCuboid) Bounds() (int, int, int, int) { return c.MinX, c.MinY, c.MaxX, c.MaxY }
+
+func (c ZoneCuboid) RandomPoint(rnd func(int) int) (int, int, int) {
+	return c.MinX + rnd(c.MaxX-c.MinX+1), c.MinY + rnd(c.MaxY-c.MinY+1), (c.MinZ + c.MaxZ) / 2
+}
+
+// ZoneCylinder is an upright cylinder around X, Y (L2J ZoneCylinder).
+type ZoneCylinder struct {
+	X, Y, Radius int
+	MinZ, MaxZ   int
+}
+
+func (c ZoneCylinder) Contains(x, y, z int) bool {
+	if z < c.MinZ || z > c.MaxZ {
+		return false
+	}
+	dx, dy := x-c.X, y-c.Y
+	return dx*dx+dy*dy <= c.Radius*c.Radius
+}
+
+func (c ZoneCylinder) Bounds() (int, int, int, int) {
+	return c.X - c.Radius, c.Y - c.Radius, c.X + c.Radius, c.Y + c.Radius
+}
+
+func (c ZoneCylinder) RandomPoint(rnd func(int) int) (int, int, int) {
+	return randomPointIn(c, rnd, c.X, c.Y, (c.MinZ+c.MaxZ)/2)
+}
+
+// ZoneNPoly is an upright prism over a polygon of any number of corners
+// (L2J ZoneNPoly).
+type ZoneNPoly struct {
+	Xs, Ys     []int
+	MinZ, MaxZ int
+}
+
+// Contains tests the point against the polygon by ray casting.
+func (p ZoneNPoly) Contains(x, y, z int) bool {
+	if z < p.MinZ || z > p.MaxZ {
+		return false
+	}
+	inside := false
+	for i, j := 0, len(p.Xs)-1; i < len(p.Xs); j, i = i, i+1 {
+		xi, yi, xj, yj := p.Xs[i], p.Ys[i], p.Xs[j], p.Ys[j]
+		if (yi > y) != (yj > y) && float64(x) < float64(xj-xi)*float64(y-yi)/float64(yj-yi)+float64(xi) {
+			inside = !inside
+		}
+	}
+	return inside
+}
+
+func (p ZoneNPoly) Bounds() (minX, minY, maxX, maxY int) {
+	if len(p.Xs) == 0 {
+		return 0, 0, 0, 0
+	}
+	minX, minY, maxX, maxY = p.Xs[0], p.Ys[0], p.Xs[0], p.Ys[0]
+	for i := range p.Xs {
+		minX, maxX = min(minX, p.Xs[i]), max(maxX, p.Xs[i])
+		minY, maxY = min(minY, p.Ys[i]), max(maxY, p.Ys[i])
+	}
+	return minX, minY, maxX, maxY
+}
+
+func (p ZoneNPoly) RandomPoint(rnd func(int) int) (int, int, int) {
+	if len(p.Xs) == 0 {
+		return 0, 0, 0
+	}
+	return randomPointIn(p, rnd, p.Xs[0], p.Ys[0], (p.MinZ+p.MaxZ)/2)
+}
+
+// randomPointIn picks random points of a shape's bounding box until one lies
+// inside it (L2J ZoneForm.getRandomPoint), falling back to (fx, fy) if none
+// does in 100 tries.
+func randomPointIn(s ZoneShape, rnd func(int) int, fx, fy, z int) (int, int, int) {
+	minX, minY, maxX, maxY := s.Bounds()
+	for range 100 {
+		x, y := minX+rnd(maxX-minX+1), minY+rnd(maxY-minY+1)
+		if s.Contains(x, y, z) {
+			return x, y, z
+		}
+	}
+	return fx, fy, z
+}
+
+// Zone is an area of the world with rules of its own (L2J L2ZoneType):
+// towns, arenas, water, spawn territories and the like.
+type Zone struct {
+	ID    int32
+	Name  string
+	Type  ZoneType
+	Shape ZoneShape
+	// Params are the zone's datapack <stat name val> settings.
+	Params map[string]string
+}
+
+// Contains reports whether pos lies inside the zone.
+func (z *Zone) Contains(pos Position) bool {
+	return z.Shape.Contains(pos.X, pos.Y, pos.Z)
+}
+
+// RandomPoint returns a random position inside the zone; rnd(n) returns [0, n).
+func (z *Zone) RandomPoint(rnd func(n int) int) Position {
+	x, y, h := z.Shape.RandomPoint(rnd)
+	return Position{X: x, Y: y, Z: h}
+}
@@ -0,0 +1,65 @@
+package inclient
+
+import (
+	"fmt"
+
+	"github.com/VerTox/l2go/pkg/l2pkt"
+)
+
+// RequestDropItem drops an inventory item on the ground (opcode 0x17).
+// Format: D objectId, Q count, D x, D y, D z — where the player let go of it.
+type RequestDropItem struct {
+	ObjectID int32
+	Count    int64
+	X, Y, Z  int32
+}
+
+// ParseRequestDropItem parses RequestDropItem.
+func ParseRequestDropItem(data []byte) (*RequestDropItem, error) {
+	r := l2pkt.NewReader(data)
+	objectID, count, err := readItemCount(r)
+	if err != nil {
+		return nil, err
+	}
+	p := &RequestDropItem{ObjectID: objectID, Count: count}
+	if p.X, err = r.ReadD(); err != nil {
+		return nil, fmt.Errorf("read x: %w", err)
+	}
+	if p.Y, err = r.ReadD(); err != nil {
+		return nil, fmt.Errorf("read y: %w", err)
+	}
+	if p.Z, err = r.ReadD(); err != nil {
+		return nil, fmt.Errorf("read z: %w", err)
+	}
+	return p, nil
+}
+
+// ItemCountRequest is an inventory item and how many of it to act on:
+// RequestDestroyItem (0x60) and RequestCrystallizeItem (0x2f) share the
+// layout D objectId, Q count.
+type ItemCountRequest struct {
+	ObjectID int32
+	Count    int64
+}
+
+// ParseItemCountRequest parses RequestDestroyItem or RequestCrystallizeItem.
+func ParseItemCountRequest(data []byte) (*ItemCountRequest, error) {
+	r := l2pkt.NewReader(data)
+	objectID, count, err := readItemCount(r)
+	if err != nil {
+		return nil, err
+	}
+	return &ItemCountRequest{ObjectID: objectID, Count: count}, nil
+}
+
+func readItemCount(r *l2pkt.Reader) (int32, int64, error) {
+	objectID, err := r.ReadD()
+	if err != nil {
+		return 0, 0, fmt.Errorf("read objectId: %w", err)
+	}
+	count, err := r.ReadQ()
+	if err != nil {
+		return 0, 0, fmt.Errorf("read count: %w", err)
+	}
+	return objectID, count, nil
+}
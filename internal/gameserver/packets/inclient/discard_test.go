@@ -0,0 +1,41 @@
+package inclient
+
+import (
+	"testing"
+
+	"github.com/VerTox/l2go/pkg/l2pkt"
+)
+
+func TestParseRequestDropItem(t *testing.T) {
+	w := l2pkt.NewWriter()
+	w.WriteD(268435501)
+	w.WriteQ(25)
+	w.WriteD(-71000)
+	w.WriteD(258000)
+	w.WriteD(-3100)
+	p, err := ParseRequestDropItem(w.Bytes())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if *p != (RequestDropItem{ObjectID: 268435501, Count: 25, X: -71000, Y: 258000, Z: -3100}) {
+		t.Fatalf("bad parse: %+v", p)
+	}
+
+	// A drop without its location is rejected.
+	if _, err := ParseRequestDropItem(w.Bytes()[:12]); err == nil {
+		t.Fatal("truncated packet should fail")
+	}
+}
+
+func TestParseItemCountRequest(t *testing.T) {
+	w := l2pkt.NewWriter()
+	w.WriteD(268435502)
+	w.WriteQ(1)
+	p, err := ParseItemCountRequest(w.Bytes())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if *p != (ItemCountRequest{ObjectID: 268435502, Count: 1}) {
+		t.Fatalf("bad parse: %+v", p)
+	}
+}
@@ -0,0 +1,35 @@
+package inclient
+
+import (
+	"fmt"
+
+	"github.com/VerTox/l2go/pkg/l2pkt"
+)
+
+// MultiSellChoose is an exchange picked in a multisell window (opcode 0xb0).
+// Format: D listId, D entryId, Q amount, then the chosen item's enchant,
+// augmentation and attributes, which only matter to inventory-bound lists
+// and are not read.
+type MultiSellChoose struct {
+	ListID  int32
+	EntryID int32
+	Amount  int64
+}
+
+// ParseMultiSellChoose parses MultiSellChoose.
+func ParseMultiSellChoose(data []byte) (*MultiSellChoose, error) {
+	r := l2pkt.NewReader(data)
+	listID, err := r.ReadD()
+	if err != nil {
+		return nil, fmt.Errorf("read listId: %w", err)
+	}
+	entryID, err := r.ReadD()
+	if err != nil {
+		return nil, fmt.Errorf("read entryId: %w", err)
+	}
+	amount, err := r.ReadQ()
+	if err != nil {
+		return nil, fmt.Errorf("read amount: %w", err)
+	}
+	return &MultiSellChoose{ListID: listID, EntryID: entryID, Amount: amount}, nil
+}
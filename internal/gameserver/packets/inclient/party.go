@@ -0,0 +1,95 @@
+package inclient
+
+import (
+	"fmt"
+
+	"github.com/VerTox/l2go/pkg/l2pkt"
+)
+
+// RequestJoinParty is the party invitation (opcode 0x42): the invitee's name and
+// the loot mode the inviter proposes for a new party.
+// Format: S name, D distributionType (L2J HF RequestJoinParty.readImpl).
+type RequestJoinParty struct {
+	Name     string
+	LootType int32
+}
+
+// ParseRequestJoinParty parses a RequestJoinParty packet.
+func ParseRequestJoinParty(data []byte) (*RequestJoinParty, error) {
+	r := l2pkt.NewReader(data)
+	name, err := r.ReadS()
+	if err != nil {
+		return nil, fmt.Errorf("read name: %w", err)
+	}
+	lootType, err := r.ReadD()
+	if err != nil {
+		return nil, fmt.Errorf("read distributionType: %w", err)
+	}
+	return &RequestJoinParty{Name: name, LootType: lootType}, nil
+}
+
+// RequestAnswerJoinParty is the invitee's reply to AskJoinParty (opcode 0x43).
+// Format: D response (1 = accept, 0 = decline, -1 = auto-refuse).
+type RequestAnswerJoinParty struct {
+	Response int32
+}
+
+// ParseRequestAnswerJoinParty parses a RequestAnswerJoinParty packet.
+func ParseRequestAnswerJoinParty(data []byte) (*RequestAnswerJoinParty, error) {
+	r := l2pkt.NewReader(data)
+	response, err := r.ReadD()
+	if err != nil {
+		return nil, fmt.Errorf("read response: %w", err)
+	}
+	return &RequestAnswerJoinParty{Response: response}, nil
+}
+
+// RequestPartyMemberName carries a single member name: RequestOustPartyMember
+// (0x45) and RequestChangePartyLeader (0xD0:0x0c) share this layout (S name).
+type RequestPartyMemberName struct {
+	Name string
+}
+
+// ParseRequestPartyMemberName parses RequestOustPartyMember / RequestChangePartyLeader.
+func ParseRequestPartyMemberName(data []byte) (*RequestPartyMemberName, error) {
+	r := l2pkt.NewReader(data)
+	name, err := r.ReadS()
+	if err != nil {
+		return nil, fmt.Errorf("read name: %w", err)
+	}
+	return &RequestPartyMemberName{Name: name}, nil
+}
+
+// RequestPartyLootModification is the leader's loot-mode change request
+// (multi-packet 0xD0:0x78). Format: D distributionType.
+type RequestPartyLootModification struct {
+	LootType int32
+}
+
+// ParseRequestPartyLootModification parses a RequestPartyLootModification packet
+// (payload after the sub-opcode).
+func ParseRequestPartyLootModification(data []byte) (*RequestPartyLootModification, error) {
+	r := l2pkt.NewReader(data)
+	lootType, err := r.ReadD()
+	if err != nil {
+		return nil, fmt.Errorf("read distributionType: %w", err)
+	}
+	return &RequestPartyLootModification{LootType: lootType}, nil
+}
+
+// AnswerPartyLootModification is a member's vote on the pending loot-mode change
+// (multi-packet 0xD0:0x79). Format: D answer (1 = approve).
+type AnswerPartyLootModification struct {
+	Answer int32
+}
+
+// ParseAnswerPartyLootModification parses an AnswerPartyLootModification packet
+// (payload after the sub-opcode).
+func ParseAnswerPartyLootModification(data []byte) (*AnswerPartyLootModification, error) {
+	r := l2pkt.NewReader(data)
+	answer, err := r.ReadD()
+	if err != nil {
+		return nil, fmt.Errorf("read answer: %w", err)
+	}
+	return &AnswerPartyLootModification{Answer: answer}, nil
+}
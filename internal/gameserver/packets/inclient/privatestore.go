@@ -0,0 +1,200 @@
+package inclient
+
+import (
+	"fmt"
+
+	"github.com/VerTox/l2go/pkg/l2pkt"
+)
+
+// maxStoreLines bounds the item count a private store packet may declare, so a
+// forged header cannot make the parser allocate a huge slice (L2J
+// Config.MAX_ITEM_IN_PACKET).
+const maxStoreLines = 250
+
+// StoreLine is one item line of a private store packet. Sell-side packets name
+// the stack by ObjectID; SetPrivateStoreListBuy names the wanted ItemID and
+// EnchantLevel instead; RequestPrivateStoreSell carries both.
+type StoreLine struct {
+	ObjectID     int32
+	ItemID       int32
+	EnchantLevel int32
+	Count        int64
+	Price        int64
+}
+
+// SetPrivateStoreListSell opens a sell store with the given lines (opcode 0x31).
+// Format: D packageSale, D n, n×(D objectId, Q count, Q price). n = 0 closes the
+// store.
+type SetPrivateStoreListSell struct {
+	PackageSale bool
+	Items       []StoreLine
+}
+
+// ParseSetPrivateStoreListSell parses a SetPrivateStoreListSell packet.
+func ParseSetPrivateStoreListSell(data []byte) (*SetPrivateStoreListSell, error) {
+	r := l2pkt.NewReader(data)
+	pkg, err := r.ReadD()
+	if err != nil {
+		return nil, fmt.Errorf("read packageSale: %w", err)
+	}
+	items, err := readStoreLines(r, 20, readObjectCountPrice)
+	if err != nil {
+		return nil, err
+	}
+	return &SetPrivateStoreListSell{PackageSale: pkg == 1, Items: items}, nil
+}
+
+// RequestPrivateStoreBuy buys from a sell store (opcode 0x83).
+// Format: D storeObjectId, D n, n×(D objectId, Q count, Q price).
+type RequestPrivateStoreBuy struct {
+	StoreObjectID int32
+	Items         []StoreLine
+}
+
+// ParseRequestPrivateStoreBuy parses a RequestPrivateStoreBuy packet.
+func ParseRequestPrivateStoreBuy(data []byte) (*RequestPrivateStoreBuy, error) {
+	r := l2pkt.NewReader(data)
+	storeID, err := r.ReadD()
+	if err != nil {
+		return nil, fmt.Errorf("read storeObjectId: %w", err)
+	}
+	items, err := readStoreLines(r, 20, readObjectCountPrice)
+	if err != nil {
+		return nil, err
+	}
+	return &RequestPrivateStoreBuy{StoreObjectID: storeID, Items: items}, nil
+}
+
+// SetPrivateStoreListBuy opens a buy store (opcode 0x9a). Format: D n,
+// n×(D itemId, H enchant, H unknown, Q count, Q price, 16 bytes of element
+// attributes the server ignores) — L2J HF BATCH_LENGTH 40.
+type SetPrivateStoreListBuy struct {
+	Items []StoreLine
+}
+
+// ParseSetPrivateStoreListBuy parses a SetPrivateStoreListBuy packet.
+func ParseSetPrivateStoreListBuy(data []byte) (*SetPrivateStoreListBuy, error) {
+	r := l2pkt.NewReader(data)
+	items, err := readStoreLines(r, 40, func(r *l2pkt.Reader, l *StoreLine) error {
+		var err error
+		if l.ItemID, err = r.ReadD(); err != nil {
+			return fmt.Errorf("read itemId: %w", err)
+		}
+		enchant, err := r.ReadH()
+		if err != nil {
+			return fmt.Errorf("read enchant: %w", err)
+		}
+		l.EnchantLevel = int32(enchant)
+		if _, err := r.ReadH(); err != nil {
+			return fmt.Errorf("read unknown: %w", err)
+		}
+		if l.Count, err = r.ReadQ(); err != nil {
+			return fmt.Errorf("read count: %w", err)
+		}
+		if l.Price, err = r.ReadQ(); err != nil {
+			return fmt.Errorf("read price: %w", err)
+		}
+		var attrs [16]byte
+		if err := r.ReadB(attrs[:]); err != nil {
+			return fmt.Errorf("read attributes: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &SetPrivateStoreListBuy{Items: items}, nil
+}
+
+// RequestPrivateStoreSell sells into a buy store (opcode 0x9f). Format:
+// D storeObjectId, D n, n×(D objectId, D itemId, H, H, Q count, Q price).
+type RequestPrivateStoreSell struct {
+	StoreObjectID int32
+	Items         []StoreLine
+}
+
+// ParseRequestPrivateStoreSell parses a RequestPrivateStoreSell packet.
+func ParseRequestPrivateStoreSell(data []byte) (*RequestPrivateStoreSell, error) {
+	r := l2pkt.NewReader(data)
+	storeID, err := r.ReadD()
+	if err != nil {
+		return nil, fmt.Errorf("read storeObjectId: %w", err)
+	}
+	items, err := readStoreLines(r, 28, func(r *l2pkt.Reader, l *StoreLine) error {
+		var err error
+		if l.ObjectID, err = r.ReadD(); err != nil {
+			return fmt.Errorf("read objectId: %w", err)
+		}
+		if l.ItemID, err = r.ReadD(); err != nil {
+			return fmt.Errorf("read itemId: %w", err)
+		}
+		if _, err := r.ReadH(); err != nil {
+			return fmt.Errorf("read unknown: %w", err)
+		}
+		if _, err := r.ReadH(); err != nil {
+			return fmt.Errorf("read unknown: %w", err)
+		}
+		if l.Count, err = r.ReadQ(); err != nil {
+			return fmt.Errorf("read count: %w", err)
+		}
+		if l.Price, err = r.ReadQ(); err != nil {
+			return fmt.Errorf("read price: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &RequestPrivateStoreSell{StoreObjectID: storeID, Items: items}, nil
+}
+
+// SetPrivateStoreMsg carries a store title: SetPrivateStoreMsgSell (0x97),
+// SetPrivateStoreMsgBuy (0x9d) and SetPrivateStoreWholeMsg (0xD0:0x4a) share
+// the single S layout.
+type SetPrivateStoreMsg struct {
+	Message string
+}
+
+// ParseSetPrivateStoreMsg parses any of the store title packets.
+func ParseSetPrivateStoreMsg(data []byte) (*SetPrivateStoreMsg, error) {
+	r := l2pkt.NewReader(data)
+	msg, err := r.ReadS()
+	if err != nil {
+		return nil, fmt.Errorf("read message: %w", err)
+	}
+	return &SetPrivateStoreMsg{Message: msg}, nil
+}
+
+// readStoreLines reads the D count header and count fixed-size lines. The
+// header must match the bytes that follow exactly, as in L2J's BATCH_LENGTH
+// check.
+func readStoreLines(r *l2pkt.Reader, batch int, read func(*l2pkt.Reader, *StoreLine) error) ([]StoreLine, error) {
+	n, err := r.ReadD()
+	if err != nil {
+		return nil, fmt.Errorf("read count: %w", err)
+	}
+	if n < 0 || n > maxStoreLines || int(n)*batch != r.Remaining() {
+		return nil, fmt.Errorf("invalid item count %d for %d remaining bytes", n, r.Remaining())
+	}
+	lines := make([]StoreLine, n)
+	for i := range lines {
+		if err := read(r, &lines[i]); err != nil {
+			return nil, fmt.Errorf("item %d: %w", i, err)
+		}
+	}
+	return lines, nil
+}
+
+func readObjectCountPrice(r *l2pkt.Reader, l *StoreLine) error {
+	var err error
+	if l.ObjectID, err = r.ReadD(); err != nil {
+		return fmt.Errorf("read objectId: %w", err)
+	}
+	if l.Count, err = r.ReadQ(); err != nil {
+		return fmt.Errorf("read count: %w", err)
+	}
+	if l.Price, err = r.ReadQ(); err != nil {
+		return fmt.Errorf("read price: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,76 @@
+package inclient
+
+import (
+	"testing"
+
+	"github.com/VerTox/l2go/pkg/l2pkt"
+)
+
+func TestParseSetPrivateStoreListSell(t *testing.T) {
+	w := l2pkt.NewWriter()
+	w.WriteD(1) // package sale
+	w.WriteD(2)
+	w.WriteD(100)
+	w.WriteQ(5)
+	w.WriteQ(1000)
+	w.WriteD(101)
+	w.WriteQ(1)
+	w.WriteQ(0)
+	p, err := ParseSetPrivateStoreListSell(w.Bytes())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !p.PackageSale || len(p.Items) != 2 || p.Items[0] != (StoreLine{ObjectID: 100, Count: 5, Price: 1000}) ||
+		p.Items[1] != (StoreLine{ObjectID: 101, Count: 1}) {
+		t.Fatalf("bad parse: %+v", p)
+	}
+
+	// A count that disagrees with the payload length is rejected.
+	w = l2pkt.NewWriter()
+	w.WriteD(0)
+	w.WriteD(3)
+	w.WriteD(100)
+	if _, err := ParseSetPrivateStoreListSell(w.Bytes()); err == nil {
+		t.Fatal("truncated list should fail")
+	}
+}
+
+func TestParseSetPrivateStoreListBuy(t *testing.T) {
+	w := l2pkt.NewWriter()
+	w.WriteD(1)
+	w.WriteD(2369)
+	w.WriteH(3) // enchant
+	w.WriteH(0)
+	w.WriteQ(2)
+	w.WriteQ(250000)
+	for i := 0; i < 8; i++ {
+		w.WriteH(0) // element attributes
+	}
+	p, err := ParseSetPrivateStoreListBuy(w.Bytes())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(p.Items) != 1 || p.Items[0] != (StoreLine{ItemID: 2369, EnchantLevel: 3, Count: 2, Price: 250000}) {
+		t.Fatalf("bad parse: %+v", p)
+	}
+}
+
+func TestParseRequestPrivateStoreSell(t *testing.T) {
+	w := l2pkt.NewWriter()
+	w.WriteD(0x10000001)
+	w.WriteD(1)
+	w.WriteD(500)
+	w.WriteD(2369)
+	w.WriteH(0)
+	w.WriteH(0)
+	w.WriteQ(1)
+	w.WriteQ(250000)
+	p, err := ParseRequestPrivateStoreSell(w.Bytes())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if p.StoreObjectID != 0x10000001 || len(p.Items) != 1 ||
+		p.Items[0] != (StoreLine{ObjectID: 500, ItemID: 2369, Count: 1, Price: 250000}) {
+		t.Fatalf("bad parse: %+v", p)
+	}
+}
@@ -0,0 +1,39 @@
+package inclient
+
+import (
+	"fmt"
+
+	"github.com/VerTox/l2go/pkg/l2pkt"
+)
+
+// RequestQuestAbortPacket is RequestQuestAbort (0x63): D questId, a quest the
+// player gives up from the quest journal.
+type RequestQuestAbortPacket struct {
+	QuestID int32
+}
+
+// ParseRequestQuestAbort parses the quest id.
+func ParseRequestQuestAbort(payload []byte) (*RequestQuestAbortPacket, error) {
+	r := l2pkt.NewReader(payload)
+	id, err := r.ReadD()
+	if err != nil {
+		return nil, fmt.Errorf("read quest id: %w", err)
+	}
+	return &RequestQuestAbortPacket{QuestID: id}, nil
+}
+
+// RequestTutorialQuestionMarkPacket is RequestTutorialQuestionMark (0x87):
+// D mark, the question mark (TutorialShowQuestionMark) the player clicked.
+type RequestTutorialQuestionMarkPacket struct {
+	Mark int32
+}
+
+// ParseRequestTutorialQuestionMark parses the clicked mark.
+func ParseRequestTutorialQuestionMark(payload []byte) (*RequestTutorialQuestionMarkPacket, error) {
+	r := l2pkt.NewReader(payload)
+	mark, err := r.ReadD()
+	if err != nil {
+		return nil, fmt.Errorf("read mark: %w", err)
+	}
+	return &RequestTutorialQuestionMarkPacket{Mark: mark}, nil
+}
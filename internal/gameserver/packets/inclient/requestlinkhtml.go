@@ -0,0 +1,23 @@
+package inclient
+
+import (
+	"fmt"
+
+	"github.com/VerTox/l2go/pkg/l2pkt"
+)
+
+// RequestLinkHtmlPacket is RequestLinkHtml (0x22): S link, the html page an
+// NPC dialogue "link" points at.
+type RequestLinkHtmlPacket struct {
+	Link string
+}
+
+// ParseRequestLinkHtml parses the link path.
+func ParseRequestLinkHtml(payload []byte) (*RequestLinkHtmlPacket, error) {
+	r := l2pkt.NewReader(payload)
+	link, err := r.ReadS()
+	if err != nil {
+		return nil, fmt.Errorf("read link: %w", err)
+	}
+	return &RequestLinkHtmlPacket{Link: link}, nil
+}
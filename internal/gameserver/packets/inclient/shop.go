@@ -0,0 +1,91 @@
+package inclient
+
+import (
+	"fmt"
+
+	"github.com/VerTox/l2go/pkg/l2pkt"
+)
+
+// ShopList is a confirmed merchant window: the buy list it was opened with and
+// its lines. RequestBuyItem (0x40) sends D listId, D n, n×(D itemId, Q count)
+// — L2J HF BATCH_LENGTH 12, lines carry ItemID and Count; RequestSellItem
+// (0x37) sends D listId, D n, n×(D objectId, D itemId, Q count) — BATCH_LENGTH
+// 16, lines carry ObjectID, ItemID and Count.
+type ShopList struct {
+	ListID int32
+	Items  []StoreLine
+}
+
+// ParseRequestBuyItem parses RequestBuyItem.
+func ParseRequestBuyItem(data []byte) (*ShopList, error) {
+	return parseShopList(data, 12, func(r *l2pkt.Reader, l *StoreLine) error {
+		var err error
+		if l.ItemID, err = r.ReadD(); err != nil {
+			return fmt.Errorf("read itemId: %w", err)
+		}
+		if l.Count, err = r.ReadQ(); err != nil {
+			return fmt.Errorf("read count: %w", err)
+		}
+		return nil
+	})
+}
+
+// ParseRequestSellItem parses RequestSellItem.
+func ParseRequestSellItem(data []byte) (*ShopList, error) {
+	return parseShopList(data, 16, func(r *l2pkt.Reader, l *StoreLine) error {
+		var err error
+		if l.ObjectID, err = r.ReadD(); err != nil {
+			return fmt.Errorf("read objectId: %w", err)
+		}
+		if l.ItemID, err = r.ReadD(); err != nil {
+			return fmt.Errorf("read itemId: %w", err)
+		}
+		if l.Count, err = r.ReadQ(); err != nil {
+			return fmt.Errorf("read count: %w", err)
+		}
+		return nil
+	})
+}
+
+func parseShopList(data []byte, batch int, read func(*l2pkt.Reader, *StoreLine) error) (*ShopList, error) {
+	r := l2pkt.NewReader(data)
+	listID, err := r.ReadD()
+	if err != nil {
+		return nil, fmt.Errorf("read listId: %w", err)
+	}
+	items, err := readStoreLines(r, batch, read)
+	if err != nil {
+		return nil, err
+	}
+	return &ShopList{ListID: listID, Items: items}, nil
+}
+
+// RequestRefundItem buys back lines of the refund list (0xD0:0x75).
+// Format: D listId, D n, n×(D refund list index).
+type RequestRefundItem struct {
+	ListID  int32
+	Indexes []int32
+}
+
+// ParseRequestRefundItem parses RequestRefundItem.
+func ParseRequestRefundItem(data []byte) (*RequestRefundItem, error) {
+	r := l2pkt.NewReader(data)
+	listID, err := r.ReadD()
+	if err != nil {
+		return nil, fmt.Errorf("read listId: %w", err)
+	}
+	n, err := r.ReadD()
+	if err != nil {
+		return nil, fmt.Errorf("read count: %w", err)
+	}
+	if n < 0 || n > maxStoreLines || int(n)*4 != r.Remaining() {
+		return nil, fmt.Errorf("invalid item count %d for %d remaining bytes", n, r.Remaining())
+	}
+	idx := make([]int32, n)
+	for i := range idx {
+		if idx[i], err = r.ReadD(); err != nil {
+			return nil, fmt.Errorf("item %d: %w", i, err)
+		}
+	}
+	return &RequestRefundItem{ListID: listID, Indexes: idx}, nil
+}
@@ -0,0 +1,60 @@
+package inclient
+
+import (
+	"testing"
+
+	"github.com/VerTox/l2go/pkg/l2pkt"
+)
+
+func TestParseShopLists(t *testing.T) {
+	w := l2pkt.NewWriter()
+	w.WriteD(3030101)
+	w.WriteD(1)
+	w.WriteD(1060)
+	w.WriteQ(20)
+	buy, err := ParseRequestBuyItem(w.Bytes())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if buy.ListID != 3030101 || len(buy.Items) != 1 || buy.Items[0] != (StoreLine{ItemID: 1060, Count: 20}) {
+		t.Fatalf("bad buy parse: %+v", buy)
+	}
+	// A sell line is 16 bytes: the buy layout does not fit it.
+	if _, err := ParseRequestSellItem(w.Bytes()); err == nil {
+		t.Fatal("mismatched sell count should fail")
+	}
+
+	w = l2pkt.NewWriter()
+	w.WriteD(3030101)
+	w.WriteD(1)
+	w.WriteD(100)
+	w.WriteD(1835)
+	w.WriteQ(300)
+	sell, err := ParseRequestSellItem(w.Bytes())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(sell.Items) != 1 || sell.Items[0] != (StoreLine{ObjectID: 100, ItemID: 1835, Count: 300}) {
+		t.Fatalf("bad sell parse: %+v", sell)
+	}
+}
+
+func TestParseRequestRefundItem(t *testing.T) {
+	w := l2pkt.NewWriter()
+	w.WriteD(3030101)
+	w.WriteD(2)
+	w.WriteD(0)
+	w.WriteD(3)
+	p, err := ParseRequestRefundItem(w.Bytes())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if p.ListID != 3030101 || len(p.Indexes) != 2 || p.Indexes[0] != 0 || p.Indexes[1] != 3 {
+		t.Fatalf("bad parse: %+v", p)
+	}
+
+	w.WriteD(0)
+	if _, err := ParseRequestRefundItem(w.Bytes()); err == nil {
+		t.Fatal("mismatched count should fail")
+	}
+}
@@ -0,0 +1,65 @@
+package inclient
+
+import (
+	"fmt"
+
+	"github.com/VerTox/l2go/pkg/l2pkt"
+)
+
+// TradeRequest asks another player for a trade (opcode 0x1a).
+// Format: D objectId (L2J HF TradeRequest.readImpl).
+type TradeRequest struct {
+	ObjectID int32
+}
+
+// ParseTradeRequest parses a TradeRequest packet.
+func ParseTradeRequest(data []byte) (*TradeRequest, error) {
+	r := l2pkt.NewReader(data)
+	objectID, err := r.ReadD()
+	if err != nil {
+		return nil, fmt.Errorf("read objectId: %w", err)
+	}
+	return &TradeRequest{ObjectID: objectID}, nil
+}
+
+// AddTradeItem puts an inventory item into the open trade window (opcode 0x1b).
+// Format: D tradeId (unused), D objectId, Q count.
+type AddTradeItem struct {
+	TradeID  int32
+	ObjectID int32
+	Count    int64
+}
+
+// ParseAddTradeItem parses an AddTradeItem packet.
+func ParseAddTradeItem(data []byte) (*AddTradeItem, error) {
+	r := l2pkt.NewReader(data)
+	tradeID, err := r.ReadD()
+	if err != nil {
+		return nil, fmt.Errorf("read tradeId: %w", err)
+	}
+	objectID, err := r.ReadD()
+	if err != nil {
+		return nil, fmt.Errorf("read objectId: %w", err)
+	}
+	count, err := r.ReadQ()
+	if err != nil {
+		return nil, fmt.Errorf("read count: %w", err)
+	}
+	return &AddTradeItem{TradeID: tradeID, ObjectID: objectID, Count: count}, nil
+}
+
+// TradeResponse carries a single D answer: TradeDone (0x1c, 1 = confirm,
+// 0 = cancel) and AnswerTradeRequest (0x55, 1 = accept) share this layout.
+type TradeResponse struct {
+	Response int32
+}
+
+// ParseTradeResponse parses TradeDone / AnswerTradeRequest.
+func ParseTradeResponse(data []byte) (*TradeResponse, error) {
+	r := l2pkt.NewReader(data)
+	response, err := r.ReadD()
+	if err != nil {
+		return nil, fmt.Errorf("read response: %w", err)
+	}
+	return &TradeResponse{Response: response}, nil
+}
@@ -0,0 +1,34 @@
+package inclient
+
+import (
+	"fmt"
+
+	"github.com/VerTox/l2go/pkg/l2pkt"
+)
+
+// WarehouseList is a confirmed warehouse window: SendWareHouseDepositList
+// (0x3b) and SendWareHouseWithDrawList (0x3c) share the layout
+// D n, n×(D objectId, Q count) — L2J HF BATCH_LENGTH 12. Lines carry only
+// ObjectID and Count.
+type WarehouseList struct {
+	Items []StoreLine
+}
+
+// ParseWarehouseList parses either warehouse list packet.
+func ParseWarehouseList(data []byte) (*WarehouseList, error) {
+	r := l2pkt.NewReader(data)
+	items, err := readStoreLines(r, 12, func(r *l2pkt.Reader, l *StoreLine) error {
+		var err error
+		if l.ObjectID, err = r.ReadD(); err != nil {
+			return fmt.Errorf("read objectId: %w", err)
+		}
+		if l.Count, err = r.ReadQ(); err != nil {
+			return fmt.Errorf("read count: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &WarehouseList{Items: items}, nil
+}
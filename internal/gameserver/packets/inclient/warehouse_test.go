@@ -0,0 +1,29 @@
+package inclient
+
+import (
+	"testing"
+
+	"github.com/VerTox/l2go/pkg/l2pkt"
+)
+
+func TestParseWarehouseList(t *testing.T) {
+	w := l2pkt.NewWriter()
+	w.WriteD(2)
+	w.WriteD(100)
+	w.WriteQ(5)
+	w.WriteD(101)
+	w.WriteQ(1)
+	p, err := ParseWarehouseList(w.Bytes())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(p.Items) != 2 || p.Items[0] != (StoreLine{ObjectID: 100, Count: 5}) || p.Items[1] != (StoreLine{ObjectID: 101, Count: 1}) {
+		t.Fatalf("bad parse: %+v", p)
+	}
+
+	// Trailing bytes the count does not cover are rejected.
+	w.WriteD(0)
+	if _, err := ParseWarehouseList(w.Bytes()); err == nil {
+		t.Fatal("mismatched count should fail")
+	}
+}
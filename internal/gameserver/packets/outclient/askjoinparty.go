@@ -0,0 +1,47 @@
+package outclient
+
+import "github.com/VerTox/l2go/pkg/l2pkt"
+
+// BuildAskJoinParty builds AskJoinParty (0x39) — the invitation dialog shown to the
+// invited player. L2J HF AskJoinParty.writeImpl: C 0x39, S requestorName,
+// D distributionType.
+func BuildAskJoinParty(requestorName string, lootType int32) []byte {
+	w := l2pkt.NewWriter()
+	w.WriteC(0x39)
+	w.WriteS(requestorName)
+	w.WriteD(lootType)
+	return w.Bytes()
+}
+
+// BuildJoinParty builds JoinParty (0x3A) — the invitee's answer relayed back to the
+// inviter (1 = accepted, 0 = declined/expired). L2J HF JoinParty.writeImpl.
+func BuildJoinParty(response int32) []byte {
+	w := l2pkt.NewWriter()
+	w.WriteC(0x3A)
+	w.WriteD(response)
+	return w.Bytes()
+}
+
+// BuildExAskModifyPartyLooting builds ExAskModifyPartyLooting (0xFE:0xBF) — asks a
+// member to approve the leader's loot-mode change. L2J HF writeImpl: S requestor,
+// D distributionType.
+func BuildExAskModifyPartyLooting(requestorName string, lootType int32) []byte {
+	w := l2pkt.NewWriter()
+	w.WriteC(0xFE)
+	w.WriteH(0xBF)
+	w.WriteS(requestorName)
+	w.WriteD(lootType)
+	return w.Bytes()
+}
+
+// BuildExSetPartyLooting builds ExSetPartyLooting (0xFE:0xC0) — the vote outcome
+// (result 1 = changed, 0 = cancelled) and the party's now-current loot mode.
+// L2J HF ExSetPartyLooting.writeImpl.
+func BuildExSetPartyLooting(result, lootType int32) []byte {
+	w := l2pkt.NewWriter()
+	w.WriteC(0xFE)
+	w.WriteH(0xC0)
+	w.WriteD(result)
+	w.WriteD(lootType)
+	return w.Bytes()
+}
@@ -0,0 +1,25 @@
+package outclient
+
+import (
+	"github.com/VerTox/l2go/internal/gameserver/models"
+	"github.com/VerTox/l2go/pkg/l2pkt"
+)
+
+// ChangeWaitType values (L2J ChangeWaitType.WT_*).
+const (
+	WaitTypeSitting  int32 = 0
+	WaitTypeStanding int32 = 1
+)
+
+// BuildChangeWaitType builds ChangeWaitType (0x29) — a character sits down or
+// stands up. L2J HF writeImpl: C 0x29, D objId, D waitType, D x, D y, D z.
+func BuildChangeWaitType(objectID, waitType int32, pos models.Position) []byte {
+	w := l2pkt.NewWriter()
+	w.WriteC(0x29)
+	w.WriteD(objectID)
+	w.WriteD(waitType)
+	w.WriteD(int32(pos.X))
+	w.WriteD(int32(pos.Y))
+	w.WriteD(int32(pos.Z))
+	return w.Bytes()
+}
@@ -0,0 +1,31 @@
+package outclient
+
+import "github.com/VerTox/l2go/pkg/l2pkt"
+
+// BossRecord is one row of the raid point window: the points a character
+// earned on one boss.
+type BossRecord struct {
+	BossID int32 // NPC template id
+	Points int32
+}
+
+// BuildExGetBossRecord builds the ExGetBossRecord extended packet (0xFE:0x34,
+// per L2J HF serverpackets/ExGetBossRecord.java), the reply to
+// RequestGetBossRecord that fills the client's raid point window.
+//
+// Format: writeC(0xFE), writeH(0x34), writeD(ranking), writeD(totalPoints),
+// writeD(count), then per boss writeD(bossId), writeD(points), writeD(0).
+func BuildExGetBossRecord(ranking, totalPoints int32, bosses []BossRecord) []byte {
+	w := l2pkt.NewWriter()
+	w.WriteC(0xfe)
+	w.WriteH(0x34)
+	w.WriteD(ranking)
+	w.WriteD(totalPoints)
+	w.WriteD(int32(len(bosses)))
+	for _, b := range bosses {
+		w.WriteD(b.BossID)
+		w.WriteD(b.Points)
+		w.WriteD(0) // unknown, always 0
+	}
+	return w.Bytes()
+}
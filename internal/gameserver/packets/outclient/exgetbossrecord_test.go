@@ -0,0 +1,36 @@
+package outclient
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestBuildExGetBossRecord(t *testing.T) {
+	got := BuildExGetBossRecord(3, 40, []BossRecord{{BossID: 25001, Points: 40}})
+	want := []byte{
+		0xFE,       // opcode
+		0x34, 0x00, // sub-opcode (H, little-endian)
+		0x03, 0x00, 0x00, 0x00, // ranking
+		0x28, 0x00, 0x00, 0x00, // total points
+		0x01, 0x00, 0x00, 0x00, // count
+		0xA9, 0x61, 0x00, 0x00, // boss id
+		0x28, 0x00, 0x00, 0x00, // points
+		0x00, 0x00, 0x00, 0x00, // unknown
+	}
+	if !bytes.Equal(got, want) {
+		t.Errorf("ExGetBossRecord bytes mismatch\n got: %x\nwant: %x", got, want)
+	}
+}
+
+func TestBuildExGetBossRecord_Empty(t *testing.T) {
+	got := BuildExGetBossRecord(0, 0, nil)
+	want := []byte{
+		0xFE, 0x34, 0x00,
+		0x00, 0x00, 0x00, 0x00, // ranking
+		0x00, 0x00, 0x00, 0x00, // total points
+		0x00, 0x00, 0x00, 0x00, // count
+	}
+	if !bytes.Equal(got, want) {
+		t.Errorf("ExGetBossRecord bytes mismatch\n got: %x\nwant: %x", got, want)
+	}
+}
@@ -0,0 +1,27 @@
+package outclient
+
+import "github.com/VerTox/l2go/pkg/l2pkt"
+
+// Compass zone codes for ExSetCompassZoneCode (L2J HF ExSetCompassZoneCode):
+// the zone kind the client shows next to the minimap and uses to tint names.
+const (
+	CompassZoneAltered    int32 = 0x08
+	CompassZoneSiegeWar   int32 = 0x0B // SIEGEWARZONE2: the siege itself
+	CompassZonePeace      int32 = 0x0C
+	CompassZoneSevenSigns int32 = 0x0D
+	CompassZonePvP        int32 = 0x0E
+	CompassZoneGeneral    int32 = 0x0F
+)
+
+// BuildExSetCompassZoneCode builds the ExSetCompassZoneCode extended packet
+// (0xFE:0x33, per L2J HF serverpackets/ExSetCompassZoneCode.java), sent when a
+// player crosses into a zone of another kind.
+//
+// Format: writeC(0xFE), writeH(0x33), writeD(zoneType).
+func BuildExSetCompassZoneCode(zoneType int32) []byte {
+	w := l2pkt.NewWriter()
+	w.WriteC(0xfe)
+	w.WriteH(0x33)
+	w.WriteD(zoneType)
+	return w.Bytes()
+}
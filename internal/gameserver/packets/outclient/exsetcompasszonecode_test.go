@@ -0,0 +1,18 @@
+package outclient
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestBuildExSetCompassZoneCode(t *testing.T) {
+	got := BuildExSetCompassZoneCode(CompassZonePeace)
+	want := []byte{
+		0xFE,       // opcode
+		0x33, 0x00, // sub-opcode (H, little-endian)
+		0x0C, 0x00, 0x00, 0x00, // zone type
+	}
+	if !bytes.Equal(got, want) {
+		t.Errorf("ExSetCompassZoneCode bytes mismatch\n got: %x\nwant: %x", got, want)
+	}
+}
@@ -0,0 +1,53 @@
+package outclient
+
+import "github.com/VerTox/l2go/pkg/l2pkt"
+
+// BuildSpawnItem builds SpawnItem (0x05), which shows an item already lying
+// on the ground to a client that comes into view of it. L2J HF writeImpl:
+// C 0x05, D objectId, D itemId, D x, D y, D z, D stackable, Q count, D 0, D 0.
+func BuildSpawnItem(objectID, itemID int32, x, y, z int, stackable bool, count int64) []byte {
+	w := l2pkt.NewWriter()
+	w.WriteC(0x05)
+	w.WriteD(objectID)
+	w.WriteD(itemID)
+	w.WriteD(int32(x))
+	w.WriteD(int32(y))
+	w.WriteD(int32(z))
+	w.WriteD(boolToD(stackable))
+	w.WriteQ(count)
+	w.WriteD(0)
+	w.WriteD(0)
+	return w.Bytes()
+}
+
+// BuildDropItem builds DropItem (0x16), which plays an item falling to the
+// ground from dropperID. L2J HF writeImpl: C 0x16, D dropper objectId, D item
+// objectId, D itemId, D x, D y, D z, D stackable, Q count, D 1.
+func BuildDropItem(dropperID, objectID, itemID int32, x, y, z int, stackable bool, count int64) []byte {
+	w := l2pkt.NewWriter()
+	w.WriteC(0x16)
+	w.WriteD(dropperID)
+	w.WriteD(objectID)
+	w.WriteD(itemID)
+	w.WriteD(int32(x))
+	w.WriteD(int32(y))
+	w.WriteD(int32(z))
+	w.WriteD(boolToD(stackable))
+	w.WriteQ(count)
+	w.WriteD(1)
+	return w.Bytes()
+}
+
+// BuildGetItem builds GetItem (0x17), which plays charID picking up the item
+// at x, y, z. L2J HF writeImpl: C 0x17, D charId, D item objectId, D x, D y,
+// D z.
+func BuildGetItem(charID, objectID int32, x, y, z int) []byte {
+	w := l2pkt.NewWriter()
+	w.WriteC(0x17)
+	w.WriteD(charID)
+	w.WriteD(objectID)
+	w.WriteD(int32(x))
+	w.WriteD(int32(y))
+	w.WriteD(int32(z))
+	return w.Bytes()
+}
@@ -0,0 +1,9 @@
+package outclient
+
+import "testing"
+
+func TestGroundItemPackets(t *testing.T) {
+	checkGolden(t, "spawnitem", BuildSpawnItem(1000042, 57, -71000, 258000, -3100, true, 35))
+	checkGolden(t, "dropitem", BuildDropItem(1000007, 1000042, 112, -71000, 258000, -3100, false, 1))
+	checkGolden(t, "getitem", BuildGetItem(7, 1000042, -71000, 258000, -3100))
+}
@@ -0,0 +1,84 @@
+package outclient
+
+import "github.com/VerTox/l2go/pkg/l2pkt"
+
+// MultiSellPageSize is how many entries one MultiSellList page carries (L2J
+// MultisellData.PAGE_SIZE).
+const MultiSellPageSize = 40
+
+// MultiSellItem is a product or ingredient line of a multisell entry. Type2 is
+// -1 (0xFFFF) for an item the server has no template for.
+type MultiSellItem struct {
+	ItemID       int32
+	BodyPart     int32
+	Type2        int16
+	Count        int64
+	EnchantLevel int32
+}
+
+// MultiSellEntry is one exchange of a multisell list.
+type MultiSellEntry struct {
+	EntryID     int32
+	Stackable   bool
+	Products    []MultiSellItem
+	Ingredients []MultiSellItem
+}
+
+// BuildMultiSellList builds MultiSellList (0xD0) — one page of a multisell
+// list; page counts from 1 and finished is set on the last one. L2J HF
+// writeImpl: C 0xD0, D listId, D page, D finished, D pageSize, D n, then per
+// entry D id, C stackable, H 0, D 0, D 0, H 0xFFFE, 7×H 0, H products,
+// H ingredients; a product is D itemId, D bodyPart, H type2, Q count,
+// H enchant, D augment, D mana, 8×H attributes; an ingredient the same without
+// the body part.
+func BuildMultiSellList(listID, page int32, finished bool, entries []MultiSellEntry) []byte {
+	w := l2pkt.NewWriter()
+	w.WriteC(0xD0)
+	w.WriteD(listID)
+	w.WriteD(page)
+	if finished {
+		w.WriteD(1)
+	} else {
+		w.WriteD(0)
+	}
+	w.WriteD(MultiSellPageSize)
+	w.WriteD(int32(len(entries)))
+	for _, e := range entries {
+		w.WriteD(e.EntryID)
+		if e.Stackable {
+			w.WriteC(1)
+		} else {
+			w.WriteC(0)
+		}
+		w.WriteH(0)
+		w.WriteD(0)
+		w.WriteD(0)
+		w.WriteH(0xFFFE)
+		for i := 0; i < 7; i++ {
+			w.WriteH(0)
+		}
+		w.WriteH(uint16(len(e.Products)))
+		w.WriteH(uint16(len(e.Ingredients)))
+		for _, it := range e.Products {
+			w.WriteD(it.ItemID)
+			w.WriteD(it.BodyPart)
+			writeMultiSellItem(w, it)
+		}
+		for _, it := range e.Ingredients {
+			w.WriteD(it.ItemID)
+			writeMultiSellItem(w, it)
+		}
+	}
+	return w.Bytes()
+}
+
+func writeMultiSellItem(w *l2pkt.Writer, it MultiSellItem) {
+	w.WriteH(uint16(it.Type2))
+	w.WriteQ(it.Count)
+	w.WriteH(uint16(it.EnchantLevel))
+	w.WriteD(0) // augmentation
+	w.WriteD(0) // mana
+	for i := 0; i < 8; i++ {
+		w.WriteH(0) // attack element, its power, 6 defence elements
+	}
+}
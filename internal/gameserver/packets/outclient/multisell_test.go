@@ -0,0 +1,20 @@
+package outclient
+
+import "testing"
+
+func TestMultiSellList(t *testing.T) {
+	entries := []MultiSellEntry{
+		{
+			EntryID:     1,
+			Stackable:   true,
+			Products:    []MultiSellItem{{ItemID: 1061, Type2: 5, Count: 1}},
+			Ingredients: []MultiSellItem{{ItemID: 1060, Type2: 5, Count: 10}, {ItemID: 57, Type2: 4, Count: 100}},
+		},
+		{
+			EntryID:     2,
+			Products:    []MultiSellItem{{ItemID: 2, BodyPart: 0x4000, Count: 1, EnchantLevel: 4}},
+			Ingredients: []MultiSellItem{{ItemID: 1, Count: 1, EnchantLevel: 4}},
+		},
+	}
+	checkGolden(t, "multisell_list", BuildMultiSellList(3030101, 1, true, entries))
+}
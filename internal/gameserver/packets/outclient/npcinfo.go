@@ -14,7 +14,7 @@ func BuildNpcInfo(npc *models.NpcInstance) []byte {
 	w.WriteC(0x0C) // opcode
 
 	w.WriteD(npc.ObjectID)                    // objectId
-	w.WriteD(npc.TemplateID + 1_000_000)      // npcTypeId = npcId + 1000000
+	w.WriteD(npcDisplayID(npc) + 1_000_000)   // npcTypeId = displayId + 1000000
 	w.WriteD(boolToD(t.Attackable))           // isAttackable
 	w.WriteD(int32(npc.Position.X))           // x
 	w.WriteD(int32(npc.Position.Y))           // y
@@ -95,3 +95,12 @@ func boolToC(b bool) byte {
 	}
 	return 0
 }
+
+// npcDisplayID is the client NPC id an NPC is drawn as: the template's display
+// id, which custom NPCs set to a retail NPC, or else its own id.
+func npcDisplayID(npc *models.NpcInstance) int32 {
+	if npc.Template != nil && npc.Template.DisplayID != 0 {
+		return npc.Template.DisplayID
+	}
+	return npc.TemplateID
+}
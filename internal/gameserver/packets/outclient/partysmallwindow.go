@@ -0,0 +1,117 @@
+package outclient
+
+import "github.com/VerTox/l2go/pkg/l2pkt"
+
+// PartyMemberInfo is one member row of the party window (name, vitals, level,
+// class). Shared by PartySmallWindowAll/Add/Update.
+type PartyMemberInfo struct {
+	ObjectID int32
+	Name     string
+	CurCP    int32
+	MaxCP    int32
+	CurHP    int32
+	MaxHP    int32
+	CurMP    int32
+	MaxMP    int32
+	Level    int32
+	ClassID  int32
+	Race     int32
+}
+
+func writePartyMemberVitals(w *l2pkt.Writer, m PartyMemberInfo) {
+	w.WriteD(m.ObjectID)
+	w.WriteS(m.Name)
+	w.WriteD(m.CurCP)
+	w.WriteD(m.MaxCP)
+	w.WriteD(m.CurHP)
+	w.WriteD(m.MaxHP)
+	w.WriteD(m.CurMP)
+	w.WriteD(m.MaxMP)
+	w.WriteD(m.Level)
+	w.WriteD(m.ClassID)
+}
+
+// BuildPartySmallWindowAll builds PartySmallWindowAll (0x4E) — the full party
+// window sent to a member on join and after a leader change. members must exclude
+// the receiving player. L2J HF PartySmallWindowAll.writeImpl:
+//
+//	C 0x4E, D leaderObjectId, D distributionType, D count
+//	per member: D objId, S name, D cp, D maxCp, D hp, D maxHp, D mp, D maxMp,
+//	            D level, D classId, D 0, D race, D 0, D 0, D summonObjId (0 = none)
+func BuildPartySmallWindowAll(leaderObjectID, lootType int32, members []PartyMemberInfo) []byte {
+	w := l2pkt.NewWriter()
+	w.WriteC(0x4E)
+	w.WriteD(leaderObjectID)
+	w.WriteD(lootType)
+	w.WriteD(int32(len(members)))
+	for _, m := range members {
+		writePartyMemberVitals(w, m)
+		w.WriteD(0x00)
+		w.WriteD(m.Race)
+		w.WriteD(0x00)
+		w.WriteD(0x00)
+		w.WriteD(0x00) // no summon
+	}
+	return w.Bytes()
+}
+
+// BuildPartySmallWindowAdd builds PartySmallWindowAdd (0x4F) — a new member row
+// appended to the windows of the existing members. L2J HF writeImpl: C 0x4F,
+// D leaderObjectId, D distributionType, member vitals, D 0, D 0.
+func BuildPartySmallWindowAdd(leaderObjectID, lootType int32, m PartyMemberInfo) []byte {
+	w := l2pkt.NewWriter()
+	w.WriteC(0x4F)
+	w.WriteD(leaderObjectID)
+	w.WriteD(lootType)
+	writePartyMemberVitals(w, m)
+	w.WriteD(0x00)
+	w.WriteD(0x00)
+	return w.Bytes()
+}
+
+// BuildPartySmallWindowDelete builds PartySmallWindowDelete (0x50) — removes one
+// member row. L2J HF writeImpl: C 0x50, D objId, S name.
+func BuildPartySmallWindowDelete(objectID int32, name string) []byte {
+	w := l2pkt.NewWriter()
+	w.WriteC(0x50)
+	w.WriteD(objectID)
+	w.WriteS(name)
+	return w.Bytes()
+}
+
+// BuildPartySmallWindowDeleteAll builds PartySmallWindowDeleteAll (0x51) — closes
+// the party window (the receiver left, was expelled, or the party dispersed).
+func BuildPartySmallWindowDeleteAll() []byte {
+	w := l2pkt.NewWriter()
+	w.WriteC(0x51)
+	return w.Bytes()
+}
+
+// BuildPartySmallWindowUpdate builds PartySmallWindowUpdate (0x52) — refreshes one
+// member's bars/level in the other members' windows. L2J HF writeImpl: C 0x52,
+// member vitals (objId, name, cp/maxCp, hp/maxHp, mp/maxMp, level, classId).
+func BuildPartySmallWindowUpdate(m PartyMemberInfo) []byte {
+	w := l2pkt.NewWriter()
+	w.WriteC(0x52)
+	writePartyMemberVitals(w, m)
+	return w.Bytes()
+}
+
+// BuildPartySpelled builds PartySpelled (0xF4) — a party member's buff icons shown
+// under its row in the party window. L2J HF PartySpelled.writeImpl:
+//
+//	C 0xF4, D type (0 = player), D objectId, D count
+//	per effect: D skillId, H level, D remaining seconds
+func BuildPartySpelled(objectID int32, buffs []AbnormalBuff) []byte {
+	w := l2pkt.NewWriter()
+	w.WriteC(0xF4)
+	w.WriteD(0x00)
+	w.WriteD(objectID)
+	w.WriteD(int32(len(buffs)))
+	for _, b := range buffs {
+		w.WriteD(b.DisplayID)
+		w.WriteH(uint16(b.DisplayLevel))
+		w.WriteD(b.RemainSec)
+	}
+	return w.Bytes()
+}
@@ -0,0 +1,48 @@
+package outclient
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestPartyWindowPackets(t *testing.T) {
+	m := PartyMemberInfo{
+		ObjectID: 0x10000001, Name: "Member",
+		CurCP: 50, MaxCP: 60, CurHP: 120, MaxHP: 150, CurMP: 40, MaxMP: 70,
+		Level: 20, ClassID: 1, Race: 0,
+	}
+	checkGolden(t, "partysmallwindowall", BuildPartySmallWindowAll(0x10000002, 3, []PartyMemberInfo{m}))
+	checkGolden(t, "partysmallwindowadd", BuildPartySmallWindowAdd(0x10000002, 3, m))
+	checkGolden(t, "partysmallwindowupdate", BuildPartySmallWindowUpdate(m))
+	checkGolden(t, "partysmallwindowdelete", BuildPartySmallWindowDelete(m.ObjectID, m.Name))
+	checkGolden(t, "partyspelled", BuildPartySpelled(m.ObjectID, []AbnormalBuff{{DisplayID: 1068, DisplayLevel: 3, RemainSec: 1200}}))
+	checkGolden(t, "askjoinparty", BuildAskJoinParty("Leader", 1))
+}
+
+// TestPartyFixedPackets checks the parameterless / numeric-only party packets
+// byte-for-byte against L2J HF writeImpl.
+func TestPartyFixedPackets(t *testing.T) {
+	cases := []struct {
+		name string
+		got  []byte
+		want []byte
+	}{
+		{"PartySmallWindowDeleteAll", BuildPartySmallWindowDeleteAll(), []byte{0x51}},
+		{"JoinParty", BuildJoinParty(1), []byte{0x3A, 0x01, 0x00, 0x00, 0x00}},
+		{"ExSetPartyLooting", BuildExSetPartyLooting(1, 4), []byte{
+			0xFE, 0xC0, 0x00,
+			0x01, 0x00, 0x00, 0x00,
+			0x04, 0x00, 0x00, 0x00,
+		}},
+		{"ExAskModifyPartyLooting", BuildExAskModifyPartyLooting("A", 2), []byte{
+			0xFE, 0xBF, 0x00,
+			'A', 0x00, 0x00, 0x00, // UTF-16LE "A" + terminator
+			0x02, 0x00, 0x00, 0x00,
+		}},
+	}
+	for _, tc := range cases {
+		if !bytes.Equal(tc.got, tc.want) {
+			t.Errorf("%s mismatch\n got: %x\nwant: %x", tc.name, tc.got, tc.want)
+		}
+	}
+}
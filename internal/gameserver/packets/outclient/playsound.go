@@ -0,0 +1,22 @@
+package outclient
+
+import "github.com/VerTox/l2go/pkg/l2pkt"
+
+// BuildPlaySound builds PlaySound (0x9E) for a sound the client plays for the
+// player alone, such as a quest's "ItemSound.quest_accept". L2J HF writeImpl:
+// C 0x9E, D 0 (sound type), S file, D 0 (not bound to an object), D 0
+// (object id), D x, D y, D z, D 0 (delay) — the position is unused for
+// unbound sounds.
+func BuildPlaySound(file string) []byte {
+	w := l2pkt.NewWriter()
+	w.WriteC(0x9E)
+	w.WriteD(0)
+	w.WriteS(file)
+	w.WriteD(0)
+	w.WriteD(0)
+	w.WriteD(0)
+	w.WriteD(0)
+	w.WriteD(0)
+	w.WriteD(0)
+	return w.Bytes()
+}
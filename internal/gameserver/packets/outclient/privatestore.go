@@ -0,0 +1,130 @@
+package outclient
+
+import "github.com/VerTox/l2go/pkg/l2pkt"
+
+// StoreItemRow is one line of a private store window: the item, the asking
+// price per unit and the reference price the client shows beside it (L2J
+// getReferencePrice() * 2). StoreCount is only written by buy-store lists: the
+// units the store owner still wants.
+type StoreItemRow struct {
+	Item       InventoryItem
+	Price      int64
+	RefPrice   int64
+	StoreCount int64
+}
+
+// BuildPrivateStoreManageListSell builds PrivateStoreManageListSell (0xA0) — the
+// seller's setup window. L2J HF writeImpl: C 0xA0, D objId, D packageSale,
+// Q adena, D n, n×(writeItem, Q refPrice), D m, m×(writeItem, Q price, Q refPrice).
+func BuildPrivateStoreManageListSell(objectID int32, packageSale bool, adena int64, available, listed []StoreItemRow) []byte {
+	w := l2pkt.NewWriter()
+	w.WriteC(0xA0)
+	w.WriteD(objectID)
+	w.WriteD(boolToD(packageSale))
+	w.WriteQ(adena)
+	w.WriteD(int32(len(available)))
+	for _, r := range available {
+		writeItemInfo(w, r.Item)
+		w.WriteQ(r.RefPrice)
+	}
+	w.WriteD(int32(len(listed)))
+	for _, r := range listed {
+		writeItemInfo(w, r.Item)
+		w.WriteQ(r.Price)
+		w.WriteQ(r.RefPrice)
+	}
+	return w.Bytes()
+}
+
+// BuildPrivateStoreListSell builds PrivateStoreListSell (0xA1) — a sell store as
+// a buyer sees it. L2J HF writeImpl: C 0xA1, D sellerObjId, D packageSale,
+// Q buyerAdena, D n, n×(writeItem, Q price, Q refPrice).
+func BuildPrivateStoreListSell(sellerObjectID int32, packageSale bool, buyerAdena int64, items []StoreItemRow) []byte {
+	w := l2pkt.NewWriter()
+	w.WriteC(0xA1)
+	w.WriteD(sellerObjectID)
+	w.WriteD(boolToD(packageSale))
+	w.WriteQ(buyerAdena)
+	w.WriteD(int32(len(items)))
+	for _, r := range items {
+		writeItemInfo(w, r.Item)
+		w.WriteQ(r.Price)
+		w.WriteQ(r.RefPrice)
+	}
+	return w.Bytes()
+}
+
+// BuildPrivateStoreMsgSell builds PrivateStoreMsgSell (0xA2) — the title shown
+// over a sell store. L2J HF writeImpl: C 0xA2, D objId, S msg.
+func BuildPrivateStoreMsgSell(objectID int32, msg string) []byte {
+	return buildStoreMsg(0xA2, objectID, msg)
+}
+
+// BuildPrivateStoreManageListBuy builds PrivateStoreManageListBuy (0xBD) — the
+// buyer's setup window. L2J HF writeImpl: C 0xBD, D objId, Q adena, D n,
+// n×(writeItem, Q refPrice), D m, m×(writeItem, Q price, Q refPrice, Q count).
+func BuildPrivateStoreManageListBuy(objectID int32, adena int64, available, listed []StoreItemRow) []byte {
+	w := l2pkt.NewWriter()
+	w.WriteC(0xBD)
+	w.WriteD(objectID)
+	w.WriteQ(adena)
+	w.WriteD(int32(len(available)))
+	for _, r := range available {
+		writeItemInfo(w, r.Item)
+		w.WriteQ(r.RefPrice)
+	}
+	w.WriteD(int32(len(listed)))
+	for _, r := range listed {
+		writeItemInfo(w, r.Item)
+		w.WriteQ(r.Price)
+		w.WriteQ(r.RefPrice)
+		w.WriteQ(r.StoreCount)
+	}
+	return w.Bytes()
+}
+
+// BuildPrivateStoreListBuy builds PrivateStoreListBuy (0xBE) — a buy store as a
+// seller sees it: each wanted item matched to the seller's own stack. L2J HF
+// writeImpl: C 0xBE, D buyerObjId, Q sellerAdena, D n,
+// n×(writeItem, D objId, Q price, Q refPrice, Q storeCount).
+func BuildPrivateStoreListBuy(buyerObjectID int32, sellerAdena int64, items []StoreItemRow) []byte {
+	w := l2pkt.NewWriter()
+	w.WriteC(0xBE)
+	w.WriteD(buyerObjectID)
+	w.WriteQ(sellerAdena)
+	w.WriteD(int32(len(items)))
+	for _, r := range items {
+		writeItemInfo(w, r.Item)
+		w.WriteD(r.Item.ObjectID)
+		w.WriteQ(r.Price)
+		w.WriteQ(r.RefPrice)
+		w.WriteQ(r.StoreCount)
+	}
+	return w.Bytes()
+}
+
+// BuildPrivateStoreMsgBuy builds PrivateStoreMsgBuy (0xBF) — the title shown
+// over a buy store. L2J HF writeImpl: C 0xBF, D objId, S msg.
+func BuildPrivateStoreMsgBuy(objectID int32, msg string) []byte {
+	return buildStoreMsg(0xBF, objectID, msg)
+}
+
+func buildStoreMsg(opcode uint8, objectID int32, msg string) []byte {
+	w := l2pkt.NewWriter()
+	w.WriteC(opcode)
+	w.WriteD(objectID)
+	w.WriteS(msg)
+	return w.Bytes()
+}
+
+// BuildExPrivateStoreSetWholeMsg builds ExPrivateStoreSetWholeMsg (0xFE:0x80) —
+// the title of a package sell store. L2J HF writeImpl: C 0xFE, H 0x80,
+// D objId, S msg.
+func BuildExPrivateStoreSetWholeMsg(objectID int32, msg string) []byte {
+	w := l2pkt.NewWriter()
+	w.WriteC(0xFE)
+	w.WriteH(0x80)
+	w.WriteD(objectID)
+	w.WriteS(msg)
+	return w.Bytes()
+}
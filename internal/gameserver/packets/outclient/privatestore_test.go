@@ -0,0 +1,50 @@
+package outclient
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/VerTox/l2go/internal/gameserver/models"
+)
+
+func TestPrivateStoreListPackets(t *testing.T) {
+	sword := StoreItemRow{
+		Item: InventoryItem{
+			ObjectID: 0x10000011, ItemID: 2369, LocationSlot: -1, Count: 1,
+			BodyPart: 0x4000, EnchantLevel: 3, Mana: -1, TimeRemaining: -9999,
+		},
+		Price: 250000, RefPrice: 20000, StoreCount: 2,
+	}
+	shots := StoreItemRow{
+		Item:  InventoryItem{ObjectID: 0x10000012, ItemID: 1835, LocationSlot: -1, Count: 500, ItemType: 5, TimeRemaining: -9999},
+		Price: 10, RefPrice: 14,
+	}
+	checkGolden(t, "privatestoremanagelistsell", BuildPrivateStoreManageListSell(0x10000001, true, 1500, []StoreItemRow{shots}, []StoreItemRow{sword}))
+	checkGolden(t, "privatestorelistsell", BuildPrivateStoreListSell(0x10000001, false, 300000, []StoreItemRow{sword, shots}))
+	checkGolden(t, "privatestoremanagelistbuy", BuildPrivateStoreManageListBuy(0x10000001, 1500, []StoreItemRow{shots}, []StoreItemRow{sword}))
+	checkGolden(t, "privatestorelistbuy", BuildPrivateStoreListBuy(0x10000001, 42, []StoreItemRow{sword}))
+}
+
+// TestPrivateStoreFixedPackets checks the title and sit/stand packets
+// byte-for-byte against L2J HF writeImpl.
+func TestPrivateStoreFixedPackets(t *testing.T) {
+	cases := []struct {
+		name string
+		got  []byte
+		want []byte
+	}{
+		{"PrivateStoreMsgSell", BuildPrivateStoreMsgSell(0x10000001, "Hi"),
+			[]byte{0xA2, 0x01, 0x00, 0x00, 0x10, 'H', 0, 'i', 0, 0, 0}},
+		{"PrivateStoreMsgBuy", BuildPrivateStoreMsgBuy(0x10000001, ""),
+			[]byte{0xBF, 0x01, 0x00, 0x00, 0x10, 0, 0}},
+		{"ExPrivateStoreSetWholeMsg", BuildExPrivateStoreSetWholeMsg(0x10000001, "A"),
+			[]byte{0xFE, 0x80, 0x00, 0x01, 0x00, 0x00, 0x10, 'A', 0, 0, 0}},
+		{"ChangeWaitType", BuildChangeWaitType(0x10000001, WaitTypeSitting, models.Position{X: 1, Y: -1, Z: 2}),
+			[]byte{0x29, 0x01, 0x00, 0x00, 0x10, 0, 0, 0, 0, 1, 0, 0, 0, 0xFF, 0xFF, 0xFF, 0xFF, 2, 0, 0, 0}},
+	}
+	for _, tc := range cases {
+		if !bytes.Equal(tc.got, tc.want) {
+			t.Errorf("%s = % x, want % x", tc.name, tc.got, tc.want)
+		}
+	}
+}
@@ -0,0 +1,36 @@
+package outclient
+
+import "github.com/VerTox/l2go/pkg/l2pkt"
+
+// QuestListEntry is a started quest in the quest journal: its id and the
+// step (cond) the journal shows.
+type QuestListEntry struct {
+	ID   int32
+	Cond int32
+}
+
+// questMaskSize is the size of QuestList's one-time quest mask: one bit per
+// quest id below 1024.
+const questMaskSize = 128
+
+// BuildQuestList builds QuestList (0x86) — the player's quest journal.
+// completed lists the one-time quests the player finished; the client marks
+// them done in its quest tree. L2J HF writeImpl: C 0x86, H n, per quest D id,
+// D cond, then the 128-byte one-time quest mask.
+func BuildQuestList(quests []QuestListEntry, completed []int32) []byte {
+	w := l2pkt.NewWriter()
+	w.WriteC(0x86)
+	w.WriteH(uint16(len(quests)))
+	for _, q := range quests {
+		w.WriteD(q.ID)
+		w.WriteD(q.Cond)
+	}
+	var mask [questMaskSize]byte
+	for _, id := range completed {
+		if id >= 0 && id < questMaskSize*8 {
+			mask[id/8] |= 1 << (id % 8)
+		}
+	}
+	w.WriteB(mask[:])
+	return w.Bytes()
+}
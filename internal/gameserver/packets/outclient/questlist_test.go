@@ -0,0 +1,16 @@
+package outclient
+
+import "testing"
+
+func TestQuestList(t *testing.T) {
+	checkGolden(t, "questlist", BuildQuestList([]QuestListEntry{{ID: 258, Cond: 1}, {ID: 1, Cond: 3}}, []int32{2, 151}))
+	checkGolden(t, "questlist_empty", BuildQuestList(nil, nil))
+}
+
+func TestTutorialShowQuestionMark(t *testing.T) {
+	checkGolden(t, "tutorialshowquestionmark", BuildTutorialShowQuestionMark(1))
+}
+
+func TestPlaySound(t *testing.T) {
+	checkGolden(t, "playsound", BuildPlaySound("ItemSound.quest_accept"))
+}
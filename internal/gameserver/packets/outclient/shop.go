@@ -0,0 +1,59 @@
+package outclient
+
+import "github.com/VerTox/l2go/pkg/l2pkt"
+
+// ShopItem is a line of a merchant window: the item and its price. Buy list
+// lines carry the unit price the merchant asks, sell and refund lines the unit
+// price it pays.
+type ShopItem struct {
+	Item  InventoryItem
+	Price int64
+}
+
+// BuildBuyList builds ExBuyList (0xFE:0xB7) — what a merchant's buy list
+// sells. Items carry the item id in place of an object id and the stock left
+// as count (0 when unlimited). L2J HF BuyList.writeImpl: C 0xFE, H 0xB7, D 0,
+// Q adena, D listId, H n, n×(writeItem, Q price).
+func BuildBuyList(adena int64, listID int32, items []ShopItem) []byte {
+	w := l2pkt.NewWriter()
+	w.WriteC(0xFE)
+	w.WriteH(0xB7)
+	w.WriteD(0)
+	w.WriteQ(adena)
+	w.WriteD(listID)
+	w.WriteH(uint16(len(items)))
+	for _, it := range items {
+		writeItemInfo(w, it.Item)
+		w.WriteQ(it.Price)
+	}
+	return w.Bytes()
+}
+
+// BuildExBuySellList builds ExBuySellList (0xFE:0xB8) — the sell tab of the
+// shop window: the inventory a merchant buys, then the refund list of items
+// sold this session. done is set on the refresh after a deal. L2J HF
+// writeImpl: C 0xFE, H 0xB8, D 1, H n, n×(writeItem, Q price), H m,
+// m×(writeItem, D index, Q price×count), C done.
+func BuildExBuySellList(sell, refund []ShopItem, done bool) []byte {
+	w := l2pkt.NewWriter()
+	w.WriteC(0xFE)
+	w.WriteH(0xB8)
+	w.WriteD(1)
+	w.WriteH(uint16(len(sell)))
+	for _, it := range sell {
+		writeItemInfo(w, it.Item)
+		w.WriteQ(it.Price)
+	}
+	w.WriteH(uint16(len(refund)))
+	for i, it := range refund {
+		writeItemInfo(w, it.Item)
+		w.WriteD(int32(i))
+		w.WriteQ(it.Price * it.Item.Count)
+	}
+	if done {
+		w.WriteC(0x01)
+	} else {
+		w.WriteC(0x00)
+	}
+	return w.Bytes()
+}
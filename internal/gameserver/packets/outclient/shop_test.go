@@ -0,0 +1,22 @@
+package outclient
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestShopPackets(t *testing.T) {
+	potion := InventoryItem{ObjectID: 1060, ItemID: 1060, Count: 0, ItemType: 5, TimeRemaining: -9999}
+	arrows := InventoryItem{ObjectID: 17, ItemID: 17, Count: 500, ItemType: 5, TimeRemaining: -9999}
+	checkGolden(t, "buylist", BuildBuyList(12000, 3030101, []ShopItem{{Item: potion, Price: 20}, {Item: arrows, Price: 2}}))
+
+	sword := InventoryItem{ObjectID: 0x10000011, ItemID: 1, LocationSlot: -1, Count: 1, BodyPart: 0x4000, EnchantLevel: 2, TimeRemaining: -9999}
+	shots := InventoryItem{ObjectID: 0x10000012, ItemID: 1835, LocationSlot: -1, Count: 300, ItemType: 5, TimeRemaining: -9999}
+	checkGolden(t, "exbuyselllist", BuildExBuySellList([]ShopItem{{Item: sword, Price: 384}}, []ShopItem{{Item: shots, Price: 3}}, true))
+
+	// Nothing to sell or buy back: C 0xFE, H 0xB8, D 1, H 0, H 0, C done.
+	want := []byte{0xFE, 0xB8, 0x00, 0x01, 0, 0, 0, 0x00, 0x00, 0x00, 0x00, 0x00}
+	if got := BuildExBuySellList(nil, nil, false); !bytes.Equal(got, want) {
+		t.Fatalf("empty ExBuySellList = % x, want % x", got, want)
+	}
+}
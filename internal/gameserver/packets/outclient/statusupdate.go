@@ -4,15 +4,17 @@ import "github.com/VerTox/l2go/pkg/l2pkt"
 
 // StatusUpdate attribute IDs (from L2J StatusUpdate.java).
 const (
-	StatusLevel = 0x01
-	StatusExp   = 0x02
-	StatusCurHP = 0x09
-	StatusMaxHP = 0x0A
-	StatusCurMP = 0x0B
-	StatusMaxMP = 0x0C
-	StatusSP    = 0x0D
-	StatusCurCP = 0x21
-	StatusMaxCP = 0x22
+	StatusLevel   = 0x01
+	StatusExp     = 0x02
+	StatusCurHP   = 0x09
+	StatusMaxHP   = 0x0A
+	StatusCurMP   = 0x0B
+	StatusMaxMP   = 0x0C
+	StatusSP      = 0x0D
+	StatusCurLoad = 0x0E
+	StatusMaxLoad = 0x0F
+	StatusCurCP   = 0x21
+	StatusMaxCP   = 0x22
 )
 
 // StatusAttribute represents a single attribute in a StatusUpdate packet.
@@ -12,6 +12,9 @@ const (
 	SysMsgTargetNotFound         = 145  // TARGET_IS_NOT_FOUND_IN_THE_GAME (TELL to offline player)
 	SysMsgNotEnoughMp            = 24   // NOT_ENOUGH_MP "Not enough MP."
 	SysMsgIncorrectTarget        = 109  // INCORRECT_TARGET "Invalid target." (l2go-fgz)
+	SysMsgCantAtkPeaceZone       = 84   // CANT_ATK_PEACEZONE "You may not attack in a peaceful zone."
+	SysMsgTargetInPeaceZone      = 85   // TARGET_IN_PEACEZONE "You may not attack this target in a peaceful zone."
+	SysMsgCantSeeTarget          = 181  // CANT_SEE_TARGET "Cannot see target."
 	SysMsgLearnedSkillS1         = 277  // LEARNED_SKILL_S1 (l2go-hv9)
 	SysMsgNotEnoughSpToLearn     = 278  // NOT_ENOUGH_SP_TO_LEARN_SKILL (l2go-hv9)
 	SysMsgDontSpam               = 1078 // DONT_SPAM "Please refrain from constant individual purchases."
@@ -36,17 +39,87 @@ const (
 	SysMsgC1ReceivedDamageS3FromC2  = 2262 // C1_RECEIVED_DAMAGE_OF_S3_FROM_C2 [TEXT, NPC_NAME, INT]
 	SysMsgC1AttackWentAstray        = 2265 // C1_ATTACK_WENT_ASTRAY (miss) [PLAYER_NAME]
 	SysMsgC1HadCriticalHit          = 2266 // C1_HAD_CRITICAL_HIT (crit) [PLAYER_NAME]
+
+	// Party messages (L2J HF SystemMessageId, L2Party / RequestJoinParty).
+	SysMsgYouInvitedC1ToParty              = 105  // YOU_INVITED_C1_TO_PARTY [PLAYER_NAME]
+	SysMsgYouJoinedS1Party                 = 106  // YOU_JOINED_S1_PARTY [TEXT]
+	SysMsgC1JoinedParty                    = 107  // C1_JOINED_PARTY [PLAYER_NAME]
+	SysMsgC1LeftParty                      = 108  // C1_LEFT_PARTY [PLAYER_NAME]
+	SysMsgYouInvitedWrongTarget            = 152  // YOU_HAVE_INVITED_THE_WRONG_TARGET
+	SysMsgC1IsBusyTryLater                 = 153  // C1_IS_BUSY_TRY_LATER [PLAYER_NAME]
+	SysMsgOnlyLeaderCanInvite              = 154  // ONLY_THE_LEADER_CAN_GIVE_OUT_INVITATIONS
+	SysMsgPartyFull                        = 155  // PARTY_FULL
+	SysMsgC1IsAlreadyInParty               = 160  // C1_IS_ALREADY_IN_PARTY [PLAYER_NAME]
+	SysMsgWaitingForAnotherReply           = 164  // WAITING_FOR_ANOTHER_REPLY
+	SysMsgFirstSelectUserToInvite          = 185  // FIRST_SELECT_USER_TO_INVITE_TO_PARTY
+	SysMsgYouLeftParty                     = 200  // YOU_LEFT_PARTY
+	SysMsgC1WasExpelledFromParty           = 201  // C1_WAS_EXPELLED_FROM_PARTY [PLAYER_NAME]
+	SysMsgHaveBeenExpelledFromParty        = 202  // HAVE_BEEN_EXPELLED_FROM_PARTY
+	SysMsgPartyDispersed                   = 203  // PARTY_DISPERSED
+	SysMsgC1HasBecomePartyLeader           = 1384 // C1_HAS_BECOME_A_PARTY_LEADER [PLAYER_NAME]
+	SysMsgCannotTransferRightsToYourself   = 1401 // YOU_CANNOT_TRANSFER_RIGHTS_TO_YOURSELF
+	SysMsgTransferRightsOnlyToPartyMember  = 1402 // YOU_CAN_TRANSFER_RIGHTS_ONLY_TO_ANOTHER_PARTY_MEMBER
+	SysMsgRequestingApprovalPartyLootS1    = 3135 // REQUESTING_APPROVAL_CHANGE_PARTY_LOOT_S1 [SYSTEM_STRING]
+	SysMsgPartyLootChangeCancelled         = 3137 // PARTY_LOOT_CHANGE_CANCELLED
+	SysMsgPartyLootChangedS1               = 3138 // PARTY_LOOT_CHANGED_S1 [SYSTEM_STRING]
+
+	// Trade messages (L2J HF SystemMessageId, TradeRequest / TradeList).
+	SysMsgRequestC1ForTrade              = 118 // REQUEST_C1_FOR_TRADE [PLAYER_NAME]
+	SysMsgC1DeniedTradeRequest           = 119 // C1_DENIED_TRADE_REQUEST [PLAYER_NAME]
+	SysMsgBeginTradeWithC1               = 120 // BEGIN_TRADE_WITH_C1 [PLAYER_NAME]
+	SysMsgC1ConfirmedTrade               = 121 // C1_CONFIRMED_TRADE [PLAYER_NAME]
+	SysMsgCannotAdjustItemsAfterConfirm  = 122 // CANNOT_ADJUST_ITEMS_AFTER_TRADE_CONFIRMED
+	SysMsgTradeSuccessful                = 123 // TRADE_SUCCESSFUL
+	SysMsgC1CanceledTrade                = 124 // C1_CANCELED_TRADE [PLAYER_NAME]
+	SysMsgAlreadyTrading                 = 142 // ALREADY_TRADING
+	SysMsgC1AlreadyTrading               = 143 // C1_ALREADY_TRADING [PLAYER_NAME]
+	SysMsgTargetTooFar                   = 151 // TARGET_TOO_FAR
+
+	// Private store messages (L2J HF SystemMessageId, TradeList.privateStoreBuy/Sell).
+	SysMsgNotEnoughAdena           = 279  // YOU_NOT_ENOUGH_ADENA
+	SysMsgC1PurchasedS2            = 378  // C1_PURCHASED_S2 [PLAYER_NAME, ITEM_NAME]
+	SysMsgC1PurchasedS3S2          = 380  // C1_PURCHASED_S3_S2_S [PLAYER_NAME, ITEM_NAME, LONG]
+	SysMsgPurchasedS2FromC1        = 559  // PURCHASED_S2_FROM_C1 [PLAYER_NAME, ITEM_NAME]
+	SysMsgPurchasedS3S2FromC1      = 561  // PURCHASED_S3_S2_S_FROM_C1 [PLAYER_NAME, ITEM_NAME, LONG]
+	SysMsgExceededQuantityForInput = 1036 // YOU_HAVE_EXCEEDED_QUANTITY_THAT_CAN_BE_INPUTTED
+
+	// Warehouse messages (L2J HF SystemMessageId, SendWareHouseDepositList /
+	// SendWareHouseWithDrawList / WarehouseInstance).
+	SysMsgSlotsFull           = 129 // SLOTS_FULL
+	SysMsgNoItemDepositedInWh = 282 // NO_ITEM_DEPOSITED_IN_WH
+
+	// Multisell messages (L2J HF SystemMessageId, MultiSellChoose).
+	SysMsgEarnedS2S1S            = 53  // EARNED_S2_S1_S [ITEM_NAME, LONG]
+	SysMsgEarnedItemS1           = 54  // EARNED_ITEM_S1 [ITEM_NAME]
+	SysMsgNotEnoughRequiredItems = 701 // NOT_ENOUGH_REQUIRED_ITEMS
+
+	// Loot messages (L2J HF SystemMessageId, L2PcInstance.addItem/addAdena).
+	SysMsgPickedUpS2S1          = 29 // YOU_PICKED_UP_S2_S1 [ITEM_NAME, LONG]
+	SysMsgPickedUpS1            = 30 // YOU_PICKED_UP_S1 [ITEM_NAME]
+	SysMsgEarnedS1Adena         = 52 // EARNED_S1_ADENA [LONG]
+	SysMsgFailedToPickUpS1Adena = 55 // FAILED_TO_PICKUP_S1_ADENA [LONG]
+	SysMsgFailedToPickUpS1      = 56 // FAILED_TO_PICKUP_S1 [ITEM_NAME]
+	SysMsgFailedToPickUpS2S1    = 57 // FAILED_TO_PICKUP_S2_S1 [ITEM_NAME, LONG]
+
+	// Discard messages (L2J HF SystemMessageId, RequestDropItem/RequestDestroyItem/RequestCrystallizeItem).
+	SysMsgCannotDiscardThisItem   = 98   // CANNOT_DISCARD_THIS_ITEM
+	SysMsgCrystallizeLevelTooLow  = 562  // CRYSTALLIZE_LEVEL_TOO_LOW
+	SysMsgCannotDiscardInShopMode = 1065 // CANNOT_TRADE_DISCARD_DROP_ITEM_WHILE_IN_SHOPMODE
+
+	// Raid boss messages (L2J HF SystemMessageId, L2RaidBossInstance.doDie).
+	SysMsgRaidWasSuccessful = 1209 // RAID_WAS_SUCCESSFUL "Congratulations! The raid was successful."
 )
 
 // SystemMessage parameter types.
 const (
-	smParamText       = 0  // string
-	smParamInt        = 1  // int32
-	smParamNpcName    = 2  // int32 (template ID + 1000000)
-	smParamItemName   = 3  // int32
-	smParamSkillName  = 4  // 2×int32
-	smParamLong       = 6  // int64
-	smParamPlayerName = 12 // string
+	smParamText         = 0  // string
+	smParamInt          = 1  // int32
+	smParamNpcName      = 2  // int32 (template ID + 1000000)
+	smParamItemName     = 3  // int32
+	smParamSkillName    = 4  // 2×int32
+	smParamLong         = 6  // int64
+	smParamPlayerName   = 12 // string
+	smParamSystemString = 13 // int32 (SysString id)
 )
 
 // smParam holds a single SystemMessage parameter.
@@ -107,6 +180,13 @@ func (b *SystemMessageBuilder) AddString(v string) *SystemMessageBuilder {
 	return b
 }
 
+// AddSystemString adds a client system-string parameter (TYPE_SYSTEM_STRING), e.g.
+// the localized name of a party loot mode.
+func (b *SystemMessageBuilder) AddSystemString(id int32) *SystemMessageBuilder {
+	b.params = append(b.params, smParam{ptype: smParamSystemString, ival: id})
+	return b
+}
+
 // Build serializes the SystemMessage packet.
 func (b *SystemMessageBuilder) Build() []byte {
 	w := l2pkt.NewWriter()
@@ -120,7 +200,7 @@ func (b *SystemMessageBuilder) Build() []byte {
 			w.WriteS(p.sval)
 		case smParamLong:
 			w.WriteQ(p.lval)
-		case smParamInt, smParamNpcName, smParamItemName:
+		case smParamInt, smParamNpcName, smParamItemName, smParamSystemString:
 			w.WriteD(p.ival)
 		}
 	}
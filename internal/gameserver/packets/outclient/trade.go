@@ -0,0 +1,115 @@
+package outclient
+
+import "github.com/VerTox/l2go/pkg/l2pkt"
+
+// BuildSendTradeRequest builds SendTradeRequest (0x70) — the "X requests a trade"
+// dialog shown to the asked player. L2J HF writeImpl: C 0x70, D senderObjectId.
+func BuildSendTradeRequest(senderObjectID int32) []byte {
+	w := l2pkt.NewWriter()
+	w.WriteC(0x70)
+	w.WriteD(senderObjectID)
+	return w.Bytes()
+}
+
+// BuildTradeStart builds TradeStart (0x14) — opens the trade window with the
+// receiver's own tradeable items listed below it. L2J HF TradeStart.writeImpl:
+// C 0x14, D partnerObjectId, H count, then AbstractItemPacket.writeItem per item
+// (the InventoryUpdate item layout without the leading update type).
+func BuildTradeStart(partnerObjectID int32, items []InventoryItem) []byte {
+	w := l2pkt.NewWriter()
+	w.WriteC(0x14)
+	w.WriteD(partnerObjectID)
+	w.WriteH(uint16(len(items)))
+	for _, it := range items {
+		writeItemInfo(w, it)
+	}
+	return w.Bytes()
+}
+
+// writeItemInfo writes L2J HF AbstractItemPacket.writeItem: the InventoryUpdate
+// item layout without the leading update type. Shared by the trade and private
+// store windows.
+func writeItemInfo(w *l2pkt.Writer, it InventoryItem) {
+	w.WriteD(it.ObjectID)
+	w.WriteD(it.ItemID)
+	w.WriteD(it.LocationSlot)
+	w.WriteQ(it.Count)
+	w.WriteH(uint16(it.ItemType))
+	w.WriteH(uint16(it.CustomType1))
+	if it.Equipped {
+		w.WriteH(0x01)
+	} else {
+		w.WriteH(0x00)
+	}
+	w.WriteD(it.BodyPart)
+	w.WriteH(uint16(it.EnchantLevel))
+	w.WriteH(uint16(it.CustomType2))
+	w.WriteD(it.AugmentationID)
+	w.WriteD(it.Mana)
+	w.WriteD(it.TimeRemaining)
+	writeTradeItemAttributes(w, it)
+}
+
+// BuildTradeOwnAdd builds TradeOwnAdd (0x1A) — an item the receiver put in the
+// window (upper-left pane). BuildTradeOtherAdd is the partner's view (0x1B).
+// L2J HF writeImpl: C op, H 1, H 0, D objId, D itemId, Q count, H type2,
+// H customType1, D bodyPart, H enchant, H 0, H customType2, element attributes,
+// 3×H enchant options.
+func BuildTradeOwnAdd(item InventoryItem) []byte {
+	return buildTradeAdd(0x1A, item)
+}
+
+// BuildTradeOtherAdd builds TradeOtherAdd (0x1B) — an item the partner offered.
+func BuildTradeOtherAdd(item InventoryItem) []byte {
+	return buildTradeAdd(0x1B, item)
+}
+
+func buildTradeAdd(opcode uint8, it InventoryItem) []byte {
+	w := l2pkt.NewWriter()
+	w.WriteC(opcode)
+	w.WriteH(1)
+	w.WriteH(0)
+	w.WriteD(it.ObjectID)
+	w.WriteD(it.ItemID)
+	w.WriteQ(it.Count)
+	w.WriteH(uint16(it.ItemType))
+	w.WriteH(uint16(it.CustomType1))
+	w.WriteD(it.BodyPart)
+	w.WriteH(uint16(it.EnchantLevel))
+	w.WriteH(0x00)
+	w.WriteH(uint16(it.CustomType2))
+	writeTradeItemAttributes(w, it)
+	return w.Bytes()
+}
+
+// writeTradeItemAttributes writes the element attack/defense block and the three
+// enchant options shared by the trade item layouts.
+func writeTradeItemAttributes(w *l2pkt.Writer, it InventoryItem) {
+	w.WriteH(uint16(it.AttackElementType))
+	w.WriteH(uint16(it.AttackElementPower))
+	w.WriteH(uint16(it.DefenseElementFire))
+	w.WriteH(uint16(it.DefenseElementWater))
+	w.WriteH(uint16(it.DefenseElementWind))
+	w.WriteH(uint16(it.DefenseElementEarth))
+	w.WriteH(uint16(it.DefenseElementHoly))
+	w.WriteH(uint16(it.DefenseElementDark))
+	w.WriteH(uint16(it.EnchantOption1))
+	w.WriteH(uint16(it.EnchantOption2))
+	w.WriteH(uint16(it.EnchantOption3))
+}
+
+// BuildTradeOtherDone builds TradeOtherDone (0x82) — the partner pressed OK.
+func BuildTradeOtherDone() []byte {
+	w := l2pkt.NewWriter()
+	w.WriteC(0x82)
+	return w.Bytes()
+}
+
+// BuildTradeDone builds TradeDone (0x1C) — closes the trade window
+// (1 = exchanged, 0 = cancelled). L2J HF writeImpl: C 0x1C, D num.
+func BuildTradeDone(result int32) []byte {
+	w := l2pkt.NewWriter()
+	w.WriteC(0x1C)
+	w.WriteD(result)
+	return w.Bytes()
+}
@@ -0,0 +1,45 @@
+package outclient
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestTradeItemPackets(t *testing.T) {
+	item := InventoryItem{
+		ObjectID: 0x10000010, ItemID: 57, LocationSlot: -1, Count: 1500,
+		ItemType: 4, TimeRemaining: -9999,
+	}
+	sword := InventoryItem{
+		ObjectID: 0x10000011, ItemID: 2369, LocationSlot: -1, Count: 1,
+		BodyPart: 0x4000, EnchantLevel: 3, Mana: -1, TimeRemaining: -9999,
+		DefenseElementFire: 20,
+	}
+	checkGolden(t, "tradestart", BuildTradeStart(0x10000002, []InventoryItem{item, sword}))
+	checkGolden(t, "tradeownadd", BuildTradeOwnAdd(sword))
+	checkGolden(t, "tradeotheradd", BuildTradeOtherAdd(item))
+}
+
+// TestTradeFixedPackets checks the numeric-only trade packets byte-for-byte
+// against L2J HF writeImpl.
+func TestTradeFixedPackets(t *testing.T) {
+	cases := []struct {
+		name string
+		got  []byte
+		want []byte
+	}{
+		{"SendTradeRequest", BuildSendTradeRequest(0x10000001), []byte{0x70, 0x01, 0x00, 0x00, 0x10}},
+		{"TradeDone", BuildTradeDone(1), []byte{0x1C, 0x01, 0x00, 0x00, 0x00}},
+		{"TradeOtherDone", BuildTradeOtherDone(), []byte{0x82}},
+	}
+	for _, tc := range cases {
+		if !bytes.Equal(tc.got, tc.want) {
+			t.Errorf("%s = % x, want % x", tc.name, tc.got, tc.want)
+		}
+	}
+	// Own/other adds differ only in the opcode.
+	own, other := BuildTradeOwnAdd(InventoryItem{ObjectID: 1}), BuildTradeOtherAdd(InventoryItem{ObjectID: 1})
+	if own[0] != 0x1A || other[0] != 0x1B || !bytes.Equal(own[1:], other[1:]) {
+		t.Errorf("TradeOwnAdd/TradeOtherAdd layouts diverge: % x vs % x", own, other)
+	}
+}
@@ -0,0 +1,14 @@
+package outclient
+
+import "github.com/VerTox/l2go/pkg/l2pkt"
+
+// BuildTutorialShowQuestionMark builds TutorialShowQuestionMark (0xA7) — the
+// blinking question mark a quest shows beside the chat window. Clicking it
+// sends RequestTutorialQuestionMark with the same mark. L2J HF writeImpl:
+// C 0xA7, D mark.
+func BuildTutorialShowQuestionMark(mark int32) []byte {
+	w := l2pkt.NewWriter()
+	w.WriteC(0xA7)
+	w.WriteD(mark)
+	return w.Bytes()
+}
@@ -301,7 +301,7 @@ func BuildUserInfo(info UserInfo) []byte {
 
 	// Mount and store info
 	w.WriteC(0) // Mount type
-	w.WriteC(0) // Private store type
+	w.WriteC(byte(info.PrivateStoreType))
 	w.WriteC(0) // Can craft
 
 	// PK/PvP kills
@@ -0,0 +1,36 @@
+package outclient
+
+import "github.com/VerTox/l2go/pkg/l2pkt"
+
+// Warehouse window types written into WareHouseDepositList /
+// WareHouseWithdrawalList (L2J HF: PRIVATE = 1, CLAN = 4).
+const (
+	WarehouseTypePrivate int16 = 1
+	WarehouseTypeClan    int16 = 4
+)
+
+// BuildWareHouseDepositList builds WareHouseDepositList (0x41) — the inventory
+// items the player may put into the warehouse. L2J HF writeImpl: C 0x41,
+// H whType, Q adena, H n, n×(writeItem, D objectId).
+func BuildWareHouseDepositList(whType int16, adena int64, items []InventoryItem) []byte {
+	return buildWarehouseList(0x41, whType, adena, items)
+}
+
+// BuildWareHouseWithdrawalList builds WareHouseWithdrawalList (0x42) — what the
+// warehouse holds. Same layout as WareHouseDepositList.
+func BuildWareHouseWithdrawalList(whType int16, adena int64, items []InventoryItem) []byte {
+	return buildWarehouseList(0x42, whType, adena, items)
+}
+
+func buildWarehouseList(opcode uint8, whType int16, adena int64, items []InventoryItem) []byte {
+	w := l2pkt.NewWriter()
+	w.WriteC(opcode)
+	w.WriteH(uint16(whType))
+	w.WriteQ(adena)
+	w.WriteH(uint16(len(items)))
+	for _, it := range items {
+		writeItemInfo(w, it)
+		w.WriteD(it.ObjectID)
+	}
+	return w.Bytes()
+}
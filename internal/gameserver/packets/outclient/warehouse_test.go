@@ -0,0 +1,21 @@
+package outclient
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestWarehouseListPackets(t *testing.T) {
+	items := []InventoryItem{
+		{ObjectID: 0x10000011, ItemID: 2369, LocationSlot: -1, Count: 1, BodyPart: 0x4000, EnchantLevel: 3, Mana: -1, TimeRemaining: -9999},
+		{ObjectID: 0x10000012, ItemID: 57, LocationSlot: -1, Count: 1500, ItemType: 4, TimeRemaining: -9999},
+	}
+	checkGolden(t, "warehousedepositlist", BuildWareHouseDepositList(WarehouseTypePrivate, 1500, items))
+	checkGolden(t, "warehousewithdrawallist", BuildWareHouseWithdrawalList(WarehouseTypeClan, 1500, items[:1]))
+
+	// Empty window: C op, H type, Q adena, H 0.
+	want := []byte{0x42, 0x04, 0x00, 0x2A, 0, 0, 0, 0, 0, 0, 0, 0x00, 0x00}
+	if got := BuildWareHouseWithdrawalList(WarehouseTypeClan, 42, nil); !bytes.Equal(got, want) {
+		t.Fatalf("empty WareHouseWithdrawalList = % x, want % x", got, want)
+	}
+}
@@ -0,0 +1,115 @@
+package quests
+
+import (
+	"github.com/VerTox/l2go/internal/gameserver/gameloop"
+	"github.com/VerTox/l2go/internal/gameserver/models"
+)
+
+// Letters of Love (Talking Island): Darin sends a letter to Roxxy, whose
+// kerchief he trades for a receipt for Baulro's potion.
+const (
+	q00001Name = "Q00001_LettersOfLove"
+
+	q00001Darin  = 30048
+	q00001Roxxy  = 30006
+	q00001Baulro = 30033
+
+	q00001DarinsLetter   = 687
+	q00001RoxxysKerchief = 688
+	q00001DarinsReceipt  = 1079
+	q00001BaulrosPotion  = 1080
+	q00001Necklace       = 906 // Necklace of Knowledge
+
+	q00001MinLevel = 2
+)
+
+func init() {
+	gameloop.RegisterQuest(&gameloop.Quest{
+		ID:        1,
+		Name:      q00001Name,
+		Title:     "Letters of Love",
+		StartNpcs: []int32{q00001Darin},
+		TalkNpcs:  []int32{q00001Darin, q00001Roxxy, q00001Baulro},
+		Items:     []int32{q00001DarinsLetter, q00001RoxxysKerchief, q00001DarinsReceipt, q00001BaulrosPotion},
+		OnTalk:    q00001Talk,
+		OnEvent:   q00001Event,
+	})
+}
+
+func q00001Event(qs *gameloop.QuestState, npc *models.NpcInstance, event string) string {
+	switch event {
+	case "30048-03.htm", "30048-04.htm", "30048-05.htm":
+		return event
+	case "30048-06.htm":
+		if qs.IsStarted() || qs.IsCompleted() || qs.Player.Character.Level < q00001MinLevel {
+			return ""
+		}
+		qs.StartQuest()
+		qs.GiveItems(q00001DarinsLetter, 1)
+		return event
+	}
+	return ""
+}
+
+func q00001Talk(qs *gameloop.QuestState, npc *models.NpcInstance) string {
+	if qs.IsCompleted() {
+		return gameloop.AlreadyCompletedHtml
+	}
+	switch npc.TemplateID {
+	case q00001Darin:
+		if !qs.IsStarted() {
+			if qs.Player.Character.Level < q00001MinLevel {
+				return "30048-01.htm"
+			}
+			return "30048-02.htm"
+		}
+		switch qs.Cond() {
+		case 1:
+			return "30048-07.htm"
+		case 2:
+			qs.TakeItems(q00001RoxxysKerchief, 0)
+			qs.GiveItems(q00001DarinsReceipt, 1)
+			qs.SetCond(3)
+			qs.PlaySound(gameloop.SoundMiddle)
+			return "30048-08.htm"
+		case 3:
+			return "30048-09.htm"
+		case 4:
+			qs.GiveItems(q00001Necklace, 1)
+			qs.RewardExpSp(5672, 446)
+			qs.ExitQuest()
+			return "30048-10.htm"
+		}
+	case q00001Roxxy:
+		if !qs.IsStarted() {
+			return ""
+		}
+		switch qs.Cond() {
+		case 1:
+			qs.TakeItems(q00001DarinsLetter, 0)
+			qs.GiveItems(q00001RoxxysKerchief, 1)
+			qs.SetCond(2)
+			qs.PlaySound(gameloop.SoundMiddle)
+			return "30006-01.htm"
+		case 2, 3:
+			return "30006-02.htm"
+		case 4:
+			return "30006-03.htm"
+		}
+	case q00001Baulro:
+		if !qs.IsStarted() {
+			return ""
+		}
+		switch qs.Cond() {
+		case 3:
+			qs.TakeItems(q00001DarinsReceipt, 0)
+			qs.GiveItems(q00001BaulrosPotion, 1)
+			qs.SetCond(4)
+			qs.PlaySound(gameloop.SoundMiddle)
+			return "30033-01.htm"
+		case 4:
+			return "30033-02.htm"
+		}
+	}
+	return ""
+}
@@ -0,0 +1,102 @@
+package quests
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/VerTox/l2go/internal/gameserver/gameloop"
+	"github.com/VerTox/l2go/internal/gameserver/models"
+	"github.com/VerTox/l2go/internal/gameserver/registry"
+)
+
+// runQuestLoop starts a game loop with player 7 (level 2) standing by the
+// given NPCs, one object id per template id, and returns its command channel
+// and quest sink.
+func runQuestLoop(t *testing.T, npcs map[int32]int32) (chan<- gameloop.Command, <-chan gameloop.QuestJob) {
+	t.Helper()
+	world := registry.NewWorldRegistry()
+	char := &models.Character{ID: 7, AccountName: "acc", Name: "Tester", Level: 2, MaxHP: 100, CurrentHP: 100}
+	if err := world.AddPlayer(context.Background(), char); err != nil {
+		t.Fatal(err)
+	}
+	for objID, npcID := range npcs {
+		world.AddNPC(&models.NpcInstance{
+			ObjectID:   objID,
+			TemplateID: npcID,
+			CurrentHP:  100,
+			Template:   &models.NpcTemplate{ID: npcID, Type: "L2Npc"},
+		})
+	}
+	gl := gameloop.New(world, registry.NewConnectionRegistry(), 1, 1)
+	sink := make(chan gameloop.QuestJob, 8)
+	gl.SetQuestSink(sink)
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		_ = gl.Run(ctx)
+	}()
+	t.Cleanup(func() {
+		cancel()
+		<-done
+	})
+	return gl.CommandChannel(), sink
+}
+
+// nextJob waits for the quest step a command caused.
+func nextJob(t *testing.T, sink <-chan gameloop.QuestJob) gameloop.QuestJob {
+	t.Helper()
+	select {
+	case job := <-sink:
+		return job
+	case <-time.After(time.Second):
+		t.Fatal("no quest step")
+		return gameloop.QuestJob{}
+	}
+}
+
+func TestLettersOfLove_Walkthrough(t *testing.T) {
+	cmds, sink := runQuestLoop(t, map[int32]int32{1: q00001Darin, 2: q00001Roxxy, 3: q00001Baulro})
+	bypass := func(cmd string) { cmds <- gameloop.CmdBypass{CharID: 7, Command: cmd} }
+
+	bypass("npc_1_Quest " + q00001Name + " 30048-06.htm")
+	job := nextJob(t, sink)
+	if job.Vars["cond"] != "1" || len(job.Give) != 1 || job.Give[0].ItemID != q00001DarinsLetter {
+		t.Fatalf("accept = %+v", job)
+	}
+
+	bypass("npc_2_Quest")
+	job = nextJob(t, sink)
+	if job.Vars["cond"] != "2" || job.Take[0].ItemID != q00001DarinsLetter || job.Give[0].ItemID != q00001RoxxysKerchief {
+		t.Fatalf("Roxxy = %+v", job)
+	}
+
+	// Baulro has nothing for the player until Darin wrote the receipt.
+	bypass("npc_3_Quest")
+	bypass("npc_1_Quest")
+	job = nextJob(t, sink)
+	if job.Vars["cond"] != "3" || job.Give[0].ItemID != q00001DarinsReceipt {
+		t.Fatalf("Darin = %+v", job)
+	}
+
+	bypass("npc_3_Quest")
+	job = nextJob(t, sink)
+	if job.Vars["cond"] != "4" || job.Give[0].ItemID != q00001BaulrosPotion {
+		t.Fatalf("Baulro = %+v", job)
+	}
+
+	bypass("npc_1_Quest")
+	job = nextJob(t, sink)
+	if job.Vars["state"] != "completed" || len(job.Take) != 4 || job.Give[0].ItemID != q00001Necklace {
+		t.Fatalf("reward = %+v", job)
+	}
+
+	// A one-time quest cannot be taken again.
+	bypass("npc_1_Quest " + q00001Name + " 30048-06.htm")
+	select {
+	case job := <-sink:
+		t.Fatalf("quest restarted: %+v", job)
+	case <-time.After(50 * time.Millisecond):
+	}
+}
@@ -0,0 +1,98 @@
+package quests
+
+import (
+	"math/rand/v2"
+
+	"github.com/VerTox/l2go/internal/gameserver/gameloop"
+	"github.com/VerTox/l2go/internal/gameserver/models"
+)
+
+// Bring Wolf Pelts (Talking Island): Lector pays in armor for forty wolf
+// pelts. Repeatable.
+const (
+	q00258Name = "Q00258_BringWolfPelts"
+
+	q00258Lector    = 30001
+	q00258Wolf      = 20120
+	q00258ElderWolf = 20442
+
+	q00258WolfPelt = 702
+	q00258Needed   = 40
+
+	q00258MinLevel = 3
+)
+
+// q00258Rewards are Lector's payments: one is picked per quest, weighted
+// by the roll of a 16-sided die (L2J Q00258).
+var q00258Rewards = []struct {
+	itemID int32
+	upTo   int // rolls below upTo pay this item
+}{
+	{itemID: 390, upTo: 1},   // Cotton Shirt
+	{itemID: 29, upTo: 6},    // Leather Pants
+	{itemID: 22, upTo: 9},    // Leather Shirt
+	{itemID: 1119, upTo: 13}, // Short Leather Gloves
+	{itemID: 426, upTo: 16},  // Tunic
+}
+
+func init() {
+	gameloop.RegisterQuest(&gameloop.Quest{
+		ID:         258,
+		Name:       q00258Name,
+		Title:      "Bring Wolf Pelts",
+		StartNpcs:  []int32{q00258Lector},
+		TalkNpcs:   []int32{q00258Lector},
+		KillNpcs:   []int32{q00258Wolf, q00258ElderWolf},
+		Items:      []int32{q00258WolfPelt},
+		Repeatable: true,
+		OnTalk:     q00258Talk,
+		OnEvent:    q00258Event,
+		OnKill:     q00258Kill,
+	})
+}
+
+func q00258Event(qs *gameloop.QuestState, npc *models.NpcInstance, event string) string {
+	if event != "30001-03.htm" || qs.IsStarted() || qs.Player.Character.Level < q00258MinLevel {
+		return ""
+	}
+	qs.StartQuest()
+	return event
+}
+
+func q00258Talk(qs *gameloop.QuestState, npc *models.NpcInstance) string {
+	if !qs.IsStarted() {
+		if qs.Player.Character.Level < q00258MinLevel {
+			return "30001-01.htm"
+		}
+		return "30001-02.htm"
+	}
+	if qs.GetInt("pelts") < q00258Needed {
+		return "30001-04.htm"
+	}
+	roll := rand.IntN(16)
+	for _, r := range q00258Rewards {
+		if roll < r.upTo {
+			qs.GiveItems(r.itemID, 1)
+			break
+		}
+	}
+	qs.ExitQuest()
+	return "30001-05.htm"
+}
+
+func q00258Kill(qs *gameloop.QuestState, npc *models.NpcInstance) string {
+	pelts := qs.GetInt("pelts")
+	if qs.Cond() != 1 || pelts >= q00258Needed {
+		return ""
+	}
+	pelts++
+	qs.SetInt("pelts", pelts)
+	qs.GiveItems(q00258WolfPelt, 1)
+	if pelts == q00258Needed {
+		qs.SetCond(2)
+		qs.PlaySound(gameloop.SoundMiddle)
+	} else {
+		qs.PlaySound(gameloop.SoundItemGet)
+	}
+	return ""
+}
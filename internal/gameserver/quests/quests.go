@@ -0,0 +1,5 @@
+// Package quests holds the quest scripts. Each file registers one quest with
+// the game loop's quest engine from init(); the gameserver imports the
+// package for that side effect. A script's html pages live in the datapack
+// under html/quests/<quest name>/.
+package quests
@@ -0,0 +1,308 @@
+package registry
+
+import (
+	"encoding/xml"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/rs/zerolog/log"
+)
+
+// BuyListItem is one line of a merchant's buy list as the shop window shows it
+// right now. Limited lines (L2J count attribute) sell out and come back after
+// their restock delay; Count is the stock left on them.
+type BuyListItem struct {
+	ItemID  int32
+	Price   int64
+	Limited bool
+	Count   int64
+}
+
+// BuyListLine is a requested quantity of one item of a buy list.
+type BuyListLine struct {
+	ItemID int32
+	Count  int64
+}
+
+// buyListProduct is a loaded product with its live stock.
+type buyListProduct struct {
+	itemID       int32
+	price        int64
+	maxCount     int64 // 0: unlimited
+	restockDelay time.Duration
+	count        int64
+	restockAt    time.Time // zero while the stock is full
+}
+
+// buyList is one loaded list: the NPCs allowed to open it and its products in
+// file order.
+type buyList struct {
+	id       int32
+	npcs     map[int32]bool
+	products []*buyListProduct
+	byItem   map[int32]*buyListProduct
+}
+
+// BuyListRegistry holds merchant buy lists parsed from data/buylists/*.xml
+// (L2J BuyListData): the list id is the file name. Limited stock is tracked
+// here, in memory, and resets on restart like retail. Safe for concurrent use.
+type BuyListRegistry struct {
+	mu     sync.Mutex
+	lists  map[int32]*buyList
+	byNpc  map[int32][]int32 // npc template id -> list ids, ascending
+	loaded bool
+
+	// now is the injectable clock for restocks; templateOf resolves the
+	// reference price of lines without a price attribute.
+	now        func() time.Time
+	templateOf func(itemID int32) *ItemTemplate
+}
+
+// NewBuyListRegistry creates an empty registry.
+func NewBuyListRegistry() *BuyListRegistry {
+	return &BuyListRegistry{
+		lists:      make(map[int32]*buyList),
+		byNpc:      make(map[int32][]int32),
+		now:        time.Now,
+		templateOf: GetItemTemplateRegistry().Get,
+	}
+}
+
+// Global buy list registry instance.
+var buyLists = NewBuyListRegistry()
+
+// GetBuyListRegistry returns the global buy list registry.
+func GetBuyListRegistry() *BuyListRegistry { return buyLists }
+
+// XML schema for data/buylists/*.xml.
+type xmlBuyList struct {
+	XMLName xml.Name         `xml:"list"`
+	Npcs    []int32          `xml:"npcs>npc"`
+	Items   []xmlBuyListItem `xml:"item"`
+}
+
+type xmlBuyListItem struct {
+	ID           int32  `xml:"id,attr"`
+	Price        string `xml:"price,attr"`
+	Count        int64  `xml:"count,attr"`
+	RestockDelay int64  `xml:"restock_delay,attr"` // minutes
+}
+
+// IsLoaded reports whether buy lists have been loaded.
+func (r *BuyListRegistry) IsLoaded() bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.loaded
+}
+
+// Count returns the number of loaded buy lists.
+func (r *BuyListRegistry) Count() int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return len(r.lists)
+}
+
+// LoadFromDirectory parses every <listId>.xml buy list in dir, replacing any
+// previous data. A file that fails to parse is skipped with a warning.
+func (r *BuyListRegistry) LoadFromDirectory(dir string) error {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return fmt.Errorf("read buylists dir: %w", err)
+	}
+
+	lists := make(map[int32]*buyList)
+	for _, e := range entries {
+		if e.IsDir() || filepath.Ext(e.Name()) != ".xml" {
+			continue
+		}
+		id, err := strconv.ParseInt(strings.TrimSuffix(e.Name(), ".xml"), 10, 32)
+		if err != nil {
+			log.Warn().Str("file", e.Name()).Msg("buylists: file name is not a list id")
+			continue
+		}
+		data, err := os.ReadFile(filepath.Join(dir, e.Name()))
+		if err != nil {
+			log.Warn().Err(err).Str("file", e.Name()).Msg("buylists: read failed")
+			continue
+		}
+		list, err := r.parse(int32(id), data)
+		if err != nil {
+			log.Warn().Err(err).Str("file", e.Name()).Msg("buylists: parse failed")
+			continue
+		}
+		lists[list.id] = list
+	}
+
+	byNpc := make(map[int32][]int32)
+	for id, list := range lists {
+		for npc := range list.npcs {
+			byNpc[npc] = append(byNpc[npc], id)
+		}
+	}
+	for _, ids := range byNpc {
+		sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+	}
+
+	r.mu.Lock()
+	r.lists, r.byNpc, r.loaded = lists, byNpc, true
+	r.mu.Unlock()
+	log.Info().Int("lists", len(lists)).Msg("Loaded buy lists")
+	return nil
+}
+
+// parse builds one list. A line without a price sells at the item's reference
+// price; a line whose price cannot be resolved is dropped.
+func (r *BuyListRegistry) parse(id int32, data []byte) (*buyList, error) {
+	var doc xmlBuyList
+	if err := xml.Unmarshal(data, &doc); err != nil {
+		return nil, err
+	}
+	list := &buyList{
+		id:     id,
+		npcs:   make(map[int32]bool, len(doc.Npcs)),
+		byItem: make(map[int32]*buyListProduct, len(doc.Items)),
+	}
+	for _, npc := range doc.Npcs {
+		list.npcs[npc] = true
+	}
+	for _, it := range doc.Items {
+		price := int64(-1)
+		if it.Price != "" {
+			p, err := strconv.ParseInt(it.Price, 10, 64)
+			if err != nil {
+				return nil, fmt.Errorf("item %d: bad price %q", it.ID, it.Price)
+			}
+			price = p
+		} else if tmpl := r.templateOf(it.ID); tmpl != nil {
+			price = tmpl.Price
+		}
+		if price < 0 {
+			log.Warn().Int32("list", id).Int32("item_id", it.ID).Msg("buylists: unknown item without a price, skipped")
+			continue
+		}
+		if list.byItem[it.ID] != nil {
+			continue
+		}
+		p := &buyListProduct{itemID: it.ID, price: price}
+		if it.Count > 0 {
+			p.maxCount = it.Count
+			p.count = it.Count
+			p.restockDelay = time.Duration(it.RestockDelay) * time.Minute
+		}
+		list.products = append(list.products, p)
+		list.byItem[it.ID] = p
+	}
+	return list, nil
+}
+
+// ListsForNpc returns the ids of the buy lists an NPC template may open,
+// ascending.
+func (r *BuyListRegistry) ListsForNpc(npcID int32) []int32 {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]int32(nil), r.byNpc[npcID]...)
+}
+
+// AllowsNpc reports whether list listID may be opened at NPC template npcID.
+func (r *BuyListRegistry) AllowsNpc(listID, npcID int32) bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	list := r.lists[listID]
+	return list != nil && list.npcs[npcID]
+}
+
+// Items returns list listID as the shop window shows it: sold-out limited
+// lines are left out.
+func (r *BuyListRegistry) Items(listID int32) ([]BuyListItem, bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	list := r.lists[listID]
+	if list == nil {
+		return nil, false
+	}
+	now := r.now()
+	out := make([]BuyListItem, 0, len(list.products))
+	for _, p := range list.products {
+		r.restock(p, now)
+		if p.maxCount > 0 && p.count <= 0 {
+			continue
+		}
+		out = append(out, BuyListItem{ItemID: p.itemID, Price: p.price, Limited: p.maxCount > 0, Count: p.count})
+	}
+	return out, true
+}
+
+// Reserve prices the requested lines of list listID and takes them out of
+// limited stock, all or nothing. It fails when the list does not sell one of
+// the items or has too few left; the reservation is undone with Release when
+// the purchase does not go through.
+func (r *BuyListRegistry) Reserve(listID int32, lines []BuyListLine) ([]BuyListItem, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	list := r.lists[listID]
+	if list == nil {
+		return nil, fmt.Errorf("unknown buy list %d", listID)
+	}
+	now := r.now()
+	want := make(map[int32]int64, len(lines))
+	out := make([]BuyListItem, len(lines))
+	for i, l := range lines {
+		p := list.byItem[l.ItemID]
+		if p == nil {
+			return nil, fmt.Errorf("buy list %d does not sell item %d", listID, l.ItemID)
+		}
+		r.restock(p, now)
+		want[l.ItemID] += l.Count
+		if p.maxCount > 0 && want[l.ItemID] > p.count {
+			return nil, fmt.Errorf("buy list %d has %d of item %d left", listID, p.count, l.ItemID)
+		}
+		out[i] = BuyListItem{ItemID: p.itemID, Price: p.price, Limited: p.maxCount > 0, Count: l.Count}
+	}
+	for itemID, n := range want {
+		p := list.byItem[itemID]
+		if p.maxCount == 0 {
+			continue
+		}
+		if p.count == p.maxCount {
+			p.restockAt = now.Add(p.restockDelay)
+		}
+		p.count -= n
+	}
+	return out, nil
+}
+
+// Release puts reserved lines back into limited stock.
+func (r *BuyListRegistry) Release(listID int32, lines []BuyListLine) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	list := r.lists[listID]
+	if list == nil {
+		return
+	}
+	for _, l := range lines {
+		p := list.byItem[l.ItemID]
+		if p == nil || p.maxCount == 0 {
+			continue
+		}
+		p.count = min(p.count+l.Count, p.maxCount)
+		if p.count == p.maxCount {
+			p.restockAt = time.Time{}
+		}
+	}
+}
+
+// restock refills a limited product whose restock delay has run out since it
+// was first bought from (L2J Product.restartRestockTask).
+func (r *BuyListRegistry) restock(p *buyListProduct, now time.Time) {
+	if p.maxCount == 0 || p.restockAt.IsZero() || now.Before(p.restockAt) {
+		return
+	}
+	p.count = p.maxCount
+	p.restockAt = time.Time{}
+}
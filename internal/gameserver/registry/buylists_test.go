@@ -0,0 +1,106 @@
+package registry
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+const testBuyList = `<?xml version="1.0" encoding="UTF-8"?>
+<list>
+	<npcs>
+		<npc>30301</npc>
+		<npc>30315</npc>
+	</npcs>
+	<item id="1060" />
+	<item id="1061" price="300" count="5" restock_delay="60" />
+	<item id="9999" />
+</list>`
+
+func newTestBuyLists(t *testing.T) (*BuyListRegistry, *time.Time) {
+	t.Helper()
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "3030101.xml"), []byte(testBuyList), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "notes.xml"), []byte("<list/>"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	r := NewBuyListRegistry()
+	r.now = func() time.Time { return now }
+	r.templateOf = func(id int32) *ItemTemplate {
+		if id == 1060 {
+			return &ItemTemplate{ID: id, Price: 40}
+		}
+		return nil
+	}
+	if err := r.LoadFromDirectory(dir); err != nil {
+		t.Fatal(err)
+	}
+	return r, &now
+}
+
+func TestBuyLists_Load(t *testing.T) {
+	r, _ := newTestBuyLists(t)
+	if !r.IsLoaded() || r.Count() != 1 {
+		t.Fatalf("loaded = %v, count = %d", r.IsLoaded(), r.Count())
+	}
+	if got := r.ListsForNpc(30315); len(got) != 1 || got[0] != 3030101 {
+		t.Fatalf("lists for 30315 = %v", got)
+	}
+	if !r.AllowsNpc(3030101, 30301) || r.AllowsNpc(3030101, 30005) || r.AllowsNpc(1, 30301) {
+		t.Fatal("list must open only at its own NPCs")
+	}
+
+	// No price: the reference price; unknown item without a price: dropped.
+	items, ok := r.Items(3030101)
+	want := []BuyListItem{{ItemID: 1060, Price: 40}, {ItemID: 1061, Price: 300, Limited: true, Count: 5}}
+	if !ok || len(items) != len(want) || items[0] != want[0] || items[1] != want[1] {
+		t.Fatalf("items = %+v, want %+v", items, want)
+	}
+}
+
+func TestBuyLists_LimitedStockAndRestock(t *testing.T) {
+	r, now := newTestBuyLists(t)
+
+	// All or nothing: the second line asks for more than is left.
+	if _, err := r.Reserve(3030101, []BuyListLine{{ItemID: 1060, Count: 100}, {ItemID: 1061, Count: 6}}); err == nil {
+		t.Fatal("reserving past the stock must fail")
+	}
+	if _, err := r.Reserve(3030101, []BuyListLine{{ItemID: 2, Count: 1}}); err == nil {
+		t.Fatal("reserving an item the list does not sell must fail")
+	}
+
+	priced, err := r.Reserve(3030101, []BuyListLine{{ItemID: 1061, Count: 3}, {ItemID: 1060, Count: 100}})
+	if err != nil {
+		t.Fatalf("Reserve: %v", err)
+	}
+	if priced[0] != (BuyListItem{ItemID: 1061, Price: 300, Limited: true, Count: 3}) || priced[1].Price != 40 {
+		t.Fatalf("priced = %+v", priced)
+	}
+	if _, err := r.Reserve(3030101, []BuyListLine{{ItemID: 1061, Count: 2}}); err != nil {
+		t.Fatalf("Reserve the rest: %v", err)
+	}
+	// Sold out: the line leaves the window.
+	if items, _ := r.Items(3030101); len(items) != 1 {
+		t.Fatalf("items = %+v, want the sold-out line hidden", items)
+	}
+
+	// A failed purchase hands its stock back.
+	r.Release(3030101, []BuyListLine{{ItemID: 1061, Count: 2}})
+	if items, _ := r.Items(3030101); len(items) != 2 || items[1].Count != 2 {
+		t.Fatalf("items = %+v, want 2 back in stock", items)
+	}
+
+	// The restock delay counts from the first sale out of a full stock.
+	*now = now.Add(59 * time.Minute)
+	if items, _ := r.Items(3030101); items[1].Count != 2 {
+		t.Fatalf("restocked too early: %+v", items)
+	}
+	*now = now.Add(time.Minute)
+	if items, _ := r.Items(3030101); items[1].Count != 5 {
+		t.Fatalf("not restocked: %+v", items)
+	}
+}
@@ -49,7 +49,10 @@ func (cr *ConnectionRegistry) UnregisterIf(accountName string, conn *client.Clie
 	}
 }
 
-// GetConnection retrieves a client connection by account name
+// GetConnection retrieves a client connection by account name. Returns nil for
+// an account with no client attached — including players that stay in the world
+// without one (offline store keepers), so callers must not assume a player in
+// the WorldRegistry has a connection.
 func (cr *ConnectionRegistry) GetConnection(accountName string) *client.ClientConn {
 	cr.mutex.RLock()
 	defer cr.mutex.RUnlock()
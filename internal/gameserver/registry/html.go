@@ -0,0 +1,108 @@
+package registry
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/rs/zerolog/log"
+)
+
+// HtmlRegistry holds the dialogue pages parsed from data/html (L2J HtmCache),
+// keyed by their slash-separated path below that directory, e.g.
+// "merchant/30301-1.htm". Pages are read-only once loaded.
+type HtmlRegistry struct {
+	mu     sync.RWMutex
+	pages  map[string]string
+	loaded bool
+}
+
+// NewHtmlRegistry creates an empty registry.
+func NewHtmlRegistry() *HtmlRegistry {
+	return &HtmlRegistry{pages: make(map[string]string)}
+}
+
+// Global HTML registry instance.
+var htmlPages = NewHtmlRegistry()
+
+// GetHtmlRegistry returns the global HTML registry.
+func GetHtmlRegistry() *HtmlRegistry { return htmlPages }
+
+// IsLoaded reports whether the pages have been loaded.
+func (r *HtmlRegistry) IsLoaded() bool {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.loaded
+}
+
+// Count returns the number of loaded pages.
+func (r *HtmlRegistry) Count() int {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return len(r.pages)
+}
+
+// Get returns the page at path, relative to the html directory.
+func (r *HtmlRegistry) Get(name string) (string, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	page, ok := r.pages[name]
+	return page, ok
+}
+
+// IsHtmlPath reports whether name may name a page: a relative .htm or .html
+// path that stays inside the html directory. Client links are checked with it
+// before any lookup (L2J RequestLinkHtml).
+func IsHtmlPath(name string) bool {
+	if name == "" || strings.Contains(name, "..") || strings.Contains(name, "\\") || path.IsAbs(name) {
+		return false
+	}
+	ext := path.Ext(name)
+	return ext == ".htm" || ext == ".html"
+}
+
+// LoadFromDirectory reads every .htm and .html file below dir, replacing any
+// previous pages. A file that cannot be read is skipped with a warning.
+func (r *HtmlRegistry) LoadFromDirectory(dir string) error {
+	if _, err := os.Stat(dir); err != nil {
+		return fmt.Errorf("read html dir: %w", err)
+	}
+
+	pages := make(map[string]string)
+	err := filepath.WalkDir(dir, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			return nil
+		}
+		rel, err := filepath.Rel(dir, p)
+		if err != nil {
+			return err
+		}
+		name := filepath.ToSlash(rel)
+		if !IsHtmlPath(name) {
+			return nil
+		}
+		data, err := os.ReadFile(p)
+		if err != nil {
+			log.Warn().Err(err).Str("file", name).Msg("html: read failed")
+			return nil
+		}
+		pages[name] = string(data)
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("walk html dir: %w", err)
+	}
+
+	r.mu.Lock()
+	r.pages, r.loaded = pages, true
+	r.mu.Unlock()
+	log.Info().Int("pages", len(pages)).Msg("Loaded html pages")
+	return nil
+}
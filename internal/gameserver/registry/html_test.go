@@ -0,0 +1,59 @@
+package registry
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestHtml_LoadFromDirectory(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"merchant/30301.htm":   "<html>grocer</html>",
+		"default/30005-1.html": "<html>page 1</html>",
+		"readme.txt":           "not a page",
+	}
+	for name, data := range files {
+		p := filepath.Join(dir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(p), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(p, []byte(data), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	r := NewHtmlRegistry()
+	if err := r.LoadFromDirectory(dir); err != nil {
+		t.Fatal(err)
+	}
+	if !r.IsLoaded() || r.Count() != 2 {
+		t.Fatalf("loaded = %v, count = %d", r.IsLoaded(), r.Count())
+	}
+	if page, ok := r.Get("merchant/30301.htm"); !ok || page != "<html>grocer</html>" {
+		t.Fatalf("merchant page = %q, %v", page, ok)
+	}
+	if _, ok := r.Get("readme.txt"); ok {
+		t.Fatal("only .htm and .html files are pages")
+	}
+	if err := NewHtmlRegistry().LoadFromDirectory(filepath.Join(dir, "missing")); err == nil {
+		t.Fatal("a missing directory must fail")
+	}
+}
+
+func TestHtml_IsHtmlPath(t *testing.T) {
+	for name, want := range map[string]bool{
+		"merchant/30301-1.htm": true,
+		"default/30005.html":   true,
+		"../config/server.htm": false,
+		"merchant/../../x.htm": false,
+		"/etc/passwd.htm":      false,
+		"merchant\\30301.htm":  false,
+		"merchant/30301.xml":   false,
+		"":                     false,
+	} {
+		if got := IsHtmlPath(name); got != want {
+			t.Errorf("IsHtmlPath(%q) = %v, want %v", name, got, want)
+		}
+	}
+}
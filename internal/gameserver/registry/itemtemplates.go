@@ -149,6 +149,7 @@ type ItemTemplate struct {
 	Stackable   bool `json:"stackable"`
 	Tradeable   bool `json:"tradeable"`
 	Droppable   bool `json:"droppable"`
+	Destroyable bool `json:"destroyable"`
 	Sellable    bool `json:"sellable"`
 	Depositable bool `json:"depositable"`
 	Enchantable bool `json:"enchantable"`
@@ -170,6 +171,7 @@ type ItemTemplate struct {
 	ReuseDelay       int         `json:"reuse_delay"`        // Per-item reuse delay
 	SharedReuseGroup int         `json:"shared_reuse_group"` // Shared reuse group id
 	ImmediateEffect  bool        `json:"immediate_effect"`   // Consumed/applied immediately
+	ExImmediateEffect bool       `json:"ex_immediate_effect"` // Used on pickup (herbs)
 	IsOlyRestricted  bool        `json:"is_oly_restricted"`  // Restricted in Olympiad
 	QuestItem        bool        `json:"quest_item"`         // is_questitem flag (drives Type2=QUEST)
 
@@ -323,6 +325,7 @@ func (r *ItemTemplateRegistry) convertXMLItem(xi xmlItem) *ItemTemplate {
 		Stackable:   false,
 		Tradeable:   true,
 		Droppable:   true,
+		Destroyable: true,
 		Sellable:    true,
 		Depositable: true,
 		Enchantable: false,
@@ -394,6 +397,8 @@ func (r *ItemTemplateRegistry) applySetAttribute(t *ItemTemplate, name, val stri
 		t.Tradeable = parseBool(val)
 	case "is_droppable":
 		t.Droppable = parseBool(val)
+	case "is_destroyable":
+		t.Destroyable = parseBool(val)
 	case "is_sellable":
 		t.Sellable = parseBool(val)
 	case "is_depositable":
@@ -416,6 +421,8 @@ func (r *ItemTemplateRegistry) applySetAttribute(t *ItemTemplate, name, val stri
 		t.SharedReuseGroup = parseInt(val)
 	case "immediate_effect":
 		t.ImmediateEffect = parseBool(val)
+	case "ex_immediate_effect":
+		t.ExImmediateEffect = parseBool(val)
 	case "is_oly_restricted":
 		t.IsOlyRestricted = parseBool(val)
 	case "is_questitem":
@@ -623,6 +630,72 @@ func bodyPartToCode(bodyPart string) int32 {
 	}
 }
 
+// CrystalItemID returns the crystal a grade's items crystallize into (L2J
+// CrystalType.getCrystalId); 0 for no-grade.
+func (g ItemGrade) CrystalItemID() int32 {
+	switch g {
+	case GradeD:
+		return 1458
+	case GradeC:
+		return 1459
+	case GradeB:
+		return 1460
+	case GradeA:
+		return 1461
+	case GradeS, GradeS80, GradeS84:
+		return 1462
+	default:
+		return 0
+	}
+}
+
+// crystalEnchantBonus returns how many crystals each enchant level adds to
+// armor and jewelry, and to weapons, of a grade (L2J CrystalType).
+func (g ItemGrade) crystalEnchantBonus() (armor, weapon int) {
+	switch g {
+	case GradeD:
+		return 11, 90
+	case GradeC:
+		return 6, 45
+	case GradeB:
+		return 11, 67
+	case GradeA:
+		return 20, 145
+	case GradeS, GradeS80, GradeS84:
+		return 25, 250
+	default:
+		return 0, 0
+	}
+}
+
+// Crystallizable reports whether the item breaks into crystals.
+func (t *ItemTemplate) Crystallizable() bool {
+	return t.CrystalType != GradeNone && t.CrystalCount > 0
+}
+
+// CrystalCountAt returns how many crystals the item gives at an enchant level
+// (L2J L2Item.getCrystalCount): every level adds its grade's bonus, and levels
+// above +3 count for more.
+func (t *ItemTemplate) CrystalCountAt(enchant int) int {
+	armor, weapon := t.CrystalType.crystalEnchantBonus()
+	switch {
+	case enchant <= 0:
+		return t.CrystalCount
+	case t.Type2 == ItemType2Armor || t.Type2 == ItemType2Accessory:
+		if enchant > 3 {
+			return t.CrystalCount + armor*(3*enchant-6)
+		}
+		return t.CrystalCount + armor*enchant
+	case t.Type2 == ItemType2Weapon:
+		if enchant > 3 {
+			return t.CrystalCount + weapon*(2*enchant-3)
+		}
+		return t.CrystalCount + weapon*enchant
+	default:
+		return t.CrystalCount
+	}
+}
+
 // parseCrystalType parses crystal type string to ItemGrade
 func parseCrystalType(val string) ItemGrade {
 	switch strings.ToUpper(val) {
@@ -82,6 +82,23 @@ func TestConvertXMLItem_Defaults(t *testing.T) {
 	if tmpl.ImmediateEffect || tmpl.IsOlyRestricted || tmpl.QuestItem {
 		t.Error("bool defaults should be false")
 	}
+	if !tmpl.Droppable || !tmpl.Destroyable {
+		t.Error("items are droppable and destroyable unless their XML says otherwise")
+	}
+}
+
+func TestConvertXMLItem_DropDestroyFlags(t *testing.T) {
+	const doc = `<list>
+		<item id="99998" type="EtcItem" name="Bound Item">
+			<set name="is_droppable" val="false" />
+			<set name="is_destroyable" val="false" />
+		</item>
+	</list>`
+
+	tmpl := parseSingleItem(t, doc)
+	if tmpl.Droppable || tmpl.Destroyable {
+		t.Errorf("Droppable/Destroyable = %v/%v, want false/false", tmpl.Droppable, tmpl.Destroyable)
+	}
 }
 
 func TestParseItemSkills(t *testing.T) {
@@ -215,3 +232,29 @@ func TestComputeType2_QuestItem(t *testing.T) {
 		t.Errorf("etc item Type2 = %d, want %d (OTHER)", tmpl.Type2, ItemType2Other)
 	}
 }
+
+func TestCrystalCountAt(t *testing.T) {
+	weapon := &ItemTemplate{Type2: ItemType2Weapon, CrystalType: GradeD, CrystalCount: 100}
+	armor := &ItemTemplate{Type2: ItemType2Armor, CrystalType: GradeC, CrystalCount: 50}
+	other := &ItemTemplate{Type2: ItemType2Other, CrystalType: GradeA, CrystalCount: 10}
+	tests := []struct {
+		tmpl    *ItemTemplate
+		enchant int
+		want    int
+	}{
+		{weapon, 0, 100},
+		{weapon, 3, 100 + 90*3},
+		{weapon, 5, 100 + 90*7},
+		{armor, 2, 50 + 6*2},
+		{armor, 4, 50 + 6*6},
+		{other, 6, 10},
+	}
+	for _, tt := range tests {
+		if got := tt.tmpl.CrystalCountAt(tt.enchant); got != tt.want {
+			t.Errorf("type2 %d +%d: CrystalCountAt = %d, want %d", tt.tmpl.Type2, tt.enchant, got, tt.want)
+		}
+	}
+	if weapon.CrystalType.CrystalItemID() != 1458 || GradeS84.CrystalItemID() != 1462 || GradeNone.CrystalItemID() != 0 {
+		t.Error("CrystalItemID maps grades to crystals D..S")
+	}
+}
@@ -0,0 +1,270 @@
+package registry
+
+import (
+	"encoding/xml"
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/rs/zerolog/log"
+
+	"github.com/VerTox/l2go/internal/gameserver/models"
+)
+
+// MultisellIngredient is one item a multisell entry takes or gives, per unit
+// of the exchange. TaxIngredient marks an adena line that is only the base of
+// the castle tax (see Cost); MaintainIngredient an ingredient that is required
+// but not consumed.
+type MultisellIngredient struct {
+	ItemID             int32
+	Count              int64
+	TaxIngredient      bool
+	MaintainIngredient bool
+}
+
+// MultisellEntry is one exchange of a multisell list. ID is its 1-based
+// position in the list, which MultiSellChoose names. Stackable is set when
+// every product stacks: only then may the player exchange several at once.
+type MultisellEntry struct {
+	ID          int32
+	Stackable   bool
+	Ingredients []MultisellIngredient
+	Products    []MultisellIngredient
+}
+
+// MultisellList is a loaded multisell list. A list without NPCs opens at any
+// NPC; MaintainEnchantment carries the enchant level of the exchanged
+// equipment over to the product. Lists are read-only once loaded.
+type MultisellList struct {
+	ID                  int32
+	ApplyTaxes          bool
+	MaintainEnchantment bool
+	Entries             []MultisellEntry
+	npcs                map[int32]bool
+}
+
+// AllowsNpc reports whether the list may be opened at NPC template npcID.
+func (l *MultisellList) AllowsNpc(npcID int32) bool {
+	return len(l.npcs) == 0 || l.npcs[npcID]
+}
+
+// Entry returns the entry with the given id.
+func (l *MultisellList) Entry(id int32) (*MultisellEntry, bool) {
+	if id < 1 || int(id) > len(l.Entries) {
+		return nil, false
+	}
+	return &l.Entries[id-1], true
+}
+
+// Cost returns what one exchange of e takes at the castle taxRate (L2J
+// PreparedEntry): adena lines are folded into one, and a tax ingredient only
+// sets the base the tax is charged on, and only in lists that apply taxes.
+func (l *MultisellList) Cost(e *MultisellEntry, taxRate float64) []MultisellIngredient {
+	var adena int64
+	out := make([]MultisellIngredient, 0, len(e.Ingredients))
+	for _, ing := range e.Ingredients {
+		if ing.ItemID != models.ItemIDAdena {
+			out = append(out, ing)
+			continue
+		}
+		if !ing.TaxIngredient {
+			adena += ing.Count
+		} else if l.ApplyTaxes {
+			adena += int64(math.Round(float64(ing.Count) * taxRate))
+		}
+	}
+	if adena > 0 {
+		out = append(out, MultisellIngredient{ItemID: models.ItemIDAdena, Count: adena})
+	}
+	return out
+}
+
+// MultisellRegistry holds the multisell lists parsed from data/multisell/*.xml
+// (L2J MultisellData): the list id is the file name.
+type MultisellRegistry struct {
+	mu     sync.RWMutex
+	lists  map[int32]*MultisellList
+	loaded bool
+
+	// templateOf resolves product templates to tell stackable entries.
+	templateOf func(itemID int32) *ItemTemplate
+}
+
+// NewMultisellRegistry creates an empty registry.
+func NewMultisellRegistry() *MultisellRegistry {
+	return &MultisellRegistry{
+		lists:      make(map[int32]*MultisellList),
+		templateOf: GetItemTemplateRegistry().Get,
+	}
+}
+
+// Global multisell registry instance.
+var multisells = NewMultisellRegistry()
+
+// GetMultisellRegistry returns the global multisell registry.
+func GetMultisellRegistry() *MultisellRegistry { return multisells }
+
+// XML schema for data/multisell/*.xml.
+type xmlMultisellList struct {
+	XMLName             xml.Name            `xml:"list"`
+	ApplyTaxes          bool                `xml:"applyTaxes,attr"`
+	MaintainEnchantment bool                `xml:"maintainEnchantment,attr"`
+	Npcs                []int32             `xml:"npcs>npc"`
+	Items               []xmlMultisellEntry `xml:"item"`
+}
+
+type xmlMultisellEntry struct {
+	Ingredients []xmlMultisellIngredient `xml:"ingredient"`
+	Productions []xmlMultisellIngredient `xml:"production"`
+}
+
+type xmlMultisellIngredient struct {
+	ID                 int32 `xml:"id,attr"`
+	Count              int64 `xml:"count,attr"`
+	IsTaxIngredient    bool  `xml:"isTaxIngredient,attr"`
+	MaintainIngredient bool  `xml:"maintainIngredient,attr"`
+}
+
+// IsLoaded reports whether multisell lists have been loaded.
+func (r *MultisellRegistry) IsLoaded() bool {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.loaded
+}
+
+// Count returns the number of loaded lists.
+func (r *MultisellRegistry) Count() int {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return len(r.lists)
+}
+
+// Get returns list id.
+func (r *MultisellRegistry) Get(id int32) (*MultisellList, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	l, ok := r.lists[id]
+	return l, ok
+}
+
+// ListsForNpc returns the ids of the lists that name NPC template npcID, in
+// ascending order. Lists open to any NPC are left out: only a link in some
+// dialogue leads to them.
+func (r *MultisellRegistry) ListsForNpc(npcID int32) []int32 {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	var ids []int32
+	for id, l := range r.lists {
+		if l.npcs[npcID] {
+			ids = append(ids, id)
+		}
+	}
+	slices.Sort(ids)
+	return ids
+}
+
+// LoadFromDirectory parses every <listId>.xml multisell list in dir, replacing
+// any previous data. A file that fails to parse is skipped with a warning.
+func (r *MultisellRegistry) LoadFromDirectory(dir string) error {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return fmt.Errorf("read multisell dir: %w", err)
+	}
+
+	lists := make(map[int32]*MultisellList)
+	for _, e := range entries {
+		if e.IsDir() || filepath.Ext(e.Name()) != ".xml" {
+			continue
+		}
+		id, err := strconv.ParseInt(strings.TrimSuffix(e.Name(), ".xml"), 10, 32)
+		if err != nil {
+			log.Warn().Str("file", e.Name()).Msg("multisell: file name is not a list id")
+			continue
+		}
+		data, err := os.ReadFile(filepath.Join(dir, e.Name()))
+		if err != nil {
+			log.Warn().Err(err).Str("file", e.Name()).Msg("multisell: read failed")
+			continue
+		}
+		list, err := r.parse(int32(id), data)
+		if err != nil {
+			log.Warn().Err(err).Str("file", e.Name()).Msg("multisell: parse failed")
+			continue
+		}
+		lists[list.ID] = list
+	}
+
+	r.mu.Lock()
+	r.lists, r.loaded = lists, true
+	r.mu.Unlock()
+	log.Info().Int("lists", len(lists)).Msg("Loaded multisell lists")
+	return nil
+}
+
+// parse builds one list. Entries with a non-item ingredient (L2J's negative
+// ids for PC café points, clan reputation and fame) or an empty side are
+// dropped: nothing here can pay or grant those.
+func (r *MultisellRegistry) parse(id int32, data []byte) (*MultisellList, error) {
+	var doc xmlMultisellList
+	if err := xml.Unmarshal(data, &doc); err != nil {
+		return nil, err
+	}
+	list := &MultisellList{
+		ID:                  id,
+		ApplyTaxes:          doc.ApplyTaxes,
+		MaintainEnchantment: doc.MaintainEnchantment,
+		npcs:                make(map[int32]bool, len(doc.Npcs)),
+	}
+	for _, npc := range doc.Npcs {
+		list.npcs[npc] = true
+	}
+	for i, it := range doc.Items {
+		ingredients, ok := multisellIngredients(it.Ingredients)
+		products, ok2 := multisellIngredients(it.Productions)
+		if !ok || !ok2 || len(ingredients) == 0 || len(products) == 0 {
+			log.Warn().Int32("list", id).Int("entry", i+1).Msg("multisell: unsupported entry, skipped")
+			continue
+		}
+		stackable := true
+		for _, p := range products {
+			if tmpl := r.templateOf(p.ItemID); tmpl == nil || !tmpl.Stackable {
+				stackable = false
+			}
+		}
+		list.Entries = append(list.Entries, MultisellEntry{
+			ID:          int32(len(list.Entries) + 1),
+			Stackable:   stackable,
+			Ingredients: ingredients,
+			Products:    products,
+		})
+	}
+	return list, nil
+}
+
+func multisellIngredients(in []xmlMultisellIngredient) ([]MultisellIngredient, bool) {
+	out := make([]MultisellIngredient, 0, len(in))
+	for _, x := range in {
+		if x.ID <= 0 || x.Count <= 0 {
+			return nil, false
+		}
+		out = append(out, MultisellIngredient{
+			ItemID:             x.ID,
+			Count:              x.Count,
+			TaxIngredient:      x.IsTaxIngredient,
+			MaintainIngredient: x.MaintainIngredient,
+		})
+	}
+	return out, true
+}
+
+// ActiveMultisell is the multisell list a player has open and the NPC that
+// opened it. ListID is zero while none is open.
+type ActiveMultisell struct {
+	ListID   int32
+	NpcObjID int32
+}
@@ -0,0 +1,101 @@
+package registry
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/VerTox/l2go/internal/gameserver/models"
+)
+
+const testMultisell = `<?xml version="1.0" encoding="UTF-8"?>
+<list applyTaxes="true">
+	<npcs>
+		<npc>30301</npc>
+	</npcs>
+	<item>
+		<ingredient id="1060" count="10" />
+		<ingredient id="57" count="100" />
+		<ingredient id="57" count="1000" isTaxIngredient="true" />
+		<production id="1061" count="2" />
+	</item>
+	<item>
+		<ingredient id="-100" count="5" />
+		<production id="1061" count="1" />
+	</item>
+	<item>
+		<ingredient id="1" count="1" maintainIngredient="true" />
+		<production id="2" count="1" />
+	</item>
+</list>`
+
+func newTestMultisells(t *testing.T) *MultisellRegistry {
+	t.Helper()
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "100.xml"), []byte(testMultisell), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "200.xml"), []byte(`<list><item><ingredient id="57" count="1" /><production id="1" count="1" /></item></list>`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "notes.xml"), []byte("<list/>"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	r := NewMultisellRegistry()
+	r.templateOf = func(id int32) *ItemTemplate {
+		return &ItemTemplate{ID: id, Stackable: id == 1061}
+	}
+	if err := r.LoadFromDirectory(dir); err != nil {
+		t.Fatal(err)
+	}
+	return r
+}
+
+func TestMultisell_Load(t *testing.T) {
+	r := newTestMultisells(t)
+	if !r.IsLoaded() || r.Count() != 2 {
+		t.Fatalf("loaded = %v, count = %d", r.IsLoaded(), r.Count())
+	}
+	list, ok := r.Get(100)
+	if !ok || !list.ApplyTaxes || list.MaintainEnchantment {
+		t.Fatalf("list = %+v", list)
+	}
+	// The clan reputation entry is dropped; the ids follow the kept entries.
+	if len(list.Entries) != 2 || !list.Entries[0].Stackable || list.Entries[1].Stackable || list.Entries[1].ID != 2 {
+		t.Fatalf("entries = %+v", list.Entries)
+	}
+	if !list.Entries[1].Ingredients[0].MaintainIngredient {
+		t.Fatal("maintainIngredient must be kept")
+	}
+	if _, ok := list.Entry(3); ok {
+		t.Fatal("entry 3 must not exist")
+	}
+
+	if !list.AllowsNpc(30301) || list.AllowsNpc(30315) {
+		t.Fatal("list 100 must open only at its own NPC")
+	}
+	if open, _ := r.Get(200); !open.AllowsNpc(30315) {
+		t.Fatal("a list without NPCs opens anywhere")
+	}
+	if got := r.ListsForNpc(30301); len(got) != 1 || got[0] != 100 {
+		t.Fatalf("lists for 30301 = %v", got)
+	}
+}
+
+func TestMultisell_CostFoldsAdenaAndTax(t *testing.T) {
+	r := newTestMultisells(t)
+	list, _ := r.Get(100)
+	entry, _ := list.Entry(1)
+
+	cost := list.Cost(entry, 0.1)
+	want := []MultisellIngredient{{ItemID: 1060, Count: 10}, {ItemID: models.ItemIDAdena, Count: 200}}
+	if len(cost) != len(want) || cost[0] != want[0] || cost[1] != want[1] {
+		t.Fatalf("cost = %+v, want %+v", cost, want)
+	}
+
+	// Without taxes the tax ingredient costs nothing.
+	list.ApplyTaxes = false
+	if cost := list.Cost(entry, 0.1); len(cost) != 2 || cost[1].Count != 100 {
+		t.Fatalf("untaxed cost = %+v", cost)
+	}
+}
@@ -14,8 +14,9 @@ import (
 	"github.com/VerTox/l2go/internal/gameserver/models"
 )
 
-// LoadSpawnsFromDirectory loads all fixed-position spawn data from XML files.
-// Zone-based spawns (count without coordinates) are skipped in this iteration.
+// LoadSpawnsFromDirectory loads all spawn data from XML files: fixed-position
+// spawns, and zone-based spawn groups whose NPCs (count without coordinates)
+// spawn at random points of the group's territory.
 func LoadSpawnsFromDirectory(dir string) ([]models.SpawnData, error) {
 	log.Info().Str("dir", dir).Msg("Loading NPC spawns from directory")
 
@@ -57,13 +58,14 @@ type xmlSpawn struct {
 }
 
 type xmlSpawnNpc struct {
-	ID           int32  `xml:"id,attr"`
-	X            string `xml:"x,attr"`
-	Y            string `xml:"y,attr"`
-	Z            string `xml:"z,attr"`
-	Heading      string `xml:"heading,attr"`
-	RespawnDelay string `xml:"respawnDelay,attr"`
-	Count        string `xml:"count,attr"`
+	ID            int32  `xml:"id,attr"`
+	X             string `xml:"x,attr"`
+	Y             string `xml:"y,attr"`
+	Z             string `xml:"z,attr"`
+	Heading       string `xml:"heading,attr"`
+	RespawnDelay  string `xml:"respawnDelay,attr"`
+	RespawnRandom string `xml:"respawnRandom,attr"`
+	Count         string `xml:"count,attr"`
 }
 
 func loadSpawnFile(filename string) ([]models.SpawnData, error) {
@@ -87,19 +89,22 @@ func loadSpawnFile(filename string) ([]models.SpawnData, error) {
 
 	for _, spawnGroup := range list.Spawns {
 		for _, npc := range spawnGroup.NPCs {
-			// Skip zone-based spawns (have count but no coordinates)
-			if npc.X == "" || npc.Y == "" || npc.Z == "" {
-				continue
-			}
-
 			sd := models.SpawnData{
-				NpcID:        npc.ID,
-				X:            atoi(npc.X),
-				Y:            atoi(npc.Y),
-				Z:            atoi(npc.Z),
-				Heading:      atoi(npc.Heading),
-				RespawnDelay: atoi(npc.RespawnDelay),
-				Count:        1,
+				NpcID:         npc.ID,
+				Heading:       atoi(npc.Heading),
+				RespawnDelay:  atoi(npc.RespawnDelay),
+				RespawnRandom: atoi(npc.RespawnRandom),
+				Count:         1,
+			}
+			switch {
+			case npc.X != "" && npc.Y != "" && npc.Z != "":
+				sd.X, sd.Y, sd.Z = atoi(npc.X), atoi(npc.Y), atoi(npc.Z)
+			case spawnGroup.Zone != "":
+				// Zone-based: count NPCs at random points of the group's territory.
+				sd.Territory = spawnGroup.Zone
+			default:
+				log.Warn().Str("file", filename).Int32("npc", npc.ID).Msg("spawn without coordinates or zone, skipped")
+				continue
 			}
 
 			if npc.Count != "" {
@@ -124,7 +129,7 @@ func atoi(s string) int {
 // sqlSpawnTupleRe matches a single VALUES tuple from L2J spawnlist.sql:
 // ("location", count, npc_templateid, locx, locy, locz, randomx, randomy, heading, respawn_delay, respawn_random, loc_id, periodOfDay)
 var sqlSpawnTupleRe = regexp.MustCompile(
-	`\("[^"]*"\s*,\s*(\d+)\s*,\s*(\d+)\s*,\s*(-?\d+)\s*,\s*(-?\d+)\s*,\s*(-?\d+)\s*,\s*-?\d+\s*,\s*-?\d+\s*,\s*(-?\d+)\s*,\s*(\d+)\s*,\s*-?\d+\s*,\s*-?\d+\s*,\s*\d+\s*\)`,
+	`\("[^"]*"\s*,\s*(\d+)\s*,\s*(\d+)\s*,\s*(-?\d+)\s*,\s*(-?\d+)\s*,\s*(-?\d+)\s*,\s*-?\d+\s*,\s*-?\d+\s*,\s*(-?\d+)\s*,\s*(\d+)\s*,\s*(\d+)\s*,\s*-?\d+\s*,\s*\d+\s*\)`,
 )
 
 // LoadSpawnsFromSQL loads spawn data by parsing a L2J spawnlist.sql file.
@@ -148,19 +153,21 @@ func LoadSpawnsFromSQL(filename string) ([]models.SpawnData, error) {
 		line := scanner.Text()
 		matches := sqlSpawnTupleRe.FindAllStringSubmatch(line, -1)
 		for _, m := range matches {
-			// m[1]=count, m[2]=npc_templateid, m[3]=locx, m[4]=locy, m[5]=locz, m[6]=heading, m[7]=respawn_delay
+			// m[1]=count, m[2]=npc_templateid, m[3]=locx, m[4]=locy, m[5]=locz, m[6]=heading,
+			// m[7]=respawn_delay, m[8]=respawn_random
 			count := atoi(m[1])
 			if count < 1 {
 				count = 1
 			}
 			sd := models.SpawnData{
-				NpcID:        int32(atoi(m[2])),
-				X:            atoi(m[3]),
-				Y:            atoi(m[4]),
-				Z:            atoi(m[5]),
-				Heading:      atoi(m[6]),
-				RespawnDelay: atoi(m[7]),
-				Count:        count,
+				NpcID:         int32(atoi(m[2])),
+				X:             atoi(m[3]),
+				Y:             atoi(m[4]),
+				Z:             atoi(m[5]),
+				Heading:       atoi(m[6]),
+				RespawnDelay:  atoi(m[7]),
+				RespawnRandom: atoi(m[8]),
+				Count:         count,
 			}
 			spawns = append(spawns, sd)
 		}
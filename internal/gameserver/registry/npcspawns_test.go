@@ -0,0 +1,62 @@
+package registry
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/VerTox/l2go/internal/gameserver/models"
+)
+
+const testSpawnList = `<?xml version="1.0" encoding="UTF-8"?>
+<list enabled="true">
+	<spawn name="fixed">
+		<npc id="22775" x="88347" y="56413" z="-3495" heading="49152" respawnDelay="90" />
+	</spawn>
+	<spawn zone="turek_orc_zone_01">
+		<npc id="20498" count="2" respawnDelay="60" respawnRandom="20" />
+	</spawn>
+	<spawn>
+		<npc id="1" />
+	</spawn>
+</list>`
+
+func TestLoadSpawnsFromDirectory_Groups(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "orcs.xml"), []byte(testSpawnList), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	spawns, err := LoadSpawnsFromDirectory(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []models.SpawnData{
+		{NpcID: 22775, X: 88347, Y: 56413, Z: -3495, Heading: 49152, RespawnDelay: 90, Count: 1},
+		{NpcID: 20498, RespawnDelay: 60, RespawnRandom: 20, Count: 2, Territory: "turek_orc_zone_01"},
+	}
+	if len(spawns) != len(want) {
+		t.Fatalf("spawns = %+v", spawns)
+	}
+	for i := range want {
+		if spawns[i] != want[i] {
+			t.Errorf("spawn %d = %+v, want %+v", i, spawns[i], want[i])
+		}
+	}
+}
+
+func TestLoadSpawnsFromSQL_RespawnRandom(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "spawnlist.sql")
+	sql := "INSERT INTO `spawnlist` VALUES\n" +
+		`("oren21_qm2220_05", 1, 20780, 83021, 91920, -3481, 0, 0, 43462, 30, 5, 0, 0),` + "\n"
+	if err := os.WriteFile(file, []byte(sql), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	spawns, err := LoadSpawnsFromSQL(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := models.SpawnData{NpcID: 20780, X: 83021, Y: 91920, Z: -3481, Heading: 43462, RespawnDelay: 30, RespawnRandom: 5, Count: 1}
+	if len(spawns) != 1 || spawns[0] != want {
+		t.Fatalf("spawns = %+v, want %+v", spawns, want)
+	}
+}
@@ -97,10 +97,11 @@ type xmlNpcList struct {
 }
 
 type xmlNpc struct {
-	ID    int32  `xml:"id,attr"`
-	Level int    `xml:"level,attr"`
-	Type  string `xml:"type,attr"`
-	Name  string `xml:"name,attr"`
+	ID        int32  `xml:"id,attr"`
+	DisplayID int32  `xml:"displayId,attr"` // custom NPCs: the retail NPC they are drawn as
+	Level     int    `xml:"level,attr"`
+	Type      string `xml:"type,attr"`
+	Name      string `xml:"name,attr"`
 
 	Race      string        `xml:"race"`
 	Sex       string        `xml:"sex"`
@@ -110,6 +111,67 @@ type xmlNpc struct {
 	AI        *xmlAI        `xml:"ai"`
 	Collision *xmlCollision `xml:"collision"`
 	Status    *xmlStatus    `xml:"status"`
+	DropLists *xmlDropLists `xml:"dropLists"`
+	SkillList *xmlSkillList `xml:"skillList"`
+
+	Parameters *xmlNpcParameters `xml:"parameters"`
+}
+
+// xmlNpcParameters is the datapack <parameters>; only the <minions> lists are
+// read, the script parameters are left to the scripts.
+type xmlNpcParameters struct {
+	Minions []xmlMinionList `xml:"minions"`
+}
+
+// minionListPrivates names the escort a leader spawns with (L2J
+// L2MonsterInstance.onSpawn); other lists (Privates1, Privates2, ...) are
+// summoned by the NPC's script.
+const minionListPrivates = "Privates"
+
+type xmlMinionList struct {
+	Name string      `xml:"name,attr"`
+	NPCs []xmlMinion `xml:"npc"`
+}
+
+type xmlMinion struct {
+	ID          int32 `xml:"id,attr"`
+	Count       int   `xml:"count,attr"`
+	RespawnTime int   `xml:"respawnTime,attr"`
+}
+
+// xmlSkillList is the datapack <skillList>: the skills an NPC has, passive
+// stat skills and the ones its AI casts alike.
+type xmlSkillList struct {
+	Skills []xmlNpcSkill `xml:"skill"`
+}
+
+type xmlNpcSkill struct {
+	ID    int32 `xml:"id,attr"`
+	Level int   `xml:"level,attr"`
+}
+
+// xmlDropLists is the datapack <dropLists>: <death> holds ungrouped items and
+// <group>s rolled on a kill, <corpse> the spoil items.
+type xmlDropLists struct {
+	Death  *xmlDropList `xml:"death"`
+	Corpse *xmlDropList `xml:"corpse"`
+}
+
+type xmlDropList struct {
+	Items  []xmlDropItem  `xml:"item"`
+	Groups []xmlDropGroup `xml:"group"`
+}
+
+type xmlDropGroup struct {
+	Chance string        `xml:"chance,attr"`
+	Items  []xmlDropItem `xml:"item"`
+}
+
+type xmlDropItem struct {
+	ID     int32  `xml:"id,attr"`
+	Min    string `xml:"min,attr"`
+	Max    string `xml:"max,attr"`
+	Chance string `xml:"chance,attr"`
 }
 
 // xmlAcquire is the datapack <acquire expRate=".." sp=".."/> reward element.
@@ -176,8 +238,18 @@ type xmlCollisionValue struct {
 }
 
 type xmlAI struct {
-	AggroRange   string `xml:"aggroRange,attr"`
-	IsAggressive string `xml:"isAggressive,attr"`
+	Type          string       `xml:"type,attr"`
+	AggroRange    string       `xml:"aggroRange,attr"`
+	ClanHelpRange string       `xml:"clanHelpRange,attr"`
+	IsAggressive  string       `xml:"isAggressive,attr"`
+	ClanList      *xmlClanList `xml:"clanList"`
+}
+
+// xmlClanList is the <ai><clanList>: the factions an NPC belongs to and calls
+// for help, and the NPCs of those factions it does not call.
+type xmlClanList struct {
+	Clans        []string `xml:"clan"`
+	IgnoreNpcIDs []int32  `xml:"ignoreNpcId"`
 }
 
 type xmlStatus struct {
@@ -223,6 +295,10 @@ func convertXMLNpc(xn xmlNpc) *models.NpcTemplate {
 		CanMove:    true,
 	}
 
+	if xn.DisplayID > 0 {
+		t.DisplayID = xn.DisplayID
+	}
+
 	// Type-based defaults
 	switch xn.Type {
 	case "L2Npc", "L2Merchant", "L2Teleporter", "L2Warehouse":
@@ -283,11 +359,78 @@ func convertXMLNpc(xn xmlNpc) *models.NpcTemplate {
 	// AI
 	if xn.AI != nil {
 		t.AggroRange = parseIntSafe(xn.AI.AggroRange)
+		t.Aggressive = xn.AI.IsAggressive != "false"
+		t.AIType = xn.AI.Type
+		t.ClanHelpRange = parseIntSafe(xn.AI.ClanHelpRange)
+		if cl := xn.AI.ClanList; cl != nil {
+			t.Clans = cl.Clans
+			t.IgnoreClanNpcIDs = cl.IgnoreNpcIDs
+		}
+	}
+
+	// Skills
+	if xn.SkillList != nil {
+		for _, sk := range xn.SkillList.Skills {
+			if sk.ID > 0 && sk.Level > 0 {
+				t.Skills = append(t.Skills, models.NpcSkill{ID: sk.ID, Level: sk.Level})
+			}
+		}
+	}
+
+	// Minions
+	if xn.Parameters != nil {
+		for _, list := range xn.Parameters.Minions {
+			if list.Name != minionListPrivates {
+				continue
+			}
+			for _, m := range list.NPCs {
+				if m.ID > 0 && m.Count > 0 {
+					t.Minions = append(t.Minions, models.NpcMinion{NpcID: m.ID, Count: m.Count, RespawnTime: m.RespawnTime})
+				}
+			}
+		}
+	}
+
+	// Drops
+	if xn.DropLists != nil {
+		if d := xn.DropLists.Death; d != nil {
+			t.DeathItems = convertDropItems(d.Items)
+			for _, g := range d.Groups {
+				if items := convertDropItems(g.Items); len(items) > 0 {
+					t.DeathGroups = append(t.DeathGroups, models.DropGroup{Chance: parseFloat64(g.Chance), Items: items})
+				}
+			}
+		}
+		if c := xn.DropLists.Corpse; c != nil {
+			t.SpoilItems = convertDropItems(c.Items)
+		}
 	}
 
 	return t
 }
 
+// convertDropItems converts drop list items, skipping those that can never
+// drop: no chance, or no count.
+func convertDropItems(xs []xmlDropItem) []models.DropItem {
+	var out []models.DropItem
+	for _, x := range xs {
+		d := models.DropItem{
+			ItemID: x.ID,
+			Min:    int64(parseFloat64(x.Min)),
+			Max:    int64(parseFloat64(x.Max)),
+			Chance: parseFloat64(x.Chance),
+		}
+		if d.Max < d.Min {
+			d.Max = d.Min
+		}
+		if d.ItemID <= 0 || d.Max <= 0 || d.Chance <= 0 {
+			continue
+		}
+		out = append(out, d)
+	}
+	return out
+}
+
 func parseFloat64(s string) float64 {
 	v, _ := strconv.ParseFloat(strings.TrimSpace(s), 64)
 	return v
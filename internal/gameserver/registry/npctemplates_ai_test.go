@@ -0,0 +1,125 @@
+package registry
+
+import (
+	"encoding/xml"
+	"reflect"
+	"testing"
+
+	"github.com/VerTox/l2go/internal/gameserver/models"
+)
+
+// Monsters are aggressive unless their <ai> says isAggressive="false"; the
+// aggro range alone does not make them attack.
+func TestConvertXMLNpc_Aggressive(t *testing.T) {
+	const doc = `<list>
+		<npc id="20001" level="3" type="L2Monster" name="Gremlin">
+			<ai aggroRange="1000" clanHelpRange="300" isAggressive="false" />
+		</npc>
+		<npc id="20120" level="22" type="L2Monster" name="Wolf">
+			<ai aggroRange="300" clanHelpRange="300" />
+		</npc>
+	</list>`
+
+	var list xmlNpcList
+	if err := xml.Unmarshal([]byte(doc), &list); err != nil {
+		t.Fatalf("unmarshal: %v", err)
+	}
+	if gremlin := convertXMLNpc(list.NPCs[0]); gremlin.Aggressive || gremlin.AggroRange != 1000 {
+		t.Errorf("Gremlin: Aggressive = %v, AggroRange = %d", gremlin.Aggressive, gremlin.AggroRange)
+	}
+	if wolf := convertXMLNpc(list.NPCs[1]); !wolf.Aggressive || wolf.AggroRange != 300 {
+		t.Errorf("Wolf: Aggressive = %v, AggroRange = %d", wolf.Aggressive, wolf.AggroRange)
+	}
+}
+
+func TestConvertXMLNpc_Skills(t *testing.T) {
+	const doc = `<list>
+		<npc id="21066" level="52" type="L2Monster" name="Shaman">
+			<skillList>
+				<skill id="4100" level="7" />
+				<skill id="4408" level="13" />
+				<skill id="0" level="1" />
+			</skillList>
+			<ai type="MAGE" aggroRange="1000" clanHelpRange="400" />
+		</npc>
+		<npc id="20001" level="3" type="L2Monster" name="Gremlin"></npc>
+	</list>`
+
+	var list xmlNpcList
+	if err := xml.Unmarshal([]byte(doc), &list); err != nil {
+		t.Fatalf("unmarshal: %v", err)
+	}
+	shaman := convertXMLNpc(list.NPCs[0])
+	want := []models.NpcSkill{{ID: 4100, Level: 7}, {ID: 4408, Level: 13}}
+	if !reflect.DeepEqual(shaman.Skills, want) {
+		t.Errorf("Skills = %+v, want %+v", shaman.Skills, want)
+	}
+	if shaman.AIType != "MAGE" {
+		t.Errorf("AIType = %q, want MAGE", shaman.AIType)
+	}
+	if gremlin := convertXMLNpc(list.NPCs[1]); gremlin.Skills != nil || gremlin.AIType != "" {
+		t.Errorf("an NPC without <skillList> or <ai> has none: %+v", gremlin)
+	}
+}
+
+func TestConvertXMLNpc_ClanList(t *testing.T) {
+	const doc = `<list>
+		<npc id="20495" level="49" type="L2Monster" name="Turek Orc Warlord">
+			<ai aggroRange="1000" clanHelpRange="300" isAggressive="false">
+				<clanList>
+					<clan>ORC</clan>
+					<ignoreNpcId>20134</ignoreNpcId>
+				</clanList>
+			</ai>
+		</npc>
+	</list>`
+
+	var list xmlNpcList
+	if err := xml.Unmarshal([]byte(doc), &list); err != nil {
+		t.Fatalf("unmarshal: %v", err)
+	}
+	orc := convertXMLNpc(list.NPCs[0])
+	if !reflect.DeepEqual(orc.Clans, []string{"ORC"}) || orc.ClanHelpRange != 300 {
+		t.Errorf("Clans = %v, ClanHelpRange = %d", orc.Clans, orc.ClanHelpRange)
+	}
+	if !reflect.DeepEqual(orc.IgnoreClanNpcIDs, []int32{20134}) {
+		t.Errorf("IgnoreClanNpcIDs = %v", orc.IgnoreClanNpcIDs)
+	}
+	if !orc.IsClan([]string{"ORC"}) || orc.IsClan([]string{"SKELETON"}) || !orc.IsClan([]string{models.ClanAll}) {
+		t.Error("IsClan must match a shared clan or ALL")
+	}
+}
+
+func TestConvertXMLNpc_Minions(t *testing.T) {
+	const doc = `<list>
+		<npc id="25800" level="25" type="L2RaidBoss" name="Mammon Collector Talos">
+			<parameters>
+				<minions name="Privates">
+					<npc id="25801" count="3" respawnTime="360" weightPoint="1" />
+					<npc id="0" count="1" respawnTime="0" weightPoint="1" />
+				</minions>
+				<minions name="Privates1">
+					<npc id="25802" count="1" respawnTime="0" weightPoint="1" />
+				</minions>
+				<param name="RaidSpawnMusic" value="Rm01_A" />
+			</parameters>
+		</npc>
+		<npc id="20001" level="3" type="L2Monster" name="Gremlin"></npc>
+	</list>`
+
+	var list xmlNpcList
+	if err := xml.Unmarshal([]byte(doc), &list); err != nil {
+		t.Fatalf("unmarshal: %v", err)
+	}
+	talos := convertXMLNpc(list.NPCs[0])
+	want := []models.NpcMinion{{NpcID: 25801, Count: 3, RespawnTime: 360}}
+	if !reflect.DeepEqual(talos.Minions, want) {
+		t.Errorf("Minions = %+v, want %+v", talos.Minions, want)
+	}
+	if !talos.IsRaid() {
+		t.Error("an L2RaidBoss must be a raid")
+	}
+	if gremlin := convertXMLNpc(list.NPCs[1]); gremlin.Minions != nil || gremlin.IsRaid() {
+		t.Errorf("Gremlin: Minions = %+v, IsRaid = %v", gremlin.Minions, gremlin.IsRaid())
+	}
+}
@@ -0,0 +1,61 @@
+package registry
+
+import (
+	"encoding/xml"
+	"reflect"
+	"testing"
+
+	"github.com/VerTox/l2go/internal/gameserver/models"
+)
+
+func TestConvertXMLNpc_DropLists(t *testing.T) {
+	const doc = `<list>
+		<npc id="20001" level="3" type="L2Monster" name="Gremlin">
+			<dropLists>
+				<death>
+					<item id="1869" min="1" max="2" chance="12.5" />
+					<group chance="70">
+						<item id="57" min="30" max="42" chance="100" />
+					</group>
+					<group chance="33.5">
+						<item id="112" min="1" max="1" chance="30.6" />
+						<item id="118" min="1" max="1" chance="0" />
+					</group>
+					<group chance="5">
+						<item id="116" min="1" max="1" chance="0" />
+					</group>
+				</death>
+				<corpse>
+					<item id="1864" min="1" max="3" chance="41" />
+				</corpse>
+			</dropLists>
+		</npc>
+		<npc id="30001" level="20" type="L2Merchant" name="Lector"></npc>
+	</list>`
+
+	var list xmlNpcList
+	if err := xml.Unmarshal([]byte(doc), &list); err != nil {
+		t.Fatalf("unmarshal: %v", err)
+	}
+
+	gremlin := convertXMLNpc(list.NPCs[0])
+	if want := []models.DropItem{{ItemID: 1869, Min: 1, Max: 2, Chance: 12.5}}; !reflect.DeepEqual(gremlin.DeathItems, want) {
+		t.Errorf("DeathItems = %+v, want %+v", gremlin.DeathItems, want)
+	}
+	// Items that can never drop are left out, and so are groups left empty.
+	wantGroups := []models.DropGroup{
+		{Chance: 70, Items: []models.DropItem{{ItemID: 57, Min: 30, Max: 42, Chance: 100}}},
+		{Chance: 33.5, Items: []models.DropItem{{ItemID: 112, Min: 1, Max: 1, Chance: 30.6}}},
+	}
+	if !reflect.DeepEqual(gremlin.DeathGroups, wantGroups) {
+		t.Errorf("DeathGroups = %+v, want %+v", gremlin.DeathGroups, wantGroups)
+	}
+	if want := []models.DropItem{{ItemID: 1864, Min: 1, Max: 3, Chance: 41}}; !reflect.DeepEqual(gremlin.SpoilItems, want) {
+		t.Errorf("SpoilItems = %+v, want %+v", gremlin.SpoilItems, want)
+	}
+
+	lector := convertXMLNpc(list.NPCs[1])
+	if lector.DeathItems != nil || lector.DeathGroups != nil || lector.SpoilItems != nil {
+		t.Errorf("an NPC without <dropLists> drops nothing: %+v", lector)
+	}
+}
@@ -0,0 +1,54 @@
+package registry
+
+import "github.com/VerTox/l2go/internal/gameserver/models"
+
+// PrivateStoreType is the store mode shown in CharInfo/UserInfo (L2J
+// PrivateStoreType ids, written as-is to the client).
+type PrivateStoreType int32
+
+const (
+	StoreNone        PrivateStoreType = 0
+	StoreSell        PrivateStoreType = 1
+	StoreSellManage  PrivateStoreType = 2
+	StoreBuy         PrivateStoreType = 3
+	StoreBuyManage   PrivateStoreType = 4
+	StorePackageSell PrivateStoreType = 8
+)
+
+// Selling reports whether the store is open for buyers (sell or package sell).
+func (t PrivateStoreType) Selling() bool { return t == StoreSell || t == StorePackageSell }
+
+// Open reports whether the store is open for visitors (not merely managed).
+func (t PrivateStoreType) Open() bool { return t.Selling() || t == StoreBuy }
+
+// StoreItem is one line of a private store list. On a sell list Item is the
+// seller's stack as it was when listed (ObjectID identifies it); on a buy list
+// only ItemID and EnchantLevel matter — any matching stack the visitor owns can
+// fill it. Count is what is still for sale / still wanted, Price is per unit.
+type StoreItem struct {
+	Item  models.CharacterItem
+	Count int64
+	Price int64
+}
+
+// PrivateStore is a player's store: the current mode plus the sell and buy
+// lists and their title messages. The lists outlive a closed store so the
+// manage window offers the previous setup again, as in L2J. Seq changes every
+// time the store opens, so a deal result for an earlier opening is ignored.
+type PrivateStore struct {
+	Type     PrivateStoreType
+	Package  bool
+	SellMsg  string
+	BuyMsg   string
+	SellList []StoreItem
+	BuyList  []StoreItem
+	Seq      int64
+}
+
+// Message returns the title shown over the store for its current mode.
+func (s *PrivateStore) Message() string {
+	if s.Type == StoreBuy || s.Type == StoreBuyManage {
+		return s.BuyMsg
+	}
+	return s.SellMsg
+}
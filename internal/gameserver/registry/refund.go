@@ -0,0 +1,87 @@
+package registry
+
+import (
+	"sync"
+
+	"github.com/VerTox/l2go/internal/gameserver/models"
+)
+
+// RefundListSize is how many sold lines a merchant keeps for buy-back (L2J
+// PcRefund): selling more pushes the oldest out.
+const RefundListSize = 12
+
+// RefundRegistry stores each character's refund list: copies of the items it
+// sold to merchants, newest last, which it may buy back at the price it got.
+// Like L2J's refund container it is NOT persisted and is dropped via Clear
+// when the player leaves the world. Safe for concurrent use.
+type RefundRegistry struct {
+	mu     sync.Mutex
+	byChar map[int32][]models.CharacterItem
+}
+
+// NewRefundRegistry creates an empty refund registry.
+func NewRefundRegistry() *RefundRegistry {
+	return &RefundRegistry{byChar: make(map[int32][]models.CharacterItem)}
+}
+
+// Global refund registry instance.
+var refunds = NewRefundRegistry()
+
+// GetRefundRegistry returns the global refund registry.
+func GetRefundRegistry() *RefundRegistry { return refunds }
+
+// Add appends sold items to charID's refund list, dropping the oldest beyond
+// RefundListSize.
+func (r *RefundRegistry) Add(charID int32, items ...models.CharacterItem) {
+	if len(items) == 0 {
+		return
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	list := append(r.byChar[charID], items...)
+	if over := len(list) - RefundListSize; over > 0 {
+		list = append([]models.CharacterItem(nil), list[over:]...)
+	}
+	r.byChar[charID] = list
+}
+
+// List returns a copy of charID's refund list, oldest first. Indexes into it
+// are what RequestRefundItem names.
+func (r *RefundRegistry) List(charID int32) []models.CharacterItem {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]models.CharacterItem(nil), r.byChar[charID]...)
+}
+
+// Take removes the entries at the given indexes from charID's refund list and
+// returns them, all or nothing: an index out of range or named twice takes
+// nothing. A buy-back that then fails puts them back with Add.
+func (r *RefundRegistry) Take(charID int32, indexes []int32) ([]models.CharacterItem, bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	list := r.byChar[charID]
+	picked := make(map[int32]bool, len(indexes))
+	out := make([]models.CharacterItem, 0, len(indexes))
+	for _, idx := range indexes {
+		if idx < 0 || int(idx) >= len(list) || picked[idx] {
+			return nil, false
+		}
+		picked[idx] = true
+		out = append(out, list[idx])
+	}
+	rest := make([]models.CharacterItem, 0, len(list)-len(out))
+	for i := range list {
+		if !picked[int32(i)] {
+			rest = append(rest, list[i])
+		}
+	}
+	r.byChar[charID] = rest
+	return out, true
+}
+
+// Clear drops charID's refund list.
+func (r *RefundRegistry) Clear(charID int32) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	delete(r.byChar, charID)
+}
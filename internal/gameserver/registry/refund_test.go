@@ -0,0 +1,37 @@
+package registry
+
+import (
+	"testing"
+
+	"github.com/VerTox/l2go/internal/gameserver/models"
+)
+
+func TestRefund_KeepsNewestAndTakesAllOrNothing(t *testing.T) {
+	r := NewRefundRegistry()
+	for i := 1; i <= RefundListSize+2; i++ {
+		r.Add(7, models.CharacterItem{ObjectID: int32(i), ItemID: 1, Count: 1})
+	}
+	list := r.List(7)
+	if len(list) != RefundListSize || list[0].ObjectID != 3 {
+		t.Fatalf("refund list = %+v, want the %d newest", list, RefundListSize)
+	}
+
+	if _, ok := r.Take(7, []int32{0, 0}); ok {
+		t.Fatal("an index named twice must take nothing")
+	}
+	if _, ok := r.Take(7, []int32{1, RefundListSize}); ok {
+		t.Fatal("an index out of range must take nothing")
+	}
+	taken, ok := r.Take(7, []int32{2, 0})
+	if !ok || len(taken) != 2 || taken[0].ObjectID != 5 || taken[1].ObjectID != 3 {
+		t.Fatalf("taken = %+v, %v", taken, ok)
+	}
+	if list := r.List(7); len(list) != RefundListSize-2 || list[0].ObjectID != 4 || list[1].ObjectID != 6 {
+		t.Fatalf("rest = %+v", list)
+	}
+
+	r.Clear(7)
+	if len(r.List(7)) != 0 {
+		t.Fatal("Clear must drop the list")
+	}
+}
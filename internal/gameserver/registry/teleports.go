@@ -0,0 +1,203 @@
+package registry
+
+import (
+	"encoding/xml"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/rs/zerolog/log"
+
+	"github.com/VerTox/l2go/internal/gameserver/models"
+)
+
+// TeleportLocation is one destination of a gatekeeper's teleport list. Price
+// units of ItemID (adena unless the file names another item, e.g. noblesse
+// gate passes) pay for it. MinLevel and MaxLevel bound the characters it is
+// open to when set; Noble restricts it to nobles.
+type TeleportLocation struct {
+	ID       int32
+	Name     string
+	Position models.Position
+	Price    int64
+	ItemID   int32
+	MinLevel int
+	MaxLevel int
+	Noble    bool
+}
+
+// TeleportList is a loaded teleport list and the gatekeepers that offer it.
+// Lists are read-only once loaded.
+type TeleportList struct {
+	ID        int32
+	Locations []TeleportLocation
+	npcs      map[int32]bool
+}
+
+// AllowsNpc reports whether the list may be used at NPC template npcID.
+func (l *TeleportList) AllowsNpc(npcID int32) bool {
+	return l.npcs[npcID]
+}
+
+// Location returns the destination with the given id.
+func (l *TeleportList) Location(id int32) (*TeleportLocation, bool) {
+	for i := range l.Locations {
+		if l.Locations[i].ID == id {
+			return &l.Locations[i], true
+		}
+	}
+	return nil, false
+}
+
+// TeleportRegistry holds the gatekeeper teleport lists parsed from
+// data/teleports/*.xml (L2J TeleportLocationTable, split per gatekeeper): the
+// list id is the file name.
+type TeleportRegistry struct {
+	mu     sync.RWMutex
+	lists  map[int32]*TeleportList
+	loaded bool
+}
+
+// NewTeleportRegistry creates an empty registry.
+func NewTeleportRegistry() *TeleportRegistry {
+	return &TeleportRegistry{lists: make(map[int32]*TeleportList)}
+}
+
+// Global teleport registry instance.
+var teleports = NewTeleportRegistry()
+
+// GetTeleportRegistry returns the global teleport registry.
+func GetTeleportRegistry() *TeleportRegistry { return teleports }
+
+// XML schema for data/teleports/*.xml.
+type xmlTeleportList struct {
+	XMLName   xml.Name              `xml:"list"`
+	Npcs      []int32               `xml:"npcs>npc"`
+	Locations []xmlTeleportLocation `xml:"location"`
+}
+
+type xmlTeleportLocation struct {
+	ID       int32  `xml:"id,attr"`
+	Name     string `xml:"name,attr"`
+	X        int    `xml:"x,attr"`
+	Y        int    `xml:"y,attr"`
+	Z        int    `xml:"z,attr"`
+	Price    int64  `xml:"price,attr"`
+	ItemID   int32  `xml:"itemId,attr"`
+	MinLevel int    `xml:"minLevel,attr"`
+	MaxLevel int    `xml:"maxLevel,attr"`
+	Noble    bool   `xml:"noble,attr"`
+}
+
+// IsLoaded reports whether teleport lists have been loaded.
+func (r *TeleportRegistry) IsLoaded() bool {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.loaded
+}
+
+// Count returns the number of loaded lists.
+func (r *TeleportRegistry) Count() int {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return len(r.lists)
+}
+
+// Get returns list id.
+func (r *TeleportRegistry) Get(id int32) (*TeleportList, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	l, ok := r.lists[id]
+	return l, ok
+}
+
+// ListsForNpc returns the ids of the lists NPC template npcID offers, in
+// ascending order.
+func (r *TeleportRegistry) ListsForNpc(npcID int32) []int32 {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	var ids []int32
+	for id, l := range r.lists {
+		if l.npcs[npcID] {
+			ids = append(ids, id)
+		}
+	}
+	slices.Sort(ids)
+	return ids
+}
+
+// LoadFromDirectory parses every <listId>.xml teleport list in dir, replacing
+// any previous data. A file that fails to parse is skipped with a warning.
+func (r *TeleportRegistry) LoadFromDirectory(dir string) error {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return fmt.Errorf("read teleports dir: %w", err)
+	}
+
+	lists := make(map[int32]*TeleportList)
+	for _, e := range entries {
+		if e.IsDir() || filepath.Ext(e.Name()) != ".xml" {
+			continue
+		}
+		id, err := strconv.ParseInt(strings.TrimSuffix(e.Name(), ".xml"), 10, 32)
+		if err != nil {
+			log.Warn().Str("file", e.Name()).Msg("teleports: file name is not a list id")
+			continue
+		}
+		data, err := os.ReadFile(filepath.Join(dir, e.Name()))
+		if err != nil {
+			log.Warn().Err(err).Str("file", e.Name()).Msg("teleports: read failed")
+			continue
+		}
+		list, err := parseTeleportList(int32(id), data)
+		if err != nil {
+			log.Warn().Err(err).Str("file", e.Name()).Msg("teleports: parse failed")
+			continue
+		}
+		lists[list.ID] = list
+	}
+
+	r.mu.Lock()
+	r.lists, r.loaded = lists, true
+	r.mu.Unlock()
+	log.Info().Int("lists", len(lists)).Msg("Loaded teleport lists")
+	return nil
+}
+
+// parseTeleportList builds one list. Locations without an id, with a negative
+// price or repeating an id are dropped.
+func parseTeleportList(id int32, data []byte) (*TeleportList, error) {
+	var doc xmlTeleportList
+	if err := xml.Unmarshal(data, &doc); err != nil {
+		return nil, err
+	}
+	list := &TeleportList{ID: id, npcs: make(map[int32]bool, len(doc.Npcs))}
+	for _, npc := range doc.Npcs {
+		list.npcs[npc] = true
+	}
+	for _, x := range doc.Locations {
+		if _, dup := list.Location(x.ID); x.ID <= 0 || x.Price < 0 || dup {
+			log.Warn().Int32("list", id).Int32("location", x.ID).Msg("teleports: bad location, skipped")
+			continue
+		}
+		loc := TeleportLocation{
+			ID:       x.ID,
+			Name:     x.Name,
+			Position: models.Position{X: x.X, Y: x.Y, Z: x.Z},
+			Price:    x.Price,
+			ItemID:   x.ItemID,
+			MinLevel: x.MinLevel,
+			MaxLevel: x.MaxLevel,
+			Noble:    x.Noble,
+		}
+		if loc.ItemID == 0 {
+			loc.ItemID = models.ItemIDAdena
+		}
+		list.Locations = append(list.Locations, loc)
+	}
+	return list, nil
+}
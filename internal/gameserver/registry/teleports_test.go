@@ -0,0 +1,63 @@
+package registry
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/VerTox/l2go/internal/gameserver/models"
+)
+
+const testTeleports = `<?xml version="1.0" encoding="UTF-8"?>
+<list>
+	<npcs>
+		<npc>60002</npc>
+	</npcs>
+	<location id="1" name="Gludin Village" x="-80826" y="149775" z="-3043" price="9400" />
+	<location id="2" name="Ketra Orc Outpost" x="146990" y="-67128" z="-3640" price="1" itemId="6651" noble="true" />
+	<location id="3" name="Hunters Village" x="117110" y="76883" z="-2695" price="30000" minLevel="20" maxLevel="60" />
+	<location id="3" name="Duplicate" x="0" y="0" z="0" price="1" />
+	<location id="4" name="Negative" x="0" y="0" z="0" price="-1" />
+</list>`
+
+func TestTeleports_Load(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "6000201.xml"), []byte(testTeleports), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "notes.xml"), []byte("<list/>"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	r := NewTeleportRegistry()
+	if err := r.LoadFromDirectory(dir); err != nil {
+		t.Fatal(err)
+	}
+	if !r.IsLoaded() || r.Count() != 1 {
+		t.Fatalf("loaded = %v, count = %d", r.IsLoaded(), r.Count())
+	}
+	if got := r.ListsForNpc(60002); len(got) != 1 || got[0] != 6000201 {
+		t.Fatalf("lists for 60002 = %v", got)
+	}
+
+	list, _ := r.Get(6000201)
+	if !list.AllowsNpc(60002) || list.AllowsNpc(30006) {
+		t.Fatal("list must be used only at its own gatekeeper")
+	}
+	// The duplicate and the negative price are dropped.
+	if len(list.Locations) != 3 {
+		t.Fatalf("locations = %+v", list.Locations)
+	}
+	gludin, ok := list.Location(1)
+	if !ok || gludin.ItemID != models.ItemIDAdena || gludin.Price != 9400 || gludin.Position != (models.Position{X: -80826, Y: 149775, Z: -3043}) {
+		t.Fatalf("gludin = %+v", gludin)
+	}
+	if ketra, _ := list.Location(2); !ketra.Noble || ketra.ItemID != 6651 {
+		t.Fatalf("ketra = %+v", ketra)
+	}
+	if hunters, _ := list.Location(3); hunters.Name != "Hunters Village" || hunters.MinLevel != 20 || hunters.MaxLevel != 60 {
+		t.Fatalf("hunters = %+v", hunters)
+	}
+	if _, ok := list.Location(4); ok {
+		t.Fatal("location 4 must be dropped")
+	}
+}
@@ -0,0 +1,9 @@
+package registry
+
+// ActiveWarehouse is the warehouse a player has open: which keeper NPC opened
+// it and whether it is the clan's rather than the player's own. NpcObjID is
+// zero while none is open.
+type ActiveWarehouse struct {
+	NpcObjID int32
+	Clan     bool
+}
@@ -83,12 +83,43 @@ type PlayerWorldState struct {
 	// Owned by the game loop goroutine.
 	Effects models.CharEffectList `json:"-"`
 
+	// Sitting is true while the player sits on the ground (ChangeWaitType).
+	// PrivateStore is the player's private store: its Type is written into
+	// CharInfo/UserInfo, so every (re)spawn shows the store sign. Both are owned
+	// by the game loop goroutine.
+	Sitting      bool         `json:"-"`
+	PrivateStore PrivateStore `json:"-"`
+
+	// OfflineTrade is set while the player keeps its store open with no client
+	// attached: it has no connection in the ConnectionRegistry and leaves the
+	// world when the store closes. Owned by the game loop goroutine.
+	OfflineTrade bool `json:"-"`
+
+	// Warehouse is the warehouse window the player opened at a keeper NPC;
+	// deposits and withdrawals only go to it. Owned by the game loop goroutine.
+	Warehouse ActiveWarehouse `json:"-"`
+
+	// Merchant is the object id of the merchant NPC whose shop window the
+	// player opened, zero while none is. Owned by the game loop goroutine.
+	Merchant int32 `json:"-"`
+
+	// Multisell is the multisell list the player opened at an NPC; exchanges
+	// only come from it. Owned by the game loop goroutine.
+	Multisell ActiveMultisell `json:"-"`
+
+	// Quests holds the variables of the player's quests by quest name, loaded
+	// at world entry. Owned by the game loop goroutine afterwards.
+	Quests map[string]map[string]string `json:"-"`
+
 	// Known objects (sent to client, used for visibility tracking)
 	KnownNPCs map[int32]bool `json:"-"` // NPC objectIDs already sent to this client
 	// KnownPlayers tracks other players already spawned to this client (CharInfo sent).
 	// Owned exclusively by the game loop — only the loop goroutine reads/writes it, so
 	// no locking is needed despite being shared world state. (l2go-23g)
 	KnownPlayers map[int32]bool `json:"-"`
+	// KnownItems tracks the ground items already shown to this client. Owned by
+	// the game loop, like KnownPlayers.
+	KnownItems map[int32]bool `json:"-"`
 
 	// Session info
 	SessionData map[string]interface{} `json:"session_data,omitempty"`
@@ -160,6 +191,7 @@ type WorldRegistry struct {
 	players map[int32]*PlayerWorldState    // charID -> state
 	objects map[int32]*WorldObject         // objectID -> object
 	npcs    map[int32]*models.NpcInstance  // objectID -> NPC instance
+	items   map[int32]*models.GroundItem   // objectID -> item on the ground
 
 	// Spatial indexing (simple implementation). Keys are packed int64 region
 	// coordinates (see packRegion) rather than "x,y" strings, so grid queries build
@@ -178,6 +210,7 @@ func NewWorldRegistry() *WorldRegistry {
 		players: make(map[int32]*PlayerWorldState),
 		objects: make(map[int32]*WorldObject),
 		npcs:    make(map[int32]*models.NpcInstance),
+		items:   make(map[int32]*models.GroundItem),
 		regions: make(map[int64][]int32),
 		targets: newTargetIndex(),
 	}
@@ -203,6 +236,7 @@ func (wr *WorldRegistry) AddPlayer(ctx context.Context, char *models.Character)
 		IsRunning:   true, // L2J default: players start in running mode
 		KnownNPCs:   make(map[int32]bool),
 		KnownPlayers: make(map[int32]bool),
+		KnownItems:  make(map[int32]bool),
 		SessionData: make(map[string]interface{}),
 	}
 	
@@ -269,6 +303,9 @@ func (wr *WorldRegistry) RemovePlayer(ctx context.Context, charID int32) error {
 	// _activeSoulShots set).
 	GetAutoShotRegistry().Clear(charID)
 
+	// Sold items can only be bought back within the session (L2J PcRefund).
+	GetRefundRegistry().Clear(charID)
+
 	log.Ctx(ctx).Info().
 		Int32("char_id", charID).
 		Str("account", state.AccountName).
@@ -514,6 +551,30 @@ func (wr *WorldRegistry) RemoveNPC(objectID int32) {
 	wr.targets.dropTarget(objectID)
 }
 
+// UpdateNPCPosition moves an NPC, keeping the spatial index current.
+func (wr *WorldRegistry) UpdateNPCPosition(objectID int32, newPos models.Position, heading int32) {
+	wr.mu.Lock()
+	defer wr.mu.Unlock()
+
+	npc, exists := wr.npcs[objectID]
+	if !exists {
+		return
+	}
+
+	oldRegionKey := wr.getRegionKey(npc.Position.X, npc.Position.Y)
+	newRegionKey := wr.getRegionKey(newPos.X, newPos.Y)
+
+	npc.Position = newPos
+	npc.Heading = heading
+	if obj, exists := wr.objects[objectID]; exists {
+		obj.Position = newPos
+	}
+	if oldRegionKey != newRegionKey {
+		wr.removeFromRegion(oldRegionKey, objectID)
+		wr.regions[newRegionKey] = append(wr.regions[newRegionKey], objectID)
+	}
+}
+
 // GetNPC retrieves an NPC instance by object ID.
 func (wr *WorldRegistry) GetNPC(objectID int32) (*models.NpcInstance, bool) {
 	wr.mu.RLock()
@@ -577,6 +638,65 @@ func (wr *WorldRegistry) GetAllNPCs() []*models.NpcInstance {
 	return result
 }
 
+// Ground Item Management
+
+// AddItem puts an item on the ground.
+func (wr *WorldRegistry) AddItem(item *models.GroundItem) {
+	wr.mu.Lock()
+	defer wr.mu.Unlock()
+
+	wr.items[item.ObjectID] = item
+	wr.objects[item.ObjectID] = &WorldObject{
+		ID:       item.ObjectID,
+		Name:     item.GetName(),
+		Type:     ObjectTypeItem,
+		Position: item.Position,
+	}
+	regionKey := wr.getRegionKey(item.Position.X, item.Position.Y)
+	wr.regions[regionKey] = append(wr.regions[regionKey], item.ObjectID)
+}
+
+// RemoveItem takes an item off the ground.
+func (wr *WorldRegistry) RemoveItem(objectID int32) {
+	wr.mu.Lock()
+	defer wr.mu.Unlock()
+
+	item, exists := wr.items[objectID]
+	if !exists {
+		return
+	}
+	wr.removeFromRegion(wr.getRegionKey(item.Position.X, item.Position.Y), objectID)
+	delete(wr.items, objectID)
+	delete(wr.objects, objectID)
+	wr.targets.dropTarget(objectID)
+}
+
+// GetItem retrieves an item on the ground by object ID.
+func (wr *WorldRegistry) GetItem(objectID int32) (*models.GroundItem, bool) {
+	wr.mu.RLock()
+	defer wr.mu.RUnlock()
+
+	item, exists := wr.items[objectID]
+	return item, exists
+}
+
+// GetItemsInRange returns the items on the ground within range of a position.
+func (wr *WorldRegistry) GetItemsInRange(pos models.Position, radius int) []*models.GroundItem {
+	wr.mu.RLock()
+	defer wr.mu.RUnlock()
+
+	var result []*models.GroundItem
+	for _, regionKey := range wr.getNearbyRegions(pos.X, pos.Y, radius) {
+		for _, objectID := range wr.regions[regionKey] {
+			item, isItem := wr.items[objectID]
+			if isItem && wr.calculateDistance(pos, item.Position) <= radius {
+				result = append(result, item)
+			}
+		}
+	}
+	return result
+}
+
 // Statistics
 
 // GetOnlinePlayerCount returns the number of online players
@@ -0,0 +1,245 @@
+package registry
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/rs/zerolog/log"
+
+	"github.com/VerTox/l2go/internal/gameserver/models"
+)
+
+// zoneTileShift sizes the tiles zones are indexed by (2048 units), so a
+// position lookup only tests the zones that overlap its tile.
+const zoneTileShift = 11
+
+// firstDynamicZoneID numbers zones the datapack gives no id (L2J
+// ZoneManager's dynamic ids).
+const firstDynamicZoneID = 300000
+
+// ZoneRegistry holds the world's zones parsed from data/zones/**/*.xml (L2J
+// ZoneManager). Zones are read-only once loaded.
+type ZoneRegistry struct {
+	mu     sync.RWMutex
+	zones  []*models.Zone
+	byName map[string]*models.Zone
+	tiles  map[[2]int][]*models.Zone
+	nextID int32
+	loaded bool
+}
+
+// NewZoneRegistry creates an empty registry.
+func NewZoneRegistry() *ZoneRegistry {
+	return &ZoneRegistry{
+		byName: make(map[string]*models.Zone),
+		tiles:  make(map[[2]int][]*models.Zone),
+		nextID: firstDynamicZoneID,
+	}
+}
+
+// Global zone registry instance.
+var zones = NewZoneRegistry()
+
+// GetZoneRegistry returns the global zone registry.
+func GetZoneRegistry() *ZoneRegistry { return zones }
+
+// XML schema for data/zones/*.xml.
+type xmlZoneList struct {
+	XMLName xml.Name  `xml:"list"`
+	Enabled string    `xml:"enabled,attr"`
+	Zones   []xmlZone `xml:"zone"`
+}
+
+type xmlZone struct {
+	ID    int32         `xml:"id,attr"`
+	Name  string        `xml:"name,attr"`
+	Type  string        `xml:"type,attr"`
+	Shape string        `xml:"shape,attr"`
+	MinZ  int           `xml:"minZ,attr"`
+	MaxZ  int           `xml:"maxZ,attr"`
+	Rad   int           `xml:"rad,attr"`
+	Stats []xmlZoneStat `xml:"stat"`
+	Nodes []xmlZoneNode `xml:"node"`
+}
+
+type xmlZoneStat struct {
+	Name string `xml:"name,attr"`
+	Val  string `xml:"val,attr"`
+}
+
+type xmlZoneNode struct {
+	X int `xml:"X,attr"`
+	Y int `xml:"Y,attr"`
+}
+
+// IsLoaded reports whether zones have been loaded.
+func (r *ZoneRegistry) IsLoaded() bool {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.loaded
+}
+
+// Count returns the number of zones.
+func (r *ZoneRegistry) Count() int {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return len(r.zones)
+}
+
+// Get returns the zone named name; the first one loaded if several share it.
+func (r *ZoneRegistry) Get(name string) (*models.Zone, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	z, ok := r.byName[name]
+	return z, ok
+}
+
+// ZonesAt returns the zones containing pos.
+func (r *ZoneRegistry) ZonesAt(pos models.Position) []*models.Zone {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	var out []*models.Zone
+	for _, z := range r.tiles[zoneTile(pos.X, pos.Y)] {
+		if z.Contains(pos) {
+			out = append(out, z)
+		}
+	}
+	return out
+}
+
+// Add registers a zone, numbering it if it has no id.
+func (r *ZoneRegistry) Add(z *models.Zone) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.add(z)
+}
+
+func (r *ZoneRegistry) add(z *models.Zone) {
+	if z.ID == 0 {
+		z.ID = r.nextID
+		r.nextID++
+	}
+	r.zones = append(r.zones, z)
+	if _, dup := r.byName[z.Name]; z.Name != "" && !dup {
+		r.byName[z.Name] = z
+	}
+	minX, minY, maxX, maxY := z.Shape.Bounds()
+	lo, hi := zoneTile(minX, minY), zoneTile(maxX, maxY)
+	for tx := lo[0]; tx <= hi[0]; tx++ {
+		for ty := lo[1]; ty <= hi[1]; ty++ {
+			r.tiles[[2]int{tx, ty}] = append(r.tiles[[2]int{tx, ty}], z)
+		}
+	}
+}
+
+// zoneTile returns the index tile of a world (x, y). The arithmetic shift
+// floors, so tiles stay 2048 wide on both sides of zero.
+func zoneTile(x, y int) [2]int {
+	return [2]int{x >> zoneTileShift, y >> zoneTileShift}
+}
+
+// LoadFromDirectory parses every *.xml zone file under dir, subdirectories
+// included, adding to the zones already registered. Disabled lists are
+// skipped; a file that fails to parse and a zone with a bad shape are skipped
+// with a warning.
+func (r *ZoneRegistry) LoadFromDirectory(dir string) error {
+	if _, err := os.Stat(dir); err != nil {
+		return fmt.Errorf("read zones dir: %w", err)
+	}
+	var loaded []*models.Zone
+	err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() || filepath.Ext(path) != ".xml" {
+			return err
+		}
+		data, err := os.ReadFile(path)
+		if err != nil {
+			log.Warn().Err(err).Str("file", path).Msg("zones: read failed")
+			return nil
+		}
+		zs, err := parseZoneList(data)
+		if err != nil {
+			log.Warn().Err(err).Str("file", path).Msg("zones: parse failed")
+			return nil
+		}
+		loaded = append(loaded, zs...)
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("walk zones dir: %w", err)
+	}
+
+	r.mu.Lock()
+	for _, z := range loaded {
+		r.add(z)
+	}
+	r.loaded = true
+	r.mu.Unlock()
+	log.Info().Int("zones", len(loaded)).Msg("Loaded zones")
+	return nil
+}
+
+// parseZoneList builds the zones of one file; nil for a disabled list.
+func parseZoneList(data []byte) ([]*models.Zone, error) {
+	var doc xmlZoneList
+	if err := xml.Unmarshal(data, &doc); err != nil {
+		return nil, err
+	}
+	if doc.Enabled == "false" {
+		return nil, nil
+	}
+	var out []*models.Zone
+	for _, xz := range doc.Zones {
+		shape, err := buildZoneShape(xz)
+		if err != nil {
+			log.Warn().Err(err).Str("zone", xz.Name).Msg("zones: bad shape")
+			continue
+		}
+		z := &models.Zone{ID: xz.ID, Name: xz.Name, Type: models.ZoneType(xz.Type), Shape: shape}
+		if len(xz.Stats) > 0 {
+			z.Params = make(map[string]string, len(xz.Stats))
+			for _, st := range xz.Stats {
+				z.Params[st.Name] = st.Val
+			}
+		}
+		out = append(out, z)
+	}
+	return out, nil
+}
+
+// buildZoneShape reads a zone's shape: a Cuboid spans its two corner nodes,
+// a Cylinder is rad around its one node, an NPoly has three nodes or more.
+func buildZoneShape(xz xmlZone) (models.ZoneShape, error) {
+	n := xz.Nodes
+	switch strings.ToLower(xz.Shape) {
+	case "cuboid":
+		if len(n) != 2 {
+			return nil, fmt.Errorf("cuboid needs 2 nodes, has %d", len(n))
+		}
+		return models.ZoneCuboid{
+			MinX: min(n[0].X, n[1].X), MinY: min(n[0].Y, n[1].Y),
+			MaxX: max(n[0].X, n[1].X), MaxY: max(n[0].Y, n[1].Y),
+			MinZ: xz.MinZ, MaxZ: xz.MaxZ,
+		}, nil
+	case "cylinder":
+		if len(n) != 1 || xz.Rad <= 0 {
+			return nil, fmt.Errorf("cylinder needs 1 node and a radius, has %d nodes, rad %d", len(n), xz.Rad)
+		}
+		return models.ZoneCylinder{X: n[0].X, Y: n[0].Y, Radius: xz.Rad, MinZ: xz.MinZ, MaxZ: xz.MaxZ}, nil
+	case "npoly":
+		if len(n) < 3 {
+			return nil, fmt.Errorf("npoly needs 3 nodes or more, has %d", len(n))
+		}
+		p := models.ZoneNPoly{MinZ: xz.MinZ, MaxZ: xz.MaxZ}
+		for _, node := range n {
+			p.Xs = append(p.Xs, node.X)
+			p.Ys = append(p.Ys, node.Y)
+		}
+		return p, nil
+	}
+	return nil, fmt.Errorf("unknown shape %q", xz.Shape)
+}
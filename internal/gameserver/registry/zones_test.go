@@ -0,0 +1,112 @@
+package registry
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/VerTox/l2go/internal/gameserver/models"
+)
+
+const testZones = `<?xml version="1.0" encoding="UTF-8"?>
+<list enabled="true">
+	<zone name="talking_island_peace" type="PeaceZone" shape="NPoly" minZ="-4000" maxZ="-2000">
+		<node X="-85000" Y="240000" />
+		<node X="-80000" Y="240000" />
+		<node X="-80000" Y="246000" />
+		<node X="-85000" Y="246000" />
+	</zone>
+	<zone name="colosseum" id="11012" type="ArenaZone" shape="Cuboid" minZ="-3700" maxZ="-3300">
+		<stat name="spawnX" val="147450" />
+		<node X="146000" Y="45000" />
+		<node X="149000" Y="48000" />
+	</zone>
+	<zone name="pond" type="WaterZone" shape="Cylinder" minZ="-3000" maxZ="-2800" rad="500">
+		<node X="-82000" Y="243000" />
+	</zone>
+	<zone name="broken" type="PeaceZone" shape="NPoly" minZ="0" maxZ="1">
+		<node X="0" Y="0" />
+		<node X="1" Y="1" />
+	</zone>
+</list>`
+
+func TestZones_Load(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(dir, "town"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "town", "peace.xml"), []byte(testZones), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "off.xml"), []byte(`<list enabled="false"><zone name="off" type="PeaceZone" shape="Cylinder" rad="10" minZ="0" maxZ="1"><node X="0" Y="0"/></zone></list>`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	r := NewZoneRegistry()
+	if err := r.LoadFromDirectory(dir); err != nil {
+		t.Fatal(err)
+	}
+	// The two-node polygon and the disabled list are dropped.
+	if !r.IsLoaded() || r.Count() != 3 {
+		t.Fatalf("loaded = %v, count = %d", r.IsLoaded(), r.Count())
+	}
+
+	arena, ok := r.Get("colosseum")
+	if !ok || arena.ID != 11012 || arena.Type != models.ZoneArena || arena.Params["spawnX"] != "147450" {
+		t.Fatalf("colosseum = %+v", arena)
+	}
+	if peace, _ := r.Get("talking_island_peace"); peace.ID < firstDynamicZoneID {
+		t.Errorf("a zone without an id got %d", peace.ID)
+	}
+
+	names := func(pos models.Position) map[string]bool {
+		out := map[string]bool{}
+		for _, z := range r.ZonesAt(pos) {
+			out[z.Name] = true
+		}
+		return out
+	}
+	if got := names(models.Position{X: -82000, Y: 243100, Z: -2900}); !got["talking_island_peace"] || !got["pond"] || len(got) != 2 {
+		t.Errorf("in the pond = %v", got)
+	}
+	if got := names(models.Position{X: -82000, Y: 243100, Z: -3500}); !got["talking_island_peace"] || got["pond"] {
+		t.Errorf("below the pond = %v", got)
+	}
+	if got := names(models.Position{X: 147000, Y: 46000, Z: -3500}); !got["colosseum"] {
+		t.Errorf("in the colosseum = %v", got)
+	}
+	if got := names(models.Position{X: 147000, Y: 46000, Z: -3000}); len(got) != 0 {
+		t.Errorf("above the colosseum = %v", got)
+	}
+}
+
+func TestZones_Missing(t *testing.T) {
+	r := NewZoneRegistry()
+	if err := r.LoadFromDirectory(filepath.Join(t.TempDir(), "nope")); err == nil {
+		t.Fatal("a missing dir must be an error")
+	}
+	if r.IsLoaded() {
+		t.Fatal("loaded after a failure")
+	}
+}
+
+func TestZones_Datapack(t *testing.T) {
+	r := NewZoneRegistry()
+	if err := r.LoadFromDirectory("../../../datapack/zones"); err != nil {
+		t.Skip("datapack zones not available:", err)
+	}
+	has := func(pos models.Position, want models.ZoneType) bool {
+		for _, z := range r.ZonesAt(pos) {
+			if z.Type == want {
+				return true
+			}
+		}
+		return false
+	}
+	// Talking Island's first respawn point is in town; the Colosseum's is in the arena.
+	if !has(models.Position{X: -83990, Y: 243336, Z: -3700}, models.ZoneTown) {
+		t.Error("Talking Island respawn point is not in a town")
+	}
+	if !has(models.Position{X: 147420, Y: 46378, Z: -3350}, models.ZoneArena) {
+		t.Error("Colosseum respawn point is not in the arena")
+	}
+}
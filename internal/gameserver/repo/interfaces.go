@@ -40,15 +40,20 @@ type ItemRepository interface {
 	// Item CRUD operations
 	GetByCharacter(ctx context.Context, charID int32) ([]models.CharacterItem, error)
 	GetByObjectID(ctx context.Context, objectID int32) (*models.CharacterItem, error)
+	// GetByObjectIDForUpdate is GetByObjectID with a row lock; only meaningful
+	// inside a transaction, where it serializes concurrent moves of the same item.
+	GetByObjectIDForUpdate(ctx context.Context, objectID int32) (*models.CharacterItem, error)
 	Create(ctx context.Context, item *models.CharacterItem) error
 	Update(ctx context.Context, item *models.CharacterItem) error
 	Delete(ctx context.Context, objectID int32) error
 	DeleteByCharacter(ctx context.Context, charID int32) error // cleanup when character deleted
 
-	// Item location queries
+	// Item location queries. Clan warehouse rows (LocClanWH) are owned by the
+	// clan: GetWarehouse, FindStackableItem and MoveItem then take the clan id
+	// as owner, and the queries by character never see them.
 	GetInventory(ctx context.Context, charID int32) ([]models.CharacterItem, error)
 	GetPaperdoll(ctx context.Context, charID int32) ([]models.CharacterItem, error)
-	GetWarehouse(ctx context.Context, charID int32, location models.ItemLocation) ([]models.CharacterItem, error)
+	GetWarehouse(ctx context.Context, ownerID int32, location models.ItemLocation) ([]models.CharacterItem, error)
 	GetByItemID(ctx context.Context, charID int32, itemID int32) ([]models.CharacterItem, error)
 
 	// Equipment operations
@@ -59,7 +64,16 @@ type ItemRepository interface {
 	// Inventory management
 	GetInventoryWeight(ctx context.Context, charID int32) (int, error)
 	GetItemCount(ctx context.Context, charID int32, itemID int32) (int64, error)
-	FindStackableItem(ctx context.Context, charID int32, itemID int32, location models.ItemLocation) (*models.CharacterItem, error)
+	FindStackableItem(ctx context.Context, ownerID int32, itemID int32, location models.ItemLocation) (*models.CharacterItem, error)
+	// FindStackableItemForUpdate is FindStackableItem with a row lock, so a
+	// count read from the stack can be written back inside a transaction.
+	FindStackableItemForUpdate(ctx context.Context, ownerID int32, itemID int32, location models.ItemLocation) (*models.CharacterItem, error)
+	// MoveItem hands an item row to a new owner/location without copying it, so
+	// the object id (and everything attached to it) survives the move.
+	MoveItem(ctx context.Context, objectID int32, ownerID int32, location models.ItemLocation, locData int) error
+	// DeleteByLocation deletes every item row at location, whoever owns it,
+	// and returns how many there were.
+	DeleteByLocation(ctx context.Context, location models.ItemLocation) (int64, error)
 }
 
 // SkillRepository defines the interface for character skills data access
@@ -118,6 +132,46 @@ type RecipeRepository interface {
 	DeleteByCharacter(ctx context.Context, charID int32) error
 }
 
+// OfflineTradeRepository defines the interface for offline private store data
+// access (L2J character_offline_trade).
+type OfflineTradeRepository interface {
+	// GetAll returns every persisted offline store with its lines.
+	GetAll(ctx context.Context) ([]models.OfflineTrade, error)
+	// Save stores a character's offline store, replacing any previous one.
+	Save(ctx context.Context, trade *models.OfflineTrade) error
+	// Delete removes a character's offline store.
+	Delete(ctx context.Context, charID int32) error
+	// DeleteAll removes every offline store (offline trade switched off).
+	DeleteAll(ctx context.Context) error
+}
+
+// QuestRepository defines the interface for per-character quest state data
+// access (L2J character_quests). A quest's variables are saved as a whole.
+type QuestRepository interface {
+	// GetByCharacter returns every variable of every quest a character has.
+	GetByCharacter(ctx context.Context, charID int32) ([]models.CharacterQuestVar, error)
+	// Save stores a quest's variables, replacing the ones saved before.
+	Save(ctx context.Context, charID int32, questName string, vars map[string]string) error
+	// Delete forgets a quest of a character.
+	Delete(ctx context.Context, charID int32, questName string) error
+}
+
+// RaidBossRepository defines the interface for raid boss data access (L2J
+// raidboss_spawnlist and character_raid_points).
+type RaidBossRepository interface {
+	// GetAll returns the saved state of every raid boss.
+	GetAll(ctx context.Context) ([]models.RaidBossStatus, error)
+	// Save stores a raid boss's state, replacing the one saved before.
+	Save(ctx context.Context, status *models.RaidBossStatus) error
+	// AddPoints adds raid points a character earned on a boss.
+	AddPoints(ctx context.Context, charID, bossID, points int32) error
+	// GetPoints returns a character's raid points per boss.
+	GetPoints(ctx context.Context, charID int32) ([]models.CharacterRaidPoints, error)
+	// GetRanking returns a character's place by total raid points, 1 being
+	// the most; 0 when it has none.
+	GetRanking(ctx context.Context, charID int32) (int32, error)
+}
+
 // SpawnRepository defines the interface for NPC spawnlist data access
 type SpawnRepository interface {
 	// GetAll returns all spawn entries from the database
@@ -136,6 +190,7 @@ type Repository struct {
 	Shortcut  ShortcutRepository
 	Recipe    RecipeRepository
 	Spawn     SpawnRepository
+	Offline   OfflineTradeRepository
 }
 
 // Transaction defines transaction interface for atomic operations
@@ -149,6 +204,8 @@ type Transaction interface {
 	Item() ItemRepository
 	Skill() SkillRepository
 	Shortcut() ShortcutRepository
+	Quest() QuestRepository
+	RaidBoss() RaidBossRepository
 }
 
 // TransactionManager defines interface for transaction management
@@ -178,4 +235,7 @@ type DatabaseRepository interface {
 	Shortcut() ShortcutRepository
 	Recipe() RecipeRepository
 	Spawn() SpawnRepository
+	OfflineTrade() OfflineTradeRepository
+	Quest() QuestRepository
+	RaidBoss() RaidBossRepository
 }
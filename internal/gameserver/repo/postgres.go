@@ -23,6 +23,9 @@ type PostgreSQLRepository struct {
 	shortcut *ShortcutRepositoryImpl
 	recipe   *RecipeRepositoryImpl
 	spawn    *SpawnRepositoryImpl
+	offline  *OfflineTradeRepositoryImpl
+	quest    *QuestRepositoryImpl
+	raidBoss *RaidBossRepositoryImpl
 }
 
 // NewPostgreSQLRepository creates a new PostgreSQL repository
@@ -35,16 +38,22 @@ func NewPostgreSQLRepository(db *pgxpool.Pool) DatabaseRepository {
 		shortcut: NewShortcutRepository(db),
 		recipe:   NewRecipeRepository(db),
 		spawn:    NewSpawnRepository(db),
+		offline:  NewOfflineTradeRepository(db),
+		quest:    NewQuestRepository(db),
+		raidBoss: NewRaidBossRepository(db),
 	}
 }
 
 // Repository access methods
-func (r *PostgreSQLRepository) Character() CharacterRepository { return r.char }
-func (r *PostgreSQLRepository) Item() ItemRepository           { return r.item }
-func (r *PostgreSQLRepository) Skill() SkillRepository         { return r.skill }
-func (r *PostgreSQLRepository) Shortcut() ShortcutRepository   { return r.shortcut }
-func (r *PostgreSQLRepository) Recipe() RecipeRepository       { return r.recipe }
-func (r *PostgreSQLRepository) Spawn() SpawnRepository         { return r.spawn }
+func (r *PostgreSQLRepository) Character() CharacterRepository       { return r.char }
+func (r *PostgreSQLRepository) Item() ItemRepository                 { return r.item }
+func (r *PostgreSQLRepository) Skill() SkillRepository               { return r.skill }
+func (r *PostgreSQLRepository) Shortcut() ShortcutRepository         { return r.shortcut }
+func (r *PostgreSQLRepository) Recipe() RecipeRepository             { return r.recipe }
+func (r *PostgreSQLRepository) Spawn() SpawnRepository               { return r.spawn }
+func (r *PostgreSQLRepository) OfflineTrade() OfflineTradeRepository { return r.offline }
+func (r *PostgreSQLRepository) Quest() QuestRepository               { return r.quest }
+func (r *PostgreSQLRepository) RaidBoss() RaidBossRepository         { return r.raidBoss }
 
 // Transaction implementation
 type PostgreSQLTransaction struct {
@@ -53,6 +62,8 @@ type PostgreSQLTransaction struct {
 	item     *ItemRepositoryImpl
 	skill    *SkillRepositoryImpl
 	shortcut *ShortcutRepositoryImpl
+	quest    *QuestRepositoryImpl
+	raidBoss *RaidBossRepositoryImpl
 }
 
 func (t *PostgreSQLTransaction) Commit(ctx context.Context) error   { return t.tx.Commit(ctx) }
@@ -61,6 +72,8 @@ func (t *PostgreSQLTransaction) Character() CharacterRepository     { return t.c
 func (t *PostgreSQLTransaction) Item() ItemRepository               { return t.item }
 func (t *PostgreSQLTransaction) Skill() SkillRepository             { return t.skill }
 func (t *PostgreSQLTransaction) Shortcut() ShortcutRepository       { return t.shortcut }
+func (t *PostgreSQLTransaction) Quest() QuestRepository             { return t.quest }
+func (t *PostgreSQLTransaction) RaidBoss() RaidBossRepository       { return t.raidBoss }
 
 // BeginTransaction starts a new database transaction
 func (r *PostgreSQLRepository) BeginTransaction(ctx context.Context) (Transaction, error) {
@@ -75,6 +88,8 @@ func (r *PostgreSQLRepository) BeginTransaction(ctx context.Context) (Transactio
 		item:     NewItemRepositoryTx(tx),
 		skill:    NewSkillRepositoryTx(tx),
 		shortcut: NewShortcutRepositoryTx(tx),
+		quest:    NewQuestRepositoryTx(tx),
+		raidBoss: NewRaidBossRepositoryTx(tx),
 	}, nil
 }
 
@@ -117,6 +132,7 @@ type pgxDB interface {
 	Query(ctx context.Context, sql string, args ...any) (pgx.Rows, error)
 	QueryRow(ctx context.Context, sql string, args ...any) pgx.Row
 	Exec(ctx context.Context, sql string, args ...any) (pgconn.CommandTag, error)
+	Begin(ctx context.Context) (pgx.Tx, error)
 }
 
 // NewCharacterRepository creates a character repository with pool
@@ -24,16 +24,36 @@ func NewItemRepositoryTx(tx pgx.Tx) *ItemRepositoryImpl {
 	return &ItemRepositoryImpl{db: tx}
 }
 
-// GetByCharacter retrieves all items for a character
+// itemOwner splits an item's owner into the column it is stored in: clan
+// warehouse rows belong to a clan (clan_id), every other row to a character
+// (owner_id, a foreign key to characters). The other column stays NULL, so a
+// clan id never reads as the character with the same id.
+func itemOwner(ownerID int32, location models.ItemLocation) (charID, clanID *int32) {
+	if location == models.LocClanWH {
+		return nil, &ownerID
+	}
+	return &ownerID, nil
+}
+
+// ownerColumn is the column itemOwner stores an owner at location in.
+func ownerColumn(location models.ItemLocation) string {
+	if location == models.LocClanWH {
+		return "clan_id"
+	}
+	return "owner_id"
+}
+
+// GetByCharacter retrieves all items a character carries (inventory + paperdoll).
+// Warehouse items are left out.
 func (r *ItemRepositoryImpl) GetByCharacter(ctx context.Context, charID int32) ([]models.CharacterItem, error) {
 	query := `
-		SELECT object_id, owner_id, item_id, count, loc, loc_data, enchant_level, created_at,
+		SELECT object_id, COALESCE(owner_id, clan_id), item_id, count, loc, loc_data, enchant_level, created_at,
 			   custom_type1, custom_type2, mana_left, time, augmentation_id,
 			   augmentation_skill1, augmentation_skill2, attribute_fire, attribute_water,
 			   attribute_wind, attribute_earth, attribute_holy, attribute_dark,
 			   visual_id, is_blessed, is_protected
 		FROM character_items 
-		WHERE owner_id = $1 
+		WHERE owner_id = $1 AND loc IN ('INVENTORY', 'PAPERDOLL')
 		ORDER BY loc, loc_data, object_id`
 
 	rows, err := r.db.Query(ctx, query, charID)
@@ -68,7 +88,7 @@ func (r *ItemRepositoryImpl) GetByCharacter(ctx context.Context, charID int32) (
 // GetByObjectID retrieves an item by object ID
 func (r *ItemRepositoryImpl) GetByObjectID(ctx context.Context, objectID int32) (*models.CharacterItem, error) {
 	query := `
-		SELECT object_id, owner_id, item_id, count, loc, loc_data, enchant_level, created_at,
+		SELECT object_id, COALESCE(owner_id, clan_id), item_id, count, loc, loc_data, enchant_level, created_at,
 			   custom_type1, custom_type2, mana_left, time, augmentation_id,
 			   augmentation_skill1, augmentation_skill2, attribute_fire, attribute_water,
 			   attribute_wind, attribute_earth, attribute_holy, attribute_dark,
@@ -98,21 +118,57 @@ func (r *ItemRepositoryImpl) GetByObjectID(ctx context.Context, objectID int32)
 	return &item, nil
 }
 
-// Create creates a new item
+// GetByObjectIDForUpdate retrieves an item by object ID and locks its row until
+// the surrounding transaction ends
+func (r *ItemRepositoryImpl) GetByObjectIDForUpdate(ctx context.Context, objectID int32) (*models.CharacterItem, error) {
+	query := `
+		SELECT object_id, COALESCE(owner_id, clan_id), item_id, count, loc, loc_data, enchant_level, created_at,
+			   custom_type1, custom_type2, mana_left, time, augmentation_id,
+			   augmentation_skill1, augmentation_skill2, attribute_fire, attribute_water,
+			   attribute_wind, attribute_earth, attribute_holy, attribute_dark,
+			   visual_id, is_blessed, is_protected
+		FROM character_items 
+		WHERE object_id = $1
+		FOR UPDATE`
+
+	var item models.CharacterItem
+
+	err := r.db.QueryRow(ctx, query, objectID).Scan(
+		&item.ObjectID, &item.OwnerID, &item.ItemID, &item.Count,
+		&item.Loc, &item.LocData, &item.EnchantLevel, &item.CreatedAt,
+		&item.CustomType1, &item.CustomType2, &item.ManaLeft, &item.Time,
+		&item.AugmentationID, &item.AugmentationSkill1, &item.AugmentationSkill2,
+		&item.AttributeFire, &item.AttributeWater, &item.AttributeWind,
+		&item.AttributeEarth, &item.AttributeHoly, &item.AttributeDark,
+		&item.VisualID, &item.IsBlessed, &item.IsProtected,
+	)
+
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to lock item by object ID: %w", err)
+	}
+
+	return &item, nil
+}
+
+// Create creates a new item; for a clan warehouse item OwnerID is the clan
 func (r *ItemRepositoryImpl) Create(ctx context.Context, item *models.CharacterItem) error {
 	query := `
 		INSERT INTO character_items (
-			owner_id, item_id, count, loc, loc_data, enchant_level,
+			owner_id, clan_id, item_id, count, loc, loc_data, enchant_level,
 			custom_type1, custom_type2, mana_left, time, augmentation_id,
 			augmentation_skill1, augmentation_skill2, attribute_fire, attribute_water,
 			attribute_wind, attribute_earth, attribute_holy, attribute_dark,
 			visual_id, is_blessed, is_protected
 		) VALUES (
-			$1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18, $19, $20, $21, $22
+			$1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18, $19, $20, $21, $22, $23
 		) RETURNING object_id, created_at`
 
+	charID, clanID := itemOwner(item.OwnerID, models.ItemLocation(item.Loc))
 	err := r.db.QueryRow(ctx, query,
-		item.OwnerID, item.ItemID, item.Count, item.Loc, item.LocData,
+		charID, clanID, item.ItemID, item.Count, item.Loc, item.LocData,
 		item.EnchantLevel, item.CustomType1, item.CustomType2, item.ManaLeft,
 		item.Time, item.AugmentationID, item.AugmentationSkill1,
 		item.AugmentationSkill2, item.AttributeFire, item.AttributeWater,
@@ -176,7 +232,7 @@ func (r *ItemRepositoryImpl) DeleteByCharacter(ctx context.Context, charID int32
 // GetInventory retrieves items in inventory location
 func (r *ItemRepositoryImpl) GetInventory(ctx context.Context, charID int32) ([]models.CharacterItem, error) {
 	query := `
-		SELECT object_id, owner_id, item_id, count, loc, loc_data, enchant_level, created_at,
+		SELECT object_id, COALESCE(owner_id, clan_id), item_id, count, loc, loc_data, enchant_level, created_at,
 			   custom_type1, custom_type2, mana_left, time, augmentation_id,
 			   augmentation_skill1, augmentation_skill2, attribute_fire, attribute_water,
 			   attribute_wind, attribute_earth, attribute_holy, attribute_dark,
@@ -217,7 +273,7 @@ func (r *ItemRepositoryImpl) GetInventory(ctx context.Context, charID int32) ([]
 // GetPaperdoll retrieves equipped items (paperdoll)
 func (r *ItemRepositoryImpl) GetPaperdoll(ctx context.Context, charID int32) ([]models.CharacterItem, error) {
 	query := `
-		SELECT object_id, owner_id, item_id, count, loc, loc_data, enchant_level, created_at,
+		SELECT object_id, COALESCE(owner_id, clan_id), item_id, count, loc, loc_data, enchant_level, created_at,
 			   custom_type1, custom_type2, mana_left, time, augmentation_id,
 			   augmentation_skill1, augmentation_skill2, attribute_fire, attribute_water,
 			   attribute_wind, attribute_earth, attribute_holy, attribute_dark,
@@ -255,19 +311,20 @@ func (r *ItemRepositoryImpl) GetPaperdoll(ctx context.Context, charID int32) ([]
 	return items, rows.Err()
 }
 
-// GetWarehouse retrieves items in warehouse locations
-func (r *ItemRepositoryImpl) GetWarehouse(ctx context.Context, charID int32, location models.ItemLocation) ([]models.CharacterItem, error) {
+// GetWarehouse retrieves items in warehouse locations; for LocClanWH ownerID is
+// the clan
+func (r *ItemRepositoryImpl) GetWarehouse(ctx context.Context, ownerID int32, location models.ItemLocation) ([]models.CharacterItem, error) {
 	query := `
-		SELECT object_id, owner_id, item_id, count, loc, loc_data, enchant_level, created_at,
+		SELECT object_id, COALESCE(owner_id, clan_id), item_id, count, loc, loc_data, enchant_level, created_at,
 			   custom_type1, custom_type2, mana_left, time, augmentation_id,
 			   augmentation_skill1, augmentation_skill2, attribute_fire, attribute_water,
 			   attribute_wind, attribute_earth, attribute_holy, attribute_dark,
 			   visual_id, is_blessed, is_protected
 		FROM character_items 
-		WHERE owner_id = $1 AND loc = $2
+		WHERE ` + ownerColumn(location) + ` = $1 AND loc = $2
 		ORDER BY object_id`
 
-	rows, err := r.db.Query(ctx, query, charID, string(location))
+	rows, err := r.db.Query(ctx, query, ownerID, string(location))
 	if err != nil {
 		return nil, fmt.Errorf("failed to query warehouse items: %w", err)
 	}
@@ -296,16 +353,16 @@ func (r *ItemRepositoryImpl) GetWarehouse(ctx context.Context, charID int32, loc
 	return items, rows.Err()
 }
 
-// GetByItemID retrieves all items with specified item template ID
+// GetByItemID retrieves all items with specified item template ID a character carries
 func (r *ItemRepositoryImpl) GetByItemID(ctx context.Context, charID int32, itemID int32) ([]models.CharacterItem, error) {
 	query := `
-		SELECT object_id, owner_id, item_id, count, loc, loc_data, enchant_level, created_at,
+		SELECT object_id, COALESCE(owner_id, clan_id), item_id, count, loc, loc_data, enchant_level, created_at,
 			   custom_type1, custom_type2, mana_left, time, augmentation_id,
 			   augmentation_skill1, augmentation_skill2, attribute_fire, attribute_water,
 			   attribute_wind, attribute_earth, attribute_holy, attribute_dark,
 			   visual_id, is_blessed, is_protected
 		FROM character_items 
-		WHERE owner_id = $1 AND item_id = $2
+		WHERE owner_id = $1 AND item_id = $2 AND loc IN ('INVENTORY', 'PAPERDOLL')
 		ORDER BY object_id`
 
 	rows, err := r.db.Query(ctx, query, charID, itemID)
@@ -340,7 +397,7 @@ func (r *ItemRepositoryImpl) GetByItemID(ctx context.Context, charID int32, item
 // GetEquippedItem retrieves item equipped in specific paperdoll slot
 func (r *ItemRepositoryImpl) GetEquippedItem(ctx context.Context, charID int32, slot models.PaperdollSlot) (*models.CharacterItem, error) {
 	query := `
-		SELECT object_id, owner_id, item_id, count, loc, loc_data, enchant_level, created_at,
+		SELECT object_id, COALESCE(owner_id, clan_id), item_id, count, loc, loc_data, enchant_level, created_at,
 			   custom_type1, custom_type2, mana_left, time, augmentation_id,
 			   augmentation_skill1, augmentation_skill2, attribute_fire, attribute_water,
 			   attribute_wind, attribute_earth, attribute_holy, attribute_dark,
@@ -406,11 +463,11 @@ func (r *ItemRepositoryImpl) GetInventoryWeight(ctx context.Context, charID int3
 	return weight, nil
 }
 
-// GetItemCount returns total count of specific item type
+// GetItemCount returns total count of specific item type a character carries
 func (r *ItemRepositoryImpl) GetItemCount(ctx context.Context, charID int32, itemID int32) (int64, error) {
 	var count int64
 	err := r.db.QueryRow(ctx,
-		"SELECT COALESCE(SUM(count), 0) FROM character_items WHERE owner_id = $1 AND item_id = $2",
+		"SELECT COALESCE(SUM(count), 0) FROM character_items WHERE owner_id = $1 AND item_id = $2 AND loc IN ('INVENTORY', 'PAPERDOLL')",
 		charID, itemID).Scan(&count)
 	if err != nil {
 		return 0, fmt.Errorf("failed to get item count: %w", err)
@@ -418,22 +475,33 @@ func (r *ItemRepositoryImpl) GetItemCount(ctx context.Context, charID int32, ite
 	return count, nil
 }
 
-// FindStackableItem finds existing stackable item to add count to
-func (r *ItemRepositoryImpl) FindStackableItem(ctx context.Context, charID int32, itemID int32, location models.ItemLocation) (*models.CharacterItem, error) {
+// FindStackableItem finds existing stackable item to add count to; for
+// LocClanWH ownerID is the clan
+func (r *ItemRepositoryImpl) FindStackableItem(ctx context.Context, ownerID int32, itemID int32, location models.ItemLocation) (*models.CharacterItem, error) {
+	return r.findStackableItem(ctx, ownerID, itemID, location, "")
+}
+
+// FindStackableItemForUpdate finds the stack like FindStackableItem and locks
+// its row until the surrounding transaction ends
+func (r *ItemRepositoryImpl) FindStackableItemForUpdate(ctx context.Context, ownerID int32, itemID int32, location models.ItemLocation) (*models.CharacterItem, error) {
+	return r.findStackableItem(ctx, ownerID, itemID, location, " FOR UPDATE")
+}
+
+func (r *ItemRepositoryImpl) findStackableItem(ctx context.Context, ownerID int32, itemID int32, location models.ItemLocation, lock string) (*models.CharacterItem, error) {
 	query := `
-		SELECT object_id, owner_id, item_id, count, loc, loc_data, enchant_level, created_at,
+		SELECT object_id, COALESCE(owner_id, clan_id), item_id, count, loc, loc_data, enchant_level, created_at,
 			   custom_type1, custom_type2, mana_left, time, augmentation_id,
 			   augmentation_skill1, augmentation_skill2, attribute_fire, attribute_water,
 			   attribute_wind, attribute_earth, attribute_holy, attribute_dark,
 			   visual_id, is_blessed, is_protected
 		FROM character_items 
-		WHERE owner_id = $1 AND item_id = $2 AND loc = $3 AND enchant_level = 0 AND augmentation_id = 0
+		WHERE ` + ownerColumn(location) + ` = $1 AND item_id = $2 AND loc = $3 AND enchant_level = 0 AND augmentation_id = 0
 		ORDER BY count DESC
-		LIMIT 1`
+		LIMIT 1` + lock
 
 	var item models.CharacterItem
 
-	err := r.db.QueryRow(ctx, query, charID, itemID, string(location)).Scan(
+	err := r.db.QueryRow(ctx, query, ownerID, itemID, string(location)).Scan(
 		&item.ObjectID, &item.OwnerID, &item.ItemID, &item.Count,
 		&item.Loc, &item.LocData, &item.EnchantLevel, &item.CreatedAt,
 		&item.CustomType1, &item.CustomType2, &item.ManaLeft, &item.Time,
@@ -451,4 +519,26 @@ func (r *ItemRepositoryImpl) FindStackableItem(ctx context.Context, charID int32
 	}
 
 	return &item, nil
-}
\ No newline at end of file
+}
+
+// MoveItem changes an item's owner and location in place; for LocClanWH
+// ownerID is the clan
+func (r *ItemRepositoryImpl) MoveItem(ctx context.Context, objectID int32, ownerID int32, location models.ItemLocation, locData int) error {
+	charID, clanID := itemOwner(ownerID, location)
+	_, err := r.db.Exec(ctx,
+		"UPDATE character_items SET owner_id = $2, clan_id = $3, loc = $4, loc_data = $5 WHERE object_id = $1",
+		objectID, charID, clanID, string(location), locData)
+	if err != nil {
+		return fmt.Errorf("failed to move item: %w", err)
+	}
+	return nil
+}
+
+// DeleteByLocation deletes every item row at location, whoever owns it.
+func (r *ItemRepositoryImpl) DeleteByLocation(ctx context.Context, location models.ItemLocation) (int64, error) {
+	tag, err := r.db.Exec(ctx, "DELETE FROM character_items WHERE loc = $1", string(location))
+	if err != nil {
+		return 0, fmt.Errorf("failed to delete items at %s: %w", location, err)
+	}
+	return tag.RowsAffected(), nil
+}
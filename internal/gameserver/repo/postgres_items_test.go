@@ -0,0 +1,100 @@
+package repo
+
+import (
+	"context"
+	"os"
+	"testing"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/stretchr/testify/require"
+
+	"github.com/VerTox/l2go/internal/gameserver/models"
+	"github.com/VerTox/l2go/internal/gameserver/schema"
+)
+
+func TestItemOwner_ClanWarehouseRowsBelongToTheClan(t *testing.T) {
+	charID, clanID := itemOwner(7, models.LocClanWH)
+	require.Nil(t, charID)
+	require.Equal(t, int32(7), *clanID)
+	require.Equal(t, "clan_id", ownerColumn(models.LocClanWH))
+
+	for _, loc := range []models.ItemLocation{models.LocInventory, models.LocWarehouse} {
+		charID, clanID := itemOwner(7, loc)
+		require.Equal(t, int32(7), *charID, loc)
+		require.Nil(t, clanID, loc)
+		require.Equal(t, "owner_id", ownerColumn(loc), loc)
+	}
+}
+
+// TestItemRepository_ClanIDEqualToCharacterID stores a clan warehouse item for
+// a clan whose id is also a character's and checks that none of the
+// character's queries sees it.
+func TestItemRepository_ClanIDEqualToCharacterID(t *testing.T) {
+	dbURL := os.Getenv("TEST_DATABASE_URL")
+	if dbURL == "" {
+		t.Skip("TEST_DATABASE_URL not set, skipping integration tests")
+	}
+	ctx := context.Background()
+	db, err := pgxpool.New(ctx, dbURL)
+	require.NoError(t, err, "Failed to connect to test database")
+	defer db.Close()
+	require.NoError(t, schema.NewMigrationManager(db).Migrate(ctx))
+
+	var charID int32
+	err = db.QueryRow(ctx, `
+		INSERT INTO characters (account_name, char_name, race, class_id, base_class, x, y, z)
+		VALUES ('itemrepotest', 'itemrepotest', 0, 0, 0, 0, 0, 0)
+		RETURNING char_id
+	`).Scan(&charID)
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		_, _ = db.Exec(ctx, "DELETE FROM character_items WHERE clan_id = $1", charID)
+		_, _ = db.Exec(ctx, "DELETE FROM characters WHERE char_id = $1", charID)
+	})
+
+	items := NewItemRepository(db)
+	carried := &models.CharacterItem{OwnerID: charID, ItemID: models.ItemIDAdena, Count: 100, Loc: string(models.LocInventory), LocData: -1}
+	require.NoError(t, items.Create(ctx, carried))
+	clanStack := &models.CharacterItem{OwnerID: charID, ItemID: models.ItemIDAdena, Count: 5000, Loc: string(models.LocClanWH), LocData: -1}
+	require.NoError(t, items.Create(ctx, clanStack))
+
+	all, err := items.GetByCharacter(ctx, charID)
+	require.NoError(t, err)
+	require.Len(t, all, 1)
+	require.Equal(t, carried.ObjectID, all[0].ObjectID)
+
+	byItem, err := items.GetByItemID(ctx, charID, models.ItemIDAdena)
+	require.NoError(t, err)
+	require.Len(t, byItem, 1)
+
+	count, err := items.GetItemCount(ctx, charID, models.ItemIDAdena)
+	require.NoError(t, err)
+	require.Equal(t, int64(100), count)
+
+	stored, err := items.GetWarehouse(ctx, charID, models.LocClanWH)
+	require.NoError(t, err)
+	require.Len(t, stored, 1)
+	require.Equal(t, clanStack.ObjectID, stored[0].ObjectID)
+	require.Equal(t, charID, stored[0].OwnerID)
+
+	stack, err := items.FindStackableItem(ctx, charID, models.ItemIDAdena, models.LocClanWH)
+	require.NoError(t, err)
+	require.Equal(t, clanStack.ObjectID, stack.ObjectID)
+
+	// Deposited into and withdrawn from the clan warehouse in place.
+	require.NoError(t, items.MoveItem(ctx, carried.ObjectID, charID, models.LocClanWH, -1))
+	all, err = items.GetByCharacter(ctx, charID)
+	require.NoError(t, err)
+	require.Empty(t, all)
+	require.NoError(t, items.MoveItem(ctx, clanStack.ObjectID, charID, models.LocInventory, -1))
+	count, err = items.GetItemCount(ctx, charID, models.ItemIDAdena)
+	require.NoError(t, err)
+	require.Equal(t, int64(5000), count)
+
+	// The character's deletion leaves the clan's items alone.
+	require.NoError(t, items.DeleteByCharacter(ctx, charID))
+	stored, err = items.GetWarehouse(ctx, charID, models.LocClanWH)
+	require.NoError(t, err)
+	require.Len(t, stored, 1)
+	require.Equal(t, carried.ObjectID, stored[0].ObjectID)
+}
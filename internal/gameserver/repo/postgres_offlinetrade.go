@@ -0,0 +1,111 @@
+package repo
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/VerTox/l2go/internal/gameserver/models"
+)
+
+// OfflineTradeRepositoryImpl implements OfflineTradeRepository using PostgreSQL.
+type OfflineTradeRepositoryImpl struct {
+	db *pgxpool.Pool
+}
+
+// NewOfflineTradeRepository creates a new offline trade repository.
+func NewOfflineTradeRepository(db *pgxpool.Pool) *OfflineTradeRepositoryImpl {
+	return &OfflineTradeRepositoryImpl{db: db}
+}
+
+// GetAll returns every persisted offline store with its lines.
+func (r *OfflineTradeRepositoryImpl) GetAll(ctx context.Context) ([]models.OfflineTrade, error) {
+	rows, err := r.db.Query(ctx,
+		`SELECT char_id, store_type, title, started_at
+		 FROM character_offline_trade
+		 ORDER BY char_id`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query offline stores: %w", err)
+	}
+	var trades []models.OfflineTrade
+	byChar := make(map[int32]int)
+	for rows.Next() {
+		var t models.OfflineTrade
+		if err := rows.Scan(&t.CharID, &t.StoreType, &t.Title, &t.StartedAt); err != nil {
+			rows.Close()
+			return nil, fmt.Errorf("failed to scan offline store: %w", err)
+		}
+		byChar[t.CharID] = len(trades)
+		trades = append(trades, t)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read offline stores: %w", err)
+	}
+
+	rows, err = r.db.Query(ctx,
+		`SELECT char_id, item, enchant, count, price
+		 FROM character_offline_trade_items`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query offline store items: %w", err)
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var (
+			charID int32
+			it     models.OfflineTradeItem
+		)
+		if err := rows.Scan(&charID, &it.Item, &it.Enchant, &it.Count, &it.Price); err != nil {
+			return nil, fmt.Errorf("failed to scan offline store item: %w", err)
+		}
+		if i, ok := byChar[charID]; ok {
+			trades[i].Items = append(trades[i].Items, it)
+		}
+	}
+	return trades, rows.Err()
+}
+
+// Save stores a character's offline store, replacing the lines of any previous
+// one, in one transaction so a restart never sees a half-written store. The
+// original started_at of a store that is saved again is kept.
+func (r *OfflineTradeRepositoryImpl) Save(ctx context.Context, trade *models.OfflineTrade) error {
+	return pgx.BeginFunc(ctx, r.db, func(tx pgx.Tx) error {
+		if _, err := tx.Exec(ctx,
+			`INSERT INTO character_offline_trade (char_id, store_type, title)
+			 VALUES ($1, $2, $3)
+			 ON CONFLICT (char_id) DO UPDATE SET store_type = EXCLUDED.store_type, title = EXCLUDED.title`,
+			trade.CharID, trade.StoreType, trade.Title); err != nil {
+			return fmt.Errorf("failed to upsert offline store: %w", err)
+		}
+		if _, err := tx.Exec(ctx, `DELETE FROM character_offline_trade_items WHERE char_id = $1`, trade.CharID); err != nil {
+			return fmt.Errorf("failed to clear offline store items: %w", err)
+		}
+		for _, it := range trade.Items {
+			if _, err := tx.Exec(ctx,
+				`INSERT INTO character_offline_trade_items (char_id, item, enchant, count, price)
+				 VALUES ($1, $2, $3, $4, $5)`,
+				trade.CharID, it.Item, it.Enchant, it.Count, it.Price); err != nil {
+				return fmt.Errorf("failed to insert offline store item: %w", err)
+			}
+		}
+		return nil
+	})
+}
+
+// Delete removes a character's offline store; its lines cascade.
+func (r *OfflineTradeRepositoryImpl) Delete(ctx context.Context, charID int32) error {
+	if _, err := r.db.Exec(ctx, `DELETE FROM character_offline_trade WHERE char_id = $1`, charID); err != nil {
+		return fmt.Errorf("failed to delete offline store: %w", err)
+	}
+	return nil
+}
+
+// DeleteAll removes every offline store.
+func (r *OfflineTradeRepositoryImpl) DeleteAll(ctx context.Context) error {
+	if _, err := r.db.Exec(ctx, `DELETE FROM character_offline_trade`); err != nil {
+		return fmt.Errorf("failed to delete offline stores: %w", err)
+	}
+	return nil
+}
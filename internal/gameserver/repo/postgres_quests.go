@@ -0,0 +1,81 @@
+package repo
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/VerTox/l2go/internal/gameserver/models"
+)
+
+// QuestRepositoryImpl implements QuestRepository using PostgreSQL.
+type QuestRepositoryImpl struct {
+	db pgxDB
+}
+
+// NewQuestRepository creates a new quest repository.
+func NewQuestRepository(db *pgxpool.Pool) *QuestRepositoryImpl {
+	return &QuestRepositoryImpl{db: db}
+}
+
+// NewQuestRepositoryTx creates a quest repository with transaction.
+func NewQuestRepositoryTx(tx pgx.Tx) *QuestRepositoryImpl {
+	return &QuestRepositoryImpl{db: tx}
+}
+
+// GetByCharacter returns every variable of every quest a character has.
+func (r *QuestRepositoryImpl) GetByCharacter(ctx context.Context, charID int32) ([]models.CharacterQuestVar, error) {
+	rows, err := r.db.Query(ctx,
+		`SELECT char_id, quest_name, var, value
+		 FROM character_quests
+		 WHERE char_id = $1
+		 ORDER BY quest_name, var`, charID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query character quests: %w", err)
+	}
+	defer rows.Close()
+
+	var vars []models.CharacterQuestVar
+	for rows.Next() {
+		var v models.CharacterQuestVar
+		if err := rows.Scan(&v.CharID, &v.QuestName, &v.Var, &v.Value); err != nil {
+			return nil, fmt.Errorf("failed to scan character quest: %w", err)
+		}
+		vars = append(vars, v)
+	}
+	return vars, rows.Err()
+}
+
+// Save stores a quest's variables, replacing the ones saved before, in one
+// transaction (a savepoint inside an outer one) so a crash never leaves half
+// a quest behind.
+func (r *QuestRepositoryImpl) Save(ctx context.Context, charID int32, questName string, vars map[string]string) error {
+	return pgx.BeginFunc(ctx, r.db, func(tx pgx.Tx) error {
+		if _, err := tx.Exec(ctx,
+			`DELETE FROM character_quests WHERE char_id = $1 AND quest_name = $2`,
+			charID, questName); err != nil {
+			return fmt.Errorf("failed to clear quest variables: %w", err)
+		}
+		for name, value := range vars {
+			if _, err := tx.Exec(ctx,
+				`INSERT INTO character_quests (char_id, quest_name, var, value)
+				 VALUES ($1, $2, $3, $4)`,
+				charID, questName, name, value); err != nil {
+				return fmt.Errorf("failed to insert quest variable: %w", err)
+			}
+		}
+		return nil
+	})
+}
+
+// Delete forgets a quest of a character.
+func (r *QuestRepositoryImpl) Delete(ctx context.Context, charID int32, questName string) error {
+	if _, err := r.db.Exec(ctx,
+		`DELETE FROM character_quests WHERE char_id = $1 AND quest_name = $2`,
+		charID, questName); err != nil {
+		return fmt.Errorf("failed to delete quest: %w", err)
+	}
+	return nil
+}
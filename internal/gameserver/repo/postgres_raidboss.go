@@ -0,0 +1,132 @@
+package repo
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/VerTox/l2go/internal/gameserver/models"
+)
+
+// RaidBossRepositoryImpl implements RaidBossRepository using PostgreSQL.
+type RaidBossRepositoryImpl struct {
+	db pgxDB
+}
+
+// NewRaidBossRepository creates a new raid boss repository.
+func NewRaidBossRepository(db *pgxpool.Pool) *RaidBossRepositoryImpl {
+	return &RaidBossRepositoryImpl{db: db}
+}
+
+// NewRaidBossRepositoryTx creates a raid boss repository bound to a transaction.
+func NewRaidBossRepositoryTx(tx pgx.Tx) *RaidBossRepositoryImpl {
+	return &RaidBossRepositoryImpl{db: tx}
+}
+
+// GetAll returns the saved state of every raid boss.
+func (r *RaidBossRepositoryImpl) GetAll(ctx context.Context) ([]models.RaidBossStatus, error) {
+	rows, err := r.db.Query(ctx,
+		`SELECT boss_id, respawn_time, current_hp, current_mp
+		 FROM raidboss_status
+		 ORDER BY boss_id`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query raid bosses: %w", err)
+	}
+	defer rows.Close()
+
+	var statuses []models.RaidBossStatus
+	for rows.Next() {
+		var (
+			s         models.RaidBossStatus
+			respawnAt *time.Time
+		)
+		if err := rows.Scan(&s.BossID, &respawnAt, &s.CurrentHP, &s.CurrentMP); err != nil {
+			return nil, fmt.Errorf("failed to scan raid boss: %w", err)
+		}
+		if respawnAt != nil {
+			s.RespawnAt = *respawnAt
+		}
+		statuses = append(statuses, s)
+	}
+	return statuses, rows.Err()
+}
+
+// Save stores a raid boss's state, replacing the one saved before.
+func (r *RaidBossRepositoryImpl) Save(ctx context.Context, s *models.RaidBossStatus) error {
+	var respawnAt *time.Time
+	if !s.Alive() {
+		respawnAt = &s.RespawnAt
+	}
+	if _, err := r.db.Exec(ctx,
+		`INSERT INTO raidboss_status (boss_id, respawn_time, current_hp, current_mp)
+		 VALUES ($1, $2, $3, $4)
+		 ON CONFLICT (boss_id) DO UPDATE SET respawn_time = EXCLUDED.respawn_time,
+		     current_hp = EXCLUDED.current_hp, current_mp = EXCLUDED.current_mp`,
+		s.BossID, respawnAt, s.CurrentHP, s.CurrentMP); err != nil {
+		return fmt.Errorf("failed to save raid boss %d: %w", s.BossID, err)
+	}
+	return nil
+}
+
+// AddPoints adds raid points a character earned on a boss.
+func (r *RaidBossRepositoryImpl) AddPoints(ctx context.Context, charID, bossID, points int32) error {
+	if _, err := r.db.Exec(ctx,
+		`INSERT INTO character_raid_points (char_id, boss_id, points)
+		 VALUES ($1, $2, $3)
+		 ON CONFLICT (char_id, boss_id) DO UPDATE SET points = character_raid_points.points + EXCLUDED.points`,
+		charID, bossID, points); err != nil {
+		return fmt.Errorf("failed to add raid points: %w", err)
+	}
+	return nil
+}
+
+// GetPoints returns a character's raid points per boss.
+func (r *RaidBossRepositoryImpl) GetPoints(ctx context.Context, charID int32) ([]models.CharacterRaidPoints, error) {
+	rows, err := r.db.Query(ctx,
+		`SELECT char_id, boss_id, points
+		 FROM character_raid_points
+		 WHERE char_id = $1
+		 ORDER BY boss_id`, charID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query raid points: %w", err)
+	}
+	defer rows.Close()
+
+	var points []models.CharacterRaidPoints
+	for rows.Next() {
+		var p models.CharacterRaidPoints
+		if err := rows.Scan(&p.CharID, &p.BossID, &p.Points); err != nil {
+			return nil, fmt.Errorf("failed to scan raid points: %w", err)
+		}
+		points = append(points, p)
+	}
+	return points, rows.Err()
+}
+
+// GetRanking returns a character's place among all characters by total raid
+// points, 1 being the most; 0 when it has none.
+func (r *RaidBossRepositoryImpl) GetRanking(ctx context.Context, charID int32) (int32, error) {
+	var rank int32
+	err := r.db.QueryRow(ctx,
+		`WITH totals AS (
+		     SELECT char_id, SUM(points) AS total
+		     FROM character_raid_points
+		     GROUP BY char_id
+		 ), mine AS (
+		     SELECT total FROM totals WHERE char_id = $1 AND total > 0
+		 )
+		 SELECT (COUNT(*) FILTER (WHERE t.total > m.total) + 1)::INTEGER
+		 FROM mine m CROSS JOIN totals t
+		 GROUP BY m.total`, charID).Scan(&rank)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return 0, nil
+		}
+		return 0, fmt.Errorf("failed to rank raid points: %w", err)
+	}
+	return rank, nil
+}
@@ -22,7 +22,7 @@ func NewSpawnRepository(db *pgxpool.Pool) *SpawnRepositoryImpl {
 
 // GetAll returns all spawn entries from the database
 func (r *SpawnRepositoryImpl) GetAll(ctx context.Context) ([]models.SpawnData, error) {
-	query := `SELECT npc_templateid, locx, locy, locz, heading, respawn_delay, count
+	query := `SELECT npc_templateid, locx, locy, locz, heading, respawn_delay, respawn_random, count, territory
 		FROM spawnlist ORDER BY id`
 
 	rows, err := r.db.Query(ctx, query)
@@ -34,7 +34,7 @@ func (r *SpawnRepositoryImpl) GetAll(ctx context.Context) ([]models.SpawnData, e
 	var spawns []models.SpawnData
 	for rows.Next() {
 		var sd models.SpawnData
-		if err := rows.Scan(&sd.NpcID, &sd.X, &sd.Y, &sd.Z, &sd.Heading, &sd.RespawnDelay, &sd.Count); err != nil {
+		if err := rows.Scan(&sd.NpcID, &sd.X, &sd.Y, &sd.Z, &sd.Heading, &sd.RespawnDelay, &sd.RespawnRandom, &sd.Count, &sd.Territory); err != nil {
 			return nil, fmt.Errorf("failed to scan spawn row: %w", err)
 		}
 		spawns = append(spawns, sd)
@@ -60,7 +60,7 @@ func (r *SpawnRepositoryImpl) GetCount(ctx context.Context) (int, error) {
 // BulkInsert inserts a batch of spawn entries using PostgreSQL COPY protocol for speed.
 // Returns the number of rows inserted.
 func (r *SpawnRepositoryImpl) BulkInsert(ctx context.Context, spawns []models.SpawnData) (int, error) {
-	columns := []string{"npc_templateid", "locx", "locy", "locz", "heading", "respawn_delay", "count"}
+	columns := []string{"npc_templateid", "locx", "locy", "locz", "heading", "respawn_delay", "respawn_random", "count", "territory"}
 
 	rows := make([][]interface{}, 0, len(spawns))
 	for _, s := range spawns {
@@ -71,7 +71,9 @@ func (r *SpawnRepositoryImpl) BulkInsert(ctx context.Context, spawns []models.Sp
 			s.Z,
 			s.Heading,
 			s.RespawnDelay,
+			s.RespawnRandom,
 			s.Count,
+			s.Territory,
 		})
 	}
 
@@ -0,0 +1,39 @@
+-- Migration: Create character_offline_trade tables
+-- Version: 010
+-- Description: Private stores kept open by offline-trade characters, mirroring
+--              L2J's character_offline_trade / character_offline_trade_items.
+--              A row exists while a store keeper sits in the world without a
+--              client; the server reopens these stores on startup.
+
+-- One row per offline store keeper.
+--   store_type : registry.PrivateStoreType of the open store
+--                (1=sell, 3=buy, 8=package sell)
+--   title      : the store sign shown above the keeper
+CREATE TABLE character_offline_trade (
+    char_id    INTEGER     NOT NULL PRIMARY KEY REFERENCES characters(char_id) ON DELETE CASCADE,
+    store_type SMALLINT    NOT NULL,
+    title      VARCHAR(29) NOT NULL DEFAULT '',
+    started_at TIMESTAMP   NOT NULL DEFAULT CURRENT_TIMESTAMP,
+
+    CONSTRAINT character_offline_trade_type_check CHECK (store_type IN (1, 3, 8))
+);
+
+-- Store lines of an offline store.
+--   item    : sell stores list an inventory object id, buy stores an item template id
+--   enchant : enchant level a buy store asks for (0 for sell stores)
+CREATE TABLE character_offline_trade_items (
+    char_id INTEGER NOT NULL REFERENCES character_offline_trade(char_id) ON DELETE CASCADE,
+    item    INTEGER NOT NULL,
+    enchant INTEGER NOT NULL DEFAULT 0,
+    count   BIGINT  NOT NULL,
+    price   BIGINT  NOT NULL,
+
+    CONSTRAINT character_offline_trade_items_count_check CHECK (count > 0),
+    CONSTRAINT character_offline_trade_items_price_check CHECK (price >= 0)
+);
+
+CREATE INDEX idx_character_offline_trade_items_char_id ON character_offline_trade_items(char_id);
+
+COMMENT ON TABLE character_offline_trade IS 'Private stores of offline-trade characters, reopened on startup (L2J character_offline_trade)';
+COMMENT ON TABLE character_offline_trade_items IS 'Lines of offline private stores (L2J character_offline_trade_items)';
+COMMENT ON COLUMN character_offline_trade_items.item IS 'Inventory object id (sell stores) or item template id (buy stores)';
@@ -0,0 +1,17 @@
+-- Migration 011: Let clan warehouse items belong to a clan
+-- Clan warehouse rows (loc = 'CLAN_WH') are owned by a clan rather than a
+-- character, as in L2J's items.owner_id. The clan id goes in its own clan_id
+-- column so it never reads as the character with the same id: owner_id is
+-- NULL on clan rows and keeps its foreign key to characters everywhere else.
+
+ALTER TABLE character_items ALTER COLUMN owner_id DROP NOT NULL;
+ALTER TABLE character_items ADD COLUMN IF NOT EXISTS clan_id INTEGER;
+
+-- Exactly one owner: a clan for the clan warehouse, a character otherwise.
+ALTER TABLE character_items DROP CONSTRAINT IF EXISTS character_items_owner_check;
+ALTER TABLE character_items ADD CONSTRAINT character_items_owner_check
+    CHECK ((loc = 'CLAN_WH' AND owner_id IS NULL AND clan_id IS NOT NULL)
+        OR (loc <> 'CLAN_WH' AND owner_id IS NOT NULL AND clan_id IS NULL));
+
+CREATE INDEX IF NOT EXISTS idx_character_items_clan ON character_items(clan_id, loc)
+    WHERE clan_id IS NOT NULL;
@@ -0,0 +1,28 @@
+-- Migration: Create character_quests table
+-- Version: 012
+-- Description: Per-character quest state, mirroring L2J's character_quests
+--              table. Every quest a character has started or completed keeps
+--              its variables here as name/value pairs; the quest scripts
+--              registered in the game loop decide what they mean.
+
+-- Character quest variables.
+--   quest_name : name of the quest script (e.g. Q00001_LettersOfLove)
+--   var        : variable name; "state" (started/completed) and "cond" (the
+--                step shown in the quest journal) are kept by the engine,
+--                the others belong to the script
+--   value      : variable value as text
+CREATE TABLE character_quests (
+    char_id    INTEGER      NOT NULL REFERENCES characters(char_id) ON DELETE CASCADE,
+    quest_name VARCHAR(60)  NOT NULL,
+    var        VARCHAR(20)  NOT NULL,
+    value      VARCHAR(255) NOT NULL DEFAULT '',
+
+    PRIMARY KEY (char_id, quest_name, var)
+);
+
+-- Load all quests for a character (world entry).
+CREATE INDEX idx_character_quests_char_id ON character_quests(char_id);
+
+COMMENT ON TABLE character_quests IS 'Per-character quest variables, L2J character_quests equivalent';
+COMMENT ON COLUMN character_quests.quest_name IS 'Quest script name the variable belongs to';
+COMMENT ON COLUMN character_quests.var IS 'Variable name; state and cond are kept by the quest engine';
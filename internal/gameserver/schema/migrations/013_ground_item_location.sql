@@ -0,0 +1,10 @@
+-- Migration 013: Let item rows lie on the ground
+-- An item a player drops keeps its row while it lies on the ground, moved to
+-- loc = 'VOID' (L2J ItemLocation.VOID) with the dropper as owner_id. Picking
+-- it up moves the row into the picker's inventory; letting it decay deletes
+-- it. Rows still on the ground when the server starts are stale and removed
+-- on startup.
+
+ALTER TABLE character_items DROP CONSTRAINT IF EXISTS character_items_loc_check;
+ALTER TABLE character_items ADD CONSTRAINT character_items_loc_check
+    CHECK (loc IN ('INVENTORY', 'PAPERDOLL', 'WAREHOUSE', 'CLAN_WH', 'PET', 'PET_EQUIP', 'FREIGHT', 'VOID'));
@@ -0,0 +1,306 @@
+-- Migration 014: Per-spawn respawn jitter and spawn territories
+-- respawn_random is how many seconds a respawn may come early or late
+-- (L2J respawn_random / respawnRandom). territory names the spawn zone of a
+-- zone-based XML spawn group, whose count NPCs appear at random points inside
+-- it; empty for a fixed point.
+--
+-- A spawnlist seeded before this migration lost the datapack's jitter, so the
+-- rows that have one get it back below, matched on npc and position (the L2J
+-- spawnlist key): the oren21_qm2220 rows of spawnlist.sql and every spawn in
+-- spawnlist/turek_orcs_fixed_pos.xml. The old seed skipped zone-based groups
+-- rather than storing them, so no existing row needs a territory; those groups
+-- are only seeded into an empty spawnlist.
+
+ALTER TABLE spawnlist ADD COLUMN IF NOT EXISTS respawn_random INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE spawnlist ADD COLUMN IF NOT EXISTS territory VARCHAR(64) NOT NULL DEFAULT '';
+
+UPDATE spawnlist s SET respawn_random = d.respawn_random
+FROM (VALUES
+    (20780, 83021, 91920, -3481, 5),
+    (20780, 83767, 91980, -3541, 5),
+    (20780, 84449, 91167, -3532, 5),
+    (20780, 83395, 91302, -3406, 5),
+    (20780, 83419, 87630, -3323, 5),
+    (20780, 83392, 86801, -3226, 5),
+    (20780, 84831, 87958, -3238, 5),
+    (20780, 84308, 89510, -3379, 5),
+    (20780, 85294, 88944, -3303, 5),
+    (20498, -100536, 103827, -3488, 20),
+    (20498, -99937, 103479, -3536, 20),
+    (20497, -98696, 101233, -3536, 20),
+    (20497, -99367, 102854, -3520, 20),
+    (20501, -100719, 103235, -3552, 20),
+    (20495, -99756, 100648, -3328, 20),
+    (20498, -100298, 105322, -3008, 20),
+    (20498, -99419, 106918, -3568, 20),
+    (20497, -99040, 104551, -3664, 20),
+    (20497, -99386, 105349, -3552, 20),
+    (20501, -100196, 105397, -3472, 20),
+    (20501, -98897, 105850, -3600, 20),
+    (20494, -97269, 102450, -3504, 20),
+    (20494, -96112, 102114, -3536, 20),
+    (20500, -95967, 102844, -3504, 20),
+    (20500, -97677, 102445, -3504, 20),
+    (20499, -97863, 101342, -3432, 20),
+    (20499, -95789, 101347, -3392, 20),
+    (20496, -96821, 103026, -3472, 20),
+    (20496, -95279, 102461, -3552, 20),
+    (20494, -95257, 100677, -3408, 20),
+    (20494, -93654, 100417, -3536, 20),
+    (20494, -93013, 99829, -3584, 20),
+    (20494, -94676, 99354, -3536, 20),
+    (20500, -95207, 99013, -3536, 20),
+    (20500, -96287, 99145, -3520, 20),
+    (20500, -94787, 101222, -3456, 20),
+    (20500, -92927, 101963, -3520, 20),
+    (20500, -93243, 99431, -3568, 20),
+    (20500, -93814, 99489, -3552, 20),
+    (20500, -95413, 100073, -3472, 20),
+    (20499, -95893, 99883, -3440, 20),
+    (20499, -96281, 99579, -3472, 20),
+    (20499, -93871, 100831, -3520, 20),
+    (20499, -92022, 102507, -3408, 20),
+    (20499, -94328, 98792, -3536, 20),
+    (20499, -94983, 99016, -3536, 20),
+    (20496, -96390, 100551, -3272, 20),
+    (20496, -94742, 101332, -3456, 20),
+    (20496, -95739, 100526, -3360, 20),
+    (20494, -89965, 100807, -3504, 20),
+    (20494, -91220, 100141, -3568, 20),
+    (20500, -91427, 100794, -3584, 20),
+    (20500, -91127, 99839, -3568, 20),
+    (20500, -90451, 101684, -3408, 20),
+    (20499, -88054, 101640, -3424, 20),
+    (20499, -90800, 103136, -3464, 20),
+    (20499, -89444, 101187, -3456, 20),
+    (20496, -90072, 100840, -3512, 20),
+    (20496, -88448, 102169, -3376, 20),
+    (20494, -87009, 102108, -3440, 20),
+    (20494, -87616, 102822, -3424, 20),
+    (20500, -88111, 104520, -3408, 20),
+    (20500, -87410, 103985, -3392, 20),
+    (20499, -88844, 103961, -3424, 20),
+    (20499, -88753, 103495, -3408, 20),
+    (20496, -87643, 102600, -3420, 20),
+    (20496, -97770, 104922, -3504, 20),
+    (20496, -96769, 107218, -3424, 20),
+    (20498, -96948, 108326, -3424, 20),
+    (20498, -97081, 105164, -3440, 20),
+    (20497, -96887, 106239, -3408, 20),
+    (20497, -97224, 105466, -3472, 20),
+    (20501, -97111, 108548, -3424, 20),
+    (20501, -97848, 104635, -3520, 20),
+    (20501, -96395, 105153, -3392, 20),
+    (20495, -96493, 108032, -3440, 20),
+    (20495, -98082, 106349, -3472, 20),
+    (20495, -97716, 107710, -3424, 20),
+    (20500, -91075, 105088, -3360, 20),
+    (20500, -94062, 106893, -3680, 20),
+    (20499, -94122, 105126, -3504, 20),
+    (20499, -95167, 107017, -3552, 20),
+    (20496, -95205, 105433, -3424, 20),
+    (20496, -94529, 104940, -3440, 20),
+    (20498, -91537, 105970, -3568, 20),
+    (20498, -92027, 105025, -3472, 20),
+    (20497, -95189, 106112, -3480, 20),
+    (20497, -94055, 106297, -3696, 20),
+    (20499, -94625, 108583, -3712, 20),
+    (20499, -94312, 108254, -3800, 20),
+    (20499, -94132, 109649, -3808, 20),
+    (20496, -95188, 107669, -3536, 20),
+    (20496, -93239, 107207, -3824, 20),
+    (20498, -93477, 108007, -3872, 20),
+    (20498, -93141, 108520, -3872, 20),
+    (20498, -93046, 107593, -3872, 20),
+    (20497, -93613, 107258, -3800, 20),
+    (20497, -92025, 108015, -3832, 20),
+    (20499, -97157, 110580, -3472, 20),
+    (20499, -96461, 111531, -3440, 20),
+    (20496, -97661, 110347, -3472, 20),
+    (20496, -97164, 109025, -3424, 20),
+    (20498, -97983, 109944, -3488, 20),
+    (20498, -97709, 111764, -3600, 20),
+    (20497, -98210, 109307, -3504, 20),
+    (20497, -96140, 109400, -3440, 20),
+    (20501, -97536, 109246, -3440, 20),
+    (20499, -97213, 112891, -3616, 20),
+    (20499, -97225, 114096, -3560, 20),
+    (20496, -97454, 112533, -3600, 20),
+    (20496, -97514, 115813, -3296, 20),
+    (20498, -95699, 115448, -3312, 20),
+    (20498, -95922, 113302, -3664, 20),
+    (20497, -95922, 113784, -3616, 20),
+    (20497, -96632, 113554, -3616, 20),
+    (20501, -98060, 113422, -3616, 20),
+    (20501, -96822, 115134, -3440, 20),
+    (20500, -96013, 111979, -3528, 20),
+    (20500, -94698, 111327, -3680, 20),
+    (20500, -94416, 112544, -3704, 20),
+    (20499, -93764, 111891, -3696, 20),
+    (20499, -93811, 110409, -3720, 20),
+    (20499, -93721, 113668, -3568, 20),
+    (20496, -91242, 112666, -3512, 20),
+    (20496, -94288, 112976, -3704, 20),
+    (20496, -93363, 113984, -3384, 20),
+    (20498, -95143, 113585, -3584, 20),
+    (20498, -93920, 111920, -3696, 20),
+    (20498, -94274, 111662, -3696, 20),
+    (20497, -94092, 114489, -3328, 20),
+    (20497, -95146, 113452, -3616, 20),
+    (20497, -92473, 112707, -3664, 20),
+    (20501, -94860, 114443, -3360, 20),
+    (20501, -93024, 111968, -3696, 20),
+    (20501, -92201, 111779, -3712, 20),
+    (20500, -90072, 109263, -3440, 20),
+    (20500, -92812, 109921, -3792, 20),
+    (20500, -90380, 111376, -3456, 20),
+    (20499, -89772, 108802, -3440, 20),
+    (20499, -88545, 110548, -3128, 20),
+    (20499, -91264, 109680, -3544, 20),
+    (20496, -89924, 109518, -3408, 20),
+    (20496, -91033, 111696, -3464, 20),
+    (20496, -90076, 109674, -3424, 20),
+    (20498, -89222, 110700, -3304, 20),
+    (20498, -92085, 110347, -3544, 20),
+    (20498, -90055, 108141, -3504, 20),
+    (20497, -91196, 108848, -3552, 20),
+    (20497, -91740, 110909, -3552, 20),
+    (20497, -90546, 109809, -3488, 20),
+    (20499, -97857, 119587, -3520, 20),
+    (20499, -96296, 119456, -3408, 20),
+    (20496, -96563, 117688, -3424, 20),
+    (20496, -95498, 120021, -3392, 20),
+    (20498, -96476, 118137, -3408, 20),
+    (20498, -97338, 118176, -3440, 20),
+    (20497, -95584, 120464, -3392, 20),
+    (20497, -96469, 120478, -3408, 20),
+    (20500, -93704, 119946, -3384, 20),
+    (20500, -91564, 118238, -3360, 20),
+    (20500, -95206, 116349, -3392, 20),
+    (20499, -95185, 118543, -3408, 20),
+    (20499, -93757, 115190, -3328, 20),
+    (20499, -95329, 117555, -3424, 20),
+    (20496, -92252, 118689, -3456, 20),
+    (20496, -93751, 119041, -3440, 20),
+    (20496, -94441, 118070, -3616, 20),
+    (20496, -93779, 117592, -3616, 20),
+    (20498, -94052, 118989, -3440, 20),
+    (20498, -91517, 119099, -3472, 20),
+    (20498, -92984, 117432, -3584, 20),
+    (20498, -93384, 117215, -3616, 20),
+    (20497, -91961, 119768, -3488, 20),
+    (20497, -93992, 116529, -3520, 20),
+    (20497, -93707, 118461, -3424, 20),
+    (20497, -92965, 119392, -3488, 20),
+    (20501, -91327, 118447, -3344, 20),
+    (20501, -92809, 116984, -3568, 20),
+    (20501, -95694, 116282, -3344, 20),
+    (20499, -91156, 115058, -3544, 20),
+    (20499, -89459, 117154, -3408, 20),
+    (20499, -89666, 114525, -3472, 20),
+    (20496, -90848, 114064, -3544, 20),
+    (20496, -89415, 115496, -3440, 20),
+    (20496, -91999, 115505, -3536, 20),
+    (20498, -90462, 116269, -3552, 20),
+    (20498, -90732, 115570, -3552, 20),
+    (20498, -90603, 115193, -3544, 20),
+    (20498, -92872, 114579, -3376, 20),
+    (20497, -91964, 116575, -3464, 20),
+    (20497, -89654, 116046, -3456, 20),
+    (20497, -89128, 114817, -3472, 20),
+    (20497, -91922, 116980, -3440, 20),
+    (20501, -91926, 114447, -3536, 20),
+    (20501, -88882, 116132, -3336, 20),
+    (20501, -90923, 117340, -3376, 20),
+    (20500, -88253, 113220, -3360, 20),
+    (20500, -87202, 113890, -3200, 20),
+    (20500, -89792, 112576, -3424, 20),
+    (20499, -87990, 114524, -3408, 20),
+    (20499, -88754, 114201, -3408, 20),
+    (20499, -88544, 113221, -3392, 20),
+    (20496, -88008, 114376, -3392, 20),
+    (20496, -87357, 114606, -3360, 20),
+    (20498, -89155, 113472, -3472, 20),
+    (20498, -89207, 112413, -3392, 20),
+    (20498, -89999, 113081, -3488, 20),
+    (20497, -89798, 113346, -3504, 20),
+    (20497, -87985, 114037, -3344, 20),
+    (20497, -87913, 112371, -3136, 20),
+    (20501, -95360, 102576, -3552, 20),
+    (20501, -95189, 102474, -3552, 20),
+    (20495, -94898, 102342, -3552, 20),
+    (20495, -95008, 102130, -3552, 20),
+    (20497, -90210, 100217, -3552, 20),
+    (20497, -90592, 100400, -3552, 20),
+    (20501, -90166, 100428, -3552, 20),
+    (20501, -90506, 100088, -3552, 20),
+    (20497, -88605, 103271, -3400, 20),
+    (20497, -88150, 102989, -3400, 20),
+    (20501, -87989, 103261, -3400, 20),
+    (20501, -88643, 102900, -3406, 20),
+    (20501, -97231, 107056, -3400, 20),
+    (20495, -97244, 106700, -3392, 20),
+    (20495, -97482, 106960, -3408, 20),
+    (20546, -97094, 106709, -3400, 20),
+    (20546, -97438, 106851, -3400, 20),
+    (20501, -93539, 106471, -3696, 20),
+    (20501, -93861, 106238, -3696, 20),
+    (20495, -93647, 106056, -3696, 20),
+    (20495, -93314, 106112, -3696, 20),
+    (20546, -93363, 105853, -3688, 20),
+    (20501, -93384, 107823, -3872, 20),
+    (20501, -93236, 108132, -3872, 20),
+    (20495, -93373, 108381, -3872, 20),
+    (20495, -93734, 107893, -3872, 20),
+    (20546, -93423, 107951, -3872, 20),
+    (20501, -97063, 110513, -3472, 20),
+    (20501, -97513, 110544, -3472, 20),
+    (20495, -97138, 110637, -3472, 20),
+    (20495, -97245, 110497, -3472, 20),
+    (20546, -97438, 110838, -3472, 20),
+    (20501, -91648, 110384, -3544, 20),
+    (20501, -91236, 109977, -3544, 20),
+    (20495, -91415, 110081, -3552, 20),
+    (20546, -91622, 110110, -3544, 20),
+    (20501, -90300, 111585, -3456, 20),
+    (20501, -90640, 111477, -3456, 20),
+    (20495, -90749, 111562, -3456, 20),
+    (20501, -94016, 112898, -3696, 20),
+    (20501, -93913, 112252, -3696, 20),
+    (20501, -93622, 112518, -3696, 20),
+    (20501, -94000, 112800, -3696, 20),
+    (20495, -94618, 112558, -3712, 20),
+    (20495, -94448, 112023, -3696, 20),
+    (20546, -94037, 111725, -3672, 20),
+    (20546, -94447, 112338, -3712, 20),
+    (20501, -97344, 114257, -3560, 20),
+    (20501, -96800, 113968, -3560, 20),
+    (20495, -97031, 114476, -3560, 20),
+    (20495, -96672, 114544, -3560, 20),
+    (20546, -96976, 114304, -3560, 20),
+    (20501, -97362, 117806, -3408, 20),
+    (20495, -96950, 117847, -3424, 20),
+    (20495, -97054, 117997, -3432, 20),
+    (20546, -97411, 118081, -3440, 20),
+    (20501, -93721, 117617, -3616, 20),
+    (20501, -93717, 117511, -3616, 20),
+    (20495, -93792, 118128, -3584, 20),
+    (20495, -93945, 117844, -3608, 20),
+    (20546, -93922, 117601, -3616, 20),
+    (20501, -92083, 119536, -3488, 20),
+    (20501, -91809, 119201, -3488, 20),
+    (20495, -91821, 119025, -3488, 20),
+    (20546, -92152, 119595, -3488, 20),
+    (20501, -91478, 114072, -3544, 20),
+    (20501, -90491, 113830, -3552, 20),
+    (20501, -90519, 113766, -3552, 20),
+    (20501, -90803, 114670, -3544, 20),
+    (20495, -90337, 114755, -3536, 20),
+    (20495, -91590, 116676, -3552, 20),
+    (20495, -90736, 115808, -3544, 20),
+    (20546, -90999, 115135, -3552, 20),
+    (20546, -91508, 114853, -3552, 20),
+    (20546, -91584, 115712, -3552, 20)
+) AS d(npc_templateid, locx, locy, locz, respawn_random)
+WHERE s.npc_templateid = d.npc_templateid
+  AND s.locx = d.locx AND s.locy = d.locy AND s.locz = d.locz;
@@ -0,0 +1,29 @@
+-- Migration: Create raid boss tables
+-- Version: 015
+-- Description: Raid boss state that outlives a restart, mirroring L2J's
+--              raidboss_spawnlist (respawn timer, current HP/MP) and
+--              character_raid_points (points earned per boss killed).
+
+-- One row per raid or grand boss the server has seen spawn or die.
+--   boss_id      : NPC template id of the boss
+--   respawn_time : when a dead boss comes back; NULL while it is alive
+--   current_hp   : HP of a living boss (0 for a dead one)
+--   current_mp   : MP of a living boss
+CREATE TABLE raidboss_status (
+    boss_id      INTEGER          NOT NULL PRIMARY KEY,
+    respawn_time TIMESTAMPTZ,
+    current_hp   DOUBLE PRECISION NOT NULL DEFAULT 0,
+    current_mp   DOUBLE PRECISION NOT NULL DEFAULT 0
+);
+
+-- Raid points a character earned by taking part in boss kills.
+CREATE TABLE character_raid_points (
+    char_id INTEGER NOT NULL REFERENCES characters(char_id) ON DELETE CASCADE,
+    boss_id INTEGER NOT NULL,
+    points  INTEGER NOT NULL DEFAULT 0,
+
+    PRIMARY KEY (char_id, boss_id)
+);
+
+COMMENT ON TABLE raidboss_status IS 'Raid boss respawn timers and HP/MP, L2J raidboss_spawnlist equivalent';
+COMMENT ON TABLE character_raid_points IS 'Raid points per character and boss, L2J character_raid_points equivalent';
@@ -4,6 +4,7 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"math/rand"
 	"net"
 	"net/http"
 	"time"
@@ -13,10 +14,12 @@ import (
 	"golang.org/x/sync/errgroup"
 
 	"github.com/VerTox/l2go/internal/gameserver/gameloop"
+	"github.com/VerTox/l2go/internal/gameserver/geodata"
 	"github.com/VerTox/l2go/internal/gameserver/handlers/client"
 	"github.com/VerTox/l2go/internal/gameserver/handlers/loginserver"
 	"github.com/VerTox/l2go/internal/gameserver/models"
 	"github.com/VerTox/l2go/internal/gameserver/packets/outclient"
+	_ "github.com/VerTox/l2go/internal/gameserver/quests" // quest scripts register from init()
 	"github.com/VerTox/l2go/internal/gameserver/registry"
 	"github.com/VerTox/l2go/internal/gameserver/repo"
 	"github.com/VerTox/l2go/internal/gameserver/schema"
@@ -71,6 +74,7 @@ type usecases struct {
 	movement        usecase.MovementUseCase
 	logout          usecase.LogoutUseCase
 	inventory       *usecase.InventoryUseCase
+	raidBoss        *usecase.RaidBossUseCase
 }
 
 type handlers struct {
@@ -109,8 +113,17 @@ type Params struct {
 	ShowClock    bool
 
 	// Rates
-	ExpRate float64
-	SpRate  float64
+	ExpRate   float64
+	SpRate    float64
+	DropRate  float64
+	AdenaRate float64
+
+	// OfflineTrade keeps private stores open after their owner disconnects.
+	OfflineTrade bool
+
+	// FreeTeleportLevel makes gatekeeper teleports free below this level
+	// (0 = never free).
+	FreeTeleportLevel int
 }
 
 type config struct {
@@ -143,8 +156,16 @@ type config struct {
 	showClock    bool
 
 	// Rates
-	expRate float64
-	spRate  float64
+	expRate   float64
+	spRate    float64
+	dropRate  float64
+	adenaRate float64
+
+	// Offline trade
+	offlineTrade bool
+
+	// Gatekeepers
+	freeTeleportLevel int
 }
 
 func (c *config) loginServerAddress() string {
@@ -247,6 +268,11 @@ func (g *GameServer) initDatabase(ctx context.Context) error {
 				Str("dir", npcDir).
 				Msg("NPC templates loaded successfully")
 			npcTemplatesLoaded = true
+			// Custom NPCs (service teleporter, buffer, event NPCs) live in a
+			// subdirectory and add to the retail templates.
+			if err := registry.GetNpcTemplateRegistry().LoadFromDirectory(npcDir + "/custom"); err != nil {
+				log.Ctx(ctx).Warn().Err(err).Msg("Failed to load custom NPC templates")
+			}
 			break
 		}
 	}
@@ -301,6 +327,110 @@ func (g *GameServer) initDatabase(ctx context.Context) error {
 		log.Ctx(ctx).Warn().Msg("Failed to load category data from any path")
 	}
 
+	// Load merchant buy lists (NPC shops). Item templates must be loaded first:
+	// lines without a price sell at the item's reference price.
+	for _, dir := range []string{
+		"datapack/buylists",
+		"../../datapack/buylists",
+	} {
+		if err := registry.GetBuyListRegistry().LoadFromDirectory(dir); err == nil {
+			log.Ctx(ctx).Info().
+				Int("count", registry.GetBuyListRegistry().Count()).
+				Str("dir", dir).
+				Msg("Buy lists loaded successfully")
+			break
+		}
+	}
+	if !registry.GetBuyListRegistry().IsLoaded() {
+		log.Ctx(ctx).Warn().Msg("Failed to load buy lists from any path")
+	}
+
+	// Load NPC dialogue pages (datapack/html/<type>/<npcId>[-<page>].htm).
+	for _, dir := range []string{
+		"datapack/html",
+		"../../datapack/html",
+	} {
+		if err := registry.GetHtmlRegistry().LoadFromDirectory(dir); err == nil {
+			log.Ctx(ctx).Info().
+				Int("count", registry.GetHtmlRegistry().Count()).
+				Str("dir", dir).
+				Msg("Html pages loaded successfully")
+			break
+		}
+	}
+	if !registry.GetHtmlRegistry().IsLoaded() {
+		log.Ctx(ctx).Warn().Msg("Failed to load html pages from any path")
+	}
+
+	// Load gatekeeper teleport lists.
+	for _, dir := range []string{
+		"datapack/teleports",
+		"../../datapack/teleports",
+	} {
+		if err := registry.GetTeleportRegistry().LoadFromDirectory(dir); err == nil {
+			log.Ctx(ctx).Info().
+				Int("count", registry.GetTeleportRegistry().Count()).
+				Str("dir", dir).
+				Msg("Teleport lists loaded successfully")
+			break
+		}
+	}
+	if !registry.GetTeleportRegistry().IsLoaded() {
+		log.Ctx(ctx).Warn().Msg("Failed to load teleport lists from any path")
+	}
+
+	// Load zones (peace, arena, water, spawn territories, ...).
+	for _, dir := range []string{
+		"datapack/zones",
+		"../../datapack/zones",
+	} {
+		if err := registry.GetZoneRegistry().LoadFromDirectory(dir); err == nil {
+			log.Ctx(ctx).Info().
+				Int("count", registry.GetZoneRegistry().Count()).
+				Str("dir", dir).
+				Msg("Zones loaded successfully")
+			break
+		}
+	}
+	if !registry.GetZoneRegistry().IsLoaded() {
+		log.Ctx(ctx).Warn().Msg("Failed to load zones from any path")
+	}
+
+	// Load geodata (terrain heights, walls, line of sight). Optional: regions
+	// without a file are open ground.
+	for _, dir := range []string{
+		"datapack/geodata",
+		"../../datapack/geodata",
+	} {
+		if err := geodata.GetEngine().LoadFromDirectory(dir); err == nil {
+			log.Ctx(ctx).Info().
+				Int("regions", geodata.GetEngine().RegionCount()).
+				Str("dir", dir).
+				Msg("Geodata loaded successfully")
+			break
+		}
+	}
+	if !geodata.GetEngine().IsLoaded() {
+		log.Ctx(ctx).Warn().Msg("No geodata found; movement and line of sight are not checked against terrain")
+	}
+
+	// Load multisell lists (item exchanges opened by "multisell <id>" links).
+	for _, dir := range []string{
+		"datapack/multisell",
+		"../../datapack/multisell",
+	} {
+		if err := registry.GetMultisellRegistry().LoadFromDirectory(dir); err == nil {
+			log.Ctx(ctx).Info().
+				Int("count", registry.GetMultisellRegistry().Count()).
+				Str("dir", dir).
+				Msg("Multisell lists loaded successfully")
+			break
+		}
+	}
+	if !registry.GetMultisellRegistry().IsLoaded() {
+		log.Ctx(ctx).Warn().Msg("Failed to load multisell lists from any path")
+	}
+
 	// Load NPC spawns from database and populate world
 	if npcTemplatesLoaded {
 		// Seed spawnlist table if empty (one-time import from L2J datapack)
@@ -325,29 +455,50 @@ func (g *GameServer) initDatabase(ctx context.Context) error {
 		} else {
 			npcCount := 0
 			skipped := 0
-			for _, spawn := range allSpawns {
+			skippedTerritory := 0
+			for i := range allSpawns {
+				spawn := &allSpawns[i]
 				tpl := registry.GetNpcTemplateRegistry().Get(spawn.NpcID)
 				if tpl == nil {
 					skipped++
 					continue
 				}
-				npc := &models.NpcInstance{
-					ObjectID:   registry.NextNPCObjectID(),
-					TemplateID: spawn.NpcID,
-					Template:   tpl,
-					Position:   models.Position{X: spawn.X, Y: spawn.Y, Z: spawn.Z},
-					Heading:    int32(spawn.Heading),
-					IsRunning:  true, // L2J default: NPCs start in running mode (enables idle animation)
-					IsDead:     false,
-					CurrentHP:  tpl.HP,
-					CurrentMP:  tpl.MP,
+				// A spawn group's NPCs appear at random points of its territory.
+				var territory *models.Zone
+				if spawn.Territory != "" {
+					z, ok := registry.GetZoneRegistry().Get(spawn.Territory)
+					if !ok {
+						skippedTerritory++
+						continue
+					}
+					territory = z
+				}
+				for range max(spawn.Count, 1) {
+					npc := &models.NpcInstance{
+						ObjectID:   registry.NextNPCObjectID(),
+						TemplateID: spawn.NpcID,
+						Template:   tpl,
+						Position:   models.Position{X: spawn.X, Y: spawn.Y, Z: spawn.Z},
+						Heading:    int32(spawn.Heading),
+						IsRunning:  true, // L2J default: NPCs start in running mode (enables idle animation)
+						IsDead:     false,
+						CurrentHP:  tpl.HP,
+						CurrentMP:  tpl.MP,
+						Spawn:      spawn,
+					}
+					if territory != nil {
+						npc.Position = territory.RandomPoint(rand.Intn)
+						npc.Position.Z = geodata.GetEngine().GetHeight(npc.Position.X, npc.Position.Y, npc.Position.Z)
+						npc.Heading = int32(rand.Intn(65536))
+					}
+					g.world.AddNPC(npc)
+					npcCount++
 				}
-				g.world.AddNPC(npc)
-				npcCount++
 			}
 			log.Ctx(ctx).Info().
 				Int("spawned", npcCount).
 				Int("skipped_no_template", skipped).
+				Int("skipped_no_territory", skippedTerritory).
 				Int("total_spawn_entries", len(allSpawns)).
 				Msg("NPCs spawned into world")
 		}
@@ -450,6 +601,9 @@ func (g *GameServer) prepareUseCases() {
 	// Initialize inventory use case
 	g.usc.inventory = usecase.NewInventoryUseCase(g.repo)
 
+	// Initialize raid boss use case
+	g.usc.raidBoss = usecase.NewRaidBossUseCase(g.repo)
+
 	// Register soulshot / spiritshot item handlers (l2go-sew). The notifier
 	// bridges the domain handlers to the world/connection registries for
 	// system messages and the activation visual; charged state lives in the
@@ -490,6 +644,7 @@ func (g *GameServer) prepareHandlers() {
 
 	// Initialize client handlers for game client connections with use cases
 	g.handlers.client = client.New(g.usc.character, g.usc.movement, g.usc.logout, g.usc.inventory, g.world, g.connections, g.handlers.loginServer, g.gameLoop.CommandChannel())
+	g.handlers.client.SetRaidBossUseCase(g.usc.raidBoss)
 
 	// Register consumable item handlers. INTERIM (l2go-diu): potions read their
 	// linked restore skill (HP/MP/CP + amount) from the skill data and restore
@@ -504,6 +659,7 @@ func (g *GameServer) prepareHandlers() {
 	g.skillData = registry.NewSkillData(skillRoots)
 	g.handlers.client.SetSkillData(g.skillData)
 	g.handlers.client.SetPromMetrics(g.promMetrics) // world-entry funnel (l2go-5wq)
+	g.handlers.client.SetOfflineTrade(g.config.offlineTrade)
 	g.gameLoop.SetSkillData(g.skillData) // casting (l2go-lu8)
 
 	// Potions cast their linked item skill through the real skill engine (l2go-849):
@@ -808,6 +964,13 @@ func New(p Params) *GameServer {
 			pvp:             p.PvP,
 			testServer:      p.TestServer,
 			showClock:       p.ShowClock,
+			expRate:         p.ExpRate,
+			spRate:          p.SpRate,
+			dropRate:        p.DropRate,
+			adenaRate:       p.AdenaRate,
+			offlineTrade:    p.OfflineTrade,
+
+			freeTeleportLevel: p.FreeTeleportLevel,
 		},
 		status: gameServerStatus{
 			playersOnline:   0,
@@ -837,7 +1000,8 @@ func (g *GameServer) Run(ctx context.Context) error {
 	g.gameLoop.SetPromMetrics(g.promMetrics)
 
 	// Seed respawn data from the NPCs loaded into the world above, so killed NPCs
-	// can respawn (otherwise RespawnEvent finds no spawn info). (l2go-c44)
+	// can respawn (otherwise RespawnEvent finds no spawn info). (l2go-c44) Each
+	// NPC keeps its spawn entry's respawn delay and territory; leaders get their minions.
 	g.gameLoop.RegisterWorldSpawns()
 
 	g.prepareUseCases()
@@ -919,6 +1083,142 @@ func (g *GameServer) run(ctx context.Context) error {
 	}()
 	g.gameLoop.SetSkillLearnSink(learnCh)
 
+	// Async trade worker: the game loop negotiates the trade window and hands the
+	// database work here — TradeStart item lists on open, and the transactional
+	// item swap once both partners confirmed — so the tick never waits on the DB.
+	tradeCh := make(chan gameloop.TradeJob, 64)
+	tradeDone := make(chan struct{})
+	go func() {
+		defer close(tradeDone)
+		for job := range tradeCh {
+			g.handlers.client.HandleTradeJob(context.Background(), job)
+		}
+	}()
+	g.gameLoop.SetTradeSink(tradeCh)
+
+	// Async private store worker: setup/browse windows read the inventory, and
+	// purchases settle in one transaction; the outcome goes back to the loop as a
+	// command. At most 64 deals are queued, well below the command buffer, so the
+	// shutdown drain cannot block on a stopped loop.
+	storeCh := make(chan gameloop.StoreJob, 64)
+	storeDone := make(chan struct{})
+	go func() {
+		defer close(storeDone)
+		for job := range storeCh {
+			g.handlers.client.HandleStoreJob(context.Background(), job)
+		}
+	}()
+	g.gameLoop.SetStoreSink(storeCh)
+
+	// Async warehouse worker: keeper windows read the inventory or the warehouse,
+	// and deposits/withdrawals move items in one transaction. Nothing goes back
+	// to the loop, so the drain on shutdown only waits for the DB.
+	warehouseCh := make(chan gameloop.WarehouseJob, 64)
+	warehouseDone := make(chan struct{})
+	go func() {
+		defer close(warehouseDone)
+		for job := range warehouseCh {
+			g.handlers.client.HandleWarehouseJob(context.Background(), job)
+		}
+	}()
+	g.gameLoop.SetWarehouseSink(warehouseCh)
+
+	// Async shop worker: merchant windows read the inventory, and purchases,
+	// sales and buy-backs settle in one transaction each. Nothing goes back to
+	// the loop, so the drain on shutdown only waits for the DB.
+	shopCh := make(chan gameloop.ShopJob, 64)
+	shopDone := make(chan struct{})
+	go func() {
+		defer close(shopDone)
+		for job := range shopCh {
+			g.handlers.client.HandleShopJob(context.Background(), job)
+		}
+	}()
+	g.gameLoop.SetShopSink(shopCh)
+
+	// Async multisell worker: each exchange takes the ingredients and creates
+	// the products in one transaction. Nothing goes back to the loop.
+	multisellCh := make(chan gameloop.MultisellJob, 64)
+	multisellDone := make(chan struct{})
+	go func() {
+		defer close(multisellDone)
+		for job := range multisellCh {
+			g.handlers.client.HandleMultisellJob(context.Background(), job)
+		}
+	}()
+	g.gameLoop.SetMultisellSink(multisellCh)
+
+	// Async teleport worker: takes a gatekeeper's fee, then sends the teleport
+	// back to the loop as CmdTeleport.
+	teleportCh := make(chan gameloop.TeleportJob, 64)
+	teleportDone := make(chan struct{})
+	go func() {
+		defer close(teleportDone)
+		for job := range teleportCh {
+			g.handlers.client.HandleTeleportJob(context.Background(), job)
+		}
+	}()
+	g.gameLoop.SetTeleportSink(teleportCh)
+	g.gameLoop.SetFreeTeleportLevel(g.config.freeTeleportLevel)
+
+	// Async quest worker: moves the items of each quest step and saves the
+	// quest's variables, in order. Nothing goes back to the loop.
+	questCh := make(chan gameloop.QuestJob, 64)
+	questDone := make(chan struct{})
+	go func() {
+		defer close(questDone)
+		for job := range questCh {
+			g.handlers.client.HandleQuestJob(context.Background(), job)
+		}
+	}()
+	g.gameLoop.SetQuestSink(questCh)
+
+	// Async raid boss worker: saves boss respawn timers and HP/MP and credits
+	// raid points, in order. The bosses saved by the last run get their timers
+	// and HP back before the loop starts.
+	if statuses, err := g.usc.raidBoss.LoadRaidBosses(ctx); err != nil {
+		log.Ctx(ctx).Error().Err(err).Msg("failed to load raid boss states")
+	} else {
+		g.gameLoop.RestoreRaidBosses(statuses)
+	}
+	raidBossCh := make(chan gameloop.RaidBossJob, 64)
+	raidBossDone := make(chan struct{})
+	go func() {
+		defer close(raidBossDone)
+		for job := range raidBossCh {
+			g.handlers.client.HandleRaidBossJob(context.Background(), job)
+		}
+	}()
+	g.gameLoop.SetRaidBossSink(raidBossCh)
+
+	// Async ground item worker: moves picked up items into inventories and
+	// deletes decayed ones. Pickups report back to the loop (CmdPickUpDone).
+	// Items left on the ground by the last run are gone, like in L2J.
+	if n, err := g.usc.inventory.ClearGround(ctx); err != nil {
+		log.Ctx(ctx).Error().Err(err).Msg("failed to clear items left on the ground")
+	} else if n > 0 {
+		log.Ctx(ctx).Info().Int64("items", n).Msg("cleared items left on the ground")
+	}
+	groundItemCh := make(chan gameloop.GroundItemJob, 64)
+	groundItemDone := make(chan struct{})
+	go func() {
+		defer close(groundItemDone)
+		for job := range groundItemCh {
+			g.handlers.client.HandleGroundItemJob(context.Background(), job)
+		}
+	}()
+	g.gameLoop.SetGroundItemSink(groundItemCh)
+	g.gameLoop.SetDropRates(g.config.dropRate, g.config.adenaRate)
+	discardCh := make(chan gameloop.DiscardJob, 64)
+	discardDone := make(chan struct{})
+	go func() {
+		defer close(discardDone)
+		for job := range discardCh {
+			g.handlers.client.HandleDiscardJob(context.Background(), job)
+		}
+	}()
+	g.gameLoop.SetDiscardSink(discardCh)
+
 	// Expose the async persistence sinks' backlog as Prometheus gauges (l2go-f9j).
 	// Read via len() at scrape time — no sampler goroutine. A filling queue means DB
 	// latency is outpacing the loop and about to stall the tick; the earliest scalable
@@ -926,6 +1226,16 @@ func (g *GameServer) run(ctx context.Context) error {
 	g.promMetrics.RegisterQueueDepth("l2go_sink_save_queue_depth", "Pending character-persistence snapshots queued for the async saver.", func() int { return len(saveCh) })
 	g.promMetrics.RegisterQueueDepth("l2go_sink_recharge_queue_depth", "Pending auto-soulshot recharge requests queued off the loop.", func() int { return len(rechargeCh) })
 	g.promMetrics.RegisterQueueDepth("l2go_sink_learn_queue_depth", "Pending learned-skill writes queued for async persistence.", func() int { return len(learnCh) })
+	g.promMetrics.RegisterQueueDepth("l2go_sink_trade_queue_depth", "Pending trade opens/commits queued for the trade worker.", func() int { return len(tradeCh) })
+	g.promMetrics.RegisterQueueDepth("l2go_sink_store_queue_depth", "Pending private store windows/deals queued for the store worker.", func() int { return len(storeCh) })
+	g.promMetrics.RegisterQueueDepth("l2go_sink_warehouse_queue_depth", "Pending warehouse windows/transfers queued for the warehouse worker.", func() int { return len(warehouseCh) })
+	g.promMetrics.RegisterQueueDepth("l2go_sink_shop_queue_depth", "Pending merchant windows/deals queued for the shop worker.", func() int { return len(shopCh) })
+	g.promMetrics.RegisterQueueDepth("l2go_sink_multisell_queue_depth", "Pending multisell exchanges queued for the multisell worker.", func() int { return len(multisellCh) })
+	g.promMetrics.RegisterQueueDepth("l2go_sink_teleport_queue_depth", "Pending paid teleports queued for the teleport worker.", func() int { return len(teleportCh) })
+	g.promMetrics.RegisterQueueDepth("l2go_sink_quest_queue_depth", "Pending quest steps queued for the quest worker.", func() int { return len(questCh) })
+	g.promMetrics.RegisterQueueDepth("l2go_sink_raidboss_queue_depth", "Pending raid boss states and raid points queued for the raid boss worker.", func() int { return len(raidBossCh) })
+	g.promMetrics.RegisterQueueDepth("l2go_sink_grounditem_queue_depth", "Pending pickups/decays queued for the ground item worker.", func() int { return len(groundItemCh) })
+	g.promMetrics.RegisterQueueDepth("l2go_sink_discard_queue_depth", "Pending item drops/destroys/crystallizations queued for the discard worker.", func() int { return len(discardCh) })
 	// Active client connections gauge (l2go-18n) — live count read at scrape time.
 	g.promMetrics.RegisterQueueDepth("l2go_active_connections", "Registered client TCP connections.", func() int { return g.connections.GetConnectionCount() })
 
@@ -948,6 +1258,13 @@ func (g *GameServer) run(ctx context.Context) error {
 		return g.gameLoop.Run(egctx)
 	})
 
+	// Reopen the offline stores persisted before the last shutdown. Runs beside
+	// the loop, which takes the restored keepers as commands.
+	eg.Go(func() error {
+		g.handlers.client.RestoreOfflineTraders(egctx)
+		return nil
+	})
+
 	// Serve Prometheus tick-health metrics on /metrics. Bound to a fixed port that
 	// the dev-stack Prometheus scrapes (l2go-5pc); shuts down gracefully when the
 	// group context is cancelled so it never outlives the rest of the server.
@@ -994,6 +1311,50 @@ func (g *GameServer) run(ctx context.Context) error {
 	close(learnCh)
 	<-learnDone
 
+	// Trade sink: finish queued commits before the DB closes.
+	close(tradeCh)
+	<-tradeDone
+
+	// Store sink: settle queued deals before the DB closes.
+	close(storeCh)
+	<-storeDone
+
+	// Warehouse sink: finish queued transfers before the DB closes.
+	close(warehouseCh)
+	<-warehouseDone
+
+	// Shop sink: settle queued merchant deals before the DB closes.
+	close(shopCh)
+	<-shopDone
+
+	// Multisell sink: finish queued exchanges before the DB closes.
+	close(multisellCh)
+	<-multisellDone
+
+	// Teleport sink: take the fees already queued before the DB closes.
+	close(teleportCh)
+	<-teleportDone
+
+	// Quest sink: save the quest steps already queued before the DB closes.
+	close(questCh)
+	<-questDone
+
+	// Raid boss sink: save the queued boss states, then the HP and MP every
+	// living boss has now, before the DB closes.
+	close(raidBossCh)
+	<-raidBossDone
+	for _, status := range g.gameLoop.RaidBossStatuses() {
+		g.handlers.client.HandleRaidBossJob(context.Background(), gameloop.RaidBossJob{Status: status})
+	}
+
+	// Ground item sink: finish queued pickups before the DB closes.
+	close(groundItemCh)
+	<-groundItemDone
+
+	// Discard sink: finish queued drops and destroys before the DB closes.
+	close(discardCh)
+	<-discardDone
+
 	// Save-on-shutdown: persist the freshest snapshot of every online player before
 	// the DB closes, so a graceful stop never loses session progress.
 	g.saveOnlinePlayersOnShutdown(context.Background())
@@ -138,9 +138,19 @@ func (uc *CharacterUseCase) SelectCharacter(ctx context.Context, charID int32, a
 		// In production, use proper logging
 	}
 
-	items, err := uc.repo.Item().GetPaperdoll(ctx, char.ID)
+	if err := fillPaperdoll(ctx, uc.repo.Item(), char); err != nil {
+		return nil, err
+	}
+
+	return char, nil
+}
+
+// fillPaperdoll loads the character's equipped items into its paperdoll slots,
+// which UserInfo and CharInfo read for the character's appearance.
+func fillPaperdoll(ctx context.Context, itemRepo repo.ItemRepository, char *models.Character) error {
+	items, err := itemRepo.GetPaperdoll(ctx, char.ID)
 	if err != nil {
-		return nil, fmt.Errorf("failed to load character items: %w", err)
+		return fmt.Errorf("failed to load character items: %w", err)
 	}
 
 	for _, item := range items {
@@ -149,8 +159,7 @@ func (uc *CharacterUseCase) SelectCharacter(ctx context.Context, charID int32, a
 			char.PaperdollObjectIDs[item.LocData] = item.ObjectID
 		}
 	}
-
-	return char, nil
+	return nil
 }
 
 // DeleteCharacter marks a character for deletion or deletes immediately
@@ -0,0 +1,237 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/rs/zerolog/log"
+
+	"github.com/VerTox/l2go/internal/gameserver/models"
+	"github.com/VerTox/l2go/internal/gameserver/registry"
+	"github.com/VerTox/l2go/internal/gameserver/repo"
+)
+
+var (
+	// ErrCannotDiscard refuses dropping, destroying or crystallizing an item:
+	// it is gone, not the player's, a quest item, or its template forbids it.
+	ErrCannotDiscard = errors.New("item cannot be discarded")
+	// ErrCrystallizeLevelTooLow refuses crystallizing an item of a grade above
+	// the player's Crystallize skill level.
+	ErrCrystallizeLevelTooLow = errors.New("crystallize skill level too low")
+)
+
+// DiscardResult is what dropping, destroying or crystallizing an item did.
+type DiscardResult struct {
+	Changed []ChangedItem
+	// Unequipped is set when the item was worn and came off first, so the
+	// paperdoll changed too.
+	Unequipped bool
+	// Item is what left the inventory, Count units of it. For a drop it is
+	// the row now lying on the ground.
+	Item models.CharacterItem
+	// Crystals is what crystallizing gave.
+	CrystalID    int32
+	CrystalCount int64
+}
+
+// DropItem takes count units of an item out of charID's inventory and puts
+// them on the ground: the row moves to LocGround, or a partial stack splits
+// off into a new ground row, so the item is never copied.
+func (uc *InventoryUseCase) DropItem(ctx context.Context, charID, objectID int32, count int64) (*DiscardResult, error) {
+	var res *DiscardResult
+	err := uc.repo.WithTransaction(ctx, func(tx repo.Transaction) error {
+		items := tx.Item()
+		item, unequipped, err := uc.lockDiscardable(ctx, items, charID, objectID, count, func(t *registry.ItemTemplate) error {
+			return discardableIf(t.Droppable)
+		})
+		if err != nil {
+			return err
+		}
+		res = &DiscardResult{Unequipped: unequipped}
+		if count < item.Count {
+			ground := *item
+			ground.ObjectID = 0
+			ground.Count = count
+			ground.SetLocation(models.LocGround, 0)
+			if err := items.Create(ctx, &ground); err != nil {
+				return fmt.Errorf("failed to split stack %d: %w", item.ObjectID, err)
+			}
+			sent, err := takeFromStack(ctx, items, item, count)
+			if err != nil {
+				return err
+			}
+			res.Changed = []ChangedItem{sent}
+			res.Item = ground
+			return nil
+		}
+		if err := items.MoveItem(ctx, item.ObjectID, charID, models.LocGround, 0); err != nil {
+			return fmt.Errorf("failed to drop item %d: %w", item.ObjectID, err)
+		}
+		res.Changed = []ChangedItem{{Item: *item, UpdateType: 3}} // REMOVE
+		res.Item = *item
+		res.Item.SetLocation(models.LocGround, 0)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	log.Ctx(ctx).Debug().
+		Int32("char_id", charID).
+		Int32("object_id", objectID).
+		Int64("count", count).
+		Msg("dropped item")
+
+	return res, nil
+}
+
+// DestroyItem destroys count units of an item in charID's inventory.
+func (uc *InventoryUseCase) DestroyItem(ctx context.Context, charID, objectID int32, count int64) (*DiscardResult, error) {
+	var res *DiscardResult
+	err := uc.repo.WithTransaction(ctx, func(tx repo.Transaction) error {
+		item, unequipped, err := uc.lockDiscardable(ctx, tx.Item(), charID, objectID, count, func(t *registry.ItemTemplate) error {
+			return discardableIf(t.Destroyable)
+		})
+		if err != nil {
+			return err
+		}
+		destroyed := *item
+		destroyed.Count = count
+		sent, err := takeFromStack(ctx, tx.Item(), item, count)
+		if err != nil {
+			return err
+		}
+		res = &DiscardResult{Changed: []ChangedItem{sent}, Unequipped: unequipped, Item: destroyed}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	log.Ctx(ctx).Debug().
+		Int32("char_id", charID).
+		Int32("object_id", objectID).
+		Int64("count", count).
+		Msg("destroyed item")
+
+	return res, nil
+}
+
+// CrystallizeItem breaks an item in charID's inventory into crystals of its
+// grade, as many as its crystal count at its enchant level. crystallizeLevel
+// is the player's Crystallize skill level, which must reach the grade (D 1 up
+// to S 5; S80 and S84 count as S).
+func (uc *InventoryUseCase) CrystallizeItem(ctx context.Context, charID, objectID int32, crystallizeLevel int32) (*DiscardResult, error) {
+	var res *DiscardResult
+	err := uc.repo.WithTransaction(ctx, func(tx repo.Transaction) error {
+		items := tx.Item()
+		var tmpl *registry.ItemTemplate
+		item, unequipped, err := uc.lockDiscardable(ctx, items, charID, objectID, 1, func(t *registry.ItemTemplate) error {
+			if !t.Destroyable || !t.Crystallizable() {
+				return ErrCannotDiscard
+			}
+			if crystallizeLevel < int32(gradeSPlus(t.CrystalType)) {
+				return ErrCrystallizeLevelTooLow
+			}
+			tmpl = t
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+		if err := items.Delete(ctx, item.ObjectID); err != nil {
+			return fmt.Errorf("failed to delete item %d: %w", item.ObjectID, err)
+		}
+		res = &DiscardResult{
+			Changed:      []ChangedItem{{Item: *item, UpdateType: 3}}, // REMOVE
+			Unequipped:   unequipped,
+			Item:         *item,
+			CrystalID:    tmpl.CrystalType.CrystalItemID(),
+			CrystalCount: int64(tmpl.CrystalCountAt(item.EnchantLevel)),
+		}
+		added, err := uc.addToInventory(ctx, items, charID, models.CharacterItem{ItemID: res.CrystalID}, res.CrystalCount)
+		if err != nil {
+			return err
+		}
+		res.Changed = append(res.Changed, added...)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	log.Ctx(ctx).Debug().
+		Int32("char_id", charID).
+		Int32("object_id", objectID).
+		Int32("crystal_id", res.CrystalID).
+		Int64("crystals", res.CrystalCount).
+		Msg("crystallized item")
+
+	return res, nil
+}
+
+// lockDiscardable locks an item charID is about to give up and checks it may:
+// it must be carried or worn, hold at least count units (exactly one of a
+// non-stackable), not be a quest item, and pass check. A worn item is taken
+// off first (L2J unequips before dropping or destroying); unequipped reports
+// it.
+func (uc *InventoryUseCase) lockDiscardable(ctx context.Context, items repo.ItemRepository, charID, objectID int32, count int64, check func(*registry.ItemTemplate) error) (item *models.CharacterItem, unequipped bool, err error) {
+	item, err = items.GetByObjectIDForUpdate(ctx, objectID)
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to lock item %d: %w", objectID, err)
+	}
+	if item == nil || item.OwnerID != charID || count <= 0 || count > item.Count {
+		return nil, false, ErrCannotDiscard
+	}
+	if item.Loc != string(models.LocInventory) && !item.IsEquipped() {
+		return nil, false, ErrCannotDiscard
+	}
+	tmpl := uc.templateOf(item.ItemID)
+	if tmpl == nil || tmpl.QuestItem || tmpl.Type2 == registry.ItemType2Quest || (!tmpl.Stackable && count != 1) {
+		return nil, false, ErrCannotDiscard
+	}
+	if err := check(tmpl); err != nil {
+		return nil, false, err
+	}
+
+	if !item.IsEquipped() {
+		return item, false, nil
+	}
+	slot := models.PaperdollSlot(item.LocData)
+	if err := items.UnequipSlot(ctx, charID, slot); err != nil {
+		return nil, false, fmt.Errorf("failed to unequip slot %d: %w", slot, err)
+	}
+	item.Unequip()
+	if slot == models.SlotRHand {
+		registry.GetChargedShotRegistry().Clear(item.ObjectID)
+	}
+	return item, true, nil
+}
+
+// discardableIf turns a template flag into lockDiscardable's verdict.
+func discardableIf(allowed bool) error {
+	if !allowed {
+		return ErrCannotDiscard
+	}
+	return nil
+}
+
+// CurrentLoad returns the weight of everything charID carries and wears.
+func (uc *InventoryUseCase) CurrentLoad(ctx context.Context, charID int32) (int, error) {
+	inv, err := uc.repo.Item().GetInventory(ctx, charID)
+	if err != nil {
+		return 0, fmt.Errorf("failed to load inventory: %w", err)
+	}
+	worn, err := uc.repo.Item().GetPaperdoll(ctx, charID)
+	if err != nil {
+		return 0, fmt.Errorf("failed to load paperdoll: %w", err)
+	}
+	var load int64
+	for _, it := range append(inv, worn...) {
+		if tmpl := uc.templateOf(it.ItemID); tmpl != nil {
+			load += int64(tmpl.Weight) * it.Count
+		}
+	}
+	return int(min(load, int64(1<<31-1))), nil
+}
@@ -0,0 +1,163 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/VerTox/l2go/internal/gameserver/models"
+	"github.com/VerTox/l2go/internal/gameserver/registry"
+)
+
+func (r *tradeFakeItemRepo) UnequipSlot(_ context.Context, charID int32, slot models.PaperdollSlot) error {
+	for _, it := range r.items {
+		if it.OwnerID == charID && it.Loc == string(models.LocPaperdoll) && it.LocData == int(slot) {
+			it.Unequip()
+		}
+	}
+	return nil
+}
+
+const (
+	discardShots   = 1835
+	discardBlade   = 158 // D-grade weapon, 100 crystals
+	discardArmor   = 352 // C-grade armor, 50 crystals
+	discardBound   = 900 // neither droppable nor destroyable
+	discardQuest   = 901
+	discardCrystal = 1458
+)
+
+func newDiscardTest(items ...*models.CharacterItem) (*InventoryUseCase, *tradeFakeItemRepo) {
+	uc, ir := newTradeTest(items...)
+	tmpls := map[int32]*registry.ItemTemplate{
+		discardShots:   {ID: discardShots, Stackable: true, Droppable: true, Destroyable: true, Weight: 3, Type2: registry.ItemType2Other},
+		discardBlade:   {ID: discardBlade, Droppable: true, Destroyable: true, Weight: 1000, Type2: registry.ItemType2Weapon, CrystalType: registry.GradeD, CrystalCount: 100},
+		discardArmor:   {ID: discardArmor, Droppable: true, Destroyable: true, Type2: registry.ItemType2Armor, CrystalType: registry.GradeC, CrystalCount: 50},
+		discardBound:   {ID: discardBound, Type2: registry.ItemType2Other},
+		discardQuest:   {ID: discardQuest, Stackable: true, Droppable: true, Destroyable: true, Type2: registry.ItemType2Quest},
+		discardCrystal: {ID: discardCrystal, Stackable: true, Droppable: true, Destroyable: true, Type2: registry.ItemType2Other},
+	}
+	uc.templateOf = func(id int32) *registry.ItemTemplate { return tmpls[id] }
+	return uc, ir
+}
+
+func TestDropItem_MovesOrSplitsTheRow(t *testing.T) {
+	uc, ir := newDiscardTest(
+		&models.CharacterItem{ObjectID: 1, OwnerID: 7, ItemID: discardShots, Count: 100},
+		&models.CharacterItem{ObjectID: 2, OwnerID: 7, ItemID: discardBlade, Count: 1},
+	)
+	ir.items[2].Equip(models.SlotRHand)
+	ctx := context.Background()
+
+	// Part of a stack splits off into a ground row.
+	res, err := uc.DropItem(ctx, 7, 1, 30)
+	if err != nil {
+		t.Fatalf("drop shots: %v", err)
+	}
+	ground := ir.items[res.Item.ObjectID]
+	if res.Item.ObjectID == 1 || ground == nil || ground.Count != 30 || ground.Loc != string(models.LocGround) {
+		t.Fatalf("ground row = %+v", ground)
+	}
+	if ir.items[1].Count != 70 || len(res.Changed) != 1 || res.Changed[0].UpdateType != 2 {
+		t.Fatalf("stack = %+v, changed %+v", ir.items[1], res.Changed)
+	}
+
+	// A worn weapon comes off and its own row goes to the ground.
+	res, err = uc.DropItem(ctx, 7, 2, 1)
+	if err != nil {
+		t.Fatalf("drop blade: %v", err)
+	}
+	if !res.Unequipped || res.Item.ObjectID != 2 || ir.items[2].Loc != string(models.LocGround) || res.Changed[0].UpdateType != 3 {
+		t.Fatalf("blade drop = %+v, row %+v", res, ir.items[2])
+	}
+	if len(ir.items) != 3 {
+		t.Fatalf("dropping must not copy rows: %d rows", len(ir.items))
+	}
+}
+
+func TestDiscard_RefusesForbiddenItems(t *testing.T) {
+	uc, _ := newDiscardTest(
+		&models.CharacterItem{ObjectID: 1, OwnerID: 7, ItemID: discardBound, Count: 1},
+		&models.CharacterItem{ObjectID: 2, OwnerID: 7, ItemID: discardQuest, Count: 5},
+		&models.CharacterItem{ObjectID: 3, OwnerID: 8, ItemID: discardShots, Count: 5},
+		&models.CharacterItem{ObjectID: 4, OwnerID: 7, ItemID: discardBlade, Count: 1},
+	)
+	ctx := context.Background()
+
+	for _, tc := range []struct {
+		name     string
+		objectID int32
+		count    int64
+	}{
+		{"template forbids it", 1, 1},
+		{"quest item", 2, 1},
+		{"someone else's", 3, 1},
+		{"more than carried", 2, 6},
+		{"several of a non-stackable", 4, 2},
+	} {
+		if _, err := uc.DropItem(ctx, 7, tc.objectID, tc.count); !errors.Is(err, ErrCannotDiscard) {
+			t.Errorf("drop %s: %v", tc.name, err)
+		}
+		if _, err := uc.DestroyItem(ctx, 7, tc.objectID, tc.count); !errors.Is(err, ErrCannotDiscard) {
+			t.Errorf("destroy %s: %v", tc.name, err)
+		}
+	}
+}
+
+func TestDestroyItem_PartialStack(t *testing.T) {
+	uc, ir := newDiscardTest(
+		&models.CharacterItem{ObjectID: 1, OwnerID: 7, ItemID: discardShots, Count: 100},
+	)
+	ctx := context.Background()
+
+	if _, err := uc.DestroyItem(ctx, 7, 1, 40); err != nil || ir.items[1].Count != 60 {
+		t.Fatalf("partial destroy: %+v, %v", ir.items[1], err)
+	}
+	res, err := uc.DestroyItem(ctx, 7, 1, 60)
+	if err != nil || res.Changed[0].UpdateType != 3 || res.Item.Count != 60 {
+		t.Fatalf("destroy the rest: %+v, %v", res, err)
+	}
+	if _, ok := ir.items[1]; ok {
+		t.Fatal("an emptied stack must be deleted")
+	}
+}
+
+func TestCrystallizeItem(t *testing.T) {
+	uc, ir := newDiscardTest(
+		&models.CharacterItem{ObjectID: 1, OwnerID: 7, ItemID: discardBlade, Count: 1, EnchantLevel: 2},
+		&models.CharacterItem{ObjectID: 2, OwnerID: 7, ItemID: discardArmor, Count: 1},
+		&models.CharacterItem{ObjectID: 3, OwnerID: 7, ItemID: discardShots, Count: 10},
+		&models.CharacterItem{ObjectID: 4, OwnerID: 7, ItemID: discardCrystal, Count: 5},
+	)
+	ctx := context.Background()
+
+	if _, err := uc.CrystallizeItem(ctx, 7, 2, 1); !errors.Is(err, ErrCrystallizeLevelTooLow) {
+		t.Fatalf("C grade at level 1: %v", err)
+	}
+	if _, err := uc.CrystallizeItem(ctx, 7, 3, 5); !errors.Is(err, ErrCannotDiscard) {
+		t.Fatalf("no-grade item: %v", err)
+	}
+
+	// +2 D-grade weapon: 100 + 2*90 crystals, into the carried stack.
+	res, err := uc.CrystallizeItem(ctx, 7, 1, 1)
+	if err != nil {
+		t.Fatalf("crystallize: %v", err)
+	}
+	if res.CrystalID != discardCrystal || res.CrystalCount != 280 || ir.items[4].Count != 285 {
+		t.Fatalf("crystals = %d x%d, stack %d", res.CrystalID, res.CrystalCount, ir.items[4].Count)
+	}
+	if _, ok := ir.items[1]; ok || len(res.Changed) != 2 {
+		t.Fatalf("weapon row kept or changes %+v", res.Changed)
+	}
+}
+
+func TestCurrentLoad(t *testing.T) {
+	uc, ir := newDiscardTest(
+		&models.CharacterItem{ObjectID: 1, OwnerID: 7, ItemID: discardShots, Count: 100},
+		&models.CharacterItem{ObjectID: 2, OwnerID: 7, ItemID: discardBlade, Count: 1},
+	)
+	ir.items[2].Equip(models.SlotRHand)
+	if load, err := uc.CurrentLoad(context.Background(), 7); err != nil || load != 1300 {
+		t.Fatalf("load = %d, %v", load, err)
+	}
+}
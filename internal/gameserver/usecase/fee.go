@@ -0,0 +1,54 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/rs/zerolog/log"
+
+	"github.com/VerTox/l2go/internal/gameserver/models"
+	"github.com/VerTox/l2go/internal/gameserver/repo"
+)
+
+// ErrNotEnoughItems aborts a payment in items the player does not carry
+// enough of.
+var ErrNotEnoughItems = errors.New("not enough items")
+
+// PayFee takes count units of itemID from charID's inventory in one
+// transaction, e.g. a gatekeeper's fee in adena or noblesse gate passes.
+// Too little adena fails with ErrNotEnoughAdena, too few of another item with
+// ErrNotEnoughItems.
+func (uc *InventoryUseCase) PayFee(ctx context.Context, charID, itemID int32, count int64) (ChangedItem, error) {
+	if count <= 0 {
+		return ChangedItem{}, fmt.Errorf("bad fee %d", count)
+	}
+	var changed ChangedItem
+	err := uc.repo.WithTransaction(ctx, func(tx repo.Transaction) error {
+		if itemID == models.ItemIDAdena {
+			c, err := uc.payAdena(ctx, tx.Item(), charID, count)
+			changed = c
+			return err
+		}
+		stack, err := tx.Item().FindStackableItemForUpdate(ctx, charID, itemID, models.LocInventory)
+		if err != nil {
+			return fmt.Errorf("failed to find item %d: %w", itemID, err)
+		}
+		if stack == nil || stack.Count < count {
+			return ErrNotEnoughItems
+		}
+		changed, err = takeFromStack(ctx, tx.Item(), stack, count)
+		return err
+	})
+	if err != nil {
+		return ChangedItem{}, err
+	}
+
+	log.Ctx(ctx).Info().
+		Int32("char_id", charID).
+		Int32("item_id", itemID).
+		Int64("count", count).
+		Msg("fee paid")
+
+	return changed, nil
+}
@@ -0,0 +1,32 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/VerTox/l2go/internal/gameserver/models"
+)
+
+func TestPayFee(t *testing.T) {
+	uc, ir := newShopTest(
+		&models.CharacterItem{ObjectID: 1, OwnerID: 7, ItemID: tradeAdena, Count: 1000},
+		&models.CharacterItem{ObjectID: 2, OwnerID: 7, ItemID: tradeQuest, Count: 1},
+	)
+	ctx := context.Background()
+
+	changed, err := uc.PayFee(ctx, 7, tradeAdena, 400)
+	if err != nil || changed.UpdateType != 2 || ir.items[1].Count != 600 {
+		t.Fatalf("PayFee adena: %+v, %v", changed, err)
+	}
+	if _, err := uc.PayFee(ctx, 7, tradeAdena, 601); !errors.Is(err, ErrNotEnoughAdena) {
+		t.Fatalf("err = %v, want ErrNotEnoughAdena", err)
+	}
+	if _, err := uc.PayFee(ctx, 7, tradeQuest, 2); !errors.Is(err, ErrNotEnoughItems) {
+		t.Fatalf("err = %v, want ErrNotEnoughItems", err)
+	}
+	// The last pass goes with the fee.
+	if changed, err := uc.PayFee(ctx, 7, tradeQuest, 1); err != nil || changed.UpdateType != 3 || ir.items[2] != nil {
+		t.Fatalf("PayFee pass: %+v, %v", changed, err)
+	}
+}
@@ -0,0 +1,102 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/rs/zerolog/log"
+
+	"github.com/VerTox/l2go/internal/gameserver/models"
+	"github.com/VerTox/l2go/internal/gameserver/repo"
+)
+
+// ErrNotOnGround is returned when a ground item's row has already left the
+// ground.
+var ErrNotOnGround = errors.New("item is not on the ground")
+
+// PickUp puts a ground item into charID's inventory in one transaction. An
+// item that was never anyone's gets new rows; an item a player dropped keeps
+// its row, which moves back into an inventory (or merges into the carried
+// stack) instead of being copied. Returns ErrInventoryFull when the item
+// needs a slot beyond inventorySlots.
+func (uc *InventoryUseCase) PickUp(ctx context.Context, charID int32, item models.GroundItem, inventorySlots int) ([]ChangedItem, error) {
+	var changed []ChangedItem
+	err := uc.repo.WithTransaction(ctx, func(tx repo.Transaction) error {
+		changed = nil
+		items := tx.Item()
+		adding := []*models.CharacterItem{{ItemID: item.ItemID}}
+		if err := uc.checkInventorySlots(ctx, items, charID, adding, inventorySlots); err != nil {
+			return err
+		}
+		if item.StoredID == 0 {
+			added, err := uc.addToInventory(ctx, items, charID, models.CharacterItem{ItemID: item.ItemID, EnchantLevel: item.Enchant}, item.Count)
+			changed = added
+			return err
+		}
+
+		row, err := items.GetByObjectIDForUpdate(ctx, item.StoredID)
+		if err != nil {
+			return fmt.Errorf("failed to load ground item %d: %w", item.StoredID, err)
+		}
+		if row == nil || row.Loc != string(models.LocGround) {
+			return ErrNotOnGround
+		}
+		if tmpl := uc.templateOf(row.ItemID); tmpl != nil && tmpl.Stackable {
+			stack, err := items.FindStackableItem(ctx, charID, row.ItemID, models.LocInventory)
+			if err != nil {
+				return fmt.Errorf("failed to find stack %d: %w", row.ItemID, err)
+			}
+			if stack != nil {
+				added, err := uc.addToInventory(ctx, items, charID, models.CharacterItem{ItemID: row.ItemID}, row.Count)
+				if err != nil {
+					return err
+				}
+				if err := items.Delete(ctx, row.ObjectID); err != nil {
+					return fmt.Errorf("failed to delete ground item %d: %w", row.ObjectID, err)
+				}
+				changed = added
+				return nil
+			}
+		}
+		if err := items.MoveItem(ctx, row.ObjectID, charID, models.LocInventory, -1); err != nil {
+			return fmt.Errorf("failed to pick up item %d: %w", row.ObjectID, err)
+		}
+		row.OwnerID = charID
+		row.SetLocation(models.LocInventory, -1)
+		changed = []ChangedItem{{Item: *row, UpdateType: 1}} // ADD
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	log.Ctx(ctx).Debug().
+		Int32("char_id", charID).
+		Int32("item_id", item.ItemID).
+		Int64("count", item.Count).
+		Msg("picked up item")
+
+	return changed, nil
+}
+
+// DestroyGroundItem deletes the row of a dropped item that lay on the ground
+// until it decayed. A row that has left the ground meanwhile is kept.
+func (uc *InventoryUseCase) DestroyGroundItem(ctx context.Context, storedID int32) error {
+	return uc.repo.WithTransaction(ctx, func(tx repo.Transaction) error {
+		row, err := tx.Item().GetByObjectIDForUpdate(ctx, storedID)
+		if err != nil {
+			return fmt.Errorf("failed to load ground item %d: %w", storedID, err)
+		}
+		if row == nil || row.Loc != string(models.LocGround) {
+			return nil
+		}
+		return tx.Item().Delete(ctx, storedID)
+	})
+}
+
+// ClearGround deletes the items left lying on the ground when the server last
+// stopped; like in L2J they do not survive a restart.
+func (uc *InventoryUseCase) ClearGround(ctx context.Context) (int64, error) {
+	return uc.repo.Item().DeleteByLocation(ctx, models.LocGround)
+}
@@ -0,0 +1,92 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/VerTox/l2go/internal/gameserver/models"
+)
+
+func TestPickUp_MonsterDrop(t *testing.T) {
+	uc, ir := newTradeTest(
+		&models.CharacterItem{ObjectID: 1, OwnerID: 7, ItemID: tradeAdena, Count: 100},
+	)
+	ctx := context.Background()
+
+	// Adena merges into the stack, the sword takes the last free slot and the
+	// next one finds none.
+	if _, err := uc.PickUp(ctx, 7, models.GroundItem{ItemID: tradeAdena, Count: 40}, 2); err != nil {
+		t.Fatalf("adena: %v", err)
+	}
+	if ir.items[1].Count != 140 {
+		t.Errorf("adena = %d, want 140", ir.items[1].Count)
+	}
+	changed, err := uc.PickUp(ctx, 7, models.GroundItem{ItemID: tradeSword, Count: 1, Enchant: 3}, 2)
+	if err != nil || len(changed) != 1 || changed[0].UpdateType != 1 {
+		t.Fatalf("sword: %+v, %v", changed, err)
+	}
+	if it := ir.items[1001]; it == nil || it.OwnerID != 7 || it.EnchantLevel != 3 || it.Loc != string(models.LocInventory) {
+		t.Fatalf("new sword = %+v", it)
+	}
+	if _, err := uc.PickUp(ctx, 7, models.GroundItem{ItemID: tradeSword, Count: 1}, 2); !errors.Is(err, ErrInventoryFull) {
+		t.Fatalf("full inventory: %v", err)
+	}
+}
+
+func TestPickUp_DroppedItemKeepsItsRow(t *testing.T) {
+	uc, ir := newTradeTest(
+		&models.CharacterItem{ObjectID: 1, OwnerID: 8, ItemID: tradeSword, Count: 1, EnchantLevel: 5},
+		&models.CharacterItem{ObjectID: 2, OwnerID: 8, ItemID: tradeAdena, Count: 30},
+		&models.CharacterItem{ObjectID: 3, OwnerID: 7, ItemID: tradeAdena, Count: 100},
+	)
+	ir.items[1].SetLocation(models.LocGround, 0)
+	ir.items[2].SetLocation(models.LocGround, 0)
+	ctx := context.Background()
+
+	changed, err := uc.PickUp(ctx, 7, models.GroundItem{ItemID: tradeSword, Count: 1, StoredID: 1}, 80)
+	if err != nil || len(changed) != 1 || changed[0].Item.ObjectID != 1 {
+		t.Fatalf("sword: %+v, %v", changed, err)
+	}
+	if it := ir.items[1]; it.OwnerID != 7 || it.Loc != string(models.LocInventory) || it.EnchantLevel != 5 {
+		t.Fatalf("sword row = %+v", it)
+	}
+	if len(ir.items) != 3 {
+		t.Fatalf("picking up must not copy the row: %d rows", len(ir.items))
+	}
+
+	// Dropped adena merges into the carried stack and its row goes away.
+	if _, err := uc.PickUp(ctx, 7, models.GroundItem{ItemID: tradeAdena, Count: 30, StoredID: 2}, 80); err != nil {
+		t.Fatalf("adena: %v", err)
+	}
+	if _, ok := ir.items[2]; ok || ir.items[3].Count != 130 {
+		t.Fatalf("adena rows = %+v", ir.items)
+	}
+
+	// A row that already left the ground cannot be picked up twice.
+	if _, err := uc.PickUp(ctx, 7, models.GroundItem{ItemID: tradeSword, Count: 1, StoredID: 1}, 80); !errors.Is(err, ErrNotOnGround) {
+		t.Fatalf("second pickup: %v", err)
+	}
+}
+
+func TestDestroyGroundItem_OnlyOnGround(t *testing.T) {
+	uc, ir := newTradeTest(
+		&models.CharacterItem{ObjectID: 1, OwnerID: 8, ItemID: tradeSword, Count: 1},
+		&models.CharacterItem{ObjectID: 2, OwnerID: 8, ItemID: tradeSword, Count: 1},
+	)
+	ir.items[1].SetLocation(models.LocGround, 0)
+	ctx := context.Background()
+
+	if err := uc.DestroyGroundItem(ctx, 1); err != nil {
+		t.Fatalf("destroy: %v", err)
+	}
+	if err := uc.DestroyGroundItem(ctx, 2); err != nil {
+		t.Fatalf("destroy carried: %v", err)
+	}
+	if _, ok := ir.items[1]; ok {
+		t.Error("the decayed item's row must be deleted")
+	}
+	if _, ok := ir.items[2]; !ok {
+		t.Error("a carried item must be kept")
+	}
+}
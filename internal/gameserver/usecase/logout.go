@@ -20,6 +20,9 @@ type LogoutUseCase interface {
 	// Return to character selection (restart)
 	PerformRestart(ctx context.Context, accountName string, charID int32) error
 	
+	// Leave the game as an offline store keeper - save, but stay in the world
+	PerformOfflineTrade(ctx context.Context, accountName string, charID int32) error
+	
 	// Return to lobby from character selection
 	PerformGotoLobby(ctx context.Context, accountName string) error
 	
@@ -132,6 +135,25 @@ func (uc *logoutUseCase) PerformRestart(ctx context.Context, accountName string,
 	return nil
 }
 
+// PerformOfflineTrade handles a store keeper leaving in offline-trade mode:
+// the character is saved like on logout but stays in the world, where its
+// store keeps trading without a client. The game loop owns the store from here
+// and removes the character once the store closes.
+func (uc *logoutUseCase) PerformOfflineTrade(ctx context.Context, accountName string, charID int32) error {
+	logger := uc.logger.With().
+		Str("account", accountName).
+		Int32("char_id", charID).
+		Logger()
+
+	if err := uc.saveCharacterData(ctx, charID); err != nil {
+		logger.Error().Err(err).Msg("failed to save character data for offline trade")
+		return fmt.Errorf("failed to save character: %w", err)
+	}
+
+	logger.Info().Msg("character left the game as an offline store keeper")
+	return nil
+}
+
 // PerformGotoLobby handles return to lobby from character selection
 func (uc *logoutUseCase) PerformGotoLobby(ctx context.Context, accountName string) error {
 	logger := uc.logger.With().
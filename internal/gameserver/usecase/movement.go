@@ -37,6 +37,9 @@ type MovementResult struct {
 	EstimatedTime   time.Duration         `json:"estimated_time"`
 	VisiblePlayers  []*registry.PlayerWorldState `json:"visible_players,omitempty"`
 	ValidationError *MovementValidationError     `json:"validation_error,omitempty"`
+	// Blocked is set when terrain cut the walk short of the requested
+	// destination; the game loop can then path round the obstacle.
+	Blocked bool `json:"blocked,omitempty"`
 }
 
 // PositionCorrectionResult represents position validation result
@@ -138,7 +141,12 @@ func (uc *movementUseCase) StartMovement(
 		}, nil // Return validation error in result, not as error
 	}
 	
-	// 3. Check if movement is significant enough to process
+	// 3. Stop short of walls and cliffs in the way
+	clipped := uc.validator.ClipToTerrain(currentPos, destination)
+	blocked := clipped.X != destination.X || clipped.Y != destination.Y
+	destination = clipped
+
+	// 4. Check if movement is significant enough to process
 	if !IsSignificantMovement(currentPos, destination, 10.0) {
 		logger.Debug().Msg("movement distance too small, ignoring")
 		return &MovementResult{
@@ -147,23 +155,24 @@ func (uc *movementUseCase) StartMovement(
 			StartPosition:  currentPos,
 			TargetPosition: currentPos, // No movement
 			EstimatedTime:  0,
+			Blocked:        blocked,
 		}, nil
 	}
 	
-	// 4. Update world registry with movement state
+	// 5. Update world registry with movement state
 	if err := uc.updateMovementState(ctx, charID, destination, isRunning); err != nil {
 		logger.Error().Err(err).Msg("failed to update movement state")
 		return nil, fmt.Errorf("failed to update movement state: %w", err)
 	}
 	
-	// 5. Get visible players for broadcasting
+	// 6. Get visible players for broadcasting
 	visiblePlayers, err := uc.GetVisiblePlayers(ctx, charID)
 	if err != nil {
 		logger.Warn().Err(err).Msg("failed to get visible players")
 		visiblePlayers = []*registry.PlayerWorldState{} // Continue without broadcasting
 	}
 	
-	// 6. Calculate estimated movement time
+	// 7. Calculate estimated movement time
 	distance := uc.validator.CalculateDistance(currentPos, destination)
 	speed := PlayerMoveSpeed(ComputeCharacterStats(playerState.Character), isRunning)
 	estimatedTime := CalculateMovementTime(distance, speed)
@@ -181,6 +190,7 @@ func (uc *movementUseCase) StartMovement(
 		TargetPosition: destination,
 		EstimatedTime:  estimatedTime,
 		VisiblePlayers: visiblePlayers,
+		Blocked:        blocked,
 	}, nil
 }
 
@@ -5,6 +5,7 @@ import (
 	"math"
 	"time"
 
+	"github.com/VerTox/l2go/internal/gameserver/geodata"
 	"github.com/VerTox/l2go/internal/gameserver/models"
 )
 
@@ -16,6 +17,9 @@ type MovementValidator struct {
 	maxMovementDistance   float64 // Maximum movement distance per request (L2J: 9900)
 	correctionThreshold   float64 // Position correction threshold (L2J: ~500)
 	maxPositionDeviation  float64 // Maximum acceptable position difference (L2J: ~600)
+
+	// geo clips moves at walls and cliffs; regions without geodata are open
+	geo *geodata.Engine
 }
 
 // NewMovementValidator creates a new movement validator with L2J-compatible settings
@@ -31,6 +35,7 @@ func NewMovementValidator() *MovementValidator {
 		maxMovementDistance:  maxMovementDistance,
 		correctionThreshold:  correctionThreshold,
 		maxPositionDeviation: maxPositionDeviation,
+		geo:                  geodata.GetEngine(),
 	}
 }
 
@@ -90,6 +95,14 @@ func (mv *MovementValidator) ValidateMovementRequest(
 	return nil
 }
 
+// ClipToTerrain returns how far toward to a character at from can walk in a
+// straight line (L2J MoveBackwardToLocation → moveCheck): to itself with its
+// Z on the ground when the way is clear, otherwise the last point before the
+// wall or cliff. Without geodata for the region, to is returned unchanged.
+func (mv *MovementValidator) ClipToTerrain(from, to models.Position) models.Position {
+	return mv.geo.MoveCheck(from, to)
+}
+
 // ValidatePosition validates a position update against expected position
 // Uses L2J ValidatePosition.java logic for position synchronization
 func (mv *MovementValidator) ValidatePosition(
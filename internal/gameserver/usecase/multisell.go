@@ -0,0 +1,136 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+
+	"github.com/rs/zerolog/log"
+
+	"github.com/VerTox/l2go/internal/gameserver/models"
+	"github.com/VerTox/l2go/internal/gameserver/registry"
+	"github.com/VerTox/l2go/internal/gameserver/repo"
+)
+
+// ErrNotEnoughIngredients aborts a multisell exchange the player lacks the
+// ingredients for.
+var ErrNotEnoughIngredients = errors.New("not enough multisell ingredients")
+
+// Exchange runs amount exchanges of one multisell entry for charID in one
+// transaction: it takes amount × each cost line from the inventory and gives
+// amount × each product. Stackable ingredients come off the carried stack;
+// non-stackable ones are picked among unequipped, unaugmented items, lowest
+// enchant first, or highest first with keepEnchant, whose enchant level then
+// goes onto the non-stackable products (L2J maintainEnchantment). A
+// maintainIngredient line is checked but not taken. Missing ingredients or too
+// few free slots for the products fail the whole exchange. Returns the
+// inventory changes, only once the transaction committed.
+func (uc *InventoryUseCase) Exchange(ctx context.Context, charID int32, cost, products []registry.MultisellIngredient, amount int64, keepEnchant bool, inventorySlots int) ([]ChangedItem, error) {
+	if amount <= 0 || len(cost) == 0 || len(products) == 0 {
+		return nil, fmt.Errorf("%w: nothing requested", ErrShopItemUnavailable)
+	}
+	for _, l := range append(cost[:len(cost):len(cost)], products...) {
+		if l.Count > math.MaxInt64/amount {
+			return nil, fmt.Errorf("%w: item %d count overflows", ErrShopItemUnavailable, l.ItemID)
+		}
+		if uc.templateOf(l.ItemID) == nil {
+			return nil, fmt.Errorf("%w: item %d", ErrShopItemUnavailable, l.ItemID)
+		}
+	}
+
+	var changed []ChangedItem
+	err := uc.repo.WithTransaction(ctx, func(tx repo.Transaction) error {
+		changed = nil
+		lines := make([]ShopPurchase, len(products))
+		for i, p := range products {
+			lines[i] = ShopPurchase{ItemID: p.ItemID, Count: p.Count * amount}
+		}
+		if err := uc.checkInventorySlots(ctx, tx.Item(), charID, uc.purchaseSlots(lines), inventorySlots); err != nil {
+			return err
+		}
+
+		inv, err := tx.Item().GetInventory(ctx, charID)
+		if err != nil {
+			return fmt.Errorf("failed to load inventory: %w", err)
+		}
+		taken := make(map[int32]bool)
+		enchant := -1
+		for _, ing := range cost {
+			need := ing.Count * amount
+			if uc.templateOf(ing.ItemID).Stackable {
+				stack := findItemStack(inv, ing.ItemID)
+				if stack == nil || stack.Count < need {
+					return fmt.Errorf("%w: item %d", ErrNotEnoughIngredients, ing.ItemID)
+				}
+				if ing.MaintainIngredient {
+					continue
+				}
+				c, err := takeFromStack(ctx, tx.Item(), stack, need)
+				if err != nil {
+					return err
+				}
+				changed = append(changed, c)
+				continue
+			}
+
+			var pool []*models.CharacterItem
+			for i := range inv {
+				it := &inv[i]
+				if it.ItemID == ing.ItemID && !taken[it.ObjectID] && !it.IsAugmented() {
+					pool = append(pool, it)
+				}
+			}
+			if int64(len(pool)) < need {
+				return fmt.Errorf("%w: item %d", ErrNotEnoughIngredients, ing.ItemID)
+			}
+			sort.SliceStable(pool, func(i, j int) bool {
+				if keepEnchant {
+					return pool[i].EnchantLevel > pool[j].EnchantLevel
+				}
+				return pool[i].EnchantLevel < pool[j].EnchantLevel
+			})
+			for _, it := range pool[:need] {
+				taken[it.ObjectID] = true
+				if enchant < 0 {
+					enchant = it.EnchantLevel
+				}
+				if ing.MaintainIngredient {
+					continue
+				}
+				if err := tx.Item().Delete(ctx, it.ObjectID); err != nil {
+					return fmt.Errorf("failed to delete item %d: %w", it.ObjectID, err)
+				}
+				changed = append(changed, ChangedItem{Item: *it, UpdateType: 3}) // REMOVE
+			}
+		}
+
+		for _, l := range lines {
+			proto := models.CharacterItem{ItemID: l.ItemID}
+			if keepEnchant && enchant > 0 && !uc.templateOf(l.ItemID).Stackable {
+				proto.EnchantLevel = enchant
+			}
+			added, err := uc.addToInventory(ctx, tx.Item(), charID, proto, l.Count)
+			if err != nil {
+				return err
+			}
+			if l.ItemID == models.ItemIDAdena && added[0].Item.Count > MaxAdena {
+				return fmt.Errorf("adena would exceed %d", MaxAdena)
+			}
+			changed = append(changed, added...)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	log.Ctx(ctx).Info().
+		Int32("char_id", charID).
+		Int64("amount", amount).
+		Int("products", len(products)).
+		Msg("multisell exchange")
+
+	return changed, nil
+}
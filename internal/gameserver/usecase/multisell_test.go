@@ -0,0 +1,94 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/VerTox/l2go/internal/gameserver/models"
+	"github.com/VerTox/l2go/internal/gameserver/registry"
+)
+
+func TestExchange_TakesIngredientsAndGivesProducts(t *testing.T) {
+	uc, ir := newShopTest(
+		&models.CharacterItem{ObjectID: 1, OwnerID: 7, ItemID: tradeAdena, Count: 1000},
+		&models.CharacterItem{ObjectID: 2, OwnerID: 7, ItemID: shopShot, Count: 300},
+	)
+	cost := []registry.MultisellIngredient{{ItemID: shopShot, Count: 100}, {ItemID: tradeAdena, Count: 200}}
+	products := []registry.MultisellIngredient{{ItemID: tradeQuest, Count: 5}}
+
+	changed, err := uc.Exchange(context.Background(), 7, cost, products, 2, false, InventorySlots)
+	if err != nil {
+		t.Fatalf("Exchange: %v", err)
+	}
+	if ir.items[1].Count != 600 || ir.items[2].Count != 100 {
+		t.Fatalf("adena = %d, shots = %d, want 600 and 100", ir.items[1].Count, ir.items[2].Count)
+	}
+	if len(changed) != 3 || changed[2].UpdateType != 1 || changed[2].Item.ItemID != tradeQuest || changed[2].Item.Count != 10 {
+		t.Fatalf("changed = %+v", changed)
+	}
+}
+
+func TestExchange_KeepsEnchant(t *testing.T) {
+	sword := func(id int32, enchant int) *models.CharacterItem {
+		return &models.CharacterItem{ObjectID: id, OwnerID: 7, ItemID: tradeSword, Count: 1, EnchantLevel: enchant}
+	}
+	cost := []registry.MultisellIngredient{{ItemID: tradeSword, Count: 1}}
+	products := []registry.MultisellIngredient{{ItemID: tradeSword, Count: 1}}
+
+	// Keeping the enchant trades the best sword and carries its level over.
+	uc, ir := newShopTest(sword(1, 0), sword(2, 3))
+	if _, err := uc.Exchange(context.Background(), 7, cost, products, 1, true, InventorySlots); err != nil {
+		t.Fatalf("Exchange: %v", err)
+	}
+	if ir.items[2] != nil || ir.items[1] == nil || ir.items[1001] == nil || ir.items[1001].EnchantLevel != 3 {
+		t.Fatalf("items = %+v", ir.items)
+	}
+
+	// Otherwise the plainest sword goes and the product is plain.
+	uc, ir = newShopTest(sword(1, 0), sword(2, 3))
+	if _, err := uc.Exchange(context.Background(), 7, cost, products, 1, false, InventorySlots); err != nil {
+		t.Fatalf("Exchange: %v", err)
+	}
+	if ir.items[1] != nil || ir.items[2] == nil || ir.items[1001] == nil || ir.items[1001].EnchantLevel != 0 {
+		t.Fatalf("items = %+v", ir.items)
+	}
+}
+
+func TestExchange_MaintainIngredientStays(t *testing.T) {
+	uc, ir := newShopTest(
+		&models.CharacterItem{ObjectID: 1, OwnerID: 7, ItemID: tradeAdena, Count: 1000},
+		&models.CharacterItem{ObjectID: 2, OwnerID: 7, ItemID: tradeSword, Count: 1},
+	)
+	cost := []registry.MultisellIngredient{{ItemID: tradeSword, Count: 1, MaintainIngredient: true}, {ItemID: tradeAdena, Count: 100}}
+	products := []registry.MultisellIngredient{{ItemID: shopShot, Count: 10}}
+
+	if _, err := uc.Exchange(context.Background(), 7, cost, products, 1, false, InventorySlots); err != nil {
+		t.Fatalf("Exchange: %v", err)
+	}
+	if ir.items[2] == nil || ir.items[1].Count != 900 {
+		t.Fatalf("the sword must stay and the adena go: %+v", ir.items)
+	}
+}
+
+func TestExchange_FailsWhole(t *testing.T) {
+	uc, ir := newShopTest(
+		&models.CharacterItem{ObjectID: 1, OwnerID: 7, ItemID: tradeAdena, Count: 1000},
+		&models.CharacterItem{ObjectID: 2, OwnerID: 7, ItemID: shopShot, Count: 50},
+	)
+	ctx := context.Background()
+	products := []registry.MultisellIngredient{{ItemID: tradeSword, Count: 1}}
+
+	// The adena comes off first; the missing shots must put it back.
+	cost := []registry.MultisellIngredient{{ItemID: tradeAdena, Count: 100}, {ItemID: shopShot, Count: 100}}
+	if _, err := uc.Exchange(ctx, 7, cost, products, 1, false, InventorySlots); !errors.Is(err, ErrNotEnoughIngredients) {
+		t.Fatalf("err = %v, want ErrNotEnoughIngredients", err)
+	}
+	// Two carried stacks plus the sword need three slots.
+	if _, err := uc.Exchange(ctx, 7, cost[:1], products, 1, false, 2); !errors.Is(err, ErrInventoryFull) {
+		t.Fatalf("err = %v, want ErrInventoryFull", err)
+	}
+	if len(ir.items) != 2 || ir.items[1].Count != 1000 || ir.items[2].Count != 50 {
+		t.Fatalf("a failed exchange must change nothing: %+v", ir.items)
+	}
+}
@@ -0,0 +1,143 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/rs/zerolog/log"
+
+	"github.com/VerTox/l2go/internal/gameserver/models"
+	"github.com/VerTox/l2go/internal/gameserver/registry"
+)
+
+// OfflineStore is a persisted offline store rebuilt for the world: the keeper's
+// character and its store, re-validated against what the keeper owns now.
+type OfflineStore struct {
+	Character *models.Character
+	Store     registry.PrivateStore
+}
+
+// SaveOfflineStore persists the open store of an offline-trade character so it
+// reopens after a restart. Sell lines are kept by object id, buy lines by item
+// template id and enchant level.
+func (uc *InventoryUseCase) SaveOfflineStore(ctx context.Context, charID int32, st registry.PrivateStore) error {
+	trade := models.OfflineTrade{CharID: charID, StoreType: int32(st.Type), Title: st.Message()}
+	if st.Type == registry.StoreBuy {
+		for _, line := range st.BuyList {
+			trade.Items = append(trade.Items, models.OfflineTradeItem{
+				Item: line.Item.ItemID, Enchant: int32(line.Item.EnchantLevel), Count: line.Count, Price: line.Price,
+			})
+		}
+	} else {
+		for _, line := range st.SellList {
+			trade.Items = append(trade.Items, models.OfflineTradeItem{
+				Item: line.Item.ObjectID, Count: line.Count, Price: line.Price,
+			})
+		}
+	}
+	if err := uc.repo.OfflineTrade().Save(ctx, &trade); err != nil {
+		return fmt.Errorf("failed to save offline store: %w", err)
+	}
+	return nil
+}
+
+// DeleteOfflineStore forgets a character's offline store.
+func (uc *InventoryUseCase) DeleteOfflineStore(ctx context.Context, charID int32) error {
+	return uc.repo.OfflineTrade().Delete(ctx, charID)
+}
+
+// ClearOfflineStores forgets every offline store; used on startup when offline
+// trade is switched off so old stores do not come back once it is on again.
+func (uc *InventoryUseCase) ClearOfflineStores(ctx context.Context) error {
+	return uc.repo.OfflineTrade().DeleteAll(ctx)
+}
+
+// RestoreOfflineStores loads the persisted offline stores for startup. Each
+// store is checked against the keeper's current state: sold-out or no longer
+// tradeable lines are dropped, a package missing any item is dropped whole,
+// and buy lines the keeper can no longer pay for are dropped. A store left
+// with nothing to trade, or whose keeper is gone, dead or being deleted, is
+// forgotten.
+func (uc *InventoryUseCase) RestoreOfflineStores(ctx context.Context) ([]OfflineStore, error) {
+	trades, err := uc.repo.OfflineTrade().GetAll(ctx)
+	if err != nil {
+		return nil, err
+	}
+	out := make([]OfflineStore, 0, len(trades))
+	for _, t := range trades {
+		restored, err := uc.restoreOfflineStore(ctx, t)
+		if err != nil {
+			log.Ctx(ctx).Error().Err(err).Int32("char_id", t.CharID).Msg("failed to restore offline store")
+			continue
+		}
+		if restored == nil {
+			if err := uc.DeleteOfflineStore(ctx, t.CharID); err != nil {
+				log.Ctx(ctx).Warn().Err(err).Int32("char_id", t.CharID).Msg("failed to drop stale offline store")
+			}
+			continue
+		}
+		out = append(out, *restored)
+	}
+	return out, nil
+}
+
+// restoreOfflineStore rebuilds one persisted store, or returns nil when there
+// is nothing left to reopen.
+func (uc *InventoryUseCase) restoreOfflineStore(ctx context.Context, t models.OfflineTrade) (*OfflineStore, error) {
+	char, err := uc.repo.Character().GetByID(ctx, t.CharID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load character: %w", err)
+	}
+	if char == nil || char.IsMarkedForDeletion() || char.CurrentHP <= 0 {
+		return nil, nil
+	}
+
+	st := registry.PrivateStore{Type: registry.PrivateStoreType(t.StoreType)}
+	switch st.Type {
+	case registry.StoreSell, registry.StorePackageSell:
+		st.Package = st.Type == registry.StorePackageSell
+		st.SellMsg = t.Title
+		for _, it := range t.Items {
+			item, err := uc.TradeItem(ctx, t.CharID, it.Item, it.Count)
+			if err != nil {
+				return nil, err
+			}
+			if item == nil || item.ItemID == models.ItemIDAdena {
+				if st.Package {
+					return nil, nil
+				}
+				continue
+			}
+			st.SellList = append(st.SellList, registry.StoreItem{Item: *item, Count: it.Count, Price: it.Price})
+		}
+	case registry.StoreBuy:
+		st.BuyMsg = t.Title
+		adena, err := uc.AdenaCount(ctx, t.CharID)
+		if err != nil {
+			return nil, err
+		}
+		var total int64
+		for _, it := range t.Items {
+			if !uc.StoreBuyable(it.Item) || it.Count <= 0 || it.Price < 0 ||
+				(it.Price > 0 && it.Count > (adena-total)/it.Price) {
+				continue
+			}
+			total += it.Count * it.Price
+			st.BuyList = append(st.BuyList, registry.StoreItem{
+				Item:  models.CharacterItem{ItemID: it.Item, EnchantLevel: int(it.Enchant)},
+				Count: it.Count,
+				Price: it.Price,
+			})
+		}
+	default:
+		return nil, nil
+	}
+	if len(st.SellList) == 0 && len(st.BuyList) == 0 {
+		return nil, nil
+	}
+
+	if err := fillPaperdoll(ctx, uc.repo.Item(), char); err != nil {
+		return nil, err
+	}
+	return &OfflineStore{Character: char, Store: st}, nil
+}
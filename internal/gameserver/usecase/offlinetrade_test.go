@@ -0,0 +1,127 @@
+package usecase
+
+import (
+	"context"
+	"testing"
+
+	"github.com/VerTox/l2go/internal/gameserver/models"
+	"github.com/VerTox/l2go/internal/gameserver/registry"
+	"github.com/VerTox/l2go/internal/gameserver/repo"
+)
+
+// offlineFakeDB adds in-memory characters and offline stores to the trade fakes.
+type offlineFakeDB struct {
+	*tradeFakeDB
+	chars   map[int32]*models.Character
+	offline *offlineFakeRepo
+}
+
+func (d *offlineFakeDB) Character() repo.CharacterRepository       { return offlineFakeChars{chars: d.chars} }
+func (d *offlineFakeDB) OfflineTrade() repo.OfflineTradeRepository { return d.offline }
+
+type offlineFakeChars struct {
+	repo.CharacterRepository
+	chars map[int32]*models.Character
+}
+
+func (c offlineFakeChars) GetByID(_ context.Context, id int32) (*models.Character, error) {
+	if ch, ok := c.chars[id]; ok {
+		cp := *ch
+		return &cp, nil
+	}
+	return nil, nil
+}
+
+type offlineFakeRepo struct {
+	repo.OfflineTradeRepository
+	trades map[int32]models.OfflineTrade
+}
+
+func (r *offlineFakeRepo) GetAll(context.Context) ([]models.OfflineTrade, error) {
+	out := make([]models.OfflineTrade, 0, len(r.trades))
+	for _, t := range r.trades {
+		out = append(out, t)
+	}
+	return out, nil
+}
+
+func (r *offlineFakeRepo) Save(_ context.Context, t *models.OfflineTrade) error {
+	r.trades[t.CharID] = *t
+	return nil
+}
+
+func (r *offlineFakeRepo) Delete(_ context.Context, charID int32) error {
+	delete(r.trades, charID)
+	return nil
+}
+
+func newOfflineTest(items ...*models.CharacterItem) (*InventoryUseCase, *offlineFakeRepo) {
+	uc, _ := newTradeTest(items...)
+	off := &offlineFakeRepo{trades: map[int32]models.OfflineTrade{}}
+	uc.repo = &offlineFakeDB{
+		tradeFakeDB: uc.repo.(*tradeFakeDB),
+		chars: map[int32]*models.Character{
+			7: {ID: 7, AccountName: "acc", Name: "Keeper", CurrentHP: 100},
+		},
+		offline: off,
+	}
+	return uc, off
+}
+
+func TestOfflineStore_SaveAndRestoreSellStore(t *testing.T) {
+	uc, off := newOfflineTest(
+		&models.CharacterItem{ObjectID: 1, OwnerID: 7, ItemID: tradeSword, Count: 1},
+		&models.CharacterItem{ObjectID: 3, OwnerID: 7, ItemID: tradeAdena, Count: 10},
+	)
+	ctx := context.Background()
+	st := registry.PrivateStore{
+		Type:    registry.StoreSell,
+		SellMsg: "swords",
+		SellList: []registry.StoreItem{
+			{Item: models.CharacterItem{ObjectID: 1, ItemID: tradeSword}, Count: 1, Price: 500},
+			// Sold or traded away since: dropped on restore.
+			{Item: models.CharacterItem{ObjectID: 2, ItemID: tradeSword}, Count: 1, Price: 700},
+		},
+	}
+	if err := uc.SaveOfflineStore(ctx, 7, st); err != nil {
+		t.Fatal(err)
+	}
+	if got := off.trades[7]; got.Title != "swords" || len(got.Items) != 2 || got.Items[0].Item != 1 {
+		t.Fatalf("saved = %+v", got)
+	}
+
+	stores, err := uc.RestoreOfflineStores(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(stores) != 1 || stores[0].Character.Name != "Keeper" {
+		t.Fatalf("restored = %+v", stores)
+	}
+	got := stores[0].Store
+	if got.Type != registry.StoreSell || got.SellMsg != "swords" || len(got.SellList) != 1 ||
+		got.SellList[0].Item.ObjectID != 1 || got.SellList[0].Price != 500 {
+		t.Fatalf("restored store = %+v", got)
+	}
+}
+
+func TestOfflineStore_RestoreDropsUnpayableBuyLinesAndEmptyStores(t *testing.T) {
+	uc, off := newOfflineTest(&models.CharacterItem{ObjectID: 3, OwnerID: 7, ItemID: tradeAdena, Count: 1000})
+	off.trades[7] = models.OfflineTrade{CharID: 7, StoreType: int32(registry.StoreBuy), Title: "wtb", Items: []models.OfflineTradeItem{
+		{Item: tradeSword, Count: 2, Price: 400},
+		{Item: tradeSword, Enchant: 3, Count: 1, Price: 300}, // 800 + 300 > 1000
+		{Item: tradeQuest, Count: 1, Price: 1},               // quest items are never bought
+	}}
+	// The keeper of this one was deleted meanwhile.
+	off.trades[9] = models.OfflineTrade{CharID: 9, StoreType: int32(registry.StoreSell), Items: []models.OfflineTradeItem{{Item: 5, Count: 1}}}
+
+	stores, err := uc.RestoreOfflineStores(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(stores) != 1 || len(stores[0].Store.BuyList) != 1 || stores[0].Store.BuyList[0].Count != 2 {
+		t.Fatalf("restored = %+v", stores)
+	}
+	if _, kept := off.trades[9]; kept {
+		t.Fatal("a store with nothing left to reopen should be forgotten")
+	}
+}
@@ -0,0 +1,119 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/rs/zerolog/log"
+
+	"github.com/VerTox/l2go/internal/gameserver/models"
+	"github.com/VerTox/l2go/internal/gameserver/registry"
+	"github.com/VerTox/l2go/internal/gameserver/repo"
+)
+
+// MaxAdena caps a single adena stack and any store total (L2J Config.MAX_ADENA).
+const MaxAdena int64 = 99_900_000_000
+
+// ErrNotEnoughAdena aborts a store deal whose buyer cannot pay the total.
+var ErrNotEnoughAdena = errors.New("not enough adena")
+
+// StoreBuyable reports whether a buy store may ask for an item: its template
+// must be tradeable and not a quest item, and adena — the store's currency —
+// is never bought.
+func (uc *InventoryUseCase) StoreBuyable(itemID int32) bool {
+	if itemID == models.ItemIDAdena {
+		return false
+	}
+	tmpl := uc.templateOf(itemID)
+	return tmpl != nil && tmpl.Tradeable && tmpl.Type2 != registry.ItemType2Quest
+}
+
+// StoreInventory returns what a private store may list — the tradeable
+// inventory without adena, which is the store's currency — together with the
+// character's adena, shown at the top of every store window.
+func (uc *InventoryUseCase) StoreInventory(ctx context.Context, charID int32) ([]models.CharacterItem, int64, error) {
+	items, err := uc.TradeableInventory(ctx, charID)
+	if err != nil {
+		return nil, 0, err
+	}
+	var adena int64
+	out := items[:0]
+	for _, it := range items {
+		if it.ItemID == models.ItemIDAdena {
+			adena += it.Count
+			continue
+		}
+		out = append(out, it)
+	}
+	return out, adena, nil
+}
+
+// AdenaCount returns the adena a character carries in its inventory.
+func (uc *InventoryUseCase) AdenaCount(ctx context.Context, charID int32) (int64, error) {
+	stack, err := uc.repo.Item().FindStackableItem(ctx, charID, models.ItemIDAdena, models.LocInventory)
+	if err != nil {
+		return 0, fmt.Errorf("failed to find adena: %w", err)
+	}
+	if stack == nil {
+		return 0, nil
+	}
+	return stack.Count, nil
+}
+
+// ExecuteStoreDeal settles a private store purchase in one transaction: every
+// item moves from seller to buyer exactly like a trade (row lock, re-validation,
+// stack merge/split), then total adena moves from buyer to seller. A missing
+// item or a buyer short of adena fails the whole deal and nothing moves.
+// Returns each side's inventory changes, only once the transaction committed.
+func (uc *InventoryUseCase) ExecuteStoreDeal(ctx context.Context, sellerID, buyerID int32, items []ItemTransfer, total int64) (changedSeller, changedBuyer []ChangedItem, err error) {
+	if total < 0 || total > MaxAdena {
+		return nil, nil, fmt.Errorf("invalid store total %d", total)
+	}
+	err = uc.repo.WithTransaction(ctx, func(tx repo.Transaction) error {
+		changedSeller, changedBuyer = nil, nil
+		for _, t := range items {
+			sent, recv, err := uc.transferItem(ctx, tx.Item(), sellerID, buyerID, t)
+			if err != nil {
+				return err
+			}
+			changedSeller = append(changedSeller, sent)
+			changedBuyer = append(changedBuyer, recv)
+		}
+		if total == 0 {
+			return nil
+		}
+		adena, err := tx.Item().FindStackableItem(ctx, buyerID, models.ItemIDAdena, models.LocInventory)
+		if err != nil {
+			return fmt.Errorf("failed to find buyer adena: %w", err)
+		}
+		if adena == nil || adena.Count < total {
+			return ErrNotEnoughAdena
+		}
+		paid, got, err := uc.transferItem(ctx, tx.Item(), buyerID, sellerID, ItemTransfer{ObjectID: adena.ObjectID, Count: total})
+		if err != nil {
+			if errors.Is(err, ErrTradeItemUnavailable) {
+				return ErrNotEnoughAdena
+			}
+			return err
+		}
+		if got.Item.Count > MaxAdena {
+			return fmt.Errorf("seller adena would exceed %d", MaxAdena)
+		}
+		changedBuyer = append(changedBuyer, paid)
+		changedSeller = append(changedSeller, got)
+		return nil
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	log.Ctx(ctx).Info().
+		Int32("seller", sellerID).
+		Int32("buyer", buyerID).
+		Int("items", len(items)).
+		Int64("adena", total).
+		Msg("private store deal committed")
+
+	return changedSeller, changedBuyer, nil
+}
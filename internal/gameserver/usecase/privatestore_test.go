@@ -0,0 +1,48 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/VerTox/l2go/internal/gameserver/models"
+)
+
+func TestExecuteStoreDeal_MovesItemsAndAdena(t *testing.T) {
+	uc, ir := newTradeTest(
+		&models.CharacterItem{ObjectID: 1, OwnerID: 7, ItemID: tradeSword, Count: 1},
+		&models.CharacterItem{ObjectID: 2, OwnerID: 8, ItemID: tradeAdena, Count: 1000},
+	)
+	changedSeller, changedBuyer, err := uc.ExecuteStoreDeal(context.Background(), 7, 8,
+		[]ItemTransfer{{ObjectID: 1, Count: 1}}, 400)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ir.items[1].OwnerID != 8 {
+		t.Fatalf("sword owner = %d, want buyer", ir.items[1].OwnerID)
+	}
+	if ir.items[2].Count != 600 {
+		t.Fatalf("buyer adena = %d, want 600", ir.items[2].Count)
+	}
+	seller, _ := ir.FindStackableItem(context.Background(), 7, tradeAdena, models.LocInventory)
+	if seller == nil || seller.Count != 400 {
+		t.Fatalf("seller adena = %+v, want 400", seller)
+	}
+	if len(changedSeller) != 2 || len(changedBuyer) != 2 {
+		t.Fatalf("changes = %d/%d, want 2/2", len(changedSeller), len(changedBuyer))
+	}
+}
+
+func TestExecuteStoreDeal_NotEnoughAdenaRollsBack(t *testing.T) {
+	uc, ir := newTradeTest(
+		&models.CharacterItem{ObjectID: 1, OwnerID: 7, ItemID: tradeSword, Count: 1},
+		&models.CharacterItem{ObjectID: 2, OwnerID: 8, ItemID: tradeAdena, Count: 100},
+	)
+	_, _, err := uc.ExecuteStoreDeal(context.Background(), 7, 8, []ItemTransfer{{ObjectID: 1, Count: 1}}, 400)
+	if !errors.Is(err, ErrNotEnoughAdena) {
+		t.Fatalf("err = %v, want ErrNotEnoughAdena", err)
+	}
+	if ir.items[1].OwnerID != 7 || ir.items[2].Count != 100 {
+		t.Fatal("a failed deal must leave both inventories untouched")
+	}
+}
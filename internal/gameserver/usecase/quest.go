@@ -0,0 +1,113 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/rs/zerolog/log"
+
+	"github.com/VerTox/l2go/internal/gameserver/models"
+	"github.com/VerTox/l2go/internal/gameserver/repo"
+)
+
+// QuestItem is an item a quest script hands out or takes back. Taking a
+// Count of zero takes every unit the character carries.
+type QuestItem struct {
+	ItemID int32
+	Count  int64
+}
+
+// LoadQuests returns a character's quests for world entry: each quest's
+// variables by quest name.
+func (uc *InventoryUseCase) LoadQuests(ctx context.Context, charID int32) (map[string]map[string]string, error) {
+	rows, err := uc.repo.Quest().GetByCharacter(ctx, charID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load quests: %w", err)
+	}
+	quests := make(map[string]map[string]string)
+	for _, r := range rows {
+		vars, ok := quests[r.QuestName]
+		if !ok {
+			vars = make(map[string]string)
+			quests[r.QuestName] = vars
+		}
+		vars[r.Var] = r.Value
+	}
+	return quests, nil
+}
+
+// SaveQuestStep saves a quest step in one transaction: it takes and then
+// gives the step's items, then stores the quest's variables, nil forgetting
+// the quest. Quests ignore the inventory limit, as quest items do in L2J, and
+// taking more than is carried takes what there is. A failure saves none of
+// it. Returns the inventory changes, only once the transaction committed.
+func (uc *InventoryUseCase) SaveQuestStep(ctx context.Context, charID int32, questName string, vars map[string]string, take, give []QuestItem) ([]ChangedItem, error) {
+	var changed []ChangedItem
+	err := uc.repo.WithTransaction(ctx, func(tx repo.Transaction) error {
+		var err error
+		changed, err = uc.questItems(ctx, tx.Item(), charID, take, give)
+		if err != nil {
+			return err
+		}
+		if vars == nil {
+			return tx.Quest().Delete(ctx, charID, questName)
+		}
+		return tx.Quest().Save(ctx, charID, questName, vars)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	log.Ctx(ctx).Debug().
+		Int32("char_id", charID).
+		Str("quest", questName).
+		Int("taken", len(take)).
+		Int("given", len(give)).
+		Msg("quest step saved")
+
+	return changed, nil
+}
+
+// questItems takes and then gives a quest step's items.
+func (uc *InventoryUseCase) questItems(ctx context.Context, items repo.ItemRepository, charID int32, take, give []QuestItem) ([]ChangedItem, error) {
+	var changed []ChangedItem
+	if len(take) > 0 {
+		inv, err := items.GetInventory(ctx, charID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load inventory: %w", err)
+		}
+		for _, t := range take {
+			left := t.Count
+			for i := range inv {
+				item := &inv[i]
+				if item.ItemID != t.ItemID || item.Count == 0 {
+					continue
+				}
+				n := item.Count
+				if t.Count > 0 {
+					if left == 0 {
+						break
+					}
+					n = min(n, left)
+					left -= n
+				}
+				c, err := takeFromStack(ctx, items, item, n)
+				if err != nil {
+					return nil, err
+				}
+				changed = append(changed, c)
+			}
+		}
+	}
+	for _, g := range give {
+		if g.Count <= 0 || uc.templateOf(g.ItemID) == nil {
+			return nil, fmt.Errorf("bad quest item %d x%d", g.ItemID, g.Count)
+		}
+		added, err := uc.addToInventory(ctx, items, charID, models.CharacterItem{ItemID: g.ItemID}, g.Count)
+		if err != nil {
+			return nil, err
+		}
+		changed = append(changed, added...)
+	}
+	return changed, nil
+}
@@ -0,0 +1,150 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"maps"
+	"testing"
+
+	"github.com/VerTox/l2go/internal/gameserver/models"
+	"github.com/VerTox/l2go/internal/gameserver/repo"
+)
+
+// questFakeDB adds in-memory quest variables to the trade fakes.
+type questFakeDB struct {
+	*tradeFakeDB
+	quests *questFakeRepo
+}
+
+func (d *questFakeDB) Quest() repo.QuestRepository { return d.quests }
+
+// WithTransaction rolls the quest variables back with the items.
+func (d *questFakeDB) WithTransaction(ctx context.Context, fn func(tx repo.Transaction) error) error {
+	saved := maps.Clone(d.quests.vars)
+	err := d.tradeFakeDB.WithTransaction(ctx, func(tx repo.Transaction) error {
+		return fn(&questFakeTx{Transaction: tx, quests: d.quests})
+	})
+	if err != nil {
+		d.quests.vars = saved
+	}
+	return err
+}
+
+type questFakeTx struct {
+	repo.Transaction
+	quests *questFakeRepo
+}
+
+func (t *questFakeTx) Quest() repo.QuestRepository { return t.quests }
+
+type questFakeRepo struct {
+	repo.QuestRepository
+	vars    map[string]map[string]string // by quest name, for char 7
+	saveErr error
+}
+
+func newQuestTest(items ...*models.CharacterItem) (*InventoryUseCase, *tradeFakeItemRepo, *questFakeRepo) {
+	uc, ir := newTradeTest(items...)
+	quests := &questFakeRepo{vars: map[string]map[string]string{}}
+	uc.repo = &questFakeDB{tradeFakeDB: uc.repo.(*tradeFakeDB), quests: quests}
+	return uc, ir, quests
+}
+
+func (r *questFakeRepo) GetByCharacter(_ context.Context, charID int32) ([]models.CharacterQuestVar, error) {
+	var out []models.CharacterQuestVar
+	for q, vars := range r.vars {
+		for k, v := range vars {
+			out = append(out, models.CharacterQuestVar{CharID: charID, QuestName: q, Var: k, Value: v})
+		}
+	}
+	return out, nil
+}
+
+func (r *questFakeRepo) Save(_ context.Context, _ int32, quest string, vars map[string]string) error {
+	if r.saveErr != nil {
+		return r.saveErr
+	}
+	r.vars[quest] = vars
+	return nil
+}
+
+func (r *questFakeRepo) Delete(_ context.Context, _ int32, quest string) error {
+	delete(r.vars, quest)
+	return nil
+}
+
+func TestQuestVars_SaveLoadDelete(t *testing.T) {
+	uc, _, _ := newQuestTest()
+	ctx := context.Background()
+
+	if _, err := uc.SaveQuestStep(ctx, 7, "Q1", map[string]string{"state": "started", "cond": "2"}, nil, nil); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := uc.SaveQuestStep(ctx, 7, "Q2", map[string]string{"state": "completed"}, nil, nil); err != nil {
+		t.Fatal(err)
+	}
+	got, err := uc.LoadQuests(ctx, 7)
+	if err != nil || len(got) != 2 || got["Q1"]["cond"] != "2" || got["Q2"]["state"] != "completed" {
+		t.Fatalf("LoadQuests = %v, %v", got, err)
+	}
+
+	if _, err := uc.SaveQuestStep(ctx, 7, "Q1", nil, nil, nil); err != nil {
+		t.Fatal(err)
+	}
+	if got, _ := uc.LoadQuests(ctx, 7); len(got) != 1 || got["Q1"] != nil {
+		t.Fatalf("after delete = %v", got)
+	}
+}
+
+func TestSaveQuestStep_TakeThenGive(t *testing.T) {
+	uc, ir, _ := newQuestTest(
+		&models.CharacterItem{ObjectID: 1, OwnerID: 7, ItemID: tradeQuest, Count: 5},
+		&models.CharacterItem{ObjectID: 2, OwnerID: 7, ItemID: tradeSword, Count: 1},
+		&models.CharacterItem{ObjectID: 3, OwnerID: 7, ItemID: tradeSword, Count: 1},
+	)
+	ctx := context.Background()
+
+	started := map[string]string{"state": "started"}
+
+	// Part of a stack, then every unit of an item.
+	changed, err := uc.SaveQuestStep(ctx, 7, "Q1", started, []QuestItem{{ItemID: tradeQuest, Count: 2}}, nil)
+	if err != nil || len(changed) != 1 || ir.items[1].Count != 3 {
+		t.Fatalf("take 2: %+v, %v", changed, err)
+	}
+	changed, err = uc.SaveQuestStep(ctx, 7, "Q1", started, []QuestItem{{ItemID: tradeSword}}, []QuestItem{{ItemID: tradeAdena, Count: 100}})
+	if err != nil || len(changed) != 3 || ir.items[2] != nil || ir.items[3] != nil {
+		t.Fatalf("take all swords: %+v, %v", changed, err)
+	}
+	if a := ir.items[1001]; a == nil || a.ItemID != tradeAdena || a.Count != 100 {
+		t.Fatalf("adena = %+v", a)
+	}
+
+	// Taking what is not carried is no error; an unknown reward rolls back.
+	if _, err := uc.SaveQuestStep(ctx, 7, "Q1", started, []QuestItem{{ItemID: tradeBound}}, nil); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := uc.SaveQuestStep(ctx, 7, "Q1", started, []QuestItem{{ItemID: tradeQuest}}, []QuestItem{{ItemID: 999, Count: 1}}); err == nil {
+		t.Fatal("unknown item must fail")
+	}
+	if ir.items[1] == nil || ir.items[1].Count != 3 {
+		t.Fatal("failed quest step must not take items")
+	}
+}
+
+func TestSaveQuestStep_FailedSaveMovesNoItems(t *testing.T) {
+	uc, ir, quests := newQuestTest(&models.CharacterItem{ObjectID: 1, OwnerID: 7, ItemID: tradeQuest, Count: 5})
+	quests.vars["Q1"] = map[string]string{"state": "started", "cond": "1"}
+	quests.saveErr = errors.New("db down")
+
+	_, err := uc.SaveQuestStep(context.Background(), 7, "Q1", map[string]string{"state": "started", "cond": "2"},
+		[]QuestItem{{ItemID: tradeQuest}}, []QuestItem{{ItemID: tradeAdena, Count: 100}})
+	if err == nil {
+		t.Fatal("failed save must fail the step")
+	}
+	if ir.items[1] == nil || ir.items[1].Count != 5 || len(ir.items) != 1 {
+		t.Fatalf("items after a failed step = %v, want untouched", ir.items)
+	}
+	if quests.vars["Q1"]["cond"] != "1" {
+		t.Fatalf("quest after a failed step = %v", quests.vars["Q1"])
+	}
+}
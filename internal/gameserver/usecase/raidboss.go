@@ -0,0 +1,68 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/VerTox/l2go/internal/gameserver/models"
+	"github.com/VerTox/l2go/internal/gameserver/repo"
+)
+
+// RaidBossUseCase handles raid boss persistence: respawn timers, HP/MP and
+// the raid points characters earn from kills.
+type RaidBossUseCase struct {
+	repo repo.DatabaseRepository
+}
+
+// NewRaidBossUseCase creates a new raid boss use case
+func NewRaidBossUseCase(repo repo.DatabaseRepository) *RaidBossUseCase {
+	return &RaidBossUseCase{repo: repo}
+}
+
+// BossRecord is a character's raid point standing: its place among all
+// characters, its total raid points and the points earned per boss.
+type BossRecord struct {
+	Ranking int32
+	Total   int32
+	Bosses  []models.CharacterRaidPoints
+}
+
+// GetBossRecord returns a character's raid points for the raid point window.
+func (uc *RaidBossUseCase) GetBossRecord(ctx context.Context, charID int32) (*BossRecord, error) {
+	points, err := uc.repo.RaidBoss().GetPoints(ctx, charID)
+	if err != nil {
+		return nil, err
+	}
+	rec := &BossRecord{Bosses: points}
+	for _, p := range points {
+		rec.Total += p.Points
+	}
+	if rec.Total > 0 {
+		if rec.Ranking, err = uc.repo.RaidBoss().GetRanking(ctx, charID); err != nil {
+			return nil, err
+		}
+	}
+	return rec, nil
+}
+
+// LoadRaidBosses returns the raid boss states saved before the last shutdown.
+func (uc *RaidBossUseCase) LoadRaidBosses(ctx context.Context) ([]models.RaidBossStatus, error) {
+	return uc.repo.RaidBoss().GetAll(ctx)
+}
+
+// SaveRaidBoss stores a raid boss's state and credits the raid points its
+// kill earned in one transaction, so a kill is never saved without its
+// points or the other way round.
+func (uc *RaidBossUseCase) SaveRaidBoss(ctx context.Context, status models.RaidBossStatus, points map[int32]int32) error {
+	return uc.repo.WithTransaction(ctx, func(tx repo.Transaction) error {
+		if err := tx.RaidBoss().Save(ctx, &status); err != nil {
+			return err
+		}
+		for charID, p := range points {
+			if err := tx.RaidBoss().AddPoints(ctx, charID, status.BossID, p); err != nil {
+				return fmt.Errorf("failed to add raid points for character %d: %w", charID, err)
+			}
+		}
+		return nil
+	})
+}
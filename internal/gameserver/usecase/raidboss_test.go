@@ -0,0 +1,87 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"maps"
+	"testing"
+
+	"github.com/VerTox/l2go/internal/gameserver/models"
+	"github.com/VerTox/l2go/internal/gameserver/repo"
+)
+
+// raidFakeDB keeps raid boss states and raid points in memory and rolls both
+// back when a transaction fails.
+type raidFakeDB struct {
+	repo.DatabaseRepository
+	raid *raidFakeRepo
+}
+
+func (d *raidFakeDB) RaidBoss() repo.RaidBossRepository { return d.raid }
+
+func (d *raidFakeDB) WithTransaction(_ context.Context, fn func(tx repo.Transaction) error) error {
+	statuses, points := maps.Clone(d.raid.statuses), maps.Clone(d.raid.points)
+	err := fn(&raidFakeTx{raid: d.raid})
+	if err != nil {
+		d.raid.statuses, d.raid.points = statuses, points
+	}
+	return err
+}
+
+type raidFakeTx struct {
+	repo.Transaction
+	raid *raidFakeRepo
+}
+
+func (t *raidFakeTx) RaidBoss() repo.RaidBossRepository { return t.raid }
+
+type raidFakeRepo struct {
+	repo.RaidBossRepository
+	statuses  map[int32]models.RaidBossStatus
+	points    map[int32]int32 // by character, for one boss
+	pointsErr error
+}
+
+func (r *raidFakeRepo) Save(_ context.Context, status *models.RaidBossStatus) error {
+	r.statuses[status.BossID] = *status
+	return nil
+}
+
+func (r *raidFakeRepo) AddPoints(_ context.Context, charID, _, points int32) error {
+	if r.pointsErr != nil {
+		return r.pointsErr
+	}
+	r.points[charID] += points
+	return nil
+}
+
+func newRaidBossTest() (*RaidBossUseCase, *raidFakeRepo) {
+	raid := &raidFakeRepo{statuses: map[int32]models.RaidBossStatus{}, points: map[int32]int32{}}
+	return NewRaidBossUseCase(&raidFakeDB{raid: raid}), raid
+}
+
+func TestSaveRaidBoss_SavesStatusWithPoints(t *testing.T) {
+	uc, raid := newRaidBossTest()
+
+	if err := uc.SaveRaidBoss(context.Background(), models.RaidBossStatus{BossID: 25001}, map[int32]int32{7: 40, 8: 12}); err != nil {
+		t.Fatalf("SaveRaidBoss: %v", err)
+	}
+	if _, ok := raid.statuses[25001]; !ok {
+		t.Error("boss status not saved")
+	}
+	if raid.points[7] != 40 || raid.points[8] != 12 {
+		t.Errorf("points = %v, want 7:40 8:12", raid.points)
+	}
+}
+
+func TestSaveRaidBoss_FailedPointsSaveNothing(t *testing.T) {
+	uc, raid := newRaidBossTest()
+	raid.pointsErr = errors.New("db down")
+
+	if err := uc.SaveRaidBoss(context.Background(), models.RaidBossStatus{BossID: 25001}, map[int32]int32{7: 40}); err == nil {
+		t.Fatal("SaveRaidBoss succeeded with raid points failing to save")
+	}
+	if _, ok := raid.statuses[25001]; ok {
+		t.Error("boss status saved without its raid points")
+	}
+}
@@ -0,0 +1,340 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+
+	"github.com/rs/zerolog/log"
+
+	"github.com/VerTox/l2go/internal/gameserver/models"
+	"github.com/VerTox/l2go/internal/gameserver/registry"
+	"github.com/VerTox/l2go/internal/gameserver/repo"
+)
+
+// ErrShopItemUnavailable aborts a merchant deal: a line names an unknown,
+// unsellable or missing item, or more units than there are.
+var ErrShopItemUnavailable = errors.New("shop item unavailable")
+
+// ShopPurchase is one line bought from a merchant at its buy-list unit price.
+type ShopPurchase struct {
+	ItemID int32
+	Count  int64
+	Price  int64
+}
+
+// ShopSale is one line sold to a merchant: the client names the item by object
+// and item id.
+type ShopSale struct {
+	ObjectID int32
+	ItemID   int32
+	Count    int64
+}
+
+// SellPrice is the adena a merchant pays for one unit of an item: half its
+// reference price (L2J L2Item.getReferencePrice() / 2). Buy-backs cost the same.
+func SellPrice(tmpl *registry.ItemTemplate) int64 {
+	return tmpl.Price / 2
+}
+
+// sellable reports whether a merchant takes an item (L2J
+// L2ItemInstance.isSellable): carried in the inventory, not equipped, not a
+// quest item and allowed by its template.
+func (uc *InventoryUseCase) sellable(item *models.CharacterItem) bool {
+	if item.Loc != string(models.LocInventory) {
+		return false
+	}
+	tmpl := uc.templateOf(item.ItemID)
+	return tmpl != nil && tmpl.Sellable && tmpl.Type2 != registry.ItemType2Quest
+}
+
+// SellableInventory returns what the sell window lists.
+func (uc *InventoryUseCase) SellableInventory(ctx context.Context, charID int32) ([]models.CharacterItem, error) {
+	items, err := uc.repo.Item().GetInventory(ctx, charID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load inventory: %w", err)
+	}
+	out := items[:0]
+	for i := range items {
+		if items[i].ItemID != models.ItemIDAdena && uc.sellable(&items[i]) {
+			out = append(out, items[i])
+		}
+	}
+	return out, nil
+}
+
+// Buy pays for lines out of charID's adena and puts the items into its
+// inventory in one transaction. Stackables merge into the carried stack;
+// every unit of a non-stackable is its own item and needs its own slot. Too
+// little adena or too few free slots within inventorySlots fails the whole
+// purchase. Returns the inventory changes, only once the transaction committed.
+func (uc *InventoryUseCase) Buy(ctx context.Context, charID int32, lines []ShopPurchase, inventorySlots int) ([]ChangedItem, error) {
+	if len(lines) == 0 {
+		return nil, fmt.Errorf("%w: nothing requested", ErrShopItemUnavailable)
+	}
+	var cost int64
+	for _, l := range lines {
+		tmpl := uc.templateOf(l.ItemID)
+		if tmpl == nil || l.Count <= 0 || l.Price < 0 || (!tmpl.Stackable && l.Count > int64(inventorySlots)) {
+			return nil, fmt.Errorf("%w: item %d", ErrShopItemUnavailable, l.ItemID)
+		}
+		if l.Price > 0 && l.Count > (MaxAdena-cost)/l.Price {
+			return nil, fmt.Errorf("%w: price exceeds %d", ErrNotEnoughAdena, MaxAdena)
+		}
+		cost += l.Count * l.Price
+	}
+
+	var changed []ChangedItem
+	err := uc.repo.WithTransaction(ctx, func(tx repo.Transaction) error {
+		changed = nil
+		if err := uc.checkInventorySlots(ctx, tx.Item(), charID, uc.purchaseSlots(lines), inventorySlots); err != nil {
+			return err
+		}
+		if cost > 0 {
+			paid, err := uc.payAdena(ctx, tx.Item(), charID, cost)
+			if err != nil {
+				return err
+			}
+			changed = append(changed, paid)
+		}
+		for _, l := range lines {
+			added, err := uc.addToInventory(ctx, tx.Item(), charID, models.CharacterItem{ItemID: l.ItemID}, l.Count)
+			if err != nil {
+				return err
+			}
+			changed = append(changed, added...)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	log.Ctx(ctx).Info().
+		Int32("char_id", charID).
+		Int("lines", len(lines)).
+		Int64("cost", cost).
+		Msg("merchant purchase")
+
+	return changed, nil
+}
+
+// Sell hands lines from charID's inventory to a merchant for SellPrice each in
+// one transaction. Every item is re-read under a row lock; one that is gone,
+// unsellable or short of the count fails the whole sale. Returns the inventory
+// changes and copies of what was sold, with the sold counts, for the refund
+// list; both only once the transaction committed.
+func (uc *InventoryUseCase) Sell(ctx context.Context, charID int32, lines []ShopSale) (changed []ChangedItem, sold []models.CharacterItem, err error) {
+	if len(lines) == 0 {
+		return nil, nil, fmt.Errorf("%w: nothing requested", ErrShopItemUnavailable)
+	}
+	var income int64
+	err = uc.repo.WithTransaction(ctx, func(tx repo.Transaction) error {
+		changed, sold, income = nil, nil, 0
+		seen := make(map[int32]bool, len(lines))
+		for _, l := range lines {
+			if seen[l.ObjectID] {
+				return fmt.Errorf("%w: object %d requested twice", ErrShopItemUnavailable, l.ObjectID)
+			}
+			seen[l.ObjectID] = true
+			item, err := tx.Item().GetByObjectIDForUpdate(ctx, l.ObjectID)
+			if err != nil {
+				return fmt.Errorf("failed to lock item %d: %w", l.ObjectID, err)
+			}
+			if item == nil || item.OwnerID != charID || item.ItemID != l.ItemID || item.ItemID == models.ItemIDAdena ||
+				!uc.sellable(item) || l.Count <= 0 || l.Count > item.Count {
+				return fmt.Errorf("%w: object %d", ErrShopItemUnavailable, l.ObjectID)
+			}
+			tmpl := uc.templateOf(item.ItemID)
+			if !tmpl.Stackable && l.Count != item.Count {
+				return fmt.Errorf("%w: object %d is not stackable", ErrShopItemUnavailable, l.ObjectID)
+			}
+			price := SellPrice(tmpl)
+			if price > 0 && l.Count > (MaxAdena-income)/price {
+				return fmt.Errorf("adena would exceed %d", MaxAdena)
+			}
+			income += l.Count * price
+
+			gone := *item
+			gone.Count = l.Count
+			taken, err := takeFromStack(ctx, tx.Item(), item, l.Count)
+			if err != nil {
+				return err
+			}
+			changed = append(changed, taken)
+			sold = append(sold, gone)
+		}
+		if income > 0 {
+			added, err := uc.addToInventory(ctx, tx.Item(), charID, models.CharacterItem{ItemID: models.ItemIDAdena}, income)
+			if err != nil {
+				return err
+			}
+			if added[0].Item.Count > MaxAdena {
+				return fmt.Errorf("adena would exceed %d", MaxAdena)
+			}
+			changed = append(changed, added...)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	log.Ctx(ctx).Info().
+		Int32("char_id", charID).
+		Int("lines", len(lines)).
+		Int64("income", income).
+		Msg("merchant sale")
+
+	return changed, sold, nil
+}
+
+// Refund buys back items charID sold earlier, taken off its refund list, for
+// what the merchant paid (SellPrice per unit). The items come back as they
+// were sold, enchant and all, under new object ids. Too little adena or too
+// few free slots fails the whole buy-back. Returns the inventory changes, only
+// once the transaction committed.
+func (uc *InventoryUseCase) Refund(ctx context.Context, charID int32, items []models.CharacterItem, inventorySlots int) ([]ChangedItem, error) {
+	if len(items) == 0 {
+		return nil, fmt.Errorf("%w: nothing requested", ErrShopItemUnavailable)
+	}
+	var cost int64
+	lines := make([]ShopPurchase, len(items))
+	for i, it := range items {
+		tmpl := uc.templateOf(it.ItemID)
+		if tmpl == nil || it.Count <= 0 {
+			return nil, fmt.Errorf("%w: item %d", ErrShopItemUnavailable, it.ItemID)
+		}
+		price := SellPrice(tmpl)
+		if price > 0 && it.Count > (MaxAdena-cost)/price {
+			return nil, fmt.Errorf("%w: price exceeds %d", ErrNotEnoughAdena, MaxAdena)
+		}
+		cost += it.Count * price
+		lines[i] = ShopPurchase{ItemID: it.ItemID, Count: it.Count}
+	}
+
+	var changed []ChangedItem
+	err := uc.repo.WithTransaction(ctx, func(tx repo.Transaction) error {
+		changed = nil
+		if err := uc.checkInventorySlots(ctx, tx.Item(), charID, uc.purchaseSlots(lines), inventorySlots); err != nil {
+			return err
+		}
+		if cost > 0 {
+			paid, err := uc.payAdena(ctx, tx.Item(), charID, cost)
+			if err != nil {
+				return err
+			}
+			changed = append(changed, paid)
+		}
+		for _, it := range items {
+			added, err := uc.addToInventory(ctx, tx.Item(), charID, it, it.Count)
+			if err != nil {
+				return err
+			}
+			changed = append(changed, added...)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	log.Ctx(ctx).Info().
+		Int32("char_id", charID).
+		Int("lines", len(items)).
+		Int64("cost", cost).
+		Msg("merchant buy-back")
+
+	return changed, nil
+}
+
+// purchaseSlots maps purchase lines to the item ids newSlots counts: one
+// entry per item a non-stackable line creates.
+func (uc *InventoryUseCase) purchaseSlots(lines []ShopPurchase) []*models.CharacterItem {
+	var out []*models.CharacterItem
+	for _, l := range lines {
+		n := int64(1)
+		if tmpl := uc.templateOf(l.ItemID); tmpl == nil || !tmpl.Stackable {
+			n = l.Count
+		}
+		for ; n > 0; n-- {
+			out = append(out, &models.CharacterItem{ItemID: l.ItemID})
+		}
+	}
+	return out
+}
+
+// checkInventorySlots fails with ErrInventoryFull when adding items would need
+// more stacks than charID may carry.
+func (uc *InventoryUseCase) checkInventorySlots(ctx context.Context, items repo.ItemRepository, charID int32, adding []*models.CharacterItem, inventorySlots int) error {
+	inv, err := items.GetInventory(ctx, charID)
+	if err != nil {
+		return fmt.Errorf("failed to load inventory: %w", err)
+	}
+	worn, err := items.GetPaperdoll(ctx, charID)
+	if err != nil {
+		return fmt.Errorf("failed to load paperdoll: %w", err)
+	}
+	stacks := make(map[int32]bool, len(inv))
+	for _, it := range inv {
+		stacks[it.ItemID] = true
+	}
+	if len(inv)+len(worn)+uc.newSlots(adding, stacks) > inventorySlots {
+		return ErrInventoryFull
+	}
+	return nil
+}
+
+// payAdena takes cost out of charID's carried adena.
+func (uc *InventoryUseCase) payAdena(ctx context.Context, items repo.ItemRepository, charID int32, cost int64) (ChangedItem, error) {
+	adena, err := items.FindStackableItemForUpdate(ctx, charID, models.ItemIDAdena, models.LocInventory)
+	if err != nil {
+		return ChangedItem{}, fmt.Errorf("failed to find adena: %w", err)
+	}
+	if adena == nil || adena.Count < cost {
+		return ChangedItem{}, ErrNotEnoughAdena
+	}
+	return takeFromStack(ctx, items, adena, cost)
+}
+
+// addToInventory puts count units of a new item shaped like proto into
+// charID's inventory: a stackable merges into the carried stack or starts
+// one, a non-stackable becomes count separate items.
+func (uc *InventoryUseCase) addToInventory(ctx context.Context, items repo.ItemRepository, charID int32, proto models.CharacterItem, count int64) ([]ChangedItem, error) {
+	tmpl := uc.templateOf(proto.ItemID)
+	if tmpl != nil && tmpl.Stackable {
+		stack, err := items.FindStackableItemForUpdate(ctx, charID, proto.ItemID, models.LocInventory)
+		if err != nil {
+			return nil, fmt.Errorf("failed to find stack %d: %w", proto.ItemID, err)
+		}
+		if stack != nil {
+			if count > math.MaxInt64-stack.Count {
+				return nil, fmt.Errorf("stack %d would overflow", stack.ObjectID)
+			}
+			stack.Count += count
+			if err := items.Update(ctx, stack); err != nil {
+				return nil, fmt.Errorf("failed to merge into stack %d: %w", stack.ObjectID, err)
+			}
+			return []ChangedItem{{Item: *stack, UpdateType: 2}}, nil // MODIFY
+		}
+	}
+
+	per, n := count, int64(1)
+	if tmpl == nil || !tmpl.Stackable {
+		per, n = 1, count
+	}
+	out := make([]ChangedItem, 0, n)
+	for ; n > 0; n-- {
+		item := proto
+		item.ObjectID = 0
+		item.OwnerID = charID
+		item.Count = per
+		item.SetLocation(models.LocInventory, -1)
+		if err := items.Create(ctx, &item); err != nil {
+			return nil, fmt.Errorf("failed to create item %d: %w", proto.ItemID, err)
+		}
+		out = append(out, ChangedItem{Item: item, UpdateType: 1}) // ADD
+	}
+	return out, nil
+}
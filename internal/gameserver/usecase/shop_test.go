@@ -0,0 +1,137 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/VerTox/l2go/internal/gameserver/models"
+	"github.com/VerTox/l2go/internal/gameserver/registry"
+)
+
+const shopShot = 1835
+
+// newShopTest is newTradeTest with reference prices: swords and shots sell to
+// merchants, the quest item and the bound sword do not.
+func newShopTest(items ...*models.CharacterItem) (*InventoryUseCase, *tradeFakeItemRepo) {
+	uc, ir := newTradeTest(items...)
+	base := uc.templateOf
+	shot := &registry.ItemTemplate{ID: shopShot, Stackable: true, Tradeable: true, Sellable: true, Price: 10, Type2: registry.ItemType2Other}
+	uc.templateOf = func(id int32) *registry.ItemTemplate {
+		if id == shopShot {
+			return shot
+		}
+		tmpl := base(id)
+		if tmpl == nil {
+			return nil
+		}
+		cp := *tmpl
+		cp.Sellable = id != tradeBound
+		cp.Price = map[int32]int64{tradeSword: 1000, tradeQuest: 10, tradeBound: 500}[id]
+		return &cp
+	}
+	return uc, ir
+}
+
+func TestShop_BuyPaysAndCreates(t *testing.T) {
+	uc, ir := newShopTest(&models.CharacterItem{ObjectID: 1, OwnerID: 7, ItemID: tradeAdena, Count: 1000})
+	ctx := context.Background()
+
+	changed, err := uc.Buy(ctx, 7, []ShopPurchase{{ItemID: tradeSword, Count: 2, Price: 100}, {ItemID: shopShot, Count: 50, Price: 1}}, InventorySlots)
+	if err != nil {
+		t.Fatalf("Buy: %v", err)
+	}
+	if ir.items[1].Count != 750 {
+		t.Errorf("adena = %d, want 750", ir.items[1].Count)
+	}
+	// Each sword is its own item; the shots are one new stack.
+	if len(changed) != 4 || changed[0].UpdateType != 2 || changed[1].Item.Count != 1 || changed[3].Item.Count != 50 || len(ir.items) != 4 {
+		t.Fatalf("changed = %+v, items = %d", changed, len(ir.items))
+	}
+
+	// A second purchase merges into the carried shots.
+	if _, err := uc.Buy(ctx, 7, []ShopPurchase{{ItemID: shopShot, Count: 10, Price: 1}}, InventorySlots); err != nil {
+		t.Fatalf("Buy: %v", err)
+	}
+	if len(ir.items) != 4 || ir.items[1].Count != 740 {
+		t.Fatalf("the shots must merge: %d items, adena %d", len(ir.items), ir.items[1].Count)
+	}
+}
+
+func TestShop_BuyFailsWhole(t *testing.T) {
+	uc, ir := newShopTest(&models.CharacterItem{ObjectID: 1, OwnerID: 7, ItemID: tradeAdena, Count: 100})
+	ctx := context.Background()
+
+	if _, err := uc.Buy(ctx, 7, []ShopPurchase{{ItemID: shopShot, Count: 10, Price: 1}, {ItemID: tradeSword, Count: 1, Price: 200}}, InventorySlots); !errors.Is(err, ErrNotEnoughAdena) {
+		t.Fatalf("err = %v, want ErrNotEnoughAdena", err)
+	}
+	// Adena plus two swords need three slots.
+	if _, err := uc.Buy(ctx, 7, []ShopPurchase{{ItemID: tradeSword, Count: 2, Price: 1}}, 2); !errors.Is(err, ErrInventoryFull) {
+		t.Fatalf("err = %v, want ErrInventoryFull", err)
+	}
+	if _, err := uc.Buy(ctx, 7, []ShopPurchase{{ItemID: 99, Count: 1}}, InventorySlots); !errors.Is(err, ErrShopItemUnavailable) {
+		t.Fatalf("err = %v, want ErrShopItemUnavailable", err)
+	}
+	if len(ir.items) != 1 || ir.items[1].Count != 100 {
+		t.Fatalf("a failed purchase must change nothing: %+v", ir.items)
+	}
+}
+
+func TestShop_SellAndRefund(t *testing.T) {
+	uc, ir := newShopTest(
+		&models.CharacterItem{ObjectID: 1, OwnerID: 7, ItemID: tradeSword, Count: 1, EnchantLevel: 3},
+		&models.CharacterItem{ObjectID: 2, OwnerID: 7, ItemID: shopShot, Count: 300},
+		&models.CharacterItem{ObjectID: 3, OwnerID: 7, ItemID: tradeBound, Count: 1},
+		&models.CharacterItem{ObjectID: 4, OwnerID: 7, ItemID: tradeQuest, Count: 1},
+	)
+	ctx := context.Background()
+
+	sellable, _ := uc.SellableInventory(ctx, 7)
+	if len(sellable) != 2 {
+		t.Fatalf("sellable = %+v, want the sword and the shots", sellable)
+	}
+	if _, _, err := uc.Sell(ctx, 7, []ShopSale{{ObjectID: 3, ItemID: tradeBound, Count: 1}}); !errors.Is(err, ErrShopItemUnavailable) {
+		t.Fatalf("err = %v, want ErrShopItemUnavailable", err)
+	}
+	if _, _, err := uc.Sell(ctx, 7, []ShopSale{{ObjectID: 1, ItemID: shopShot, Count: 1}}); !errors.Is(err, ErrShopItemUnavailable) {
+		t.Fatal("the item id must match the object")
+	}
+
+	changed, sold, err := uc.Sell(ctx, 7, []ShopSale{{ObjectID: 1, ItemID: tradeSword, Count: 1}, {ObjectID: 2, ItemID: shopShot, Count: 100}})
+	if err != nil {
+		t.Fatalf("Sell: %v", err)
+	}
+	// 1000/2 for the sword, 100 × 10/2 for the shots.
+	var adena *models.CharacterItem
+	for _, it := range ir.items {
+		if it.ItemID == tradeAdena {
+			adena = it
+		}
+	}
+	if adena == nil || adena.Count != 1000 || ir.items[1] != nil || ir.items[2].Count != 200 || len(changed) != 3 {
+		t.Fatalf("adena = %+v, changed = %+v", adena, changed)
+	}
+	if len(sold) != 2 || sold[0].EnchantLevel != 3 || sold[1].Count != 100 {
+		t.Fatalf("sold = %+v", sold)
+	}
+
+	// Buying back costs what the merchant paid; the sword returns enchanted.
+	if _, err := uc.Refund(ctx, 7, sold, InventorySlots); err != nil {
+		t.Fatalf("Refund: %v", err)
+	}
+	if ir.items[adena.ObjectID] != nil || ir.items[2].Count != 300 {
+		t.Fatalf("adena = %+v, shots = %d", ir.items[adena.ObjectID], ir.items[2].Count)
+	}
+	var sword *models.CharacterItem
+	for _, it := range ir.items {
+		if it.ItemID == tradeSword {
+			sword = it
+		}
+	}
+	if sword == nil || sword.EnchantLevel != 3 || sword.OwnerID != 7 || sword.Loc != string(models.LocInventory) {
+		t.Fatalf("bought-back sword = %+v", sword)
+	}
+	if _, err := uc.Refund(ctx, 7, sold[1:], InventorySlots); !errors.Is(err, ErrNotEnoughAdena) {
+		t.Fatalf("err = %v, want ErrNotEnoughAdena", err)
+	}
+}
@@ -0,0 +1,217 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/rs/zerolog/log"
+
+	"github.com/VerTox/l2go/internal/gameserver/models"
+	"github.com/VerTox/l2go/internal/gameserver/registry"
+	"github.com/VerTox/l2go/internal/gameserver/repo"
+)
+
+// ErrTradeItemUnavailable aborts a trade commit: an offered item is gone, moved,
+// equipped or has fewer units than offered since it was put in the window.
+var ErrTradeItemUnavailable = errors.New("trade item no longer available")
+
+// ItemTransfer is one line of a trade offer: Count units of the stack ObjectID.
+type ItemTransfer struct {
+	ObjectID int32
+	Count    int64
+}
+
+// tradeable reports whether an item may be put in a trade window: it must sit in
+// the inventory (not equipped or stored) and its template must allow trading.
+// Quest items never change hands (L2J L2ItemInstance.isTradeable).
+func (uc *InventoryUseCase) tradeable(item *models.CharacterItem) bool {
+	if item.Loc != string(models.LocInventory) {
+		return false
+	}
+	tmpl := uc.templateOf(item.ItemID)
+	return tmpl != nil && tmpl.Tradeable && tmpl.Type2 != registry.ItemType2Quest
+}
+
+// TradeableInventory returns the character's items that can be offered in a
+// trade — the list TradeStart shows in the lower half of the trade window.
+func (uc *InventoryUseCase) TradeableInventory(ctx context.Context, charID int32) ([]models.CharacterItem, error) {
+	items, err := uc.repo.Item().GetInventory(ctx, charID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load inventory: %w", err)
+	}
+	out := items[:0]
+	for i := range items {
+		if uc.tradeable(&items[i]) {
+			out = append(out, items[i])
+		}
+	}
+	return out, nil
+}
+
+// TradeItem validates an AddTradeItem request: charID must own objectID, the item
+// must be tradeable and hold at least count units. Returns nil (no error) when
+// the item cannot be offered; the caller silently ignores it like L2J does.
+func (uc *InventoryUseCase) TradeItem(ctx context.Context, charID, objectID int32, count int64) (*models.CharacterItem, error) {
+	item, err := uc.repo.Item().GetByObjectID(ctx, objectID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get item: %w", err)
+	}
+	if item == nil || item.OwnerID != charID || count <= 0 || count > item.Count || !uc.tradeable(item) {
+		return nil, nil
+	}
+	return item, nil
+}
+
+// ExecuteTrade swaps the two offers in a single transaction: every item is
+// re-read under a row lock and re-validated, so an item consumed, equipped or
+// dropped since it was offered fails the whole exchange and nothing moves. Whole
+// stacks and non-stackables change owner in place (same object id); partial
+// stacks are split; stackables merge into the receiver's existing stack.
+// A receiver without the free slots (within slotsA / slotsB) for what it gets
+// fails it with ErrInventoryFull, and so does adena merging past MaxAdena.
+// Returns each side's inventory changes, only once the transaction committed.
+func (uc *InventoryUseCase) ExecuteTrade(ctx context.Context, charA int32, fromA []ItemTransfer, slotsA int, charB int32, fromB []ItemTransfer, slotsB int) (changedA, changedB []ChangedItem, err error) {
+	err = uc.repo.WithTransaction(ctx, func(tx repo.Transaction) error {
+		changedA, changedB = nil, nil
+		if err := uc.checkTradeSlots(ctx, tx.Item(), charB, fromA, slotsB); err != nil {
+			return err
+		}
+		if err := uc.checkTradeSlots(ctx, tx.Item(), charA, fromB, slotsA); err != nil {
+			return err
+		}
+		for _, t := range fromA {
+			sent, recv, err := uc.transferItem(ctx, tx.Item(), charA, charB, t)
+			if err != nil {
+				return err
+			}
+			if recv.Item.ItemID == models.ItemIDAdena && recv.Item.Count > MaxAdena {
+				return fmt.Errorf("adena would exceed %d", MaxAdena)
+			}
+			changedA = append(changedA, sent)
+			changedB = append(changedB, recv)
+		}
+		for _, t := range fromB {
+			sent, recv, err := uc.transferItem(ctx, tx.Item(), charB, charA, t)
+			if err != nil {
+				return err
+			}
+			if recv.Item.ItemID == models.ItemIDAdena && recv.Item.Count > MaxAdena {
+				return fmt.Errorf("adena would exceed %d", MaxAdena)
+			}
+			changedB = append(changedB, sent)
+			changedA = append(changedA, recv)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	log.Ctx(ctx).Info().
+		Int32("char_a", charA).
+		Int32("char_b", charB).
+		Int("items_a", len(fromA)).
+		Int("items_b", len(fromB)).
+		Msg("trade committed")
+
+	return changedA, changedB, nil
+}
+
+// checkTradeSlots locks the items offered to charID and fails with
+// ErrInventoryFull when they need more than inventorySlots stacks there. Like
+// L2J, the slots the receiver's own offer frees are not counted.
+func (uc *InventoryUseCase) checkTradeSlots(ctx context.Context, items repo.ItemRepository, charID int32, offer []ItemTransfer, inventorySlots int) error {
+	adding := make([]*models.CharacterItem, 0, len(offer))
+	for _, t := range offer {
+		item, err := items.GetByObjectIDForUpdate(ctx, t.ObjectID)
+		if err != nil {
+			return fmt.Errorf("failed to lock item %d: %w", t.ObjectID, err)
+		}
+		if item == nil {
+			return fmt.Errorf("%w: object %d", ErrTradeItemUnavailable, t.ObjectID)
+		}
+		adding = append(adding, item)
+	}
+	return uc.checkInventorySlots(ctx, items, charID, adding, inventorySlots)
+}
+
+// transferItem moves t.Count units of one item from one character to another
+// inside a transaction, returning the sender's and the receiver's change.
+func (uc *InventoryUseCase) transferItem(ctx context.Context, items repo.ItemRepository, from, to int32, t ItemTransfer) (sent, recv ChangedItem, err error) {
+	item, err := items.GetByObjectIDForUpdate(ctx, t.ObjectID)
+	if err != nil {
+		return sent, recv, fmt.Errorf("failed to lock item %d: %w", t.ObjectID, err)
+	}
+	if item == nil || item.OwnerID != from || t.Count <= 0 || t.Count > item.Count || !uc.tradeable(item) {
+		return sent, recv, fmt.Errorf("%w: object %d", ErrTradeItemUnavailable, t.ObjectID)
+	}
+
+	if tmpl := uc.templateOf(item.ItemID); (tmpl == nil || !tmpl.Stackable) && t.Count != item.Count {
+		return sent, recv, fmt.Errorf("%w: object %d is not stackable", ErrTradeItemUnavailable, t.ObjectID)
+	}
+	return uc.moveItem(ctx, items, item, to, models.LocInventory, t.Count)
+}
+
+// moveItem moves count units of a locked, already validated item to owner to
+// at loc, returning the source's and the destination's change. Whole stacks
+// and non-stackables change owner in place (same object id); partial stacks
+// are split; stackables merge into an existing stack at the destination, whose
+// row is locked first so concurrent merges into it all count.
+func (uc *InventoryUseCase) moveItem(ctx context.Context, items repo.ItemRepository, item *models.CharacterItem, to int32, loc models.ItemLocation, count int64) (sent, recv ChangedItem, err error) {
+	if tmpl := uc.templateOf(item.ItemID); tmpl != nil && tmpl.Stackable {
+		stack, err := items.FindStackableItemForUpdate(ctx, to, item.ItemID, loc)
+		if err != nil {
+			return sent, recv, fmt.Errorf("failed to find receiver stack %d: %w", item.ItemID, err)
+		}
+		if stack != nil {
+			stack.Count += count
+			if err := items.Update(ctx, stack); err != nil {
+				return sent, recv, fmt.Errorf("failed to merge into stack %d: %w", stack.ObjectID, err)
+			}
+			recv = ChangedItem{Item: *stack, UpdateType: 2} // MODIFY
+			sent, err = takeFromStack(ctx, items, item, count)
+			return sent, recv, err
+		}
+		if count < item.Count {
+			split := *item
+			split.ObjectID = 0
+			split.OwnerID = to
+			split.Count = count
+			split.SetLocation(loc, -1)
+			if err := items.Create(ctx, &split); err != nil {
+				return sent, recv, fmt.Errorf("failed to split stack %d: %w", item.ObjectID, err)
+			}
+			recv = ChangedItem{Item: split, UpdateType: 1} // ADD
+			sent, err = takeFromStack(ctx, items, item, count)
+			return sent, recv, err
+		}
+	}
+
+	// The whole object changes hands.
+	if err := items.MoveItem(ctx, item.ObjectID, to, loc, -1); err != nil {
+		return sent, recv, err
+	}
+	sent = ChangedItem{Item: *item, UpdateType: 3} // REMOVE
+	moved := *item
+	moved.OwnerID = to
+	moved.SetLocation(loc, -1)
+	recv = ChangedItem{Item: moved, UpdateType: 1} // ADD
+	return sent, recv, nil
+}
+
+// takeFromStack removes count units from a stack, deleting the row when empty.
+func takeFromStack(ctx context.Context, items repo.ItemRepository, stack *models.CharacterItem, count int64) (ChangedItem, error) {
+	stack.Count -= count
+	if stack.Count <= 0 {
+		stack.Count = 0
+		if err := items.Delete(ctx, stack.ObjectID); err != nil {
+			return ChangedItem{}, fmt.Errorf("failed to delete stack %d: %w", stack.ObjectID, err)
+		}
+		return ChangedItem{Item: *stack, UpdateType: 3}, nil // REMOVE
+	}
+	if err := items.Update(ctx, stack); err != nil {
+		return ChangedItem{}, fmt.Errorf("failed to update stack %d: %w", stack.ObjectID, err)
+	}
+	return ChangedItem{Item: *stack, UpdateType: 2}, nil // MODIFY
+}
@@ -0,0 +1,269 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/VerTox/l2go/internal/gameserver/models"
+	"github.com/VerTox/l2go/internal/gameserver/registry"
+	"github.com/VerTox/l2go/internal/gameserver/repo"
+)
+
+// tradeFakeItemRepo is an in-memory item table; tradeFakeDB runs WithTransaction
+// against it and restores the pre-transaction rows when fn fails.
+type tradeFakeItemRepo struct {
+	repo.ItemRepository
+	items  map[int32]*models.CharacterItem
+	nextID int32
+}
+
+func (r *tradeFakeItemRepo) GetByObjectID(_ context.Context, id int32) (*models.CharacterItem, error) {
+	if it, ok := r.items[id]; ok {
+		cp := *it
+		return &cp, nil
+	}
+	return nil, nil
+}
+
+func (r *tradeFakeItemRepo) GetByObjectIDForUpdate(ctx context.Context, id int32) (*models.CharacterItem, error) {
+	return r.GetByObjectID(ctx, id)
+}
+
+func (r *tradeFakeItemRepo) FindStackableItem(_ context.Context, charID, itemID int32, loc models.ItemLocation) (*models.CharacterItem, error) {
+	for _, it := range r.items {
+		if it.OwnerID == charID && it.ItemID == itemID && it.Loc == string(loc) {
+			cp := *it
+			return &cp, nil
+		}
+	}
+	return nil, nil
+}
+
+func (r *tradeFakeItemRepo) FindStackableItemForUpdate(ctx context.Context, charID, itemID int32, loc models.ItemLocation) (*models.CharacterItem, error) {
+	return r.FindStackableItem(ctx, charID, itemID, loc)
+}
+
+func (r *tradeFakeItemRepo) Create(_ context.Context, item *models.CharacterItem) error {
+	r.nextID++
+	item.ObjectID = r.nextID
+	cp := *item
+	r.items[item.ObjectID] = &cp
+	return nil
+}
+
+func (r *tradeFakeItemRepo) Update(_ context.Context, item *models.CharacterItem) error {
+	cp := *item
+	r.items[item.ObjectID] = &cp
+	return nil
+}
+
+func (r *tradeFakeItemRepo) Delete(_ context.Context, id int32) error {
+	delete(r.items, id)
+	return nil
+}
+
+func (r *tradeFakeItemRepo) MoveItem(_ context.Context, id, owner int32, loc models.ItemLocation, locData int) error {
+	it := r.items[id]
+	it.OwnerID = owner
+	it.SetLocation(loc, locData)
+	return nil
+}
+
+func (r *tradeFakeItemRepo) GetWarehouse(_ context.Context, ownerID int32, loc models.ItemLocation) ([]models.CharacterItem, error) {
+	var out []models.CharacterItem
+	for _, it := range r.items {
+		if it.OwnerID == ownerID && it.Loc == string(loc) {
+			out = append(out, *it)
+		}
+	}
+	return out, nil
+}
+
+func (r *tradeFakeItemRepo) GetInventory(ctx context.Context, charID int32) ([]models.CharacterItem, error) {
+	return r.GetWarehouse(ctx, charID, models.LocInventory)
+}
+
+func (r *tradeFakeItemRepo) GetPaperdoll(ctx context.Context, charID int32) ([]models.CharacterItem, error) {
+	return r.GetWarehouse(ctx, charID, models.LocPaperdoll)
+}
+
+type tradeFakeTx struct {
+	repo.Transaction
+	item *tradeFakeItemRepo
+}
+
+func (t *tradeFakeTx) Item() repo.ItemRepository { return t.item }
+
+type tradeFakeDB struct {
+	repo.DatabaseRepository
+	item *tradeFakeItemRepo
+}
+
+func (d *tradeFakeDB) Item() repo.ItemRepository { return d.item }
+
+func (d *tradeFakeDB) WithTransaction(_ context.Context, fn func(tx repo.Transaction) error) error {
+	saved := make(map[int32]*models.CharacterItem, len(d.item.items))
+	for id, it := range d.item.items {
+		cp := *it
+		saved[id] = &cp
+	}
+	if err := fn(&tradeFakeTx{item: d.item}); err != nil {
+		d.item.items = saved
+		return err
+	}
+	return nil
+}
+
+const (
+	tradeAdena = 57
+	tradeSword = 1
+	tradeQuest = 2
+	tradeBound = 3 // depositable, never traded
+)
+
+func newTradeTest(items ...*models.CharacterItem) (*InventoryUseCase, *tradeFakeItemRepo) {
+	ir := &tradeFakeItemRepo{items: map[int32]*models.CharacterItem{}, nextID: 1000}
+	for _, it := range items {
+		it.Loc = string(models.LocInventory)
+		it.LocData = -1
+		ir.items[it.ObjectID] = it
+	}
+	tmpls := map[int32]*registry.ItemTemplate{
+		tradeAdena: {ID: tradeAdena, Stackable: true, Tradeable: true, Depositable: true, Type2: registry.ItemType2Money},
+		tradeSword: {ID: tradeSword, Tradeable: true, Depositable: true, Type2: registry.ItemType2Weapon},
+		tradeQuest: {ID: tradeQuest, Stackable: true, Tradeable: true, Depositable: true, Type2: registry.ItemType2Quest},
+		tradeBound: {ID: tradeBound, Depositable: true, Type2: registry.ItemType2Weapon},
+	}
+	uc := &InventoryUseCase{
+		repo:       &tradeFakeDB{item: ir},
+		templateOf: func(id int32) *registry.ItemTemplate { return tmpls[id] },
+	}
+	return uc, ir
+}
+
+func TestExecuteTrade_SwapsSplitsAndMerges(t *testing.T) {
+	uc, ir := newTradeTest(
+		&models.CharacterItem{ObjectID: 1, OwnerID: 7, ItemID: tradeSword, Count: 1},
+		&models.CharacterItem{ObjectID: 2, OwnerID: 8, ItemID: tradeAdena, Count: 1000},
+		&models.CharacterItem{ObjectID: 3, OwnerID: 7, ItemID: tradeAdena, Count: 50},
+	)
+
+	changedA, changedB, err := uc.ExecuteTrade(context.Background(),
+		7, []ItemTransfer{{ObjectID: 1, Count: 1}}, InventorySlots,
+		8, []ItemTransfer{{ObjectID: 2, Count: 400}}, InventorySlots)
+	if err != nil {
+		t.Fatalf("ExecuteTrade: %v", err)
+	}
+
+	// The sword keeps its object id under the new owner.
+	if ir.items[1].OwnerID != 8 {
+		t.Errorf("sword owner = %d, want 8", ir.items[1].OwnerID)
+	}
+	// Adena merged into char 7's stack; char 8 keeps the rest.
+	if ir.items[3].Count != 450 || ir.items[2].Count != 600 {
+		t.Errorf("adena stacks = %d/%d, want 450/600", ir.items[3].Count, ir.items[2].Count)
+	}
+	if len(ir.items) != 3 {
+		t.Errorf("item rows = %d, want 3 (no duplication)", len(ir.items))
+	}
+	if len(changedA) != 2 || len(changedB) != 2 {
+		t.Errorf("changes = %d/%d, want 2/2", len(changedA), len(changedB))
+	}
+}
+
+func TestExecuteTrade_SplitsIntoNewStack(t *testing.T) {
+	uc, ir := newTradeTest(&models.CharacterItem{ObjectID: 2, OwnerID: 8, ItemID: tradeAdena, Count: 1000})
+
+	_, changedB, err := uc.ExecuteTrade(context.Background(), 7, nil, InventorySlots, 8, []ItemTransfer{{ObjectID: 2, Count: 300}}, InventorySlots)
+	if err != nil {
+		t.Fatalf("ExecuteTrade: %v", err)
+	}
+	split := ir.items[1001]
+	if split == nil || split.OwnerID != 7 || split.Count != 300 {
+		t.Fatalf("split stack = %+v, want 300 adena for char 7", split)
+	}
+	if ir.items[2].Count != 700 {
+		t.Errorf("sender stack = %d, want 700", ir.items[2].Count)
+	}
+	if len(changedB) != 1 || changedB[0].UpdateType != 2 {
+		t.Errorf("sender changes = %+v, want one MODIFY", changedB)
+	}
+}
+
+func TestExecuteTrade_StaleOfferRollsBackEverything(t *testing.T) {
+	uc, ir := newTradeTest(
+		&models.CharacterItem{ObjectID: 1, OwnerID: 7, ItemID: tradeSword, Count: 1},
+		&models.CharacterItem{ObjectID: 2, OwnerID: 8, ItemID: tradeAdena, Count: 100},
+	)
+
+	// Char 8 offers more adena than it now holds: the sword must not move either.
+	_, _, err := uc.ExecuteTrade(context.Background(),
+		7, []ItemTransfer{{ObjectID: 1, Count: 1}}, InventorySlots,
+		8, []ItemTransfer{{ObjectID: 2, Count: 500}}, InventorySlots)
+	if !errors.Is(err, ErrTradeItemUnavailable) {
+		t.Fatalf("err = %v, want ErrTradeItemUnavailable", err)
+	}
+	if ir.items[1].OwnerID != 7 || ir.items[2].Count != 100 {
+		t.Errorf("rows changed after failed trade: sword owner %d, adena %d", ir.items[1].OwnerID, ir.items[2].Count)
+	}
+}
+
+func TestExecuteTrade_ReceiverWithoutSlotsRefused(t *testing.T) {
+	uc, ir := newTradeTest(
+		&models.CharacterItem{ObjectID: 1, OwnerID: 7, ItemID: tradeSword, Count: 1},
+		&models.CharacterItem{ObjectID: 2, OwnerID: 8, ItemID: tradeSword, Count: 1},
+		&models.CharacterItem{ObjectID: 3, OwnerID: 8, ItemID: tradeAdena, Count: 100},
+	)
+
+	// Char 8 carries two stacks and may hold two: the sword needs a third.
+	_, _, err := uc.ExecuteTrade(context.Background(),
+		7, []ItemTransfer{{ObjectID: 1, Count: 1}}, InventorySlots,
+		8, []ItemTransfer{{ObjectID: 3, Count: 50}}, 2)
+	if !errors.Is(err, ErrInventoryFull) {
+		t.Fatalf("err = %v, want ErrInventoryFull", err)
+	}
+	if ir.items[1].OwnerID != 7 || ir.items[3].Count != 100 {
+		t.Errorf("rows changed after refused trade: sword owner %d, adena %d", ir.items[1].OwnerID, ir.items[3].Count)
+	}
+}
+
+func TestExecuteTrade_AdenaPastMaxRefused(t *testing.T) {
+	uc, ir := newTradeTest(
+		&models.CharacterItem{ObjectID: 1, OwnerID: 7, ItemID: tradeAdena, Count: MaxAdena - 10},
+		&models.CharacterItem{ObjectID: 2, OwnerID: 8, ItemID: tradeAdena, Count: 100},
+	)
+
+	_, _, err := uc.ExecuteTrade(context.Background(),
+		7, nil, InventorySlots,
+		8, []ItemTransfer{{ObjectID: 2, Count: 11}}, InventorySlots)
+	if err == nil {
+		t.Fatal("trade merged adena past MaxAdena")
+	}
+	if ir.items[1].Count != MaxAdena-10 || ir.items[2].Count != 100 {
+		t.Errorf("adena stacks = %d/%d after refused trade", ir.items[1].Count, ir.items[2].Count)
+	}
+}
+
+func TestTradeItem_RejectsQuestEquippedAndForeign(t *testing.T) {
+	uc, ir := newTradeTest(
+		&models.CharacterItem{ObjectID: 1, OwnerID: 7, ItemID: tradeSword, Count: 1},
+		&models.CharacterItem{ObjectID: 2, OwnerID: 7, ItemID: tradeQuest, Count: 5},
+		&models.CharacterItem{ObjectID: 3, OwnerID: 8, ItemID: tradeAdena, Count: 5},
+	)
+	ir.items[1].SetLocation(models.LocPaperdoll, 5)
+
+	for _, tc := range []struct {
+		objectID int32
+		count    int64
+	}{{1, 1}, {2, 1}, {3, 1}} {
+		item, err := uc.TradeItem(context.Background(), 7, tc.objectID, tc.count)
+		if err != nil || item != nil {
+			t.Errorf("TradeItem(%d) = %+v, %v; want refused", tc.objectID, item, err)
+		}
+	}
+	ir.items[1].SetLocation(models.LocInventory, -1)
+	if item, _ := uc.TradeItem(context.Background(), 7, 1, 1); item == nil {
+		t.Error("unequipped sword should be tradeable")
+	}
+}
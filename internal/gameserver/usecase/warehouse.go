@@ -0,0 +1,283 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/rs/zerolog/log"
+
+	"github.com/VerTox/l2go/internal/gameserver/models"
+	"github.com/VerTox/l2go/internal/gameserver/registry"
+	"github.com/VerTox/l2go/internal/gameserver/repo"
+)
+
+// Warehouse and inventory slot limits (L2J Config defaults WAREHOUSE_SLOTS_*,
+// INVENTORY_MAXIMUM_*): dwarves get more room in both.
+const (
+	WarehouseSlots      = 100
+	WarehouseSlotsDwarf = 120
+	ClanWarehouseSlots  = 150
+	InventorySlots      = 80
+	InventorySlotsDwarf = 100
+)
+
+// WarehouseDepositFee is the adena charged per deposited line (L2J
+// SendWareHouseDepositList); withdrawals are free.
+const WarehouseDepositFee int64 = 30
+
+var (
+	// ErrWarehouseFull aborts a deposit that needs more free warehouse slots.
+	ErrWarehouseFull = errors.New("warehouse full")
+	// ErrInventoryFull aborts a withdrawal that needs more free inventory slots.
+	ErrInventoryFull = errors.New("inventory full")
+	// ErrWarehouseItemUnavailable aborts a deposit or withdrawal: a requested
+	// item is gone, moved, not storable here or has fewer units than asked.
+	ErrWarehouseItemUnavailable = errors.New("warehouse item unavailable")
+)
+
+// Warehouse addresses one item storage: a character's own (rows owned by the
+// character at LocWarehouse) or its clan's (owned by the clan id at
+// LocClanWH), with its slot limit.
+type Warehouse struct {
+	OwnerID int32
+	Loc     models.ItemLocation
+	Slots   int
+}
+
+// PersonalWarehouse is char's own warehouse.
+func PersonalWarehouse(char *models.Character) Warehouse {
+	slots := WarehouseSlots
+	if models.CharacterRace(char.Race) == models.RaceDwarf {
+		slots = WarehouseSlotsDwarf
+	}
+	return Warehouse{OwnerID: char.ID, Loc: models.LocWarehouse, Slots: slots}
+}
+
+// ClanWarehouse is the warehouse shared by the members of clanID.
+func ClanWarehouse(clanID int32) Warehouse {
+	return Warehouse{OwnerID: clanID, Loc: models.LocClanWH, Slots: ClanWarehouseSlots}
+}
+
+// InventoryLimit is how many stacks char may carry.
+func InventoryLimit(char *models.Character) int {
+	if models.CharacterRace(char.Race) == models.RaceDwarf {
+		return InventorySlotsDwarf
+	}
+	return InventorySlots
+}
+
+// depositable reports whether an item may go into wh (L2J
+// L2ItemInstance.isDepositable): it must sit in the inventory, not equipped,
+// and its template must allow it. The clan warehouse, shared by everyone in
+// the clan, only takes what could also be traded.
+func (uc *InventoryUseCase) depositable(item *models.CharacterItem, wh Warehouse) bool {
+	if item.Loc != string(models.LocInventory) {
+		return false
+	}
+	tmpl := uc.templateOf(item.ItemID)
+	if tmpl == nil || !tmpl.Depositable || tmpl.Type2 == registry.ItemType2Quest {
+		return false
+	}
+	return wh.Loc != models.LocClanWH || tmpl.Tradeable
+}
+
+// DepositableInventory returns what the deposit window lists for wh together
+// with the character's adena, shown at the top of the window.
+func (uc *InventoryUseCase) DepositableInventory(ctx context.Context, charID int32, wh Warehouse) ([]models.CharacterItem, int64, error) {
+	items, err := uc.repo.Item().GetInventory(ctx, charID)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to load inventory: %w", err)
+	}
+	var adena int64
+	out := items[:0]
+	for i := range items {
+		if items[i].ItemID == models.ItemIDAdena {
+			adena += items[i].Count
+		}
+		if uc.depositable(&items[i], wh) {
+			out = append(out, items[i])
+		}
+	}
+	return out, adena, nil
+}
+
+// WarehouseItems returns everything stored in wh.
+func (uc *InventoryUseCase) WarehouseItems(ctx context.Context, wh Warehouse) ([]models.CharacterItem, error) {
+	items, err := uc.repo.Item().GetWarehouse(ctx, wh.OwnerID, wh.Loc)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load warehouse: %w", err)
+	}
+	return items, nil
+}
+
+// Deposit moves items from charID's inventory into wh in one transaction and
+// charges WarehouseDepositFee per line. Every item is re-read under a row lock;
+// stackables merge into the warehouse's stack of the same item and only new
+// stacks need a free slot. A missing item, a full warehouse or too little
+// adena left for the fee fails the whole deposit and nothing moves. Returns
+// the inventory changes, only once the transaction committed.
+func (uc *InventoryUseCase) Deposit(ctx context.Context, charID int32, wh Warehouse, lines []ItemTransfer) ([]ChangedItem, error) {
+	var changed []ChangedItem
+	err := uc.repo.WithTransaction(ctx, func(tx repo.Transaction) error {
+		changed = nil
+		locked, err := uc.lockWarehouseLines(ctx, tx.Item(), lines, func(it *models.CharacterItem) bool {
+			return it.OwnerID == charID && uc.depositable(it, wh)
+		})
+		if err != nil {
+			return err
+		}
+		stored, err := tx.Item().GetWarehouse(ctx, wh.OwnerID, wh.Loc)
+		if err != nil {
+			return fmt.Errorf("failed to load warehouse: %w", err)
+		}
+		stacks := make(map[int32]bool, len(stored))
+		for _, it := range stored {
+			stacks[it.ItemID] = true
+		}
+		if len(stored)+uc.newSlots(locked, stacks) > wh.Slots {
+			return ErrWarehouseFull
+		}
+
+		for i, item := range locked {
+			sent, _, err := uc.moveItem(ctx, tx.Item(), item, wh.OwnerID, wh.Loc, lines[i].Count)
+			if err != nil {
+				return err
+			}
+			changed = append(changed, sent)
+		}
+
+		// The fee comes out of whatever adena stays behind.
+		fee := WarehouseDepositFee * int64(len(lines))
+		adena, err := tx.Item().FindStackableItemForUpdate(ctx, charID, models.ItemIDAdena, models.LocInventory)
+		if err != nil {
+			return fmt.Errorf("failed to find adena: %w", err)
+		}
+		if adena == nil || adena.Count < fee {
+			return ErrNotEnoughAdena
+		}
+		paid, err := takeFromStack(ctx, tx.Item(), adena, fee)
+		if err != nil {
+			return err
+		}
+		changed = append(changed, paid)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	log.Ctx(ctx).Info().
+		Int32("char_id", charID).
+		Int32("owner_id", wh.OwnerID).
+		Str("loc", string(wh.Loc)).
+		Int("items", len(lines)).
+		Msg("warehouse deposit")
+
+	return changed, nil
+}
+
+// Withdraw moves items from wh into charID's inventory in one transaction,
+// merging stackables into the inventory's stacks. New stacks must fit
+// inventorySlots; a missing item or a full inventory fails the whole
+// withdrawal. Returns the inventory changes, only once the transaction
+// committed.
+func (uc *InventoryUseCase) Withdraw(ctx context.Context, charID int32, wh Warehouse, lines []ItemTransfer, inventorySlots int) ([]ChangedItem, error) {
+	var changed []ChangedItem
+	err := uc.repo.WithTransaction(ctx, func(tx repo.Transaction) error {
+		changed = nil
+		locked, err := uc.lockWarehouseLines(ctx, tx.Item(), lines, func(it *models.CharacterItem) bool {
+			return it.OwnerID == wh.OwnerID && it.Loc == string(wh.Loc)
+		})
+		if err != nil {
+			return err
+		}
+		inv, err := tx.Item().GetInventory(ctx, charID)
+		if err != nil {
+			return fmt.Errorf("failed to load inventory: %w", err)
+		}
+		worn, err := tx.Item().GetPaperdoll(ctx, charID)
+		if err != nil {
+			return fmt.Errorf("failed to load paperdoll: %w", err)
+		}
+		stacks := make(map[int32]bool, len(inv))
+		for _, it := range inv {
+			stacks[it.ItemID] = true
+		}
+		if len(inv)+len(worn)+uc.newSlots(locked, stacks) > inventorySlots {
+			return ErrInventoryFull
+		}
+
+		for i, item := range locked {
+			_, recv, err := uc.moveItem(ctx, tx.Item(), item, charID, models.LocInventory, lines[i].Count)
+			if err != nil {
+				return err
+			}
+			if recv.Item.ItemID == models.ItemIDAdena && recv.Item.Count > MaxAdena {
+				return fmt.Errorf("adena would exceed %d", MaxAdena)
+			}
+			changed = append(changed, recv)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	log.Ctx(ctx).Info().
+		Int32("char_id", charID).
+		Int32("owner_id", wh.OwnerID).
+		Str("loc", string(wh.Loc)).
+		Int("items", len(lines)).
+		Msg("warehouse withdrawal")
+
+	return changed, nil
+}
+
+// lockWarehouseLines re-reads every requested item under a row lock and checks
+// it against allowed and the requested count; a non-stackable always moves
+// whole and no item may be named twice.
+func (uc *InventoryUseCase) lockWarehouseLines(ctx context.Context, items repo.ItemRepository, lines []ItemTransfer, allowed func(*models.CharacterItem) bool) ([]*models.CharacterItem, error) {
+	if len(lines) == 0 {
+		return nil, fmt.Errorf("%w: nothing requested", ErrWarehouseItemUnavailable)
+	}
+	locked := make([]*models.CharacterItem, len(lines))
+	seen := make(map[int32]bool, len(lines))
+	for i, t := range lines {
+		if seen[t.ObjectID] {
+			return nil, fmt.Errorf("%w: object %d requested twice", ErrWarehouseItemUnavailable, t.ObjectID)
+		}
+		seen[t.ObjectID] = true
+		item, err := items.GetByObjectIDForUpdate(ctx, t.ObjectID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to lock item %d: %w", t.ObjectID, err)
+		}
+		if item == nil || !allowed(item) || t.Count <= 0 || t.Count > item.Count {
+			return nil, fmt.Errorf("%w: object %d", ErrWarehouseItemUnavailable, t.ObjectID)
+		}
+		if tmpl := uc.templateOf(item.ItemID); (tmpl == nil || !tmpl.Stackable) && t.Count != item.Count {
+			return nil, fmt.Errorf("%w: object %d is not stackable", ErrWarehouseItemUnavailable, t.ObjectID)
+		}
+		locked[i] = item
+	}
+	return locked, nil
+}
+
+// newSlots counts the stacks moving items would add at a destination that
+// already holds stacks of the given item ids: each non-stackable takes a
+// slot, a stackable only when no stack of it is there yet.
+func (uc *InventoryUseCase) newSlots(items []*models.CharacterItem, stacks map[int32]bool) int {
+	n := 0
+	for _, item := range items {
+		tmpl := uc.templateOf(item.ItemID)
+		if tmpl == nil || !tmpl.Stackable {
+			n++
+			continue
+		}
+		if !stacks[item.ItemID] {
+			stacks[item.ItemID] = true
+			n++
+		}
+	}
+	return n
+}
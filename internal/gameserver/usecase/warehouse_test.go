@@ -0,0 +1,121 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/VerTox/l2go/internal/gameserver/models"
+)
+
+func TestWarehouse_DepositMergesStacksAndChargesFee(t *testing.T) {
+	uc, ir := newTradeTest(
+		&models.CharacterItem{ObjectID: 1, OwnerID: 7, ItemID: tradeSword, Count: 1},
+		&models.CharacterItem{ObjectID: 2, OwnerID: 7, ItemID: tradeAdena, Count: 1000},
+	)
+	wh := PersonalWarehouse(&models.Character{ID: 7})
+	// Adena already stored: the deposit merges into it.
+	ir.items[3] = &models.CharacterItem{ObjectID: 3, OwnerID: 7, ItemID: tradeAdena, Count: 5, Loc: string(models.LocWarehouse), LocData: -1}
+
+	changed, err := uc.Deposit(context.Background(), 7, wh, []ItemTransfer{{ObjectID: 1, Count: 1}, {ObjectID: 2, Count: 400}})
+	if err != nil {
+		t.Fatalf("Deposit: %v", err)
+	}
+	if ir.items[1].Loc != string(models.LocWarehouse) || ir.items[1].OwnerID != 7 {
+		t.Errorf("sword = %+v, want it in the warehouse under the same object id", ir.items[1])
+	}
+	if ir.items[3].Count != 405 {
+		t.Errorf("stored adena = %d, want 405", ir.items[3].Count)
+	}
+	if got, want := ir.items[2].Count, int64(1000-400-2*WarehouseDepositFee); got != want {
+		t.Errorf("carried adena = %d, want %d", got, want)
+	}
+	if len(changed) != 3 || changed[0].UpdateType != 3 || changed[2].Item.Count != ir.items[2].Count {
+		t.Errorf("changed = %+v", changed)
+	}
+}
+
+func TestWarehouse_DepositRollsBackWithoutFee(t *testing.T) {
+	uc, ir := newTradeTest(
+		&models.CharacterItem{ObjectID: 1, OwnerID: 7, ItemID: tradeSword, Count: 1},
+		&models.CharacterItem{ObjectID: 2, OwnerID: 7, ItemID: tradeAdena, Count: 40},
+	)
+	wh := PersonalWarehouse(&models.Character{ID: 7})
+
+	// Storing 20 of 40 adena leaves 20, short of the 60 fee for two lines.
+	_, err := uc.Deposit(context.Background(), 7, wh, []ItemTransfer{{ObjectID: 1, Count: 1}, {ObjectID: 2, Count: 20}})
+	if !errors.Is(err, ErrNotEnoughAdena) {
+		t.Fatalf("err = %v, want ErrNotEnoughAdena", err)
+	}
+	if ir.items[1].Loc != string(models.LocInventory) || ir.items[2].Count != 40 || len(ir.items) != 2 {
+		t.Fatalf("a failed deposit must move nothing: %+v", ir.items)
+	}
+}
+
+func TestWarehouse_ClanWarehouseRules(t *testing.T) {
+	uc, _ := newTradeTest(
+		&models.CharacterItem{ObjectID: 1, OwnerID: 7, ItemID: tradeBound, Count: 1},
+		&models.CharacterItem{ObjectID: 2, OwnerID: 7, ItemID: tradeAdena, Count: 1000},
+		&models.CharacterItem{ObjectID: 3, OwnerID: 7, ItemID: tradeQuest, Count: 1},
+	)
+	ctx := context.Background()
+
+	// Untradeable items stay out of the clan warehouse; quest items out of both.
+	own, _, _ := uc.DepositableInventory(ctx, 7, PersonalWarehouse(&models.Character{ID: 7}))
+	clan, adena, _ := uc.DepositableInventory(ctx, 7, ClanWarehouse(3))
+	if len(own) != 2 || len(clan) != 1 || clan[0].ItemID != tradeAdena || adena != 1000 {
+		t.Fatalf("own = %+v clan = %+v adena = %d", own, clan, adena)
+	}
+	if _, err := uc.Deposit(ctx, 7, ClanWarehouse(3), []ItemTransfer{{ObjectID: 1, Count: 1}}); !errors.Is(err, ErrWarehouseItemUnavailable) {
+		t.Fatalf("err = %v, want ErrWarehouseItemUnavailable", err)
+	}
+
+	// Clan rows belong to the clan id; any member may take them out.
+	if _, err := uc.Deposit(ctx, 7, ClanWarehouse(3), []ItemTransfer{{ObjectID: 2, Count: 500}}); err != nil {
+		t.Fatalf("Deposit: %v", err)
+	}
+	stored, _ := uc.WarehouseItems(ctx, ClanWarehouse(3))
+	if len(stored) != 1 || stored[0].OwnerID != 3 || stored[0].Count != 500 {
+		t.Fatalf("clan warehouse = %+v", stored)
+	}
+	changed, err := uc.Withdraw(ctx, 8, ClanWarehouse(3), []ItemTransfer{{ObjectID: stored[0].ObjectID, Count: 500}}, InventorySlots)
+	if err != nil || len(changed) != 1 || changed[0].Item.OwnerID != 8 || changed[0].UpdateType != 1 {
+		t.Fatalf("Withdraw = %+v, %v", changed, err)
+	}
+}
+
+func TestWarehouse_SlotLimits(t *testing.T) {
+	uc, ir := newTradeTest(
+		&models.CharacterItem{ObjectID: 1, OwnerID: 7, ItemID: tradeSword, Count: 1},
+		&models.CharacterItem{ObjectID: 2, OwnerID: 7, ItemID: tradeAdena, Count: 1000},
+	)
+	ctx := context.Background()
+	wh := PersonalWarehouse(&models.Character{ID: 7})
+	wh.Slots = 1
+	ir.items[3] = &models.CharacterItem{ObjectID: 3, OwnerID: 7, ItemID: tradeAdena, Count: 1, Loc: string(models.LocWarehouse), LocData: -1}
+
+	// Adena merges into the stored stack and needs no slot; the sword does.
+	if _, err := uc.Deposit(ctx, 7, wh, []ItemTransfer{{ObjectID: 2, Count: 10}}); err != nil {
+		t.Fatalf("merging deposit: %v", err)
+	}
+	if _, err := uc.Deposit(ctx, 7, wh, []ItemTransfer{{ObjectID: 1, Count: 1}}); !errors.Is(err, ErrWarehouseFull) {
+		t.Fatalf("err = %v, want ErrWarehouseFull", err)
+	}
+
+	wh.Slots = 2
+	if _, err := uc.Deposit(ctx, 7, wh, []ItemTransfer{{ObjectID: 1, Count: 1}}); err != nil {
+		t.Fatalf("Deposit: %v", err)
+	}
+
+	// Only the adena stack is carried now: taking the sword back needs a free
+	// inventory slot, taking adena merges.
+	if _, err := uc.Withdraw(ctx, 7, wh, []ItemTransfer{{ObjectID: 1, Count: 1}}, 1); !errors.Is(err, ErrInventoryFull) {
+		t.Fatalf("err = %v, want ErrInventoryFull", err)
+	}
+	if _, err := uc.Withdraw(ctx, 7, wh, []ItemTransfer{{ObjectID: 3, Count: 11}}, 1); err != nil {
+		t.Fatalf("merging withdrawal: %v", err)
+	}
+	if _, err := uc.Withdraw(ctx, 7, wh, []ItemTransfer{{ObjectID: 1, Count: 1}}, 2); err != nil {
+		t.Fatalf("Withdraw: %v", err)
+	}
+}